@@ -2,6 +2,7 @@ package diygoapi
 
 import (
 	"context"
+	"crypto/hmac"
 	"encoding/hex"
 	"fmt"
 	"time"
@@ -16,6 +17,18 @@ import (
 type AppServicer interface {
 	Create(ctx context.Context, r *CreateAppRequest, adt Audit) (*AppResponse, error)
 	Update(ctx context.Context, r *UpdateAppRequest, adt Audit) (*AppResponse, error)
+	// Delete soft deletes an App. A deleted App can no longer
+	// authenticate and is kept, along with its API keys, until it is
+	// purged.
+	Delete(ctx context.Context, extlID string, adt Audit) (DeleteResponse, error)
+	// Restore undoes the deletion of an App
+	Restore(ctx context.Context, extlID string, adt Audit) (*AppResponse, error)
+	// FindAll returns the apps of the Org the audit user is acting in
+	FindAll(ctx context.Context, includeDeleted bool, adt Audit) ([]*AppResponse, error)
+	FindByExternalID(ctx context.Context, extlID string, includeDeleted bool, adt Audit) (*AppResponse, error)
+	CreateAPIKey(ctx context.Context, r *CreateAPIKeyRequest, adt Audit) (*APIKeyResponse, error)
+	FindAPIKeys(ctx context.Context, appExtlID string, adt Audit) ([]*APIKeyMetadataResponse, error)
+	RevokeAPIKey(ctx context.Context, appExtlID, prefix string, adt Audit) (DeleteResponse, error)
 }
 
 // APIKeyGenerator creates a random, 128 API key string
@@ -33,6 +46,8 @@ type App struct {
 	Provider         Provider
 	ProviderClientID string
 	APIKeys          []APIKey
+	// DeletedAt is the moment the App was deleted, zero if it is not
+	DeletedAt time.Time
 }
 
 // AddKey validates and adds an API key to the slice of App API keys
@@ -49,11 +64,12 @@ func (a *App) AddKey(key APIKey) error {
 }
 
 // ValidateKey determines if the app has a matching key for the input
-// and if that key is valid
-func (a *App) ValidateKey(realm, matchKey string) error {
+// and if that key is valid. The pepper is the server secret used to
+// hash API keys.
+func (a *App) ValidateKey(realm, matchKey string, pepper *[32]byte) error {
 	const op errs.Op = "diygoapi/App.ValidateKey"
 
-	key, err := a.matchKey(realm, matchKey)
+	key, err := a.matchKey(realm, matchKey, pepper)
 	if err != nil {
 		return err
 	}
@@ -65,12 +81,14 @@ func (a *App) ValidateKey(realm, matchKey string) error {
 }
 
 // MatchKey returns the matching Key given the string, if exists.
-// An error will be sent if no match is found.
-func (a *App) matchKey(realm, matchKey string) (APIKey, error) {
+// The input is hashed and compared in constant time against the
+// hash of each key. An error will be sent if no match is found.
+func (a *App) matchKey(realm, matchKey string, pepper *[32]byte) (APIKey, error) {
 	const op errs.Op = "diygoapi/App.matchKey"
 
+	h := secure.HMAC([]byte(matchKey), pepper)
 	for _, apiKey := range a.APIKeys {
-		if matchKey == apiKey.Key() {
+		if hmac.Equal(h, apiKey.hash) {
 			return apiKey, nil
 		}
 	}
@@ -102,52 +120,164 @@ func (r CreateAppRequest) Validate() error {
 	return nil
 }
 
-// UpdateAppRequest is the request struct for Updating an App
+// UpdateAppRequest is the request struct for Updating an App. If the
+// Oauth2 provider and client ID are given, the App is reassigned to
+// them, otherwise the App's current provider is kept.
 type UpdateAppRequest struct {
-	ExternalID  string
-	Name        string `json:"name"`
-	Description string `json:"description"`
+	ExternalID             string
+	Name                   string `json:"name"`
+	Description            string `json:"description"`
+	Oauth2Provider         string `json:"oauth2_provider"`
+	Oauth2ProviderClientID string `json:"oauth2_provider_client_id"`
+}
+
+// Validate determines whether the UpdateAppRequest has proper data to be considered valid
+func (r UpdateAppRequest) Validate() error {
+	const op errs.Op = "diygoapi/UpdateAppRequest.Validate"
+
+	switch {
+	case r.Name == "":
+		return errs.E(op, errs.Validation, "app name is required")
+	case r.Description == "":
+		return errs.E(op, errs.Validation, "app description is required")
+	case r.Oauth2Provider != "" && r.Oauth2ProviderClientID == "":
+		return errs.E(op, errs.Validation, "oAuth2 provider client ID is required when Oauth2 provider is given")
+	case r.Oauth2Provider == "" && r.Oauth2ProviderClientID != "":
+		return errs.E(op, errs.Validation, "oAuth2 provider is required when Oauth2 provider client ID is given")
+	case r.Oauth2Provider != "" && ParseProvider(r.Oauth2Provider) == UnknownProvider:
+		return errs.E(op, errs.Validation, fmt.Sprintf("%s is not a supported oAuth2 provider", r.Oauth2Provider))
+	}
+	return nil
 }
 
 // AppResponse is the response struct for an App
 type AppResponse struct {
-	ExternalID          string           `json:"external_id"`
-	Name                string           `json:"name"`
-	Description         string           `json:"description"`
-	CreateAppExtlID     string           `json:"create_app_extl_id"`
-	CreateUserFirstName string           `json:"create_user_first_name"`
-	CreateUserLastName  string           `json:"create_user_last_name"`
-	CreateDateTime      string           `json:"create_date_time"`
-	UpdateAppExtlID     string           `json:"update_app_extl_id"`
-	UpdateUserFirstName string           `json:"update_user_first_name"`
-	UpdateUserLastName  string           `json:"update_user_last_name"`
-	UpdateDateTime      string           `json:"update_date_time"`
-	APIKeys             []APIKeyResponse `json:"api_keys"`
+	ExternalID             string           `json:"external_id"`
+	Name                   string           `json:"name"`
+	Description            string           `json:"description"`
+	Oauth2Provider         string           `json:"oauth2_provider,omitempty"`
+	Oauth2ProviderClientID string           `json:"oauth2_provider_client_id,omitempty"`
+	CreateAppExtlID        string           `json:"create_app_extl_id"`
+	CreateUserFirstName    string           `json:"create_user_first_name"`
+	CreateUserLastName     string           `json:"create_user_last_name"`
+	CreateDateTime         string           `json:"create_date_time"`
+	UpdateAppExtlID        string           `json:"update_app_extl_id"`
+	UpdateUserFirstName    string           `json:"update_user_first_name"`
+	UpdateUserLastName     string           `json:"update_user_last_name"`
+	UpdateDateTime         string           `json:"update_date_time"`
+	DeletedDateTime        string           `json:"deleted_date_time,omitempty"`
+	APIKeys                []APIKeyResponse `json:"api_keys"`
 }
 
 // APIKeyResponse is the response fields for an API key
 type APIKeyResponse struct {
 	Key              string `json:"key"`
+	Prefix           string `json:"prefix"`
+	DeactivationDate string `json:"deactivation_date"`
+}
+
+// CreateAPIKeyRequest is the request struct for issuing a new API key
+// for an existing App
+type CreateAPIKeyRequest struct {
+	AppExternalID string
+	// DeactivationDate is the moment the new key is no longer usable
+	// in RFC3339 format
+	DeactivationDate string `json:"deactivation_date"`
+	// RotationGracePeriod, if given, rotates the App's keys: each of
+	// the App's existing keys is set to deactivate once the grace period
+	// has passed, allowing the old and new keys to overlap. It is
+	// given as a duration string (e.g. "72h"), "0s" deactivates the
+	// existing keys immediately.
+	RotationGracePeriod string `json:"rotation_grace_period"`
+}
+
+// Validate determines whether the CreateAPIKeyRequest has proper data to be considered valid
+func (r CreateAPIKeyRequest) Validate() error {
+	const op errs.Op = "diygoapi/CreateAPIKeyRequest.Validate"
+
+	if r.DeactivationDate == "" {
+		return errs.E(op, errs.Validation, "deactivation date is required")
+	}
+
+	deactivation, err := time.Parse(time.RFC3339, r.DeactivationDate)
+	if err != nil {
+		return errs.E(op, errs.Validation, "deactivation date must be in RFC3339 format")
+	}
+
+	if !deactivation.After(time.Now()) {
+		return errs.E(op, errs.Validation, "deactivation date must be in the future")
+	}
+
+	if r.RotationGracePeriod != "" {
+		var grace time.Duration
+		grace, err = time.ParseDuration(r.RotationGracePeriod)
+		if err != nil {
+			return errs.E(op, errs.Validation, "rotation grace period must be a duration, e.g. 72h")
+		}
+		if grace < 0 {
+			return errs.E(op, errs.Validation, "rotation grace period cannot be negative")
+		}
+	}
+
+	return nil
+}
+
+// APIKeyMetadataResponse is the response struct for an API key when
+// listing the keys of an App. The key itself is never returned.
+type APIKeyMetadataResponse struct {
+	Prefix           string `json:"prefix"`
+	CreateDateTime   string `json:"create_date_time"`
+	LastUsedDateTime string `json:"last_used_date_time,omitempty"`
 	DeactivationDate string `json:"deactivation_date"`
+	Active           bool   `json:"active"`
+}
+
+// APIKeyPrefixLength is the number of leading characters of an API
+// key which are stored in plaintext and used to look up the key
+const APIKeyPrefixLength int = 8
+
+// apiKeyPepperLabel is the label used to derive the API key pepper
+// from the encryption key
+const apiKeyPepperLabel string = "diygoapi api key pepper v1"
+
+// APIKeyPepper derives the pepper (the server secret API keys are
+// hashed with) from the encryption key. The encryption key is not
+// used directly, so a hash of an API key reveals nothing which could
+// be used against data encrypted with it.
+func APIKeyPepper(ek *[32]byte) *[32]byte {
+	return secure.DeriveKey(ek, apiKeyPepperLabel)
+}
+
+// APIKeyPrefix returns the lookup prefix for the given API key string
+func APIKeyPrefix(key string) string {
+	if len(key) < APIKeyPrefixLength {
+		return key
+	}
+	return key[:APIKeyPrefixLength]
 }
 
 // APIKey is an API key for interacting with the system. The API key string
 // is delivered to the client along with an App ID. The API Key acts as a
 // password for the application.
+//
+// Only a one-way hash of the API key is stored, along with a short
+// plaintext prefix used to look up the key.
 type APIKey struct {
-	// key: the unencrypted API key string
+	// key: the plaintext API key string, only known when the key is generated
 	key string
-	// ciphertext: the encrypted API key as []byte
-	ciphertextbytes []byte
+	// prefix: the first APIKeyPrefixLength characters of the API key
+	prefix string
+	// hash: the HMAC-SHA256 hash of the API key
+	hash []byte
 	// deactivation: the date/time the API key is no longer usable
 	deactivation time.Time
 }
 
 // NewAPIKey initializes an APIKey. It generates a random 128-bit (16 byte)
-// base64 encoded string as an API key. The generated key is then encrypted
-// using 256-bit AES-GCM and the encrypted bytes are added to the struct as
-// well.
-func NewAPIKey(g APIKeyGenerator, ek *[32]byte, deactivation time.Time) (APIKey, error) {
+// base64 encoded string as an API key. The generated key is then hashed
+// using HMAC-SHA256 with the server pepper and the hash and lookup prefix
+// are added to the struct as well.
+func NewAPIKey(g APIKeyGenerator, pepper *[32]byte, deactivation time.Time) (APIKey, error) {
 	const (
 		n  int = 16
 		op     = "diygoapi/NewAPIKey"
@@ -161,17 +291,32 @@ func NewAPIKey(g APIKeyGenerator, ek *[32]byte, deactivation time.Time) (APIKey,
 		return APIKey{}, errs.E(op, err)
 	}
 
-	var ctb []byte
-	ctb, err = secure.Encrypt([]byte(k), ek)
+	return APIKey{
+		key:          k,
+		prefix:       APIKeyPrefix(k),
+		hash:         secure.HMAC([]byte(k), pepper),
+		deactivation: deactivation,
+	}, nil
+}
+
+// NewAPIKeyFromHash initializes an APIKey given its lookup prefix and
+// hex encoded hash, as stored in the database.
+func NewAPIKeyFromHash(prefix, hash string) (APIKey, error) {
+	const op errs.Op = "diygoapi/NewAPIKeyFromHash"
+
+	h, err := hex.DecodeString(hash)
 	if err != nil {
-		return APIKey{}, err
+		return APIKey{}, errs.E(op, errs.Internal, err)
 	}
 
-	return APIKey{key: k, ciphertextbytes: ctb, deactivation: deactivation}, nil
+	return APIKey{prefix: prefix, hash: h}, nil
 }
 
 // NewAPIKeyFromCipher initializes an APIKey given a ciphertext string.
-func NewAPIKeyFromCipher(ciphertext string, ek *[32]byte) (APIKey, error) {
+// API keys were previously stored encrypted. NewAPIKeyFromCipher is
+// used to migrate those keys: the key is decrypted and then hashed
+// using the pepper.
+func NewAPIKeyFromCipher(ciphertext string, ek, pepper *[32]byte) (APIKey, error) {
 	const op errs.Op = "diygoapi/NewAPIKeyFromCipher"
 
 	var (
@@ -191,17 +336,27 @@ func NewAPIKeyFromCipher(ciphertext string, ek *[32]byte) (APIKey, error) {
 		return APIKey{}, errs.E(op, err)
 	}
 
-	return APIKey{key: string(apiKey), ciphertextbytes: eak}, nil
+	return APIKey{
+		key:    string(apiKey),
+		prefix: APIKeyPrefix(string(apiKey)),
+		hash:   secure.HMAC(apiKey, pepper),
+	}, nil
 }
 
-// Key returns the key for the API key
+// Key returns the key for the API key. The key is only known when
+// the API key is generated, as only its hash is stored.
 func (a *APIKey) Key() string {
 	return a.key
 }
 
-// Ciphertext returns the hex encoded text of the encrypted cipher bytes for the API key
-func (a *APIKey) Ciphertext() string {
-	return hex.EncodeToString(a.ciphertextbytes)
+// Prefix returns the lookup prefix for the API key
+func (a *APIKey) Prefix() string {
+	return a.prefix
+}
+
+// Hash returns the hex encoded HMAC-SHA256 hash of the API key
+func (a *APIKey) Hash() string {
+	return hex.EncodeToString(a.hash)
 }
 
 // DeactivationDate returns the Deactivation Date for the API key
@@ -232,8 +387,8 @@ func (a *APIKey) SetStringAsDeactivationDate(s string) error {
 func (a *APIKey) validate() error {
 	const op errs.Op = "diygoapi/APIKey.validate"
 
-	if a.ciphertextbytes == nil {
-		return errs.E(op, "ciphertext must have a value")
+	if a.hash == nil {
+		return errs.E(op, "hash must have a value")
 	}
 
 	now := time.Now()
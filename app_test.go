@@ -4,6 +4,7 @@ import (
 	"encoding/base64"
 	"encoding/hex"
 	"fmt"
+	"regexp"
 	"testing"
 	"time"
 
@@ -11,6 +12,7 @@ import (
 	"github.com/google/uuid"
 
 	"github.com/gilcrest/diygoapi"
+	"github.com/gilcrest/diygoapi/errs"
 	"github.com/gilcrest/diygoapi/secure"
 )
 
@@ -118,7 +120,7 @@ func TestApp_ValidateKey(t *testing.T) {
 		err = a.AddKey(key)
 		c.Assert(err, qt.IsNil)
 
-		err = a.ValidateKey("deep in the realm", key.Key())
+		err = a.ValidateKey("deep in the realm", key.Key(), ek)
 		c.Assert(err, qt.IsNil)
 	})
 	t.Run("key does not match", func(t *testing.T) {
@@ -141,7 +143,17 @@ func TestApp_ValidateKey(t *testing.T) {
 			APIKeys:     nil,
 		}
 
-		err := a.ValidateKey("deep in the realm", "badkey")
+		ek, err := secure.NewEncryptionKey()
+		c.Assert(err, qt.IsNil)
+
+		var key diygoapi.APIKey
+		key, err = diygoapi.NewAPIKey(secure.RandomGenerator{}, ek, time.Now().Add(time.Hour*100))
+		c.Assert(err, qt.IsNil)
+
+		err = a.AddKey(key)
+		c.Assert(err, qt.IsNil)
+
+		err = a.ValidateKey("deep in the realm", "badkey", ek)
 		c.Assert(err, qt.ErrorMatches, "Key does not match any keys for the App")
 	})
 	t.Run("key matches but invalid", func(t *testing.T) {
@@ -176,8 +188,8 @@ func TestApp_ValidateKey(t *testing.T) {
 
 		a.APIKeys = append(a.APIKeys, key)
 
-		err = a.ValidateKey("deep in the realm", key.Key())
-		c.Assert(err, qt.ErrorMatches, fmt.Sprintf("Key Deactivation %s is before current time .*", key.DeactivationDate().String()))
+		err = a.ValidateKey("deep in the realm", key.Key(), ek)
+		c.Assert(err, qt.ErrorMatches, fmt.Sprintf("Key Deactivation %s is before current time .*", regexp.QuoteMeta(key.DeactivationDate().String())))
 	})
 }
 
@@ -202,7 +214,7 @@ func TestNewAPIKey(t *testing.T) {
 
 		c.Assert(len(keyBytes), qt.Equals, 16, qt.Commentf("assure key byte length is always 16 (128-bit)"))
 	})
-	t.Run("decrypt key", func(t *testing.T) {
+	t.Run("hash key", func(t *testing.T) {
 		c := qt.New(t)
 		var (
 			ek  *[32]byte
@@ -215,17 +227,94 @@ func TestNewAPIKey(t *testing.T) {
 		key, err = diygoapi.NewAPIKey(secure.RandomGenerator{}, ek, time.Date(2999, 12, 31, 0, 0, 0, 0, time.UTC))
 		c.Assert(err, qt.IsNil)
 
-		// Ciphertext method returns the bytes as a hex encoded string.
-		// decode to get the bytes
-		var cb []byte
-		cb, err = hex.DecodeString(key.Ciphertext())
+		c.Assert(key.Prefix(), qt.Equals, key.Key()[:diygoapi.APIKeyPrefixLength])
+		c.Assert(key.Hash(), qt.Equals, hex.EncodeToString(secure.HMAC([]byte(key.Key()), ek)))
+
+		// the stored hash and prefix are enough to validate the key
+		var stored diygoapi.APIKey
+		stored, err = diygoapi.NewAPIKeyFromHash(key.Prefix(), key.Hash())
+		c.Assert(err, qt.IsNil)
+		c.Assert(stored.Key(), qt.Equals, "", qt.Commentf("plaintext key must not be recoverable"))
+	})
+}
+
+func TestNewAPIKeyFromCipher(t *testing.T) {
+	t.Run("migrate encrypted key", func(t *testing.T) {
+		c := qt.New(t)
+
+		ek, err := secure.NewEncryptionKey()
 		c.Assert(err, qt.IsNil)
 
-		// decrypt the encrypted key
-		var apiKey []byte
-		apiKey, err = secure.Decrypt(cb, ek)
+		const plaintext = "8Sgy4w5HmMXT8M_CCxPviQ=="
+		var cb []byte
+		cb, err = secure.Encrypt([]byte(plaintext), ek)
 		c.Assert(err, qt.IsNil)
 
-		c.Assert(string(apiKey), qt.Equals, key.Key(), qt.Commentf("ensure decrypted key matches key string"))
+		pepper := diygoapi.APIKeyPepper(ek)
+		var key diygoapi.APIKey
+		key, err = diygoapi.NewAPIKeyFromCipher(hex.EncodeToString(cb), ek, pepper)
+		c.Assert(err, qt.IsNil)
+		c.Assert(key.Prefix(), qt.Equals, "8Sgy4w5H")
+		c.Assert(key.Hash(), qt.Equals, hex.EncodeToString(secure.HMAC([]byte(plaintext), pepper)))
+		c.Assert(key.Hash(), qt.Not(qt.Equals), hex.EncodeToString(secure.HMAC([]byte(plaintext), ek)))
 	})
 }
+
+func TestCreateAPIKeyRequest_Validate(t *testing.T) {
+	future := time.Now().Add(24 * time.Hour).Format(time.RFC3339)
+	past := time.Now().Add(-24 * time.Hour).Format(time.RFC3339)
+
+	tests := []struct {
+		name    string
+		r       diygoapi.CreateAPIKeyRequest
+		wantErr bool
+	}{
+		{name: "valid", r: diygoapi.CreateAPIKeyRequest{DeactivationDate: future}},
+		{name: "valid rotation", r: diygoapi.CreateAPIKeyRequest{DeactivationDate: future, RotationGracePeriod: "72h"}},
+		{name: "missing deactivation date", r: diygoapi.CreateAPIKeyRequest{}, wantErr: true},
+		{name: "bad deactivation date", r: diygoapi.CreateAPIKeyRequest{DeactivationDate: "2099-01-01"}, wantErr: true},
+		{name: "past deactivation date", r: diygoapi.CreateAPIKeyRequest{DeactivationDate: past}, wantErr: true},
+		{name: "bad grace period", r: diygoapi.CreateAPIKeyRequest{DeactivationDate: future, RotationGracePeriod: "3 days"}, wantErr: true},
+		{name: "negative grace period", r: diygoapi.CreateAPIKeyRequest{DeactivationDate: future, RotationGracePeriod: "-1h"}, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := qt.New(t)
+
+			err := tt.r.Validate()
+			if tt.wantErr {
+				c.Assert(errs.KindIs(errs.Validation, err), qt.IsTrue)
+				return
+			}
+			c.Assert(err, qt.IsNil)
+		})
+	}
+}
+
+func TestUpdateAppRequest_Validate(t *testing.T) {
+	tests := []struct {
+		name    string
+		r       diygoapi.UpdateAppRequest
+		wantErr bool
+	}{
+		{name: "valid", r: diygoapi.UpdateAppRequest{Name: "Bulletproof", Description: "Bulletproof app"}},
+		{name: "valid provider", r: diygoapi.UpdateAppRequest{Name: "Bulletproof", Description: "Bulletproof app", Oauth2Provider: "google", Oauth2ProviderClientID: "abc.apps.googleusercontent.com"}},
+		{name: "missing name", r: diygoapi.UpdateAppRequest{Description: "Bulletproof app"}, wantErr: true},
+		{name: "missing description", r: diygoapi.UpdateAppRequest{Name: "Bulletproof"}, wantErr: true},
+		{name: "missing client ID", r: diygoapi.UpdateAppRequest{Name: "Bulletproof", Description: "Bulletproof app", Oauth2Provider: "google"}, wantErr: true},
+		{name: "missing provider", r: diygoapi.UpdateAppRequest{Name: "Bulletproof", Description: "Bulletproof app", Oauth2ProviderClientID: "abc"}, wantErr: true},
+		{name: "unknown provider", r: diygoapi.UpdateAppRequest{Name: "Bulletproof", Description: "Bulletproof app", Oauth2Provider: "myspace", Oauth2ProviderClientID: "abc"}, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := qt.New(t)
+
+			err := tt.r.Validate()
+			if tt.wantErr {
+				c.Assert(errs.KindIs(errs.Validation, err), qt.IsTrue)
+				return
+			}
+			c.Assert(err, qt.IsNil)
+		})
+	}
+}
@@ -2,6 +2,7 @@ package diygoapi
 
 import (
 	"context"
+	"fmt"
 	"github.com/rs/zerolog"
 	"net/http"
 	"strings"
@@ -19,12 +20,28 @@ type PermissionServicer interface {
 	Create(ctx context.Context, r *CreatePermissionRequest, adt Audit) (*PermissionResponse, error)
 	FindAll(ctx context.Context) ([]*PermissionResponse, error)
 	Delete(ctx context.Context, extlID string) (DeleteResponse, error)
+	// Sync reconciles the stored permissions against the registered
+	// routes and optionally creates permissions for uncovered routes.
+	Sync(ctx context.Context, r *PermissionSyncRequest) (*PermissionSyncResponse, error)
 }
 
 // RoleServicer allows for creating, updating, reading and deleting a Role
 // as well as assigning permissions and users to it.
 type RoleServicer interface {
 	Create(ctx context.Context, r *CreateRoleRequest, adt Audit) (*RoleResponse, error)
+	Update(ctx context.Context, r *UpdateRoleRequest, adt Audit) (*RoleResponse, error)
+	Delete(ctx context.Context, extlID string) (DeleteResponse, error)
+	FindAll(ctx context.Context) ([]*RoleResponse, error)
+	FindByExternalID(ctx context.Context, extlID string) (*RoleResponse, error)
+	AddPermissions(ctx context.Context, r *RolePermissionsRequest, adt Audit) (*RoleResponse, error)
+	RemovePermissions(ctx context.Context, r *RolePermissionsRequest, adt Audit) (*RoleResponse, error)
+	AddParent(ctx context.Context, r *RoleParentRequest, adt Audit) (*RoleResponse, error)
+	RemoveParent(ctx context.Context, r *RoleParentRequest) (*RoleResponse, error)
+	AssignUserRole(ctx context.Context, r *UserRoleRequest, adt Audit) (*UserRoleResponse, error)
+	RevokeUserRole(ctx context.Context, r *UserRoleRequest, adt Audit) (DeleteResponse, error)
+	FindUsersByOrgRole(ctx context.Context, orgExtlID, roleCd string) ([]*UserRoleResponse, error)
+	FindExpiredUserRoles(ctx context.Context) ([]*UserRoleResponse, error)
+	PurgeExpiredUserRoles(ctx context.Context) (UserRolePurgeResponse, error)
 }
 
 // AuthenticationServicer represents a service for managing authentication.
@@ -41,11 +58,6 @@ type RoleServicer interface {
 // The latter is likely the more common use case.
 type AuthenticationServicer interface {
 
-	// SelfRegister is used for first-time registration of a Person/User
-	// in the system (associated with an Organization). This is "self
-	// registration" as opposed to one person registering another person.
-	SelfRegister(ctx context.Context, params AuthenticationParams) (auth Auth, err error)
-
 	// FindAuth looks up a User given a Provider and Access Token.
 	// If a User is not found, an error is returned.
 	FindAuth(ctx context.Context, params AuthenticationParams) (Auth, error)
@@ -58,11 +70,195 @@ type AuthenticationServicer interface {
 	// if the given API key is a valid key for it. It is used as part of
 	// app authentication.
 	FindAppByAPIKey(ctx context.Context, realm, appExtlID, key string) (*App, error)
+
+	// FindUserOrg finds an Org given its External ID and determines if
+	// the User is a member of it. It is used to validate the Org a User
+	// selects to act in for a request.
+	FindUserOrg(ctx context.Context, realm string, u *User, orgExtlID string) (*Org, error)
 }
 
 // AuthorizationServicer represents a service for managing authorization.
 type AuthorizationServicer interface {
 	Authorize(r *http.Request, lgr zerolog.Logger, adt Audit) error
+
+	// FindEffectivePermissions returns the permissions the audit user
+	// holds in the org they are acting in, including inherited permissions.
+	FindEffectivePermissions(ctx context.Context, adt Audit) ([]*EffectivePermissionResponse, error)
+
+	// Check determines, for each resource and operation, whether the
+	// audit user is authorized in the org they are acting in.
+	Check(ctx context.Context, r *AuthorizationCheckRequest, adt Audit) ([]*AuthorizationCheckResponse, error)
+
+	// FindDecisions searches the authorization decisions recorded
+	// for the org the audit user is acting in.
+	FindDecisions(ctx context.Context, r *AuthzDecisionSearchRequest, adt Audit) ([]*AuthzDecisionResponse, error)
+}
+
+// MaxAuthorizationChecks is the maximum number of checks allowed in
+// a single AuthorizationCheckRequest
+const MaxAuthorizationChecks = 100
+
+const (
+	// SysAdminRoleCode is the code of the system administrator role
+	// created during Genesis. A user holding it in the Principal org
+	// may modify any resource.
+	SysAdminRoleCode string = "sysAdmin"
+	// OrgAdminRoleCode is the code of the org administrator role.
+	// A user holding it in an org may modify any resource owned by
+	// that org.
+	OrgAdminRoleCode string = "orgAdmin"
+)
+
+// Owner identifies who owns a resource. It is used to layer
+// ownership checks on top of route level authorization, e.g. only
+// the user who created a movie or an org admin may update it.
+type Owner struct {
+	// OrgID is the unique ID of the Org which owns the resource.
+	OrgID uuid.UUID
+	// UserID is the unique ID of the User who created the
+	// resource, if it was created by a User.
+	UserID uuid.NullUUID
+}
+
+// CreatedBy reports whether the resource was created by User u.
+func (o Owner) CreatedBy(u *User) bool {
+	return u != nil && o.UserID.Valid && o.UserID.UUID == u.ID
+}
+
+// EffectivePermissionResponse is the response struct for a permission
+// held by a user and the role which grants or denies it.
+type EffectivePermissionResponse struct {
+	// The resource (or resource pattern) the permission is for.
+	Resource string `json:"resource"`
+	// The operation (or wildcard) the permission is for.
+	Operation string `json:"operation"`
+	// The code of the role granting or denying the permission.
+	RoleCode string `json:"role_cd"`
+	// The effect of the permission (allow or deny). A deny takes
+	// precedence over any allow matching the same request.
+	Effect string `json:"effect"`
+}
+
+// AuthorizationCheck is a single resource and operation to be checked
+type AuthorizationCheck struct {
+	// A human-readable string which represents a resource (e.g. an HTTP route or document, etc.).
+	Resource string `json:"resource"`
+	// A string representing the action taken on the resource (e.g. POST, GET, edit, etc.)
+	Operation string `json:"operation"`
+}
+
+// AuthorizationCheckRequest is the request struct for checking a
+// list of resources and operations for the current user
+type AuthorizationCheckRequest struct {
+	Checks []AuthorizationCheck `json:"checks"`
+}
+
+// Validate determines whether the AuthorizationCheckRequest has proper data to be considered valid
+func (r AuthorizationCheckRequest) Validate() error {
+	const op errs.Op = "diygoapi/AuthorizationCheckRequest.Validate"
+
+	switch {
+	case len(r.Checks) == 0:
+		return errs.E(op, errs.Validation, "at least one check is required")
+	case len(r.Checks) > MaxAuthorizationChecks:
+		return errs.E(op, errs.Validation, fmt.Sprintf("no more than %d checks are allowed per request", MaxAuthorizationChecks))
+	}
+
+	for _, c := range r.Checks {
+		if c.Resource == "" || c.Operation == "" {
+			return errs.E(op, errs.Validation, "resource and operation are required for each check")
+		}
+	}
+
+	return nil
+}
+
+// AuthorizationCheckResponse is the response struct for a single
+// authorization check
+type AuthorizationCheckResponse struct {
+	// The resource checked.
+	Resource string `json:"resource"`
+	// The operation checked.
+	Operation string `json:"operation"`
+	// Allowed is true if the user is authorized for the resource and operation.
+	Allowed bool `json:"allowed"`
+	// The code of the role granting access, empty if not allowed.
+	RoleCode string `json:"role_cd,omitempty"`
+	// The code of the role explicitly denying access, empty if access
+	// is not explicitly denied.
+	DeniedByRoleCode string `json:"denied_by_role_cd,omitempty"`
+}
+
+const (
+	// AuthzDecisionAllow is the decision recorded when a request is authorized
+	AuthzDecisionAllow string = "allow"
+	// AuthzDecisionDeny is the decision recorded when a request is not authorized
+	AuthzDecisionDeny string = "deny"
+)
+
+// MaxAuthzDecisionSearchLimit is the maximum number of decisions
+// returned by a single AuthzDecisionSearchRequest
+const MaxAuthzDecisionSearchLimit = 1000
+
+// AuthzDecisionSearchRequest is the request struct for searching the
+// recorded authorization decisions
+type AuthzDecisionSearchRequest struct {
+	// Unique External ID of the user. If empty, the decisions of
+	// all users are searched.
+	UserExternalID string
+	// The start of the time range searched (inclusive).
+	From time.Time
+	// The end of the time range searched (exclusive).
+	Until time.Time
+	// The decision searched for (allow or deny). If empty, both
+	// are searched.
+	Decision string
+	// The maximum number of decisions returned, most recent first.
+	Limit int
+}
+
+// Validate determines whether the AuthzDecisionSearchRequest has proper data to be considered valid
+func (r AuthzDecisionSearchRequest) Validate() error {
+	const op errs.Op = "diygoapi/AuthzDecisionSearchRequest.Validate"
+
+	switch {
+	case r.From.IsZero() || r.Until.IsZero():
+		return errs.E(op, errs.Validation, "from and until are required")
+	case !r.From.Before(r.Until):
+		return errs.E(op, errs.Validation, "from must be before until")
+	case r.Decision != "" && r.Decision != AuthzDecisionAllow && r.Decision != AuthzDecisionDeny:
+		return errs.E(op, errs.Validation, fmt.Sprintf("decision must be %s or %s", AuthzDecisionAllow, AuthzDecisionDeny))
+	case r.Limit < 1 || r.Limit > MaxAuthzDecisionSearchLimit:
+		return errs.E(op, errs.Validation, fmt.Sprintf("limit must be between 1 and %d", MaxAuthzDecisionSearchLimit))
+	}
+
+	return nil
+}
+
+// AuthzDecisionResponse is the response struct for a recorded
+// authorization decision
+type AuthzDecisionResponse struct {
+	// The unique ID of the decision.
+	ID string `json:"id"`
+	// Unique External ID of the user the decision was made for.
+	UserExternalID string `json:"user_external_id"`
+	// Unique External ID of the app the request was made through.
+	AppExternalID string `json:"app_external_id"`
+	// Unique External ID of the org the user was acting in.
+	OrgExternalID string `json:"org_external_id"`
+	// The resource requested.
+	Resource string `json:"resource"`
+	// The operation requested.
+	Operation string `json:"operation"`
+	// The decision made (allow or deny).
+	Decision string `json:"decision"`
+	// The code of the role whose permission allowed the request or
+	// explicitly denied it, empty if no permission matched.
+	RoleCode string `json:"role_cd,omitempty"`
+	// The ID of the request the decision was made for.
+	RequestID string `json:"request_id,omitempty"`
+	// The moment the decision was made.
+	Timestamp time.Time `json:"timestamp"`
 }
 
 // TokenExchanger exchanges an oauth2.Token for a ProviderUserInfo
@@ -74,9 +270,15 @@ type TokenExchanger interface {
 // BearerTokenType is used in authorization to access a resource
 const BearerTokenType string = "Bearer"
 
+// NonceTokenExtraKey is the oauth2.Token Extra key used to carry the
+// nonce a client sent with its authentication request. The nonce is
+// compared against the nonce claim of an OpenID Connect ID token.
+const NonceTokenExtraKey string = "nonce"
+
 // Provider defines the provider of authorization (Google, Github, Apple, auth0, etc.).
 //
-// Only Google is used currently.
+// Google, GitHub and generic OpenID Connect (OIDC) providers
+// (Okta, Auth0, Keycloak, Dex, etc.) are supported currently.
 type Provider uint8
 
 // Provider of authorization
@@ -85,12 +287,18 @@ type Provider uint8
 const (
 	UnknownProvider Provider = iota
 	Google                   // Google
+	GitHub                   // GitHub
+	OIDC                     // Generic OpenID Connect
 )
 
 func (p Provider) String() string {
 	switch p {
 	case Google:
 		return "google"
+	case GitHub:
+		return "github"
+	case OIDC:
+		return "oidc"
 	}
 	return "unknown_provider"
 }
@@ -100,6 +308,10 @@ func ParseProvider(s string) Provider {
 	switch strings.ToLower(s) {
 	case "google":
 		return Google
+	case "github":
+		return GitHub
+	case "oidc":
+		return OIDC
 	}
 	return UnknownProvider
 }
@@ -129,7 +341,7 @@ type ProviderTokenInfo struct {
 }
 
 // ProviderUserInfo contains common fields from the various Oauth2 providers.
-// Currently only using Google, so looks a lot like Google's.
+// Google was the first provider used, so it looks a lot like Google's.
 type ProviderUserInfo struct {
 	// ID: The obfuscated ID of the user assigned by the authentication provider.
 	ExternalID string
@@ -137,6 +349,11 @@ type ProviderUserInfo struct {
 	// Email: The user's email address.
 	Email string
 
+	// EmailVerified: Whether the provider has verified that the user
+	// owns Email. An unverified email must not be used to match the
+	// user to an existing account, invitation or domain.
+	EmailVerified bool
+
 	// NamePrefix: The name prefix for the Profile (e.g. Mx., Ms., Mr., etc.)
 	NamePrefix string
 
@@ -205,7 +422,19 @@ type Auth struct {
 	Token *oauth2.Token
 }
 
+// PermissionWildcard matches any operation when used as a Permission
+// Operation and any resource when used as the entire Permission Resource.
+// When used as the final path segment of a Resource (e.g. /api/v1/movies/*),
+// it matches the parent path and every path beneath it.
+const PermissionWildcard string = "*"
+
 // Permission stores an approval of a mode of access to a resource.
+//
+// A Permission Resource and Operation may be patterns which use
+// PermissionWildcard. When more than one Permission matches a
+// request, the most specific one is used: an exact resource beats
+// a pattern, a longer pattern beats a shorter one and an exact
+// operation beats a wildcard operation.
 type Permission struct {
 	// ID is the unique ID for the Permission.
 	ID uuid.UUID
@@ -232,12 +461,129 @@ func (p Permission) Validate() error {
 		return errs.E(op, errs.Validation, "External ID is required")
 	case p.Resource == "":
 		return errs.E(op, errs.Validation, "Resource is required")
+	case p.Operation == "":
+		return errs.E(op, errs.Validation, "Operation is required")
 	case p.Description == "":
 		return errs.E(op, errs.Validation, "Description is required")
 	}
+
+	if strings.Contains(p.Resource, PermissionWildcard) && p.Resource != PermissionWildcard {
+		if !strings.HasSuffix(p.Resource, "/"+PermissionWildcard) || strings.Count(p.Resource, PermissionWildcard) != 1 {
+			return errs.E(op, errs.Validation, "Resource wildcard is only allowed as the entire resource or as the final path segment (e.g. /api/v1/movies/*)")
+		}
+	}
+
+	if strings.Contains(p.Operation, PermissionWildcard) && p.Operation != PermissionWildcard {
+		return errs.E(op, errs.Validation, "Operation wildcard must be the entire operation")
+	}
+
 	return nil
 }
 
+// Matches reports whether the Permission grants access to the
+// given resource and operation, taking wildcards into account.
+// It does not consider whether the Permission is active.
+func (p Permission) Matches(resource, operation string) bool {
+	if p.Operation != PermissionWildcard && p.Operation != operation {
+		return false
+	}
+
+	switch {
+	case p.Resource == resource, p.Resource == PermissionWildcard:
+		return true
+	case strings.HasSuffix(p.Resource, "/"+PermissionWildcard):
+		// prefix includes the trailing slash, so /api/v1/movies/*
+		// does not match /api/v1/moviesXYZ
+		prefix := strings.TrimSuffix(p.Resource, PermissionWildcard)
+		return resource == strings.TrimSuffix(prefix, "/") || strings.HasPrefix(resource, prefix)
+	}
+
+	return false
+}
+
+// MoreSpecific reports whether Permission p is more specific than
+// Permission q. Both are assumed to match the same request.
+func (p Permission) MoreSpecific(q Permission) bool {
+	pExact, qExact := !strings.Contains(p.Resource, PermissionWildcard), !strings.Contains(q.Resource, PermissionWildcard)
+	if pExact != qExact {
+		return pExact
+	}
+	if len(p.Resource) != len(q.Resource) {
+		return len(p.Resource) > len(q.Resource)
+	}
+	return p.Operation != PermissionWildcard && q.Operation == PermissionWildcard
+}
+
+// Route is an HTTP route registered with the server.
+type Route struct {
+	// Resource is the path template of the route (e.g. /api/v1/movies/{extlID}).
+	Resource string
+	// Operation is the HTTP method of the route (e.g. GET).
+	Operation string
+	// RequiresAuthorization is true when the route authorizes the
+	// user against a Permission before calling its handler.
+	RequiresAuthorization bool
+}
+
+// ReconcilePermissions compares routes with permissions. Missing
+// holds the routes which require authorization, but are not matched
+// by any permission. Orphaned holds the permissions which match no
+// route at all (e.g. a permission for a path template which has
+// since been changed).
+func ReconcilePermissions(routes []Route, permissions []Permission) (missing []Route, orphaned []Permission) {
+	for _, r := range routes {
+		if !r.RequiresAuthorization {
+			continue
+		}
+		var found bool
+		for _, p := range permissions {
+			if p.Matches(r.Resource, r.Operation) {
+				found = true
+				break
+			}
+		}
+		if !found {
+			missing = append(missing, r)
+		}
+	}
+
+	for _, p := range permissions {
+		var found bool
+		for _, r := range routes {
+			if p.Matches(r.Resource, r.Operation) {
+				found = true
+				break
+			}
+		}
+		if !found {
+			orphaned = append(orphaned, p)
+		}
+	}
+
+	return missing, orphaned
+}
+
+// PermissionSyncRequest is the request struct for synchronizing
+// permissions with the registered routes.
+type PermissionSyncRequest struct {
+	// Routes are the routes registered with the server.
+	Routes []Route
+	// Upsert determines whether a permission is created for each
+	// missing route (true) or the missing routes are only reported (false).
+	Upsert bool
+}
+
+// PermissionSyncResponse is the response struct for synchronizing
+// permissions with the registered routes.
+type PermissionSyncResponse struct {
+	// Missing are the routes requiring authorization which no permission matches.
+	Missing []Route
+	// Orphaned are the permissions which match no route.
+	Orphaned []*PermissionResponse
+	// Created are the permissions created for missing routes.
+	Created []*PermissionResponse
+}
+
 // CreatePermissionRequest is the request struct for creating a permission
 type CreatePermissionRequest struct {
 	// A human-readable string which represents a resource (e.g. an HTTP route or document, etc.).
@@ -274,6 +620,14 @@ type PermissionResponse struct {
 	Active bool `json:"active"`
 }
 
+const (
+	// PermissionEffectAllow is the effect of a permission a role allows
+	PermissionEffectAllow string = "allow"
+	// PermissionEffectDeny is the effect of a permission a role denies.
+	// A deny takes precedence over any allow.
+	PermissionEffectDeny string = "deny"
+)
+
 // Role is a job function or title which defines an authority level.
 type Role struct {
 	// The unique ID for the Role.
@@ -288,6 +642,13 @@ type Role struct {
 	Active bool
 	// Permissions is the list of permissions allowed for the role.
 	Permissions []*Permission
+	// DeniedPermissions is the list of permissions explicitly denied
+	// for the role. A deny takes precedence over any allow, whether
+	// given by this role, an ancestor or another role of the user.
+	DeniedPermissions []*Permission
+	// Parents is the list of roles this role directly inherits from.
+	// A role inherits all permissions of its ancestors.
+	Parents []*Role
 }
 
 // Validate determines if the Role is valid.
@@ -304,6 +665,15 @@ func (r Role) Validate() error {
 	case r.Description == "":
 		return errs.E(op, errs.Validation, "Description is required")
 	}
+
+	for _, d := range r.DeniedPermissions {
+		for _, p := range r.Permissions {
+			if p.ID == d.ID {
+				return errs.E(op, errs.Validation, fmt.Sprintf("permission %s %s cannot be both allowed and denied", d.Operation, d.Resource))
+			}
+		}
+	}
+
 	return nil
 }
 
@@ -317,6 +687,51 @@ type CreateRoleRequest struct {
 	Active bool `json:"active"`
 	// The list of permissions to be given to the role
 	Permissions []*FindPermissionRequest
+	// The list of permissions to be explicitly denied for the role
+	DeniedPermissions []*FindPermissionRequest `json:"denied_permissions"`
+	// The codes of the roles this role inherits permissions from
+	ParentRoles []string `json:"parent_roles"`
+}
+
+// UpdateRoleRequest is the request struct for updating a role
+type UpdateRoleRequest struct {
+	// Unique External ID of the role to be updated.
+	ExternalID string
+	// A human-readable code which represents the role.
+	Code string `json:"role_cd"`
+	// A longer description of the role.
+	Description string `json:"role_description"`
+	// A boolean denoting whether the role is active (true) or not (false).
+	// An inactive role no longer grants any of its permissions.
+	Active bool `json:"active"`
+}
+
+// RolePermissionsRequest is the request struct for adding permissions
+// to or removing permissions from a role
+type RolePermissionsRequest struct {
+	// Unique External ID of the role.
+	RoleExternalID string
+	// The list of permissions to be added to or removed from the role
+	Permissions []*FindPermissionRequest `json:"permissions"`
+	// The effect (allow or deny) the permissions are added with. If
+	// empty, the permissions are allowed. A permission already added
+	// with the other effect is switched. Ignored on removal, where
+	// the permissions are removed whatever their effect.
+	Effect string `json:"effect"`
+}
+
+// Validate determines if the RolePermissionsRequest is valid.
+func (r RolePermissionsRequest) Validate() error {
+	const op errs.Op = "diygoapi/RolePermissionsRequest.Validate"
+
+	switch {
+	case len(r.Permissions) == 0:
+		return errs.E(op, errs.Validation, "at least one permission is required")
+	case r.Effect != "" && r.Effect != PermissionEffectAllow && r.Effect != PermissionEffectDeny:
+		return errs.E(op, errs.Validation, fmt.Sprintf("effect must be %s or %s", PermissionEffectAllow, PermissionEffectDeny))
+	}
+
+	return nil
 }
 
 // RoleResponse is the response struct for a Role.
@@ -331,6 +746,82 @@ type RoleResponse struct {
 	Active bool `json:"active"`
 	// Permissions is the list of permissions allowed for the role.
 	Permissions []*Permission
+	// DeniedPermissions is the list of permissions explicitly denied
+	// for the role.
+	DeniedPermissions []*Permission `json:"denied_permissions"`
+	// ParentRoles is the list of codes of the roles this role directly
+	// inherits permissions from.
+	ParentRoles []string `json:"parent_roles"`
+}
+
+// RoleParentRequest is the request struct for adding a parent role to
+// or removing a parent role from a role
+type RoleParentRequest struct {
+	// Unique External ID of the role.
+	RoleExternalID string
+	// The code of the parent role.
+	ParentRoleCode string
+}
+
+// UserRoleRequest is the request struct for assigning a role to or
+// revoking a role from a user within an organization
+type UserRoleRequest struct {
+	// Unique External ID of the organization.
+	OrgExternalID string
+	// Unique External ID of the user.
+	UserExternalID string
+	// The code of the role being assigned or revoked.
+	RoleCode string
+	// The moment the role grant becomes effective. If nil, the grant
+	// is effective immediately.
+	ValidFrom *time.Time `json:"valid_from"`
+	// The moment the role grant expires. If nil, the grant does not
+	// expire.
+	ValidUntil *time.Time `json:"valid_until"`
+}
+
+// Validate determines whether the UserRoleRequest has a proper
+// validity window to be considered valid
+func (r UserRoleRequest) Validate() error {
+	const op errs.Op = "diygoapi/UserRoleRequest.Validate"
+
+	if r.ValidFrom != nil && r.ValidUntil != nil && !r.ValidFrom.Before(*r.ValidUntil) {
+		return errs.E(op, errs.Validation, "valid_from must be before valid_until")
+	}
+
+	if r.ValidUntil != nil && !r.ValidUntil.After(time.Now()) {
+		return errs.E(op, errs.Validation, "valid_until must be in the future")
+	}
+
+	return nil
+}
+
+// UserRoleResponse is the response struct for a user's role within an
+// organization
+type UserRoleResponse struct {
+	// Unique External ID of the organization.
+	OrgExternalID string `json:"org_external_id"`
+	// Unique External ID of the user.
+	UserExternalID string `json:"user_external_id"`
+	// The user's email.
+	Email string `json:"email"`
+	// The user's first name.
+	FirstName string `json:"first_name"`
+	// The user's last name.
+	LastName string `json:"last_name"`
+	// The code of the role the user has within the organization.
+	RoleCode string `json:"role_cd"`
+	// The moment the role grant becomes effective, if bounded.
+	ValidFrom *time.Time `json:"valid_from,omitempty"`
+	// The moment the role grant expires, if bounded.
+	ValidUntil *time.Time `json:"valid_until,omitempty"`
+}
+
+// UserRolePurgeResponse is the response struct for a purge of the
+// expired role grants
+type UserRolePurgeResponse struct {
+	// The number of role grants deleted.
+	Purged int64 `json:"purged"`
 }
 
 // AuthenticationParams is the parameters needed for authenticating a User.
@@ -341,4 +832,7 @@ type AuthenticationParams struct {
 	Provider Provider
 	// Token is the authentication token sent as part of Oauth2.
 	Token *oauth2.Token
+	// InvitationToken is an optional Org invitation token. If given,
+	// the invitation is accepted for the authenticated User.
+	InvitationToken string
 }
@@ -1,10 +1,15 @@
 package diygoapi_test
 
 import (
-	"github.com/gilcrest/diygoapi"
 	"testing"
+	"time"
 
 	qt "github.com/frankban/quicktest"
+	"github.com/google/uuid"
+
+	"github.com/gilcrest/diygoapi"
+	"github.com/gilcrest/diygoapi/errs"
+	"github.com/gilcrest/diygoapi/secure"
 )
 
 func TestNewProvider(t *testing.T) {
@@ -13,6 +18,16 @@ func TestNewProvider(t *testing.T) {
 		p := diygoapi.ParseProvider("GoOgLe")
 		c.Assert(p, qt.Equals, diygoapi.Google)
 	})
+	t.Run("github", func(t *testing.T) {
+		c := qt.New(t)
+		p := diygoapi.ParseProvider("GitHub")
+		c.Assert(p, qt.Equals, diygoapi.GitHub)
+	})
+	t.Run("oidc", func(t *testing.T) {
+		c := qt.New(t)
+		p := diygoapi.ParseProvider("OIDC")
+		c.Assert(p, qt.Equals, diygoapi.OIDC)
+	})
 	t.Run("unknown", func(t *testing.T) {
 		c := qt.New(t)
 		p := diygoapi.ParseProvider("anything else!")
@@ -27,6 +42,12 @@ func TestProvider_String(t *testing.T) {
 		provider := p.String()
 		c.Assert(provider, qt.Equals, "google")
 	})
+	t.Run("github", func(t *testing.T) {
+		c := qt.New(t)
+		p := diygoapi.ParseProvider("GITHUB")
+		provider := p.String()
+		c.Assert(provider, qt.Equals, "github")
+	})
 	t.Run("unknown", func(t *testing.T) {
 		c := qt.New(t)
 		p := diygoapi.ParseProvider("anything else")
@@ -34,3 +55,209 @@ func TestProvider_String(t *testing.T) {
 		c.Assert(provider, qt.Equals, "unknown_provider")
 	})
 }
+
+func TestPermission_Matches(t *testing.T) {
+	tests := []struct {
+		name      string
+		p         diygoapi.Permission
+		resource  string
+		operation string
+		want      bool
+	}{
+		{"exact", diygoapi.Permission{Resource: "/api/v1/movies", Operation: "GET"}, "/api/v1/movies", "GET", true},
+		{"exact wrong operation", diygoapi.Permission{Resource: "/api/v1/movies", Operation: "GET"}, "/api/v1/movies", "POST", false},
+		{"exact wrong resource", diygoapi.Permission{Resource: "/api/v1/movies", Operation: "GET"}, "/api/v1/orgs", "GET", false},
+		{"operation wildcard", diygoapi.Permission{Resource: "/api/v1/movies", Operation: "*"}, "/api/v1/movies", "DELETE", true},
+		{"tree root", diygoapi.Permission{Resource: "/api/v1/movies/*", Operation: "GET"}, "/api/v1/movies", "GET", true},
+		{"tree child", diygoapi.Permission{Resource: "/api/v1/movies/*", Operation: "GET"}, "/api/v1/movies/{extlID}", "GET", true},
+		{"tree grandchild", diygoapi.Permission{Resource: "/api/v1/orgs/*", Operation: "*"}, "/api/v1/orgs/{extlID}/users/{userExtlID}/roles/{roleCd}", "PUT", true},
+		{"tree sibling prefix", diygoapi.Permission{Resource: "/api/v1/movies/*", Operation: "GET"}, "/api/v1/moviesXYZ", "GET", false},
+		{"everything", diygoapi.Permission{Resource: "*", Operation: "*"}, "/api/v1/anything", "PATCH", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := qt.New(t)
+			c.Assert(tt.p.Matches(tt.resource, tt.operation), qt.Equals, tt.want)
+		})
+	}
+}
+
+func TestPermission_MoreSpecific(t *testing.T) {
+	c := qt.New(t)
+
+	exact := diygoapi.Permission{Resource: "/api/v1/movies/{extlID}", Operation: "GET"}
+	exactAnyOp := diygoapi.Permission{Resource: "/api/v1/movies/{extlID}", Operation: "*"}
+	tree := diygoapi.Permission{Resource: "/api/v1/movies/*", Operation: "GET"}
+	wideTree := diygoapi.Permission{Resource: "/api/v1/*", Operation: "GET"}
+	everything := diygoapi.Permission{Resource: "*", Operation: "*"}
+
+	c.Assert(exact.MoreSpecific(exactAnyOp), qt.IsTrue)
+	c.Assert(exactAnyOp.MoreSpecific(tree), qt.IsTrue)
+	c.Assert(tree.MoreSpecific(wideTree), qt.IsTrue)
+	c.Assert(wideTree.MoreSpecific(everything), qt.IsTrue)
+	c.Assert(everything.MoreSpecific(exact), qt.IsFalse)
+	c.Assert(exact.MoreSpecific(exact), qt.IsFalse)
+}
+
+func TestPermission_Validate(t *testing.T) {
+	valid := func(resource, operation string) diygoapi.Permission {
+		return diygoapi.Permission{
+			ID:          uuid.New(),
+			ExternalID:  secure.NewID(),
+			Resource:    resource,
+			Operation:   operation,
+			Description: "test permission",
+		}
+	}
+
+	tests := []struct {
+		name    string
+		p       diygoapi.Permission
+		wantErr bool
+	}{
+		{"exact", valid("/api/v1/movies", "GET"), false},
+		{"tree", valid("/api/v1/movies/*", "*"), false},
+		{"everything", valid("*", "*"), false},
+		{"missing operation", valid("/api/v1/movies", ""), true},
+		{"wildcard mid path", valid("/api/v1/*/movies", "GET"), true},
+		{"partial segment wildcard", valid("/api/v1/mov*", "GET"), true},
+		{"partial operation wildcard", valid("/api/v1/movies", "G*"), true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := qt.New(t)
+			err := tt.p.Validate()
+			if tt.wantErr {
+				c.Assert(errs.KindIs(errs.Validation, err), qt.IsTrue)
+				return
+			}
+			c.Assert(err, qt.IsNil)
+		})
+	}
+}
+
+func TestReconcilePermissions(t *testing.T) {
+	c := qt.New(t)
+
+	routes := []diygoapi.Route{
+		{Resource: "/api/v1/movies", Operation: "GET", RequiresAuthorization: true},
+		{Resource: "/api/v1/movies/{extlID}", Operation: "GET", RequiresAuthorization: true},
+		{Resource: "/api/v1/orgs/{extlID}", Operation: "PUT", RequiresAuthorization: true},
+		{Resource: "/api/v1/permissions", Operation: "GET", RequiresAuthorization: false},
+	}
+	permissions := []diygoapi.Permission{
+		{Resource: "/api/v1/movies/*", Operation: "GET"},
+		{Resource: "/api/v1/orgs", Operation: "PUT"},
+		{Resource: "/api/v1/permissions", Operation: "GET"},
+	}
+
+	missing, orphaned := diygoapi.ReconcilePermissions(routes, permissions)
+	c.Assert(missing, qt.DeepEquals, []diygoapi.Route{routes[2]})
+	c.Assert(orphaned, qt.DeepEquals, []diygoapi.Permission{permissions[1]})
+
+	missing, orphaned = diygoapi.ReconcilePermissions(routes, []diygoapi.Permission{{Resource: "*", Operation: "*"}})
+	c.Assert(missing, qt.IsNil)
+	c.Assert(orphaned, qt.IsNil)
+}
+
+func TestOwner_CreatedBy(t *testing.T) {
+	c := qt.New(t)
+
+	u := &diygoapi.User{ID: uuid.New()}
+
+	c.Assert(diygoapi.Owner{UserID: uuid.NullUUID{UUID: u.ID, Valid: true}}.CreatedBy(u), qt.IsTrue)
+	c.Assert(diygoapi.Owner{UserID: uuid.NullUUID{UUID: uuid.New(), Valid: true}}.CreatedBy(u), qt.IsFalse)
+	c.Assert(diygoapi.Owner{}.CreatedBy(&diygoapi.User{}), qt.IsFalse)
+	c.Assert(diygoapi.Owner{UserID: uuid.NullUUID{UUID: u.ID, Valid: true}}.CreatedBy(nil), qt.IsFalse)
+}
+
+func TestAuthorizationCheckRequest_Validate(t *testing.T) {
+	c := qt.New(t)
+
+	r := diygoapi.AuthorizationCheckRequest{Checks: []diygoapi.AuthorizationCheck{{Resource: "/api/v1/movies", Operation: "GET"}}}
+	c.Assert(r.Validate(), qt.IsNil)
+
+	c.Assert(errs.KindIs(errs.Validation, diygoapi.AuthorizationCheckRequest{}.Validate()), qt.IsTrue)
+
+	r = diygoapi.AuthorizationCheckRequest{Checks: []diygoapi.AuthorizationCheck{{Resource: "/api/v1/movies"}}}
+	c.Assert(errs.KindIs(errs.Validation, r.Validate()), qt.IsTrue)
+
+	r = diygoapi.AuthorizationCheckRequest{Checks: make([]diygoapi.AuthorizationCheck, diygoapi.MaxAuthorizationChecks+1)}
+	for i := range r.Checks {
+		r.Checks[i] = diygoapi.AuthorizationCheck{Resource: "/api/v1/movies", Operation: "GET"}
+	}
+	c.Assert(errs.KindIs(errs.Validation, r.Validate()), qt.IsTrue)
+}
+
+func TestUserRoleRequest_Validate(t *testing.T) {
+	c := qt.New(t)
+
+	from := time.Now().Add(24 * time.Hour)
+	until := from.Add(14 * 24 * time.Hour)
+	past := time.Now().Add(-time.Hour)
+
+	c.Assert(diygoapi.UserRoleRequest{}.Validate(), qt.IsNil)
+	c.Assert(diygoapi.UserRoleRequest{ValidFrom: &from}.Validate(), qt.IsNil)
+	c.Assert(diygoapi.UserRoleRequest{ValidUntil: &until}.Validate(), qt.IsNil)
+	c.Assert(diygoapi.UserRoleRequest{ValidFrom: &from, ValidUntil: &until}.Validate(), qt.IsNil)
+
+	c.Assert(errs.KindIs(errs.Validation, diygoapi.UserRoleRequest{ValidFrom: &until, ValidUntil: &from}.Validate()), qt.IsTrue)
+	c.Assert(errs.KindIs(errs.Validation, diygoapi.UserRoleRequest{ValidFrom: &from, ValidUntil: &from}.Validate()), qt.IsTrue)
+	c.Assert(errs.KindIs(errs.Validation, diygoapi.UserRoleRequest{ValidUntil: &past}.Validate()), qt.IsTrue)
+}
+
+func TestAuthzDecisionSearchRequest_Validate(t *testing.T) {
+	c := qt.New(t)
+
+	until := time.Date(2026, 1, 2, 0, 0, 0, 0, time.UTC)
+	from := until.Add(-24 * time.Hour)
+
+	r := diygoapi.AuthzDecisionSearchRequest{From: from, Until: until, Limit: 100}
+	c.Assert(r.Validate(), qt.IsNil)
+	r.Decision = diygoapi.AuthzDecisionDeny
+	c.Assert(r.Validate(), qt.IsNil)
+
+	invalid := []diygoapi.AuthzDecisionSearchRequest{
+		{Until: until, Limit: 100},
+		{From: until, Until: from, Limit: 100},
+		{From: from, Until: until, Decision: "maybe", Limit: 100},
+		{From: from, Until: until},
+		{From: from, Until: until, Limit: diygoapi.MaxAuthzDecisionSearchLimit + 1},
+	}
+	for _, r := range invalid {
+		c.Assert(errs.KindIs(errs.Validation, r.Validate()), qt.IsTrue)
+	}
+}
+
+func TestRole_Validate_deniedPermissions(t *testing.T) {
+	c := qt.New(t)
+
+	get := &diygoapi.Permission{ID: uuid.New(), Resource: "/api/v1/orgs/*", Operation: "GET"}
+	del := &diygoapi.Permission{ID: uuid.New(), Resource: "/api/v1/orgs/*", Operation: "DELETE"}
+
+	r := diygoapi.Role{
+		ID:                uuid.New(),
+		ExternalID:        secure.NewID(),
+		Code:              "support",
+		Description:       "Support staff",
+		Permissions:       []*diygoapi.Permission{get},
+		DeniedPermissions: []*diygoapi.Permission{del},
+	}
+	c.Assert(r.Validate(), qt.IsNil)
+
+	r.DeniedPermissions = append(r.DeniedPermissions, get)
+	c.Assert(errs.KindIs(errs.Validation, r.Validate()), qt.IsTrue)
+}
+
+func TestRolePermissionsRequest_Validate(t *testing.T) {
+	c := qt.New(t)
+
+	permissions := []*diygoapi.FindPermissionRequest{{Resource: "/api/v1/orgs/{extlID}", Operation: "DELETE"}}
+
+	c.Assert(diygoapi.RolePermissionsRequest{Permissions: permissions}.Validate(), qt.IsNil)
+	c.Assert(diygoapi.RolePermissionsRequest{Permissions: permissions, Effect: diygoapi.PermissionEffectAllow}.Validate(), qt.IsNil)
+	c.Assert(diygoapi.RolePermissionsRequest{Permissions: permissions, Effect: diygoapi.PermissionEffectDeny}.Validate(), qt.IsNil)
+
+	c.Assert(errs.KindIs(errs.Validation, diygoapi.RolePermissionsRequest{}.Validate()), qt.IsTrue)
+	c.Assert(errs.KindIs(errs.Validation, diygoapi.RolePermissionsRequest{Permissions: permissions, Effect: "maybe"}.Validate()), qt.IsTrue)
+}
@@ -0,0 +1,83 @@
+package cmd
+
+import (
+	"context"
+	"os"
+
+	"github.com/jackc/pgx/v4/pgxpool"
+	"github.com/rs/zerolog"
+
+	"github.com/gilcrest/diygoapi/errs"
+	"github.com/gilcrest/diygoapi/logger"
+	"github.com/gilcrest/diygoapi/secure"
+	"github.com/gilcrest/diygoapi/service"
+	"github.com/gilcrest/diygoapi/sqldb"
+)
+
+// MigrateAPIKeys command converts API keys stored using reversible
+// encryption to hashed API keys. It should be run once, after the
+// 015-app_api_key_hash.sql DDL script has been executed against an
+// existing database. Run the DDL scripts again afterwards, so that
+// 029-app_api_key_prefix_not_null.sql makes the lookup prefix required.
+func MigrateAPIKeys() (err error) {
+	const op errs.Op = "cmd/MigrateAPIKeys"
+
+	var (
+		flgs   flags
+		minlvl zerolog.Level
+		ek     *[32]byte
+	)
+
+	// newFlags will retrieve the database info from the environment using ff
+	flgs, err = newFlags([]string{"server"})
+	if err != nil {
+		return errs.E(op, err)
+	}
+
+	// determine minimum logging level based on flag input
+	minlvl, err = zerolog.ParseLevel(flgs.logLvlMin)
+	if err != nil {
+		return errs.E(op, err)
+	}
+
+	// setup logger with appropriate defaults
+	lgr := logger.NewWithGCPHook(os.Stdout, minlvl, true)
+
+	if flgs.encryptkey == "" {
+		return errs.E(op, "no encryption key found")
+	}
+
+	// decode and retrieve encryption key
+	ek, err = secure.ParseEncryptionKey(flgs.encryptkey)
+	if err != nil {
+		return errs.E(op, err)
+	}
+
+	ctx := context.Background()
+
+	// initialize PostgreSQL database
+	var (
+		dbpool  *pgxpool.Pool
+		cleanup func()
+	)
+	dbpool, cleanup, err = sqldb.NewPostgreSQLPool(ctx, lgr, newPostgreSQLDSN(flgs))
+	if err != nil {
+		return errs.E(op, err)
+	}
+	defer cleanup()
+
+	s := service.AppService{
+		Datastorer:    sqldb.NewDB(dbpool),
+		EncryptionKey: ek,
+	}
+
+	var n int64
+	n, err = s.MigrateAPIKeys(ctx)
+	if err != nil {
+		return errs.E(op, err)
+	}
+
+	lgr.Info().Msgf("%d API keys migrated", n)
+
+	return nil
+}
@@ -5,15 +5,18 @@ import (
 	"flag"
 	"fmt"
 	"os"
+	"strings"
 
 	"github.com/jackc/pgx/v4/pgxpool"
 	"github.com/peterbourgon/ff/v3"
 	"github.com/rs/zerolog"
 	"golang.org/x/text/language"
 
+	"github.com/gilcrest/diygoapi"
 	"github.com/gilcrest/diygoapi/errs"
 	"github.com/gilcrest/diygoapi/gateway"
 	"github.com/gilcrest/diygoapi/logger"
+	"github.com/gilcrest/diygoapi/policy"
 	"github.com/gilcrest/diygoapi/secure"
 	"github.com/gilcrest/diygoapi/server"
 	"github.com/gilcrest/diygoapi/service"
@@ -31,6 +34,47 @@ const (
 	portEnv string = "PORT"
 	// encryption key environment variable name
 	encryptKeyEnv string = "ENCRYPT_KEY"
+	// OpenID Connect issuer environment variable name
+	oidcIssuerEnv string = "OIDC_ISSUER"
+	// OpenID Connect audience environment variable name
+	oidcAudienceEnv string = "OIDC_AUDIENCE"
+	// OpenID Connect JSON Web Key Set URL environment variable name
+	oidcJWKSURLEnv string = "OIDC_JWKS_URL"
+	// permission sync mode environment variable name
+	permissionSyncEnv string = "PERMISSION_SYNC"
+	// authorization allow decision sample rate environment variable name
+	authzAllowSampleRateEnv string = "AUTHZ_ALLOW_SAMPLE_RATE"
+	// authorization engine environment variable name
+	authzEngineEnv string = "AUTHZ_ENGINE"
+	// authorization policy file environment variable name
+	authzPolicyFileEnv string = "AUTHZ_POLICY_FILE"
+	// registration policy environment variable name
+	registrationPolicyEnv string = "REGISTRATION_POLICY"
+	// registration allowed domains environment variable name
+	registrationAllowedDomainsEnv string = "REGISTRATION_ALLOWED_DOMAINS"
+)
+
+const (
+	// permissionSyncOff does not compare permissions with routes at server start
+	permissionSyncOff string = "off"
+	// permissionSyncDryRun logs routes without a permission and permissions
+	// without a route at server start
+	permissionSyncDryRun string = "dry-run"
+	// permissionSyncEnforce logs as in permissionSyncDryRun and also creates
+	// a permission for each route without one at server start
+	permissionSyncEnforce string = "enforce"
+)
+
+const (
+	// authzEngineDB authorizes requests using the permissions and
+	// roles in the database
+	authzEngineDB string = "db"
+	// authzEnginePolicy authorizes requests by evaluating the policy
+	// document given by the authz-policy-file flag
+	authzEnginePolicy string = "policy"
+	// authzEngineShadow authorizes requests as authzEngineDB does and
+	// logs any disagreement with the policy document
+	authzEngineShadow string = "shadow"
 )
 
 type flags struct {
@@ -74,6 +118,40 @@ type flags struct {
 
 	// encryptkey is the encryption key
 	encryptkey string
+
+	// oidcIssuer is the OpenID Connect provider issuer. If empty,
+	// the generic OIDC authentication provider is disabled.
+	oidcIssuer string
+
+	// oidcAudience is the expected audience of OpenID Connect ID tokens
+	oidcAudience string
+
+	// oidcJWKSURL is the OpenID Connect provider JSON Web Key Set URL
+	oidcJWKSURL string
+
+	// permissionSync is the mode used to sync permissions with the
+	// registered routes at server start (off, dry-run or enforce)
+	permissionSync string
+
+	// authzAllowSampleRate is the fraction (between 0 and 1) of allow
+	// authorization decisions which are recorded, none by default.
+	// Deny decisions are always recorded.
+	authzAllowSampleRate float64
+
+	// authzEngine is the authorization engine used (db, policy or shadow)
+	authzEngine string
+
+	// authzPolicyFile is the path to the JSON policy document used by
+	// the policy and shadow authorization engines
+	authzPolicyFile string
+
+	// registrationPolicy is the policy determining who may register
+	// as a user (open, domains or invite)
+	registrationPolicy string
+
+	// registrationAllowedDomains is the comma separated list of email
+	// domains allowed to register with the domains registration policy
+	registrationAllowedDomains string
 }
 
 // newFlags parses the command line flags using ff and returns
@@ -95,6 +173,15 @@ func newFlags(args []string) (flags, error) {
 		dbpassword    = fs.String("db-password", "", fmt.Sprintf("postgresql database password (also via %s)", sqldb.DBPasswordEnv))
 		dbsearchpath  = fs.String("db-search-path", "", fmt.Sprintf("postgresql database search path (also via %s)", sqldb.DBSearchPathEnv))
 		encryptkey    = fs.String("encrypt-key", "", fmt.Sprintf("encryption key (also via %s)", encryptKeyEnv))
+		oidcIssuer    = fs.String("oidc-issuer", "", fmt.Sprintf("OpenID Connect provider issuer, empty disables OIDC (also via %s)", oidcIssuerEnv))
+		oidcAudience  = fs.String("oidc-audience", "", fmt.Sprintf("OpenID Connect ID token audience (also via %s)", oidcAudienceEnv))
+		oidcJWKSURL   = fs.String("oidc-jwks-url", "", fmt.Sprintf("OpenID Connect provider JSON Web Key Set URL (also via %s)", oidcJWKSURLEnv))
+		permSync      = fs.String("permission-sync", permissionSyncOff, fmt.Sprintf("sync permissions with registered routes at server start (off, dry-run, enforce), (also via %s)", permissionSyncEnv))
+		authzEngine   = fs.String("authz-engine", authzEngineDB, fmt.Sprintf("authorization engine (db, policy, shadow), (also via %s)", authzEngineEnv))
+		policyFile    = fs.String("authz-policy-file", "", fmt.Sprintf("path to the JSON authorization policy document used by the policy and shadow engines (also via %s)", authzPolicyFileEnv))
+		allowSample   = fs.Float64("authz-allow-sample-rate", 0, fmt.Sprintf("fraction (0 to 1) of allow authorization decisions recorded, by default none, deny decisions are always recorded (also via %s)", authzAllowSampleRateEnv))
+		regPolicy     = fs.String("registration-policy", diygoapi.RegistrationOpen, fmt.Sprintf("who may register as a user (open, domains, invite), (also via %s)", registrationPolicyEnv))
+		regDomains    = fs.String("registration-allowed-domains", "", fmt.Sprintf("comma separated email domains allowed to register with the domains registration policy (also via %s)", registrationAllowedDomainsEnv))
 	)
 
 	// Parse the command line flags from above
@@ -104,17 +191,26 @@ func newFlags(args []string) (flags, error) {
 	}
 
 	return flags{
-		loglvl:        *loglvl,
-		logLvlMin:     *logLvlMin,
-		logErrorStack: *logErrorStack,
-		port:          *port,
-		dbhost:        *dbhost,
-		dbport:        *dbport,
-		dbname:        *dbname,
-		dbuser:        *dbuser,
-		dbpassword:    *dbpassword,
-		dbsearchpath:  *dbsearchpath,
-		encryptkey:    *encryptkey,
+		loglvl:                     *loglvl,
+		logLvlMin:                  *logLvlMin,
+		logErrorStack:              *logErrorStack,
+		port:                       *port,
+		dbhost:                     *dbhost,
+		dbport:                     *dbport,
+		dbname:                     *dbname,
+		dbuser:                     *dbuser,
+		dbpassword:                 *dbpassword,
+		dbsearchpath:               *dbsearchpath,
+		encryptkey:                 *encryptkey,
+		oidcIssuer:                 *oidcIssuer,
+		oidcAudience:               *oidcAudience,
+		oidcJWKSURL:                *oidcJWKSURL,
+		permissionSync:             *permSync,
+		authzAllowSampleRate:       *allowSample,
+		authzEngine:                *authzEngine,
+		authzPolicyFile:            *policyFile,
+		registrationPolicy:         *regPolicy,
+		registrationAllowedDomains: *regDomains,
 	}, nil
 }
 
@@ -170,6 +266,25 @@ func Run(args []string) (err error) {
 		lgr.Fatal().Err(err).Msg("portRange() error")
 	}
 
+	// validate permission sync mode
+	err = permissionSyncMode(flgs.permissionSync)
+	if err != nil {
+		lgr.Fatal().Err(err).Msg("permissionSyncMode() error")
+	}
+
+	// validate authorization allow decision sample rate
+	err = sampleRate(flgs.authzAllowSampleRate)
+	if err != nil {
+		lgr.Fatal().Err(err).Msg("sampleRate() error")
+	}
+
+	// validate registration policy
+	var regPolicy diygoapi.RegistrationPolicy
+	regPolicy, err = diygoapi.NewRegistrationPolicy(flgs.registrationPolicy, strings.Split(flgs.registrationAllowedDomains, ","))
+	if err != nil {
+		lgr.Fatal().Err(err).Msg("diygoapi.NewRegistrationPolicy() error")
+	}
+
 	// initialize Server enfolding a http.Server with default timeouts
 	// a Gorilla mux router with /api subroute and a zerolog.Logger
 	s := server.New(server.NewMuxRouter(), server.NewDriver(), lgr)
@@ -188,6 +303,12 @@ func Run(args []string) (err error) {
 		lgr.Fatal().Err(err).Msg("secure.ParseEncryptionKey() error")
 	}
 
+	var tokenExchanger gateway.Oauth2TokenExchange
+	tokenExchanger, err = newTokenExchanger(flgs)
+	if err != nil {
+		lgr.Fatal().Err(err).Msg("newTokenExchanger() error")
+	}
+
 	ctx := context.Background()
 
 	// initialize PostgreSQL database
@@ -209,17 +330,34 @@ func Run(args []string) (err error) {
 		lgr.Fatal().Err(err).Msg("db.ValidatePool error")
 	}
 
+	var authorizer diygoapi.AuthorizationServicer
+	authorizer, err = newAuthorizationServicer(flgs, db)
+	if err != nil {
+		lgr.Fatal().Err(err).Msg("newAuthorizationServicer() error")
+	}
+	lgr.Info().Msgf("authorization engine set to %s", flgs.authzEngine)
+
 	var supportedLangs = []language.Tag{
 		language.AmericanEnglish,
 	}
 
 	matcher := language.NewMatcher(supportedLangs)
+	s.LanguageMatcher = matcher
+
+	authenticator := service.DBAuthenticationService{
+		Datastorer:         db,
+		TokenExchanger:     tokenExchanger,
+		EncryptionKey:      ek,
+		LanguageMatcher:    matcher,
+		RegistrationPolicy: regPolicy,
+	}
 
 	s.Services = server.Services{
 		OrgServicer: &service.OrgService{
 			Datastorer:      db,
 			APIKeyGenerator: secure.RandomGenerator{},
 			EncryptionKey:   ek},
+		OrgKindServicer: &service.OrgKindService{Datastorer: db},
 		AppServicer: &service.AppService{
 			Datastorer:      db,
 			APIKeyGenerator: secure.RandomGenerator{},
@@ -230,19 +368,28 @@ func Run(args []string) (err error) {
 			Datastorer:      db,
 			APIKeyGenerator: secure.RandomGenerator{},
 			EncryptionKey:   ek,
-			TokenExchanger:  gateway.Oauth2TokenExchange{},
-			LanguageMatcher: matcher,
-		},
-		AuthenticationServicer: service.DBAuthenticationService{
-			Datastorer:      db,
-			TokenExchanger:  gateway.Oauth2TokenExchange{},
-			EncryptionKey:   ek,
+			TokenExchanger:  tokenExchanger,
 			LanguageMatcher: matcher,
 		},
-		AuthorizationServicer: &service.DBAuthorizationService{Datastorer: db},
-		PermissionServicer:    &service.PermissionService{Datastorer: db},
-		RoleServicer:          &service.RoleService{Datastorer: db},
-		MovieServicer:         &service.MovieService{Datastorer: db},
+		AuthenticationServicer: authenticator,
+		RegisterUserService:    authenticator,
+		AuthorizationServicer:  authorizer,
+		PermissionServicer:     &service.PermissionService{Datastorer: db},
+		RoleServicer:           &service.RoleService{Datastorer: db},
+		MovieServicer:          &service.MovieService{Datastorer: db},
+		UserServicer:           &service.UserService{Datastorer: db},
+		InvitationServicer:     &service.InvitationService{Datastorer: db, EncryptionKey: ek},
+	}
+
+	if flgs.permissionSync != permissionSyncOff {
+		err = syncPermissions(ctx, lgr, s, flgs.permissionSync == permissionSyncEnforce)
+		if err != nil {
+			// before Genesis, there is no app to create permissions with
+			if !errs.KindIs(errs.NotExist, err) {
+				lgr.Fatal().Err(err).Msg("syncPermissions() error")
+			}
+			lgr.Warn().Err(err).Msg("permissions not synced")
+		}
 	}
 
 	return s.ListenAndServe()
@@ -260,6 +407,88 @@ func newPostgreSQLDSN(flgs flags) sqldb.PostgreSQLDSN {
 	}
 }
 
+// newTokenExchanger initializes a gateway.Oauth2TokenExchange given a
+// flags struct. The generic OpenID Connect provider is only configured
+// if an OIDC issuer is given.
+func newTokenExchanger(flgs flags) (gateway.Oauth2TokenExchange, error) {
+	const op errs.Op = "cmd/newTokenExchanger"
+
+	if flgs.oidcIssuer == "" {
+		return gateway.Oauth2TokenExchange{}, nil
+	}
+
+	oidc, err := gateway.NewOIDCTokenExchange(gateway.OIDCConfig{
+		Issuer:   flgs.oidcIssuer,
+		Audience: flgs.oidcAudience,
+		JWKSURL:  flgs.oidcJWKSURL,
+	})
+	if err != nil {
+		return gateway.Oauth2TokenExchange{}, errs.E(op, err)
+	}
+
+	return gateway.Oauth2TokenExchange{OIDC: oidc}, nil
+}
+
+// newAuthorizationServicer initializes the diygoapi.AuthorizationServicer
+// for the authorization engine given by the flags struct
+func newAuthorizationServicer(flgs flags, db diygoapi.Datastorer) (diygoapi.AuthorizationServicer, error) {
+	const op errs.Op = "cmd/newAuthorizationServicer"
+
+	dbAuthorizer := &service.DBAuthorizationService{
+		Datastorer:      db,
+		AllowSampleRate: flgs.authzAllowSampleRate,
+	}
+
+	switch flgs.authzEngine {
+	case authzEngineDB:
+		return dbAuthorizer, nil
+	case authzEnginePolicy, authzEngineShadow:
+		if flgs.authzPolicyFile == "" {
+			return nil, errs.E(op, fmt.Sprintf("a policy file is required for the %s authorization engine", flgs.authzEngine))
+		}
+	default:
+		return nil, errs.E(op, fmt.Sprintf("authorization engine %q is not valid (db, policy or shadow)", flgs.authzEngine))
+	}
+
+	p, err := policy.Load(flgs.authzPolicyFile)
+	if err != nil {
+		return nil, errs.E(op, err)
+	}
+
+	policyAuthorizer := &service.PolicyAuthorizationService{
+		Datastorer:      db,
+		Policy:          p,
+		AllowSampleRate: flgs.authzAllowSampleRate,
+	}
+
+	if flgs.authzEngine == authzEngineShadow {
+		return &service.ShadowAuthorizationService{DBAuthorizationService: dbAuthorizer, Shadow: policyAuthorizer}, nil
+	}
+
+	return policyAuthorizer, nil
+}
+
+// permissionSyncMode validates the permission sync mode
+func permissionSyncMode(mode string) error {
+	const op errs.Op = "cmd/permissionSyncMode"
+
+	switch mode {
+	case permissionSyncOff, permissionSyncDryRun, permissionSyncEnforce:
+		return nil
+	}
+	return errs.E(op, fmt.Sprintf("permission sync mode %q is not valid (off, dry-run or enforce)", mode))
+}
+
+// sampleRate validates the sample rate be between 0 and 1
+func sampleRate(rate float64) error {
+	const op errs.Op = "cmd/sampleRate"
+
+	if rate < 0 || rate > 1 {
+		return errs.E(op, fmt.Sprintf("sample rate %v is not between 0 and 1", rate))
+	}
+	return nil
+}
+
 // portRange validates the port be in an acceptable range
 func portRange(port int) error {
 	const op errs.Op = "cmd/portRange"
@@ -2,11 +2,15 @@ package cmd
 
 import (
 	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+
 	qt "github.com/frankban/quicktest"
 	"github.com/google/go-cmp/cmp"
-	"testing"
 
 	"github.com/gilcrest/diygoapi/errs"
+	"github.com/gilcrest/diygoapi/service"
 	"github.com/gilcrest/diygoapi/sqldb"
 )
 
@@ -33,6 +37,68 @@ func Test_portRange(t *testing.T) {
 	}
 }
 
+func Test_permissionSyncMode(t *testing.T) {
+	c := qt.New(t)
+
+	c.Assert(permissionSyncMode(permissionSyncOff), qt.IsNil)
+	c.Assert(permissionSyncMode(permissionSyncDryRun), qt.IsNil)
+	c.Assert(permissionSyncMode(permissionSyncEnforce), qt.IsNil)
+	c.Assert(permissionSyncMode("sometimes"), qt.IsNotNil)
+}
+
+func Test_sampleRate(t *testing.T) {
+	c := qt.New(t)
+
+	c.Assert(sampleRate(0), qt.IsNil)
+	c.Assert(sampleRate(0.25), qt.IsNil)
+	c.Assert(sampleRate(1), qt.IsNil)
+	c.Assert(sampleRate(-0.1), qt.IsNotNil)
+	c.Assert(sampleRate(1.5), qt.IsNotNil)
+}
+
+func Test_newAuthorizationServicer(t *testing.T) {
+	policyFile := filepath.Join(t.TempDir(), "policy.json")
+	err := os.WriteFile(policyFile, []byte(`{"statements": [{"id": "admins", "effect": "allow", "roles": ["sysAdmin"], "resources": ["*"], "operations": ["*"]}]}`), 0600)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	t.Run("db", func(t *testing.T) {
+		c := qt.New(t)
+
+		a, err := newAuthorizationServicer(flags{authzEngine: authzEngineDB}, nil)
+		c.Assert(err, qt.IsNil)
+		_, ok := a.(*service.DBAuthorizationService)
+		c.Assert(ok, qt.IsTrue)
+	})
+	t.Run("policy", func(t *testing.T) {
+		c := qt.New(t)
+
+		a, err := newAuthorizationServicer(flags{authzEngine: authzEnginePolicy, authzPolicyFile: policyFile}, nil)
+		c.Assert(err, qt.IsNil)
+		_, ok := a.(*service.PolicyAuthorizationService)
+		c.Assert(ok, qt.IsTrue)
+	})
+	t.Run("shadow", func(t *testing.T) {
+		c := qt.New(t)
+
+		a, err := newAuthorizationServicer(flags{authzEngine: authzEngineShadow, authzPolicyFile: policyFile}, nil)
+		c.Assert(err, qt.IsNil)
+		_, ok := a.(*service.ShadowAuthorizationService)
+		c.Assert(ok, qt.IsTrue)
+	})
+	t.Run("invalid", func(t *testing.T) {
+		c := qt.New(t)
+
+		_, err := newAuthorizationServicer(flags{authzEngine: "magic"}, nil)
+		c.Assert(err, qt.IsNotNil)
+		_, err = newAuthorizationServicer(flags{authzEngine: authzEnginePolicy}, nil)
+		c.Assert(err, qt.IsNotNil)
+		_, err = newAuthorizationServicer(flags{authzEngine: authzEngineShadow, authzPolicyFile: filepath.Join(t.TempDir(), "missing.json")}, nil)
+		c.Assert(err, qt.IsNotNil)
+	})
+}
+
 func Test_newFlags(t *testing.T) {
 	c := qt.New(t)
 
@@ -74,47 +140,59 @@ func Test_newFlags(t *testing.T) {
 
 	a1 := args{args: []string{"server", "-log-level=info", "-log-level-min=debug", "-log-error-stack", "-port=8080", "-db-host=localhost", "-db-port=5432", "-db-name=go_api_basic", "-db-user=postgres", "-db-password=sosecret", "-db-search-path=demo", "-encrypt-key=reallyGoodKey"}}
 	f1 := flags{
-		loglvl:        "info",
-		logLvlMin:     "debug",
-		logErrorStack: true,
-		port:          8080,
-		dbhost:        "localhost",
-		dbport:        5432,
-		dbname:        "go_api_basic",
-		dbuser:        "postgres",
-		dbpassword:    "sosecret",
-		dbsearchpath:  "demo",
-		encryptkey:    "reallyGoodKey",
+		loglvl:               "info",
+		logLvlMin:            "debug",
+		logErrorStack:        true,
+		port:                 8080,
+		dbhost:               "localhost",
+		dbport:               5432,
+		dbname:               "go_api_basic",
+		dbuser:               "postgres",
+		dbpassword:           "sosecret",
+		dbsearchpath:         "demo",
+		encryptkey:           "reallyGoodKey",
+		permissionSync:       "off",
+		authzAllowSampleRate: 0,
+		authzEngine:          "db",
+		registrationPolicy:   "open",
 	}
 
 	a2 := args{args: []string{"server"}}
 	f2 := flags{
-		loglvl:        "warn",
-		logLvlMin:     "debug",
-		logErrorStack: false,
-		port:          8081,
-		dbhost:        "hostwiththemost",
-		dbport:        5150,
-		dbname:        "whatisinaname",
-		dbuser:        "usersarelosers",
-		dbpassword:    "yeet",
-		dbsearchpath:  "u2",
-		encryptkey:    "reallyGoodKey",
+		loglvl:               "warn",
+		logLvlMin:            "debug",
+		logErrorStack:        false,
+		port:                 8081,
+		dbhost:               "hostwiththemost",
+		dbport:               5150,
+		dbname:               "whatisinaname",
+		dbuser:               "usersarelosers",
+		dbpassword:           "yeet",
+		dbsearchpath:         "u2",
+		encryptkey:           "reallyGoodKey",
+		permissionSync:       "off",
+		authzAllowSampleRate: 0,
+		authzEngine:          "db",
+		registrationPolicy:   "open",
 	}
 
 	a3 := args{args: []string{"server", "-log-level=error"}}
 	f3 := flags{
-		loglvl:        "error",
-		logLvlMin:     "debug",
-		logErrorStack: false,
-		port:          8081,
-		dbhost:        "hostwiththemost",
-		dbport:        5150,
-		dbname:        "whatisinaname",
-		dbuser:        "usersarelosers",
-		dbpassword:    "yeet",
-		dbsearchpath:  "u2",
-		encryptkey:    "reallyGoodKey",
+		loglvl:               "error",
+		logLvlMin:            "debug",
+		logErrorStack:        false,
+		port:                 8081,
+		dbhost:               "hostwiththemost",
+		dbport:               5150,
+		dbname:               "whatisinaname",
+		dbuser:               "usersarelosers",
+		dbpassword:           "yeet",
+		dbsearchpath:         "u2",
+		encryptkey:           "reallyGoodKey",
+		permissionSync:       "off",
+		authzAllowSampleRate: 0,
+		authzEngine:          "db",
+		registrationPolicy:   "open",
 	}
 
 	a4 := args{args: []string{"server", "-badflag=true"}}
@@ -122,15 +200,19 @@ func Test_newFlags(t *testing.T) {
 
 	a5 := args{args: []string{"server", "-log-level=debug", "-log-level-min=debug", "-log-error-stack", "-port=8080", "-db-host=localhost", "-db-port=5432", "-db-name=go_api_basic", "-db-user=postgres", "-db-password=sosecret"}}
 	f5 := flags{
-		loglvl:        "debug",
-		logLvlMin:     "debug",
-		logErrorStack: true,
-		port:          8080,
-		dbhost:        "localhost",
-		dbport:        5432,
-		dbname:        "go_api_basic",
-		dbuser:        "postgres",
-		dbpassword:    "sosecret",
+		loglvl:               "debug",
+		logLvlMin:            "debug",
+		logErrorStack:        true,
+		port:                 8080,
+		dbhost:               "localhost",
+		dbport:               5432,
+		dbname:               "go_api_basic",
+		dbuser:               "postgres",
+		dbpassword:           "sosecret",
+		permissionSync:       "off",
+		authzAllowSampleRate: 0,
+		authzEngine:          "db",
+		registrationPolicy:   "open",
 	}
 
 	tests := []struct {
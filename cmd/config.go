@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"os"
 	"strconv"
+	"strings"
 
 	"github.com/gilcrest/diygoapi/errs"
 	"github.com/gilcrest/diygoapi/sqldb"
@@ -95,7 +96,18 @@ type ConfigFile struct {
 			SearchPath string `json:"searchPath"`
 		} `json:"database"`
 		EncryptionKey string `json:"encryptionKey"`
-		GCP           struct {
+		OIDC          struct {
+			Issuer   string `json:"issuer"`
+			Audience string `json:"audience"`
+			JWKSURL  string `json:"jwksURL"`
+		} `json:"oidc"`
+		PermissionSync             string   `json:"permissionSync"`
+		AuthzAllowSampleRate       *float64 `json:"authzAllowSampleRate"`
+		AuthzEngine                string   `json:"authzEngine"`
+		AuthzPolicyFile            string   `json:"authzPolicyFile"`
+		RegistrationPolicy         string   `json:"registrationPolicy"`
+		RegistrationAllowedDomains []string `json:"registrationAllowedDomains"`
+		GCP                        struct {
 			ProjectID        string `json:"projectID"`
 			ArtifactRegistry struct {
 				RepoLocation string `json:"repoLocation"`
@@ -205,6 +217,63 @@ func overrideEnv(f ConfigFile) error {
 		return errs.E(op, err)
 	}
 
+	// OpenID Connect issuer
+	err = os.Setenv(oidcIssuerEnv, f.Config.OIDC.Issuer)
+	if err != nil {
+		return errs.E(op, err)
+	}
+
+	// OpenID Connect audience
+	err = os.Setenv(oidcAudienceEnv, f.Config.OIDC.Audience)
+	if err != nil {
+		return errs.E(op, err)
+	}
+
+	// OpenID Connect JSON Web Key Set URL
+	err = os.Setenv(oidcJWKSURLEnv, f.Config.OIDC.JWKSURL)
+	if err != nil {
+		return errs.E(op, err)
+	}
+
+	// permission sync mode
+	err = os.Setenv(permissionSyncEnv, f.Config.PermissionSync)
+	if err != nil {
+		return errs.E(op, err)
+	}
+
+	// authorization engine
+	err = os.Setenv(authzEngineEnv, f.Config.AuthzEngine)
+	if err != nil {
+		return errs.E(op, err)
+	}
+
+	// authorization policy file
+	err = os.Setenv(authzPolicyFileEnv, f.Config.AuthzPolicyFile)
+	if err != nil {
+		return errs.E(op, err)
+	}
+
+	// registration policy
+	err = os.Setenv(registrationPolicyEnv, f.Config.RegistrationPolicy)
+	if err != nil {
+		return errs.E(op, err)
+	}
+
+	// email domains allowed to register with the domains registration policy
+	err = os.Setenv(registrationAllowedDomainsEnv, strings.Join(f.Config.RegistrationAllowedDomains, ","))
+	if err != nil {
+		return errs.E(op, err)
+	}
+
+	// authorization allow decision sample rate, only set if given as
+	// 0 is a valid rate
+	if f.Config.AuthzAllowSampleRate != nil {
+		err = os.Setenv(authzAllowSampleRateEnv, strconv.FormatFloat(*f.Config.AuthzAllowSampleRate, 'f', -1, 64))
+		if err != nil {
+			return errs.E(op, err)
+		}
+	}
+
 	return nil
 }
 
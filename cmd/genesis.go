@@ -81,6 +81,12 @@ func Genesis() (err error) {
 		lgr.Fatal().Err(err).Msg("secure.ParseEncryptionKey() error")
 	}
 
+	var tokenExchanger gateway.Oauth2TokenExchange
+	tokenExchanger, err = newTokenExchanger(flgs)
+	if err != nil {
+		lgr.Fatal().Err(err).Msg("newTokenExchanger() error")
+	}
+
 	ctx := context.Background()
 
 	// initialize PostgreSQL database
@@ -104,7 +110,7 @@ func Genesis() (err error) {
 		Datastorer:      sqldb.NewDB(dbpool),
 		APIKeyGenerator: secure.RandomGenerator{},
 		EncryptionKey:   ek,
-		TokenExchanger:  gateway.Oauth2TokenExchange{},
+		TokenExchanger:  tokenExchanger,
 		LanguageMatcher: matcher,
 	}
 
@@ -0,0 +1,101 @@
+package cmd
+
+import (
+	"context"
+	"os"
+
+	"github.com/jackc/pgx/v4/pgxpool"
+	"github.com/rs/zerolog"
+
+	"github.com/gilcrest/diygoapi"
+	"github.com/gilcrest/diygoapi/errs"
+	"github.com/gilcrest/diygoapi/logger"
+	"github.com/gilcrest/diygoapi/server"
+	"github.com/gilcrest/diygoapi/service"
+	"github.com/gilcrest/diygoapi/sqldb"
+)
+
+// SyncPermissions command compares the permissions in the database
+// with the routes registered by the server. Routes which require
+// authorization, but have no matching permission and permissions
+// which match no route are logged. If upsert is true, a permission
+// is created for each route missing one. Genesis must have been run
+// before permissions can be created.
+func SyncPermissions(upsert bool) (err error) {
+	const op errs.Op = "cmd/SyncPermissions"
+
+	var (
+		flgs   flags
+		minlvl zerolog.Level
+	)
+
+	// newFlags will retrieve the database info from the environment using ff
+	flgs, err = newFlags([]string{"server"})
+	if err != nil {
+		return errs.E(op, err)
+	}
+
+	// determine minimum logging level based on flag input
+	minlvl, err = zerolog.ParseLevel(flgs.logLvlMin)
+	if err != nil {
+		return errs.E(op, err)
+	}
+
+	// setup logger with appropriate defaults
+	lgr := logger.NewWithGCPHook(os.Stdout, minlvl, true)
+
+	ctx := context.Background()
+
+	// initialize PostgreSQL database
+	var (
+		dbpool  *pgxpool.Pool
+		cleanup func()
+	)
+	dbpool, cleanup, err = sqldb.NewPostgreSQLPool(ctx, lgr, newPostgreSQLDSN(flgs))
+	if err != nil {
+		return errs.E(op, err)
+	}
+	defer cleanup()
+
+	// the server is only used to register and walk its routes
+	s := server.New(server.NewMuxRouter(), server.NewDriver(), lgr)
+	s.PermissionServicer = &service.PermissionService{Datastorer: sqldb.NewDB(dbpool)}
+
+	err = syncPermissions(ctx, lgr, s, upsert)
+	if err != nil {
+		return errs.E(op, err)
+	}
+
+	return nil
+}
+
+// syncPermissions syncs the permissions in the database with the
+// routes registered by the server and logs the result
+func syncPermissions(ctx context.Context, lgr zerolog.Logger, s *server.Server, upsert bool) error {
+	const op errs.Op = "cmd/syncPermissions"
+
+	routes, err := s.Routes()
+	if err != nil {
+		return errs.E(op, err)
+	}
+
+	var response *diygoapi.PermissionSyncResponse
+	response, err = s.PermissionServicer.Sync(ctx, &diygoapi.PermissionSyncRequest{Routes: routes, Upsert: upsert})
+	if err != nil {
+		return errs.E(op, err)
+	}
+
+	for _, r := range response.Missing {
+		lgr.Warn().Str("resource", r.Resource).Str("operation", r.Operation).Msg("route has no permission")
+	}
+	for _, p := range response.Orphaned {
+		lgr.Warn().Str("resource", p.Resource).Str("operation", p.Operation).Str("external_id", p.ExternalID).Msg("permission has no route")
+	}
+	for _, p := range response.Created {
+		lgr.Info().Str("resource", p.Resource).Str("operation", p.Operation).Str("external_id", p.ExternalID).Msg("permission created")
+	}
+
+	lgr.Info().Msgf("permission sync complete: %d missing, %d orphaned, %d created", len(response.Missing), len(response.Orphaned), len(response.Created))
+
+	return nil
+}
@@ -0,0 +1,129 @@
+package cmd
+
+import (
+	"context"
+	"os"
+	"time"
+
+	"github.com/jackc/pgx/v4/pgxpool"
+	"github.com/rs/zerolog"
+
+	"github.com/gilcrest/diygoapi"
+	"github.com/gilcrest/diygoapi/errs"
+	"github.com/gilcrest/diygoapi/logger"
+	"github.com/gilcrest/diygoapi/service"
+	"github.com/gilcrest/diygoapi/sqldb"
+)
+
+// PurgeDeleted command permanently removes the orgs, apps and movies
+// which were deleted longer ago than the retention period. Deleted
+// records can be restored until they are purged.
+func PurgeDeleted(retention time.Duration) (err error) {
+	const op errs.Op = "cmd/PurgeDeleted"
+
+	if retention < 0 {
+		return errs.E(op, "retention period cannot be negative")
+	}
+
+	var (
+		flgs   flags
+		minlvl zerolog.Level
+	)
+
+	// newFlags will retrieve the database info from the environment using ff
+	flgs, err = newFlags([]string{"server"})
+	if err != nil {
+		return errs.E(op, err)
+	}
+
+	// determine minimum logging level based on flag input
+	minlvl, err = zerolog.ParseLevel(flgs.logLvlMin)
+	if err != nil {
+		return errs.E(op, err)
+	}
+
+	// setup logger with appropriate defaults
+	lgr := logger.NewWithGCPHook(os.Stdout, minlvl, true)
+
+	ctx := context.Background()
+
+	// initialize PostgreSQL database
+	var (
+		dbpool  *pgxpool.Pool
+		cleanup func()
+	)
+	dbpool, cleanup, err = sqldb.NewPostgreSQLPool(ctx, lgr, newPostgreSQLDSN(flgs))
+	if err != nil {
+		return errs.E(op, err)
+	}
+	defer cleanup()
+
+	s := service.PurgeService{Datastorer: sqldb.NewDB(dbpool)}
+
+	var response diygoapi.PurgeResponse
+	response, err = s.Purge(ctx, time.Now().Add(-retention))
+	if err != nil {
+		return errs.E(op, err)
+	}
+
+	lgr.Info().Int64("movies", response.Movies).Int64("apps", response.Apps).
+		Int64("orgs", response.Orgs).Int64("skipped", response.Skipped).
+		Msgf("%d movies, %d apps and %d orgs purged, %d skipped", response.Movies, response.Apps, response.Orgs, response.Skipped)
+
+	return nil
+}
+
+// PurgeAuthzDecisions command permanently removes the authorization
+// decisions which were recorded longer ago than the retention period.
+func PurgeAuthzDecisions(retention time.Duration) (err error) {
+	const op errs.Op = "cmd/PurgeAuthzDecisions"
+
+	if retention < 0 {
+		return errs.E(op, "retention period cannot be negative")
+	}
+
+	var (
+		flgs   flags
+		minlvl zerolog.Level
+	)
+
+	// newFlags will retrieve the database info from the environment using ff
+	flgs, err = newFlags([]string{"server"})
+	if err != nil {
+		return errs.E(op, err)
+	}
+
+	// determine minimum logging level based on flag input
+	minlvl, err = zerolog.ParseLevel(flgs.logLvlMin)
+	if err != nil {
+		return errs.E(op, err)
+	}
+
+	// setup logger with appropriate defaults
+	lgr := logger.NewWithGCPHook(os.Stdout, minlvl, true)
+
+	ctx := context.Background()
+
+	// initialize PostgreSQL database
+	var (
+		dbpool  *pgxpool.Pool
+		cleanup func()
+	)
+	dbpool, cleanup, err = sqldb.NewPostgreSQLPool(ctx, lgr, newPostgreSQLDSN(flgs))
+	if err != nil {
+		return errs.E(op, err)
+	}
+	defer cleanup()
+
+	s := service.PurgeService{Datastorer: sqldb.NewDB(dbpool)}
+
+	var n int64
+	n, err = s.PurgeAuthzDecisions(ctx, time.Now().Add(-retention))
+	if err != nil {
+		return errs.E(op, err)
+	}
+
+	lgr.Info().Int64("authz_decisions", n).Msgf("%d authorization decisions purged", n)
+
+	return nil
+}
@@ -26,6 +26,16 @@ package config
 	searchPath: !="" // must be specified and non-empty
 }
 
+// OpenID Connect provider (Okta, Auth0, Keycloak, Dex, etc.)
+#OIDC: {
+	// issuer (iss claim) of ID tokens
+	issuer: !="" // must be specified and non-empty
+	// audience (aud claim) of ID tokens, typically the client ID
+	audience: !="" // must be specified and non-empty
+	// JSON Web Key Set URL of the provider
+	jwksURL: !="" // must be specified and non-empty
+}
+
 #GCP: {
 	// Google Cloud project ID
 	projectID:        !="" // must be specified and non-empty
@@ -60,19 +70,48 @@ package config
 	serviceName: !="" // must be specified and non-empty
 }
 
+// mode used to sync permissions with the registered routes at server start
+#PermissionSyncModes: "off" | "dry-run" | "enforce"
+
+// engine used to authorize requests
+#AuthzEngines: "db" | "policy" | "shadow"
+
+// policy determining who may register as a user
+#RegistrationPolicies: "open" | "domains" | "invite"
+
+// email domains allowed to register with the domains registration policy
+#RegistrationAllowedDomains: [...string]
+
+// fraction of events sampled, between 0 and 1
+#SampleRate: number & >=0 & <=1
+
 #LogLevels: "trace" | "debug" | "info" | "warn" | "error" | "fatal" | "panic" | "disabled"
 
 #LocalConfig: {
 	#Base
-	httpServer: #HTTPServer
-	logger:     #Logger
-	database:   #Database
+	httpServer:                  #HTTPServer
+	logger:                      #Logger
+	database:                    #Database
+	oidc?:                       #OIDC
+	permissionSync?:             #PermissionSyncModes
+	authzAllowSampleRate?:       #SampleRate
+	authzEngine?:                #AuthzEngines
+	authzPolicyFile?:            string
+	registrationPolicy?:         #RegistrationPolicies
+	registrationAllowedDomains?: #RegistrationAllowedDomains
 }
 
 #GCPConfig: {
 	#Base
-	httpServer: #HTTPServer
-	logger:     #Logger
-	database:   #Database
-	gcp:        #GCP
+	httpServer:                  #HTTPServer
+	logger:                      #Logger
+	database:                    #Database
+	oidc?:                       #OIDC
+	permissionSync?:             #PermissionSyncModes
+	authzAllowSampleRate?:       #SampleRate
+	authzEngine?:                #AuthzEngines
+	authzPolicyFile?:            string
+	registrationPolicy?:         #RegistrationPolicies
+	registrationAllowedDomains?: #RegistrationAllowedDomains
+	gcp:                         #GCP
 }
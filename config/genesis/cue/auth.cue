@@ -29,14 +29,14 @@ _orgsV1Post: #Permission & {
 }
 
 _orgsV1Put: #Permission & {
-	resource:    "/api/v1/orgs"
+	resource:    "/api/v1/orgs/{extlID}"
 	operation:   "PUT"
 	description: "allows for updating an organization"
 	active:      true
 }
 
 _orgsV1Delete: #Permission & {
-	resource:    "/api/v1/orgs"
+	resource:    "/api/v1/orgs/{extlID}"
 	operation:   "DELETE"
 	description: "allows for deleting an organization"
 	active:      true
@@ -126,11 +126,345 @@ _moviesV1FindAll: #Permission & {
 	active:      true
 }
 
+_appsV1KeysPost: #Permission & {
+	resource:    "/api/v1/apps/{extlID}/keys"
+	operation:   "POST"
+	description: "allows for issuing or rotating an API key for an app"
+	active:      true
+}
+
+_appsV1KeysGet: #Permission & {
+	resource:    "/api/v1/apps/{extlID}/keys"
+	operation:   "GET"
+	description: "allows for listing the API keys of an app"
+	active:      true
+}
+
+_appsV1KeysDelete: #Permission & {
+	resource:    "/api/v1/apps/{extlID}/keys/{keyPrefix}"
+	operation:   "DELETE"
+	description: "allows for revoking an API key of an app"
+	active:      true
+}
+
+_rolesV1Post: #Permission & {
+	resource:    "/api/v1/roles"
+	operation:   "POST"
+	description: "allows for creating a role"
+	active:      true
+}
+
+_rolesV1Get: #Permission & {
+	resource:    "/api/v1/roles"
+	operation:   "GET"
+	description: "allows for reading all roles"
+	active:      true
+}
+
+_rolesV1GetByExtlID: #Permission & {
+	resource:    "/api/v1/roles/{extlID}"
+	operation:   "GET"
+	description: "allows for reading a single role"
+	active:      true
+}
+
+_rolesV1Put: #Permission & {
+	resource:    "/api/v1/roles/{extlID}"
+	operation:   "PUT"
+	description: "allows for updating a role"
+	active:      true
+}
+
+_rolesV1Delete: #Permission & {
+	resource:    "/api/v1/roles/{extlID}"
+	operation:   "DELETE"
+	description: "allows for deleting a role"
+	active:      true
+}
+
+_rolesV1PermissionsPost: #Permission & {
+	resource:    "/api/v1/roles/{extlID}/permissions"
+	operation:   "POST"
+	description: "allows for adding permissions to a role"
+	active:      true
+}
+
+_rolesV1PermissionsDelete: #Permission & {
+	resource:    "/api/v1/roles/{extlID}/permissions/{permissionExtlID}"
+	operation:   "DELETE"
+	description: "allows for removing a permission from a role"
+	active:      true
+}
+
+_orgsV1UserRolesPut: #Permission & {
+	resource:    "/api/v1/orgs/{extlID}/users/{userExtlID}/roles/{roleCd}"
+	operation:   "PUT"
+	description: "allows for assigning a role to a user within an organization"
+	active:      true
+}
+
+_orgsV1UserRolesDelete: #Permission & {
+	resource:    "/api/v1/orgs/{extlID}/users/{userExtlID}/roles/{roleCd}"
+	operation:   "DELETE"
+	description: "allows for revoking a role from a user within an organization"
+	active:      true
+}
+
+_orgsV1RoleUsersGet: #Permission & {
+	resource:    "/api/v1/orgs/{extlID}/roles/{roleCd}/users"
+	operation:   "GET"
+	description: "allows for listing the users given a role within an organization"
+	active:      true
+}
+
+_rolesV1ParentsPut: #Permission & {
+	resource:    "/api/v1/roles/{extlID}/parents/{roleCd}"
+	operation:   "PUT"
+	description: "allows for adding a parent role to a role"
+	active:      true
+}
+
+_rolesV1ParentsDelete: #Permission & {
+	resource:    "/api/v1/roles/{extlID}/parents/{roleCd}"
+	operation:   "DELETE"
+	description: "allows for removing a parent role from a role"
+	active:      true
+}
+
+_rolesV1GrantsExpiredGet: #Permission & {
+	resource:    "/api/v1/roles/grants/expired"
+	operation:   "GET"
+	description: "allows for listing the role grants whose validity window has ended"
+	active:      true
+}
+
+_rolesV1GrantsExpiredDelete: #Permission & {
+	resource:    "/api/v1/roles/grants/expired"
+	operation:   "DELETE"
+	description: "allows for purging the role grants whose validity window has ended"
+	active:      true
+}
+
+_authzV1DecisionsGet: #Permission & {
+	resource:    "/api/v1/authz/decisions"
+	operation:   "GET"
+	description: "allows for searching the recorded authorization decisions"
+	active:      true
+}
+
+_orgsV1UsersGet: #Permission & {
+	resource:    "/api/v1/orgs/{extlID}/users"
+	operation:   "GET"
+	description: "allows for listing the users who are members of an organization"
+	active:      true
+}
+
+_orgsV1UsersDelete: #Permission & {
+	resource:    "/api/v1/orgs/{extlID}/users/{userExtlID}"
+	operation:   "DELETE"
+	description: "allows for removing a user from an organization"
+	active:      true
+}
+
+_usersV1Get: #Permission & {
+	resource:    "/api/v1/users"
+	operation:   "GET"
+	description: "allows for listing all users"
+	active:      true
+}
+
+_usersV1GetByExtlID: #Permission & {
+	resource:    "/api/v1/users/{extlID}"
+	operation:   "GET"
+	description: "allows for retrieving a user"
+	active:      true
+}
+
+_usersV1Put: #Permission & {
+	resource:    "/api/v1/users/{extlID}"
+	operation:   "PUT"
+	description: "allows for updating the profile of a user"
+	active:      true
+}
+
+_usersV1SuspensionPut: #Permission & {
+	resource:    "/api/v1/users/{extlID}/suspension"
+	operation:   "PUT"
+	description: "allows for suspending a user, which prevents them from authenticating"
+	active:      true
+}
+
+_usersV1SuspensionDelete: #Permission & {
+	resource:    "/api/v1/users/{extlID}/suspension"
+	operation:   "DELETE"
+	description: "allows for reinstating a suspended user"
+	active:      true
+}
+
+_orgsV1InvitationsPost: #Permission & {
+	resource:    "/api/v1/orgs/{extlID}/invitations"
+	operation:   "POST"
+	description: "allows for inviting a person, by email, to join an org with a role"
+	active:      true
+}
+
+_orgsV1InvitationsGet: #Permission & {
+	resource:    "/api/v1/orgs/{extlID}/invitations"
+	operation:   "GET"
+	description: "allows for listing the invitations of an org"
+	active:      true
+}
+
+_orgsV1InvitationsResendPost: #Permission & {
+	resource:    "/api/v1/orgs/{extlID}/invitations/{invitationExtlID}/resend"
+	operation:   "POST"
+	description: "allows for issuing a new token for an invitation"
+	active:      true
+}
+
+_orgsV1InvitationsDelete: #Permission & {
+	resource:    "/api/v1/orgs/{extlID}/invitations/{invitationExtlID}"
+	operation:   "DELETE"
+	description: "allows for revoking an invitation"
+	active:      true
+}
+
+_appsV1Get: #Permission & {
+	resource:    "/api/v1/apps"
+	operation:   "GET"
+	description: "allows for listing the apps of an organization"
+	active:      true
+}
+
+_appsV1GetByExtlID: #Permission & {
+	resource:    "/api/v1/apps/{extlID}"
+	operation:   "GET"
+	description: "allows for finding an app by external ID"
+	active:      true
+}
+
+_appsV1Put: #Permission & {
+	resource:    "/api/v1/apps/{extlID}"
+	operation:   "PUT"
+	description: "allows for updating an app"
+	active:      true
+}
+
+_appsV1Delete: #Permission & {
+	resource:    "/api/v1/apps/{extlID}"
+	operation:   "DELETE"
+	description: "allows for deleting an app"
+	active:      true
+}
+
+_orgsV1DescendantsGet: #Permission & {
+	resource:    "/api/v1/orgs/{extlID}/descendants"
+	operation:   "GET"
+	description: "allows for listing the descendants of an org"
+	active:      true
+}
+
+_moviesV1RestorePost: #Permission & {
+	resource:    "/api/v1/movies/{extlID}/restore"
+	operation:   "POST"
+	description: "allows for restoring a deleted movie"
+	active:      true
+}
+
+_orgsV1RestorePost: #Permission & {
+	resource:    "/api/v1/orgs/{extlID}/restore"
+	operation:   "POST"
+	description: "allows for restoring a deleted org"
+	active:      true
+}
+
+_appsV1RestorePost: #Permission & {
+	resource:    "/api/v1/apps/{extlID}/restore"
+	operation:   "POST"
+	description: "allows for restoring a deleted app"
+	active:      true
+}
+
+_orgKindsV1Post: #Permission & {
+	resource:    "/api/v1/orgkinds"
+	operation:   "POST"
+	description: "allows for creating an org kind"
+	active:      true
+}
+
+_orgKindsV1Get: #Permission & {
+	resource:    "/api/v1/orgkinds"
+	operation:   "GET"
+	description: "allows for listing all org kinds"
+	active:      true
+}
+
+_orgKindsV1GetByExtlID: #Permission & {
+	resource:    "/api/v1/orgkinds/{extlID}"
+	operation:   "GET"
+	description: "allows for finding an org kind by its external ID"
+	active:      true
+}
+
+_orgKindsV1Put: #Permission & {
+	resource:    "/api/v1/orgkinds/{extlID}"
+	operation:   "PUT"
+	description: "allows for updating an org kind"
+	active:      true
+}
+
+_orgKindsV1Delete: #Permission & {
+	resource:    "/api/v1/orgkinds/{extlID}"
+	operation:   "DELETE"
+	description: "allows for deleting an org kind"
+	active:      true
+}
+
+_usersV1LanguagesGet: #Permission & {
+	resource:    "/api/v1/users/{extlID}/languages"
+	operation:   "GET"
+	description: "allows for finding the language preferences of a user"
+	active:      true
+}
+
+_usersV1LanguagesPut: #Permission & {
+	resource:    "/api/v1/users/{extlID}/languages"
+	operation:   "PUT"
+	description: "allows for replacing the language preferences of a user"
+	active:      true
+}
+
 _sysAdmin: #Role & {
 	role_cd:          "sysAdmin"
 	role_description: "System administrator role."
 	active:           true
 	permissions: [_pingV1Get, _loggerV1Get, _loggerV1Put, _orgsV1Post, _orgsV1Put, _orgsV1Delete, _orgsV1Get, _orgsV1GetByExtlID, _appsV1Post,
 		_permissionsV1Post, _permissionsV1Get, _permissionsV1Delete, _moviesV1Post, _moviesV1UpdateByExtlID, _moviesV1DeleteByExtlID,
-		_moviesV1FindByExtlID, _moviesV1FindAll]
+		_moviesV1FindByExtlID, _moviesV1FindAll,
+		_appsV1KeysPost, _appsV1KeysGet, _appsV1KeysDelete,
+		_rolesV1Post, _rolesV1Get, _rolesV1GetByExtlID, _rolesV1Put, _rolesV1Delete, _rolesV1PermissionsPost, _rolesV1PermissionsDelete,
+		_orgsV1UserRolesPut, _orgsV1UserRolesDelete, _orgsV1RoleUsersGet,
+		_rolesV1ParentsPut, _rolesV1ParentsDelete,
+		_rolesV1GrantsExpiredGet, _rolesV1GrantsExpiredDelete,
+		_authzV1DecisionsGet,
+		_orgsV1UsersGet, _orgsV1UsersDelete, _usersV1Get, _usersV1GetByExtlID, _usersV1Put,
+		_usersV1SuspensionPut, _usersV1SuspensionDelete,
+		_orgsV1InvitationsPost, _orgsV1InvitationsGet, _orgsV1InvitationsResendPost, _orgsV1InvitationsDelete,
+		_appsV1Get, _appsV1GetByExtlID, _appsV1Put, _appsV1Delete,
+		_orgsV1DescendantsGet,
+		_moviesV1RestorePost, _orgsV1RestorePost, _appsV1RestorePost,
+		_orgKindsV1Post, _orgKindsV1Get, _orgKindsV1GetByExtlID, _orgKindsV1Put, _orgKindsV1Delete,
+		_usersV1LanguagesGet, _usersV1LanguagesPut]
+}
+
+_orgAdmin: #Role & {
+	role_cd:          "orgAdmin"
+	role_description: "Organization administrator role. May modify any resource owned by the organization."
+	active:           true
+	permissions: [_orgsV1Put, _orgsV1Delete, _orgsV1GetByExtlID, _orgsV1DescendantsGet, _appsV1Post, _appsV1KeysPost, _appsV1KeysGet, _appsV1KeysDelete,
+		_appsV1Get, _appsV1GetByExtlID, _appsV1Put, _appsV1Delete,
+		_orgsV1UserRolesPut, _orgsV1UserRolesDelete, _orgsV1RoleUsersGet, _orgsV1UsersGet, _orgsV1UsersDelete,
+		_orgsV1InvitationsPost, _orgsV1InvitationsGet, _orgsV1InvitationsResendPost, _orgsV1InvitationsDelete,
+		_moviesV1Post, _moviesV1UpdateByExtlID, _moviesV1DeleteByExtlID, _moviesV1FindByExtlID, _moviesV1FindAll,
+		_moviesV1RestorePost, _orgsV1RestorePost, _appsV1RestorePost]
 }
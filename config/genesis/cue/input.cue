@@ -3,7 +3,7 @@ package genesis
 // The "genesis" user - the first user to create the system and is
 // given the sysAdmin role (which has all permissions). This user is
 // added to the Principal org and the user initiated org created below.
-// Add the Oauth2 provider (currently only google is supported) and the
+// Add the Oauth2 provider (google, github or oidc are supported) and the
 // Oauth2 token to be used to create the user.
 user: provider: "google"
 user: token:    "REPLACE_ME"
@@ -7,15 +7,29 @@ user: #User
 org:  #Org
 permissions: [_pingV1Get, _loggerV1Get, _loggerV1Put, _orgsV1Post, _orgsV1Put, _orgsV1Delete, _orgsV1Get,
 	_orgsV1GetByExtlID, _appsV1Post, _permissionsV1Post, _permissionsV1Get,
-	_moviesV1Post, _moviesV1UpdateByExtlID, _moviesV1DeleteByExtlID, _moviesV1FindByExtlID, _moviesV1FindAll]
-roles: [_sysAdmin]
+	_moviesV1Post, _moviesV1UpdateByExtlID, _moviesV1DeleteByExtlID, _moviesV1FindByExtlID, _moviesV1FindAll,
+	_appsV1KeysPost, _appsV1KeysGet, _appsV1KeysDelete,
+	_rolesV1Post, _rolesV1Get, _rolesV1GetByExtlID, _rolesV1Put, _rolesV1Delete, _rolesV1PermissionsPost, _rolesV1PermissionsDelete,
+	_orgsV1UserRolesPut, _orgsV1UserRolesDelete, _orgsV1RoleUsersGet,
+	_rolesV1ParentsPut, _rolesV1ParentsDelete,
+	_rolesV1GrantsExpiredGet, _rolesV1GrantsExpiredDelete,
+	_authzV1DecisionsGet,
+	_orgsV1UsersGet, _orgsV1UsersDelete, _usersV1Get, _usersV1GetByExtlID, _usersV1Put,
+	_usersV1SuspensionPut, _usersV1SuspensionDelete,
+	_orgsV1InvitationsPost, _orgsV1InvitationsGet, _orgsV1InvitationsResendPost, _orgsV1InvitationsDelete,
+	_appsV1Get, _appsV1GetByExtlID, _appsV1Put, _appsV1Delete,
+	_orgsV1DescendantsGet,
+	_moviesV1RestorePost, _orgsV1RestorePost, _appsV1RestorePost,
+	_orgKindsV1Post, _orgKindsV1Get, _orgKindsV1GetByExtlID, _orgKindsV1Put, _orgKindsV1Delete,
+	_usersV1LanguagesGet, _usersV1LanguagesPut]
+roles: [_sysAdmin, _orgAdmin]
 
 #User: {
 	provider: #Oauth2Provider
 	token:    !="" // must be specified and non-empty
 }
 
-#Oauth2Provider: "google"
+#Oauth2Provider: "google" | "github" | "oidc"
 
 #Org: {
 	name:        !="" // must be specified and non-empty
@@ -49,6 +63,11 @@ roles: [_sysAdmin]
 	active: bool
 	// A list of permissions that the role allows
 	permissions: [...#Permission]
+	// A list of permissions that the role explicitly denies. A deny takes precedence over any allow.
+	denied_permissions?: [...#Permission]
+	// The codes of the roles this role inherits all permissions from.
+	// Parent roles must be listed before the roles that inherit from them.
+	parent_roles?: [...string]
 }
 
 // Permission stores an approval of a mode of access to a resource.
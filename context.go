@@ -5,6 +5,8 @@ import (
 	"net/http"
 	"time"
 
+	"golang.org/x/text/language"
+
 	"github.com/gilcrest/diygoapi/errs"
 )
 
@@ -13,7 +15,9 @@ type contextKey string
 const (
 	appContextKey        = contextKey("app")
 	contextKeyUser       = contextKey("user")
+	orgContextKey        = contextKey("org")
 	authParamsContextKey = contextKey("authParams")
+	languageContextKey   = contextKey("language")
 )
 
 // NewContextWithApp returns a new context with the given App
@@ -65,9 +69,27 @@ func UserFromRequest(r *http.Request) (u *User, err error) {
 	return u, nil
 }
 
+// NewContextWithOrg returns a new context with the given Org, which
+// is the Org selected by the User to act in for the request
+func NewContextWithOrg(ctx context.Context, o *Org) context.Context {
+	return context.WithValue(ctx, orgContextKey, o)
+}
+
+// OrgFromContext returns the Org selected for the request from the
+// given context
+func OrgFromContext(ctx context.Context) (*Org, error) {
+	const op errs.Op = "diygoapi/OrgFromContext"
+
+	o, ok := ctx.Value(orgContextKey).(*Org)
+	if !ok {
+		return o, errs.E(op, errs.NotExist, "Org not set to context")
+	}
+	return o, nil
+}
+
 // AuditFromRequest is a convenience function that sets up an Audit
-// struct from the App and User set to the request context.
-// The moment is also set to time.Now
+// struct from the App, User and selected Org (if any) set to the
+// request context. The moment is also set to time.Now
 func AuditFromRequest(r *http.Request) (adt Audit, err error) {
 	const op errs.Op = "diygoapi/AuditFromRequest"
 
@@ -85,6 +107,11 @@ func AuditFromRequest(r *http.Request) (adt Audit, err error) {
 
 	adt.App = a
 	adt.User = u
+	// the selected Org is optional, if not set, the
+	// User is acting in the App's Org
+	if o, oerr := OrgFromContext(r.Context()); oerr == nil {
+		adt.Org = o
+	}
 	adt.Moment = time.Now()
 
 	return adt, nil
@@ -105,3 +132,21 @@ func AuthParamsFromContext(ctx context.Context) (*AuthenticationParams, error) {
 	}
 	return a, nil
 }
+
+// NewContextWithLanguage returns a new context with the given language
+// tag, which is the language negotiated for the request
+func NewContextWithLanguage(ctx context.Context, tag language.Tag) context.Context {
+	return context.WithValue(ctx, languageContextKey, tag)
+}
+
+// LanguageFromContext returns the language negotiated for the request
+// from the given context
+func LanguageFromContext(ctx context.Context) (language.Tag, error) {
+	const op errs.Op = "diygoapi/LanguageFromContext"
+
+	tag, ok := ctx.Value(languageContextKey).(language.Tag)
+	if !ok {
+		return language.Und, errs.E(op, errs.NotExist, "Language not set to context")
+	}
+	return tag, nil
+}
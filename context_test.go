@@ -102,3 +102,38 @@ func TestUserFromRequest(t *testing.T) {
 		c.Assert(got, qt.IsNil)
 	})
 }
+
+func TestAuditFromRequest(t *testing.T) {
+	u := &User{
+		ID:         uuid.New(),
+		ExternalID: secure.NewID(),
+		FirstName:  "Otto",
+		LastName:   "Maddox",
+		FullName:   "Otto Maddox",
+		Email:      "otto.maddox@helpinghandacceptanceco.com",
+	}
+	a := &App{ID: uuid.New(), Org: &Org{ID: uuid.New()}}
+
+	t.Run("app org", func(t *testing.T) {
+		c := qt.New(t)
+
+		r := httptest.NewRequest(http.MethodGet, "/api/v1/movies", nil)
+		ctx := NewContextWithUser(NewContextWithApp(context.Background(), a), u)
+
+		adt, err := AuditFromRequest(r.WithContext(ctx))
+		c.Assert(err, qt.IsNil)
+		c.Assert(adt.Org, qt.IsNil)
+		c.Assert(adt.ActingOrg(), qt.Equals, a.Org)
+	})
+	t.Run("selected org", func(t *testing.T) {
+		c := qt.New(t)
+
+		o := &Org{ID: uuid.New()}
+		r := httptest.NewRequest(http.MethodGet, "/api/v1/movies", nil)
+		ctx := NewContextWithOrg(NewContextWithUser(NewContextWithApp(context.Background(), a), u), o)
+
+		adt, err := AuditFromRequest(r.WithContext(ctx))
+		c.Assert(err, qt.IsNil)
+		c.Assert(adt.ActingOrg(), qt.Equals, o)
+	})
+}
@@ -42,6 +42,16 @@ type PingResponse struct {
 	DBUp bool `json:"db_up"`
 }
 
+// PurgeResponse holds the number of deleted records which were
+// permanently removed by a purge. Records which are still referenced
+// by other records cannot be removed and are counted as skipped.
+type PurgeResponse struct {
+	Movies  int64 `json:"movies"`
+	Apps    int64 `json:"apps"`
+	Orgs    int64 `json:"orgs"`
+	Skipped int64 `json:"skipped"`
+}
+
 // NewNullString returns a null if s is empty, otherwise it returns
 // the string which was input
 func NewNullString(s string) sql.NullString {
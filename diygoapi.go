@@ -23,11 +23,23 @@ type GenesisServicer interface {
 
 // Audit represents the moment an App/User interacted with the system.
 type Audit struct {
-	App    *App
-	User   *User
+	App  *App
+	User *User
+	// Org is the Org selected by the User for the request (e.g. via
+	// the X-ORG-ID header). If nil, the User is acting in the App's Org.
+	Org    *Org
 	Moment time.Time
 }
 
+// ActingOrg returns the Org the User is acting in: the Org selected
+// for the request if one was selected, otherwise the App's Org.
+func (a Audit) ActingOrg() *Org {
+	if a.Org != nil {
+		return a.Org
+	}
+	return a.App.Org
+}
+
 // SimpleAudit captures the first time a record was written as well
 // as the last time the record was updated. The first time a record
 // is written Create and Update will be identical.
@@ -4,6 +4,11 @@ package gateway
 
 import (
 	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
 	"time"
 
 	"golang.org/x/oauth2"
@@ -14,18 +19,41 @@ import (
 	"github.com/gilcrest/diygoapi/errs"
 )
 
+// defaultGitHubBaseURL is the base URL for the public GitHub REST API
+const defaultGitHubBaseURL string = "https://api.github.com"
+
 // Oauth2TokenExchange is used to convert an oauth2.Token to a ProviderInfo
 // struct from details returned from a provider API
-type Oauth2TokenExchange struct{}
+type Oauth2TokenExchange struct {
+	// GitHubBaseURL is the base URL of the GitHub REST API. If empty,
+	// the public GitHub API (https://api.github.com) is used. It can be
+	// set to point to GitHub Enterprise Server or to a test server.
+	GitHubBaseURL string
+
+	// OIDC is the exchanger used for the generic OpenID Connect
+	// provider. If nil, the OIDC provider is not supported.
+	OIDC *OIDCTokenExchange
+}
 
-// Exchange calls the Google Userinfo API with the access token and converts
-// the Userinfo struct to a User struct
+// Exchange calls the provider's user information API(s) with the access
+// token and converts the response(s) to a ProviderInfo struct
 func (e Oauth2TokenExchange) Exchange(ctx context.Context, realm string, provider diygoapi.Provider, token *oauth2.Token) (*diygoapi.ProviderInfo, error) {
 	const op errs.Op = "gateway/Oauth2TokenExchange.Exchange"
 
 	switch provider {
 	case diygoapi.Google:
 		return googleTokenExchange(ctx, realm, token)
+	case diygoapi.GitHub:
+		baseURL := e.GitHubBaseURL
+		if baseURL == "" {
+			baseURL = defaultGitHubBaseURL
+		}
+		return githubTokenExchange(ctx, realm, baseURL, token)
+	case diygoapi.OIDC:
+		if e.OIDC == nil {
+			return nil, errs.E(op, errs.Unauthenticated, errs.Realm(realm), "OIDC provider not configured")
+		}
+		return e.OIDC.Exchange(ctx, realm, provider, token)
 	default:
 		return nil, errs.E(op, errs.Unauthenticated, errs.Realm(realm), "provider not recognized")
 	}
@@ -89,16 +117,17 @@ func googleTokenExchange(ctx context.Context, realm string, token *oauth2.Token)
 	}
 
 	pui := diygoapi.ProviderUserInfo{
-		ExternalID:   userinfo.Id,
-		Email:        userinfo.Email,
-		FirstName:    userinfo.GivenName,
-		LastName:     userinfo.FamilyName,
-		FullName:     userinfo.Name,
-		Gender:       userinfo.Gender,
-		HostedDomain: userinfo.Hd,
-		ProfileLink:  userinfo.Link,
-		Locale:       userinfo.Locale,
-		Picture:      userinfo.Picture,
+		ExternalID:    userinfo.Id,
+		Email:         userinfo.Email,
+		EmailVerified: userinfo.VerifiedEmail != nil && *userinfo.VerifiedEmail,
+		FirstName:     userinfo.GivenName,
+		LastName:      userinfo.FamilyName,
+		FullName:      userinfo.Name,
+		Gender:        userinfo.Gender,
+		HostedDomain:  userinfo.Hd,
+		ProfileLink:   userinfo.Link,
+		Locale:        userinfo.Locale,
+		Picture:       userinfo.Picture,
 	}
 
 	pi := diygoapi.ProviderInfo{
@@ -109,3 +138,152 @@ func googleTokenExchange(ctx context.Context, realm string, token *oauth2.Token)
 
 	return &pi, nil
 }
+
+// githubUser is the subset of the GitHub "Get the authenticated user"
+// API response (GET /user) used to populate ProviderUserInfo
+type githubUser struct {
+	ID        int64  `json:"id"`
+	Login     string `json:"login"`
+	Name      string `json:"name"`
+	Email     string `json:"email"`
+	AvatarURL string `json:"avatar_url"`
+	HTMLURL   string `json:"html_url"`
+}
+
+// githubEmail is an element of the GitHub "List email addresses for
+// the authenticated user" API response (GET /user/emails)
+type githubEmail struct {
+	Email    string `json:"email"`
+	Primary  bool   `json:"primary"`
+	Verified bool   `json:"verified"`
+}
+
+// githubTokenExchange makes requests to the GitHub REST API and
+// populates ProviderInfo based on the responses.
+//
+// GitHub does not have a token info endpoint which can be called
+// with only the user's access token, so token details are pulled
+// from the response headers GitHub sends back for OAuth tokens.
+func githubTokenExchange(ctx context.Context, realm, baseURL string, token *oauth2.Token) (*diygoapi.ProviderInfo, error) {
+	const op errs.Op = "gateway/githubTokenExchange"
+
+	// the oauth2 client sets the Authorization header using the token
+	client := oauth2.NewClient(ctx, oauth2.StaticTokenSource(token))
+
+	var gu githubUser
+	header, err := githubGet(ctx, client, baseURL+"/user", &gu)
+	if err != nil {
+		return nil, errs.E(op, errs.Unauthenticated, errs.Realm(realm), err)
+	}
+
+	if gu.ID == 0 {
+		return nil, errs.E(op, errs.Unauthenticated, errs.Realm(realm), "no user ID returned from GitHub")
+	}
+
+	pti := diygoapi.ProviderTokenInfo{
+		Expiration: parseGitHubTokenExpiration(header.Get("GitHub-Authentication-Token-Expiration")),
+		ClientID:   header.Get("X-OAuth-Client-Id"),
+		Scope:      strings.Join(strings.Fields(strings.ReplaceAll(header.Get("X-OAuth-Scopes"), ",", " ")), " "),
+	}
+
+	// the email on the user profile is only the publicly visible
+	// email, if it is not set, look for the primary verified email.
+	// GitHub only allows a verified email to be made public, so
+	// either way the email is verified.
+	email := gu.Email
+	if email == "" {
+		var emails []githubEmail
+		_, err = githubGet(ctx, client, baseURL+"/user/emails", &emails)
+		if err != nil {
+			return nil, errs.E(op, errs.Unauthenticated, errs.Realm(realm), err)
+		}
+		for _, ge := range emails {
+			if ge.Primary && ge.Verified {
+				email = ge.Email
+				break
+			}
+		}
+	}
+
+	firstName, lastName := splitGitHubName(gu.Name)
+	if firstName == "" {
+		firstName = gu.Login
+	}
+
+	pui := diygoapi.ProviderUserInfo{
+		ExternalID:    strconv.FormatInt(gu.ID, 10),
+		Email:         email,
+		EmailVerified: email != "",
+		FirstName:     firstName,
+		LastName:      lastName,
+		FullName:      gu.Name,
+		Nickname:      gu.Login,
+		ProfileLink:   gu.HTMLURL,
+		Picture:       gu.AvatarURL,
+	}
+
+	pi := diygoapi.ProviderInfo{
+		Provider:  diygoapi.GitHub,
+		TokenInfo: &pti,
+		UserInfo:  &pui,
+	}
+
+	return &pi, nil
+}
+
+// githubGet sends a GET request to the GitHub API url and decodes the
+// JSON response body into v. The response header is returned as GitHub
+// sends token details as part of it.
+func githubGet(ctx context.Context, client *http.Client, url string, v any) (http.Header, error) {
+	const op errs.Op = "gateway/githubGet"
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, errs.E(op, err)
+	}
+	req.Header.Set("Accept", "application/vnd.github+json")
+
+	var resp *http.Response
+	resp, err = client.Do(req)
+	if err != nil {
+		return nil, errs.E(op, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, errs.E(op, fmt.Sprintf("GitHub API %s returned status %d", req.URL.Path, resp.StatusCode))
+	}
+
+	err = json.NewDecoder(resp.Body).Decode(v)
+	if err != nil {
+		return nil, errs.E(op, err)
+	}
+
+	return resp.Header, nil
+}
+
+// parseGitHubTokenExpiration parses the GitHub-Authentication-Token-Expiration
+// header value. GitHub only sends the header for tokens which expire, so
+// a zero time.Time is returned if the value is empty or cannot be parsed.
+func parseGitHubTokenExpiration(s string) time.Time {
+	for _, layout := range []string{"2006-01-02 15:04:05 MST", "2006-01-02 15:04:05 -0700"} {
+		t, err := time.Parse(layout, s)
+		if err == nil {
+			return t
+		}
+	}
+	return time.Time{}
+}
+
+// splitGitHubName splits the single name field from a GitHub profile
+// into a first name and last name. The last word is taken as the last name.
+func splitGitHubName(name string) (first, last string) {
+	fields := strings.Fields(name)
+	switch len(fields) {
+	case 0:
+		return "", ""
+	case 1:
+		return fields[0], ""
+	}
+	return strings.Join(fields[:len(fields)-1], " "), fields[len(fields)-1]
+}
@@ -0,0 +1,93 @@
+package gateway_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	qt "github.com/frankban/quicktest"
+	"golang.org/x/oauth2"
+
+	"github.com/gilcrest/diygoapi"
+	"github.com/gilcrest/diygoapi/errs"
+	"github.com/gilcrest/diygoapi/gateway"
+)
+
+// newGitHubTestServer returns an httptest.Server which stands in for
+// the GitHub REST API /user and /user/emails endpoints.
+func newGitHubTestServer(t *testing.T, userJSON, emailsJSON string) *httptest.Server {
+	t.Helper()
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/user", func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer good-token" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		w.Header().Set("X-OAuth-Client-Id", "gh-client-id")
+		w.Header().Set("X-OAuth-Scopes", "read:user, user:email")
+		w.Header().Set("GitHub-Authentication-Token-Expiration", "2099-01-02 03:04:05 UTC")
+		_, _ = w.Write([]byte(userJSON))
+	})
+	mux.HandleFunc("/user/emails", func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(emailsJSON))
+	})
+
+	srv := httptest.NewServer(mux)
+	t.Cleanup(srv.Close)
+
+	return srv
+}
+
+func TestOauth2TokenExchange_Exchange(t *testing.T) {
+	t.Run("github", func(t *testing.T) {
+		c := qt.New(t)
+
+		const (
+			userJSON   = `{"id": 1234, "login": "octocat", "name": "Mona Lisa Octocat", "email": null, "avatar_url": "https://example.com/a.png", "html_url": "https://github.com/octocat"}`
+			emailsJSON = `[{"email": "other@example.com", "primary": false, "verified": true}, {"email": "octocat@example.com", "primary": true, "verified": true}]`
+		)
+		srv := newGitHubTestServer(t, userJSON, emailsJSON)
+
+		e := gateway.Oauth2TokenExchange{GitHubBaseURL: srv.URL}
+		token := &oauth2.Token{AccessToken: "good-token", TokenType: diygoapi.BearerTokenType}
+
+		pi, err := e.Exchange(context.Background(), "test", diygoapi.GitHub, token)
+		c.Assert(err, qt.IsNil)
+		c.Assert(pi.Provider, qt.Equals, diygoapi.GitHub)
+		c.Assert(pi.TokenInfo.ClientID, qt.Equals, "gh-client-id")
+		c.Assert(pi.TokenInfo.Scope, qt.Equals, "read:user user:email")
+		c.Assert(pi.TokenInfo.Expiration, qt.Equals, time.Date(2099, 1, 2, 3, 4, 5, 0, time.UTC))
+		c.Assert(pi.UserInfo.ExternalID, qt.Equals, "1234")
+		c.Assert(pi.UserInfo.Email, qt.Equals, "octocat@example.com")
+		c.Assert(pi.UserInfo.EmailVerified, qt.IsTrue)
+		c.Assert(pi.UserInfo.FirstName, qt.Equals, "Mona Lisa")
+		c.Assert(pi.UserInfo.LastName, qt.Equals, "Octocat")
+		c.Assert(pi.UserInfo.FullName, qt.Equals, "Mona Lisa Octocat")
+		c.Assert(pi.UserInfo.Nickname, qt.Equals, "octocat")
+		c.Assert(pi.UserInfo.ProfileLink, qt.Equals, "https://github.com/octocat")
+		c.Assert(pi.UserInfo.Picture, qt.Equals, "https://example.com/a.png")
+	})
+	t.Run("github bad token", func(t *testing.T) {
+		c := qt.New(t)
+
+		srv := newGitHubTestServer(t, `{}`, `[]`)
+
+		e := gateway.Oauth2TokenExchange{GitHubBaseURL: srv.URL}
+		token := &oauth2.Token{AccessToken: "bad-token", TokenType: diygoapi.BearerTokenType}
+
+		_, err := e.Exchange(context.Background(), "test", diygoapi.GitHub, token)
+		c.Assert(errs.KindIs(errs.Unauthenticated, err), qt.IsTrue)
+	})
+	t.Run("unknown provider", func(t *testing.T) {
+		c := qt.New(t)
+
+		e := gateway.Oauth2TokenExchange{}
+		token := &oauth2.Token{AccessToken: "good-token", TokenType: diygoapi.BearerTokenType}
+
+		_, err := e.Exchange(context.Background(), "test", diygoapi.UnknownProvider, token)
+		c.Assert(errs.KindIs(errs.Unauthenticated, err), qt.IsTrue)
+	})
+}
@@ -0,0 +1,570 @@
+package gateway
+
+import (
+	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"golang.org/x/oauth2"
+
+	"github.com/gilcrest/diygoapi"
+	"github.com/gilcrest/diygoapi/errs"
+)
+
+const (
+	// oidcClockSkew is the leeway allowed when validating the
+	// time based claims (exp, nbf) of an ID token
+	oidcClockSkew = time.Minute
+	// jwksMaxAge is how long a fetched JSON Web Key Set is trusted
+	// before it is fetched again
+	jwksMaxAge = 24 * time.Hour
+	// jwksMissInterval is the minimum time between two fetches of
+	// the JSON Web Key Set triggered by an unknown key ID. It keeps
+	// tokens with made up key IDs from hammering the provider.
+	jwksMissInterval = time.Minute
+)
+
+// OIDCConfig is the configuration for an OpenID Connect provider
+type OIDCConfig struct {
+	// Issuer is the expected value of the iss claim, e.g.
+	// https://dev-123456.okta.com/oauth2/default
+	Issuer string
+	// Audience is the expected value of the aud claim, typically
+	// the OAuth2 client ID of the app the ID token was issued to
+	Audience string
+	// JWKSURL is the URL of the provider's JSON Web Key Set
+	JWKSURL string
+	// HTTPClient is used to fetch the JSON Web Key Set. If nil,
+	// http.DefaultClient is used.
+	HTTPClient *http.Client
+}
+
+// OIDCTokenExchange is a TokenExchanger for a generic OpenID Connect
+// provider (Okta, Auth0, Keycloak, Dex, etc.). The bearer token is
+// expected to be an ID token (a JWT), which is verified locally against
+// the provider's JSON Web Key Set (signature, exp, nbf, aud, iss and
+// nonce). No call is made to the provider per token, the key set is
+// cached and only fetched again when it has aged out or a token
+// signed with an unknown key ID is received (key rotation).
+type OIDCTokenExchange struct {
+	issuer   string
+	audience string
+	keys     *jwksCache
+}
+
+// NewOIDCTokenExchange initializes an OIDCTokenExchange given an OIDCConfig
+func NewOIDCTokenExchange(c OIDCConfig) (*OIDCTokenExchange, error) {
+	const op errs.Op = "gateway/NewOIDCTokenExchange"
+
+	switch {
+	case c.Issuer == "":
+		return nil, errs.E(op, errs.Validation, "OIDC issuer is required")
+	case c.Audience == "":
+		return nil, errs.E(op, errs.Validation, "OIDC audience is required")
+	case c.JWKSURL == "":
+		return nil, errs.E(op, errs.Validation, "OIDC JWKS URL is required")
+	}
+
+	client := c.HTTPClient
+	if client == nil {
+		client = http.DefaultClient
+	}
+
+	return &OIDCTokenExchange{
+		issuer:   c.Issuer,
+		audience: c.Audience,
+		keys:     &jwksCache{url: c.JWKSURL, client: client},
+	}, nil
+}
+
+// Exchange verifies the ID token sent as the access token and converts
+// its claims to a ProviderInfo struct
+func (e *OIDCTokenExchange) Exchange(ctx context.Context, realm string, provider diygoapi.Provider, token *oauth2.Token) (*diygoapi.ProviderInfo, error) {
+	const op errs.Op = "gateway/OIDCTokenExchange.Exchange"
+
+	if provider != diygoapi.OIDC {
+		return nil, errs.E(op, errs.Unauthenticated, errs.Realm(realm), "provider not recognized")
+	}
+
+	claims, err := e.verify(ctx, token.AccessToken)
+	if err != nil {
+		return nil, errs.E(op, errs.Unauthenticated, errs.Realm(realm), err)
+	}
+
+	// the client must send the nonce it used to request the ID token
+	// and it must match the nonce claim, otherwise a captured ID token
+	// could be replayed
+	nonce, _ := token.Extra(diygoapi.NonceTokenExtraKey).(string)
+	switch {
+	case nonce == "":
+		return nil, errs.E(op, errs.Unauthenticated, errs.Realm(realm), "a nonce is required for an OIDC ID token")
+	case claims.Nonce == "" || nonce != claims.Nonce:
+		return nil, errs.E(op, errs.Unauthenticated, errs.Realm(realm), "ID token nonce does not match")
+	}
+
+	// an email the provider has not verified is not trusted
+	var email string
+	if claims.EmailVerified {
+		email = claims.Email
+	}
+
+	nickname := claims.Nickname
+	if nickname == "" {
+		nickname = claims.PreferredUsername
+	}
+
+	pti := diygoapi.ProviderTokenInfo{
+		Expiration: time.Unix(int64(claims.Expiry), 0),
+		ClientID:   e.audience,
+		Scope:      claims.Scope,
+	}
+
+	pui := diygoapi.ProviderUserInfo{
+		ExternalID:    claims.Subject,
+		Email:         email,
+		EmailVerified: bool(claims.EmailVerified),
+		MiddleName:    claims.MiddleName,
+		FirstName:     claims.GivenName,
+		LastName:      claims.FamilyName,
+		FullName:      claims.Name,
+		Nickname:      nickname,
+		Gender:        claims.Gender,
+		HostedDomain:  claims.HostedDomain,
+		ProfileLink:   claims.Profile,
+		Locale:        claims.Locale,
+		Picture:       claims.Picture,
+	}
+
+	pi := diygoapi.ProviderInfo{
+		Provider:  diygoapi.OIDC,
+		TokenInfo: &pti,
+		UserInfo:  &pui,
+	}
+
+	return &pi, nil
+}
+
+// jwtHeader is the JOSE header of a signed JWT
+type jwtHeader struct {
+	Algorithm string `json:"alg"`
+	KeyID     string `json:"kid"`
+}
+
+// oidcClaims are the ID token claims used to populate ProviderInfo
+type oidcClaims struct {
+	Issuer            string      `json:"iss"`
+	Subject           string      `json:"sub"`
+	Audience          audience    `json:"aud"`
+	AuthorizedParty   string      `json:"azp"`
+	Expiry            numericDate `json:"exp"`
+	NotBefore         numericDate `json:"nbf"`
+	Nonce             string      `json:"nonce"`
+	Scope             string      `json:"scope"`
+	Email             string      `json:"email"`
+	EmailVerified     flexBool    `json:"email_verified"`
+	Name              string      `json:"name"`
+	GivenName         string      `json:"given_name"`
+	FamilyName        string      `json:"family_name"`
+	MiddleName        string      `json:"middle_name"`
+	Nickname          string      `json:"nickname"`
+	PreferredUsername string      `json:"preferred_username"`
+	Profile           string      `json:"profile"`
+	Picture           string      `json:"picture"`
+	Gender            string      `json:"gender"`
+	Locale            string      `json:"locale"`
+	HostedDomain      string      `json:"hd"`
+}
+
+// audience is the aud claim, which can be either a single
+// string or an array of strings
+type audience []string
+
+// UnmarshalJSON implements the json.Unmarshaler interface
+func (a *audience) UnmarshalJSON(b []byte) error {
+	var s string
+	if err := json.Unmarshal(b, &s); err == nil {
+		*a = audience{s}
+		return nil
+	}
+	var ss []string
+	if err := json.Unmarshal(b, &ss); err != nil {
+		return err
+	}
+	*a = ss
+	return nil
+}
+
+func (a audience) contains(s string) bool {
+	for _, v := range a {
+		if v == s {
+			return true
+		}
+	}
+	return false
+}
+
+// flexBool is a boolean claim. Some providers send boolean claims
+// (e.g. email_verified) as the strings "true" and "false".
+type flexBool bool
+
+// UnmarshalJSON implements the json.Unmarshaler interface
+func (f *flexBool) UnmarshalJSON(b []byte) error {
+	var v bool
+	if err := json.Unmarshal(b, &v); err == nil {
+		*f = flexBool(v)
+		return nil
+	}
+	var s string
+	if err := json.Unmarshal(b, &s); err != nil {
+		return err
+	}
+	v, err := strconv.ParseBool(s)
+	if err != nil {
+		return fmt.Errorf("invalid boolean %s", b)
+	}
+	*f = flexBool(v)
+	return nil
+}
+
+// numericDate is a JWT NumericDate: seconds since the Unix epoch,
+// which may be sent with a fractional part
+type numericDate int64
+
+// UnmarshalJSON implements the json.Unmarshaler interface
+func (n *numericDate) UnmarshalJSON(b []byte) error {
+	f, err := strconv.ParseFloat(string(b), 64)
+	if err != nil {
+		return fmt.Errorf("invalid NumericDate %s", b)
+	}
+	*n = numericDate(f)
+	return nil
+}
+
+// verify checks the signature of a compact serialized JWT and
+// validates its registered claims
+func (e *OIDCTokenExchange) verify(ctx context.Context, rawToken string) (oidcClaims, error) {
+	parts := strings.Split(rawToken, ".")
+	if len(parts) != 3 {
+		return oidcClaims{}, fmt.Errorf("ID token is not a JWT")
+	}
+
+	var hdr jwtHeader
+	err := decodeJWTSegment(parts[0], &hdr)
+	if err != nil {
+		return oidcClaims{}, fmt.Errorf("invalid JWT header: %w", err)
+	}
+
+	var sig []byte
+	sig, err = base64.RawURLEncoding.DecodeString(parts[2])
+	if err != nil {
+		return oidcClaims{}, fmt.Errorf("invalid JWT signature encoding: %w", err)
+	}
+
+	var key crypto.PublicKey
+	key, err = e.keys.key(ctx, hdr.KeyID)
+	if err != nil {
+		return oidcClaims{}, err
+	}
+
+	err = verifyJWTSignature(hdr.Algorithm, key, []byte(parts[0]+"."+parts[1]), sig)
+	if err != nil {
+		return oidcClaims{}, err
+	}
+
+	var claims oidcClaims
+	err = decodeJWTSegment(parts[1], &claims)
+	if err != nil {
+		return oidcClaims{}, fmt.Errorf("invalid JWT claims: %w", err)
+	}
+
+	now := time.Now()
+	switch {
+	case claims.Issuer != e.issuer:
+		return oidcClaims{}, fmt.Errorf("unexpected ID token issuer %q", claims.Issuer)
+	case !claims.Audience.contains(e.audience):
+		return oidcClaims{}, fmt.Errorf("ID token audience %v does not include %q", []string(claims.Audience), e.audience)
+	case claims.AuthorizedParty != "" && claims.AuthorizedParty != e.audience:
+		return oidcClaims{}, fmt.Errorf("unexpected ID token authorized party %q", claims.AuthorizedParty)
+	case claims.Subject == "":
+		return oidcClaims{}, fmt.Errorf("ID token has no subject")
+	case claims.Expiry == 0:
+		return oidcClaims{}, fmt.Errorf("ID token has no expiration")
+	case now.After(time.Unix(int64(claims.Expiry), 0).Add(oidcClockSkew)):
+		return oidcClaims{}, fmt.Errorf("ID token is expired")
+	case claims.NotBefore != 0 && now.Add(oidcClockSkew).Before(time.Unix(int64(claims.NotBefore), 0)):
+		return oidcClaims{}, fmt.Errorf("ID token is not valid yet")
+	}
+
+	return claims, nil
+}
+
+// decodeJWTSegment base64url decodes a JWT segment and unmarshals
+// the resulting JSON into v
+func decodeJWTSegment(seg string, v any) error {
+	b, err := base64.RawURLEncoding.DecodeString(seg)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(b, v)
+}
+
+// verifyJWTSignature verifies the signature of signed given the JWS
+// algorithm and public key. Only asymmetric algorithms are accepted,
+// "none" and the HMAC (HS*) algorithms are rejected.
+func verifyJWTSignature(alg string, key crypto.PublicKey, signed, sig []byte) error {
+	var h crypto.Hash
+	switch alg {
+	case "RS256", "PS256", "ES256":
+		h = crypto.SHA256
+	case "RS384", "PS384", "ES384":
+		h = crypto.SHA384
+	case "RS512", "PS512", "ES512":
+		h = crypto.SHA512
+	default:
+		return fmt.Errorf("unsupported JWT signing algorithm %q", alg)
+	}
+
+	hasher := h.New()
+	hasher.Write(signed)
+	digest := hasher.Sum(nil)
+
+	switch alg[:2] {
+	case "RS", "PS":
+		pub, ok := key.(*rsa.PublicKey)
+		if !ok {
+			return fmt.Errorf("key type does not match JWT signing algorithm %q", alg)
+		}
+		var err error
+		if alg[0] == 'R' {
+			err = rsa.VerifyPKCS1v15(pub, h, digest, sig)
+		} else {
+			err = rsa.VerifyPSS(pub, h, digest, sig, nil)
+		}
+		if err != nil {
+			return fmt.Errorf("invalid JWT signature")
+		}
+	case "ES":
+		pub, ok := key.(*ecdsa.PublicKey)
+		if !ok {
+			return fmt.Errorf("key type does not match JWT signing algorithm %q", alg)
+		}
+		size := (pub.Curve.Params().BitSize + 7) / 8
+		if len(sig) != 2*size {
+			return fmt.Errorf("invalid JWT signature")
+		}
+		r := new(big.Int).SetBytes(sig[:size])
+		s := new(big.Int).SetBytes(sig[size:])
+		if !ecdsa.Verify(pub, digest, r, s) {
+			return fmt.Errorf("invalid JWT signature")
+		}
+	}
+
+	return nil
+}
+
+// jwksCache caches the public keys of a JSON Web Key Set by key ID.
+// Keys are read under a read lock, the key set is fetched without
+// holding the lock so a slow JWKS endpoint does not hold up tokens
+// signed with a key already cached. Concurrent fetches are merged.
+type jwksCache struct {
+	url    string
+	client *http.Client
+
+	mu       sync.RWMutex
+	keys     map[string]crypto.PublicKey
+	fetched  time.Time
+	lastMiss time.Time
+	// inflight is the fetch in progress, if any
+	inflight *jwksFetch
+}
+
+// jwksFetch is a fetch of the key set, done is closed once it completes
+type jwksFetch struct {
+	done chan struct{}
+	err  error
+}
+
+// key returns the public key for kid, fetching the key set if it has
+// not been fetched yet, has aged out or does not include kid.
+func (c *jwksCache) key(ctx context.Context, kid string) (crypto.PublicKey, error) {
+	c.mu.RLock()
+	k, ok := c.keys[kid]
+	fresh := ok && time.Since(c.fetched) < jwksMaxAge
+	// an unknown kid may mean the provider rotated its keys, but
+	// do not refetch for every token with a made up kid
+	recentMiss := !ok && !c.lastMiss.IsZero() && time.Since(c.lastMiss) < jwksMissInterval
+	c.mu.RUnlock()
+
+	if fresh {
+		return k, nil
+	}
+	if recentMiss {
+		return nil, fmt.Errorf("unknown JWT key ID %q", kid)
+	}
+
+	err := c.fetch(ctx)
+	if err != nil {
+		// a stale key is better than no key if the provider is unreachable
+		if ok {
+			return k, nil
+		}
+		return nil, err
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	k, ok = c.keys[kid]
+	if !ok {
+		c.lastMiss = time.Now()
+		return nil, fmt.Errorf("unknown JWT key ID %q", kid)
+	}
+
+	return k, nil
+}
+
+// fetch fetches the key set and swaps it in. If a fetch is already in
+// progress, fetch waits for it and returns its result instead.
+func (c *jwksCache) fetch(ctx context.Context) error {
+	c.mu.Lock()
+	if f := c.inflight; f != nil {
+		c.mu.Unlock()
+		select {
+		case <-f.done:
+			return f.err
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+	f := &jwksFetch{done: make(chan struct{})}
+	c.inflight = f
+	c.mu.Unlock()
+
+	var keys map[string]crypto.PublicKey
+	keys, f.err = fetchJWKS(ctx, c.client, c.url)
+
+	c.mu.Lock()
+	if f.err == nil {
+		c.keys = keys
+		c.fetched = time.Now()
+	}
+	c.inflight = nil
+	c.mu.Unlock()
+	close(f.done)
+
+	return f.err
+}
+
+// jsonWebKey is a JSON Web Key (RFC 7517) holding an RSA or EC public key
+type jsonWebKey struct {
+	KeyType string `json:"kty"`
+	KeyID   string `json:"kid"`
+	Use     string `json:"use"`
+	N       string `json:"n"`
+	E       string `json:"e"`
+	Curve   string `json:"crv"`
+	X       string `json:"x"`
+	Y       string `json:"y"`
+}
+
+// fetchJWKS retrieves the JSON Web Key Set at url and returns its
+// signing keys by key ID. Keys of an unsupported type are skipped.
+func fetchJWKS(ctx context.Context, client *http.Client, url string) (map[string]crypto.PublicKey, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	var resp *http.Response
+	resp, err = client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("fetching JWKS: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("fetching JWKS: %s", resp.Status)
+	}
+
+	var set struct {
+		Keys []jsonWebKey `json:"keys"`
+	}
+	err = json.NewDecoder(resp.Body).Decode(&set)
+	if err != nil {
+		return nil, fmt.Errorf("decoding JWKS: %w", err)
+	}
+
+	keys := make(map[string]crypto.PublicKey, len(set.Keys))
+	for _, jwk := range set.Keys {
+		if jwk.Use != "" && jwk.Use != "sig" {
+			continue
+		}
+		var pub crypto.PublicKey
+		pub, err = jwk.publicKey()
+		if err != nil {
+			continue
+		}
+		keys[jwk.KeyID] = pub
+	}
+
+	return keys, nil
+}
+
+// publicKey converts the JSON Web Key to an *rsa.PublicKey or *ecdsa.PublicKey
+func (k jsonWebKey) publicKey() (crypto.PublicKey, error) {
+	switch k.KeyType {
+	case "RSA":
+		n, err := base64.RawURLEncoding.DecodeString(k.N)
+		if err != nil {
+			return nil, err
+		}
+		var e []byte
+		e, err = base64.RawURLEncoding.DecodeString(k.E)
+		if err != nil {
+			return nil, err
+		}
+		return &rsa.PublicKey{
+			N: new(big.Int).SetBytes(n),
+			E: int(new(big.Int).SetBytes(e).Int64()),
+		}, nil
+	case "EC":
+		var curve elliptic.Curve
+		switch k.Curve {
+		case "P-256":
+			curve = elliptic.P256()
+		case "P-384":
+			curve = elliptic.P384()
+		case "P-521":
+			curve = elliptic.P521()
+		default:
+			return nil, fmt.Errorf("unsupported curve %q", k.Curve)
+		}
+		x, err := base64.RawURLEncoding.DecodeString(k.X)
+		if err != nil {
+			return nil, err
+		}
+		var y []byte
+		y, err = base64.RawURLEncoding.DecodeString(k.Y)
+		if err != nil {
+			return nil, err
+		}
+		return &ecdsa.PublicKey{
+			Curve: curve,
+			X:     new(big.Int).SetBytes(x),
+			Y:     new(big.Int).SetBytes(y),
+		}, nil
+	default:
+		return nil, fmt.Errorf("unsupported key type %q", k.KeyType)
+	}
+}
@@ -0,0 +1,359 @@
+package gateway_test
+
+import (
+	"context"
+	"crypto"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	qt "github.com/frankban/quicktest"
+	"golang.org/x/oauth2"
+
+	"github.com/gilcrest/diygoapi"
+	"github.com/gilcrest/diygoapi/errs"
+	"github.com/gilcrest/diygoapi/gateway"
+)
+
+const (
+	testIssuer   = "https://issuer.example.com"
+	testAudience = "test-client-id"
+)
+
+// testJWKS serves a JSON Web Key Set which can be rotated during a test
+type testJWKS struct {
+	mu      sync.Mutex
+	keys    map[string]*rsa.PrivateKey
+	fetches int
+	// block, if set, holds up each fetch until it is closed
+	block chan struct{}
+}
+
+func (j *testJWKS) rotate(t *testing.T, kid string) *rsa.PrivateKey {
+	t.Helper()
+
+	k, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	j.keys = map[string]*rsa.PrivateKey{kid: k}
+
+	return k
+}
+
+func (j *testJWKS) fetchCount() int {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	return j.fetches
+}
+
+func (j *testJWKS) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	j.mu.Lock()
+	j.fetches++
+	block := j.block
+	j.mu.Unlock()
+
+	if block != nil {
+		<-block
+	}
+
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	type jwk struct {
+		Kty string `json:"kty"`
+		Kid string `json:"kid"`
+		Use string `json:"use"`
+		N   string `json:"n"`
+		E   string `json:"e"`
+	}
+	var set struct {
+		Keys []jwk `json:"keys"`
+	}
+	for kid, k := range j.keys {
+		set.Keys = append(set.Keys, jwk{
+			Kty: "RSA",
+			Kid: kid,
+			Use: "sig",
+			N:   base64.RawURLEncoding.EncodeToString(k.N.Bytes()),
+			E:   base64.RawURLEncoding.EncodeToString(big.NewInt(int64(k.E)).Bytes()),
+		})
+	}
+	_ = json.NewEncoder(w).Encode(set)
+}
+
+// signRS256 returns a compact serialized JWT signed with key
+func signRS256(t *testing.T, key *rsa.PrivateKey, kid string, claims map[string]any) string {
+	t.Helper()
+
+	hdr, err := json.Marshal(map[string]string{"alg": "RS256", "typ": "JWT", "kid": kid})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var body []byte
+	body, err = json.Marshal(claims)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	signed := base64.RawURLEncoding.EncodeToString(hdr) + "." + base64.RawURLEncoding.EncodeToString(body)
+	digest := sha256.Sum256([]byte(signed))
+
+	var sig []byte
+	sig, err = rsa.SignPKCS1v15(rand.Reader, key, crypto.SHA256, digest[:])
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return signed + "." + base64.RawURLEncoding.EncodeToString(sig)
+}
+
+func validClaims() map[string]any {
+	return map[string]any{
+		"iss":            testIssuer,
+		"sub":            "00u1abcd",
+		"aud":            testAudience,
+		"exp":            time.Now().Add(time.Hour).Unix(),
+		"iat":            time.Now().Unix(),
+		"nonce":          "n-0S6_WzA2Mj",
+		"email":          "otto@example.com",
+		"email_verified": true,
+		"given_name":     "Otto",
+		"family_name":    "Mann",
+		"name":           "Otto Mann",
+		"locale":         "en-US",
+	}
+}
+
+// newTestOIDCToken signs claims and returns them as the access token
+// of an oauth2.Token, along with the nonce sent by the client
+func newTestOIDCToken(t *testing.T, key *rsa.PrivateKey, kid string, claims map[string]any) *oauth2.Token {
+	t.Helper()
+
+	return (&oauth2.Token{AccessToken: signRS256(t, key, kid, claims), TokenType: diygoapi.BearerTokenType}).
+		WithExtra(map[string]any{diygoapi.NonceTokenExtraKey: claims["nonce"]})
+}
+
+func newTestOIDCExchange(t *testing.T, jwks *testJWKS) *gateway.OIDCTokenExchange {
+	t.Helper()
+
+	srv := httptest.NewServer(jwks)
+	t.Cleanup(srv.Close)
+
+	e, err := gateway.NewOIDCTokenExchange(gateway.OIDCConfig{
+		Issuer:   testIssuer,
+		Audience: testAudience,
+		JWKSURL:  srv.URL,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return e
+}
+
+func TestNewOIDCTokenExchange(t *testing.T) {
+	c := qt.New(t)
+
+	_, err := gateway.NewOIDCTokenExchange(gateway.OIDCConfig{Issuer: testIssuer, Audience: testAudience})
+	c.Assert(errs.KindIs(errs.Validation, err), qt.IsTrue)
+}
+
+func TestOIDCTokenExchange_Exchange(t *testing.T) {
+	t.Run("valid", func(t *testing.T) {
+		c := qt.New(t)
+
+		jwks := &testJWKS{}
+		key := jwks.rotate(t, "k1")
+		e := newTestOIDCExchange(t, jwks)
+
+		claims := validClaims()
+		token := (&oauth2.Token{AccessToken: signRS256(t, key, "k1", claims), TokenType: diygoapi.BearerTokenType}).
+			WithExtra(map[string]any{diygoapi.NonceTokenExtraKey: "n-0S6_WzA2Mj"})
+
+		pi, err := e.Exchange(context.Background(), "test", diygoapi.OIDC, token)
+		c.Assert(err, qt.IsNil)
+		c.Assert(pi.Provider, qt.Equals, diygoapi.OIDC)
+		c.Assert(pi.TokenInfo.ClientID, qt.Equals, testAudience)
+		c.Assert(pi.TokenInfo.Expiration, qt.Equals, time.Unix(claims["exp"].(int64), 0))
+		c.Assert(pi.UserInfo.ExternalID, qt.Equals, "00u1abcd")
+		c.Assert(pi.UserInfo.Email, qt.Equals, "otto@example.com")
+		c.Assert(pi.UserInfo.EmailVerified, qt.IsTrue)
+		c.Assert(pi.UserInfo.FirstName, qt.Equals, "Otto")
+		c.Assert(pi.UserInfo.LastName, qt.Equals, "Mann")
+		c.Assert(pi.UserInfo.FullName, qt.Equals, "Otto Mann")
+		c.Assert(pi.UserInfo.Locale, qt.Equals, "en-US")
+
+		// the key set is cached
+		_, err = e.Exchange(context.Background(), "test", diygoapi.OIDC, token)
+		c.Assert(err, qt.IsNil)
+		c.Assert(jwks.fetches, qt.Equals, 1)
+	})
+	t.Run("key rotation", func(t *testing.T) {
+		c := qt.New(t)
+
+		jwks := &testJWKS{}
+		key := jwks.rotate(t, "k1")
+		e := newTestOIDCExchange(t, jwks)
+
+		token := newTestOIDCToken(t, key, "k1", validClaims())
+		_, err := e.Exchange(context.Background(), "test", diygoapi.OIDC, token)
+		c.Assert(err, qt.IsNil)
+
+		key = jwks.rotate(t, "k2")
+		token = newTestOIDCToken(t, key, "k2", validClaims())
+		_, err = e.Exchange(context.Background(), "test", diygoapi.OIDC, token)
+		c.Assert(err, qt.IsNil)
+		c.Assert(jwks.fetches, qt.Equals, 2)
+	})
+	t.Run("slow JWKS endpoint", func(t *testing.T) {
+		c := qt.New(t)
+
+		jwks := &testJWKS{}
+		key1 := jwks.rotate(t, "k1")
+		e := newTestOIDCExchange(t, jwks)
+
+		_, err := e.Exchange(context.Background(), "test", diygoapi.OIDC, newTestOIDCToken(t, key1, "k1", validClaims()))
+		c.Assert(err, qt.IsNil)
+
+		// a token signed with a new key triggers a fetch which hangs
+		block := make(chan struct{})
+		jwks.mu.Lock()
+		jwks.block = block
+		jwks.mu.Unlock()
+		key2 := jwks.rotate(t, "k2")
+		done := make(chan error, 1)
+		go func() {
+			_, err := e.Exchange(context.Background(), "test", diygoapi.OIDC, newTestOIDCToken(t, key2, "k2", validClaims()))
+			done <- err
+		}()
+		for jwks.fetchCount() < 2 {
+			time.Sleep(time.Millisecond)
+		}
+
+		// a token signed with the cached key is verified meanwhile
+		cached := make(chan error, 1)
+		go func() {
+			_, err := e.Exchange(context.Background(), "test", diygoapi.OIDC, newTestOIDCToken(t, key1, "k1", validClaims()))
+			cached <- err
+		}()
+		select {
+		case err = <-cached:
+			c.Assert(err, qt.IsNil)
+		case <-time.After(5 * time.Second):
+			close(block)
+			c.Fatal("verifying a token signed with a cached key waited for the JWKS fetch")
+		}
+
+		close(block)
+		c.Assert(<-done, qt.IsNil)
+		c.Assert(jwks.fetchCount(), qt.Equals, 2)
+	})
+	t.Run("unknown kid", func(t *testing.T) {
+		c := qt.New(t)
+
+		jwks := &testJWKS{}
+		key := jwks.rotate(t, "k1")
+		e := newTestOIDCExchange(t, jwks)
+
+		token := newTestOIDCToken(t, key, "nope", validClaims())
+		_, err := e.Exchange(context.Background(), "test", diygoapi.OIDC, token)
+		c.Assert(errs.KindIs(errs.Unauthenticated, err), qt.IsTrue)
+
+		// a second unknown kid does not trigger another fetch
+		_, err = e.Exchange(context.Background(), "test", diygoapi.OIDC, token)
+		c.Assert(errs.KindIs(errs.Unauthenticated, err), qt.IsTrue)
+		c.Assert(jwks.fetches, qt.Equals, 1)
+	})
+
+	invalid := []struct {
+		name   string
+		modify func(claims map[string]any)
+		nonce  string
+	}{
+		{name: "wrong issuer", modify: func(claims map[string]any) { claims["iss"] = "https://evil.example.com" }},
+		{name: "wrong audience", modify: func(claims map[string]any) { claims["aud"] = []string{"someone-else"} }},
+		{name: "expired", modify: func(claims map[string]any) { claims["exp"] = time.Now().Add(-time.Hour).Unix() }},
+		{name: "not yet valid", modify: func(claims map[string]any) { claims["nbf"] = time.Now().Add(time.Hour).Unix() }},
+		{name: "nonce mismatch", modify: func(claims map[string]any) {}, nonce: "something else"},
+		{name: "no nonce sent", modify: func(claims map[string]any) {}, nonce: "-"},
+		{name: "no nonce claim", modify: func(claims map[string]any) { delete(claims, "nonce") }},
+	}
+	for _, tt := range invalid {
+		t.Run(tt.name, func(t *testing.T) {
+			c := qt.New(t)
+
+			jwks := &testJWKS{}
+			key := jwks.rotate(t, "k1")
+			e := newTestOIDCExchange(t, jwks)
+
+			claims := validClaims()
+			tt.modify(claims)
+			token := &oauth2.Token{AccessToken: signRS256(t, key, "k1", claims)}
+			switch tt.nonce {
+			case "":
+				token = token.WithExtra(map[string]any{diygoapi.NonceTokenExtraKey: "n-0S6_WzA2Mj"})
+			case "-":
+				// no nonce sent by the client
+			default:
+				token = token.WithExtra(map[string]any{diygoapi.NonceTokenExtraKey: tt.nonce})
+			}
+
+			_, err := e.Exchange(context.Background(), "test", diygoapi.OIDC, token)
+			c.Assert(errs.KindIs(errs.Unauthenticated, err), qt.IsTrue)
+		})
+	}
+	t.Run("unverified email", func(t *testing.T) {
+		c := qt.New(t)
+
+		jwks := &testJWKS{}
+		key := jwks.rotate(t, "k1")
+		e := newTestOIDCExchange(t, jwks)
+
+		for _, v := range []any{false, "false", nil} {
+			claims := validClaims()
+			claims["email_verified"] = v
+			pi, err := e.Exchange(context.Background(), "test", diygoapi.OIDC, newTestOIDCToken(t, key, "k1", claims))
+			c.Assert(err, qt.IsNil)
+			c.Assert(pi.UserInfo.Email, qt.Equals, "")
+			c.Assert(pi.UserInfo.EmailVerified, qt.IsFalse)
+		}
+
+		claims := validClaims()
+		claims["email_verified"] = "true"
+		pi, err := e.Exchange(context.Background(), "test", diygoapi.OIDC, newTestOIDCToken(t, key, "k1", claims))
+		c.Assert(err, qt.IsNil)
+		c.Assert(pi.UserInfo.Email, qt.Equals, "otto@example.com")
+		c.Assert(pi.UserInfo.EmailVerified, qt.IsTrue)
+	})
+	t.Run("bad signature", func(t *testing.T) {
+		c := qt.New(t)
+
+		jwks := &testJWKS{}
+		key := jwks.rotate(t, "k1")
+		e := newTestOIDCExchange(t, jwks)
+
+		raw := signRS256(t, key, "k1", validClaims())
+		parts := strings.Split(raw, ".")
+		claims := validClaims()
+		claims["sub"] = "someone-else"
+		forged := strings.Split(signRS256(t, key, "k1", claims), ".")[1]
+
+		token := (&oauth2.Token{AccessToken: parts[0] + "." + forged + "." + parts[2]}).
+			WithExtra(map[string]any{diygoapi.NonceTokenExtraKey: "n-0S6_WzA2Mj"})
+		_, err := e.Exchange(context.Background(), "test", diygoapi.OIDC, token)
+		c.Assert(errs.KindIs(errs.Unauthenticated, err), qt.IsTrue)
+	})
+}
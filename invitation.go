@@ -0,0 +1,179 @@
+package diygoapi
+
+import (
+	"context"
+	"crypto/hmac"
+	"encoding/base64"
+	"fmt"
+	"net/mail"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/gilcrest/diygoapi/errs"
+	"github.com/gilcrest/diygoapi/secure"
+)
+
+// InvitationServicer manages the invitations given to people to join an Org
+//
+// No email is sent by the server. Create and Resend return the
+// invitation token, which the caller delivers to the invited person.
+type InvitationServicer interface {
+	Create(ctx context.Context, r *CreateInvitationRequest, adt Audit) (*InvitationResponse, error)
+	FindByOrg(ctx context.Context, orgExtlID string, adt Audit) ([]*InvitationResponse, error)
+	// Resend issues a new token for an invitation which has not been
+	// accepted or revoked. Tokens issued before are no longer valid.
+	// The new token is returned, it is not sent to the invited person.
+	Resend(ctx context.Context, orgExtlID, extlID string, adt Audit) (*InvitationResponse, error)
+	Revoke(ctx context.Context, orgExtlID, extlID string, adt Audit) (DeleteResponse, error)
+}
+
+// InvitationValidity is how long an invitation token can be used
+// after the invitation is sent.
+const InvitationValidity = 7 * 24 * time.Hour
+
+// Invitation statuses
+const (
+	InvitationStatusPending  = "pending"
+	InvitationStatusAccepted = "accepted"
+	InvitationStatusRevoked  = "revoked"
+	InvitationStatusExpired  = "expired"
+)
+
+// Invitation is an invitation for a person, identified by their email,
+// to join an Org with a Role. The invitation is accepted when the
+// person authenticates with the invitation token and the email given
+// by their authentication provider matches the invitation email.
+type Invitation struct {
+	ID         uuid.UUID
+	ExternalID secure.Identifier
+	Org        *Org
+	Role       *Role
+	Email      string
+	// Expiry: the moment the current invitation token expires
+	Expiry time.Time
+	// SendCount: the number of times the invitation has been sent
+	SendCount int64
+	// LastSent: the moment the invitation was last sent
+	LastSent time.Time
+	// AcceptedBy: the User who accepted the invitation, if accepted
+	AcceptedBy *User
+	AcceptedAt time.Time
+	RevokedAt  time.Time
+}
+
+// Status returns the status of the Invitation at the given moment
+func (i Invitation) Status(now time.Time) string {
+	switch {
+	case !i.AcceptedAt.IsZero():
+		return InvitationStatusAccepted
+	case !i.RevokedAt.IsZero():
+		return InvitationStatusRevoked
+	case !now.Before(i.Expiry):
+		return InvitationStatusExpired
+	}
+	return InvitationStatusPending
+}
+
+// invitationKeyLabel is the label used to derive the invitation token
+// signing key from the encryption key
+const invitationKeyLabel string = "diygoapi invitation token v1"
+
+// invitationKey derives the key invitation tokens are signed with from
+// the encryption key. The encryption key is not used directly, so an
+// invitation token reveals nothing which could be used against data
+// encrypted with it or against API key hashes.
+func invitationKey(ek *[32]byte) *[32]byte {
+	return secure.DeriveKey(ek, invitationKeyLabel)
+}
+
+// Token returns the signed invitation token for the Invitation. The
+// token holds the invitation external ID and expiry and is signed
+// using HMAC-SHA256 with a key derived from the encryption key.
+func (i Invitation) Token(ek *[32]byte) string {
+	payload := i.ExternalID.String() + "." + strconv.FormatInt(i.Expiry.Unix(), 10)
+	return payload + "." + base64.RawURLEncoding.EncodeToString(secure.HMAC([]byte(payload), invitationKey(ek)))
+}
+
+// ParseInvitationToken verifies the signature of an invitation token
+// and returns the invitation external ID and the token expiry. An
+// error is returned if the token is malformed, has been tampered with
+// or has expired.
+func ParseInvitationToken(token string, ek *[32]byte, now time.Time) (extlID string, expiry time.Time, err error) {
+	const op errs.Op = "diygoapi/ParseInvitationToken"
+
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		return "", time.Time{}, errs.E(op, errs.Validation, "malformed invitation token")
+	}
+
+	var sig []byte
+	sig, err = base64.RawURLEncoding.DecodeString(parts[2])
+	if err != nil {
+		return "", time.Time{}, errs.E(op, errs.Validation, "malformed invitation token")
+	}
+
+	payload := parts[0] + "." + parts[1]
+	if !hmac.Equal(sig, secure.HMAC([]byte(payload), invitationKey(ek))) {
+		return "", time.Time{}, errs.E(op, errs.Validation, "invalid invitation token signature")
+	}
+
+	var unix int64
+	unix, err = strconv.ParseInt(parts[1], 10, 64)
+	if err != nil {
+		return "", time.Time{}, errs.E(op, errs.Validation, "malformed invitation token")
+	}
+
+	expiry = time.Unix(unix, 0)
+	if !now.Before(expiry) {
+		return "", time.Time{}, errs.E(op, errs.Validation, "invitation token has expired")
+	}
+
+	return parts[0], expiry, nil
+}
+
+// CreateInvitationRequest is the request struct for inviting a person
+// to join an Org
+type CreateInvitationRequest struct {
+	OrgExternalID string
+	Email         string `json:"email"`
+	RoleCode      string `json:"role_cd"`
+}
+
+// Validate determines whether the CreateInvitationRequest has proper data to be considered valid
+func (r CreateInvitationRequest) Validate() error {
+	const op errs.Op = "diygoapi/CreateInvitationRequest.Validate"
+
+	switch {
+	case r.Email == "":
+		return errs.E(op, errs.Validation, "email is required")
+	case r.RoleCode == "":
+		return errs.E(op, errs.Validation, "role code is required")
+	}
+
+	addr, err := mail.ParseAddress(r.Email)
+	if err != nil || addr.Address != r.Email {
+		return errs.E(op, errs.Validation, fmt.Sprintf("%s is not a valid email address", r.Email))
+	}
+
+	return nil
+}
+
+// InvitationResponse is the response struct for an Invitation. The
+// token is only given when the invitation is created or resent.
+type InvitationResponse struct {
+	ExternalID         string `json:"external_id"`
+	OrgExtlID          string `json:"org_extl_id"`
+	Email              string `json:"email"`
+	RoleCode           string `json:"role_cd"`
+	Status             string `json:"status"`
+	Token              string `json:"token,omitempty"`
+	ExpiryDateTime     string `json:"expiry_date_time"`
+	SendCount          int64  `json:"send_count"`
+	LastSentDateTime   string `json:"last_sent_date_time"`
+	AcceptedUserExtlID string `json:"accepted_user_extl_id,omitempty"`
+	AcceptedDateTime   string `json:"accepted_date_time,omitempty"`
+	RevokedDateTime    string `json:"revoked_date_time,omitempty"`
+}
@@ -0,0 +1,82 @@
+package diygoapi_test
+
+import (
+	"encoding/base64"
+	"strings"
+	"testing"
+	"time"
+
+	qt "github.com/frankban/quicktest"
+
+	"github.com/gilcrest/diygoapi"
+	"github.com/gilcrest/diygoapi/errs"
+	"github.com/gilcrest/diygoapi/secure"
+)
+
+func TestParseInvitationToken(t *testing.T) {
+	c := qt.New(t)
+
+	key, err := secure.NewEncryptionKey()
+	c.Assert(err, qt.IsNil)
+
+	now := time.Date(2026, 3, 4, 10, 30, 0, 0, time.UTC)
+	inv := diygoapi.Invitation{ExternalID: secure.NewID(), Expiry: now.Add(diygoapi.InvitationValidity)}
+	token := inv.Token(key)
+
+	extlID, expiry, err := diygoapi.ParseInvitationToken(token, key, now)
+	c.Assert(err, qt.IsNil)
+	c.Assert(extlID, qt.Equals, inv.ExternalID.String())
+	c.Assert(expiry.Equal(inv.Expiry), qt.IsTrue)
+
+	otherKey, err := secure.NewEncryptionKey()
+	c.Assert(err, qt.IsNil)
+
+	parts := strings.Split(token, ".")
+	invalid := []struct {
+		name  string
+		token string
+		key   *[32]byte
+		now   time.Time
+	}{
+		{"expired", token, key, inv.Expiry},
+		{"other key", token, otherKey, now},
+		{"tampered expiry", parts[0] + ".9999999999." + parts[2], key, now},
+		{"tampered ID", secure.NewID().String() + "." + parts[1] + "." + parts[2], key, now},
+		{"signed with the encryption key", parts[0] + "." + parts[1] + "." + base64.RawURLEncoding.EncodeToString(secure.HMAC([]byte(parts[0]+"."+parts[1]), key)), key, now},
+		{"malformed", parts[0] + "." + parts[1], key, now},
+		{"bad signature encoding", parts[0] + "." + parts[1] + ".!!", key, now},
+	}
+	for _, tt := range invalid {
+		t.Run(tt.name, func(t *testing.T) {
+			c := qt.New(t)
+
+			_, _, err := diygoapi.ParseInvitationToken(tt.token, tt.key, tt.now)
+			c.Assert(errs.KindIs(errs.Validation, err), qt.IsTrue)
+		})
+	}
+}
+
+func TestInvitation_Status(t *testing.T) {
+	c := qt.New(t)
+
+	now := time.Now()
+	pending := diygoapi.Invitation{Expiry: now.Add(time.Hour)}
+	c.Assert(pending.Status(now), qt.Equals, diygoapi.InvitationStatusPending)
+	c.Assert(pending.Status(now.Add(time.Hour)), qt.Equals, diygoapi.InvitationStatusExpired)
+
+	accepted := diygoapi.Invitation{Expiry: now.Add(-time.Hour), AcceptedAt: now.Add(-2 * time.Hour)}
+	c.Assert(accepted.Status(now), qt.Equals, diygoapi.InvitationStatusAccepted)
+
+	revoked := diygoapi.Invitation{Expiry: now.Add(time.Hour), RevokedAt: now}
+	c.Assert(revoked.Status(now), qt.Equals, diygoapi.InvitationStatusRevoked)
+}
+
+func TestCreateInvitationRequest_Validate(t *testing.T) {
+	c := qt.New(t)
+
+	c.Assert(diygoapi.CreateInvitationRequest{Email: "otto@example.com", RoleCode: "orgAdmin"}.Validate(), qt.IsNil)
+	c.Assert(errs.KindIs(errs.Validation, diygoapi.CreateInvitationRequest{RoleCode: "orgAdmin"}.Validate()), qt.IsTrue)
+	c.Assert(errs.KindIs(errs.Validation, diygoapi.CreateInvitationRequest{Email: "otto@example.com"}.Validate()), qt.IsTrue)
+	c.Assert(errs.KindIs(errs.Validation, diygoapi.CreateInvitationRequest{Email: "otto", RoleCode: "orgAdmin"}.Validate()), qt.IsTrue)
+	c.Assert(errs.KindIs(errs.Validation, diygoapi.CreateInvitationRequest{Email: "Otto <otto@example.com>", RoleCode: "orgAdmin"}.Validate()), qt.IsTrue)
+}
@@ -3,6 +3,7 @@ package main
 import (
 	"fmt"
 	"os"
+	"time"
 
 	"github.com/magefile/mage/sh"
 
@@ -173,6 +174,96 @@ func Genesis(env string) (err error) {
 	return nil
 }
 
+// MigrateAPIKeys converts API keys stored using reversible encryption
+// to hashed API keys, example: mage -v migrateAPIKeys local.
+// Run the DDL scripts (mage -v dbup local) first to add the
+// api_key_prefix column.
+func MigrateAPIKeys(env string) (err error) {
+	const op errs.Op = "main/MigrateAPIKeys"
+
+	err = cmd.LoadEnv(cmd.ParseEnv(env))
+	if err != nil {
+		return errs.E(op, err)
+	}
+
+	err = cmd.MigrateAPIKeys()
+	if err != nil {
+		return errs.E(op, err)
+	}
+
+	return nil
+}
+
+// PurgeDeleted permanently removes the orgs, apps and movies deleted
+// longer ago than the retention period, example: mage -v purgeDeleted local 720h.
+// The retention period is given as a duration string.
+func PurgeDeleted(env, retention string) (err error) {
+	const op errs.Op = "main/PurgeDeleted"
+
+	var d time.Duration
+	d, err = time.ParseDuration(retention)
+	if err != nil {
+		return errs.E(op, err)
+	}
+
+	err = cmd.LoadEnv(cmd.ParseEnv(env))
+	if err != nil {
+		return errs.E(op, err)
+	}
+
+	err = cmd.PurgeDeleted(d)
+	if err != nil {
+		return errs.E(op, err)
+	}
+
+	return nil
+}
+
+// PurgeAuthzDecisions permanently removes the authorization decisions
+// recorded longer ago than the retention period, example: mage -v purgeAuthzDecisions local 2160h.
+// The retention period is given as a duration string.
+func PurgeAuthzDecisions(env, retention string) (err error) {
+	const op errs.Op = "main/PurgeAuthzDecisions"
+
+	var d time.Duration
+	d, err = time.ParseDuration(retention)
+	if err != nil {
+		return errs.E(op, err)
+	}
+
+	err = cmd.LoadEnv(cmd.ParseEnv(env))
+	if err != nil {
+		return errs.E(op, err)
+	}
+
+	err = cmd.PurgeAuthzDecisions(d)
+	if err != nil {
+		return errs.E(op, err)
+	}
+
+	return nil
+}
+
+// SyncPermissions compares the permissions in the database with the
+// routes registered by the server, example: mage -v syncPermissions local false.
+// If upsert is true, a permission is created for each route which
+// requires authorization, but has none. Run Genesis first.
+func SyncPermissions(env string, upsert bool) (err error) {
+	const op errs.Op = "main/SyncPermissions"
+
+	err = cmd.LoadEnv(cmd.ParseEnv(env))
+	if err != nil {
+		return errs.E(op, err)
+	}
+
+	err = cmd.SyncPermissions(upsert)
+	if err != nil {
+		return errs.E(op, err)
+	}
+
+	return nil
+}
+
 // TestAll runs all tests for the app,
 // example: mage -v testall false local.
 // If verbose is true, tests will be run in verbose mode.
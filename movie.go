@@ -14,9 +14,12 @@ import (
 type MovieServicer interface {
 	Create(ctx context.Context, r *CreateMovieRequest, adt Audit) (*MovieResponse, error)
 	Update(ctx context.Context, r *UpdateMovieRequest, adt Audit) (*MovieResponse, error)
-	Delete(ctx context.Context, extlID string) (DeleteResponse, error)
-	FindMovieByExternalID(ctx context.Context, extlID string) (*MovieResponse, error)
-	FindAllMovies(ctx context.Context) ([]*MovieResponse, error)
+	// Delete soft deletes a Movie. Deleted movies are kept until they
+	// are purged and can be restored until then.
+	Delete(ctx context.Context, extlID string, adt Audit) (DeleteResponse, error)
+	Restore(ctx context.Context, extlID string, adt Audit) (*MovieResponse, error)
+	FindMovieByExternalID(ctx context.Context, extlID string, includeDeleted bool) (*MovieResponse, error)
+	FindAllMovies(ctx context.Context, includeDeleted bool) ([]*MovieResponse, error)
 }
 
 // Movie holds details of a movie
@@ -29,6 +32,8 @@ type Movie struct {
 	RunTime    int
 	Director   string
 	Writer     string
+	// DeletedAt is the moment the Movie was deleted, zero if it is not
+	DeletedAt time.Time
 }
 
 // IsValid performs validation of the struct
@@ -93,4 +98,5 @@ type MovieResponse struct {
 	UpdateUserFirstName string `json:"update_user_first_name"`
 	UpdateUserLastName  string `json:"update_user_last_name"`
 	UpdateDateTime      string `json:"update_date_time"`
+	DeletedDateTime     string `json:"deleted_date_time,omitempty"`
 }
@@ -2,6 +2,7 @@ package diygoapi
 
 import (
 	"context"
+	"time"
 
 	"github.com/google/uuid"
 
@@ -14,9 +15,34 @@ type OrgServicer interface {
 	// Create manages the creation of an Org (and optional app)
 	Create(ctx context.Context, r *CreateOrgRequest, adt Audit) (*OrgResponse, error)
 	Update(ctx context.Context, r *UpdateOrgRequest, adt Audit) (*OrgResponse, error)
-	Delete(ctx context.Context, extlID string) (DeleteResponse, error)
-	FindAll(ctx context.Context) ([]*OrgResponse, error)
-	FindByExternalID(ctx context.Context, extlID string) (*OrgResponse, error)
+	// Delete soft deletes an Org and its apps. An Org which has child
+	// orgs is only deleted if cascade is true, in which case all of its
+	// descendants are deleted as well. Deleted orgs are kept until they
+	// are purged and can be restored until then.
+	Delete(ctx context.Context, extlID string, cascade bool, adt Audit) (DeleteResponse, error)
+	// Restore undoes the deletion of an Org, along with the descendants
+	// and apps which were deleted with it.
+	Restore(ctx context.Context, extlID string, adt Audit) (*OrgResponse, error)
+	FindAll(ctx context.Context, includeDeleted bool) ([]*OrgResponse, error)
+	FindByExternalID(ctx context.Context, extlID string, includeDeleted bool) (*OrgResponse, error)
+	// FindDescendants lists the children of an Org, their children
+	// and so on, ordered by depth.
+	FindDescendants(ctx context.Context, extlID string, includeDeleted bool) ([]*OrgResponse, error)
+}
+
+// OrgKindServicer manages the retrieval and manipulation of an OrgKind.
+// Only a sysAdmin of the Principal org may create, update or delete an
+// OrgKind, the kinds created by Genesis cannot be updated or deleted.
+type OrgKindServicer interface {
+	Create(ctx context.Context, r *CreateOrgKindRequest, adt Audit) (*OrgKindResponse, error)
+	// Update updates the description and replaces the default roles
+	// of an OrgKind. The external ID of an OrgKind cannot be changed.
+	Update(ctx context.Context, r *UpdateOrgKindRequest, adt Audit) (*OrgKindResponse, error)
+	// Delete removes an OrgKind. An OrgKind which classifies any Org,
+	// including a deleted one, cannot be removed.
+	Delete(ctx context.Context, extlID string, adt Audit) (DeleteResponse, error)
+	FindAll(ctx context.Context) ([]*OrgKindResponse, error)
+	FindByExternalID(ctx context.Context, extlID string) (*OrgKindResponse, error)
 }
 
 // OrgKind is a way of classifying an organization. Examples are Genesis, Test, Standard
@@ -27,6 +53,9 @@ type OrgKind struct {
 	ExternalID string
 	// Description: A longer description of the organization kind
 	Description string
+	// DefaultRoles: the roles granted to the User who creates an
+	// organization of this kind, within that organization
+	DefaultRoles []*Role
 }
 
 // Validate determines whether the Person has proper data to be considered valid
@@ -58,6 +87,23 @@ type Org struct {
 	Description string
 	// Kind: a way of classifying organizations
 	Kind *OrgKind
+	// Parent: the organization this organization belongs to, if any.
+	// A role granted in the parent applies to this organization as well.
+	Parent *Org
+	// DeletedAt: the moment the organization was deleted, zero if it is not
+	DeletedAt time.Time
+}
+
+// ParentID returns the ID of the Org parent as uuid.NullUUID. It is
+// null if the Org has no parent.
+func (o Org) ParentID() uuid.NullUUID {
+	if o.Parent == nil || o.Parent.ID == uuid.Nil {
+		return uuid.NullUUID{}
+	}
+	return uuid.NullUUID{
+		UUID:  o.Parent.ID,
+		Valid: true,
+	}
 }
 
 // Validate determines whether the Org has proper data to be considered valid
@@ -87,6 +133,7 @@ type CreateOrgRequest struct {
 	Name             string            `json:"name"`
 	Description      string            `json:"description"`
 	Kind             string            `json:"kind"`
+	ParentExternalID string            `json:"parent_extl_id"`
 	CreateAppRequest *CreateAppRequest `json:"app"`
 }
 
@@ -105,11 +152,15 @@ func (r CreateOrgRequest) Validate() error {
 	return nil
 }
 
-// UpdateOrgRequest is the request struct for Updating an Org
+// UpdateOrgRequest is the request struct for Updating an Org. The
+// parent of the Org is left unchanged when ParentExternalID is nil
+// (parent_extl_id is not sent), an empty ParentExternalID detaches
+// the Org from its parent.
 type UpdateOrgRequest struct {
-	ExternalID  string
-	Name        string `json:"name"`
-	Description string `json:"description"`
+	ExternalID       string
+	Name             string  `json:"name"`
+	Description      string  `json:"description"`
+	ParentExternalID *string `json:"parent_extl_id"`
 }
 
 // OrgResponse is the response struct for an Org.
@@ -122,6 +173,7 @@ type OrgResponse struct {
 	Name                string       `json:"name"`
 	KindExternalID      string       `json:"kind_description"`
 	Description         string       `json:"description"`
+	ParentExternalID    string       `json:"parent_extl_id,omitempty"`
 	CreateAppExtlID     string       `json:"create_app_extl_id"`
 	CreateUserFirstName string       `json:"create_user_first_name"`
 	CreateUserLastName  string       `json:"create_user_last_name"`
@@ -130,5 +182,51 @@ type OrgResponse struct {
 	UpdateUserFirstName string       `json:"update_user_first_name"`
 	UpdateUserLastName  string       `json:"update_user_last_name"`
 	UpdateDateTime      string       `json:"update_date_time"`
+	DeletedDateTime     string       `json:"deleted_date_time,omitempty"`
 	App                 *AppResponse `json:"app,omitempty"`
 }
+
+// CreateOrgKindRequest is the request struct for creating an OrgKind
+type CreateOrgKindRequest struct {
+	ExternalID       string   `json:"external_id"`
+	Description      string   `json:"description"`
+	DefaultRoleCodes []string `json:"default_role_cds"`
+}
+
+// Validate determines whether the CreateOrgKindRequest has proper data to be considered valid
+func (r CreateOrgKindRequest) Validate() error {
+	const op errs.Op = "diygoapi/CreateOrgKindRequest.Validate"
+
+	switch {
+	case r.ExternalID == "":
+		return errs.E(op, errs.Validation, "org kind external ID is required")
+	case r.Description == "":
+		return errs.E(op, errs.Validation, "org kind description is required")
+	}
+	return nil
+}
+
+// UpdateOrgKindRequest is the request struct for updating an OrgKind.
+// The default roles of the OrgKind are replaced by the roles given.
+type UpdateOrgKindRequest struct {
+	ExternalID       string
+	Description      string   `json:"description"`
+	DefaultRoleCodes []string `json:"default_role_cds"`
+}
+
+// Validate determines whether the UpdateOrgKindRequest has proper data to be considered valid
+func (r UpdateOrgKindRequest) Validate() error {
+	const op errs.Op = "diygoapi/UpdateOrgKindRequest.Validate"
+
+	if r.Description == "" {
+		return errs.E(op, errs.Validation, "org kind description is required")
+	}
+	return nil
+}
+
+// OrgKindResponse is the response struct for an OrgKind
+type OrgKindResponse struct {
+	ExternalID       string   `json:"external_id"`
+	Description      string   `json:"description"`
+	DefaultRoleCodes []string `json:"default_role_cds"`
+}
@@ -0,0 +1,46 @@
+package diygoapi_test
+
+import (
+	"testing"
+
+	qt "github.com/frankban/quicktest"
+	"github.com/google/uuid"
+
+	"github.com/gilcrest/diygoapi"
+	"github.com/gilcrest/diygoapi/errs"
+)
+
+func TestOrg_ParentID(t *testing.T) {
+	parentID := uuid.New()
+	tests := []struct {
+		name string
+		org  diygoapi.Org
+		want uuid.NullUUID
+	}{
+		{"no parent", diygoapi.Org{ID: uuid.New()}, uuid.NullUUID{}},
+		{"parent without ID", diygoapi.Org{ID: uuid.New(), Parent: &diygoapi.Org{}}, uuid.NullUUID{}},
+		{"parent", diygoapi.Org{ID: uuid.New(), Parent: &diygoapi.Org{ID: parentID}}, uuid.NullUUID{UUID: parentID, Valid: true}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := qt.New(t)
+			c.Assert(tt.org.ParentID(), qt.Equals, tt.want)
+		})
+	}
+}
+
+func TestCreateOrgKindRequest_Validate(t *testing.T) {
+	c := qt.New(t)
+
+	c.Assert(diygoapi.CreateOrgKindRequest{ExternalID: "trial", Description: "Trial organizations"}.Validate(), qt.IsNil)
+	c.Assert(diygoapi.CreateOrgKindRequest{ExternalID: "trial", Description: "Trial organizations", DefaultRoleCodes: []string{"orgAdmin"}}.Validate(), qt.IsNil)
+	c.Assert(errs.KindIs(errs.Validation, diygoapi.CreateOrgKindRequest{Description: "Trial organizations"}.Validate()), qt.IsTrue)
+	c.Assert(errs.KindIs(errs.Validation, diygoapi.CreateOrgKindRequest{ExternalID: "trial"}.Validate()), qt.IsTrue)
+}
+
+func TestUpdateOrgKindRequest_Validate(t *testing.T) {
+	c := qt.New(t)
+
+	c.Assert(diygoapi.UpdateOrgKindRequest{ExternalID: "trial", Description: "Trial organizations"}.Validate(), qt.IsNil)
+	c.Assert(errs.KindIs(errs.Validation, diygoapi.UpdateOrgKindRequest{ExternalID: "trial"}.Validate()), qt.IsTrue)
+}
@@ -0,0 +1,411 @@
+// Package policy evaluates declarative authorization policies. A Policy
+// is a JSON document (which can be exported from CUE) made up of
+// statements, each allowing or denying a set of subjects or roles
+// access to resources and operations, optionally only under
+// conditions on request attributes like the time or source IP.
+package policy
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/netip"
+	"os"
+	"time"
+
+	"github.com/gilcrest/diygoapi"
+	"github.com/gilcrest/diygoapi/errs"
+)
+
+// Effect is the effect of a Statement which applies to a Request
+type Effect string
+
+const (
+	// Allow grants access
+	Allow Effect = "allow"
+	// Deny refuses access, overriding any statement which allows it
+	Deny Effect = "deny"
+)
+
+// Wildcard matches any subject, resource or operation
+const Wildcard string = diygoapi.PermissionWildcard
+
+// timeOfDayLayout is the layout of the times in a TimeOfDay
+const timeOfDayLayout string = "15:04"
+
+// Policy is a set of Statements. A Request is denied if any statement
+// denies it, otherwise it is allowed if any statement allows it.
+// A Request no statement applies to is denied.
+type Policy struct {
+	Statements []*Statement `json:"statements"`
+}
+
+// Statement allows or denies subjects or roles access to resources
+// and operations.
+type Statement struct {
+	// ID identifies the statement in decisions and logs.
+	ID string `json:"id"`
+	// Effect is either allow or deny.
+	Effect Effect `json:"effect"`
+	// Subjects are the external IDs of the users the statement
+	// applies to, * applies to any user.
+	Subjects []string `json:"subjects,omitempty"`
+	// Roles are the codes of the roles the statement applies to. A
+	// user holding any of them in the org they are acting in is
+	// subject to the statement.
+	Roles []string `json:"roles,omitempty"`
+	// Resources are resource patterns, matched as Permission
+	// resources are (e.g. /api/v1/movies/* or *).
+	Resources []string `json:"resources"`
+	// Operations are the operations (e.g. GET) the statement applies
+	// to, * applies to any operation.
+	Operations []string `json:"operations"`
+	// Conditions optionally restricts the requests the statement
+	// applies to.
+	Conditions *Conditions `json:"conditions,omitempty"`
+}
+
+// Conditions restricts a Statement to requests with certain
+// attributes. All given conditions must be met.
+type Conditions struct {
+	// SourceIPs are CIDR prefixes (e.g. 10.0.0.0/8), the request
+	// must come from one of them.
+	SourceIPs []string `json:"source_ips,omitempty"`
+	// Weekdays are the days (e.g. Monday) the request must be made on.
+	Weekdays []string `json:"weekdays,omitempty"`
+	// TimeOfDay is the time of day the request must be made within.
+	TimeOfDay *TimeOfDay `json:"time_of_day,omitempty"`
+	// Location is the IANA time zone (e.g. America/Chicago) Weekdays
+	// and TimeOfDay are in. Defaults to UTC.
+	Location string `json:"location,omitempty"`
+
+	prefixes []netip.Prefix
+	weekdays map[time.Weekday]bool
+	location *time.Location
+}
+
+// TimeOfDay is a time range within a day, given as 24-hour clock times
+// (e.g. 09:00). From is inclusive, Until is exclusive. If Until is
+// before From, the range spans midnight.
+type TimeOfDay struct {
+	From  string `json:"from"`
+	Until string `json:"until"`
+
+	from, until time.Duration
+}
+
+// Request holds the attributes of a request to be evaluated
+type Request struct {
+	// Subject is the external ID of the user.
+	Subject string
+	// Roles are the codes of the roles the user holds in the org they
+	// are acting in, including inherited roles.
+	Roles []string
+	// Resource requested (e.g. an HTTP route path template).
+	Resource string
+	// Operation requested (e.g. an HTTP method).
+	Operation string
+	// SourceIP is the address the request came from. If invalid,
+	// SourceIPs conditions are not met.
+	SourceIP netip.Addr
+	// Time the request was made.
+	Time time.Time
+}
+
+// Decision is the result of evaluating a Request
+type Decision struct {
+	// Allowed is true if the request is allowed.
+	Allowed bool
+	// StatementID is the ID of the statement which decided the
+	// request, empty if no statement applies.
+	StatementID string
+	// RoleCode is the code of the role through which the deciding
+	// statement applies, empty if it applies to the subject directly.
+	RoleCode string
+}
+
+// Grant is an allow Statement which applies to a subject, either
+// directly or through the role given by RoleCode
+type Grant struct {
+	Statement *Statement
+	RoleCode  string
+}
+
+// Load reads and parses the Policy in the JSON file at path
+func Load(path string) (*Policy, error) {
+	const op errs.Op = "policy/Load"
+
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return nil, errs.E(op, err)
+	}
+
+	var p *Policy
+	p, err = Parse(b)
+	if err != nil {
+		return nil, errs.E(op, err)
+	}
+
+	return p, nil
+}
+
+// Parse parses and validates a JSON Policy. Unknown fields are an error.
+func Parse(b []byte) (*Policy, error) {
+	const op errs.Op = "policy/Parse"
+
+	dec := json.NewDecoder(bytes.NewReader(b))
+	dec.DisallowUnknownFields()
+
+	p := new(Policy)
+	err := dec.Decode(p)
+	if err != nil {
+		return nil, errs.E(op, errs.Validation, err)
+	}
+
+	err = p.compile()
+	if err != nil {
+		return nil, errs.E(op, err)
+	}
+
+	return p, nil
+}
+
+// compile validates the Policy and prepares its conditions for evaluation
+func (p *Policy) compile() error {
+	const op errs.Op = "policy/Policy.compile"
+
+	if len(p.Statements) == 0 {
+		return errs.E(op, errs.Validation, "a policy must have at least one statement")
+	}
+
+	ids := make(map[string]bool, len(p.Statements))
+	for _, s := range p.Statements {
+		if s == nil {
+			return errs.E(op, errs.Validation, "statements cannot be null")
+		}
+		if s.ID == "" {
+			return errs.E(op, errs.Validation, "each statement must have an id")
+		}
+		if ids[s.ID] {
+			return errs.E(op, errs.Validation, fmt.Sprintf("statement id %s is not unique", s.ID))
+		}
+		ids[s.ID] = true
+
+		err := s.compile()
+		if err != nil {
+			return errs.E(op, err)
+		}
+	}
+
+	return nil
+}
+
+// compile validates the Statement and prepares its conditions for evaluation
+func (s *Statement) compile() error {
+	const op errs.Op = "policy/Statement.compile"
+
+	switch {
+	case s.Effect != Allow && s.Effect != Deny:
+		return errs.E(op, errs.Validation, fmt.Sprintf("statement %s: effect must be %s or %s", s.ID, Allow, Deny))
+	case len(s.Subjects) == 0 && len(s.Roles) == 0:
+		return errs.E(op, errs.Validation, fmt.Sprintf("statement %s: at least one subject or role is required", s.ID))
+	case len(s.Resources) == 0:
+		return errs.E(op, errs.Validation, fmt.Sprintf("statement %s: at least one resource is required", s.ID))
+	case len(s.Operations) == 0:
+		return errs.E(op, errs.Validation, fmt.Sprintf("statement %s: at least one operation is required", s.ID))
+	}
+
+	if s.Conditions == nil {
+		return nil
+	}
+
+	c := s.Conditions
+	for _, cidr := range c.SourceIPs {
+		prefix, err := netip.ParsePrefix(cidr)
+		if err != nil {
+			return errs.E(op, errs.Validation, fmt.Sprintf("statement %s: source IP %s is not a valid CIDR prefix", s.ID, cidr))
+		}
+		c.prefixes = append(c.prefixes, prefix.Masked())
+	}
+
+	if len(c.Weekdays) > 0 {
+		c.weekdays = make(map[time.Weekday]bool, len(c.Weekdays))
+		for _, day := range c.Weekdays {
+			wd, ok := parseWeekday(day)
+			if !ok {
+				return errs.E(op, errs.Validation, fmt.Sprintf("statement %s: weekday %s is not valid", s.ID, day))
+			}
+			c.weekdays[wd] = true
+		}
+	}
+
+	if c.TimeOfDay != nil {
+		var err error
+		c.TimeOfDay.from, err = parseTimeOfDay(c.TimeOfDay.From)
+		if err != nil {
+			return errs.E(op, errs.Validation, fmt.Sprintf("statement %s: time_of_day from %s is not a valid time (HH:MM)", s.ID, c.TimeOfDay.From))
+		}
+		c.TimeOfDay.until, err = parseTimeOfDay(c.TimeOfDay.Until)
+		if err != nil {
+			return errs.E(op, errs.Validation, fmt.Sprintf("statement %s: time_of_day until %s is not a valid time (HH:MM)", s.ID, c.TimeOfDay.Until))
+		}
+		if c.TimeOfDay.from == c.TimeOfDay.until {
+			return errs.E(op, errs.Validation, fmt.Sprintf("statement %s: time_of_day from and until cannot be equal", s.ID))
+		}
+	}
+
+	c.location = time.UTC
+	if c.Location != "" {
+		loc, err := time.LoadLocation(c.Location)
+		if err != nil {
+			return errs.E(op, errs.Validation, fmt.Sprintf("statement %s: location %s is not a valid time zone", s.ID, c.Location))
+		}
+		c.location = loc
+	}
+
+	return nil
+}
+
+// Evaluate decides whether the Policy allows the Request. Deny
+// statements take precedence over allow statements.
+func (p *Policy) Evaluate(r Request) Decision {
+	var allow *Decision
+	for _, s := range p.Statements {
+		role, ok := s.applies(r)
+		if !ok {
+			continue
+		}
+		if s.Effect == Deny {
+			return Decision{Allowed: false, StatementID: s.ID, RoleCode: role}
+		}
+		if allow == nil {
+			allow = &Decision{Allowed: true, StatementID: s.ID, RoleCode: role}
+		}
+	}
+
+	if allow != nil {
+		return *allow
+	}
+
+	return Decision{}
+}
+
+// Grants returns the allow statements which apply to the subject or
+// any of the roles, regardless of resource, operation or conditions.
+func (p *Policy) Grants(subject string, roles []string) []Grant {
+	var grants []Grant
+	for _, s := range p.Statements {
+		if s.Effect != Allow {
+			continue
+		}
+		role, ok := s.appliesTo(subject, roles)
+		if !ok {
+			continue
+		}
+		grants = append(grants, Grant{Statement: s, RoleCode: role})
+	}
+	return grants
+}
+
+// applies reports whether the statement applies to the request and,
+// if it applies through a role, the role's code
+func (s *Statement) applies(r Request) (string, bool) {
+	if !s.matches(r.Resource, r.Operation) {
+		return "", false
+	}
+	role, ok := s.appliesTo(r.Subject, r.Roles)
+	if !ok {
+		return "", false
+	}
+	if s.Conditions != nil && !s.Conditions.met(r) {
+		return "", false
+	}
+	return role, true
+}
+
+// appliesTo reports whether the statement applies to the subject or
+// any of the roles and, if it applies through a role, the role's code
+func (s *Statement) appliesTo(subject string, roles []string) (string, bool) {
+	for _, sub := range s.Subjects {
+		if sub == Wildcard || sub == subject {
+			return "", true
+		}
+	}
+	for _, want := range s.Roles {
+		for _, have := range roles {
+			if want == have {
+				return have, true
+			}
+		}
+	}
+	return "", false
+}
+
+// matches reports whether the statement covers the resource and operation
+func (s *Statement) matches(resource, operation string) bool {
+	for _, res := range s.Resources {
+		for _, o := range s.Operations {
+			if (diygoapi.Permission{Resource: res, Operation: o}).Matches(resource, operation) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// met reports whether the request meets all conditions
+func (c *Conditions) met(r Request) bool {
+	if len(c.prefixes) > 0 {
+		if !r.SourceIP.IsValid() {
+			return false
+		}
+		ip := r.SourceIP.Unmap()
+		in := false
+		for _, p := range c.prefixes {
+			if p.Contains(ip) {
+				in = true
+				break
+			}
+		}
+		if !in {
+			return false
+		}
+	}
+
+	t := r.Time.In(c.location)
+
+	if c.weekdays != nil && !c.weekdays[t.Weekday()] {
+		return false
+	}
+
+	if c.TimeOfDay != nil {
+		since := time.Duration(t.Hour())*time.Hour + time.Duration(t.Minute())*time.Minute
+		from, until := c.TimeOfDay.from, c.TimeOfDay.until
+		if from < until {
+			return since >= from && since < until
+		}
+		// the range spans midnight
+		return since >= from || since < until
+	}
+
+	return true
+}
+
+// parseWeekday parses the English name of a day of the week
+func parseWeekday(s string) (time.Weekday, bool) {
+	for d := time.Sunday; d <= time.Saturday; d++ {
+		if d.String() == s {
+			return d, true
+		}
+	}
+	return 0, false
+}
+
+// parseTimeOfDay parses a 24-hour clock time into the duration since midnight
+func parseTimeOfDay(s string) (time.Duration, error) {
+	t, err := time.Parse(timeOfDayLayout, s)
+	if err != nil {
+		return 0, err
+	}
+	return time.Duration(t.Hour())*time.Hour + time.Duration(t.Minute())*time.Minute, nil
+}
@@ -0,0 +1,198 @@
+package policy_test
+
+import (
+	"net/netip"
+	"testing"
+	"time"
+
+	qt "github.com/frankban/quicktest"
+
+	"github.com/gilcrest/diygoapi/errs"
+	"github.com/gilcrest/diygoapi/policy"
+)
+
+const testPolicy = `{
+  "statements": [
+    {
+      "id": "admins",
+      "effect": "allow",
+      "roles": ["sysAdmin"],
+      "resources": ["*"],
+      "operations": ["*"]
+    },
+    {
+      "id": "movie-readers",
+      "effect": "allow",
+      "subjects": ["*"],
+      "resources": ["/api/v1/movies/*"],
+      "operations": ["GET"]
+    },
+    {
+      "id": "office-hours",
+      "effect": "allow",
+      "roles": ["contractor"],
+      "resources": ["/api/v1/movies/*"],
+      "operations": ["PUT"],
+      "conditions": {
+        "source_ips": ["10.0.0.0/8"],
+        "weekdays": ["Monday", "Tuesday", "Wednesday", "Thursday", "Friday"],
+        "time_of_day": {"from": "09:00", "until": "17:00"},
+        "location": "America/Chicago"
+      }
+    },
+    {
+      "id": "no-deletes-for-otto",
+      "effect": "deny",
+      "subjects": ["otto"],
+      "resources": ["*"],
+      "operations": ["DELETE"]
+    }
+  ]
+}`
+
+func TestParse(t *testing.T) {
+	c := qt.New(t)
+
+	p, err := policy.Parse([]byte(testPolicy))
+	c.Assert(err, qt.IsNil)
+	c.Assert(p.Statements, qt.HasLen, 4)
+
+	invalid := []struct {
+		name string
+		doc  string
+	}{
+		{"malformed", `{"statements": [`},
+		{"unknown field", `{"statements": [{"id": "a", "effect": "allow", "subjects": ["*"], "resources": ["*"], "operations": ["*"], "priority": 1}]}`},
+		{"no statements", `{"statements": []}`},
+		{"no id", `{"statements": [{"effect": "allow", "subjects": ["*"], "resources": ["*"], "operations": ["*"]}]}`},
+		{"duplicate id", `{"statements": [{"id": "a", "effect": "allow", "subjects": ["*"], "resources": ["*"], "operations": ["*"]}, {"id": "a", "effect": "deny", "subjects": ["*"], "resources": ["*"], "operations": ["*"]}]}`},
+		{"bad effect", `{"statements": [{"id": "a", "effect": "maybe", "subjects": ["*"], "resources": ["*"], "operations": ["*"]}]}`},
+		{"no subject or role", `{"statements": [{"id": "a", "effect": "allow", "resources": ["*"], "operations": ["*"]}]}`},
+		{"no resource", `{"statements": [{"id": "a", "effect": "allow", "subjects": ["*"], "operations": ["*"]}]}`},
+		{"no operation", `{"statements": [{"id": "a", "effect": "allow", "subjects": ["*"], "resources": ["*"]}]}`},
+		{"bad cidr", `{"statements": [{"id": "a", "effect": "allow", "subjects": ["*"], "resources": ["*"], "operations": ["*"], "conditions": {"source_ips": ["10.0.0.0"]}}]}`},
+		{"bad weekday", `{"statements": [{"id": "a", "effect": "allow", "subjects": ["*"], "resources": ["*"], "operations": ["*"], "conditions": {"weekdays": ["Funday"]}}]}`},
+		{"bad time", `{"statements": [{"id": "a", "effect": "allow", "subjects": ["*"], "resources": ["*"], "operations": ["*"], "conditions": {"time_of_day": {"from": "9am", "until": "17:00"}}}]}`},
+		{"bad location", `{"statements": [{"id": "a", "effect": "allow", "subjects": ["*"], "resources": ["*"], "operations": ["*"], "conditions": {"location": "Mars/Olympus_Mons"}}]}`},
+	}
+	for _, tt := range invalid {
+		t.Run(tt.name, func(t *testing.T) {
+			c := qt.New(t)
+
+			_, err := policy.Parse([]byte(tt.doc))
+			c.Assert(errs.KindIs(errs.Validation, err), qt.IsTrue)
+		})
+	}
+}
+
+func TestPolicy_Evaluate(t *testing.T) {
+	p, err := policy.Parse([]byte(testPolicy))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	chicago, err := time.LoadLocation("America/Chicago")
+	if err != nil {
+		t.Fatal(err)
+	}
+	// a Wednesday
+	workday := time.Date(2026, 3, 4, 10, 30, 0, 0, chicago)
+	office := netip.MustParseAddr("10.1.2.3")
+
+	tests := []struct {
+		name string
+		req  policy.Request
+		want policy.Decision
+	}{
+		{
+			name: "role allows anything",
+			req:  policy.Request{Subject: "jane", Roles: []string{"sysAdmin"}, Resource: "/api/v1/orgs", Operation: "POST"},
+			want: policy.Decision{Allowed: true, StatementID: "admins", RoleCode: "sysAdmin"},
+		},
+		{
+			name: "any subject",
+			req:  policy.Request{Subject: "jane", Resource: "/api/v1/movies/{extlID}", Operation: "GET"},
+			want: policy.Decision{Allowed: true, StatementID: "movie-readers"},
+		},
+		{
+			name: "no statement applies",
+			req:  policy.Request{Subject: "jane", Resource: "/api/v1/orgs", Operation: "POST"},
+			want: policy.Decision{},
+		},
+		{
+			name: "deny overrides allow",
+			req:  policy.Request{Subject: "otto", Roles: []string{"sysAdmin"}, Resource: "/api/v1/orgs/{extlID}", Operation: "DELETE"},
+			want: policy.Decision{StatementID: "no-deletes-for-otto"},
+		},
+		{
+			name: "conditions met",
+			req:  policy.Request{Subject: "jane", Roles: []string{"contractor"}, Resource: "/api/v1/movies/{extlID}", Operation: "PUT", SourceIP: office, Time: workday},
+			want: policy.Decision{Allowed: true, StatementID: "office-hours", RoleCode: "contractor"},
+		},
+		{
+			name: "conditions met, time in another zone",
+			req:  policy.Request{Subject: "jane", Roles: []string{"contractor"}, Resource: "/api/v1/movies/{extlID}", Operation: "PUT", SourceIP: office, Time: workday.UTC()},
+			want: policy.Decision{Allowed: true, StatementID: "office-hours", RoleCode: "contractor"},
+		},
+		{
+			name: "IPv4-mapped IPv6 source",
+			req:  policy.Request{Subject: "jane", Roles: []string{"contractor"}, Resource: "/api/v1/movies/{extlID}", Operation: "PUT", SourceIP: netip.MustParseAddr("::ffff:10.1.2.3"), Time: workday},
+			want: policy.Decision{Allowed: true, StatementID: "office-hours", RoleCode: "contractor"},
+		},
+		{
+			name: "outside source IPs",
+			req:  policy.Request{Subject: "jane", Roles: []string{"contractor"}, Resource: "/api/v1/movies/{extlID}", Operation: "PUT", SourceIP: netip.MustParseAddr("192.168.1.1"), Time: workday},
+			want: policy.Decision{},
+		},
+		{
+			name: "unknown source IP",
+			req:  policy.Request{Subject: "jane", Roles: []string{"contractor"}, Resource: "/api/v1/movies/{extlID}", Operation: "PUT", Time: workday},
+			want: policy.Decision{},
+		},
+		{
+			name: "outside time of day",
+			req:  policy.Request{Subject: "jane", Roles: []string{"contractor"}, Resource: "/api/v1/movies/{extlID}", Operation: "PUT", SourceIP: office, Time: workday.Add(8 * time.Hour)},
+			want: policy.Decision{},
+		},
+		{
+			name: "weekend",
+			req:  policy.Request{Subject: "jane", Roles: []string{"contractor"}, Resource: "/api/v1/movies/{extlID}", Operation: "PUT", SourceIP: office, Time: workday.AddDate(0, 0, 3)},
+			want: policy.Decision{},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := qt.New(t)
+			c.Assert(p.Evaluate(tt.req), qt.Equals, tt.want)
+		})
+	}
+}
+
+func TestPolicy_Evaluate_overnight(t *testing.T) {
+	c := qt.New(t)
+
+	p, err := policy.Parse([]byte(`{"statements": [{"id": "night", "effect": "allow", "subjects": ["*"], "resources": ["*"], "operations": ["*"], "conditions": {"time_of_day": {"from": "22:00", "until": "06:00"}}}]}`))
+	c.Assert(err, qt.IsNil)
+
+	at := func(hour int) policy.Request {
+		return policy.Request{Subject: "jane", Resource: "/api/v1/ping", Operation: "GET", Time: time.Date(2026, 3, 4, hour, 0, 0, 0, time.UTC)}
+	}
+	c.Assert(p.Evaluate(at(23)).Allowed, qt.IsTrue)
+	c.Assert(p.Evaluate(at(2)).Allowed, qt.IsTrue)
+	c.Assert(p.Evaluate(at(6)).Allowed, qt.IsFalse)
+	c.Assert(p.Evaluate(at(12)).Allowed, qt.IsFalse)
+}
+
+func TestPolicy_Grants(t *testing.T) {
+	c := qt.New(t)
+
+	p, err := policy.Parse([]byte(testPolicy))
+	c.Assert(err, qt.IsNil)
+
+	grants := p.Grants("jane", []string{"contractor"})
+	c.Assert(grants, qt.HasLen, 2)
+	c.Assert(grants[0].Statement.ID, qt.Equals, "movie-readers")
+	c.Assert(grants[0].RoleCode, qt.Equals, "")
+	c.Assert(grants[1].Statement.ID, qt.Equals, "office-hours")
+	c.Assert(grants[1].RoleCode, qt.Equals, "contractor")
+}
@@ -0,0 +1 @@
+drop table if exists role_parent cascade;
\ No newline at end of file
@@ -0,0 +1 @@
+drop table if exists org_invitation cascade;
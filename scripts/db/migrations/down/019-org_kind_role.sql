@@ -0,0 +1 @@
+drop table if exists org_kind_role cascade;
@@ -0,0 +1,17 @@
+-- API keys were previously stored encrypted (reversible) in api_key.
+-- They are now stored as a one-way hash along with a plaintext lookup
+-- prefix.
+--
+-- For an existing database, run this script and then convert the
+-- encrypted keys to hashes with: mage -v migrateAPIKeys <env>
+-- 029-app_api_key_prefix_not_null.sql makes the prefix required once
+-- every key has been converted.
+alter table app_api_key
+    add column if not exists api_key_prefix varchar;
+
+comment on column app_api_key.api_key is 'api_key is a hash (HMAC-SHA256, hex encoded) of a key given to an person for an app';
+
+comment on column app_api_key.api_key_prefix is 'api_key_prefix is the first characters of the key, used to look up the key';
+
+create index if not exists app_api_key_prefix_ix
+    on app_api_key (app_id, api_key_prefix);
@@ -0,0 +1,13 @@
+-- API keys can be issued, rotated with a grace window and revoked
+-- for an existing app. The deactivation date becomes a timestamp so
+-- a grace window can be shorter than a day and the last time each key
+-- was used is tracked.
+alter table app_api_key
+    alter column deactv_date type timestamp with time zone;
+
+alter table app_api_key
+    add column if not exists last_used_timestamp timestamp with time zone;
+
+comment on column app_api_key.deactv_date is 'the moment the key is no longer usable';
+
+comment on column app_api_key.last_used_timestamp is 'the last time the key was used to authenticate';
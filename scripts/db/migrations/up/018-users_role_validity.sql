@@ -0,0 +1,19 @@
+-- Role grants can be bounded in time (e.g. a contractor is given a
+-- role for two weeks). A grant outside of its window does not
+-- authorize anything and expired grants can be purged.
+alter table users_role
+    add column if not exists valid_from timestamp with time zone;
+
+alter table users_role
+    add column if not exists valid_until timestamp with time zone;
+
+alter table users_role
+    drop constraint if exists users_role_valid_window_ck;
+
+alter table users_role
+    add constraint users_role_valid_window_ck
+        check (valid_from is null or valid_until is null or valid_from < valid_until);
+
+comment on column users_role.valid_from is 'The moment the role grant becomes effective. If null, the grant is effective from when it is created.';
+
+comment on column users_role.valid_until is 'The moment the role grant expires. If null, the grant does not expire.';
@@ -0,0 +1,16 @@
+-- A permission can be explicitly denied to a role (e.g. support staff
+-- may do everything under /orgs except DELETE). A deny takes
+-- precedence over any allow. Existing role permissions are allows.
+alter table role_permission
+    add column if not exists effect varchar default 'allow' not null;
+
+alter table role_permission
+    drop constraint if exists role_permission_effect_ck;
+
+alter table role_permission
+    add constraint role_permission_effect_ck
+        check (effect in ('allow', 'deny'));
+
+comment on column role_permission.effect is 'Whether the permission is allowed or denied for the role. A deny takes precedence over any allow the user holds for the same request.';
+
+comment on column authz_decision.role_cd is 'The role whose permission allowed the request or explicitly denied it. Null when no permission matched the request.';
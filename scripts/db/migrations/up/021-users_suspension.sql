@@ -0,0 +1,24 @@
+-- A user can be suspended (e.g. an employee who left the company).
+-- A suspended user cannot authenticate until reinstated. Existing
+-- users are active.
+alter table users
+    add column if not exists active boolean default true not null;
+
+alter table users
+    add column if not exists suspended_reason varchar;
+
+alter table users
+    add column if not exists suspended_timestamp timestamp with time zone;
+
+alter table users
+    drop constraint if exists users_suspended_ck;
+
+alter table users
+    add constraint users_suspended_ck
+        check (active = (suspended_timestamp is null));
+
+comment on column users.active is 'A boolean denoting whether the user is active (true) or suspended (false). A suspended user cannot authenticate.';
+
+comment on column users.suspended_reason is 'Why the user was suspended. Null when the user is active.';
+
+comment on column users.suspended_timestamp is 'The timestamp when the user was suspended. Null when the user is active.';
@@ -0,0 +1,83 @@
+create table if not exists org_invitation
+(
+    org_invitation_id      uuid                     not null,
+    org_invitation_extl_id varchar                  not null,
+    org_id                 uuid                     not null,
+    role_id                uuid                     not null,
+    email                  varchar                  not null,
+    expiry_timestamp       timestamp with time zone not null,
+    send_count             bigint default 1         not null,
+    last_sent_timestamp    timestamp with time zone not null,
+    accepted_user_id       uuid,
+    accepted_timestamp     timestamp with time zone,
+    revoked_timestamp      timestamp with time zone,
+    create_app_id          uuid                     not null,
+    create_user_id         uuid,
+    create_timestamp       timestamp with time zone not null,
+    update_app_id          uuid                     not null,
+    update_user_id         uuid,
+    update_timestamp       timestamp with time zone not null,
+    constraint org_invitation_pk
+        primary key (org_invitation_id),
+    constraint org_invitation_extl_id_ui
+        unique (org_invitation_extl_id),
+    constraint org_invitation_accepted_or_revoked_ck
+        check (accepted_timestamp is null or revoked_timestamp is null),
+    constraint org_invitation_org_id_fk
+        foreign key (org_id) references org,
+    constraint org_invitation_role_id_fk
+        foreign key (role_id) references role,
+    constraint org_invitation_accepted_user_id_fk
+        foreign key (accepted_user_id) references users,
+    constraint org_invitation_create_app_fk
+        foreign key (create_app_id) references app
+            deferrable initially deferred,
+    constraint org_invitation_update_app_fk
+        foreign key (update_app_id) references app
+            deferrable initially deferred,
+    constraint org_invitation_create_user_fk
+        foreign key (create_user_id) references users
+            deferrable initially deferred,
+    constraint org_invitation_update_user_fk
+        foreign key (update_user_id) references users
+            deferrable initially deferred
+);
+
+create index if not exists org_invitation_org_id_ix
+    on org_invitation (org_id);
+
+comment on table org_invitation is 'The org_invitation table stores the invitations given to people (by email) to join an organization with a role. An invitation is accepted when the invited person authenticates with its token and a matching email.';
+
+comment on column org_invitation.org_invitation_id is 'The unique ID for the invitation.';
+
+comment on column org_invitation.org_invitation_extl_id is 'The unique external ID for the invitation.';
+
+comment on column org_invitation.org_id is 'The organization the person is invited to join.';
+
+comment on column org_invitation.role_id is 'The role the person is given in the organization when the invitation is accepted.';
+
+comment on column org_invitation.email is 'The email of the invited person. It must match the email given by the authentication provider when the invitation is accepted.';
+
+comment on column org_invitation.expiry_timestamp is 'The moment the invitation token expires. A new token, with a new expiry, is issued each time the invitation is sent.';
+
+comment on column org_invitation.send_count is 'The number of times the invitation has been sent.';
+
+comment on column org_invitation.last_sent_timestamp is 'The timestamp when the invitation was last sent.';
+
+comment on column org_invitation.accepted_user_id is 'The user who accepted the invitation. Null until the invitation is accepted.';
+
+comment on column org_invitation.accepted_timestamp is 'The timestamp when the invitation was accepted. Null until the invitation is accepted.';
+
+comment on column org_invitation.revoked_timestamp is 'The timestamp when the invitation was revoked. Null unless the invitation is revoked.';
+
+comment on column org_invitation.create_app_id is 'The application which created this record.';
+
+comment on column org_invitation.create_user_id is 'The user which created this record.';
+
+comment on column org_invitation.create_timestamp is 'The timestamp when this record was created.';
+
+comment on column org_invitation.update_app_id is 'The application which performed the most recent update to this record.';
+
+comment on column org_invitation.update_user_id is 'The user which performed the most recent update to this record.';
+
+comment on column org_invitation.update_timestamp is 'The timestamp when the record was updated most recently.';
@@ -0,0 +1,19 @@
+-- An org can have a parent org (e.g. an agency managing several
+-- client orgs). A role granted in an org applies to all of its
+-- descendants. Existing orgs are top level.
+alter table org
+    add column if not exists parent_org_id uuid;
+
+alter table org
+    add constraint org_parent_org_fk
+        foreign key (parent_org_id) references org
+            deferrable initially deferred;
+
+alter table org
+    add constraint org_parent_org_ck
+        check (parent_org_id <> org_id);
+
+comment on column org.parent_org_id is 'The parent organization, null for a top level organization. A role granted in an organization applies to all of its descendants.';
+
+create index if not exists org_parent_org_id_ix
+    on org (parent_org_id);
@@ -0,0 +1,85 @@
+-- Orgs, apps and movies are soft deleted: a delete records when, and
+-- by which app and user, the record was deleted and the record is
+-- hard deleted (purged) once a retention period has passed. Existing
+-- records are not deleted.
+
+alter table app
+    add column if not exists deleted_app_id uuid;
+
+alter table app
+    add column if not exists deleted_user_id uuid;
+
+alter table app
+    add column if not exists deleted_timestamp timestamp with time zone;
+
+alter table app
+    add constraint app_deleted_ck
+        check ((deleted_timestamp is null) = (deleted_app_id is null));
+
+alter table app
+    add constraint app_self_ref3
+        foreign key (deleted_app_id) references app;
+
+comment on column app.deleted_app_id is 'The application which deleted this record. Null unless the record is deleted.';
+
+comment on column app.deleted_user_id is 'The user which deleted this record. Null unless the record is deleted.';
+
+comment on column app.deleted_timestamp is 'The timestamp when this record was deleted. A deleted record is kept until it is purged after a retention period. Null unless the record is deleted.';
+
+alter table movie
+    add column if not exists deleted_app_id uuid;
+
+alter table movie
+    add column if not exists deleted_user_id uuid;
+
+alter table movie
+    add column if not exists deleted_timestamp timestamp with time zone;
+
+alter table movie
+    add constraint movie_deleted_ck
+        check ((deleted_timestamp is null) = (deleted_app_id is null));
+
+alter table movie
+    add constraint movie_deleted_app_fk
+        foreign key (deleted_app_id) references app
+            deferrable initially deferred;
+
+alter table movie
+    add constraint movie_deleted_user_fk
+        foreign key (deleted_user_id) references users
+            deferrable initially deferred;
+
+comment on column movie.deleted_app_id is 'The application which deleted this record. Null unless the record is deleted.';
+
+comment on column movie.deleted_user_id is 'The user which deleted this record. Null unless the record is deleted.';
+
+comment on column movie.deleted_timestamp is 'The timestamp when this record was deleted. A deleted record is kept until it is purged after a retention period. Null unless the record is deleted.';
+
+alter table org
+    add column if not exists deleted_app_id uuid;
+
+alter table org
+    add column if not exists deleted_user_id uuid;
+
+alter table org
+    add column if not exists deleted_timestamp timestamp with time zone;
+
+alter table org
+    add constraint org_deleted_ck
+        check ((deleted_timestamp is null) = (deleted_app_id is null));
+
+alter table org
+    add constraint org_deleted_app_fk
+        foreign key (deleted_app_id) references app
+            deferrable initially deferred;
+
+alter table org
+    add constraint org_deleted_user_fk
+        foreign key (deleted_user_id) references users
+            deferrable initially deferred;
+
+comment on column org.deleted_app_id is 'The application which deleted this record. Null unless the record is deleted.';
+
+comment on column org.deleted_user_id is 'The user which deleted this record. Null unless the record is deleted.';
+
+comment on column org.deleted_timestamp is 'The timestamp when this record was deleted. A deleted record is kept until it is purged after a retention period. Null unless the record is deleted.';
@@ -0,0 +1,50 @@
+create table if not exists org_kind_role
+(
+    org_kind_id      uuid                     not null,
+    role_id          uuid                     not null,
+    create_app_id    uuid                     not null,
+    create_user_id   uuid,
+    create_timestamp timestamp with time zone not null,
+    update_app_id    uuid                     not null,
+    update_user_id   uuid,
+    update_timestamp timestamp with time zone not null,
+    constraint org_kind_role_pk
+        primary key (org_kind_id, role_id),
+    constraint org_kind_role_org_kind_id_fk
+        foreign key (org_kind_id) references org_kind,
+    constraint org_kind_role_role_id_fk
+        foreign key (role_id) references role,
+    constraint org_kind_role_create_app_fk
+        foreign key (create_app_id) references app
+            deferrable initially deferred,
+    constraint org_kind_role_update_app_fk
+        foreign key (update_app_id) references app
+            deferrable initially deferred,
+    constraint org_kind_role_create_user_fk
+        foreign key (create_user_id) references users
+            deferrable initially deferred,
+    constraint org_kind_role_update_user_fk
+        foreign key (update_user_id) references users
+            deferrable initially deferred
+);
+
+create index if not exists org_kind_role_role_id_ix
+    on org_kind_role (role_id);
+
+comment on table org_kind_role is 'The org_kind_role table stores the default roles of an organization kind. The user who creates an organization is granted the default roles of its kind in the organization.';
+
+comment on column org_kind_role.org_kind_id is 'The organization kind.';
+
+comment on column org_kind_role.role_id is 'The role granted to the creator of an organization of the kind.';
+
+comment on column org_kind_role.create_app_id is 'The application which created this record.';
+
+comment on column org_kind_role.create_user_id is 'The user which created this record.';
+
+comment on column org_kind_role.create_timestamp is 'The timestamp when this record was created.';
+
+comment on column org_kind_role.update_app_id is 'The application which performed the most recent update to this record.';
+
+comment on column org_kind_role.update_user_id is 'The user which performed the most recent update to this record.';
+
+comment on column org_kind_role.update_timestamp is 'The timestamp when the record was updated most recently.';
@@ -0,0 +1,10 @@
+-- The language preferences of a user are ordered, the most preferred
+-- first. Existing users have at most one preference.
+alter table users_lang_prefs
+    add column if not exists pref_order integer default 1 not null;
+
+alter table users_lang_prefs
+    add constraint users_lang_prefs_order_ui
+        unique (user_id, pref_order);
+
+comment on column users_lang_prefs.pref_order is 'The position of the language tag in the ordered preferences of the user, 1 being the most preferred.';
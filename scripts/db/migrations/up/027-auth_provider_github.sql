@@ -0,0 +1,11 @@
+-- Databases on which genesis was run before GitHub was supported only
+-- have the google auth_provider record. The github record is created
+-- with the audit data of the google record. On a new database, genesis
+-- creates every auth_provider record and this script does nothing.
+insert into auth_provider (auth_provider_id, auth_provider_cd, auth_provider_desc,
+                           create_app_id, create_user_id, create_timestamp,
+                           update_app_id, update_user_id, update_timestamp)
+select 2, 'github', 'GitHub Oauth2', create_app_id, create_user_id, now(), update_app_id, update_user_id, now()
+from auth_provider
+where auth_provider_id = 1
+on conflict do nothing;
@@ -0,0 +1,11 @@
+-- Databases on which genesis was run before OpenID Connect was supported
+-- do not have the oidc auth_provider record. The oidc record is created
+-- with the audit data of the google record. On a new database, genesis
+-- creates every auth_provider record and this script does nothing.
+insert into auth_provider (auth_provider_id, auth_provider_cd, auth_provider_desc,
+                           create_app_id, create_user_id, create_timestamp,
+                           update_app_id, update_user_id, update_timestamp)
+select 3, 'oidc', 'OpenID Connect', create_app_id, create_user_id, now(), update_app_id, update_user_id, now()
+from auth_provider
+where auth_provider_id = 1
+on conflict do nothing;
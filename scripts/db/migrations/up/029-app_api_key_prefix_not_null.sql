@@ -0,0 +1,16 @@
+-- API keys are looked up by api_key_prefix, so it is required. The
+-- prefix of a key stored encrypted (reversible) can only be backfilled
+-- by decrypting the key, which is done with: mage -v migrateAPIKeys <env>
+-- If any key has not been converted yet, this script fails and should
+-- be run again after the conversion.
+do
+$$
+    begin
+        if exists (select 1 from app_api_key where api_key_prefix is null) then
+            raise exception 'app_api_key has keys without an api_key_prefix, run mage -v migrateAPIKeys <env> and then run this script again';
+        end if;
+    end
+$$;
+
+alter table app_api_key
+    alter column api_key_prefix set not null;
@@ -0,0 +1,4 @@
+-- Authorization decisions are searched within the org the user is
+-- acting in.
+create index if not exists authz_decision_org_id_timestamp_ix
+    on authz_decision (org_id, decision_timestamp);
@@ -13,6 +13,9 @@ create table if not exists app
     update_app_id           uuid                     not null,
     update_user_id          uuid,
     update_timestamp        timestamp with time zone not null,
+    deleted_app_id          uuid,
+    deleted_user_id         uuid,
+    deleted_timestamp       timestamp with time zone,
     constraint app_pk
         primary key (app_id),
     constraint app_self_ref1
@@ -23,7 +26,11 @@ create table if not exists app
         foreign key (auth_provider_id) references auth_provider,
     constraint app_org_org_id_fk
         foreign key (org_id) references org
-            deferrable initially deferred
+            deferrable initially deferred,
+    constraint app_deleted_ck
+        check ((deleted_timestamp is null) = (deleted_app_id is null)),
+    constraint app_self_ref3
+        foreign key (deleted_app_id) references app
 );
 
 comment on table app is 'app stores data about applications that interact with the system';
@@ -54,6 +61,12 @@ comment on column app.update_user_id is 'The user which performed the most recen
 
 comment on column app.update_timestamp is 'The timestamp when the record was updated most recently.';
 
+comment on column app.deleted_app_id is 'The application which deleted this record. Null unless the record is deleted.';
+
+comment on column app.deleted_user_id is 'The user which deleted this record. Null unless the record is deleted.';
+
+comment on column app.deleted_timestamp is 'The timestamp when this record was deleted. A deleted record is kept until it is purged after a retention period. Null unless the record is deleted.';
+
 comment on constraint app_auth_provider_null_fk on app is 'Not every app has an associated auth provider, thus this field can be null.';
 
 create unique index if not exists app_app_extl_id_uindex
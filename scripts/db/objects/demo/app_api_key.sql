@@ -1,14 +1,16 @@
 create table if not exists app_api_key
 (
-    api_key          varchar                  not null,
-    app_id           uuid                     not null,
-    deactv_date      date                     not null,
-    create_app_id    uuid                     not null,
-    create_user_id   uuid,
-    create_timestamp timestamp with time zone not null,
-    update_app_id    uuid                     not null,
-    update_user_id   uuid,
-    update_timestamp timestamp with time zone not null,
+    api_key             varchar                  not null,
+    api_key_prefix      varchar                  not null,
+    app_id              uuid                     not null,
+    deactv_date         timestamp with time zone not null,
+    last_used_timestamp timestamp with time zone,
+    create_app_id       uuid                     not null,
+    create_user_id      uuid,
+    create_timestamp    timestamp with time zone not null,
+    update_app_id       uuid                     not null,
+    update_user_id      uuid,
+    update_timestamp    timestamp with time zone not null,
     constraint app_key_pk
         primary key (api_key),
     constraint app_key_app_app_id_fk
@@ -27,7 +29,15 @@ create table if not exists app_api_key
             deferrable initially deferred
 );
 
-comment on column app_api_key.api_key is 'app_key is a hash of a key given to an person for an app';
+comment on column app_api_key.api_key is 'api_key is a hash (HMAC-SHA256, hex encoded) of a key given to an person for an app';
+
+comment on column app_api_key.api_key_prefix is 'api_key_prefix is the first characters of the key, used to look up the key';
 
 comment on column app_api_key.app_id is 'foreign key to app table';
 
+comment on column app_api_key.deactv_date is 'the moment the key is no longer usable';
+
+comment on column app_api_key.last_used_timestamp is 'the last time the key was used to authenticate';
+
+create index if not exists app_api_key_prefix_ix
+    on app_api_key (app_id, api_key_prefix);
@@ -0,0 +1,48 @@
+create table if not exists authz_decision
+(
+    authz_decision_id  uuid                     not null,
+    user_id            uuid                     not null,
+    app_id             uuid                     not null,
+    org_id             uuid                     not null,
+    resource           varchar                  not null,
+    operation          varchar                  not null,
+    decision           varchar                  not null,
+    role_cd            varchar,
+    request_id         varchar,
+    decision_timestamp timestamp with time zone not null,
+    constraint authz_decision_pk
+        primary key (authz_decision_id),
+    constraint authz_decision_decision_ck
+        check (decision in ('allow', 'deny'))
+);
+
+create index if not exists authz_decision_user_id_timestamp_ix
+    on authz_decision (user_id, decision_timestamp);
+
+create index if not exists authz_decision_timestamp_ix
+    on authz_decision (decision_timestamp);
+
+create index if not exists authz_decision_org_id_timestamp_ix
+    on authz_decision (org_id, decision_timestamp);
+
+comment on table authz_decision is 'The authz_decision table stores the authorization decisions made for requests. Decisions are kept after the user, app or org they reference is removed, so there are no foreign keys.';
+
+comment on column authz_decision.authz_decision_id is 'The unique ID for the authorization decision.';
+
+comment on column authz_decision.user_id is 'The user the decision was made for.';
+
+comment on column authz_decision.app_id is 'The application the request was made through.';
+
+comment on column authz_decision.org_id is 'The organization the user was acting in.';
+
+comment on column authz_decision.resource is 'The resource requested (e.g. an HTTP route path template).';
+
+comment on column authz_decision.operation is 'The operation requested on the resource (e.g. GET, POST, etc.).';
+
+comment on column authz_decision.decision is 'The decision made, either allow or deny.';
+
+comment on column authz_decision.role_cd is 'The role whose permission allowed the request or explicitly denied it. Null when no permission matched the request.';
+
+comment on column authz_decision.request_id is 'The ID of the request the decision was made for.';
+
+comment on column authz_decision.decision_timestamp is 'The timestamp when the decision was made.';
@@ -1,19 +1,22 @@
 create table if not exists movie
 (
-    movie_id         uuid                     not null,
-    extl_id          varchar                  not null,
-    title            varchar(1000)            not null,
-    rated            varchar,
-    released         date,
-    run_time         integer,
-    director         varchar(1000),
-    writer           varchar(1000),
-    create_app_id    uuid                     not null,
-    create_user_id   uuid,
-    create_timestamp timestamp with time zone not null,
-    update_app_id    uuid                     not null,
-    update_user_id   uuid,
-    update_timestamp timestamp with time zone not null,
+    movie_id          uuid                     not null,
+    extl_id           varchar                  not null,
+    title             varchar(1000)            not null,
+    rated             varchar,
+    released          date,
+    run_time          integer,
+    director          varchar(1000),
+    writer            varchar(1000),
+    create_app_id     uuid                     not null,
+    create_user_id    uuid,
+    create_timestamp  timestamp with time zone not null,
+    update_app_id     uuid                     not null,
+    update_user_id    uuid,
+    update_timestamp  timestamp with time zone not null,
+    deleted_app_id    uuid,
+    deleted_user_id   uuid,
+    deleted_timestamp timestamp with time zone,
     constraint movie_pk
         primary key (movie_id),
     constraint movie_create_app_fk
@@ -27,6 +30,14 @@ create table if not exists movie
             deferrable initially deferred,
     constraint movie_update_user_fk
         foreign key (update_user_id) references users
+            deferrable initially deferred,
+    constraint movie_deleted_ck
+        check ((deleted_timestamp is null) = (deleted_app_id is null)),
+    constraint movie_deleted_app_fk
+        foreign key (deleted_app_id) references app
+            deferrable initially deferred,
+    constraint movie_deleted_user_fk
+        foreign key (deleted_user_id) references users
             deferrable initially deferred
 );
 
@@ -60,6 +71,12 @@ comment on column movie.update_user_id is 'The user which performed the most rec
 
 comment on column movie.update_timestamp is 'The timestamp when the record was updated most recently.';
 
+comment on column movie.deleted_app_id is 'The application which deleted this record. Null unless the record is deleted.';
+
+comment on column movie.deleted_user_id is 'The user which deleted this record. Null unless the record is deleted.';
+
+comment on column movie.deleted_timestamp is 'The timestamp when this record was deleted. A deleted record is kept until it is purged after a retention period. Null unless the record is deleted.';
+
 create unique index if not exists movie_extl_id_uindex
     on movie (extl_id);
 
@@ -1,16 +1,20 @@
 create table if not exists org
 (
-    org_id           uuid                     not null,
-    org_extl_id      varchar                  not null,
-    org_name         varchar                  not null,
-    org_description  varchar                  not null,
-    org_kind_id      uuid                     not null,
-    create_app_id    uuid                     not null,
-    create_user_id   uuid,
-    create_timestamp timestamp with time zone not null,
-    update_app_id    uuid                     not null,
-    update_user_id   uuid,
-    update_timestamp timestamp with time zone not null,
+    org_id            uuid                     not null,
+    org_extl_id       varchar                  not null,
+    org_name          varchar                  not null,
+    org_description   varchar                  not null,
+    org_kind_id       uuid                     not null,
+    create_app_id     uuid                     not null,
+    create_user_id    uuid,
+    create_timestamp  timestamp with time zone not null,
+    update_app_id     uuid                     not null,
+    update_user_id    uuid,
+    update_timestamp  timestamp with time zone not null,
+    parent_org_id     uuid,
+    deleted_app_id    uuid,
+    deleted_user_id   uuid,
+    deleted_timestamp timestamp with time zone,
     constraint org_pk
         primary key (org_id),
     constraint org_create_user_fk
@@ -27,6 +31,19 @@ create table if not exists org
             deferrable initially deferred,
     constraint org_org_kind_fk
         foreign key (org_kind_id) references org_kind
+            deferrable initially deferred,
+    constraint org_parent_org_fk
+        foreign key (parent_org_id) references org
+            deferrable initially deferred,
+    constraint org_parent_org_ck
+        check (parent_org_id <> org_id),
+    constraint org_deleted_ck
+        check ((deleted_timestamp is null) = (deleted_app_id is null)),
+    constraint org_deleted_app_fk
+        foreign key (deleted_app_id) references app
+            deferrable initially deferred,
+    constraint org_deleted_user_fk
+        foreign key (deleted_user_id) references users
             deferrable initially deferred
 );
 
@@ -52,6 +69,14 @@ comment on column org.update_user_id is 'The user which performed the most recen
 
 comment on column org.update_timestamp is 'The timestamp when the record was updated most recently.';
 
+comment on column org.deleted_app_id is 'The application which deleted this record. Null unless the record is deleted.';
+
+comment on column org.deleted_user_id is 'The user which deleted this record. Null unless the record is deleted.';
+
+comment on column org.deleted_timestamp is 'The timestamp when this record was deleted. A deleted record is kept until it is purged after a retention period. Null unless the record is deleted.';
+
+comment on column org.parent_org_id is 'The parent organization, null for a top level organization. A role granted in an organization applies to all of its descendants.';
+
 create unique index if not exists org_org_id_uindex
     on org (org_id);
 
@@ -61,3 +86,6 @@ create unique index if not exists org_org_name_uindex
 create unique index if not exists org_org_extl_id_uindex
     on org (org_extl_id);
 
+create index if not exists org_parent_org_id_ix
+    on org (parent_org_id);
+
@@ -0,0 +1,53 @@
+create table if not exists role_parent
+(
+    role_id          uuid                     not null,
+    parent_role_id   uuid                     not null,
+    create_app_id    uuid                     not null,
+    create_user_id   uuid,
+    create_timestamp timestamp with time zone not null,
+    update_app_id    uuid                     not null,
+    update_user_id   uuid,
+    update_timestamp timestamp with time zone not null,
+    constraint role_parent_pk
+        primary key (role_id, parent_role_id),
+    constraint role_parent_role_id_fk
+        foreign key (role_id) references role,
+    constraint role_parent_parent_role_id_fk
+        foreign key (parent_role_id) references role,
+    constraint role_parent_not_self_ck
+        check (role_id <> parent_role_id),
+    constraint role_parent_create_app_fk
+        foreign key (create_app_id) references app
+            deferrable initially deferred,
+    constraint role_parent_update_app_fk
+        foreign key (update_app_id) references app
+            deferrable initially deferred,
+    constraint role_parent_create_user_fk
+        foreign key (create_user_id) references users
+            deferrable initially deferred,
+    constraint role_parent_update_user_fk
+        foreign key (update_user_id) references users
+            deferrable initially deferred
+);
+
+create index if not exists role_parent_parent_role_id_ix
+    on role_parent (parent_role_id);
+
+comment on table role_parent is 'The role_parent table stores the role hierarchy. A role inherits all permissions of its ancestors.';
+
+comment on column role_parent.role_id is 'The role which inherits the permissions of the parent role.';
+
+comment on column role_parent.parent_role_id is 'The role whose permissions are inherited.';
+
+comment on column role_parent.create_app_id is 'The application which created this record.';
+
+comment on column role_parent.create_user_id is 'The user which created this record.';
+
+comment on column role_parent.create_timestamp is 'The timestamp when this record was created.';
+
+comment on column role_parent.update_app_id is 'The application which performed the most recent update to this record.';
+
+comment on column role_parent.update_user_id is 'The user which performed the most recent update to this record.';
+
+comment on column role_parent.update_timestamp is 'The timestamp when the record was updated most recently.';
+
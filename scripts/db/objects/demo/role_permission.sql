@@ -2,6 +2,7 @@ create table if not exists role_permission
 (
     role_id          uuid                     not null,
     permission_id    uuid                     not null,
+    effect           varchar default 'allow'  not null,
     create_app_id    uuid                     not null,
     create_user_id   uuid,
     create_timestamp timestamp with time zone not null,
@@ -10,6 +11,8 @@ create table if not exists role_permission
     update_timestamp timestamp with time zone not null,
     constraint role_permission_pk
         primary key (role_id, permission_id),
+    constraint role_permission_effect_ck
+        check (effect in ('allow', 'deny')),
     constraint role_permission_role_id_fk
         foreign key (role_id) references role,
     constraint role_permission_permission_id_fk
@@ -34,6 +37,8 @@ comment on column role_permission.role_id is 'The unique role which can have 1 t
 
 comment on column role_permission.permission_id is 'The unique permission that is being given to the role.';
 
+comment on column role_permission.effect is 'Whether the permission is allowed or denied for the role. A deny takes precedence over any allow the user holds for the same request.';
+
 comment on column role_permission.create_app_id is 'The application which created this record.';
 
 comment on column role_permission.create_user_id is 'The user which created this record.';
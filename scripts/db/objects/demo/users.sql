@@ -1,32 +1,37 @@
 create table if not exists users
 (
-    user_id          uuid                     not null,
-    user_extl_id     varchar                  not null,
-    person_id        uuid                     not null,
-    name_prefix      varchar,
-    first_name       varchar                  not null,
-    middle_name      varchar,
-    last_name        varchar                  not null,
-    name_suffix      varchar,
-    nickname         varchar,
-    email            varchar,
-    company_name     varchar,
-    company_dept     varchar,
-    job_title        varchar,
-    birth_date       date,
-    birth_year       bigint,
-    birth_month      bigint,
-    birth_day        bigint,
-    create_app_id    uuid                     not null,
-    create_user_id   uuid,
-    create_timestamp timestamp with time zone not null,
-    update_app_id    uuid                     not null,
-    update_user_id   uuid,
-    update_timestamp timestamp with time zone not null,
+    user_id             uuid                     not null,
+    user_extl_id        varchar                  not null,
+    person_id           uuid                     not null,
+    name_prefix         varchar,
+    first_name          varchar                  not null,
+    middle_name         varchar,
+    last_name           varchar                  not null,
+    name_suffix         varchar,
+    nickname            varchar,
+    email               varchar,
+    company_name        varchar,
+    company_dept        varchar,
+    job_title           varchar,
+    birth_date          date,
+    birth_year          bigint,
+    birth_month         bigint,
+    birth_day           bigint,
+    create_app_id       uuid                     not null,
+    create_user_id      uuid,
+    create_timestamp    timestamp with time zone not null,
+    update_app_id       uuid                     not null,
+    update_user_id      uuid,
+    update_timestamp    timestamp with time zone not null,
+    active              boolean default true     not null,
+    suspended_reason    varchar,
+    suspended_timestamp timestamp with time zone,
     constraint users_pk
         primary key (user_id),
     constraint users_extl_id_ui
         unique (user_extl_id),
+    constraint users_suspended_ck
+        check (active = (suspended_timestamp is null)),
     constraint users_create_app_fk
         foreign key (create_app_id) references app
             deferrable initially deferred,
@@ -48,3 +53,9 @@ comment on table users is 'users stores data about users that interact with the
 
 comment on column users.email is 'Primary email for the user';
 
+comment on column users.active is 'A boolean denoting whether the user is active (true) or suspended (false). A suspended user cannot authenticate.';
+
+comment on column users.suspended_reason is 'Why the user was suspended. Null when the user is active.';
+
+comment on column users.suspended_timestamp is 'The timestamp when the user was suspended. Null when the user is active.';
+
@@ -2,6 +2,7 @@ create table if not exists users_lang_prefs
 (
     user_id          uuid                     not null,
     language_tag     varchar                  not null,
+    pref_order       integer default 1        not null,
     create_app_id    uuid                     not null,
     create_user_id   uuid,
     create_timestamp timestamp with time zone not null,
@@ -10,6 +11,8 @@ create table if not exists users_lang_prefs
     update_timestamp timestamp with time zone not null,
     constraint users_lang_prefs_pk
         primary key (user_id, language_tag),
+    constraint users_lang_prefs_order_ui
+        unique (user_id, pref_order),
     constraint users_lang_prefs_user_id_fk
         foreign key (user_id) references users,
     constraint users_lang_prefs_create_app_fk
@@ -32,6 +35,8 @@ comment on column users_lang_prefs.user_id is 'The user having a language tag pr
 
 comment on column users_lang_prefs.language_tag is 'The BCP 47 Language Tag which identifies a language both spoken and written.';
 
+comment on column users_lang_prefs.pref_order is 'The position of the language tag in the ordered preferences of the user, 1 being the most preferred.';
+
 comment on column users_lang_prefs.create_app_id is 'The application which created this record.';
 
 comment on column users_lang_prefs.create_user_id is 'The user which created this record.';
@@ -3,6 +3,8 @@ create table if not exists users_role
     user_id          uuid                     not null,
     role_id          uuid                     not null,
     org_id           uuid                     not null,
+    valid_from       timestamp with time zone,
+    valid_until      timestamp with time zone,
     create_app_id    uuid                     not null,
     create_user_id   uuid,
     create_timestamp timestamp with time zone not null,
@@ -11,6 +13,8 @@ create table if not exists users_role
     update_timestamp timestamp with time zone not null,
     constraint users_role_pk
         primary key (user_id, role_id, org_id),
+    constraint users_role_valid_window_ck
+        check (valid_from is null or valid_until is null or valid_from < valid_until),
     constraint users_role_user_id_fk
         foreign key (user_id) references users,
     constraint users_role_role_id_fk
@@ -39,6 +43,10 @@ comment on column users_role.role_id is 'The role which can have one to many use
 
 comment on column users_role.org_id is 'The organization to which the role and user are associated.';
 
+comment on column users_role.valid_from is 'The moment the role grant becomes effective. If null, the grant is effective from when it is created.';
+
+comment on column users_role.valid_until is 'The moment the role grant expires. If null, the grant does not expire.';
+
 comment on column users_role.create_app_id is 'The application which created this record.';
 
 comment on column users_role.create_user_id is 'The user which created this record.';
@@ -3,7 +3,9 @@ package secure
 import (
 	"crypto/aes"
 	"crypto/cipher"
+	"crypto/hmac"
 	"crypto/rand"
+	"crypto/sha256"
 	"encoding/base64"
 	"encoding/hex"
 	"io"
@@ -164,3 +166,22 @@ func Decrypt(ciphertext []byte, key *[32]byte) (plaintext []byte, err error) {
 
 	return plaintext, nil
 }
+
+// HMAC returns the keyed hash (HMAC-SHA256) of message. Unlike Encrypt,
+// the result cannot be reversed, even with the key. Use hmac.Equal to
+// compare two hashes in constant time.
+func HMAC(message []byte, key *[32]byte) []byte {
+	mac := hmac.New(sha256.New, key[:])
+	mac.Write(message)
+	return mac.Sum(nil)
+}
+
+// DeriveKey derives a 256-bit key from key for the purpose named by
+// label (HMAC-SHA256 of the label). Keys derived with different labels
+// are independent of each other and of key, so a single secret can
+// be used for several purposes without one leaking into another.
+func DeriveKey(key *[32]byte, label string) *[32]byte {
+	dk := [32]byte{}
+	copy(dk[:], HMAC([]byte(label), key))
+	return &dk
+}
@@ -30,3 +30,33 @@ func TestParseEncryptionKey(t *testing.T) {
 		c.Assert(len(keyBytes), qt.Equals, 32)
 	})
 }
+
+func TestHMAC(t *testing.T) {
+	t.Run("deterministic and keyed", func(t *testing.T) {
+		c := qt.New(t)
+
+		k1, err := secure.NewEncryptionKey()
+		c.Assert(err, qt.IsNil)
+		var k2 *[32]byte
+		k2, err = secure.NewEncryptionKey()
+		c.Assert(err, qt.IsNil)
+
+		msg := []byte("some api key")
+		c.Assert(secure.HMAC(msg, k1), qt.DeepEquals, secure.HMAC(msg, k1))
+		c.Assert(secure.HMAC(msg, k1), qt.Not(qt.DeepEquals), secure.HMAC(msg, k2))
+		c.Assert(len(secure.HMAC(msg, k1)), qt.Equals, 32)
+	})
+}
+
+func TestDeriveKey(t *testing.T) {
+	t.Run("deterministic and separated by label", func(t *testing.T) {
+		c := qt.New(t)
+
+		k, err := secure.NewEncryptionKey()
+		c.Assert(err, qt.IsNil)
+
+		c.Assert(secure.DeriveKey(k, "a"), qt.DeepEquals, secure.DeriveKey(k, "a"))
+		c.Assert(secure.DeriveKey(k, "a"), qt.Not(qt.DeepEquals), secure.DeriveKey(k, "b"))
+		c.Assert(secure.DeriveKey(k, "a"), qt.Not(qt.DeepEquals), k)
+	})
+}
@@ -2,7 +2,9 @@ package server
 
 import (
 	"encoding/json"
+	"io"
 	"net/http"
+	"time"
 
 	"github.com/gorilla/mux"
 	"github.com/rs/zerolog/hlog"
@@ -115,7 +117,47 @@ func (s *Server) handleMovieDelete(w http.ResponseWriter, r *http.Request) {
 	vars := mux.Vars(r)
 	extlID := vars["extlID"]
 
-	response, err := s.MovieServicer.Delete(r.Context(), extlID)
+	adt, err := diygoapi.AuditFromRequest(r)
+	if err != nil {
+		errs.HTTPErrorResponse(w, logger, err)
+		return
+	}
+
+	var response diygoapi.DeleteResponse
+	response, err = s.MovieServicer.Delete(r.Context(), extlID, adt)
+	if err != nil {
+		errs.HTTPErrorResponse(w, logger, err)
+		return
+	}
+
+	// Encode response struct to JSON for the response body
+	err = json.NewEncoder(w).Encode(response)
+	if err != nil {
+		errs.HTTPErrorResponse(w, logger, errs.E(errs.Internal, err))
+		return
+	}
+}
+
+// handleMovieRestore handles POST requests for the
+// /movies/{id}/restore endpoint and restores the given deleted movie
+func (s *Server) handleMovieRestore(w http.ResponseWriter, r *http.Request) {
+
+	logger := *hlog.FromRequest(r)
+
+	// gorilla mux Vars function returns the route variables for the
+	// current request, if any. id is the external id given for the
+	// movie
+	vars := mux.Vars(r)
+	extlID := vars["extlID"]
+
+	adt, err := diygoapi.AuditFromRequest(r)
+	if err != nil {
+		errs.HTTPErrorResponse(w, logger, err)
+		return
+	}
+
+	var response *diygoapi.MovieResponse
+	response, err = s.MovieServicer.Restore(r.Context(), extlID, adt)
 	if err != nil {
 		errs.HTTPErrorResponse(w, logger, err)
 		return
@@ -141,7 +183,14 @@ func (s *Server) handleFindMovieByID(w http.ResponseWriter, r *http.Request) {
 	vars := mux.Vars(r)
 	extlID := vars["extlID"]
 
-	response, err := s.MovieServicer.FindMovieByExternalID(r.Context(), extlID)
+	includeDeleted, err := boolQueryParam(r.URL.Query(), "include_deleted")
+	if err != nil {
+		errs.HTTPErrorResponse(w, logger, err)
+		return
+	}
+
+	var response *diygoapi.MovieResponse
+	response, err = s.MovieServicer.FindMovieByExternalID(r.Context(), extlID, includeDeleted)
 	if err != nil {
 		errs.HTTPErrorResponse(w, logger, err)
 		return
@@ -161,7 +210,14 @@ func (s *Server) handleFindAllMovies(w http.ResponseWriter, r *http.Request) {
 
 	logger := *hlog.FromRequest(r)
 
-	response, err := s.MovieServicer.FindAllMovies(r.Context())
+	includeDeleted, err := boolQueryParam(r.URL.Query(), "include_deleted")
+	if err != nil {
+		errs.HTTPErrorResponse(w, logger, err)
+		return
+	}
+
+	var response []*diygoapi.MovieResponse
+	response, err = s.MovieServicer.FindAllMovies(r.Context(), includeDeleted)
 	if err != nil {
 		errs.HTTPErrorResponse(w, logger, err)
 		return
@@ -272,7 +328,51 @@ func (s *Server) handleOrgDelete(w http.ResponseWriter, r *http.Request) {
 	// extlID is the external id given for the resource
 	extlID := vars["extlID"]
 
-	response, err := s.OrgServicer.Delete(r.Context(), extlID)
+	adt, err := diygoapi.AuditFromRequest(r)
+	if err != nil {
+		errs.HTTPErrorResponse(w, lgr, err)
+		return
+	}
+
+	var cascade bool
+	cascade, err = boolQueryParam(r.URL.Query(), "cascade")
+	if err != nil {
+		errs.HTTPErrorResponse(w, lgr, err)
+		return
+	}
+
+	var response diygoapi.DeleteResponse
+	response, err = s.OrgServicer.Delete(r.Context(), extlID, cascade, adt)
+	if err != nil {
+		errs.HTTPErrorResponse(w, lgr, err)
+		return
+	}
+
+	// Encode response struct to JSON for the response body
+	err = json.NewEncoder(w).Encode(response)
+	if err != nil {
+		errs.HTTPErrorResponse(w, lgr, errs.E(errs.Internal, err))
+		return
+	}
+}
+
+// handleOrgRestore is a HandlerFunc used to restore a deleted Org
+func (s *Server) handleOrgRestore(w http.ResponseWriter, r *http.Request) {
+	lgr := *hlog.FromRequest(r)
+
+	// gorilla mux Vars function returns the route variables for the
+	// current request, if any. ID is the external id given for the resource
+	vars := mux.Vars(r)
+	extlID := vars["extlID"]
+
+	adt, err := diygoapi.AuditFromRequest(r)
+	if err != nil {
+		errs.HTTPErrorResponse(w, lgr, err)
+		return
+	}
+
+	var response *diygoapi.OrgResponse
+	response, err = s.OrgServicer.Restore(r.Context(), extlID, adt)
 	if err != nil {
 		errs.HTTPErrorResponse(w, lgr, err)
 		return
@@ -290,7 +390,14 @@ func (s *Server) handleOrgDelete(w http.ResponseWriter, r *http.Request) {
 func (s *Server) handleOrgFindAll(w http.ResponseWriter, r *http.Request) {
 	lgr := *hlog.FromRequest(r)
 
-	response, err := s.OrgServicer.FindAll(r.Context())
+	includeDeleted, err := boolQueryParam(r.URL.Query(), "include_deleted")
+	if err != nil {
+		errs.HTTPErrorResponse(w, lgr, err)
+		return
+	}
+
+	var response []*diygoapi.OrgResponse
+	response, err = s.OrgServicer.FindAll(r.Context(), includeDeleted)
 	if err != nil {
 		errs.HTTPErrorResponse(w, lgr, err)
 		return
@@ -313,7 +420,14 @@ func (s *Server) handleOrgFindByExtlID(w http.ResponseWriter, r *http.Request) {
 	vars := mux.Vars(r)
 	extlID := vars["extlID"]
 
-	response, err := s.OrgServicer.FindByExternalID(r.Context(), extlID)
+	includeDeleted, err := boolQueryParam(r.URL.Query(), "include_deleted")
+	if err != nil {
+		errs.HTTPErrorResponse(w, lgr, err)
+		return
+	}
+
+	var response *diygoapi.OrgResponse
+	response, err = s.OrgServicer.FindByExternalID(r.Context(), extlID, includeDeleted)
 	if err != nil {
 		errs.HTTPErrorResponse(w, lgr, err)
 		return
@@ -327,34 +441,22 @@ func (s *Server) handleOrgFindByExtlID(w http.ResponseWriter, r *http.Request) {
 	}
 }
 
-// handleAppCreate is a HandlerFunc used to create an App
-func (s *Server) handleAppCreate(w http.ResponseWriter, r *http.Request) {
+// handleOrgFindDescendants is a HandlerFunc used to list the
+// descendants of an Org
+func (s *Server) handleOrgFindDescendants(w http.ResponseWriter, r *http.Request) {
 	lgr := *hlog.FromRequest(r)
 
-	adt, err := diygoapi.AuditFromRequest(r)
-	if err != nil {
-		errs.HTTPErrorResponse(w, lgr, err)
-		return
-	}
-
-	// Declare request body (rb)
-	rb := new(diygoapi.CreateAppRequest)
+	vars := mux.Vars(r)
+	extlID := vars["extlID"]
 
-	// Decode JSON HTTP request body into a Decoder type
-	// and unmarshal that into the MovieRequest struct in the
-	// AddMovieHandler
-	err = json.NewDecoder(r.Body).Decode(&rb)
-	defer r.Body.Close()
-	// Call decoderErr to determine if body is nil, json is malformed
-	// or any other error
-	err = decoderErr(err)
+	includeDeleted, err := boolQueryParam(r.URL.Query(), "include_deleted")
 	if err != nil {
 		errs.HTTPErrorResponse(w, lgr, err)
 		return
 	}
 
-	var response *diygoapi.AppResponse
-	response, err = s.AppServicer.Create(r.Context(), rb, adt)
+	var response []*diygoapi.OrgResponse
+	response, err = s.OrgServicer.FindDescendants(r.Context(), extlID, includeDeleted)
 	if err != nil {
 		errs.HTTPErrorResponse(w, lgr, err)
 		return
@@ -368,8 +470,8 @@ func (s *Server) handleAppCreate(w http.ResponseWriter, r *http.Request) {
 	}
 }
 
-// handleRegister is a HandlerFunc used to register a User
-func (s *Server) handleRegister(w http.ResponseWriter, r *http.Request) {
+// handleOrgUserRoleAssign is a HandlerFunc used to assign a Role to a User within an Org
+func (s *Server) handleOrgUserRoleAssign(w http.ResponseWriter, r *http.Request) {
 	lgr := *hlog.FromRequest(r)
 
 	adt, err := diygoapi.AuditFromRequest(r)
@@ -378,49 +480,69 @@ func (s *Server) handleRegister(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	err = s.RegisterUserService.SelfRegister(r.Context(), adt)
+	// gorilla mux Vars function returns the route variables for the
+	// current request, if any. extlID is the external id given for the
+	// org, userExtlID is the external id of the user and roleCd is the
+	// code of the role being assigned
+	vars := mux.Vars(r)
+
+	// The request body is optional and only holds the validity window
+	// of the grant. An empty body grants the role without one.
+	rb := new(diygoapi.UserRoleRequest)
+	err = json.NewDecoder(r.Body).Decode(rb)
+	defer r.Body.Close()
+	if err != io.EOF {
+		err = decoderErr(err)
+		if err != nil {
+			errs.HTTPErrorResponse(w, lgr, err)
+			return
+		}
+	}
+
+	// the org, user and role are from path variables, need to set
+	// separate from decoding the request body
+	rb.OrgExternalID = vars["extlID"]
+	rb.UserExternalID = vars["userExtlID"]
+	rb.RoleCode = vars["roleCd"]
+
+	var response *diygoapi.UserRoleResponse
+	response, err = s.RoleServicer.AssignUserRole(r.Context(), rb, adt)
 	if err != nil {
 		errs.HTTPErrorResponse(w, lgr, err)
 		return
 	}
-}
-
-// handleLoggerRead handles GET requests for the /logger endpoint
-func (s *Server) handleLoggerRead(w http.ResponseWriter, r *http.Request) {
-	lgr := *hlog.FromRequest(r)
-
-	response := s.LoggerService.Read()
 
 	// Encode response struct to JSON for the response body
-	err := json.NewEncoder(w).Encode(response)
+	err = json.NewEncoder(w).Encode(response)
 	if err != nil {
 		errs.HTTPErrorResponse(w, lgr, errs.E(errs.Internal, err))
 		return
 	}
 }
 
-// handleLoggerUpdate handles PUT requests for the /logger endpoint
-// and updates the logger globals
-func (s *Server) handleLoggerUpdate(w http.ResponseWriter, r *http.Request) {
+// handleOrgUserRoleRevoke is a HandlerFunc used to revoke a Role from a User within an Org
+func (s *Server) handleOrgUserRoleRevoke(w http.ResponseWriter, r *http.Request) {
 	lgr := *hlog.FromRequest(r)
 
-	// Declare rb as an instance of service.LoggerRequest
-	rb := new(diygoapi.LoggerRequest)
+	// gorilla mux Vars function returns the route variables for the
+	// current request, if any. extlID is the external id given for the
+	// org, userExtlID is the external id of the user and roleCd is the
+	// code of the role being revoked
+	vars := mux.Vars(r)
+	rb := &diygoapi.UserRoleRequest{
+		OrgExternalID:  vars["extlID"],
+		UserExternalID: vars["userExtlID"],
+		RoleCode:       vars["roleCd"],
+	}
 
-	// Decode JSON HTTP request body into a json.Decoder type
-	// and unmarshal that into rb
-	err := json.NewDecoder(r.Body).Decode(&rb)
-	defer r.Body.Close()
-	// Call DecoderErr to determine if body is nil, json is malformed
-	// or any other error
-	err = decoderErr(err)
+	adt, err := diygoapi.AuditFromRequest(r)
 	if err != nil {
 		errs.HTTPErrorResponse(w, lgr, err)
 		return
 	}
 
-	var response *diygoapi.LoggerResponse
-	response, err = s.LoggerService.Update(rb)
+	var response diygoapi.DeleteResponse
+	response, err = s.RoleServicer.RevokeUserRole(r.Context(), rb, adt)
 	if err != nil {
 		errs.HTTPErrorResponse(w, lgr, err)
 		return
@@ -434,45 +556,45 @@ func (s *Server) handleLoggerUpdate(w http.ResponseWriter, r *http.Request) {
 	}
 }
 
-// Ping handles GET requests for the /ping endpoint
-func (s *Server) handlePing(w http.ResponseWriter, r *http.Request) {
-	// pull logger from request context
+// handleOrgRoleUsersFindAll is a HandlerFunc used to list the Users given a Role within an Org
+func (s *Server) handleOrgRoleUsersFindAll(w http.ResponseWriter, r *http.Request) {
 	lgr := *hlog.FromRequest(r)
 
-	// pull the context from the http request
-	ctx := r.Context()
+	// gorilla mux Vars function returns the route variables for the
+	// current request, if any. extlID is the external id given for the
+	// org and roleCd is the code of the role
+	vars := mux.Vars(r)
 
-	response := s.PingService.Ping(ctx, lgr)
+	response, err := s.RoleServicer.FindUsersByOrgRole(r.Context(), vars["extlID"], vars["roleCd"])
+	if err != nil {
+		errs.HTTPErrorResponse(w, lgr, err)
+		return
+	}
 
 	// Encode response struct to JSON for the response body
-	err := json.NewEncoder(w).Encode(response)
+	err = json.NewEncoder(w).Encode(response)
 	if err != nil {
 		errs.HTTPErrorResponse(w, lgr, errs.E(errs.Internal, err))
 		return
 	}
 }
 
-// handleGenesis handles POST requests for the /genesis endpoint
-func (s *Server) handleGenesis(w http.ResponseWriter, r *http.Request) {
+// handleOrgUsersFindAll is a HandlerFunc used to list the Users who are members of an Org
+func (s *Server) handleOrgUsersFindAll(w http.ResponseWriter, r *http.Request) {
 	lgr := *hlog.FromRequest(r)
 
-	// Declare rb as an instance of service.LoggerRequest
-	rb := new(diygoapi.GenesisRequest)
-
-	// Decode JSON HTTP request body into a json.Decoder type
-	// and unmarshal that into rb
-	err := json.NewDecoder(r.Body).Decode(&rb)
-	defer r.Body.Close()
-	// Call DecoderErr to determine if body is nil, json is malformed
-	// or any other error
-	err = decoderErr(err)
+	adt, err := diygoapi.AuditFromRequest(r)
 	if err != nil {
 		errs.HTTPErrorResponse(w, lgr, err)
 		return
 	}
 
-	var response diygoapi.GenesisResponse
-	response, err = s.GenesisServicer.Arche(r.Context(), rb)
+	// gorilla mux Vars function returns the route variables for the
+	// current request, if any. extlID is the external id given for the org
+	vars := mux.Vars(r)
+
+	var response []*diygoapi.UserResponse
+	response, err = s.UserServicer.FindByOrg(r.Context(), vars["extlID"], adt)
 	if err != nil {
 		errs.HTTPErrorResponse(w, lgr, err)
 		return
@@ -486,15 +608,23 @@ func (s *Server) handleGenesis(w http.ResponseWriter, r *http.Request) {
 	}
 }
 
-// handleGenesis handles GET requests for the /genesis endpoint
-func (s *Server) handleGenesisRead(w http.ResponseWriter, r *http.Request) {
+// handleOrgUserRemove is a HandlerFunc used to remove a User from an Org
+func (s *Server) handleOrgUserRemove(w http.ResponseWriter, r *http.Request) {
 	lgr := *hlog.FromRequest(r)
 
-	var (
-		response diygoapi.GenesisResponse
-		err      error
-	)
-	response, err = s.GenesisServicer.ReadConfig()
+	adt, err := diygoapi.AuditFromRequest(r)
+	if err != nil {
+		errs.HTTPErrorResponse(w, lgr, err)
+		return
+	}
+
+	// gorilla mux Vars function returns the route variables for the
+	// current request, if any. extlID is the external id given for the
+	// org and userExtlID is the external id of the user
+	vars := mux.Vars(r)
+
+	var response diygoapi.DeleteResponse
+	response, err = s.UserServicer.RemoveFromOrg(r.Context(), vars["extlID"], vars["userExtlID"], adt)
 	if err != nil {
 		errs.HTTPErrorResponse(w, lgr, err)
 		return
@@ -508,28 +638,25 @@ func (s *Server) handleGenesisRead(w http.ResponseWriter, r *http.Request) {
 	}
 }
 
-// handlePermissionCreate handles POST requests for the /permission endpoint
-func (s *Server) handlePermissionCreate(w http.ResponseWriter, r *http.Request) {
+// handleOrgInvitationCreate is a HandlerFunc used to invite a person
+// to join an Org
+func (s *Server) handleOrgInvitationCreate(w http.ResponseWriter, r *http.Request) {
 	lgr := *hlog.FromRequest(r)
 
-	var (
-		err error
-		adt diygoapi.Audit
-	)
-	adt, err = diygoapi.AuditFromRequest(r)
+	adt, err := diygoapi.AuditFromRequest(r)
 	if err != nil {
 		errs.HTTPErrorResponse(w, lgr, err)
 		return
 	}
 
-	// Declare rb as an instance of service.PermissionRequest
-	rb := new(diygoapi.CreatePermissionRequest)
+	// Declare request body (rb)
+	rb := new(diygoapi.CreateInvitationRequest)
 
-	// Decode JSON HTTP request body into a json.Decoder type
+	// Decode JSON HTTP request body into a Decoder type
 	// and unmarshal that into rb
 	err = json.NewDecoder(r.Body).Decode(&rb)
 	defer r.Body.Close()
-	// Call DecoderErr to determine if body is nil, json is malformed
+	// Call decoderErr to determine if body is nil, json is malformed
 	// or any other error
 	err = decoderErr(err)
 	if err != nil {
@@ -537,8 +664,13 @@ func (s *Server) handlePermissionCreate(w http.ResponseWriter, r *http.Request)
 		return
 	}
 
-	var response *diygoapi.PermissionResponse
-	response, err = s.PermissionServicer.Create(r.Context(), rb, adt)
+	// gorilla mux Vars function returns the route variables for the
+	// current request, if any. extlID is the external id given for the org
+	vars := mux.Vars(r)
+	rb.OrgExternalID = vars["extlID"]
+
+	var response *diygoapi.InvitationResponse
+	response, err = s.InvitationServicer.Create(r.Context(), rb, adt)
 	if err != nil {
 		errs.HTTPErrorResponse(w, lgr, err)
 		return
@@ -552,11 +684,23 @@ func (s *Server) handlePermissionCreate(w http.ResponseWriter, r *http.Request)
 	}
 }
 
-// handlePermissionFindAll handles GET requests for the /permission endpoint
-func (s *Server) handlePermissionFindAll(w http.ResponseWriter, r *http.Request) {
+// handleOrgInvitationsFindAll is a HandlerFunc used to list the
+// invitations of an Org
+func (s *Server) handleOrgInvitationsFindAll(w http.ResponseWriter, r *http.Request) {
 	lgr := *hlog.FromRequest(r)
 
-	response, err := s.PermissionServicer.FindAll(r.Context())
+	adt, err := diygoapi.AuditFromRequest(r)
+	if err != nil {
+		errs.HTTPErrorResponse(w, lgr, err)
+		return
+	}
+
+	// gorilla mux Vars function returns the route variables for the
+	// current request, if any. extlID is the external id given for the org
+	vars := mux.Vars(r)
+
+	var response []*diygoapi.InvitationResponse
+	response, err = s.InvitationServicer.FindByOrg(r.Context(), vars["extlID"], adt)
 	if err != nil {
 		errs.HTTPErrorResponse(w, lgr, err)
 		return
@@ -570,16 +714,1468 @@ func (s *Server) handlePermissionFindAll(w http.ResponseWriter, r *http.Request)
 	}
 }
 
-// handlePermissionDelete handles DELETE requests for the /permission endpoint
-func (s *Server) handlePermissionDelete(w http.ResponseWriter, r *http.Request) {
+// handleOrgInvitationResend is a HandlerFunc used to issue a new token
+// for an invitation
+func (s *Server) handleOrgInvitationResend(w http.ResponseWriter, r *http.Request) {
 	lgr := *hlog.FromRequest(r)
 
+	adt, err := diygoapi.AuditFromRequest(r)
+	if err != nil {
+		errs.HTTPErrorResponse(w, lgr, err)
+		return
+	}
+
 	// gorilla mux Vars function returns the route variables for the
-	// current request, if any. ID is the external id given for the resource
+	// current request, if any. extlID is the external id given for the
+	// org and invitationExtlID is the external id of the invitation
 	vars := mux.Vars(r)
-	extlID := vars["extlID"]
 
-	response, err := s.PermissionServicer.Delete(r.Context(), extlID)
+	var response *diygoapi.InvitationResponse
+	response, err = s.InvitationServicer.Resend(r.Context(), vars["extlID"], vars["invitationExtlID"], adt)
+	if err != nil {
+		errs.HTTPErrorResponse(w, lgr, err)
+		return
+	}
+
+	// Encode response struct to JSON for the response body
+	err = json.NewEncoder(w).Encode(response)
+	if err != nil {
+		errs.HTTPErrorResponse(w, lgr, errs.E(errs.Internal, err))
+		return
+	}
+}
+
+// handleOrgInvitationRevoke is a HandlerFunc used to revoke an invitation
+func (s *Server) handleOrgInvitationRevoke(w http.ResponseWriter, r *http.Request) {
+	lgr := *hlog.FromRequest(r)
+
+	adt, err := diygoapi.AuditFromRequest(r)
+	if err != nil {
+		errs.HTTPErrorResponse(w, lgr, err)
+		return
+	}
+
+	// gorilla mux Vars function returns the route variables for the
+	// current request, if any. extlID is the external id given for the
+	// org and invitationExtlID is the external id of the invitation
+	vars := mux.Vars(r)
+
+	var response diygoapi.DeleteResponse
+	response, err = s.InvitationServicer.Revoke(r.Context(), vars["extlID"], vars["invitationExtlID"], adt)
+	if err != nil {
+		errs.HTTPErrorResponse(w, lgr, err)
+		return
+	}
+
+	// Encode response struct to JSON for the response body
+	err = json.NewEncoder(w).Encode(response)
+	if err != nil {
+		errs.HTTPErrorResponse(w, lgr, errs.E(errs.Internal, err))
+		return
+	}
+}
+
+// handleOrgKindCreate is a HandlerFunc used to create an OrgKind
+func (s *Server) handleOrgKindCreate(w http.ResponseWriter, r *http.Request) {
+	lgr := *hlog.FromRequest(r)
+
+	adt, err := diygoapi.AuditFromRequest(r)
+	if err != nil {
+		errs.HTTPErrorResponse(w, lgr, err)
+		return
+	}
+
+	// Declare request body (rb)
+	rb := new(diygoapi.CreateOrgKindRequest)
+
+	// Decode JSON HTTP request body into a Decoder type
+	// and unmarshal that into rb
+	err = json.NewDecoder(r.Body).Decode(&rb)
+	defer r.Body.Close()
+	// Call decoderErr to determine if body is nil, json is malformed
+	// or any other error
+	err = decoderErr(err)
+	if err != nil {
+		errs.HTTPErrorResponse(w, lgr, err)
+		return
+	}
+
+	var response *diygoapi.OrgKindResponse
+	response, err = s.OrgKindServicer.Create(r.Context(), rb, adt)
+	if err != nil {
+		errs.HTTPErrorResponse(w, lgr, err)
+		return
+	}
+
+	// Encode response struct to JSON for the response body
+	err = json.NewEncoder(w).Encode(response)
+	if err != nil {
+		errs.HTTPErrorResponse(w, lgr, errs.E(errs.Internal, err))
+		return
+	}
+}
+
+// handleOrgKindFindAll is a HandlerFunc used to find all OrgKinds
+func (s *Server) handleOrgKindFindAll(w http.ResponseWriter, r *http.Request) {
+	lgr := *hlog.FromRequest(r)
+
+	response, err := s.OrgKindServicer.FindAll(r.Context())
+	if err != nil {
+		errs.HTTPErrorResponse(w, lgr, err)
+		return
+	}
+
+	// Encode response struct to JSON for the response body
+	err = json.NewEncoder(w).Encode(response)
+	if err != nil {
+		errs.HTTPErrorResponse(w, lgr, errs.E(errs.Internal, err))
+		return
+	}
+}
+
+// handleOrgKindFindByExtlID is a HandlerFunc used to find a specific OrgKind by External ID
+func (s *Server) handleOrgKindFindByExtlID(w http.ResponseWriter, r *http.Request) {
+	lgr := *hlog.FromRequest(r)
+
+	// gorilla mux Vars function returns the route variables for the
+	// current request, if any. ID is the external id given for the resource
+	vars := mux.Vars(r)
+	extlID := vars["extlID"]
+
+	response, err := s.OrgKindServicer.FindByExternalID(r.Context(), extlID)
+	if err != nil {
+		errs.HTTPErrorResponse(w, lgr, err)
+		return
+	}
+
+	// Encode response struct to JSON for the response body
+	err = json.NewEncoder(w).Encode(response)
+	if err != nil {
+		errs.HTTPErrorResponse(w, lgr, errs.E(errs.Internal, err))
+		return
+	}
+}
+
+// handleOrgKindUpdate is a HandlerFunc used to update an OrgKind
+func (s *Server) handleOrgKindUpdate(w http.ResponseWriter, r *http.Request) {
+	lgr := *hlog.FromRequest(r)
+
+	adt, err := diygoapi.AuditFromRequest(r)
+	if err != nil {
+		errs.HTTPErrorResponse(w, lgr, err)
+		return
+	}
+
+	// Declare request body (rb)
+	rb := new(diygoapi.UpdateOrgKindRequest)
+
+	// Decode JSON HTTP request body into a Decoder type
+	// and unmarshal that into rb
+	err = json.NewDecoder(r.Body).Decode(&rb)
+	defer r.Body.Close()
+	// Call decoderErr to determine if body is nil, json is malformed
+	// or any other error
+	err = decoderErr(err)
+	if err != nil {
+		errs.HTTPErrorResponse(w, lgr, err)
+		return
+	}
+
+	// gorilla mux Vars function returns the route variables for the
+	// current request, if any. ID is the external id given for the resource
+	vars := mux.Vars(r)
+	rb.ExternalID = vars["extlID"]
+
+	var response *diygoapi.OrgKindResponse
+	response, err = s.OrgKindServicer.Update(r.Context(), rb, adt)
+	if err != nil {
+		errs.HTTPErrorResponse(w, lgr, err)
+		return
+	}
+
+	// Encode response struct to JSON for the response body
+	err = json.NewEncoder(w).Encode(response)
+	if err != nil {
+		errs.HTTPErrorResponse(w, lgr, errs.E(errs.Internal, err))
+		return
+	}
+}
+
+// handleOrgKindDelete is a HandlerFunc used to delete an OrgKind
+func (s *Server) handleOrgKindDelete(w http.ResponseWriter, r *http.Request) {
+	lgr := *hlog.FromRequest(r)
+
+	adt, err := diygoapi.AuditFromRequest(r)
+	if err != nil {
+		errs.HTTPErrorResponse(w, lgr, err)
+		return
+	}
+
+	// gorilla mux Vars function returns the route variables for the
+	// current request, if any. ID is the external id given for the resource
+	vars := mux.Vars(r)
+	extlID := vars["extlID"]
+
+	var response diygoapi.DeleteResponse
+	response, err = s.OrgKindServicer.Delete(r.Context(), extlID, adt)
+	if err != nil {
+		errs.HTTPErrorResponse(w, lgr, err)
+		return
+	}
+
+	// Encode response struct to JSON for the response body
+	err = json.NewEncoder(w).Encode(response)
+	if err != nil {
+		errs.HTTPErrorResponse(w, lgr, errs.E(errs.Internal, err))
+		return
+	}
+}
+
+// handleUserFindAll is a HandlerFunc used to find a list of Users
+func (s *Server) handleUserFindAll(w http.ResponseWriter, r *http.Request) {
+	lgr := *hlog.FromRequest(r)
+
+	adt, err := diygoapi.AuditFromRequest(r)
+	if err != nil {
+		errs.HTTPErrorResponse(w, lgr, err)
+		return
+	}
+
+	var response []*diygoapi.UserResponse
+	response, err = s.UserServicer.FindAll(r.Context(), adt)
+	if err != nil {
+		errs.HTTPErrorResponse(w, lgr, err)
+		return
+	}
+
+	// Encode response struct to JSON for the response body
+	err = json.NewEncoder(w).Encode(response)
+	if err != nil {
+		errs.HTTPErrorResponse(w, lgr, errs.E(errs.Internal, err))
+		return
+	}
+}
+
+// handleUserFindByExtlID is a HandlerFunc used to find a specific User by External ID
+func (s *Server) handleUserFindByExtlID(w http.ResponseWriter, r *http.Request) {
+	lgr := *hlog.FromRequest(r)
+
+	// gorilla mux Vars function returns the route variables for the
+	// current request, if any. ID is the external id given for the resource
+	vars := mux.Vars(r)
+	extlID := vars["extlID"]
+
+	adt, err := diygoapi.AuditFromRequest(r)
+	if err != nil {
+		errs.HTTPErrorResponse(w, lgr, err)
+		return
+	}
+
+	var response *diygoapi.UserResponse
+	response, err = s.UserServicer.FindByExternalID(r.Context(), extlID, adt)
+	if err != nil {
+		errs.HTTPErrorResponse(w, lgr, err)
+		return
+	}
+
+	// Encode response struct to JSON for the response body
+	err = json.NewEncoder(w).Encode(response)
+	if err != nil {
+		errs.HTTPErrorResponse(w, lgr, errs.E(errs.Internal, err))
+		return
+	}
+}
+
+// handleUserUpdate is a HandlerFunc used to update the profile of a User
+func (s *Server) handleUserUpdate(w http.ResponseWriter, r *http.Request) {
+	lgr := *hlog.FromRequest(r)
+
+	adt, err := diygoapi.AuditFromRequest(r)
+	if err != nil {
+		errs.HTTPErrorResponse(w, lgr, err)
+		return
+	}
+
+	// Declare request body (rb)
+	rb := new(diygoapi.UpdateUserRequest)
+
+	// Decode JSON HTTP request body into a Decoder type
+	// and unmarshal that into rb
+	err = json.NewDecoder(r.Body).Decode(&rb)
+	defer r.Body.Close()
+	// Call decoderErr to determine if body is nil, json is malformed
+	// or any other error
+	err = decoderErr(err)
+	if err != nil {
+		errs.HTTPErrorResponse(w, lgr, err)
+		return
+	}
+
+	// gorilla mux Vars function returns the route variables for the
+	// current request, if any. ID is the external id given for the resource
+	vars := mux.Vars(r)
+	rb.ExternalID = vars["extlID"]
+
+	var response *diygoapi.UserResponse
+	response, err = s.UserServicer.Update(r.Context(), rb, adt)
+	if err != nil {
+		errs.HTTPErrorResponse(w, lgr, err)
+		return
+	}
+
+	// Encode response struct to JSON for the response body
+	err = json.NewEncoder(w).Encode(response)
+	if err != nil {
+		errs.HTTPErrorResponse(w, lgr, errs.E(errs.Internal, err))
+		return
+	}
+}
+
+// handleUserSuspend is a HandlerFunc used to suspend a User, which
+// prevents them from authenticating
+func (s *Server) handleUserSuspend(w http.ResponseWriter, r *http.Request) {
+	lgr := *hlog.FromRequest(r)
+
+	adt, err := diygoapi.AuditFromRequest(r)
+	if err != nil {
+		errs.HTTPErrorResponse(w, lgr, err)
+		return
+	}
+
+	// Declare request body (rb)
+	rb := new(diygoapi.SuspendUserRequest)
+
+	// Decode JSON HTTP request body into a Decoder type
+	// and unmarshal that into rb
+	err = json.NewDecoder(r.Body).Decode(&rb)
+	defer r.Body.Close()
+	// Call decoderErr to determine if body is nil, json is malformed
+	// or any other error
+	err = decoderErr(err)
+	if err != nil {
+		errs.HTTPErrorResponse(w, lgr, err)
+		return
+	}
+
+	// gorilla mux Vars function returns the route variables for the
+	// current request, if any. ID is the external id given for the resource
+	vars := mux.Vars(r)
+	rb.ExternalID = vars["extlID"]
+
+	var response *diygoapi.UserResponse
+	response, err = s.UserServicer.Suspend(r.Context(), rb, adt)
+	if err != nil {
+		errs.HTTPErrorResponse(w, lgr, err)
+		return
+	}
+
+	// Encode response struct to JSON for the response body
+	err = json.NewEncoder(w).Encode(response)
+	if err != nil {
+		errs.HTTPErrorResponse(w, lgr, errs.E(errs.Internal, err))
+		return
+	}
+}
+
+// handleUserReinstate is a HandlerFunc used to reinstate a suspended User
+func (s *Server) handleUserReinstate(w http.ResponseWriter, r *http.Request) {
+	lgr := *hlog.FromRequest(r)
+
+	adt, err := diygoapi.AuditFromRequest(r)
+	if err != nil {
+		errs.HTTPErrorResponse(w, lgr, err)
+		return
+	}
+
+	// gorilla mux Vars function returns the route variables for the
+	// current request, if any. ID is the external id given for the resource
+	vars := mux.Vars(r)
+	extlID := vars["extlID"]
+
+	var response *diygoapi.UserResponse
+	response, err = s.UserServicer.Reinstate(r.Context(), extlID, adt)
+	if err != nil {
+		errs.HTTPErrorResponse(w, lgr, err)
+		return
+	}
+
+	// Encode response struct to JSON for the response body
+	err = json.NewEncoder(w).Encode(response)
+	if err != nil {
+		errs.HTTPErrorResponse(w, lgr, errs.E(errs.Internal, err))
+		return
+	}
+}
+
+// handleUserLanguagesFind is a HandlerFunc used to find the language
+// preferences of a User
+func (s *Server) handleUserLanguagesFind(w http.ResponseWriter, r *http.Request) {
+	lgr := *hlog.FromRequest(r)
+
+	adt, err := diygoapi.AuditFromRequest(r)
+	if err != nil {
+		errs.HTTPErrorResponse(w, lgr, err)
+		return
+	}
+
+	// gorilla mux Vars function returns the route variables for the
+	// current request, if any. ID is the external id given for the resource
+	vars := mux.Vars(r)
+	extlID := vars["extlID"]
+
+	var response *diygoapi.UserLanguagePreferencesResponse
+	response, err = s.UserServicer.FindLanguagePreferences(r.Context(), extlID, adt)
+	if err != nil {
+		errs.HTTPErrorResponse(w, lgr, err)
+		return
+	}
+
+	// Encode response struct to JSON for the response body
+	err = json.NewEncoder(w).Encode(response)
+	if err != nil {
+		errs.HTTPErrorResponse(w, lgr, errs.E(errs.Internal, err))
+		return
+	}
+}
+
+// handleUserLanguagesUpdate is a HandlerFunc used to replace the
+// language preferences of a User
+func (s *Server) handleUserLanguagesUpdate(w http.ResponseWriter, r *http.Request) {
+	lgr := *hlog.FromRequest(r)
+
+	adt, err := diygoapi.AuditFromRequest(r)
+	if err != nil {
+		errs.HTTPErrorResponse(w, lgr, err)
+		return
+	}
+
+	// Declare request body (rb)
+	rb := new(diygoapi.UpdateUserLanguagePreferencesRequest)
+
+	// Decode JSON HTTP request body into a Decoder type
+	// and unmarshal that into rb
+	err = json.NewDecoder(r.Body).Decode(&rb)
+	defer r.Body.Close()
+	// Call decoderErr to determine if body is nil, json is malformed
+	// or any other error
+	err = decoderErr(err)
+	if err != nil {
+		errs.HTTPErrorResponse(w, lgr, err)
+		return
+	}
+
+	// gorilla mux Vars function returns the route variables for the
+	// current request, if any. ID is the external id given for the resource
+	vars := mux.Vars(r)
+	rb.ExternalID = vars["extlID"]
+
+	var response *diygoapi.UserLanguagePreferencesResponse
+	response, err = s.UserServicer.UpdateLanguagePreferences(r.Context(), rb, adt)
+	if err != nil {
+		errs.HTTPErrorResponse(w, lgr, err)
+		return
+	}
+
+	// Encode response struct to JSON for the response body
+	err = json.NewEncoder(w).Encode(response)
+	if err != nil {
+		errs.HTTPErrorResponse(w, lgr, errs.E(errs.Internal, err))
+		return
+	}
+}
+
+// handleUserRolesExpiredFindAll is a HandlerFunc used to list the role
+// grants whose validity window has ended
+func (s *Server) handleUserRolesExpiredFindAll(w http.ResponseWriter, r *http.Request) {
+	lgr := *hlog.FromRequest(r)
+
+	response, err := s.RoleServicer.FindExpiredUserRoles(r.Context())
+	if err != nil {
+		errs.HTTPErrorResponse(w, lgr, err)
+		return
+	}
+
+	// Encode response struct to JSON for the response body
+	err = json.NewEncoder(w).Encode(response)
+	if err != nil {
+		errs.HTTPErrorResponse(w, lgr, errs.E(errs.Internal, err))
+		return
+	}
+}
+
+// handleUserRolesExpiredPurge is a HandlerFunc used to delete the role
+// grants whose validity window has ended
+func (s *Server) handleUserRolesExpiredPurge(w http.ResponseWriter, r *http.Request) {
+	lgr := *hlog.FromRequest(r)
+
+	response, err := s.RoleServicer.PurgeExpiredUserRoles(r.Context())
+	if err != nil {
+		errs.HTTPErrorResponse(w, lgr, err)
+		return
+	}
+
+	// Encode response struct to JSON for the response body
+	err = json.NewEncoder(w).Encode(response)
+	if err != nil {
+		errs.HTTPErrorResponse(w, lgr, errs.E(errs.Internal, err))
+		return
+	}
+}
+
+// handleAppCreate is a HandlerFunc used to create an App
+func (s *Server) handleAppCreate(w http.ResponseWriter, r *http.Request) {
+	lgr := *hlog.FromRequest(r)
+
+	adt, err := diygoapi.AuditFromRequest(r)
+	if err != nil {
+		errs.HTTPErrorResponse(w, lgr, err)
+		return
+	}
+
+	// Declare request body (rb)
+	rb := new(diygoapi.CreateAppRequest)
+
+	// Decode JSON HTTP request body into a Decoder type
+	// and unmarshal that into the MovieRequest struct in the
+	// AddMovieHandler
+	err = json.NewDecoder(r.Body).Decode(&rb)
+	defer r.Body.Close()
+	// Call decoderErr to determine if body is nil, json is malformed
+	// or any other error
+	err = decoderErr(err)
+	if err != nil {
+		errs.HTTPErrorResponse(w, lgr, err)
+		return
+	}
+
+	var response *diygoapi.AppResponse
+	response, err = s.AppServicer.Create(r.Context(), rb, adt)
+	if err != nil {
+		errs.HTTPErrorResponse(w, lgr, err)
+		return
+	}
+
+	// Encode response struct to JSON for the response body
+	err = json.NewEncoder(w).Encode(response)
+	if err != nil {
+		errs.HTTPErrorResponse(w, lgr, errs.E(errs.Internal, err))
+		return
+	}
+}
+
+// handleAppUpdate is a HandlerFunc used to update an App
+func (s *Server) handleAppUpdate(w http.ResponseWriter, r *http.Request) {
+	lgr := *hlog.FromRequest(r)
+
+	adt, err := diygoapi.AuditFromRequest(r)
+	if err != nil {
+		errs.HTTPErrorResponse(w, lgr, err)
+		return
+	}
+
+	// Declare request body (rb)
+	rb := new(diygoapi.UpdateAppRequest)
+
+	// Decode JSON HTTP request body into a Decoder type
+	// and unmarshal that into the UpdateAppRequest struct
+	err = json.NewDecoder(r.Body).Decode(&rb)
+	defer r.Body.Close()
+	// Call decoderErr to determine if body is nil, json is malformed
+	// or any other error
+	err = decoderErr(err)
+	if err != nil {
+		errs.HTTPErrorResponse(w, lgr, err)
+		return
+	}
+
+	// gorilla mux Vars function returns the route variables for the
+	// current request, if any. ID is the external id given for the resource
+	vars := mux.Vars(r)
+	rb.ExternalID = vars["extlID"]
+
+	var response *diygoapi.AppResponse
+	response, err = s.AppServicer.Update(r.Context(), rb, adt)
+	if err != nil {
+		errs.HTTPErrorResponse(w, lgr, err)
+		return
+	}
+
+	// Encode response struct to JSON for the response body
+	err = json.NewEncoder(w).Encode(response)
+	if err != nil {
+		errs.HTTPErrorResponse(w, lgr, errs.E(errs.Internal, err))
+		return
+	}
+}
+
+// handleAppDelete is a HandlerFunc used to delete an App
+func (s *Server) handleAppDelete(w http.ResponseWriter, r *http.Request) {
+	lgr := *hlog.FromRequest(r)
+
+	// gorilla mux Vars function returns the route variables for the
+	// current request, if any.
+	vars := mux.Vars(r)
+	// extlID is the external id given for the resource
+	extlID := vars["extlID"]
+
+	adt, err := diygoapi.AuditFromRequest(r)
+	if err != nil {
+		errs.HTTPErrorResponse(w, lgr, err)
+		return
+	}
+
+	var response diygoapi.DeleteResponse
+	response, err = s.AppServicer.Delete(r.Context(), extlID, adt)
+	if err != nil {
+		errs.HTTPErrorResponse(w, lgr, err)
+		return
+	}
+
+	// Encode response struct to JSON for the response body
+	err = json.NewEncoder(w).Encode(response)
+	if err != nil {
+		errs.HTTPErrorResponse(w, lgr, errs.E(errs.Internal, err))
+		return
+	}
+}
+
+// handleAppRestore is a HandlerFunc used to restore a deleted App
+func (s *Server) handleAppRestore(w http.ResponseWriter, r *http.Request) {
+	lgr := *hlog.FromRequest(r)
+
+	// gorilla mux Vars function returns the route variables for the
+	// current request, if any. ID is the external id given for the resource
+	vars := mux.Vars(r)
+	extlID := vars["extlID"]
+
+	adt, err := diygoapi.AuditFromRequest(r)
+	if err != nil {
+		errs.HTTPErrorResponse(w, lgr, err)
+		return
+	}
+
+	var response *diygoapi.AppResponse
+	response, err = s.AppServicer.Restore(r.Context(), extlID, adt)
+	if err != nil {
+		errs.HTTPErrorResponse(w, lgr, err)
+		return
+	}
+
+	// Encode response struct to JSON for the response body
+	err = json.NewEncoder(w).Encode(response)
+	if err != nil {
+		errs.HTTPErrorResponse(w, lgr, errs.E(errs.Internal, err))
+		return
+	}
+}
+
+// handleAppFindAll is a HandlerFunc used to find the Apps of the
+// Org the user is acting in
+func (s *Server) handleAppFindAll(w http.ResponseWriter, r *http.Request) {
+	lgr := *hlog.FromRequest(r)
+
+	adt, err := diygoapi.AuditFromRequest(r)
+	if err != nil {
+		errs.HTTPErrorResponse(w, lgr, err)
+		return
+	}
+
+	var includeDeleted bool
+	includeDeleted, err = boolQueryParam(r.URL.Query(), "include_deleted")
+	if err != nil {
+		errs.HTTPErrorResponse(w, lgr, err)
+		return
+	}
+
+	var response []*diygoapi.AppResponse
+	response, err = s.AppServicer.FindAll(r.Context(), includeDeleted, adt)
+	if err != nil {
+		errs.HTTPErrorResponse(w, lgr, err)
+		return
+	}
+
+	// Encode response struct to JSON for the response body
+	err = json.NewEncoder(w).Encode(response)
+	if err != nil {
+		errs.HTTPErrorResponse(w, lgr, errs.E(errs.Internal, err))
+		return
+	}
+}
+
+// handleAppFindByExtlID is a HandlerFunc used to find a specific App by External ID
+func (s *Server) handleAppFindByExtlID(w http.ResponseWriter, r *http.Request) {
+	lgr := *hlog.FromRequest(r)
+
+	// gorilla mux Vars function returns the route variables for the
+	// current request, if any. ID is the external id given for the resource
+	vars := mux.Vars(r)
+	extlID := vars["extlID"]
+
+	adt, err := diygoapi.AuditFromRequest(r)
+	if err != nil {
+		errs.HTTPErrorResponse(w, lgr, err)
+		return
+	}
+
+	var includeDeleted bool
+	includeDeleted, err = boolQueryParam(r.URL.Query(), "include_deleted")
+	if err != nil {
+		errs.HTTPErrorResponse(w, lgr, err)
+		return
+	}
+
+	var response *diygoapi.AppResponse
+	response, err = s.AppServicer.FindByExternalID(r.Context(), extlID, includeDeleted, adt)
+	if err != nil {
+		errs.HTTPErrorResponse(w, lgr, err)
+		return
+	}
+
+	// Encode response struct to JSON for the response body
+	err = json.NewEncoder(w).Encode(response)
+	if err != nil {
+		errs.HTTPErrorResponse(w, lgr, errs.E(errs.Internal, err))
+		return
+	}
+}
+
+// handleAPIKeyCreate is a HandlerFunc used to issue (or rotate) an API key for an App
+func (s *Server) handleAPIKeyCreate(w http.ResponseWriter, r *http.Request) {
+	lgr := *hlog.FromRequest(r)
+
+	adt, err := diygoapi.AuditFromRequest(r)
+	if err != nil {
+		errs.HTTPErrorResponse(w, lgr, err)
+		return
+	}
+
+	// Declare request body (rb)
+	rb := new(diygoapi.CreateAPIKeyRequest)
+
+	// Decode JSON HTTP request body into a Decoder type
+	// and unmarshal that into rb
+	err = json.NewDecoder(r.Body).Decode(&rb)
+	defer r.Body.Close()
+	// Call decoderErr to determine if body is nil, json is malformed
+	// or any other error
+	err = decoderErr(err)
+	if err != nil {
+		errs.HTTPErrorResponse(w, lgr, err)
+		return
+	}
+
+	// gorilla mux Vars function returns the route variables for the
+	// current request, if any. extlID is the external id given for the app
+	vars := mux.Vars(r)
+	rb.AppExternalID = vars["extlID"]
+
+	var response *diygoapi.APIKeyResponse
+	response, err = s.AppServicer.CreateAPIKey(r.Context(), rb, adt)
+	if err != nil {
+		errs.HTTPErrorResponse(w, lgr, err)
+		return
+	}
+
+	// Encode response struct to JSON for the response body
+	err = json.NewEncoder(w).Encode(response)
+	if err != nil {
+		errs.HTTPErrorResponse(w, lgr, errs.E(errs.Internal, err))
+		return
+	}
+}
+
+// handleAPIKeyFindAll is a HandlerFunc used to list the API keys of an App
+func (s *Server) handleAPIKeyFindAll(w http.ResponseWriter, r *http.Request) {
+	lgr := *hlog.FromRequest(r)
+
+	// gorilla mux Vars function returns the route variables for the
+	// current request, if any. extlID is the external id given for the app
+	vars := mux.Vars(r)
+	extlID := vars["extlID"]
+
+	adt, err := diygoapi.AuditFromRequest(r)
+	if err != nil {
+		errs.HTTPErrorResponse(w, lgr, err)
+		return
+	}
+
+	var response []*diygoapi.APIKeyMetadataResponse
+	response, err = s.AppServicer.FindAPIKeys(r.Context(), extlID, adt)
+	if err != nil {
+		errs.HTTPErrorResponse(w, lgr, err)
+		return
+	}
+
+	// Encode response struct to JSON for the response body
+	err = json.NewEncoder(w).Encode(response)
+	if err != nil {
+		errs.HTTPErrorResponse(w, lgr, errs.E(errs.Internal, err))
+		return
+	}
+}
+
+// handleAPIKeyRevoke is a HandlerFunc used to revoke an API key of an App
+func (s *Server) handleAPIKeyRevoke(w http.ResponseWriter, r *http.Request) {
+	lgr := *hlog.FromRequest(r)
+
+	// gorilla mux Vars function returns the route variables for the
+	// current request, if any. extlID is the external id given for the
+	// app, keyPrefix is the lookup prefix of the API key
+	vars := mux.Vars(r)
+	extlID := vars["extlID"]
+	keyPrefix := vars["keyPrefix"]
+
+	adt, err := diygoapi.AuditFromRequest(r)
+	if err != nil {
+		errs.HTTPErrorResponse(w, lgr, err)
+		return
+	}
+
+	var response diygoapi.DeleteResponse
+	response, err = s.AppServicer.RevokeAPIKey(r.Context(), extlID, keyPrefix, adt)
+	if err != nil {
+		errs.HTTPErrorResponse(w, lgr, err)
+		return
+	}
+
+	// Encode response struct to JSON for the response body
+	err = json.NewEncoder(w).Encode(response)
+	if err != nil {
+		errs.HTTPErrorResponse(w, lgr, errs.E(errs.Internal, err))
+		return
+	}
+}
+
+// handleRegister is a HandlerFunc used for a person to register
+// themselves as a User. The person is authenticated with the same
+// headers used for authentication on other routes. The request body,
+// used to create a personal Org, is optional.
+func (s *Server) handleRegister(w http.ResponseWriter, r *http.Request) {
+	lgr := *hlog.FromRequest(r)
+
+	params, err := newAuthenticationParams(defaultRealm, r.Header)
+	if err != nil {
+		errs.HTTPErrorResponse(w, lgr, err)
+		return
+	}
+
+	// Declare request body (rb)
+	rb := new(diygoapi.RegisterUserRequest)
+
+	// Decode JSON HTTP request body into a Decoder type
+	// and unmarshal that into the RegisterUserRequest struct,
+	// an empty request body is allowed
+	err = json.NewDecoder(r.Body).Decode(&rb)
+	defer r.Body.Close()
+	if err != io.EOF {
+		// Call decoderErr to determine if json is malformed
+		// or any other error
+		err = decoderErr(err)
+		if err != nil {
+			errs.HTTPErrorResponse(w, lgr, err)
+			return
+		}
+	}
+
+	var response *diygoapi.RegisterUserResponse
+	response, err = s.RegisterUserService.Register(r.Context(), params, rb)
+	if err != nil {
+		errs.HTTPErrorResponse(w, lgr, err)
+		return
+	}
+
+	// Encode response struct to JSON for the response body
+	err = json.NewEncoder(w).Encode(response)
+	if err != nil {
+		errs.HTTPErrorResponse(w, lgr, errs.E(errs.Internal, err))
+		return
+	}
+}
+
+// handleLoggerRead handles GET requests for the /logger endpoint
+func (s *Server) handleLoggerRead(w http.ResponseWriter, r *http.Request) {
+	lgr := *hlog.FromRequest(r)
+
+	response := s.LoggerService.Read()
+
+	// Encode response struct to JSON for the response body
+	err := json.NewEncoder(w).Encode(response)
+	if err != nil {
+		errs.HTTPErrorResponse(w, lgr, errs.E(errs.Internal, err))
+		return
+	}
+}
+
+// handleLoggerUpdate handles PUT requests for the /logger endpoint
+// and updates the logger globals
+func (s *Server) handleLoggerUpdate(w http.ResponseWriter, r *http.Request) {
+	lgr := *hlog.FromRequest(r)
+
+	// Declare rb as an instance of service.LoggerRequest
+	rb := new(diygoapi.LoggerRequest)
+
+	// Decode JSON HTTP request body into a json.Decoder type
+	// and unmarshal that into rb
+	err := json.NewDecoder(r.Body).Decode(&rb)
+	defer r.Body.Close()
+	// Call DecoderErr to determine if body is nil, json is malformed
+	// or any other error
+	err = decoderErr(err)
+	if err != nil {
+		errs.HTTPErrorResponse(w, lgr, err)
+		return
+	}
+
+	var response *diygoapi.LoggerResponse
+	response, err = s.LoggerService.Update(rb)
+	if err != nil {
+		errs.HTTPErrorResponse(w, lgr, err)
+		return
+	}
+
+	// Encode response struct to JSON for the response body
+	err = json.NewEncoder(w).Encode(response)
+	if err != nil {
+		errs.HTTPErrorResponse(w, lgr, errs.E(errs.Internal, err))
+		return
+	}
+}
+
+// Ping handles GET requests for the /ping endpoint
+func (s *Server) handlePing(w http.ResponseWriter, r *http.Request) {
+	// pull logger from request context
+	lgr := *hlog.FromRequest(r)
+
+	// pull the context from the http request
+	ctx := r.Context()
+
+	response := s.PingService.Ping(ctx, lgr)
+
+	// Encode response struct to JSON for the response body
+	err := json.NewEncoder(w).Encode(response)
+	if err != nil {
+		errs.HTTPErrorResponse(w, lgr, errs.E(errs.Internal, err))
+		return
+	}
+}
+
+// handleGenesis handles POST requests for the /genesis endpoint
+func (s *Server) handleGenesis(w http.ResponseWriter, r *http.Request) {
+	lgr := *hlog.FromRequest(r)
+
+	// Declare rb as an instance of service.LoggerRequest
+	rb := new(diygoapi.GenesisRequest)
+
+	// Decode JSON HTTP request body into a json.Decoder type
+	// and unmarshal that into rb
+	err := json.NewDecoder(r.Body).Decode(&rb)
+	defer r.Body.Close()
+	// Call DecoderErr to determine if body is nil, json is malformed
+	// or any other error
+	err = decoderErr(err)
+	if err != nil {
+		errs.HTTPErrorResponse(w, lgr, err)
+		return
+	}
+
+	var response diygoapi.GenesisResponse
+	response, err = s.GenesisServicer.Arche(r.Context(), rb)
+	if err != nil {
+		errs.HTTPErrorResponse(w, lgr, err)
+		return
+	}
+
+	// Encode response struct to JSON for the response body
+	err = json.NewEncoder(w).Encode(response)
+	if err != nil {
+		errs.HTTPErrorResponse(w, lgr, errs.E(errs.Internal, err))
+		return
+	}
+}
+
+// handleGenesis handles GET requests for the /genesis endpoint
+func (s *Server) handleGenesisRead(w http.ResponseWriter, r *http.Request) {
+	lgr := *hlog.FromRequest(r)
+
+	var (
+		response diygoapi.GenesisResponse
+		err      error
+	)
+	response, err = s.GenesisServicer.ReadConfig()
+	if err != nil {
+		errs.HTTPErrorResponse(w, lgr, err)
+		return
+	}
+
+	// Encode response struct to JSON for the response body
+	err = json.NewEncoder(w).Encode(response)
+	if err != nil {
+		errs.HTTPErrorResponse(w, lgr, errs.E(errs.Internal, err))
+		return
+	}
+}
+
+// handlePermissionCreate handles POST requests for the /permission endpoint
+func (s *Server) handlePermissionCreate(w http.ResponseWriter, r *http.Request) {
+	lgr := *hlog.FromRequest(r)
+
+	var (
+		err error
+		adt diygoapi.Audit
+	)
+	adt, err = diygoapi.AuditFromRequest(r)
+	if err != nil {
+		errs.HTTPErrorResponse(w, lgr, err)
+		return
+	}
+
+	// Declare rb as an instance of service.PermissionRequest
+	rb := new(diygoapi.CreatePermissionRequest)
+
+	// Decode JSON HTTP request body into a json.Decoder type
+	// and unmarshal that into rb
+	err = json.NewDecoder(r.Body).Decode(&rb)
+	defer r.Body.Close()
+	// Call DecoderErr to determine if body is nil, json is malformed
+	// or any other error
+	err = decoderErr(err)
+	if err != nil {
+		errs.HTTPErrorResponse(w, lgr, err)
+		return
+	}
+
+	var response *diygoapi.PermissionResponse
+	response, err = s.PermissionServicer.Create(r.Context(), rb, adt)
+	if err != nil {
+		errs.HTTPErrorResponse(w, lgr, err)
+		return
+	}
+
+	// Encode response struct to JSON for the response body
+	err = json.NewEncoder(w).Encode(response)
+	if err != nil {
+		errs.HTTPErrorResponse(w, lgr, errs.E(errs.Internal, err))
+		return
+	}
+}
+
+// handlePermissionFindAll handles GET requests for the /permission endpoint
+func (s *Server) handlePermissionFindAll(w http.ResponseWriter, r *http.Request) {
+	lgr := *hlog.FromRequest(r)
+
+	response, err := s.PermissionServicer.FindAll(r.Context())
+	if err != nil {
+		errs.HTTPErrorResponse(w, lgr, err)
+		return
+	}
+
+	// Encode response struct to JSON for the response body
+	err = json.NewEncoder(w).Encode(response)
+	if err != nil {
+		errs.HTTPErrorResponse(w, lgr, errs.E(errs.Internal, err))
+		return
+	}
+}
+
+// handlePermissionDelete handles DELETE requests for the /permission endpoint
+func (s *Server) handlePermissionDelete(w http.ResponseWriter, r *http.Request) {
+	lgr := *hlog.FromRequest(r)
+
+	// gorilla mux Vars function returns the route variables for the
+	// current request, if any. ID is the external id given for the resource
+	vars := mux.Vars(r)
+	extlID := vars["extlID"]
+
+	response, err := s.PermissionServicer.Delete(r.Context(), extlID)
+	if err != nil {
+		errs.HTTPErrorResponse(w, lgr, err)
+		return
+	}
+
+	// Encode response struct to JSON for the response body
+	err = json.NewEncoder(w).Encode(response)
+	if err != nil {
+		errs.HTTPErrorResponse(w, lgr, errs.E(errs.Internal, err))
+		return
+	}
+}
+
+// handleRoleCreate is a HandlerFunc used to create a Role
+func (s *Server) handleRoleCreate(w http.ResponseWriter, r *http.Request) {
+	lgr := *hlog.FromRequest(r)
+
+	adt, err := diygoapi.AuditFromRequest(r)
+	if err != nil {
+		errs.HTTPErrorResponse(w, lgr, err)
+		return
+	}
+
+	// Declare request body (rb)
+	rb := new(diygoapi.CreateRoleRequest)
+
+	// Decode JSON HTTP request body into a Decoder type
+	// and unmarshal that into rb
+	err = json.NewDecoder(r.Body).Decode(&rb)
+	defer r.Body.Close()
+	// Call decoderErr to determine if body is nil, json is malformed
+	// or any other error
+	err = decoderErr(err)
+	if err != nil {
+		errs.HTTPErrorResponse(w, lgr, err)
+		return
+	}
+
+	var response *diygoapi.RoleResponse
+	response, err = s.RoleServicer.Create(r.Context(), rb, adt)
+	if err != nil {
+		errs.HTTPErrorResponse(w, lgr, err)
+		return
+	}
+
+	// Encode response struct to JSON for the response body
+	err = json.NewEncoder(w).Encode(response)
+	if err != nil {
+		errs.HTTPErrorResponse(w, lgr, errs.E(errs.Internal, err))
+		return
+	}
+}
+
+// handleRoleFindAll is a HandlerFunc used to find all Roles
+func (s *Server) handleRoleFindAll(w http.ResponseWriter, r *http.Request) {
+	lgr := *hlog.FromRequest(r)
+
+	response, err := s.RoleServicer.FindAll(r.Context())
+	if err != nil {
+		errs.HTTPErrorResponse(w, lgr, err)
+		return
+	}
+
+	// Encode response struct to JSON for the response body
+	err = json.NewEncoder(w).Encode(response)
+	if err != nil {
+		errs.HTTPErrorResponse(w, lgr, errs.E(errs.Internal, err))
+		return
+	}
+}
+
+// handleRoleFindByExtlID is a HandlerFunc used to find a specific Role by External ID
+func (s *Server) handleRoleFindByExtlID(w http.ResponseWriter, r *http.Request) {
+	lgr := *hlog.FromRequest(r)
+
+	// gorilla mux Vars function returns the route variables for the
+	// current request, if any. ID is the external id given for the resource
+	vars := mux.Vars(r)
+	extlID := vars["extlID"]
+
+	response, err := s.RoleServicer.FindByExternalID(r.Context(), extlID)
+	if err != nil {
+		errs.HTTPErrorResponse(w, lgr, err)
+		return
+	}
+
+	// Encode response struct to JSON for the response body
+	err = json.NewEncoder(w).Encode(response)
+	if err != nil {
+		errs.HTTPErrorResponse(w, lgr, errs.E(errs.Internal, err))
+		return
+	}
+}
+
+// handleRoleUpdate is a HandlerFunc used to update a Role
+func (s *Server) handleRoleUpdate(w http.ResponseWriter, r *http.Request) {
+	lgr := *hlog.FromRequest(r)
+
+	adt, err := diygoapi.AuditFromRequest(r)
+	if err != nil {
+		errs.HTTPErrorResponse(w, lgr, err)
+		return
+	}
+
+	// Declare request body (rb)
+	rb := new(diygoapi.UpdateRoleRequest)
+
+	// Decode JSON HTTP request body into a Decoder type
+	// and unmarshal that into rb
+	err = json.NewDecoder(r.Body).Decode(&rb)
+	defer r.Body.Close()
+	// Call decoderErr to determine if body is nil, json is malformed
+	// or any other error
+	err = decoderErr(err)
+	if err != nil {
+		errs.HTTPErrorResponse(w, lgr, err)
+		return
+	}
+
+	// gorilla mux Vars function returns the route variables for the
+	// current request, if any. ID is the external id given for the resource
+	vars := mux.Vars(r)
+	rb.ExternalID = vars["extlID"]
+
+	var response *diygoapi.RoleResponse
+	response, err = s.RoleServicer.Update(r.Context(), rb, adt)
+	if err != nil {
+		errs.HTTPErrorResponse(w, lgr, err)
+		return
+	}
+
+	// Encode response struct to JSON for the response body
+	err = json.NewEncoder(w).Encode(response)
+	if err != nil {
+		errs.HTTPErrorResponse(w, lgr, errs.E(errs.Internal, err))
+		return
+	}
+}
+
+// handleRoleDelete is a HandlerFunc used to delete a Role
+func (s *Server) handleRoleDelete(w http.ResponseWriter, r *http.Request) {
+	lgr := *hlog.FromRequest(r)
+
+	// gorilla mux Vars function returns the route variables for the
+	// current request, if any. ID is the external id given for the resource
+	vars := mux.Vars(r)
+	extlID := vars["extlID"]
+
+	response, err := s.RoleServicer.Delete(r.Context(), extlID)
+	if err != nil {
+		errs.HTTPErrorResponse(w, lgr, err)
+		return
+	}
+
+	// Encode response struct to JSON for the response body
+	err = json.NewEncoder(w).Encode(response)
+	if err != nil {
+		errs.HTTPErrorResponse(w, lgr, errs.E(errs.Internal, err))
+		return
+	}
+}
+
+// handleRolePermissionsAdd is a HandlerFunc used to add Permissions to a Role
+func (s *Server) handleRolePermissionsAdd(w http.ResponseWriter, r *http.Request) {
+	lgr := *hlog.FromRequest(r)
+
+	adt, err := diygoapi.AuditFromRequest(r)
+	if err != nil {
+		errs.HTTPErrorResponse(w, lgr, err)
+		return
+	}
+
+	// Declare request body (rb)
+	rb := new(diygoapi.RolePermissionsRequest)
+
+	// Decode JSON HTTP request body into a Decoder type
+	// and unmarshal that into rb
+	err = json.NewDecoder(r.Body).Decode(&rb)
+	defer r.Body.Close()
+	// Call decoderErr to determine if body is nil, json is malformed
+	// or any other error
+	err = decoderErr(err)
+	if err != nil {
+		errs.HTTPErrorResponse(w, lgr, err)
+		return
+	}
+
+	// gorilla mux Vars function returns the route variables for the
+	// current request, if any. ID is the external id given for the role
+	vars := mux.Vars(r)
+	rb.RoleExternalID = vars["extlID"]
+
+	var response *diygoapi.RoleResponse
+	response, err = s.RoleServicer.AddPermissions(r.Context(), rb, adt)
+	if err != nil {
+		errs.HTTPErrorResponse(w, lgr, err)
+		return
+	}
+
+	// Encode response struct to JSON for the response body
+	err = json.NewEncoder(w).Encode(response)
+	if err != nil {
+		errs.HTTPErrorResponse(w, lgr, errs.E(errs.Internal, err))
+		return
+	}
+}
+
+// handleRolePermissionRemove is a HandlerFunc used to remove a Permission from a Role
+func (s *Server) handleRolePermissionRemove(w http.ResponseWriter, r *http.Request) {
+	lgr := *hlog.FromRequest(r)
+
+	adt, err := diygoapi.AuditFromRequest(r)
+	if err != nil {
+		errs.HTTPErrorResponse(w, lgr, err)
+		return
+	}
+
+	// gorilla mux Vars function returns the route variables for the
+	// current request, if any. extlID is the external id given for the
+	// role, permissionExtlID is the external id of the permission
+	vars := mux.Vars(r)
+	rb := &diygoapi.RolePermissionsRequest{
+		RoleExternalID: vars["extlID"],
+		Permissions:    []*diygoapi.FindPermissionRequest{{ExternalID: vars["permissionExtlID"]}},
+	}
+
+	var response *diygoapi.RoleResponse
+	response, err = s.RoleServicer.RemovePermissions(r.Context(), rb, adt)
+	if err != nil {
+		errs.HTTPErrorResponse(w, lgr, err)
+		return
+	}
+
+	// Encode response struct to JSON for the response body
+	err = json.NewEncoder(w).Encode(response)
+	if err != nil {
+		errs.HTTPErrorResponse(w, lgr, errs.E(errs.Internal, err))
+		return
+	}
+}
+
+// handleRoleParentAdd is a HandlerFunc used to add a parent Role to a Role
+func (s *Server) handleRoleParentAdd(w http.ResponseWriter, r *http.Request) {
+	lgr := *hlog.FromRequest(r)
+
+	adt, err := diygoapi.AuditFromRequest(r)
+	if err != nil {
+		errs.HTTPErrorResponse(w, lgr, err)
+		return
+	}
+
+	// gorilla mux Vars function returns the route variables for the
+	// current request, if any. extlID is the external id given for the
+	// role, roleCd is the code of the parent role
+	vars := mux.Vars(r)
+	rb := &diygoapi.RoleParentRequest{
+		RoleExternalID: vars["extlID"],
+		ParentRoleCode: vars["roleCd"],
+	}
+
+	var response *diygoapi.RoleResponse
+	response, err = s.RoleServicer.AddParent(r.Context(), rb, adt)
+	if err != nil {
+		errs.HTTPErrorResponse(w, lgr, err)
+		return
+	}
+
+	// Encode response struct to JSON for the response body
+	err = json.NewEncoder(w).Encode(response)
+	if err != nil {
+		errs.HTTPErrorResponse(w, lgr, errs.E(errs.Internal, err))
+		return
+	}
+}
+
+// handleRoleParentRemove is a HandlerFunc used to remove a parent Role from a Role
+func (s *Server) handleRoleParentRemove(w http.ResponseWriter, r *http.Request) {
+	lgr := *hlog.FromRequest(r)
+
+	// gorilla mux Vars function returns the route variables for the
+	// current request, if any. extlID is the external id given for the
+	// role, roleCd is the code of the parent role
+	vars := mux.Vars(r)
+	rb := &diygoapi.RoleParentRequest{
+		RoleExternalID: vars["extlID"],
+		ParentRoleCode: vars["roleCd"],
+	}
+
+	response, err := s.RoleServicer.RemoveParent(r.Context(), rb)
+	if err != nil {
+		errs.HTTPErrorResponse(w, lgr, err)
+		return
+	}
+
+	// Encode response struct to JSON for the response body
+	err = json.NewEncoder(w).Encode(response)
+	if err != nil {
+		errs.HTTPErrorResponse(w, lgr, errs.E(errs.Internal, err))
+		return
+	}
+}
+
+// handleMePermissions is a HandlerFunc used to list the effective
+// permissions of the current user
+func (s *Server) handleMePermissions(w http.ResponseWriter, r *http.Request) {
+	lgr := *hlog.FromRequest(r)
+
+	adt, err := diygoapi.AuditFromRequest(r)
+	if err != nil {
+		errs.HTTPErrorResponse(w, lgr, err)
+		return
+	}
+
+	var response []*diygoapi.EffectivePermissionResponse
+	response, err = s.AuthorizationServicer.FindEffectivePermissions(r.Context(), adt)
+	if err != nil {
+		errs.HTTPErrorResponse(w, lgr, err)
+		return
+	}
+
+	// Encode response struct to JSON for the response body
+	err = json.NewEncoder(w).Encode(response)
+	if err != nil {
+		errs.HTTPErrorResponse(w, lgr, errs.E(errs.Internal, err))
+		return
+	}
+}
+
+// handleAuthzCheck is a HandlerFunc used to check whether the current
+// user is authorized for a list of resources and operations
+func (s *Server) handleAuthzCheck(w http.ResponseWriter, r *http.Request) {
+	lgr := *hlog.FromRequest(r)
+
+	adt, err := diygoapi.AuditFromRequest(r)
+	if err != nil {
+		errs.HTTPErrorResponse(w, lgr, err)
+		return
+	}
+
+	// Declare request body (rb)
+	rb := new(diygoapi.AuthorizationCheckRequest)
+
+	// Decode JSON HTTP request body into a Decoder type
+	// and unmarshal that into rb
+	err = json.NewDecoder(r.Body).Decode(&rb)
+	defer r.Body.Close()
+	// Call decoderErr to determine if body is nil, json is malformed
+	// or any other error
+	err = decoderErr(err)
+	if err != nil {
+		errs.HTTPErrorResponse(w, lgr, err)
+		return
+	}
+
+	var response []*diygoapi.AuthorizationCheckResponse
+	response, err = s.AuthorizationServicer.Check(r.Context(), rb, adt)
+	if err != nil {
+		errs.HTTPErrorResponse(w, lgr, err)
+		return
+	}
+
+	// Encode response struct to JSON for the response body
+	err = json.NewEncoder(w).Encode(response)
+	if err != nil {
+		errs.HTTPErrorResponse(w, lgr, errs.E(errs.Internal, err))
+		return
+	}
+}
+
+// handleAuthzDecisionsFindAll is a HandlerFunc used to search the
+// recorded authorization decisions
+func (s *Server) handleAuthzDecisionsFindAll(w http.ResponseWriter, r *http.Request) {
+	lgr := *hlog.FromRequest(r)
+
+	adt, err := diygoapi.AuditFromRequest(r)
+	if err != nil {
+		errs.HTTPErrorResponse(w, lgr, err)
+		return
+	}
+
+	var rb *diygoapi.AuthzDecisionSearchRequest
+	rb, err = newAuthzDecisionSearchRequest(r.URL.Query(), time.Now())
+	if err != nil {
+		errs.HTTPErrorResponse(w, lgr, err)
+		return
+	}
+
+	var response []*diygoapi.AuthzDecisionResponse
+	response, err = s.AuthorizationServicer.FindDecisions(r.Context(), rb, adt)
 	if err != nil {
 		errs.HTTPErrorResponse(w, lgr, err)
 		return
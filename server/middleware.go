@@ -10,6 +10,7 @@ import (
 	"github.com/justinas/alice"
 	"github.com/rs/zerolog/hlog"
 	"golang.org/x/oauth2"
+	"golang.org/x/text/language"
 
 	"github.com/gilcrest/diygoapi"
 	"github.com/gilcrest/diygoapi/errs"
@@ -22,6 +23,19 @@ const (
 	apiKeyHeaderKey string = "X-API-KEY"
 	// Authorization provider header key
 	authProviderHeaderKey string = "X-AUTH-PROVIDER"
+	// Org ID header key, optionally sent to select the org (by
+	// external ID) the user is acting in for the request
+	orgIDHeaderKey string = "X-ORG-ID"
+	// Authentication nonce header key, sent with an OpenID Connect ID
+	// token to be matched against its nonce claim. It is required for
+	// the OIDC provider and ignored for the others.
+	authNonceHeaderKey string = "X-AUTH-NONCE"
+	// Accept-Language header key, optionally sent with the languages
+	// accepted for the response
+	acceptLanguageHeaderKey string = "Accept-Language"
+	// Invitation token header key, optionally sent to accept an
+	// org invitation on behalf of the authenticated user
+	invitationTokenHeaderKey string = "X-INVITATION-TOKEN"
 	// Default Realm used as part of the WWW-Authenticate response
 	// header when returning a 401 Unauthorized response
 	defaultRealm string = "diy"
@@ -118,6 +132,13 @@ func (s *Server) appHandler(h http.Handler) http.Handler {
 // to the request Context. If an app has already been authenticated as part
 // of an upstream middleware and set to the request Context, then the Oauth2
 // Provider's Client ID for the given User is not considered.
+//
+// Finally, authHandler determines the Org the User is acting in:
+//
+// If the X-ORG-ID header is sent with the external ID of an Org other
+// than the App's Org, the User must be a member of that Org. The Org is
+// then set to the request Context and used for authorization and auditing.
+// Otherwise, the User acts in the App's Org.
 func (s *Server) authHandler(h http.Handler) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		lgr := *hlog.FromRequest(r)
@@ -125,29 +146,12 @@ func (s *Server) authHandler(h http.Handler) http.Handler {
 		// retrieve the context from the http.Request
 		ctx := r.Context()
 
-		var (
-			provider diygoapi.Provider
-			err      error
-		)
-		provider, err = parseProviderHeader(defaultRealm, r.Header)
+		params, err := newAuthenticationParams(defaultRealm, r.Header)
 		if err != nil {
 			errs.HTTPErrorResponse(w, lgr, err)
 			return
 		}
 
-		var token *oauth2.Token
-		token, err = parseAuthorizationHeader(defaultRealm, r.Header)
-		if err != nil {
-			errs.HTTPErrorResponse(w, lgr, err)
-			return
-		}
-
-		params := diygoapi.AuthenticationParams{
-			Realm:    defaultRealm,
-			Provider: provider,
-			Token:    token,
-		}
-
 		var auth diygoapi.Auth
 		auth, err = s.AuthenticationServicer.FindAuth(ctx, params)
 		if err != nil {
@@ -157,10 +161,10 @@ func (s *Server) authHandler(h http.Handler) http.Handler {
 
 		ctx = diygoapi.NewContextWithUser(ctx, auth.User)
 
-		_, err = diygoapi.AppFromRequest(r)
+		var a *diygoapi.App
+		a, err = diygoapi.AppFromRequest(r)
 		if err != nil {
 			// no app found in request, lookup app from Auth
-			var a *diygoapi.App
 			a, err = s.AuthenticationServicer.FindAppByProviderClientID(ctx, defaultRealm, auth)
 			if err != nil {
 				errs.HTTPErrorResponse(w, lgr, err)
@@ -170,11 +174,91 @@ func (s *Server) authHandler(h http.Handler) http.Handler {
 			ctx = diygoapi.NewContextWithApp(ctx, a)
 		}
 
+		// selecting an org is optional, if no X-ORG-ID header is
+		// sent, the user acts in the org the app belongs to
+		var orgExtlID string
+		orgExtlID, err = parseOrgHeader(r.Header)
+		if err != nil && !errs.KindIs(errs.NotExist, err) {
+			errs.HTTPErrorResponse(w, lgr, err)
+			return
+		}
+		if orgExtlID != "" && orgExtlID != a.Org.ExternalID.String() {
+			var o *diygoapi.Org
+			o, err = s.AuthenticationServicer.FindUserOrg(ctx, defaultRealm, auth.User, orgExtlID)
+			if err != nil {
+				errs.HTTPErrorResponse(w, lgr, err)
+				return
+			}
+			// get a new context with the selected Org added to it
+			ctx = diygoapi.NewContextWithOrg(ctx, o)
+		}
+
 		// call original, with new context
 		h.ServeHTTP(w, r.WithContext(ctx))
 	})
 }
 
+// newAuthenticationParams parses the authentication provider,
+// authorization, nonce and invitation HTTP headers into
+// diygoapi.AuthenticationParams
+func newAuthenticationParams(realm string, header http.Header) (diygoapi.AuthenticationParams, error) {
+	const op errs.Op = "server/newAuthenticationParams"
+
+	provider, err := parseProviderHeader(realm, header)
+	if err != nil {
+		return diygoapi.AuthenticationParams{}, errs.E(op, err)
+	}
+
+	var token *oauth2.Token
+	token, err = parseAuthorizationHeader(realm, header)
+	if err != nil {
+		return diygoapi.AuthenticationParams{}, errs.E(op, err)
+	}
+
+	token, err = addNonce(realm, header, token)
+	if err != nil {
+		return diygoapi.AuthenticationParams{}, errs.E(op, err)
+	}
+
+	var invitationToken string
+	invitationToken, err = parseInvitationHeader(realm, header)
+	if err != nil {
+		return diygoapi.AuthenticationParams{}, errs.E(op, err)
+	}
+
+	return diygoapi.AuthenticationParams{
+		Realm:           realm,
+		Provider:        provider,
+		Token:           token,
+		InvitationToken: invitationToken,
+	}, nil
+}
+
+// languageHandler middleware is used to negotiate the language of a
+// request given the Accept-Language header and the language
+// preferences of the User set to the request context, if any. The
+// languages are matched against the languages supported by the server
+// and the best match is set to the request context.
+func (s *Server) languageHandler(h http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if s.LanguageMatcher == nil {
+			h.ServeHTTP(w, r)
+			return
+		}
+
+		// the language preferences of the User are optional
+		var prefs []language.Tag
+		if u, err := diygoapi.UserFromRequest(r); err == nil {
+			prefs = u.LanguagePreferences
+		}
+
+		tag := diygoapi.NegotiateLanguage(s.LanguageMatcher, r.Header.Get(acceptLanguageHeaderKey), prefs)
+
+		// call original, with new context
+		h.ServeHTTP(w, r.WithContext(diygoapi.NewContextWithLanguage(r.Context(), tag)))
+	})
+}
+
 // authorizeUserHandler middleware is used authorize a User for a request path and http method
 func (s *Server) authorizeUserHandler(h http.Handler) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
@@ -254,6 +338,33 @@ func parseAppHeader(realm string, header http.Header, key string) (v string, err
 	return v, nil
 }
 
+// parseOrgHeader parses the X-ORG-ID header and returns its value.
+// If the header is not sent, an error of kind errs.NotExist is returned.
+func parseOrgHeader(header http.Header) (string, error) {
+	const op errs.Op = "server/parseOrgHeader"
+
+	// Pull the header value from the Header map given the key
+	headerValue, ok := header[http.CanonicalHeaderKey(orgIDHeaderKey)]
+	if !ok {
+		return "", errs.E(op, errs.NotExist, fmt.Sprintf("no %s header sent", orgIDHeaderKey))
+	}
+
+	// too many values sent - should only be one value
+	if len(headerValue) > 1 {
+		return "", errs.E(op, errs.InvalidRequest, fmt.Sprintf("%s header value > 1", orgIDHeaderKey))
+	}
+
+	// remove all leading/trailing white space
+	v := strings.TrimSpace(headerValue[0])
+
+	// should not be empty
+	if v == "" {
+		return "", errs.E(op, errs.InvalidRequest, fmt.Sprintf("%s header value not found", orgIDHeaderKey))
+	}
+
+	return v, nil
+}
+
 // parseProviderHeader parses the X-AUTH-PROVIDER header and returns its value.
 func parseProviderHeader(realm string, header http.Header) (p diygoapi.Provider, err error) {
 	const op errs.Op = "server/parseProviderHeader"
@@ -331,6 +442,43 @@ func parseAuthorizationHeader(realm string, header http.Header) (*oauth2.Token,
 	return &oauth2.Token{AccessToken: token, TokenType: diygoapi.BearerTokenType}, nil
 }
 
+// addNonce parses the X-AUTH-NONCE header and, if sent, returns a copy
+// of token with the nonce added as an Extra value. The header is only
+// required by the OIDC token exchange, which rejects ID tokens sent
+// without it.
+func addNonce(realm string, header http.Header, token *oauth2.Token) (*oauth2.Token, error) {
+	const op errs.Op = "server/addNonce"
+
+	nonce, err := parseAppHeader(realm, header, authNonceHeaderKey)
+	if err != nil {
+		if errs.KindIs(errs.NotExist, err) {
+			// the token exchange decides if a nonce is required
+			return token, nil
+		}
+		return nil, errs.E(op, err)
+	}
+
+	return token.WithExtra(map[string]interface{}{diygoapi.NonceTokenExtraKey: nonce}), nil
+}
+
+// parseInvitationHeader parses the optional X-INVITATION-TOKEN header
+// and returns its value. If the header is not sent, an empty string
+// is returned.
+func parseInvitationHeader(realm string, header http.Header) (string, error) {
+	const op errs.Op = "server/parseInvitationHeader"
+
+	token, err := parseAppHeader(realm, header, invitationTokenHeaderKey)
+	if err != nil {
+		if errs.KindIs(errs.NotExist, err) {
+			// the invitation token is optional
+			return "", nil
+		}
+		return "", errs.E(op, err)
+	}
+
+	return token, nil
+}
+
 // genesisAuthHandler middleware is used to parse the request authentication
 // provider and authorization Bearer token HTTP headers (X-AUTH-PROVIDER +
 // Authorization respectively) and determine authentication. Authentication
@@ -360,6 +508,12 @@ func (s *Server) genesisAuthHandler(h http.Handler) http.Handler {
 			return
 		}
 
+		token, err = addNonce(defaultRealm, r.Header, token)
+		if err != nil {
+			errs.HTTPErrorResponse(w, lgr, err)
+			return
+		}
+
 		params := &diygoapi.AuthenticationParams{
 			Realm:    defaultRealm,
 			Provider: provider,
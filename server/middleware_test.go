@@ -13,6 +13,7 @@ import (
 	"github.com/google/uuid"
 	"github.com/rs/zerolog"
 	"golang.org/x/oauth2"
+	"golang.org/x/text/language"
 
 	"github.com/gilcrest/diygoapi"
 	"github.com/gilcrest/diygoapi/errs"
@@ -21,16 +22,12 @@ import (
 
 type mockAuthenticationService struct{}
 
-func (mockAuthenticationService) SelfRegister(ctx context.Context, params diygoapi.AuthenticationParams) (diygoapi.Auth, error) {
-	panic("implement me")
-}
-
 func (mockAuthenticationService) FindAuth(ctx context.Context, params diygoapi.AuthenticationParams) (diygoapi.Auth, error) {
-	panic("implement me")
+	return diygoapi.Auth{}, errs.E(errs.Unauthenticated, "mock: no Auth")
 }
 
 func (mockAuthenticationService) FindAppByProviderClientID(ctx context.Context, realm string, auth diygoapi.Auth) (a *diygoapi.App, err error) {
-	panic("implement me")
+	return nil, errs.E(errs.NotExist, "mock: no App for provider client ID")
 }
 
 func (mockAuthenticationService) FindAppByAPIKey(ctx context.Context, realm, appExtlID, key string) (*diygoapi.App, error) {
@@ -44,6 +41,10 @@ func (mockAuthenticationService) FindAppByAPIKey(ctx context.Context, realm, app
 	}, nil
 }
 
+func (mockAuthenticationService) FindUserOrg(ctx context.Context, realm string, u *diygoapi.User, orgExtlID string) (*diygoapi.Org, error) {
+	return nil, errs.E(errs.Unauthorized, "mock: user is not a member of the org")
+}
+
 func TestJSONContentTypeResponseHandler(t *testing.T) {
 
 	s := Server{}
@@ -111,6 +112,72 @@ func TestServer_appHandler(t *testing.T) {
 	})
 }
 
+func TestServer_languageHandler(t *testing.T) {
+	matcher := language.NewMatcher([]language.Tag{language.AmericanEnglish, language.French})
+
+	u := &diygoapi.User{
+		ID:                  uuid.New(),
+		ExternalID:          []byte("so random"),
+		FirstName:           "Otto",
+		LastName:            "Maddox",
+		LanguagePreferences: []language.Tag{language.French},
+	}
+
+	tests := []struct {
+		name           string
+		acceptLanguage string
+		user           *diygoapi.User
+		want           language.Tag
+	}{
+		{"no header, no user", "", nil, language.AmericanEnglish},
+		{"header, no user", "fr-CA", nil, language.French},
+		{"no header, user preferences", "", u, language.French},
+		{"unsupported header, user preferences", "de", u, language.French},
+		{"header over user preferences", "en", u, language.AmericanEnglish},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := qt.New(t)
+
+			req := httptest.NewRequest(http.MethodGet, "/api/v1/ping", nil)
+			if tt.acceptLanguage != "" {
+				req.Header.Set(acceptLanguageHeaderKey, tt.acceptLanguage)
+			}
+			if tt.user != nil {
+				req = req.WithContext(diygoapi.NewContextWithUser(req.Context(), tt.user))
+			}
+
+			var got language.Tag
+			testLanguageHandler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				var err error
+				got, err = diygoapi.LanguageFromContext(r.Context())
+				c.Assert(err, qt.IsNil)
+			})
+
+			s := Server{LanguageMatcher: matcher}
+			s.languageHandler(testLanguageHandler).ServeHTTP(httptest.NewRecorder(), req)
+
+			gotBase, _ := got.Base()
+			wantBase, _ := tt.want.Base()
+			c.Assert(gotBase, qt.Equals, wantBase)
+		})
+	}
+
+	t.Run("no matcher", func(t *testing.T) {
+		c := qt.New(t)
+
+		req := httptest.NewRequest(http.MethodGet, "/api/v1/ping", nil)
+
+		testLanguageHandler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			_, err := diygoapi.LanguageFromContext(r.Context())
+			c.Assert(errs.KindIs(errs.NotExist, err), qt.IsTrue)
+		})
+
+		s := Server{}
+		s.languageHandler(testLanguageHandler).ServeHTTP(httptest.NewRecorder(), req)
+	})
+}
+
 func Test_parseAppHeader(t *testing.T) {
 	t.Run("x-app-id", func(t *testing.T) {
 		c := qt.New(t)
@@ -147,6 +214,41 @@ func Test_parseAppHeader(t *testing.T) {
 	})
 }
 
+func Test_parseOrgHeader(t *testing.T) {
+	t.Run("x-org-id", func(t *testing.T) {
+		c := qt.New(t)
+		hdr := http.Header{}
+		hdr.Add(orgIDHeaderKey, " orgIdHeaderFakeText ")
+
+		orgID, err := parseOrgHeader(hdr)
+		c.Assert(err, qt.IsNil)
+		c.Assert(orgID, qt.Equals, "orgIdHeaderFakeText")
+	})
+	t.Run("no header error", func(t *testing.T) {
+		c := qt.New(t)
+
+		_, err := parseOrgHeader(http.Header{})
+		c.Assert(err, qt.CmpEquals(cmp.Comparer(errs.Match)), errs.E(errs.NotExist, fmt.Sprintf("no %s header sent", orgIDHeaderKey)))
+	})
+	t.Run("too many values error", func(t *testing.T) {
+		c := qt.New(t)
+		hdr := http.Header{}
+		hdr.Add(orgIDHeaderKey, "value1")
+		hdr.Add(orgIDHeaderKey, "value2")
+
+		_, err := parseOrgHeader(hdr)
+		c.Assert(err, qt.CmpEquals(cmp.Comparer(errs.Match)), errs.E(errs.InvalidRequest, fmt.Sprintf("%s header value > 1", orgIDHeaderKey)))
+	})
+	t.Run("empty value error", func(t *testing.T) {
+		c := qt.New(t)
+		hdr := http.Header{}
+		hdr.Add(orgIDHeaderKey, "")
+
+		_, err := parseOrgHeader(hdr)
+		c.Assert(err, qt.CmpEquals(cmp.Comparer(errs.Match)), errs.E(errs.InvalidRequest, fmt.Sprintf("%s header value not found", orgIDHeaderKey)))
+	})
+}
+
 func Test_parseAuthorizationHeader(t *testing.T) {
 	c := qt.New(t)
 
@@ -2,6 +2,11 @@ package server
 
 import (
 	"net/http"
+
+	"github.com/gorilla/mux"
+
+	"github.com/gilcrest/diygoapi"
+	"github.com/gilcrest/diygoapi/errs"
 )
 
 const (
@@ -17,6 +22,10 @@ const (
 	moviesV1PathRoot string = "/v1/movies"
 	// organization V1 Path root
 	orgsV1PathRoot string = "/v1/orgs"
+	// org kinds V1 Path root
+	orgKindsV1PathRoot string = "/v1/orgkinds"
+	// users V1 Path root
+	usersV1PathRoot string = "/v1/users"
 	// app V1 Path root
 	appsV1PathRoot string = "/v1/apps"
 	// register V1 Path root
@@ -29,6 +38,51 @@ const (
 	genesisV1PathRoot string = "/v1/genesis"
 	// permissions V1 Path root
 	permissionV1PathRoot = "/v1/permissions"
+	// app API keys path, relative to an app
+	appKeysPathDir string = "/keys"
+	// keyPrefix is used to represent the lookup prefix of an API key
+	keyPrefixPathDir string = "/{keyPrefix}"
+	// roles V1 Path root
+	rolesV1PathRoot string = "/v1/roles"
+	// role permissions path, relative to a role
+	rolePermissionsPathDir string = "/permissions"
+	// permissionExtlID is used to represent the external id of a
+	// permission when nested under another resource
+	permissionExtlIDPathDir string = "/{permissionExtlID}"
+	// users path, relative to another resource (e.g. an org)
+	usersPathDir string = "/users"
+	// userExtlID is used to represent the external id of a user
+	// when nested under another resource
+	userExtlIDPathDir string = "/{userExtlID}"
+	// roles path, relative to another resource (e.g. an org user)
+	rolesPathDir string = "/roles"
+	// roleCd is used to represent a role code
+	roleCdPathDir string = "/{roleCd}"
+	// role parents path, relative to a role
+	roleParentsPathDir string = "/parents"
+	// expired role grants path, relative to roles
+	expiredRoleGrantsPathDir string = "/grants/expired"
+	// invitations path, relative to an org
+	invitationsPathDir string = "/invitations"
+	// invitationExtlID is used to represent the external id of an
+	// invitation when nested under another resource
+	invitationExtlIDPathDir string = "/{invitationExtlID}"
+	// descendants path, relative to an org
+	descendantsPathDir string = "/descendants"
+	// resend path, relative to an invitation
+	resendPathDir string = "/resend"
+	// restore path, relative to a deleted resource (e.g. an org)
+	restorePathDir string = "/restore"
+	// user suspension path, relative to a user
+	suspensionPathDir string = "/suspension"
+	// user language preferences path, relative to a user
+	languagesPathDir string = "/languages"
+	// current user permissions V1 Path root
+	mePermissionsV1PathRoot string = "/v1/me/permissions"
+	// authorization check V1 Path root
+	authzCheckV1PathRoot string = "/v1/authz/check"
+	// authorization decisions V1 Path root
+	authzDecisionsV1PathRoot string = "/v1/authz/decisions"
 )
 
 // register routes/middleware/handlers to the Server router
@@ -40,6 +94,7 @@ func (s *Server) registerRoutes() {
 		s.loggerChain().
 			Append(s.appHandler).
 			Append(s.authHandler).
+			Append(s.languageHandler).
 			Append(s.authorizeUserHandler).
 			Append(s.jsonContentTypeResponseHandler).
 			ThenFunc(s.handleMovieCreate)).
@@ -52,6 +107,7 @@ func (s *Server) registerRoutes() {
 		s.loggerChain().
 			Append(s.appHandler).
 			Append(s.authHandler).
+			Append(s.languageHandler).
 			Append(s.authorizeUserHandler).
 			Append(s.jsonContentTypeResponseHandler).
 			ThenFunc(s.handleMovieUpdate)).
@@ -63,16 +119,29 @@ func (s *Server) registerRoutes() {
 		s.loggerChain().
 			Append(s.appHandler).
 			Append(s.authHandler).
+			Append(s.languageHandler).
 			Append(s.authorizeUserHandler).
 			Append(s.jsonContentTypeResponseHandler).
 			ThenFunc(s.handleMovieDelete)).
 		Methods(http.MethodDelete)
 
+	// Match only POST requests at /api/v1/movies/{extlID}/restore
+	s.router.Handle(moviesV1PathRoot+extlIDPathDir+restorePathDir,
+		s.loggerChain().
+			Append(s.appHandler).
+			Append(s.authHandler).
+			Append(s.languageHandler).
+			Append(s.authorizeUserHandler).
+			Append(s.jsonContentTypeResponseHandler).
+			ThenFunc(s.handleMovieRestore)).
+		Methods(http.MethodPost)
+
 	// Match only GET requests having an ID at /api/v1/movies/{extlID}
 	s.router.Handle(moviesV1PathRoot+extlIDPathDir,
 		s.loggerChain().
 			Append(s.appHandler).
 			Append(s.authHandler).
+			Append(s.languageHandler).
 			Append(s.authorizeUserHandler).
 			Append(s.jsonContentTypeResponseHandler).
 			ThenFunc(s.handleFindMovieByID)).
@@ -83,6 +152,7 @@ func (s *Server) registerRoutes() {
 		s.loggerChain().
 			Append(s.appHandler).
 			Append(s.authHandler).
+			Append(s.languageHandler).
 			Append(s.authorizeUserHandler).
 			Append(s.jsonContentTypeResponseHandler).
 			ThenFunc(s.handleFindAllMovies)).
@@ -94,6 +164,7 @@ func (s *Server) registerRoutes() {
 		s.loggerChain().
 			Append(s.appHandler).
 			Append(s.authHandler).
+			Append(s.languageHandler).
 			Append(s.authorizeUserHandler).
 			Append(s.jsonContentTypeResponseHandler).
 			ThenFunc(s.handleOrgCreate)).
@@ -106,6 +177,7 @@ func (s *Server) registerRoutes() {
 		s.loggerChain().
 			Append(s.appHandler).
 			Append(s.authHandler).
+			Append(s.languageHandler).
 			Append(s.authorizeUserHandler).
 			Append(s.jsonContentTypeResponseHandler).
 			ThenFunc(s.handleOrgUpdate)).
@@ -117,16 +189,29 @@ func (s *Server) registerRoutes() {
 		s.loggerChain().
 			Append(s.appHandler).
 			Append(s.authHandler).
+			Append(s.languageHandler).
 			Append(s.authorizeUserHandler).
 			Append(s.jsonContentTypeResponseHandler).
 			ThenFunc(s.handleOrgDelete)).
 		Methods(http.MethodDelete)
 
+	// Match only POST requests at /api/v1/orgs/{extlID}/restore
+	s.router.Handle(orgsV1PathRoot+extlIDPathDir+restorePathDir,
+		s.loggerChain().
+			Append(s.appHandler).
+			Append(s.authHandler).
+			Append(s.languageHandler).
+			Append(s.authorizeUserHandler).
+			Append(s.jsonContentTypeResponseHandler).
+			ThenFunc(s.handleOrgRestore)).
+		Methods(http.MethodPost)
+
 	// Match only GET requests at /api/v1/orgs
 	s.router.Handle(orgsV1PathRoot,
 		s.loggerChain().
 			Append(s.appHandler).
 			Append(s.authHandler).
+			Append(s.languageHandler).
 			Append(s.authorizeUserHandler).
 			Append(s.jsonContentTypeResponseHandler).
 			ThenFunc(s.handleOrgFindAll)).
@@ -137,36 +222,385 @@ func (s *Server) registerRoutes() {
 		s.loggerChain().
 			Append(s.appHandler).
 			Append(s.authHandler).
+			Append(s.languageHandler).
 			Append(s.authorizeUserHandler).
 			Append(s.jsonContentTypeResponseHandler).
 			ThenFunc(s.handleOrgFindByExtlID)).
 		Methods(http.MethodGet)
 
+	// Match only GET requests at /api/v1/orgs/{extlID}/descendants
+	s.router.Handle(orgsV1PathRoot+extlIDPathDir+descendantsPathDir,
+		s.loggerChain().
+			Append(s.appHandler).
+			Append(s.authHandler).
+			Append(s.languageHandler).
+			Append(s.authorizeUserHandler).
+			Append(s.jsonContentTypeResponseHandler).
+			ThenFunc(s.handleOrgFindDescendants)).
+		Methods(http.MethodGet)
+
+	// Match only PUT requests at /api/v1/orgs/{extlID}/users/{userExtlID}/roles/{roleCd}
+	s.router.Handle(orgsV1PathRoot+extlIDPathDir+usersPathDir+userExtlIDPathDir+rolesPathDir+roleCdPathDir,
+		s.loggerChain().
+			Append(s.appHandler).
+			Append(s.authHandler).
+			Append(s.languageHandler).
+			Append(s.authorizeUserHandler).
+			Append(s.jsonContentTypeResponseHandler).
+			ThenFunc(s.handleOrgUserRoleAssign)).
+		Methods(http.MethodPut)
+
+	// Match only DELETE requests at /api/v1/orgs/{extlID}/users/{userExtlID}/roles/{roleCd}
+	s.router.Handle(orgsV1PathRoot+extlIDPathDir+usersPathDir+userExtlIDPathDir+rolesPathDir+roleCdPathDir,
+		s.loggerChain().
+			Append(s.appHandler).
+			Append(s.authHandler).
+			Append(s.languageHandler).
+			Append(s.authorizeUserHandler).
+			Append(s.jsonContentTypeResponseHandler).
+			ThenFunc(s.handleOrgUserRoleRevoke)).
+		Methods(http.MethodDelete)
+
+	// Match only GET requests at /api/v1/orgs/{extlID}/roles/{roleCd}/users
+	s.router.Handle(orgsV1PathRoot+extlIDPathDir+rolesPathDir+roleCdPathDir+usersPathDir,
+		s.loggerChain().
+			Append(s.appHandler).
+			Append(s.authHandler).
+			Append(s.languageHandler).
+			Append(s.authorizeUserHandler).
+			Append(s.jsonContentTypeResponseHandler).
+			ThenFunc(s.handleOrgRoleUsersFindAll)).
+		Methods(http.MethodGet)
+
+	// Match only GET requests at /api/v1/orgs/{extlID}/users
+	s.router.Handle(orgsV1PathRoot+extlIDPathDir+usersPathDir,
+		s.loggerChain().
+			Append(s.appHandler).
+			Append(s.authHandler).
+			Append(s.languageHandler).
+			Append(s.authorizeUserHandler).
+			Append(s.jsonContentTypeResponseHandler).
+			ThenFunc(s.handleOrgUsersFindAll)).
+		Methods(http.MethodGet)
+
+	// Match only DELETE requests at /api/v1/orgs/{extlID}/users/{userExtlID}
+	s.router.Handle(orgsV1PathRoot+extlIDPathDir+usersPathDir+userExtlIDPathDir,
+		s.loggerChain().
+			Append(s.appHandler).
+			Append(s.authHandler).
+			Append(s.languageHandler).
+			Append(s.authorizeUserHandler).
+			Append(s.jsonContentTypeResponseHandler).
+			ThenFunc(s.handleOrgUserRemove)).
+		Methods(http.MethodDelete)
+
+	// Match only POST requests at /api/v1/orgs/{extlID}/invitations
+	// with Content-Type header = application/json
+	s.router.Handle(orgsV1PathRoot+extlIDPathDir+invitationsPathDir,
+		s.loggerChain().
+			Append(s.appHandler).
+			Append(s.authHandler).
+			Append(s.languageHandler).
+			Append(s.authorizeUserHandler).
+			Append(s.jsonContentTypeResponseHandler).
+			ThenFunc(s.handleOrgInvitationCreate)).
+		Methods(http.MethodPost).
+		Headers(contentTypeHeaderKey, appJSONContentTypeHeaderVal)
+
+	// Match only GET requests at /api/v1/orgs/{extlID}/invitations
+	s.router.Handle(orgsV1PathRoot+extlIDPathDir+invitationsPathDir,
+		s.loggerChain().
+			Append(s.appHandler).
+			Append(s.authHandler).
+			Append(s.languageHandler).
+			Append(s.authorizeUserHandler).
+			Append(s.jsonContentTypeResponseHandler).
+			ThenFunc(s.handleOrgInvitationsFindAll)).
+		Methods(http.MethodGet)
+
+	// Match only POST requests at /api/v1/orgs/{extlID}/invitations/{invitationExtlID}/resend
+	s.router.Handle(orgsV1PathRoot+extlIDPathDir+invitationsPathDir+invitationExtlIDPathDir+resendPathDir,
+		s.loggerChain().
+			Append(s.appHandler).
+			Append(s.authHandler).
+			Append(s.languageHandler).
+			Append(s.authorizeUserHandler).
+			Append(s.jsonContentTypeResponseHandler).
+			ThenFunc(s.handleOrgInvitationResend)).
+		Methods(http.MethodPost)
+
+	// Match only DELETE requests at /api/v1/orgs/{extlID}/invitations/{invitationExtlID}
+	s.router.Handle(orgsV1PathRoot+extlIDPathDir+invitationsPathDir+invitationExtlIDPathDir,
+		s.loggerChain().
+			Append(s.appHandler).
+			Append(s.authHandler).
+			Append(s.languageHandler).
+			Append(s.authorizeUserHandler).
+			Append(s.jsonContentTypeResponseHandler).
+			ThenFunc(s.handleOrgInvitationRevoke)).
+		Methods(http.MethodDelete)
+
+	// Match only POST requests at /api/v1/orgkinds
+	// with Content-Type header = application/json
+	s.router.Handle(orgKindsV1PathRoot,
+		s.loggerChain().
+			Append(s.appHandler).
+			Append(s.authHandler).
+			Append(s.languageHandler).
+			Append(s.authorizeUserHandler).
+			Append(s.jsonContentTypeResponseHandler).
+			ThenFunc(s.handleOrgKindCreate)).
+		Methods(http.MethodPost).
+		Headers(contentTypeHeaderKey, appJSONContentTypeHeaderVal)
+
+	// Match only GET requests at /api/v1/orgkinds
+	s.router.Handle(orgKindsV1PathRoot,
+		s.loggerChain().
+			Append(s.appHandler).
+			Append(s.authHandler).
+			Append(s.languageHandler).
+			Append(s.authorizeUserHandler).
+			Append(s.jsonContentTypeResponseHandler).
+			ThenFunc(s.handleOrgKindFindAll)).
+		Methods(http.MethodGet)
+
+	// Match only GET requests at /api/v1/orgkinds/{extlID}
+	s.router.Handle(orgKindsV1PathRoot+extlIDPathDir,
+		s.loggerChain().
+			Append(s.appHandler).
+			Append(s.authHandler).
+			Append(s.languageHandler).
+			Append(s.authorizeUserHandler).
+			Append(s.jsonContentTypeResponseHandler).
+			ThenFunc(s.handleOrgKindFindByExtlID)).
+		Methods(http.MethodGet)
+
+	// Match only PUT requests at /api/v1/orgkinds/{extlID}
+	// with Content-Type header = application/json
+	s.router.Handle(orgKindsV1PathRoot+extlIDPathDir,
+		s.loggerChain().
+			Append(s.appHandler).
+			Append(s.authHandler).
+			Append(s.languageHandler).
+			Append(s.authorizeUserHandler).
+			Append(s.jsonContentTypeResponseHandler).
+			ThenFunc(s.handleOrgKindUpdate)).
+		Methods(http.MethodPut).
+		Headers(contentTypeHeaderKey, appJSONContentTypeHeaderVal)
+
+	// Match only DELETE requests at /api/v1/orgkinds/{extlID}
+	s.router.Handle(orgKindsV1PathRoot+extlIDPathDir,
+		s.loggerChain().
+			Append(s.appHandler).
+			Append(s.authHandler).
+			Append(s.languageHandler).
+			Append(s.authorizeUserHandler).
+			Append(s.jsonContentTypeResponseHandler).
+			ThenFunc(s.handleOrgKindDelete)).
+		Methods(http.MethodDelete)
+
+	// Match only GET requests at /api/v1/users
+	s.router.Handle(usersV1PathRoot,
+		s.loggerChain().
+			Append(s.appHandler).
+			Append(s.authHandler).
+			Append(s.languageHandler).
+			Append(s.authorizeUserHandler).
+			Append(s.jsonContentTypeResponseHandler).
+			ThenFunc(s.handleUserFindAll)).
+		Methods(http.MethodGet)
+
+	// Match only GET requests at /api/v1/users/{extlID}
+	s.router.Handle(usersV1PathRoot+extlIDPathDir,
+		s.loggerChain().
+			Append(s.appHandler).
+			Append(s.authHandler).
+			Append(s.languageHandler).
+			Append(s.authorizeUserHandler).
+			Append(s.jsonContentTypeResponseHandler).
+			ThenFunc(s.handleUserFindByExtlID)).
+		Methods(http.MethodGet)
+
+	// Match only PUT requests at /api/v1/users/{extlID}
+	// with Content-Type header = application/json
+	s.router.Handle(usersV1PathRoot+extlIDPathDir,
+		s.loggerChain().
+			Append(s.appHandler).
+			Append(s.authHandler).
+			Append(s.languageHandler).
+			Append(s.authorizeUserHandler).
+			Append(s.jsonContentTypeResponseHandler).
+			ThenFunc(s.handleUserUpdate)).
+		Methods(http.MethodPut).
+		Headers(contentTypeHeaderKey, appJSONContentTypeHeaderVal)
+
+	// Match only PUT requests at /api/v1/users/{extlID}/suspension
+	// with Content-Type header = application/json
+	s.router.Handle(usersV1PathRoot+extlIDPathDir+suspensionPathDir,
+		s.loggerChain().
+			Append(s.appHandler).
+			Append(s.authHandler).
+			Append(s.languageHandler).
+			Append(s.authorizeUserHandler).
+			Append(s.jsonContentTypeResponseHandler).
+			ThenFunc(s.handleUserSuspend)).
+		Methods(http.MethodPut).
+		Headers(contentTypeHeaderKey, appJSONContentTypeHeaderVal)
+
+	// Match only DELETE requests at /api/v1/users/{extlID}/suspension
+	s.router.Handle(usersV1PathRoot+extlIDPathDir+suspensionPathDir,
+		s.loggerChain().
+			Append(s.appHandler).
+			Append(s.authHandler).
+			Append(s.languageHandler).
+			Append(s.authorizeUserHandler).
+			Append(s.jsonContentTypeResponseHandler).
+			ThenFunc(s.handleUserReinstate)).
+		Methods(http.MethodDelete)
+
+	// Match only GET requests at /api/v1/users/{extlID}/languages
+	s.router.Handle(usersV1PathRoot+extlIDPathDir+languagesPathDir,
+		s.loggerChain().
+			Append(s.appHandler).
+			Append(s.authHandler).
+			Append(s.languageHandler).
+			Append(s.authorizeUserHandler).
+			Append(s.jsonContentTypeResponseHandler).
+			ThenFunc(s.handleUserLanguagesFind)).
+		Methods(http.MethodGet)
+
+	// Match only PUT requests at /api/v1/users/{extlID}/languages
+	// with Content-Type header = application/json
+	s.router.Handle(usersV1PathRoot+extlIDPathDir+languagesPathDir,
+		s.loggerChain().
+			Append(s.appHandler).
+			Append(s.authHandler).
+			Append(s.languageHandler).
+			Append(s.authorizeUserHandler).
+			Append(s.jsonContentTypeResponseHandler).
+			ThenFunc(s.handleUserLanguagesUpdate)).
+		Methods(http.MethodPut).
+		Headers(contentTypeHeaderKey, appJSONContentTypeHeaderVal)
+
 	// Match only POST requests at /api/v1/apps
 	// with Content-Type header = application/json
 	s.router.Handle(appsV1PathRoot,
 		s.loggerChain().
 			Append(s.appHandler).
 			Append(s.authHandler).
+			Append(s.languageHandler).
 			Append(s.authorizeUserHandler).
 			Append(s.jsonContentTypeResponseHandler).
 			ThenFunc(s.handleAppCreate)).
 		Methods(http.MethodPost).
 		Headers(contentTypeHeaderKey, appJSONContentTypeHeaderVal)
 
-	// Match only POST requests at /api/v1/register
-	s.router.Handle(registerV1PathRoot,
+	// Match only GET requests at /api/v1/apps
+	s.router.Handle(appsV1PathRoot,
 		s.loggerChain().
 			Append(s.appHandler).
+			Append(s.authHandler).
+			Append(s.languageHandler).
+			Append(s.authorizeUserHandler).
 			Append(s.jsonContentTypeResponseHandler).
-			ThenFunc(s.handleAppCreate)).
+			ThenFunc(s.handleAppFindAll)).
+		Methods(http.MethodGet)
+
+	// Match only GET requests at /api/v1/apps/{extlID}
+	s.router.Handle(appsV1PathRoot+extlIDPathDir,
+		s.loggerChain().
+			Append(s.appHandler).
+			Append(s.authHandler).
+			Append(s.languageHandler).
+			Append(s.authorizeUserHandler).
+			Append(s.jsonContentTypeResponseHandler).
+			ThenFunc(s.handleAppFindByExtlID)).
+		Methods(http.MethodGet)
+
+	// Match only PUT requests at /api/v1/apps/{extlID}
+	// with Content-Type header = application/json
+	s.router.Handle(appsV1PathRoot+extlIDPathDir,
+		s.loggerChain().
+			Append(s.appHandler).
+			Append(s.authHandler).
+			Append(s.languageHandler).
+			Append(s.authorizeUserHandler).
+			Append(s.jsonContentTypeResponseHandler).
+			ThenFunc(s.handleAppUpdate)).
+		Methods(http.MethodPut).
+		Headers(contentTypeHeaderKey, appJSONContentTypeHeaderVal)
+
+	// Match only DELETE requests at /api/v1/apps/{extlID}
+	s.router.Handle(appsV1PathRoot+extlIDPathDir,
+		s.loggerChain().
+			Append(s.appHandler).
+			Append(s.authHandler).
+			Append(s.languageHandler).
+			Append(s.authorizeUserHandler).
+			Append(s.jsonContentTypeResponseHandler).
+			ThenFunc(s.handleAppDelete)).
+		Methods(http.MethodDelete)
+
+	// Match only POST requests at /api/v1/apps/{extlID}/restore
+	s.router.Handle(appsV1PathRoot+extlIDPathDir+restorePathDir,
+		s.loggerChain().
+			Append(s.appHandler).
+			Append(s.authHandler).
+			Append(s.languageHandler).
+			Append(s.authorizeUserHandler).
+			Append(s.jsonContentTypeResponseHandler).
+			ThenFunc(s.handleAppRestore)).
 		Methods(http.MethodPost)
 
+	// Match only POST requests at /api/v1/apps/{extlID}/keys
+	// with Content-Type header = application/json
+	s.router.Handle(appsV1PathRoot+extlIDPathDir+appKeysPathDir,
+		s.loggerChain().
+			Append(s.appHandler).
+			Append(s.authHandler).
+			Append(s.languageHandler).
+			Append(s.authorizeUserHandler).
+			Append(s.jsonContentTypeResponseHandler).
+			ThenFunc(s.handleAPIKeyCreate)).
+		Methods(http.MethodPost).
+		Headers(contentTypeHeaderKey, appJSONContentTypeHeaderVal)
+
+	// Match only GET requests at /api/v1/apps/{extlID}/keys
+	s.router.Handle(appsV1PathRoot+extlIDPathDir+appKeysPathDir,
+		s.loggerChain().
+			Append(s.appHandler).
+			Append(s.authHandler).
+			Append(s.languageHandler).
+			Append(s.authorizeUserHandler).
+			Append(s.jsonContentTypeResponseHandler).
+			ThenFunc(s.handleAPIKeyFindAll)).
+		Methods(http.MethodGet)
+
+	// Match only DELETE requests at /api/v1/apps/{extlID}/keys/{keyPrefix}
+	s.router.Handle(appsV1PathRoot+extlIDPathDir+appKeysPathDir+keyPrefixPathDir,
+		s.loggerChain().
+			Append(s.appHandler).
+			Append(s.authHandler).
+			Append(s.languageHandler).
+			Append(s.authorizeUserHandler).
+			Append(s.jsonContentTypeResponseHandler).
+			ThenFunc(s.handleAPIKeyRevoke)).
+		Methods(http.MethodDelete)
+
+	// Match only POST requests at /api/v1/register
+	s.skipAuthorization(s.router.Handle(registerV1PathRoot,
+		s.loggerChain().
+			Append(s.appHandler).
+			Append(s.jsonContentTypeResponseHandler).
+			ThenFunc(s.handleRegister)).
+		Methods(http.MethodPost))
+
 	// Match only GET requests /api/v1/logger
 	s.router.Handle(loggerV1PathRoot,
 		s.loggerChain().
 			Append(s.appHandler).
 			Append(s.authHandler).
+			Append(s.languageHandler).
 			Append(s.authorizeUserHandler).
 			Append(s.jsonContentTypeResponseHandler).
 			ThenFunc(s.handleLoggerRead)).
@@ -177,6 +611,7 @@ func (s *Server) registerRoutes() {
 		s.loggerChain().
 			Append(s.appHandler).
 			Append(s.authHandler).
+			Append(s.languageHandler).
 			Append(s.authorizeUserHandler).
 			Append(s.jsonContentTypeResponseHandler).
 			ThenFunc(s.handleLoggerUpdate)).
@@ -188,51 +623,263 @@ func (s *Server) registerRoutes() {
 		s.loggerChain().
 			Append(s.appHandler).
 			Append(s.authHandler).
+			Append(s.languageHandler).
 			Append(s.authorizeUserHandler).
 			Append(s.jsonContentTypeResponseHandler).
 			ThenFunc(s.handlePing)).
 		Methods(http.MethodGet)
 
 	// Match only POST requests at /api/v1/permissions
-	s.router.Handle(permissionV1PathRoot,
+	s.skipAuthorization(s.router.Handle(permissionV1PathRoot,
 		s.loggerChain().
 			Append(s.appHandler).
 			Append(s.authHandler).
+			Append(s.languageHandler).
 			Append(s.jsonContentTypeResponseHandler).
 			ThenFunc(s.handlePermissionCreate)).
 		Methods(http.MethodPost).
-		Headers(contentTypeHeaderKey, appJSONContentTypeHeaderVal)
+		Headers(contentTypeHeaderKey, appJSONContentTypeHeaderVal))
 
 	// Match only GET requests at /api/v1/permissions
-	s.router.Handle(permissionV1PathRoot,
+	s.skipAuthorization(s.router.Handle(permissionV1PathRoot,
 		s.loggerChain().
 			Append(s.appHandler).
 			Append(s.authHandler).
+			Append(s.languageHandler).
 			Append(s.jsonContentTypeResponseHandler).
 			ThenFunc(s.handlePermissionFindAll)).
-		Methods(http.MethodGet)
+		Methods(http.MethodGet))
 
 	// Match only DELETE requests at /api/v1/permissions/{extlID}
-	s.router.Handle(permissionV1PathRoot+extlIDPathDir,
+	s.skipAuthorization(s.router.Handle(permissionV1PathRoot+extlIDPathDir,
 		s.loggerChain().
 			Append(s.appHandler).
 			Append(s.authHandler).
+			Append(s.languageHandler).
 			Append(s.jsonContentTypeResponseHandler).
 			ThenFunc(s.handlePermissionDelete)).
+		Methods(http.MethodDelete))
+
+	// Match only POST requests at /api/v1/roles
+	// with Content-Type header = application/json
+	s.router.Handle(rolesV1PathRoot,
+		s.loggerChain().
+			Append(s.appHandler).
+			Append(s.authHandler).
+			Append(s.languageHandler).
+			Append(s.authorizeUserHandler).
+			Append(s.jsonContentTypeResponseHandler).
+			ThenFunc(s.handleRoleCreate)).
+		Methods(http.MethodPost).
+		Headers(contentTypeHeaderKey, appJSONContentTypeHeaderVal)
+
+	// Match only GET requests at /api/v1/roles
+	s.router.Handle(rolesV1PathRoot,
+		s.loggerChain().
+			Append(s.appHandler).
+			Append(s.authHandler).
+			Append(s.languageHandler).
+			Append(s.authorizeUserHandler).
+			Append(s.jsonContentTypeResponseHandler).
+			ThenFunc(s.handleRoleFindAll)).
+		Methods(http.MethodGet)
+
+	// Match only GET requests at /api/v1/roles/{extlID}
+	s.router.Handle(rolesV1PathRoot+extlIDPathDir,
+		s.loggerChain().
+			Append(s.appHandler).
+			Append(s.authHandler).
+			Append(s.languageHandler).
+			Append(s.authorizeUserHandler).
+			Append(s.jsonContentTypeResponseHandler).
+			ThenFunc(s.handleRoleFindByExtlID)).
+		Methods(http.MethodGet)
+
+	// Match only PUT requests at /api/v1/roles/{extlID}
+	// with Content-Type header = application/json
+	s.router.Handle(rolesV1PathRoot+extlIDPathDir,
+		s.loggerChain().
+			Append(s.appHandler).
+			Append(s.authHandler).
+			Append(s.languageHandler).
+			Append(s.authorizeUserHandler).
+			Append(s.jsonContentTypeResponseHandler).
+			ThenFunc(s.handleRoleUpdate)).
+		Methods(http.MethodPut).
+		Headers(contentTypeHeaderKey, appJSONContentTypeHeaderVal)
+
+	// Match only DELETE requests at /api/v1/roles/{extlID}
+	s.router.Handle(rolesV1PathRoot+extlIDPathDir,
+		s.loggerChain().
+			Append(s.appHandler).
+			Append(s.authHandler).
+			Append(s.languageHandler).
+			Append(s.authorizeUserHandler).
+			Append(s.jsonContentTypeResponseHandler).
+			ThenFunc(s.handleRoleDelete)).
+		Methods(http.MethodDelete)
+
+	// Match only POST requests at /api/v1/roles/{extlID}/permissions
+	// with Content-Type header = application/json
+	s.router.Handle(rolesV1PathRoot+extlIDPathDir+rolePermissionsPathDir,
+		s.loggerChain().
+			Append(s.appHandler).
+			Append(s.authHandler).
+			Append(s.languageHandler).
+			Append(s.authorizeUserHandler).
+			Append(s.jsonContentTypeResponseHandler).
+			ThenFunc(s.handleRolePermissionsAdd)).
+		Methods(http.MethodPost).
+		Headers(contentTypeHeaderKey, appJSONContentTypeHeaderVal)
+
+	// Match only DELETE requests at /api/v1/roles/{extlID}/permissions/{permissionExtlID}
+	s.router.Handle(rolesV1PathRoot+extlIDPathDir+rolePermissionsPathDir+permissionExtlIDPathDir,
+		s.loggerChain().
+			Append(s.appHandler).
+			Append(s.authHandler).
+			Append(s.languageHandler).
+			Append(s.authorizeUserHandler).
+			Append(s.jsonContentTypeResponseHandler).
+			ThenFunc(s.handleRolePermissionRemove)).
+		Methods(http.MethodDelete)
+
+	// Match only PUT requests at /api/v1/roles/{extlID}/parents/{roleCd}
+	s.router.Handle(rolesV1PathRoot+extlIDPathDir+roleParentsPathDir+roleCdPathDir,
+		s.loggerChain().
+			Append(s.appHandler).
+			Append(s.authHandler).
+			Append(s.languageHandler).
+			Append(s.authorizeUserHandler).
+			Append(s.jsonContentTypeResponseHandler).
+			ThenFunc(s.handleRoleParentAdd)).
+		Methods(http.MethodPut)
+
+	// Match only DELETE requests at /api/v1/roles/{extlID}/parents/{roleCd}
+	s.router.Handle(rolesV1PathRoot+extlIDPathDir+roleParentsPathDir+roleCdPathDir,
+		s.loggerChain().
+			Append(s.appHandler).
+			Append(s.authHandler).
+			Append(s.languageHandler).
+			Append(s.authorizeUserHandler).
+			Append(s.jsonContentTypeResponseHandler).
+			ThenFunc(s.handleRoleParentRemove)).
 		Methods(http.MethodDelete)
 
+	// Match only GET requests at /api/v1/roles/grants/expired
+	s.router.Handle(rolesV1PathRoot+expiredRoleGrantsPathDir,
+		s.loggerChain().
+			Append(s.appHandler).
+			Append(s.authHandler).
+			Append(s.languageHandler).
+			Append(s.authorizeUserHandler).
+			Append(s.jsonContentTypeResponseHandler).
+			ThenFunc(s.handleUserRolesExpiredFindAll)).
+		Methods(http.MethodGet)
+
+	// Match only DELETE requests at /api/v1/roles/grants/expired
+	s.router.Handle(rolesV1PathRoot+expiredRoleGrantsPathDir,
+		s.loggerChain().
+			Append(s.appHandler).
+			Append(s.authHandler).
+			Append(s.languageHandler).
+			Append(s.authorizeUserHandler).
+			Append(s.jsonContentTypeResponseHandler).
+			ThenFunc(s.handleUserRolesExpiredPurge)).
+		Methods(http.MethodDelete)
+
+	// Match only GET requests at /api/v1/me/permissions
+	// Any authenticated user may see their own permissions, so
+	// authorizeUserHandler is not part of the chain
+	s.skipAuthorization(s.router.Handle(mePermissionsV1PathRoot,
+		s.loggerChain().
+			Append(s.appHandler).
+			Append(s.authHandler).
+			Append(s.languageHandler).
+			Append(s.jsonContentTypeResponseHandler).
+			ThenFunc(s.handleMePermissions)).
+		Methods(http.MethodGet))
+
+	// Match only POST requests at /api/v1/authz/check
+	// with Content-Type header = application/json
+	// Any authenticated user may check their own access, so
+	// authorizeUserHandler is not part of the chain
+	s.skipAuthorization(s.router.Handle(authzCheckV1PathRoot,
+		s.loggerChain().
+			Append(s.appHandler).
+			Append(s.authHandler).
+			Append(s.languageHandler).
+			Append(s.jsonContentTypeResponseHandler).
+			ThenFunc(s.handleAuthzCheck)).
+		Methods(http.MethodPost).
+		Headers(contentTypeHeaderKey, appJSONContentTypeHeaderVal))
+
+	// Match only GET requests at /api/v1/authz/decisions
+	s.router.Handle(authzDecisionsV1PathRoot,
+		s.loggerChain().
+			Append(s.appHandler).
+			Append(s.authHandler).
+			Append(s.languageHandler).
+			Append(s.authorizeUserHandler).
+			Append(s.jsonContentTypeResponseHandler).
+			ThenFunc(s.handleAuthzDecisionsFindAll)).
+		Methods(http.MethodGet)
+
 	// Match only POST requests at /api/v1/genesis
-	s.router.Handle(genesisV1PathRoot,
+	s.skipAuthorization(s.router.Handle(genesisV1PathRoot,
 		s.loggerChain().
 			Append(s.genesisAuthHandler).
 			Append(s.jsonContentTypeResponseHandler).
 			ThenFunc(s.handleGenesis)).
-		Methods(http.MethodPost)
+		Methods(http.MethodPost))
 
 	// Match only GET requests at /api/v1/genesis
-	s.router.Handle(genesisV1PathRoot,
+	s.skipAuthorization(s.router.Handle(genesisV1PathRoot,
 		s.loggerChain().
 			Append(s.jsonContentTypeResponseHandler).
 			ThenFunc(s.handleGenesisRead)).
-		Methods(http.MethodGet)
+		Methods(http.MethodGet))
+}
+
+// skipAuthorization records that the route does not use the
+// authorizeUserHandler middleware, and thus needs no Permission
+func (s *Server) skipAuthorization(r *mux.Route) {
+	if s.skipAuthz == nil {
+		s.skipAuthz = make(map[*mux.Route]bool)
+	}
+	s.skipAuthz[r] = true
+}
+
+// Routes walks the Server router and returns a Route for each
+// registered path template and method, in order of registration.
+func (s *Server) Routes() ([]diygoapi.Route, error) {
+	const op errs.Op = "server/Server.Routes"
+
+	var routes []diygoapi.Route
+	err := s.router.Walk(func(route *mux.Route, router *mux.Router, ancestors []*mux.Route) error {
+		pathTemplate, err := route.GetPathTemplate()
+		if err != nil {
+			return err
+		}
+
+		var methods []string
+		methods, err = route.GetMethods()
+		if err != nil {
+			return err
+		}
+
+		for _, method := range methods {
+			routes = append(routes, diygoapi.Route{
+				Resource:              pathTemplate,
+				Operation:             method,
+				RequiresAuthorization: !s.skipAuthz[route],
+			})
+		}
+
+		return nil
+	})
+	if err != nil {
+		return nil, errs.E(op, errs.Internal, err)
+	}
+
+	return routes, nil
 }
@@ -1,11 +1,23 @@
 package server
 
 import (
+	"context"
+	"encoding/json"
 	"net/http"
+	"net/http/httptest"
+	"os"
+	"regexp"
 	"testing"
 
 	qt "github.com/frankban/quicktest"
+	"github.com/google/uuid"
 	"github.com/gorilla/mux"
+	"github.com/rs/zerolog"
+
+	"github.com/gilcrest/diygoapi"
+	"github.com/gilcrest/diygoapi/errs"
+	"github.com/gilcrest/diygoapi/logger"
+	"github.com/gilcrest/diygoapi/secure"
 )
 
 func TestNewMuxRouter(t *testing.T) {
@@ -34,14 +46,46 @@ func TestNewMuxRouter(t *testing.T) {
 			{PathTemplate: pathPrefix + moviesV1PathRoot, HTTPMethods: []string{http.MethodPost}},
 			{PathTemplate: pathPrefix + moviesV1PathRoot + extlIDPathDir, HTTPMethods: []string{http.MethodPut}},
 			{PathTemplate: pathPrefix + moviesV1PathRoot + extlIDPathDir, HTTPMethods: []string{http.MethodDelete}},
+			{PathTemplate: pathPrefix + moviesV1PathRoot + extlIDPathDir + restorePathDir, HTTPMethods: []string{http.MethodPost}},
 			{PathTemplate: pathPrefix + moviesV1PathRoot + extlIDPathDir, HTTPMethods: []string{http.MethodGet}},
 			{PathTemplate: pathPrefix + moviesV1PathRoot, HTTPMethods: []string{http.MethodGet}},
 			{PathTemplate: pathPrefix + orgsV1PathRoot, HTTPMethods: []string{http.MethodPost}},
 			{PathTemplate: pathPrefix + orgsV1PathRoot + extlIDPathDir, HTTPMethods: []string{http.MethodPut}},
 			{PathTemplate: pathPrefix + orgsV1PathRoot + extlIDPathDir, HTTPMethods: []string{http.MethodDelete}},
+			{PathTemplate: pathPrefix + orgsV1PathRoot + extlIDPathDir + restorePathDir, HTTPMethods: []string{http.MethodPost}},
 			{PathTemplate: pathPrefix + orgsV1PathRoot, HTTPMethods: []string{http.MethodGet}},
 			{PathTemplate: pathPrefix + orgsV1PathRoot + extlIDPathDir, HTTPMethods: []string{http.MethodGet}},
+			{PathTemplate: pathPrefix + orgsV1PathRoot + extlIDPathDir + descendantsPathDir, HTTPMethods: []string{http.MethodGet}},
+			{PathTemplate: pathPrefix + orgsV1PathRoot + extlIDPathDir + usersPathDir + userExtlIDPathDir + rolesPathDir + roleCdPathDir, HTTPMethods: []string{http.MethodPut}},
+			{PathTemplate: pathPrefix + orgsV1PathRoot + extlIDPathDir + usersPathDir + userExtlIDPathDir + rolesPathDir + roleCdPathDir, HTTPMethods: []string{http.MethodDelete}},
+			{PathTemplate: pathPrefix + orgsV1PathRoot + extlIDPathDir + rolesPathDir + roleCdPathDir + usersPathDir, HTTPMethods: []string{http.MethodGet}},
+			{PathTemplate: pathPrefix + orgsV1PathRoot + extlIDPathDir + usersPathDir, HTTPMethods: []string{http.MethodGet}},
+			{PathTemplate: pathPrefix + orgsV1PathRoot + extlIDPathDir + usersPathDir + userExtlIDPathDir, HTTPMethods: []string{http.MethodDelete}},
+			{PathTemplate: pathPrefix + orgsV1PathRoot + extlIDPathDir + invitationsPathDir, HTTPMethods: []string{http.MethodPost}},
+			{PathTemplate: pathPrefix + orgsV1PathRoot + extlIDPathDir + invitationsPathDir, HTTPMethods: []string{http.MethodGet}},
+			{PathTemplate: pathPrefix + orgsV1PathRoot + extlIDPathDir + invitationsPathDir + invitationExtlIDPathDir + resendPathDir, HTTPMethods: []string{http.MethodPost}},
+			{PathTemplate: pathPrefix + orgsV1PathRoot + extlIDPathDir + invitationsPathDir + invitationExtlIDPathDir, HTTPMethods: []string{http.MethodDelete}},
+			{PathTemplate: pathPrefix + orgKindsV1PathRoot, HTTPMethods: []string{http.MethodPost}},
+			{PathTemplate: pathPrefix + orgKindsV1PathRoot, HTTPMethods: []string{http.MethodGet}},
+			{PathTemplate: pathPrefix + orgKindsV1PathRoot + extlIDPathDir, HTTPMethods: []string{http.MethodGet}},
+			{PathTemplate: pathPrefix + orgKindsV1PathRoot + extlIDPathDir, HTTPMethods: []string{http.MethodPut}},
+			{PathTemplate: pathPrefix + orgKindsV1PathRoot + extlIDPathDir, HTTPMethods: []string{http.MethodDelete}},
+			{PathTemplate: pathPrefix + usersV1PathRoot, HTTPMethods: []string{http.MethodGet}},
+			{PathTemplate: pathPrefix + usersV1PathRoot + extlIDPathDir, HTTPMethods: []string{http.MethodGet}},
+			{PathTemplate: pathPrefix + usersV1PathRoot + extlIDPathDir, HTTPMethods: []string{http.MethodPut}},
+			{PathTemplate: pathPrefix + usersV1PathRoot + extlIDPathDir + suspensionPathDir, HTTPMethods: []string{http.MethodPut}},
+			{PathTemplate: pathPrefix + usersV1PathRoot + extlIDPathDir + suspensionPathDir, HTTPMethods: []string{http.MethodDelete}},
+			{PathTemplate: pathPrefix + usersV1PathRoot + extlIDPathDir + languagesPathDir, HTTPMethods: []string{http.MethodGet}},
+			{PathTemplate: pathPrefix + usersV1PathRoot + extlIDPathDir + languagesPathDir, HTTPMethods: []string{http.MethodPut}},
 			{PathTemplate: pathPrefix + appsV1PathRoot, HTTPMethods: []string{http.MethodPost}},
+			{PathTemplate: pathPrefix + appsV1PathRoot, HTTPMethods: []string{http.MethodGet}},
+			{PathTemplate: pathPrefix + appsV1PathRoot + extlIDPathDir, HTTPMethods: []string{http.MethodGet}},
+			{PathTemplate: pathPrefix + appsV1PathRoot + extlIDPathDir, HTTPMethods: []string{http.MethodPut}},
+			{PathTemplate: pathPrefix + appsV1PathRoot + extlIDPathDir, HTTPMethods: []string{http.MethodDelete}},
+			{PathTemplate: pathPrefix + appsV1PathRoot + extlIDPathDir + restorePathDir, HTTPMethods: []string{http.MethodPost}},
+			{PathTemplate: pathPrefix + appsV1PathRoot + extlIDPathDir + appKeysPathDir, HTTPMethods: []string{http.MethodPost}},
+			{PathTemplate: pathPrefix + appsV1PathRoot + extlIDPathDir + appKeysPathDir, HTTPMethods: []string{http.MethodGet}},
+			{PathTemplate: pathPrefix + appsV1PathRoot + extlIDPathDir + appKeysPathDir + keyPrefixPathDir, HTTPMethods: []string{http.MethodDelete}},
 			{PathTemplate: pathPrefix + registerV1PathRoot, HTTPMethods: []string{http.MethodPost}},
 			{PathTemplate: pathPrefix + loggerV1PathRoot, HTTPMethods: []string{http.MethodGet}},
 			{PathTemplate: pathPrefix + loggerV1PathRoot, HTTPMethods: []string{http.MethodPut}},
@@ -49,6 +93,20 @@ func TestNewMuxRouter(t *testing.T) {
 			{PathTemplate: pathPrefix + permissionV1PathRoot, HTTPMethods: []string{http.MethodPost}},
 			{PathTemplate: pathPrefix + permissionV1PathRoot, HTTPMethods: []string{http.MethodGet}},
 			{PathTemplate: pathPrefix + permissionV1PathRoot + extlIDPathDir, HTTPMethods: []string{http.MethodDelete}},
+			{PathTemplate: pathPrefix + rolesV1PathRoot, HTTPMethods: []string{http.MethodPost}},
+			{PathTemplate: pathPrefix + rolesV1PathRoot, HTTPMethods: []string{http.MethodGet}},
+			{PathTemplate: pathPrefix + rolesV1PathRoot + extlIDPathDir, HTTPMethods: []string{http.MethodGet}},
+			{PathTemplate: pathPrefix + rolesV1PathRoot + extlIDPathDir, HTTPMethods: []string{http.MethodPut}},
+			{PathTemplate: pathPrefix + rolesV1PathRoot + extlIDPathDir, HTTPMethods: []string{http.MethodDelete}},
+			{PathTemplate: pathPrefix + rolesV1PathRoot + extlIDPathDir + rolePermissionsPathDir, HTTPMethods: []string{http.MethodPost}},
+			{PathTemplate: pathPrefix + rolesV1PathRoot + extlIDPathDir + rolePermissionsPathDir + permissionExtlIDPathDir, HTTPMethods: []string{http.MethodDelete}},
+			{PathTemplate: pathPrefix + rolesV1PathRoot + extlIDPathDir + roleParentsPathDir + roleCdPathDir, HTTPMethods: []string{http.MethodPut}},
+			{PathTemplate: pathPrefix + rolesV1PathRoot + extlIDPathDir + roleParentsPathDir + roleCdPathDir, HTTPMethods: []string{http.MethodDelete}},
+			{PathTemplate: pathPrefix + rolesV1PathRoot + expiredRoleGrantsPathDir, HTTPMethods: []string{http.MethodGet}},
+			{PathTemplate: pathPrefix + rolesV1PathRoot + expiredRoleGrantsPathDir, HTTPMethods: []string{http.MethodDelete}},
+			{PathTemplate: pathPrefix + mePermissionsV1PathRoot, HTTPMethods: []string{http.MethodGet}},
+			{PathTemplate: pathPrefix + authzCheckV1PathRoot, HTTPMethods: []string{http.MethodPost}},
+			{PathTemplate: pathPrefix + authzDecisionsV1PathRoot, HTTPMethods: []string{http.MethodGet}},
 			{PathTemplate: pathPrefix + genesisV1PathRoot, HTTPMethods: []string{http.MethodPost}},
 			{PathTemplate: pathPrefix + genesisV1PathRoot, HTTPMethods: []string{http.MethodGet}},
 		}
@@ -81,3 +139,110 @@ func TestNewMuxRouter(t *testing.T) {
 
 	})
 }
+
+func TestServer_Routes(t *testing.T) {
+	c := qt.New(t)
+
+	s := Server{
+		router: NewMuxRouter(),
+	}
+
+	s.registerRoutes()
+
+	routes, err := s.Routes()
+	c.Assert(err, qt.IsNil)
+
+	c.Assert(routes, qt.Contains, diygoapi.Route{Resource: pathPrefix + moviesV1PathRoot, Operation: http.MethodGet, RequiresAuthorization: true})
+	c.Assert(routes, qt.Contains, diygoapi.Route{Resource: pathPrefix + permissionV1PathRoot, Operation: http.MethodGet, RequiresAuthorization: false})
+	c.Assert(routes, qt.Contains, diygoapi.Route{Resource: pathPrefix + genesisV1PathRoot, Operation: http.MethodPost, RequiresAuthorization: false})
+
+	// the permissions created during Genesis should cover every
+	// route and every one of them should match a route
+	b, err := os.ReadFile("../config/genesis/request.json")
+	c.Assert(err, qt.IsNil)
+
+	var gr diygoapi.GenesisRequest
+	err = json.Unmarshal(b, &gr)
+	c.Assert(err, qt.IsNil)
+
+	var permissions []diygoapi.Permission
+	for _, p := range gr.CreatePermissionRequests {
+		permissions = append(permissions, diygoapi.Permission{Resource: p.Resource, Operation: p.Operation})
+	}
+
+	missing, orphaned := diygoapi.ReconcilePermissions(routes, permissions)
+	c.Assert(missing, qt.IsNil)
+	c.Assert(orphaned, qt.IsNil)
+}
+
+// routeAuthenticationService authenticates every request as the same User
+type routeAuthenticationService struct {
+	mockAuthenticationService
+}
+
+func (routeAuthenticationService) FindAuth(ctx context.Context, params diygoapi.AuthenticationParams) (diygoapi.Auth, error) {
+	return diygoapi.Auth{User: &diygoapi.User{
+		ID:         uuid.New(),
+		ExternalID: secure.NewID(),
+		FirstName:  "Otto",
+		LastName:   "Maddox",
+	}}, nil
+}
+
+// recordingAuthorizer records whether Authorize was called and denies
+// every request. Its remaining methods are left unimplemented.
+type recordingAuthorizer struct {
+	diygoapi.AuthorizationServicer
+	called bool
+}
+
+func (a *recordingAuthorizer) Authorize(r *http.Request, lgr zerolog.Logger, adt diygoapi.Audit) error {
+	a.called = true
+	return errs.E(errs.Unauthorized, "denied by test")
+}
+
+// TestServer_skipAuthorization asserts the routes marked with
+// skipAuthorization are exactly the routes whose handler chain does
+// not call the authorizeUserHandler middleware.
+func TestServer_skipAuthorization(t *testing.T) {
+	c := qt.New(t)
+
+	s := New(NewMuxRouter(), NewDriver(), logger.New(os.Stdout, zerolog.Disabled, false))
+	s.AuthenticationServicer = routeAuthenticationService{}
+
+	pathVar := regexp.MustCompile(`{[^}]+}`)
+
+	err := s.router.Walk(func(route *mux.Route, router *mux.Router, ancestors []*mux.Route) error {
+		pathTemplate, err := route.GetPathTemplate()
+		c.Assert(err, qt.IsNil)
+
+		methods, err := route.GetMethods()
+		c.Assert(err, qt.IsNil)
+
+		authz := &recordingAuthorizer{}
+		s.AuthorizationServicer = authz
+
+		req := httptest.NewRequest(methods[0], pathVar.ReplaceAllString(pathTemplate, "x"), nil)
+		req.Header.Add(contentTypeHeaderKey, appJSONContentTypeHeaderVal)
+		req.Header.Add(appIDHeaderKey, "test_app_extl_id")
+		req.Header.Add(apiKeyHeaderKey, "test_app_api_key")
+		req.Header.Add(authProviderHeaderKey, diygoapi.Google.String())
+		req.Header.Add("Authorization", "Bearer foobarbbq")
+
+		var match mux.RouteMatch
+		c.Assert(s.router.Match(req, &match), qt.IsTrue)
+		c.Assert(match.Route, qt.Equals, route, qt.Commentf("%s %s", methods[0], pathTemplate))
+
+		// routes which skip authorization go on to call services
+		// this test does not provide, recover from their panic
+		func() {
+			defer func() { _ = recover() }()
+			s.router.ServeHTTP(httptest.NewRecorder(), req)
+		}()
+
+		c.Assert(authz.called, qt.Equals, !s.skipAuthz[route], qt.Commentf("%s %s", methods[0], pathTemplate))
+
+		return nil
+	})
+	c.Assert(err, qt.IsNil)
+}
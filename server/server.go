@@ -32,12 +32,16 @@ package server
 
 import (
 	"context"
+	"fmt"
 	"io"
 	"net/http"
+	"net/url"
+	"strconv"
 	"time"
 
 	"github.com/gorilla/mux"
 	"github.com/rs/zerolog"
+	"golang.org/x/text/language"
 
 	"github.com/gilcrest/diygoapi"
 	"github.com/gilcrest/diygoapi/errs"
@@ -49,6 +53,7 @@ const pathPrefix string = "/api"
 // Services are used by the application service handlers
 type Services struct {
 	OrgServicer            diygoapi.OrgServicer
+	OrgKindServicer        diygoapi.OrgKindServicer
 	AppServicer            diygoapi.AppServicer
 	RegisterUserService    diygoapi.RegisterUserServicer
 	PingService            diygoapi.PingServicer
@@ -59,6 +64,8 @@ type Services struct {
 	PermissionServicer     diygoapi.PermissionServicer
 	RoleServicer           diygoapi.RoleServicer
 	MovieServicer          diygoapi.MovieServicer
+	UserServicer           diygoapi.UserServicer
+	InvitationServicer     diygoapi.InvitationServicer
 }
 
 // Server represents an HTTP server.
@@ -66,6 +73,10 @@ type Server struct {
 	router *mux.Router
 	Driver driver.Server
 
+	// skipAuthz holds the registered routes which do not
+	// authorize the user against a Permission
+	skipAuthz map[*mux.Route]bool
+
 	// all logging is done with a zerolog.Logger
 	Logger zerolog.Logger
 
@@ -75,6 +86,11 @@ type Server struct {
 	// See net.Dial for details of the address format.
 	Addr string
 
+	// LanguageMatcher matches the languages accepted for a request
+	// against the languages supported by the server. When nil, no
+	// language is negotiated for requests.
+	LanguageMatcher language.Matcher
+
 	// Services used by the various HTTP routes and middleware.
 	Services
 }
@@ -179,3 +195,70 @@ func decoderErr(err error) error {
 	}
 	return nil
 }
+
+const (
+	// defaultAuthzDecisionSearchWindow is the time range searched
+	// when no start of the range is given
+	defaultAuthzDecisionSearchWindow = 24 * time.Hour
+	// defaultAuthzDecisionSearchLimit is the number of decisions
+	// returned when no limit is given
+	defaultAuthzDecisionSearchLimit = 100
+)
+
+// newAuthzDecisionSearchRequest initializes an AuthzDecisionSearchRequest
+// from the user, from, until, decision and limit query parameters.
+// from and until are RFC 3339 timestamps. until defaults to now, from
+// defaults to a day before until and limit defaults to 100.
+func newAuthzDecisionSearchRequest(q url.Values, now time.Time) (*diygoapi.AuthzDecisionSearchRequest, error) {
+	const op errs.Op = "server/newAuthzDecisionSearchRequest"
+
+	r := &diygoapi.AuthzDecisionSearchRequest{
+		UserExternalID: q.Get("user"),
+		Until:          now,
+		Decision:       q.Get("decision"),
+		Limit:          defaultAuthzDecisionSearchLimit,
+	}
+
+	var err error
+	if v := q.Get("until"); v != "" {
+		r.Until, err = time.Parse(time.RFC3339, v)
+		if err != nil {
+			return nil, errs.E(op, errs.InvalidRequest, fmt.Sprintf("until must be an RFC 3339 timestamp: %s", v))
+		}
+	}
+
+	r.From = r.Until.Add(-defaultAuthzDecisionSearchWindow)
+	if v := q.Get("from"); v != "" {
+		r.From, err = time.Parse(time.RFC3339, v)
+		if err != nil {
+			return nil, errs.E(op, errs.InvalidRequest, fmt.Sprintf("from must be an RFC 3339 timestamp: %s", v))
+		}
+	}
+
+	if v := q.Get("limit"); v != "" {
+		r.Limit, err = strconv.Atoi(v)
+		if err != nil {
+			return nil, errs.E(op, errs.InvalidRequest, fmt.Sprintf("limit must be a number: %s", v))
+		}
+	}
+
+	return r, nil
+}
+
+// boolQueryParam parses an optional boolean query parameter given its
+// key (e.g. cascade or include_deleted), it defaults to false
+func boolQueryParam(q url.Values, key string) (bool, error) {
+	const op errs.Op = "server/boolQueryParam"
+
+	v := q.Get(key)
+	if v == "" {
+		return false, nil
+	}
+
+	b, err := strconv.ParseBool(v)
+	if err != nil {
+		return false, errs.E(op, errs.InvalidRequest, fmt.Sprintf("%s must be a boolean: %s", key, v))
+	}
+
+	return b, nil
+}
@@ -5,10 +5,13 @@ import (
 	"encoding/json"
 	"errors"
 	"net/http"
+	"net/url"
 	"testing"
+	"time"
 
 	qt "github.com/frankban/quicktest"
 
+	"github.com/gilcrest/diygoapi"
 	"github.com/gilcrest/diygoapi/errs"
 )
 
@@ -130,3 +133,75 @@ func TestDecoderErr(t *testing.T) {
 		c.Assert(err != nil, qt.Equals, true)
 	})
 }
+
+func Test_newAuthzDecisionSearchRequest(t *testing.T) {
+	now := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+
+	t.Run("defaults", func(t *testing.T) {
+		c := qt.New(t)
+
+		r, err := newAuthzDecisionSearchRequest(url.Values{}, now)
+		c.Assert(err, qt.IsNil)
+		c.Assert(r, qt.DeepEquals, &diygoapi.AuthzDecisionSearchRequest{
+			From:  now.Add(-defaultAuthzDecisionSearchWindow),
+			Until: now,
+			Limit: defaultAuthzDecisionSearchLimit,
+		})
+	})
+	t.Run("all parameters", func(t *testing.T) {
+		c := qt.New(t)
+
+		q := url.Values{
+			"user":     {"abc123"},
+			"from":     {"2026-02-01T00:00:00Z"},
+			"until":    {"2026-02-02T00:00:00Z"},
+			"decision": {diygoapi.AuthzDecisionDeny},
+			"limit":    {"10"},
+		}
+		r, err := newAuthzDecisionSearchRequest(q, now)
+		c.Assert(err, qt.IsNil)
+		c.Assert(r, qt.DeepEquals, &diygoapi.AuthzDecisionSearchRequest{
+			UserExternalID: "abc123",
+			From:           time.Date(2026, 2, 1, 0, 0, 0, 0, time.UTC),
+			Until:          time.Date(2026, 2, 2, 0, 0, 0, 0, time.UTC),
+			Decision:       diygoapi.AuthzDecisionDeny,
+			Limit:          10,
+		})
+	})
+	t.Run("invalid", func(t *testing.T) {
+		for _, q := range []url.Values{{"from": {"yesterday"}}, {"until": {"now"}}, {"limit": {"ten"}}} {
+			c := qt.New(t)
+
+			_, err := newAuthzDecisionSearchRequest(q, now)
+			c.Assert(errs.KindIs(errs.InvalidRequest, err), qt.IsTrue)
+		}
+	})
+}
+
+func Test_boolQueryParam(t *testing.T) {
+	tests := []struct {
+		name string
+		q    url.Values
+		want bool
+	}{
+		{"absent", url.Values{}, false},
+		{"true", url.Values{"cascade": {"true"}}, true},
+		{"false", url.Values{"cascade": {"false"}}, false},
+		{"other key", url.Values{"include_deleted": {"true"}}, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := qt.New(t)
+
+			got, err := boolQueryParam(tt.q, "cascade")
+			c.Assert(err, qt.IsNil)
+			c.Assert(got, qt.Equals, tt.want)
+		})
+	}
+	t.Run("invalid", func(t *testing.T) {
+		c := qt.New(t)
+
+		_, err := boolQueryParam(url.Values{"cascade": {"all"}}, "cascade")
+		c.Assert(errs.KindIs(errs.InvalidRequest, err), qt.IsTrue)
+	})
+}
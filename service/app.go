@@ -7,6 +7,7 @@ import (
 	"time"
 
 	"github.com/google/uuid"
+	"github.com/jackc/pgconn"
 	"github.com/jackc/pgx/v4"
 
 	"github.com/gilcrest/diygoapi"
@@ -21,10 +22,11 @@ type appAudit struct {
 	SimpleAudit *diygoapi.SimpleAudit
 }
 
-// newAPIKeyResponse initializes an APIKeyResponse. The app.APIKey is
-// decrypted and set to the Key field as part of initialization.
+// newAPIKeyResponse initializes an APIKeyResponse. The plaintext key is
+// only known when the app.APIKey is generated, so Key is only populated
+// in responses for newly created keys.
 func newAPIKeyResponse(key diygoapi.APIKey) diygoapi.APIKeyResponse {
-	return diygoapi.APIKeyResponse{Key: key.Key(), DeactivationDate: key.DeactivationDate().String()}
+	return diygoapi.APIKeyResponse{Key: key.Key(), Prefix: key.Prefix(), DeactivationDate: key.DeactivationDate().String()}
 }
 
 // newAppResponse initializes an AppResponse
@@ -34,20 +36,31 @@ func newAppResponse(aa appAudit) *diygoapi.AppResponse {
 		akr := newAPIKeyResponse(key)
 		keys = append(keys, akr)
 	}
-	return &diygoapi.AppResponse{
-		ExternalID:          aa.App.ExternalID.String(),
-		Name:                aa.App.Name,
-		Description:         aa.App.Description,
-		CreateAppExtlID:     aa.SimpleAudit.Create.App.ExternalID.String(),
-		CreateUserFirstName: aa.SimpleAudit.Create.User.FirstName,
-		CreateUserLastName:  aa.SimpleAudit.Create.User.LastName,
-		CreateDateTime:      aa.SimpleAudit.Create.Moment.Format(time.RFC3339),
-		UpdateAppExtlID:     aa.SimpleAudit.Update.App.ExternalID.String(),
-		UpdateUserFirstName: aa.SimpleAudit.Update.User.FirstName,
-		UpdateUserLastName:  aa.SimpleAudit.Update.User.LastName,
-		UpdateDateTime:      aa.SimpleAudit.Update.Moment.Format(time.RFC3339),
-		APIKeys:             keys,
-	}
+	var provider string
+	if aa.App.ProviderClientID != "" {
+		provider = aa.App.Provider.String()
+	}
+	ar := &diygoapi.AppResponse{
+		ExternalID:             aa.App.ExternalID.String(),
+		Name:                   aa.App.Name,
+		Description:            aa.App.Description,
+		Oauth2Provider:         provider,
+		Oauth2ProviderClientID: aa.App.ProviderClientID,
+		CreateAppExtlID:        aa.SimpleAudit.Create.App.ExternalID.String(),
+		CreateUserFirstName:    aa.SimpleAudit.Create.User.FirstName,
+		CreateUserLastName:     aa.SimpleAudit.Create.User.LastName,
+		CreateDateTime:         aa.SimpleAudit.Create.Moment.Format(time.RFC3339),
+		UpdateAppExtlID:        aa.SimpleAudit.Update.App.ExternalID.String(),
+		UpdateUserFirstName:    aa.SimpleAudit.Update.User.FirstName,
+		UpdateUserLastName:     aa.SimpleAudit.Update.User.LastName,
+		UpdateDateTime:         aa.SimpleAudit.Update.Moment.Format(time.RFC3339),
+		APIKeys:                keys,
+	}
+	if !aa.App.DeletedAt.IsZero() {
+		ar.DeletedDateTime = aa.App.DeletedAt.Format(time.RFC3339)
+	}
+
+	return ar
 }
 
 // AppService is a service for creating an App
@@ -70,7 +83,7 @@ func (s *AppService) Create(ctx context.Context, r *diygoapi.CreateAppRequest, a
 		Description: r.Description,
 		// when creating an app, the org the app belongs to must be
 		// the same as the org which the user is transacting.
-		Org:             adt.App.Org,
+		Org:             adt.ActingOrg(),
 		ApiKeyGenerator: s.APIKeyGenerator,
 		EncryptionKey:   s.EncryptionKey,
 	}
@@ -120,7 +133,7 @@ type newAppParams struct {
 	Org *diygoapi.Org
 	// apiKeyGenerator: random string generator used to create API key for app
 	ApiKeyGenerator diygoapi.APIKeyGenerator
-	// encryptionKey: encryption key used to encrypt the generated API key
+	// encryptionKey: server secret used to hash the generated API key
 	EncryptionKey *[32]byte
 	// Provider is the OAuth2 provider
 	Provider diygoapi.Provider
@@ -146,7 +159,7 @@ func newApp(nap newAppParams) (a *diygoapi.App, err error) {
 	// create new API key
 	keyDeactivation := time.Date(2099, 12, 31, 0, 0, 0, 0, time.UTC)
 	var key diygoapi.APIKey
-	key, err = diygoapi.NewAPIKey(nap.ApiKeyGenerator, nap.EncryptionKey, keyDeactivation)
+	key, err = diygoapi.NewAPIKey(nap.ApiKeyGenerator, diygoapi.APIKeyPepper(nap.EncryptionKey), keyDeactivation)
 	if err != nil {
 		return nil, errs.E(op, err)
 	}
@@ -193,38 +206,56 @@ func createAppTx(ctx context.Context, tx pgx.Tx, aa appAudit) (err error) {
 	}
 
 	for _, key := range aa.App.APIKeys {
-
-		createAppAPIKeyParams := datastore.CreateAppAPIKeyParams{
-			ApiKey:          key.Ciphertext(),
-			AppID:           aa.App.ID,
-			DeactvDate:      key.DeactivationDate(),
-			CreateAppID:     aa.SimpleAudit.Create.App.ID,
-			CreateUserID:    aa.SimpleAudit.Create.User.NullUUID(),
-			CreateTimestamp: aa.SimpleAudit.Create.Moment,
-			UpdateAppID:     aa.SimpleAudit.Update.App.ID,
-			UpdateUserID:    aa.SimpleAudit.Update.User.NullUUID(),
-			UpdateTimestamp: aa.SimpleAudit.Update.Moment,
-		}
-
-		// create app API key database record using appstore
-		var apiKeyRowsAffected int64
-		apiKeyRowsAffected, err = datastore.New(tx).CreateAppAPIKey(ctx, createAppAPIKeyParams)
+		err = createAPIKeyTx(ctx, tx, aa.App.ID, key, aa.SimpleAudit)
 		if err != nil {
-			return errs.E(op, errs.Database, err)
+			return errs.E(op, err)
 		}
+	}
 
-		if apiKeyRowsAffected != 1 {
-			return errs.E(op, errs.Database, fmt.Sprintf("rows affected should be 1, actual: %d", apiKeyRowsAffected))
-		}
+	return nil
+}
+
+// createAPIKeyTx creates an API key for the app in the database using a pgx.Tx.
+func createAPIKeyTx(ctx context.Context, tx pgx.Tx, appID uuid.UUID, key diygoapi.APIKey, sa *diygoapi.SimpleAudit) error {
+	const op errs.Op = "service/createAPIKeyTx"
+
+	createAppAPIKeyParams := datastore.CreateAppAPIKeyParams{
+		ApiKey:          key.Hash(),
+		ApiKeyPrefix:    key.Prefix(),
+		AppID:           appID,
+		DeactvDate:      key.DeactivationDate(),
+		CreateAppID:     sa.Create.App.ID,
+		CreateUserID:    sa.Create.User.NullUUID(),
+		CreateTimestamp: sa.Create.Moment,
+		UpdateAppID:     sa.Update.App.ID,
+		UpdateUserID:    sa.Update.User.NullUUID(),
+		UpdateTimestamp: sa.Update.Moment,
+	}
+
+	// create app API key database record using appstore
+	rowsAffected, err := datastore.New(tx).CreateAppAPIKey(ctx, createAppAPIKeyParams)
+	if err != nil {
+		return errs.E(op, errs.Database, err)
+	}
+
+	if rowsAffected != 1 {
+		return errs.E(op, errs.Database, fmt.Sprintf("rows affected should be 1, actual: %d", rowsAffected))
 	}
 
 	return nil
 }
 
-// Update is used to update an App. API Keys for an App cannot be updated.
+// Update is used to update an App. API Keys for an App cannot be
+// updated. If an Oauth2 provider and client ID are given, the App is
+// reassigned to them.
 func (s *AppService) Update(ctx context.Context, r *diygoapi.UpdateAppRequest, adt diygoapi.Audit) (ar *diygoapi.AppResponse, err error) {
 	const op errs.Op = "service/AppService.Update"
 
+	err = r.Validate()
+	if err != nil {
+		return nil, errs.E(op, err)
+	}
+
 	// start db txn using pgxpool
 	var tx pgx.Tx
 	tx, err = s.Datastorer.BeginTx(ctx)
@@ -236,34 +267,48 @@ func (s *AppService) Update(ctx context.Context, r *diygoapi.UpdateAppRequest, a
 		err = s.Datastorer.RollbackTx(ctx, tx, err)
 	}()
 
-	// retrieve existing Org
+	// retrieve existing App
 	var aa appAudit
-	aa, err = findAppByExternalIDWithAudit(ctx, tx, r.ExternalID)
+	aa, err = findAppByExternalIDWithAudit(ctx, tx, r.ExternalID, false)
 	if err != nil {
-		if err == pgx.ErrNoRows {
-			return nil, errs.E(op, errs.Validation, "No app exists for the given external ID")
-		}
-		return nil, errs.E(op, errs.Database, err)
+		return nil, errs.E(op, err)
+	}
+
+	// only the user who created the app or an admin of its org may update it
+	err = authorizeOwner(ctx, tx, adt, diygoapi.Owner{OrgID: aa.App.Org.ID, UserID: aa.SimpleAudit.Create.User.NullUUID()})
+	if err != nil {
+		return nil, errs.E(op, err)
 	}
+
 	// overwrite Update audit with the current audit
 	aa.SimpleAudit.Update = adt
 
 	// override fields with data from request
 	aa.App.Name = r.Name
 	aa.App.Description = r.Description
+	if r.Oauth2ProviderClientID != "" {
+		aa.App.Provider = diygoapi.ParseProvider(r.Oauth2Provider)
+		aa.App.ProviderClientID = r.Oauth2ProviderClientID
+	}
 
 	updateAppParams := datastore.UpdateAppParams{
-		AppName:         aa.App.Name,
-		AppDescription:  aa.App.Description,
-		UpdateAppID:     adt.App.ID,
-		UpdateUserID:    adt.User.NullUUID(),
-		UpdateTimestamp: adt.Moment,
-		AppID:           aa.App.ID,
+		AppName:              aa.App.Name,
+		AppDescription:       aa.App.Description,
+		AuthProviderID:       diygoapi.NewNullInt32(int32(aa.App.Provider)),
+		AuthProviderClientID: diygoapi.NewNullString(aa.App.ProviderClientID),
+		UpdateAppID:          adt.App.ID,
+		UpdateUserID:         adt.User.NullUUID(),
+		UpdateTimestamp:      adt.Moment,
+		AppID:                aa.App.ID,
 	}
 
 	var rowsAffected int64
 	rowsAffected, err = datastore.New(tx).UpdateApp(ctx, updateAppParams)
 	if err != nil {
+		var pgErr *pgconn.PgError
+		if errors.As(err, &pgErr) && pgErr.Code == "23505" {
+			return nil, errs.E(op, errs.Exist, "app name or oAuth2 provider client ID is already in use by another app")
+		}
 		return nil, errs.E(op, errs.Database, err)
 	}
 
@@ -274,14 +319,16 @@ func (s *AppService) Update(ctx context.Context, r *diygoapi.UpdateAppRequest, a
 	// commit db txn using pgxpool
 	err = s.Datastorer.CommitTx(ctx, tx)
 	if err != nil {
-		return nil, err
+		return nil, errs.E(op, err)
 	}
 
 	return newAppResponse(aa), nil
 }
 
-// Delete is used to delete an App
-func (s *AppService) Delete(ctx context.Context, extlID string) (dr diygoapi.DeleteResponse, err error) {
+// Delete is used to soft delete an App. A deleted App can no longer
+// authenticate, it is kept along with its API keys until it is purged.
+// The App making the request cannot be deleted.
+func (s *AppService) Delete(ctx context.Context, extlID string, adt diygoapi.Audit) (dr diygoapi.DeleteResponse, err error) {
 	const op errs.Op = "service/AppService.Delete"
 
 	// start db txn using pgxpool
@@ -296,20 +343,39 @@ func (s *AppService) Delete(ctx context.Context, extlID string) (dr diygoapi.Del
 	}()
 
 	// retrieve existing App
-	var a diygoapi.App
-	a, err = findAppByExternalID(ctx, tx, extlID)
+	var aa appAudit
+	aa, err = findAppByExternalIDWithAudit(ctx, tx, extlID, false)
 	if err != nil {
-		if err == pgx.ErrNoRows {
-			return diygoapi.DeleteResponse{}, errs.E(op, errs.Validation, "No app exists for the given external ID")
-		}
-		return diygoapi.DeleteResponse{}, errs.E(op, errs.Database, err)
+		return diygoapi.DeleteResponse{}, errs.E(op, err)
+	}
+
+	if aa.App.ID == adt.App.ID {
+		return diygoapi.DeleteResponse{}, errs.E(op, errs.Validation, "an app cannot delete itself")
 	}
 
-	err = deleteAppTx(ctx, tx, a)
+	// only the user who created the app or an admin of its org may delete it
+	err = authorizeOwner(ctx, tx, adt, diygoapi.Owner{OrgID: aa.App.Org.ID, UserID: aa.SimpleAudit.Create.User.NullUUID()})
 	if err != nil {
 		return diygoapi.DeleteResponse{}, errs.E(op, err)
 	}
 
+	params := datastore.SoftDeleteAppParams{
+		DeletedAppID:     diygoapi.NewNullUUID(adt.App.ID),
+		DeletedUserID:    adt.User.NullUUID(),
+		DeletedTimestamp: diygoapi.NewNullTime(adt.Moment),
+		AppID:            aa.App.ID,
+	}
+
+	var rowsAffected int64
+	rowsAffected, err = datastore.New(tx).SoftDeleteApp(ctx, params)
+	if err != nil {
+		return diygoapi.DeleteResponse{}, errs.E(op, errs.Database, err)
+	}
+
+	if rowsAffected != 1 {
+		return diygoapi.DeleteResponse{}, errs.E(op, errs.Database, fmt.Sprintf("rows affected should be 1, actual: %d", rowsAffected))
+	}
+
 	// commit db txn using pgxpool
 	err = s.Datastorer.CommitTx(ctx, tx)
 	if err != nil {
@@ -324,24 +390,96 @@ func (s *AppService) Delete(ctx context.Context, extlID string) (dr diygoapi.Del
 	return response, nil
 }
 
+// Restore undoes the deletion of an App. An App cannot be restored
+// while its Org is deleted.
+func (s *AppService) Restore(ctx context.Context, extlID string, adt diygoapi.Audit) (ar *diygoapi.AppResponse, err error) {
+	const op errs.Op = "service/AppService.Restore"
+
+	// start db txn using pgxpool
+	var tx pgx.Tx
+	tx, err = s.Datastorer.BeginTx(ctx)
+	if err != nil {
+		return nil, errs.E(op, err)
+	}
+	// defer transaction rollback and handle error, if any
+	defer func() {
+		err = s.Datastorer.RollbackTx(ctx, tx, err)
+	}()
+
+	var aa appAudit
+	aa, err = findAppByExternalIDWithAudit(ctx, tx, extlID, true)
+	if err != nil {
+		return nil, errs.E(op, err)
+	}
+
+	if aa.App.DeletedAt.IsZero() {
+		return nil, errs.E(op, errs.NotExist, fmt.Sprintf("app %s is not deleted", extlID))
+	}
+
+	// only the user who created the app or an admin of its org may restore it
+	err = authorizeOwner(ctx, tx, adt, diygoapi.Owner{OrgID: aa.App.Org.ID, UserID: aa.SimpleAudit.Create.User.NullUUID()})
+	if err != nil {
+		return nil, errs.E(op, err)
+	}
+
+	_, err = findOrgByExternalID(ctx, tx, aa.App.Org.ExternalID.String())
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, errs.E(op, errs.Validation, fmt.Sprintf("org %s is deleted and must be restored first", aa.App.Org.ExternalID.String()))
+		}
+		return nil, errs.E(op, err)
+	}
+
+	params := datastore.RestoreAppParams{
+		UpdateAppID:     adt.App.ID,
+		UpdateUserID:    adt.User.NullUUID(),
+		UpdateTimestamp: adt.Moment,
+		AppID:           aa.App.ID,
+	}
+
+	var rowsAffected int64
+	rowsAffected, err = datastore.New(tx).RestoreApp(ctx, params)
+	if err != nil {
+		return nil, errs.E(op, errs.Database, err)
+	}
+
+	if rowsAffected != 1 {
+		return nil, errs.E(op, errs.Database, fmt.Sprintf("RestoreApp() should update 1 row, actual: %d", rowsAffected))
+	}
+
+	// commit db txn using pgxpool
+	err = s.Datastorer.CommitTx(ctx, tx)
+	if err != nil {
+		return nil, errs.E(op, err)
+	}
+
+	aa.App.DeletedAt = time.Time{}
+	aa.SimpleAudit.Update = adt
+
+	return newAppResponse(aa), nil
+}
+
+// deleteAppTx permanently deletes an App and all of its API keys. An
+// App which has been used to create or update other records cannot be
+// deleted.
 func deleteAppTx(ctx context.Context, tx pgx.Tx, a diygoapi.App) (err error) {
 	const op errs.Op = "service/deleteAppTx"
 
 	// one-to-many API keys can be associated with an App. This will
-	// delete them all.
-	var apiKeysRowsAffected int64
-	apiKeysRowsAffected, err = datastore.New(tx).DeleteAppAPIKeys(ctx, a.ID)
+	// delete them all. An App may have no keys left if they have all
+	// been revoked.
+	_, err = datastore.New(tx).DeleteAppAPIKeys(ctx, a.ID)
 	if err != nil {
 		return errs.E(op, errs.Database, err)
 	}
 
-	if apiKeysRowsAffected < 1 {
-		return errs.E(op, errs.Database, fmt.Sprintf("rows affected should be at least 1, actual: %d", apiKeysRowsAffected))
-	}
-
 	var rowsAffected int64
 	rowsAffected, err = datastore.New(tx).DeleteApp(ctx, a.ID)
 	if err != nil {
+		var pgErr *pgconn.PgError
+		if errors.As(err, &pgErr) && pgErr.Code == "23503" {
+			return errs.E(op, errs.Validation, "app cannot be deleted as it is referenced by other records")
+		}
 		return errs.E(op, errs.Database, err)
 	}
 
@@ -352,8 +490,11 @@ func deleteAppTx(ctx context.Context, tx pgx.Tx, a diygoapi.App) (err error) {
 	return nil
 }
 
-// FindByExternalID is used to find an App by its External ID
-func (s *AppService) FindByExternalID(ctx context.Context, extlID string) (ar *diygoapi.AppResponse, err error) {
+// FindByExternalID is used to find an App by its External ID. An App
+// outside the Org the audit user is acting in can only be read by an
+// admin of the App's Org. A deleted App is only found if
+// includeDeleted is true.
+func (s *AppService) FindByExternalID(ctx context.Context, extlID string, includeDeleted bool, adt diygoapi.Audit) (ar *diygoapi.AppResponse, err error) {
 	const op errs.Op = "service/AppService.FindByExternalID"
 
 	// start db txn using pgxpool
@@ -368,7 +509,20 @@ func (s *AppService) FindByExternalID(ctx context.Context, extlID string) (ar *d
 	}()
 
 	var aa appAudit
-	aa, err = findAppByExternalIDWithAudit(ctx, tx, extlID)
+	aa, err = findAppByExternalIDWithAudit(ctx, tx, extlID, includeDeleted)
+	if err != nil {
+		return nil, errs.E(op, err)
+	}
+
+	if aa.App.Org.ID != adt.ActingOrg().ID {
+		err = authorizeOwner(ctx, tx, adt, diygoapi.Owner{OrgID: aa.App.Org.ID})
+		if err != nil {
+			return nil, errs.E(op, err)
+		}
+	}
+
+	// commit db txn using pgxpool
+	err = s.Datastorer.CommitTx(ctx, tx)
 	if err != nil {
 		return nil, errs.E(op, err)
 	}
@@ -376,8 +530,9 @@ func (s *AppService) FindByExternalID(ctx context.Context, extlID string) (ar *d
 	return newAppResponse(aa), nil
 }
 
-// FindAll is used to list all apps in the datastore
-func (s *AppService) FindAll(ctx context.Context) (sar []*diygoapi.AppResponse, err error) {
+// FindAll is used to list the apps of the Org the audit user is acting
+// in. Deleted apps are only listed if includeDeleted is true.
+func (s *AppService) FindAll(ctx context.Context, includeDeleted bool, adt diygoapi.Audit) (sar []*diygoapi.AppResponse, err error) {
 	const op errs.Op = "service/AppService.FindAll"
 
 	// start db txn using pgxpool
@@ -391,102 +546,287 @@ func (s *AppService) FindAll(ctx context.Context) (sar []*diygoapi.AppResponse,
 		err = s.Datastorer.RollbackTx(ctx, tx, err)
 	}()
 
-	var rows []datastore.FindAppsWithAuditRow
-	rows, err = datastore.New(tx).FindAppsWithAudit(ctx)
+	params := datastore.FindAppsWithAuditByOrgParams{
+		OrgID:          adt.ActingOrg().ID,
+		IncludeDeleted: includeDeleted,
+	}
+
+	var rows []datastore.FindAppsWithAuditByOrgRow
+	rows, err = datastore.New(tx).FindAppsWithAuditByOrg(ctx, params)
 	if err != nil {
 		return nil, errs.E(op, errs.Database, err)
 	}
 
 	for _, row := range rows {
-		a := &diygoapi.App{
-			ID:         row.AppID,
-			ExternalID: secure.MustParseIdentifier(row.AppExtlID),
-			Org: &diygoapi.Org{
-				ID:          row.OrgID,
-				ExternalID:  secure.MustParseIdentifier(row.OrgExtlID),
-				Name:        row.OrgName,
-				Description: row.OrgDescription,
-				Kind: &diygoapi.OrgKind{
-					ID:          row.OrgKindID,
-					ExternalID:  row.OrgKindExtlID,
-					Description: row.OrgKindDesc,
-				},
-			},
-			Name:        row.AppName,
-			Description: row.AppDescription,
-			APIKeys:     nil,
+		sar = append(sar, newAppResponse(newAppAudit(datastore.FindAppByExternalIDWithAuditRow(row))))
+	}
+
+	// commit db txn using pgxpool
+	err = s.Datastorer.CommitTx(ctx, tx)
+	if err != nil {
+		return nil, errs.E(op, err)
+	}
+
+	return sar, nil
+}
+
+// CreateAPIKey issues a new API key for an existing App. If a
+// rotation grace period is given, the App's existing keys are set to
+// deactivate once the grace period has passed.
+func (s *AppService) CreateAPIKey(ctx context.Context, r *diygoapi.CreateAPIKeyRequest, adt diygoapi.Audit) (akr *diygoapi.APIKeyResponse, err error) {
+	const op errs.Op = "service/AppService.CreateAPIKey"
+
+	err = r.Validate()
+	if err != nil {
+		return nil, errs.E(op, err)
+	}
+
+	// errors are checked as part of Validate
+	deactivation, _ := time.Parse(time.RFC3339, r.DeactivationDate)
+
+	// start db txn using pgxpool
+	var tx pgx.Tx
+	tx, err = s.Datastorer.BeginTx(ctx)
+	if err != nil {
+		return nil, errs.E(op, err)
+	}
+	// defer transaction rollback and handle error, if any
+	defer func() {
+		err = s.Datastorer.RollbackTx(ctx, tx, err)
+	}()
+
+	var aa appAudit
+	aa, err = findAppByExternalIDWithAudit(ctx, tx, r.AppExternalID, false)
+	if err != nil {
+		return nil, errs.E(op, err)
+	}
+	a := aa.App
+
+	// only the user who created the app or an admin of its org may issue its keys
+	err = authorizeOwner(ctx, tx, adt, diygoapi.Owner{OrgID: a.Org.ID, UserID: aa.SimpleAudit.Create.User.NullUUID()})
+	if err != nil {
+		return nil, errs.E(op, err)
+	}
+
+	// shorten the life of the existing keys before the new key is added
+	if r.RotationGracePeriod != "" {
+		grace, _ := time.ParseDuration(r.RotationGracePeriod)
+
+		params := datastore.UpdateAppAPIKeysDeactvDateParams{
+			DeactvDate:      adt.Moment.Add(grace),
+			UpdateAppID:     adt.App.ID,
+			UpdateUserID:    adt.User.NullUUID(),
+			UpdateTimestamp: adt.Moment,
+			AppID:           a.ID,
 		}
 
-		sa := &diygoapi.SimpleAudit{
-			Create: diygoapi.Audit{
-				App: &diygoapi.App{
-					ID:          row.CreateAppID,
-					ExternalID:  secure.MustParseIdentifier(row.CreateAppExtlID),
-					Org:         &diygoapi.Org{ID: row.CreateAppOrgID},
-					Name:        row.CreateAppName,
-					Description: row.CreateAppDescription,
-					APIKeys:     nil,
-				},
-				User: &diygoapi.User{
-					ID:        row.CreateUserID.UUID,
-					FirstName: row.CreateUserFirstName.String,
-					LastName:  row.CreateUserLastName.String,
-				},
-				Moment: row.CreateTimestamp,
-			},
-			Update: diygoapi.Audit{
-				App: &diygoapi.App{
-					ID:          row.UpdateAppID,
-					ExternalID:  secure.MustParseIdentifier(row.UpdateAppExtlID),
-					Org:         &diygoapi.Org{ID: row.UpdateAppOrgID},
-					Name:        row.UpdateAppName,
-					Description: row.UpdateAppDescription,
-					APIKeys:     nil,
-				},
-				User: &diygoapi.User{
-					ID:        row.UpdateUserID.UUID,
-					FirstName: row.UpdateUserFirstName.String,
-					LastName:  row.UpdateUserLastName.String,
-				},
-				Moment: row.UpdateTimestamp,
-			},
+		_, err = datastore.New(tx).UpdateAppAPIKeysDeactvDate(ctx, params)
+		if err != nil {
+			return nil, errs.E(op, errs.Database, err)
 		}
-		or := newAppResponse(appAudit{App: a, SimpleAudit: sa})
+	}
 
-		sar = append(sar, or)
+	var key diygoapi.APIKey
+	key, err = diygoapi.NewAPIKey(s.APIKeyGenerator, diygoapi.APIKeyPepper(s.EncryptionKey), deactivation)
+	if err != nil {
+		return nil, errs.E(op, err)
 	}
 
-	return sar, nil
+	err = createAPIKeyTx(ctx, tx, a.ID, key, &diygoapi.SimpleAudit{Create: adt, Update: adt})
+	if err != nil {
+		return nil, errs.E(op, err)
+	}
+
+	// commit db txn using pgxpool
+	err = s.Datastorer.CommitTx(ctx, tx)
+	if err != nil {
+		return nil, errs.E(op, err)
+	}
+
+	response := newAPIKeyResponse(key)
+
+	return &response, nil
 }
 
-func findAppByID(ctx context.Context, dbtx datastore.DBTX, id uuid.UUID) (diygoapi.App, error) {
-	const op errs.Op = "service/findAppByID"
+// FindAPIKeys returns the metadata for each API key of the App with
+// the given External ID.
+func (s *AppService) FindAPIKeys(ctx context.Context, appExtlID string, adt diygoapi.Audit) (keys []*diygoapi.APIKeyMetadataResponse, err error) {
+	const op errs.Op = "service/AppService.FindAPIKeys"
 
-	row, err := datastore.New(dbtx).FindAppByID(ctx, id)
+	// start db txn using pgxpool
+	var tx pgx.Tx
+	tx, err = s.Datastorer.BeginTx(ctx)
 	if err != nil {
-		return diygoapi.App{}, errs.E(op, errs.Database, err)
+		return nil, errs.E(op, err)
 	}
+	// defer transaction rollback and handle error, if any
+	defer func() {
+		err = s.Datastorer.RollbackTx(ctx, tx, err)
+	}()
 
-	a := diygoapi.App{
-		ID:         row.AppID,
-		ExternalID: secure.MustParseIdentifier(row.AppExtlID),
-		Org: &diygoapi.Org{
-			ID:          row.OrgID,
-			ExternalID:  secure.MustParseIdentifier(row.OrgExtlID),
-			Name:        row.OrgName,
-			Description: row.OrgDescription,
-			Kind: &diygoapi.OrgKind{
-				ID:          row.OrgKindID,
-				ExternalID:  row.OrgKindExtlID,
-				Description: row.OrgKindDesc,
-			},
-		},
-		Name:        row.AppName,
-		Description: row.AppDescription,
-		APIKeys:     nil,
+	var aa appAudit
+	aa, err = findAppByExternalIDWithAudit(ctx, tx, appExtlID, false)
+	if err != nil {
+		return nil, errs.E(op, err)
 	}
+	a := aa.App
 
-	return a, nil
+	// only the user who created the app or an admin of its org may list its keys
+	err = authorizeOwner(ctx, tx, adt, diygoapi.Owner{OrgID: a.Org.ID, UserID: aa.SimpleAudit.Create.User.NullUUID()})
+	if err != nil {
+		return nil, errs.E(op, err)
+	}
+
+	var rows []datastore.AppApiKey
+	rows, err = datastore.New(tx).FindAPIKeysByAppID(ctx, a.ID)
+	if err != nil {
+		return nil, errs.E(op, errs.Database, err)
+	}
+
+	now := time.Now()
+	for _, row := range rows {
+		kmr := &diygoapi.APIKeyMetadataResponse{
+			Prefix:           row.ApiKeyPrefix,
+			CreateDateTime:   row.CreateTimestamp.Format(time.RFC3339),
+			DeactivationDate: row.DeactvDate.Format(time.RFC3339),
+			Active:           row.DeactvDate.After(now),
+		}
+		if row.LastUsedTimestamp.Valid {
+			kmr.LastUsedDateTime = row.LastUsedTimestamp.Time.Format(time.RFC3339)
+		}
+		keys = append(keys, kmr)
+	}
+
+	return keys, nil
+}
+
+// RevokeAPIKey immediately revokes the API key with the given prefix
+// for the App with the given External ID. The key is deleted.
+func (s *AppService) RevokeAPIKey(ctx context.Context, appExtlID, prefix string, adt diygoapi.Audit) (dr diygoapi.DeleteResponse, err error) {
+	const op errs.Op = "service/AppService.RevokeAPIKey"
+
+	// start db txn using pgxpool
+	var tx pgx.Tx
+	tx, err = s.Datastorer.BeginTx(ctx)
+	if err != nil {
+		return diygoapi.DeleteResponse{}, errs.E(op, err)
+	}
+	// defer transaction rollback and handle error, if any
+	defer func() {
+		err = s.Datastorer.RollbackTx(ctx, tx, err)
+	}()
+
+	var aa appAudit
+	aa, err = findAppByExternalIDWithAudit(ctx, tx, appExtlID, false)
+	if err != nil {
+		return diygoapi.DeleteResponse{}, errs.E(op, err)
+	}
+	a := aa.App
+
+	// only the user who created the app or an admin of its org may revoke its keys
+	err = authorizeOwner(ctx, tx, adt, diygoapi.Owner{OrgID: a.Org.ID, UserID: aa.SimpleAudit.Create.User.NullUUID()})
+	if err != nil {
+		return diygoapi.DeleteResponse{}, errs.E(op, err)
+	}
+
+	var rows []datastore.AppApiKey
+	rows, err = datastore.New(tx).FindAPIKeysByAppID(ctx, a.ID)
+	if err != nil {
+		return diygoapi.DeleteResponse{}, errs.E(op, errs.Database, err)
+	}
+
+	var revoked int
+	for _, row := range rows {
+		if row.ApiKeyPrefix != prefix {
+			continue
+		}
+
+		var rowsAffected int64
+		rowsAffected, err = datastore.New(tx).DeleteAppAPIKey(ctx, row.ApiKey)
+		if err != nil {
+			return diygoapi.DeleteResponse{}, errs.E(op, errs.Database, err)
+		}
+
+		if rowsAffected != 1 {
+			return diygoapi.DeleteResponse{}, errs.E(op, errs.Database, fmt.Sprintf("rows affected should be 1, actual: %d", rowsAffected))
+		}
+		revoked++
+	}
+
+	if revoked == 0 {
+		return diygoapi.DeleteResponse{}, errs.E(op, errs.NotExist, fmt.Sprintf("no API key found with prefix: %s", prefix))
+	}
+
+	// commit db txn using pgxpool
+	err = s.Datastorer.CommitTx(ctx, tx)
+	if err != nil {
+		return diygoapi.DeleteResponse{}, errs.E(op, err)
+	}
+
+	response := diygoapi.DeleteResponse{
+		ExternalID: prefix,
+		Deleted:    true,
+	}
+
+	return response, nil
+}
+
+// MigrateAPIKeys converts API keys stored using reversible encryption
+// to hashed API keys. Each legacy key is decrypted using the encryption
+// key and then hashed and stored with its lookup prefix. The number of
+// keys migrated is returned.
+func (s *AppService) MigrateAPIKeys(ctx context.Context) (n int64, err error) {
+	const op errs.Op = "service/AppService.MigrateAPIKeys"
+
+	// start db txn using pgxpool
+	var tx pgx.Tx
+	tx, err = s.Datastorer.BeginTx(ctx)
+	if err != nil {
+		return 0, errs.E(op, err)
+	}
+	// defer transaction rollback and handle error, if any
+	defer func() {
+		err = s.Datastorer.RollbackTx(ctx, tx, err)
+	}()
+
+	var rows []datastore.FindLegacyAppAPIKeysRow
+	rows, err = datastore.New(tx).FindLegacyAppAPIKeys(ctx)
+	if err != nil {
+		return 0, errs.E(op, errs.Database, err)
+	}
+
+	for _, row := range rows {
+		var key diygoapi.APIKey
+		key, err = diygoapi.NewAPIKeyFromCipher(row.ApiKey, s.EncryptionKey, diygoapi.APIKeyPepper(s.EncryptionKey))
+		if err != nil {
+			return 0, errs.E(op, err)
+		}
+
+		params := datastore.UpdateAppAPIKeyHashParams{
+			ApiKeyHash:   key.Hash(),
+			ApiKeyPrefix: key.Prefix(),
+			LegacyApiKey: row.ApiKey,
+		}
+
+		var rowsAffected int64
+		rowsAffected, err = datastore.New(tx).UpdateAppAPIKeyHash(ctx, params)
+		if err != nil {
+			return 0, errs.E(op, errs.Database, err)
+		}
+
+		if rowsAffected != 1 {
+			return 0, errs.E(op, errs.Database, fmt.Sprintf("rows affected should be 1, actual: %d", rowsAffected))
+		}
+		n++
+	}
+
+	// commit db txn using pgxpool
+	err = s.Datastorer.CommitTx(ctx, tx)
+	if err != nil {
+		return 0, errs.E(op, err)
+	}
+
+	return n, nil
 }
 
 func findAppByExternalID(ctx context.Context, dbtx datastore.DBTX, extlID string) (diygoapi.App, error) {
@@ -521,8 +861,9 @@ func findAppByExternalID(ctx context.Context, dbtx datastore.DBTX, extlID string
 
 // findAppByExternalIDWithAudit retrieves App data from the datastore
 // given a unique external ID, which is then hydrated into an App
-// and audit struct.
-func findAppByExternalIDWithAudit(ctx context.Context, dbtx datastore.DBTX, extlID string) (appAudit, error) {
+// and audit struct. A deleted App is only found if includeDeleted is
+// true.
+func findAppByExternalIDWithAudit(ctx context.Context, dbtx datastore.DBTX, extlID string, includeDeleted bool) (appAudit, error) {
 	const op errs.Op = "service/findAppByExternalIDWithAudit"
 
 	var (
@@ -530,11 +871,21 @@ func findAppByExternalIDWithAudit(ctx context.Context, dbtx datastore.DBTX, extl
 		err error
 	)
 
-	row, err = datastore.New(dbtx).FindAppByExternalIDWithAudit(ctx, extlID)
+	row, err = datastore.New(dbtx).FindAppByExternalIDWithAudit(ctx, datastore.FindAppByExternalIDWithAuditParams{AppExtlID: extlID, IncludeDeleted: includeDeleted})
 	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return appAudit{}, errs.E(op, errs.NotExist, fmt.Sprintf("no app exists for the given external ID: %s", extlID))
+		}
 		return appAudit{}, errs.E(op, errs.Database, err)
 	}
 
+	return newAppAudit(row), nil
+}
+
+// newAppAudit initializes an appAudit given a row selected with audit
+// data. The rows of FindAppsWithAuditByOrg have the same fields and
+// can be converted to this type.
+func newAppAudit(row datastore.FindAppByExternalIDWithAuditRow) appAudit {
 	a := &diygoapi.App{
 		ID:         row.AppID,
 		ExternalID: secure.MustParseIdentifier(row.AppExtlID),
@@ -549,9 +900,12 @@ func findAppByExternalIDWithAudit(ctx context.Context, dbtx datastore.DBTX, extl
 				Description: row.OrgKindDesc,
 			},
 		},
-		Name:        row.AppName,
-		Description: row.AppDescription,
-		APIKeys:     nil,
+		Name:             row.AppName,
+		Description:      row.AppDescription,
+		Provider:         diygoapi.Provider(row.AuthProviderID.Int32),
+		ProviderClientID: row.AuthProviderClientID.String,
+		APIKeys:          nil,
+		DeletedAt:        row.DeletedTimestamp.Time,
 	}
 
 	sa := &diygoapi.SimpleAudit{
@@ -589,7 +943,7 @@ func findAppByExternalIDWithAudit(ctx context.Context, dbtx datastore.DBTX, extl
 		},
 	}
 
-	return appAudit{App: a, SimpleAudit: sa}, nil
+	return appAudit{App: a, SimpleAudit: sa}
 }
 
 func findAppByProviderClientID(ctx context.Context, tx pgx.Tx, id string) (*diygoapi.App, error) {
@@ -11,6 +11,7 @@ import (
 	"github.com/jackc/pgx/v4"
 
 	"github.com/gilcrest/diygoapi"
+	"github.com/gilcrest/diygoapi/errs"
 	"github.com/gilcrest/diygoapi/secure"
 	"github.com/gilcrest/diygoapi/service"
 	"github.com/gilcrest/diygoapi/sqldb/datastore"
@@ -170,7 +171,7 @@ func TestAppService(t *testing.T) {
 		}
 
 		var got *diygoapi.AppResponse
-		got, err = s.FindByExternalID(context.Background(), testAppRow.AppExtlID)
+		got, err = s.FindByExternalID(context.Background(), testAppRow.AppExtlID, false, adt)
 		want := &diygoapi.AppResponse{
 			ExternalID:          got.ExternalID,
 			Name:                testAppServiceUpdatedAppName,
@@ -191,17 +192,22 @@ func TestAppService(t *testing.T) {
 		db, cleanup := sqldbtest.NewDB(t)
 		c.Cleanup(cleanup)
 
+		// start db txn using pgxpool
 		ctx := context.Background()
+		tx, err := db.BeginTx(ctx)
+		if err != nil {
+			c.Fatalf("BeginTx() error = %v", err)
+		}
+		c.Cleanup(func() { _ = db.RollbackTx(ctx, tx, err) })
+
+		adt := findTestAudit(ctx, c, tx)
 
 		s := service.AppService{
 			Datastorer: db,
 		}
 
-		var (
-			got []*diygoapi.AppResponse
-			err error
-		)
-		got, err = s.FindAll(ctx)
+		var got []*diygoapi.AppResponse
+		got, err = s.FindAll(ctx, false, adt)
 		c.Assert(err, qt.IsNil)
 		c.Assert(len(got) >= 1, qt.IsTrue, qt.Commentf("apps found = %d, should be at least 1", len(got)))
 		c.Logf("apps found = %d", len(got))
@@ -241,7 +247,7 @@ func TestAppService(t *testing.T) {
 		}
 
 		var got diygoapi.DeleteResponse
-		got, err = s.Delete(context.Background(), testAppRow.AppExtlID)
+		got, err = s.Delete(context.Background(), testAppRow.AppExtlID, adt)
 		want := diygoapi.DeleteResponse{
 			ExternalID: testAppRow.AppExtlID,
 			Deleted:    true,
@@ -249,6 +255,46 @@ func TestAppService(t *testing.T) {
 		c.Assert(err, qt.IsNil)
 		c.Assert(got, qt.Equals, want)
 	})
+	t.Run("api keys of another org", func(t *testing.T) {
+		c := qt.New(t)
+
+		db, cleanup := sqldbtest.NewDB(t)
+		c.Cleanup(cleanup)
+
+		// the outsider is a member of an org of their own, not of
+		// the org of the Test App
+		ctx := context.Background()
+		tx, err := db.BeginTx(ctx)
+		if err != nil {
+			c.Fatalf("BeginTx() error = %v", err)
+		}
+		adt := findTestAudit(ctx, c, tx)
+		outsider := createTestUser(ctx, c, tx, adt, createTestOrg(ctx, c, tx, adt, nil))
+		err = db.CommitTx(ctx, tx)
+		c.Assert(err, qt.IsNil)
+
+		outsiderAdt := diygoapi.Audit{App: adt.App, User: outsider, Moment: time.Now()}
+		appExtlID := adt.App.ExternalID.String()
+
+		s := service.AppService{
+			Datastorer:      db,
+			APIKeyGenerator: secure.RandomGenerator{},
+			EncryptionKey:   &[32]byte{},
+		}
+
+		r := diygoapi.CreateAPIKeyRequest{
+			AppExternalID:    appExtlID,
+			DeactivationDate: time.Now().Add(24 * time.Hour).Format(time.RFC3339),
+		}
+		_, err = s.CreateAPIKey(ctx, &r, outsiderAdt)
+		c.Assert(errs.KindIs(errs.Unauthorized, err), qt.IsTrue)
+
+		_, err = s.FindAPIKeys(ctx, appExtlID, outsiderAdt)
+		c.Assert(errs.KindIs(errs.Unauthorized, err), qt.IsTrue)
+
+		_, err = s.RevokeAPIKey(ctx, appExtlID, "anything", outsiderAdt)
+		c.Assert(errs.KindIs(errs.Unauthorized, err), qt.IsTrue)
+	})
 }
 
 func findTestAudit(ctx context.Context, c *qt.C, tx datastore.DBTX) diygoapi.Audit {
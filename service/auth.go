@@ -2,8 +2,11 @@ package service
 
 import (
 	"context"
+	"database/sql"
+	"encoding/hex"
 	"errors"
 	"fmt"
+	"math/rand"
 	"net/http"
 	"time"
 
@@ -12,6 +15,7 @@ import (
 	"github.com/jackc/pgconn"
 	"github.com/jackc/pgx/v4"
 	"github.com/rs/zerolog"
+	"github.com/rs/zerolog/hlog"
 	"golang.org/x/oauth2"
 	"golang.org/x/text/language"
 
@@ -28,6 +32,8 @@ type DBAuthenticationService struct {
 	TokenExchanger  diygoapi.TokenExchanger
 	EncryptionKey   *[32]byte
 	LanguageMatcher language.Matcher
+	// RegistrationPolicy determines who is allowed to Register
+	RegistrationPolicy diygoapi.RegistrationPolicy
 }
 
 // FindAuth searches for an existing Auth object in the datastore.
@@ -45,6 +51,9 @@ type DBAuthenticationService struct {
 // exception is if an app is already set to the request context from upstream
 // authentication, in which case, the upstream app overrides the app derived
 // from the Oauth2 provider.
+//
+// If an invitation token is given, the org invitation is accepted for
+// the User.
 func (s DBAuthenticationService) FindAuth(ctx context.Context, params diygoapi.AuthenticationParams) (auth diygoapi.Auth, err error) {
 	const op errs.Op = "service/DBAuthenticationService.FindAuth"
 
@@ -59,6 +68,7 @@ func (s DBAuthenticationService) FindAuth(ctx context.Context, params diygoapi.A
 		err = s.Datastorer.RollbackTx(ctx, tx, err)
 	}()
 
+	var providerInfo *diygoapi.ProviderInfo
 	auth, err = findAuthByAccessToken(ctx, tx, params)
 	if err != nil {
 		// if error is something other than NotExist, then return error
@@ -68,7 +78,6 @@ func (s DBAuthenticationService) FindAuth(ctx context.Context, params diygoapi.A
 
 		// auth could not be found by access token in the db
 		// get ProviderInfo from provider API
-		var providerInfo *diygoapi.ProviderInfo
 		providerInfo, err = s.TokenExchanger.Exchange(ctx, params.Realm, params.Provider, params.Token)
 		if err != nil {
 			return diygoapi.Auth{}, errs.E(op, err)
@@ -90,6 +99,14 @@ func (s DBAuthenticationService) FindAuth(ctx context.Context, params diygoapi.A
 		}
 	}
 
+	// accept the org invitation sent with the request, if any
+	if params.InvitationToken != "" {
+		err = s.acceptAuthInvitation(ctx, tx, params, auth, providerInfo)
+		if err != nil {
+			return diygoapi.Auth{}, errs.E(op, err)
+		}
+	}
+
 	// commit db txn using pgxpool
 	err = s.Datastorer.CommitTx(ctx, tx)
 	if err != nil {
@@ -138,6 +155,11 @@ func findAuthByAccessToken(ctx context.Context, tx pgx.Tx, params diygoapi.Authe
 		return diygoapi.Auth{}, errs.E(op, err)
 	}
 
+	// a suspended user cannot authenticate
+	if u.Suspended {
+		return diygoapi.Auth{}, errs.E(op, errs.Unauthenticated, errs.Realm(params.Realm), "user is suspended")
+	}
+
 	// populate Auth
 	auth := diygoapi.Auth{
 		ID:               dbAuth.AuthID,
@@ -152,14 +174,30 @@ func findAuthByAccessToken(ctx context.Context, tx pgx.Tx, params diygoapi.Authe
 			Expiry:       dbAuth.AuthProviderAccessTokenExpiry.Time},
 	}
 
-	// if token is no longer valid, return an error
-	if !auth.Token.Valid() {
+	// if token is no longer valid, return an error. An OIDC ID token
+	// always expires, one stored without an expiry is not trusted.
+	if !auth.Token.Valid() || (auth.Provider == diygoapi.OIDC && auth.Token.Expiry.IsZero()) {
 		return diygoapi.Auth{}, errs.E(op, errs.Unauthenticated, errs.Realm(params.Realm), "token is no longer valid")
 	}
 
 	return auth, nil
 }
 
+// providerToken returns the token given by the client with the expiry
+// given by the provider. The token is built from the Authorization
+// header, which carries no expiry, and a token without an expiry is
+// valid forever once stored.
+func providerToken(token *oauth2.Token, pi *diygoapi.ProviderInfo) *oauth2.Token {
+	if !token.Expiry.IsZero() || pi.TokenInfo == nil || pi.TokenInfo.Expiration.IsZero() {
+		return token
+	}
+
+	t := *token
+	t.Expiry = pi.TokenInfo.Expiration
+
+	return &t
+}
+
 type findAuthByProviderExternalIDParams struct {
 	Realm        string
 	ProviderInfo *diygoapi.ProviderInfo
@@ -197,6 +235,11 @@ func findAuthByProviderExternalID(ctx context.Context, tx pgx.Tx, params findAut
 		return diygoapi.Auth{}, errs.E(op, err)
 	}
 
+	// a suspended user cannot authenticate
+	if u.Suspended {
+		return diygoapi.Auth{}, errs.E(op, errs.Unauthenticated, errs.Realm(params.Realm), "user is suspended")
+	}
+
 	// populate Auth
 	auth := diygoapi.Auth{
 		ID:               dbAuth.AuthID,
@@ -204,7 +247,7 @@ func findAuthByProviderExternalID(ctx context.Context, tx pgx.Tx, params findAut
 		Provider:         params.ProviderInfo.Provider,
 		ProviderClientID: params.ProviderInfo.TokenInfo.ClientID,
 		ProviderPersonID: params.ProviderInfo.UserInfo.ExternalID,
-		Token:            params.Token,
+		Token:            providerToken(params.Token, params.ProviderInfo),
 	}
 
 	// if token is no longer valid, return an error
@@ -260,8 +303,12 @@ func (s DBAuthenticationService) FindAppByAPIKey(ctx context.Context, realm, app
 
 	var kr []datastore.FindAppAPIKeysByAppExtlIDRow
 
-	// retrieve the list of encrypted API keys from the database
-	kr, err = datastore.New(tx).FindAppAPIKeysByAppExtlID(ctx, appExtlID)
+	// retrieve the list of hashed API keys with a matching prefix from the database
+	findParams := datastore.FindAppAPIKeysByAppExtlIDParams{
+		AppExtlID:    appExtlID,
+		ApiKeyPrefix: diygoapi.APIKeyPrefix(key),
+	}
+	kr, err = datastore.New(tx).FindAppAPIKeysByAppExtlID(ctx, findParams)
 	if err != nil {
 		return nil, errs.E(op, errs.Unauthenticated, errs.Realm(realm), err)
 	}
@@ -273,8 +320,8 @@ func (s DBAuthenticationService) FindAppByAPIKey(ctx context.Context, realm, app
 
 	a = new(diygoapi.App)
 
-	// for each row, decrypt the API key using the encryption key,
-	// initialize an app.APIKey and set to a slice of API keys.
+	// for each row, initialize an app.APIKey from the stored hash
+	// and set to a slice of API keys.
 	for i, row := range kr {
 		if i == 0 { // only need to fill the app struct on first iteration
 			var extl secure.Identifier
@@ -293,7 +340,7 @@ func (s DBAuthenticationService) FindAppByAPIKey(ctx context.Context, realm, app
 			a.Name = row.AppName
 			a.Description = row.AppDescription
 		}
-		ak, err = diygoapi.NewAPIKeyFromCipher(row.ApiKey, s.EncryptionKey)
+		ak, err = diygoapi.NewAPIKeyFromHash(row.ApiKeyPrefix, row.ApiKey)
 		if err != nil {
 			return nil, errs.E(op, err)
 		}
@@ -304,145 +351,433 @@ func (s DBAuthenticationService) FindAppByAPIKey(ctx context.Context, realm, app
 
 	// ValidKey determines if any of the keys attached to the app
 	// match the input key and are still valid.
-	err = a.ValidateKey(realm, key)
+	pepper := diygoapi.APIKeyPepper(s.EncryptionKey)
+	err = a.ValidateKey(realm, key, pepper)
+	if err != nil {
+		return nil, errs.E(op, err)
+	}
+
+	// commit db txn using pgxpool
+	err = s.Datastorer.CommitTx(ctx, tx)
 	if err != nil {
 		return nil, errs.E(op, err)
 	}
 
+	// record the key as used, at most once per apiKeyLastUsedInterval
+	hash := hex.EncodeToString(secure.HMAC([]byte(key), pepper))
+	for _, row := range kr {
+		if row.ApiKey == hash {
+			recordAPIKeyUse(ctx, s.Datastorer, hash, row.LastUsedTimestamp)
+			break
+		}
+	}
+
 	return a, nil
 }
 
-// SelfRegister is used for first-time registration of a Person/User
-// in the system (associated with an Organization). This is "self
-// registration" as opposed to one person registering another person.
-//
-// SelfRegister creates an Auth object and a Person/User and stores
-// them in the database. A search is done prior to creation to
-// determine if user is already registered, and if so, the existing
-// user is returned.
-func (s DBAuthenticationService) SelfRegister(ctx context.Context, params diygoapi.AuthenticationParams) (auth diygoapi.Auth, err error) {
-	const op errs.Op = "service/DBAuthenticationService.SelfRegister"
+// apiKeyLastUsedInterval is the minimum time between two updates of
+// the last time an API key was used
+const apiKeyLastUsedInterval = 15 * time.Minute
+
+// recordAPIKeyUse updates the last time the API key with the given
+// hash was used, unless it was updated within apiKeyLastUsedInterval.
+// It is called once the key is validated and is best effort: failing
+// to record the use is logged and does not fail authentication.
+func recordAPIKeyUse(ctx context.Context, ds diygoapi.Datastorer, hash string, lastUsed sql.NullTime) {
+	now := time.Now()
+	if lastUsed.Valid && now.Sub(lastUsed.Time) < apiKeyLastUsedInterval {
+		return
+	}
+
+	err := updateAPIKeyLastUsed(ctx, ds, datastore.UpdateAppAPIKeyLastUsedParams{
+		LastUsedTimestamp: sql.NullTime{Time: now, Valid: true},
+		ApiKey:            hash,
+		// concurrent requests with the same key only update once
+		LastUsedBefore: sql.NullTime{Time: now.Add(-apiKeyLastUsedInterval), Valid: true},
+	})
+	if err != nil {
+		zerolog.Ctx(ctx).Warn().Err(err).Msg("API key last used timestamp not updated")
+	}
+}
+
+// updateAPIKeyLastUsed writes the last time an API key was used to the
+// datastore in its own transaction
+func updateAPIKeyLastUsed(ctx context.Context, ds diygoapi.Datastorer, p datastore.UpdateAppAPIKeyLastUsedParams) (err error) {
+	const op errs.Op = "service/updateAPIKeyLastUsed"
+
+	// start db txn using pgxpool
+	var tx pgx.Tx
+	tx, err = ds.BeginTx(ctx)
+	if err != nil {
+		return errs.E(op, err)
+	}
+	// defer transaction rollback and handle error, if any
+	defer func() {
+		err = ds.RollbackTx(ctx, tx, err)
+	}()
+
+	// no row is updated if another request already recorded the use
+	_, err = datastore.New(tx).UpdateAppAPIKeyLastUsed(ctx, p)
+	if err != nil {
+		return errs.E(op, errs.Database, err)
+	}
+
+	// commit db txn using pgxpool
+	err = ds.CommitTx(ctx, tx)
+	if err != nil {
+		return errs.E(op, err)
+	}
+
+	return nil
+}
+
+// FindUserOrg finds an Org given its External ID and determines if
+// the User is a member of it or of one of its ancestors. It is used to
+// validate the Org a User selects to act in for a request.
+func (s DBAuthenticationService) FindUserOrg(ctx context.Context, realm string, u *diygoapi.User, orgExtlID string) (o *diygoapi.Org, err error) {
+	const op errs.Op = "service/DBAuthenticationService.FindUserOrg"
 
 	// start db txn using pgxpool
 	var tx pgx.Tx
 	tx, err = s.Datastorer.BeginTx(ctx)
 	if err != nil {
-		return diygoapi.Auth{}, errs.E(op, err)
+		return nil, errs.E(op, err)
 	}
 	// defer transaction rollback and handle error, if any
 	defer func() {
 		err = s.Datastorer.RollbackTx(ctx, tx, err)
 	}()
 
-	var providerInfo *diygoapi.ProviderInfo
-	auth, err = findAuthByAccessToken(ctx, tx, params)
+	var row datastore.FindUserLineageOrgByExtlIDRow
+	row, err = datastore.New(tx).FindUserLineageOrgByExtlID(ctx, datastore.FindUserLineageOrgByExtlIDParams{OrgExtlID: orgExtlID, UserID: u.ID})
 	if err != nil {
-		// if error is something other than NotExist, then return error
-		if !errs.KindIs(errs.NotExist, err) {
-			return diygoapi.Auth{}, errs.E(op, err)
+		if errors.Is(err, pgx.ErrNoRows) {
+			// do not distinguish between an org which does not
+			// exist and one the user is not a member of
+			return nil, errs.E(op, errs.Unauthorized, fmt.Sprintf("User_extl_id %s is not a member of org %s", u.ExternalID.String(), orgExtlID))
 		}
+		return nil, errs.E(op, errs.Database, err)
+	}
 
-		// auth could not be found by access token in the db
-		// get ProviderInfo from provider API
-		providerInfo, err = s.TokenExchanger.Exchange(ctx, params.Realm, params.Provider, params.Token)
-		if err != nil {
-			return diygoapi.Auth{}, errs.E(op, err)
-		}
+	o = &diygoapi.Org{
+		ID:          row.OrgID,
+		ExternalID:  secure.MustParseIdentifier(row.OrgExtlID),
+		Name:        row.OrgName,
+		Description: row.OrgDescription,
+		Kind: &diygoapi.OrgKind{
+			ID:          row.OrgKindID,
+			ExternalID:  row.OrgKindExtlID,
+			Description: row.OrgKindDesc,
+		},
+	}
 
-		fParams := findAuthByProviderExternalIDParams{
-			Realm:        params.Realm,
-			ProviderInfo: providerInfo,
-			Token:        params.Token,
-		}
+	// commit db txn using pgxpool
+	err = s.Datastorer.CommitTx(ctx, tx)
+	if err != nil {
+		return nil, errs.E(op, err)
+	}
 
-		// we've gotten here, error kind is NotExist, so auth could not be found by
-		// access token. Try to find auth by Provider External ID
-		auth, err = findAuthByProviderExternalID(ctx, tx, fParams)
-		if err != nil {
-			// if error is something other than NotExist, then return error
-			if !errs.KindIs(errs.NotExist, err) {
-				return diygoapi.Auth{}, errs.E(op, err)
-			}
-		}
+	return o, nil
+}
+
+// Register registers the person authenticated by the provider as a new
+// User. The RegistrationPolicy is checked against the email given by
+// the provider and whether an invitation token is given. If an
+// invitation token is given, the org invitation is accepted for the
+// User. If requested, a personal Org of kind "standard" is created
+// and the User is made its orgAdmin.
+//
+// An error of kind Exist is returned if the person is already registered.
+func (s DBAuthenticationService) Register(ctx context.Context, params diygoapi.AuthenticationParams, r *diygoapi.RegisterUserRequest) (response *diygoapi.RegisterUserResponse, err error) {
+	const op errs.Op = "service/DBAuthenticationService.Register"
+
+	if r == nil {
+		r = &diygoapi.RegisterUserRequest{}
+	}
+
+	// start db txn using pgxpool
+	var tx pgx.Tx
+	tx, err = s.Datastorer.BeginTx(ctx)
+	if err != nil {
+		return nil, errs.E(op, err)
 	}
+	// defer transaction rollback and handle error, if any
+	defer func() {
+		err = s.Datastorer.RollbackTx(ctx, tx, err)
+	}()
 
-	// if auth still has not been found (we know this by checking if auth ID is nil)
-	// then create a new Auth for the User
-	if auth.ID == uuid.Nil {
-		var a *diygoapi.App
-		// check app from context first
-		a, _ = diygoapi.AppFromContext(ctx)
+	var (
+		auth         diygoapi.Auth
+		providerInfo *diygoapi.ProviderInfo
+	)
+	auth, providerInfo, err = s.findRegisteredAuth(ctx, tx, params)
+	if err != nil {
+		return nil, errs.E(op, err)
+	}
+	if auth.ID != uuid.Nil {
+		return nil, errs.E(op, errs.Exist, fmt.Sprintf("user is already registered (user_extl_id: %s)", auth.User.ExternalID.String()))
+	}
 
-		// if no app in context, get app from Provider
-		if a == nil {
-			a, err = findAppByProviderClientID(ctx, tx, providerInfo.TokenInfo.ClientID)
-			if err != nil {
-				if errs.KindIs(errs.NotExist, err) {
-					return diygoapi.Auth{}, errs.E(op, errs.NotExist, fmt.Sprintf("no app registered for Provider: %s, Client ID: %s", params.Provider.String(), providerInfo.TokenInfo.ClientID))
-				}
-				return diygoapi.Auth{}, errs.E(op, err)
-			}
-		}
+	err = s.RegistrationPolicy.Allow(providerInfo.UserInfo.Email, providerInfo.UserInfo.EmailVerified, params.InvitationToken != "")
+	if err != nil {
+		return nil, errs.E(op, err)
+	}
 
-		u := newUserFromProviderInfo(providerInfo, s.LanguageMatcher)
+	var adt diygoapi.Audit
+	auth, adt, err = s.createUserAuthTx(ctx, tx, params, providerInfo)
+	if err != nil {
+		return nil, errs.E(op, err)
+	}
 
-		err = u.Validate()
+	// accept the org invitation sent with the request, if any
+	if params.InvitationToken != "" {
+		err = s.acceptAuthInvitation(ctx, tx, params, auth, providerInfo)
 		if err != nil {
-			return diygoapi.Auth{}, errs.E(op, err)
+			return nil, errs.E(op, err)
 		}
+	}
 
-		p := diygoapi.Person{
-			ID:         uuid.New(),
-			ExternalID: secure.NewID(),
-			Users:      []*diygoapi.User{u},
-		}
+	sa := &diygoapi.SimpleAudit{Create: adt, Update: adt}
 
-		adt := diygoapi.Audit{
-			App:    a,
-			User:   u,
-			Moment: time.Now(),
-		}
+	response = &diygoapi.RegisterUserResponse{
+		User: newUserResponse(&userAudit{User: auth.User, SimpleAudit: sa}),
+	}
 
-		// write Person/User from request to the database
-		err = createPersonTx(ctx, tx, p, adt)
+	if r.CreateOrg {
+		var oa *orgAudit
+		oa, err = createPersonalOrgTx(ctx, tx, r, adt)
 		if err != nil {
-			return diygoapi.Auth{}, errs.E(op, err)
+			return nil, errs.E(op, err)
 		}
+		response.Org = newOrgResponse(oa, appAudit{})
+	}
 
-		// associate user to the app's org
-		aoaParams := attachOrgAssociationParams{
-			Org:   a.Org,
-			User:  u,
-			Audit: adt,
+	// commit db txn using pgxpool
+	err = s.Datastorer.CommitTx(ctx, tx)
+	if err != nil {
+		return nil, errs.E(op, err)
+	}
+
+	return response, nil
+}
+
+// findRegisteredAuth searches for the Auth of an already registered
+// User, first by access token, then by the unique ID given by the
+// provider. If the provider was called, the ProviderInfo is returned.
+// If no Auth is found, the returned Auth has a nil ID.
+func (s DBAuthenticationService) findRegisteredAuth(ctx context.Context, tx pgx.Tx, params diygoapi.AuthenticationParams) (diygoapi.Auth, *diygoapi.ProviderInfo, error) {
+	const op errs.Op = "service/DBAuthenticationService.findRegisteredAuth"
+
+	auth, err := findAuthByAccessToken(ctx, tx, params)
+	if err == nil {
+		return auth, nil, nil
+	}
+	// if error is something other than NotExist, then return error
+	if !errs.KindIs(errs.NotExist, err) {
+		return diygoapi.Auth{}, nil, errs.E(op, err)
+	}
+
+	// auth could not be found by access token in the db
+	// get ProviderInfo from provider API
+	var providerInfo *diygoapi.ProviderInfo
+	providerInfo, err = s.TokenExchanger.Exchange(ctx, params.Realm, params.Provider, params.Token)
+	if err != nil {
+		return diygoapi.Auth{}, nil, errs.E(op, err)
+	}
+
+	fParams := findAuthByProviderExternalIDParams{
+		Realm:        params.Realm,
+		ProviderInfo: providerInfo,
+		Token:        params.Token,
+	}
+
+	// we've gotten here, error kind is NotExist, so auth could not be found by
+	// access token. Try to find auth by Provider External ID
+	auth, err = findAuthByProviderExternalID(ctx, tx, fParams)
+	if err != nil {
+		// if error is something other than NotExist, then return error
+		if !errs.KindIs(errs.NotExist, err) {
+			return diygoapi.Auth{}, nil, errs.E(op, err)
 		}
-		err = attachOrgAssociation(ctx, tx, aoaParams)
+		return diygoapi.Auth{}, providerInfo, nil
+	}
+
+	return auth, providerInfo, nil
+}
+
+// createUserAuthTx creates a Person/User from the ProviderInfo along
+// with their Auth and associates the User to the org of the App. The
+// App is taken from the context, or else found using the provider
+// client ID. The Audit used for creation is returned as well.
+func (s DBAuthenticationService) createUserAuthTx(ctx context.Context, tx pgx.Tx, params diygoapi.AuthenticationParams, providerInfo *diygoapi.ProviderInfo) (diygoapi.Auth, diygoapi.Audit, error) {
+	const op errs.Op = "service/DBAuthenticationService.createUserAuthTx"
+
+	var err error
+
+	// check app from context first
+	a, _ := diygoapi.AppFromContext(ctx)
+
+	// if no app in context, get app from Provider
+	if a == nil {
+		a, err = findAppByProviderClientID(ctx, tx, providerInfo.TokenInfo.ClientID)
 		if err != nil {
-			return diygoapi.Auth{}, errs.E(op, err)
+			if errs.KindIs(errs.NotExist, err) {
+				return diygoapi.Auth{}, diygoapi.Audit{}, errs.E(op, errs.NotExist, fmt.Sprintf("no app registered for Provider: %s, Client ID: %s", params.Provider.String(), providerInfo.TokenInfo.ClientID))
+			}
+			return diygoapi.Auth{}, diygoapi.Audit{}, errs.E(op, err)
 		}
+	}
+
+	u := newUserFromProviderInfo(providerInfo, s.LanguageMatcher)
+
+	err = u.Validate()
+	if err != nil {
+		return diygoapi.Auth{}, diygoapi.Audit{}, errs.E(op, err)
+	}
+
+	p := diygoapi.Person{
+		ID:         uuid.New(),
+		ExternalID: secure.NewID(),
+		Users:      []*diygoapi.User{u},
+	}
+
+	adt := diygoapi.Audit{
+		App:    a,
+		User:   u,
+		Moment: time.Now(),
+	}
+
+	// write Person/User from request to the database
+	err = createPersonTx(ctx, tx, p, adt)
+	if err != nil {
+		return diygoapi.Auth{}, diygoapi.Audit{}, errs.E(op, err)
+	}
+
+	// associate user to the app's org
+	aoaParams := attachOrgAssociationParams{
+		Org:   a.Org,
+		User:  u,
+		Audit: adt,
+	}
+	err = attachOrgAssociation(ctx, tx, aoaParams)
+	if err != nil {
+		return diygoapi.Auth{}, diygoapi.Audit{}, errs.E(op, err)
+	}
+
+	auth := diygoapi.Auth{
+		ID:               uuid.New(),
+		User:             u,
+		Provider:         providerInfo.Provider,
+		ProviderClientID: providerInfo.TokenInfo.ClientID,
+		ProviderPersonID: providerInfo.UserInfo.ExternalID,
+		Token:            providerToken(params.Token, providerInfo),
+	}
+
+	err = createAuthTx(ctx, tx, createAuthTxParams{Auth: auth, Audit: adt})
+	if err != nil {
+		return diygoapi.Auth{}, diygoapi.Audit{}, errs.E(op, err)
+	}
+
+	return auth, adt, nil
+}
+
+// createPersonalOrgTx creates an Org of kind "standard" for the audit
+// User, associates the User to it and assigns them the orgAdmin role.
+// If no name is given, the email of the User is used as the Org name.
+func createPersonalOrgTx(ctx context.Context, tx pgx.Tx, r *diygoapi.RegisterUserRequest, adt diygoapi.Audit) (*orgAudit, error) {
+	const op errs.Op = "service/createPersonalOrgTx"
+
+	kind, err := findOrgKindByExtlID(ctx, tx, standardOrgKind)
+	if err != nil {
+		return nil, errs.E(op, err)
+	}
+
+	name := r.OrgName
+	if name == "" {
+		name = adt.User.Email
+	}
+	description := r.OrgDescription
+	if description == "" {
+		description = fmt.Sprintf("Personal organization of %s", adt.User.Email)
+	}
+
+	oa := &orgAudit{
+		Org: &diygoapi.Org{
+			ID:          uuid.New(),
+			ExternalID:  secure.NewID(),
+			Name:        name,
+			Description: description,
+			Kind:        kind,
+		},
+		SimpleAudit: &diygoapi.SimpleAudit{Create: adt, Update: adt},
+	}
+
+	err = createOrgTx(ctx, tx, oa)
+	if err != nil {
+		return nil, errs.E(op, err)
+	}
+
+	var role diygoapi.Role
+	role, err = findRoleByCode(ctx, tx, diygoapi.OrgAdminRoleCode)
+	if err != nil {
+		return nil, errs.E(op, err)
+	}
+
+	// the registered user administers their personal org, in addition
+	// to holding the default roles of the org kind
+	err = grantCreatorRoles(ctx, tx, oa.Org, adt, role)
+	if err != nil {
+		return nil, errs.E(op, err)
+	}
 
-		auth = diygoapi.Auth{
-			ID:               uuid.New(),
-			User:             u,
-			Provider:         providerInfo.Provider,
-			ProviderClientID: providerInfo.TokenInfo.ClientID,
-			ProviderPersonID: providerInfo.UserInfo.ExternalID,
-			Token:            params.Token,
+	return oa, nil
+}
+
+// acceptAuthInvitation accepts the org invitation sent with params on
+// behalf of the authenticated User. The email given by the provider is
+// matched against the invitation email and must have been verified by
+// the provider. When the provider was not called to authenticate the
+// User, it is called here, as the email stored for the User may not
+// have been verified.
+func (s DBAuthenticationService) acceptAuthInvitation(ctx context.Context, tx pgx.Tx, params diygoapi.AuthenticationParams, auth diygoapi.Auth, pi *diygoapi.ProviderInfo) error {
+	const op errs.Op = "service/DBAuthenticationService.acceptAuthInvitation"
+
+	var err error
+
+	if pi == nil {
+		pi, err = s.TokenExchanger.Exchange(ctx, params.Realm, params.Provider, params.Token)
+		if err != nil {
+			return errs.E(op, err)
 		}
+	}
 
-		err = createAuthTx(ctx, tx, createAuthTxParams{Auth: auth, Audit: adt})
+	// check app from context first, otherwise get app from Provider
+	a, _ := diygoapi.AppFromContext(ctx)
+	if a == nil {
+		a, err = findAppByProviderClientID(ctx, tx, auth.ProviderClientID)
 		if err != nil {
-			return diygoapi.Auth{}, errs.E(op, err)
+			return errs.E(op, err)
 		}
+	}
 
+	ip := acceptInvitationParams{
+		Token:         params.InvitationToken,
+		EncryptionKey: s.EncryptionKey,
+		Email:         pi.UserInfo.Email,
+		EmailVerified: pi.UserInfo.EmailVerified,
+		Audit: diygoapi.Audit{
+			App:    a,
+			User:   auth.User,
+			Moment: time.Now(),
+		},
 	}
 
-	// commit db txn using pgxpool
-	err = s.Datastorer.CommitTx(ctx, tx)
+	err = acceptInvitation(ctx, tx, ip)
 	if err != nil {
-		return diygoapi.Auth{}, errs.E(op, err)
+		return errs.E(op, err)
 	}
 
-	return auth, nil
+	return nil
 }
 
 // newUserFromProviderInfo creates a new User struct to be used in db user creation
@@ -518,6 +853,10 @@ func createAuthTx(ctx context.Context, tx pgx.Tx, params createAuthTxParams) (er
 // DBAuthorizationService manages authorization using the database.
 type DBAuthorizationService struct {
 	Datastorer diygoapi.Datastorer
+	// AllowSampleRate is the fraction (between 0 and 1) of allow
+	// decisions made by Authorize which are recorded. Deny decisions
+	// are always recorded.
+	AllowSampleRate float64
 }
 
 // Authorize ensures that a subject (User) can perform a
@@ -529,55 +868,50 @@ type DBAuthorizationService struct {
 //
 // Authorize implements Role Based Access Control (RBAC), in this case,
 // determining authorization for a user by running sql against tables
-// in the database
-func (s *DBAuthorizationService) Authorize(r *http.Request, lgr zerolog.Logger, adt diygoapi.Audit) (err error) {
+// in the database. Each decision is recorded in the database, allow
+// decisions are sampled using AllowSampleRate.
+func (s *DBAuthorizationService) Authorize(r *http.Request, lgr zerolog.Logger, adt diygoapi.Audit) error {
 	const op errs.Op = "service/DBAuthorizationService.Authorize"
 
 	ctx := r.Context()
 
-	// start db txn using pgxpool
-	var tx pgx.Tx
-	tx, err = s.Datastorer.BeginTx(ctx)
+	pathTemplate, err := routePathTemplate(r)
 	if err != nil {
 		return errs.E(op, err)
 	}
-	// defer transaction rollback and handle error, if any
-	defer func() {
-		err = s.Datastorer.RollbackTx(ctx, tx, err)
-	}()
-
-	// current matched route for the request
-	route := mux.CurrentRoute(r)
-
-	// CurrentRoute can return a nil if route not setup properly or
-	// is being called outside the handler of the matched route
-	if route == nil {
-		return errs.E(op, errs.Unauthorized, "nil route returned from mux.CurrentRoute")
-	}
-
-	var pathTemplate string
-	pathTemplate, err = route.GetPathTemplate()
-	if err != nil {
-		return errs.E(op, errs.Unauthorized, err)
-	}
 
 	arg := datastore.IsAuthorizedParams{
 		Resource:  pathTemplate,
 		Operation: r.Method,
 		UserID:    adt.User.ID,
-		// Set the Org using the org the audit app is associated to.
-		// The business assumption currently is that an app can
-		// only belong to one org.
-		OrgID: adt.App.Org.ID,
+		// Set the Org using the org the user is acting in, which is
+		// either the org selected for the request or the org the
+		// audit app is associated to.
+		OrgID: adt.ActingOrg().ID,
 	}
 
 	// call IsAuthorized method to validate user has access to the resource and operation
-	var authorizedID uuid.UUID
-	authorizedID, err = datastore.New(tx).IsAuthorized(r.Context(), arg)
-	if err != nil || authorizedID == uuid.Nil {
-		lgr.Info().Str("user_extl_id", adt.User.ExternalID.String()).Str("resource", pathTemplate).Str("operation", r.Method).
+	// permissions may be patterns, a matching denied permission is
+	// returned first, otherwise the most specific matching permission
+	var (
+		authorized datastore.IsAuthorizedRow
+		ok         bool
+	)
+	authorized, ok, err = s.decide(ctx, arg)
+	if err == nil {
+		recordDecision(ctx, lgr, s.Datastorer, s.AllowSampleRate, newAuthzDecisionParams(r, adt, pathTemplate, authorized.RoleCd, ok))
+	}
+	if err != nil || !ok {
+		lgr.Info().Str("user_extl_id", adt.User.ExternalID.String()).Str("org_extl_id", adt.ActingOrg().ExternalID.String()).
+			Str("resource", pathTemplate).Str("operation", r.Method).Str("denied_by_role_cd", deniedByRoleCode(authorized)).
 			Msgf("Unauthorized (user_extl_id: %s, resource: %s, operation: %s)", adt.User.ExternalID.String(), pathTemplate, r.Method)
 
+		// a permission explicitly denied takes precedence over any
+		// allow, the denying role and permission are given as reason
+		if authorized.Effect == diygoapi.PermissionEffectDeny {
+			return errs.E(op, errs.Unauthorized, fmt.Sprintf("User_extl_id %s is denied %s permission for %s by role %s (denied permission: %s %s)", adt.User.ExternalID.String(), r.Method, pathTemplate, authorized.RoleCd, authorized.Operation, authorized.Resource))
+		}
+
 		// "In summary, a 401 Unauthorized response should be used for missing or
 		// bad authentication, and a 403 Forbidden response should be used afterwards,
 		// when the user is authenticated but isn’t authorized to perform the
@@ -587,20 +921,231 @@ func (s *DBAuthorizationService) Authorize(r *http.Request, lgr zerolog.Logger,
 		return errs.E(op, errs.Unauthorized, fmt.Sprintf("User_extl_id %s does not have %s permission for %s", adt.User.ExternalID.String(), r.Method, pathTemplate))
 	}
 
-	lgr.Debug().Str("user_extl_id", adt.User.ExternalID.String()).Str("resource", pathTemplate).Str("operation", r.Method).
+	lgr.Debug().Str("user_extl_id", adt.User.ExternalID.String()).Str("org_extl_id", adt.ActingOrg().ExternalID.String()).
+		Str("resource", pathTemplate).Str("operation", r.Method).
+		Str("permission_resource", authorized.Resource).Str("permission_operation", authorized.Operation).
 		Msgf("Authorized (user_extl_id: %s, resource: %s, operation: %s)", adt.User.ExternalID.String(), pathTemplate, r.Method)
 
 	return nil
 }
 
-// PermissionService is a service for creating, reading, updating and deleting a Permission
-type PermissionService struct {
-	Datastorer diygoapi.Datastorer
-}
-
-// Create is used to create a Permission
-func (s *PermissionService) Create(ctx context.Context, r *diygoapi.CreatePermissionRequest, adt diygoapi.Audit) (response *diygoapi.PermissionResponse, err error) {
-	const op errs.Op = "service/PermissionService.Create"
+// decide determines whether the user is authorized for the resource
+// and operation. Its transaction is committed before the decision is
+// recorded, so a request never holds two connections at once.
+func (s *DBAuthorizationService) decide(ctx context.Context, arg datastore.IsAuthorizedParams) (authorized datastore.IsAuthorizedRow, ok bool, err error) {
+	const op errs.Op = "service/DBAuthorizationService.decide"
+
+	// start db txn using pgxpool
+	var tx pgx.Tx
+	tx, err = s.Datastorer.BeginTx(ctx)
+	if err != nil {
+		return datastore.IsAuthorizedRow{}, false, errs.E(op, err)
+	}
+	// defer transaction rollback and handle error, if any
+	defer func() {
+		err = s.Datastorer.RollbackTx(ctx, tx, err)
+	}()
+
+	authorized, ok, err = isAuthorized(ctx, tx, arg)
+	if err != nil {
+		return datastore.IsAuthorizedRow{}, false, errs.E(op, err)
+	}
+
+	// commit db txn using pgxpool
+	err = s.Datastorer.CommitTx(ctx, tx)
+	if err != nil {
+		return datastore.IsAuthorizedRow{}, false, errs.E(op, err)
+	}
+
+	return authorized, ok, nil
+}
+
+// routePathTemplate returns the path template of the route matched
+// for the request
+func routePathTemplate(r *http.Request) (string, error) {
+	const op errs.Op = "service/routePathTemplate"
+
+	// current matched route for the request
+	route := mux.CurrentRoute(r)
+
+	// CurrentRoute can return a nil if route not setup properly or
+	// is being called outside the handler of the matched route
+	if route == nil {
+		return "", errs.E(op, errs.Unauthorized, "nil route returned from mux.CurrentRoute")
+	}
+
+	pathTemplate, err := route.GetPathTemplate()
+	if err != nil {
+		return "", errs.E(op, errs.Unauthorized, err)
+	}
+
+	return pathTemplate, nil
+}
+
+// recordDecision persists an authorization decision. Allow decisions
+// are sampled using allowSampleRate. It must be called once the
+// decision's own transaction is done, the decision is written in a
+// separate transaction, so it is kept when the request is denied.
+// Failing to record a decision is logged and does not change the
+// decision.
+func recordDecision(ctx context.Context, lgr zerolog.Logger, ds diygoapi.Datastorer, allowSampleRate float64, p datastore.CreateAuthzDecisionParams) {
+	if p.Decision == diygoapi.AuthzDecisionAllow && rand.Float64() >= allowSampleRate {
+		return
+	}
+
+	err := createAuthzDecision(ctx, ds, p)
+	if err != nil {
+		lgr.Error().Err(err).Str("request_id", p.RequestID.String).Msg("authorization decision not recorded")
+	}
+}
+
+// createAuthzDecision writes an authorization decision to the datastore
+func createAuthzDecision(ctx context.Context, ds diygoapi.Datastorer, p datastore.CreateAuthzDecisionParams) (err error) {
+	const op errs.Op = "service/createAuthzDecision"
+
+	// start db txn using pgxpool
+	var tx pgx.Tx
+	tx, err = ds.BeginTx(ctx)
+	if err != nil {
+		return errs.E(op, err)
+	}
+	// defer transaction rollback and handle error, if any
+	defer func() {
+		err = ds.RollbackTx(ctx, tx, err)
+	}()
+
+	var rowsAffected int64
+	rowsAffected, err = datastore.New(tx).CreateAuthzDecision(ctx, p)
+	if err != nil {
+		return errs.E(op, errs.Database, err)
+	}
+
+	if rowsAffected != 1 {
+		return errs.E(op, errs.Database, fmt.Sprintf("rows affected should be 1, actual: %d", rowsAffected))
+	}
+
+	// commit db txn using pgxpool
+	err = ds.CommitTx(ctx, tx)
+	if err != nil {
+		return errs.E(op, err)
+	}
+
+	return nil
+}
+
+// newAuthzDecisionParams initializes the parameters to record the
+// authorization decision made for a request. roleCd is the role which
+// allowed the request or explicitly denied it, if any.
+func newAuthzDecisionParams(r *http.Request, adt diygoapi.Audit, pathTemplate, roleCd string, allowed bool) datastore.CreateAuthzDecisionParams {
+	p := datastore.CreateAuthzDecisionParams{
+		AuthzDecisionID:   uuid.New(),
+		UserID:            adt.User.ID,
+		AppID:             adt.App.ID,
+		OrgID:             adt.ActingOrg().ID,
+		Resource:          pathTemplate,
+		Operation:         r.Method,
+		Decision:          diygoapi.AuthzDecisionDeny,
+		RoleCd:            diygoapi.NewNullString(roleCd),
+		DecisionTimestamp: time.Now(),
+	}
+	if allowed {
+		p.Decision = diygoapi.AuthzDecisionAllow
+	}
+	if id, found := hlog.IDFromRequest(r); found {
+		p.RequestID = sql.NullString{String: id.String(), Valid: true}
+	}
+
+	return p
+}
+
+// FindDecisions searches the authorization decisions recorded for
+// the org the audit user is acting in, most recent first.
+func (s *DBAuthorizationService) FindDecisions(ctx context.Context, r *diygoapi.AuthzDecisionSearchRequest, adt diygoapi.Audit) ([]*diygoapi.AuthzDecisionResponse, error) {
+	const op errs.Op = "service/DBAuthorizationService.FindDecisions"
+
+	responses, err := findDecisions(ctx, s.Datastorer, r, adt)
+	if err != nil {
+		return nil, errs.E(op, err)
+	}
+
+	return responses, nil
+}
+
+// findDecisions searches the authorization decisions recorded for the
+// org the audit user is acting in, most recent first.
+func findDecisions(ctx context.Context, ds diygoapi.Datastorer, r *diygoapi.AuthzDecisionSearchRequest, adt diygoapi.Audit) (responses []*diygoapi.AuthzDecisionResponse, err error) {
+	const op errs.Op = "service/findDecisions"
+
+	err = r.Validate()
+	if err != nil {
+		return nil, errs.E(op, err)
+	}
+
+	// start db txn using pgxpool
+	var tx pgx.Tx
+	tx, err = ds.BeginTx(ctx)
+	if err != nil {
+		return nil, errs.E(op, err)
+	}
+	// defer transaction rollback and handle error, if any
+	defer func() {
+		err = ds.RollbackTx(ctx, tx, err)
+	}()
+
+	arg := datastore.FindAuthzDecisionsParams{
+		Decision:      diygoapi.NewNullString(r.Decision),
+		DecisionFrom:  r.From,
+		DecisionUntil: r.Until,
+		OrgID:         adt.ActingOrg().ID,
+		RowLimit:      int32(r.Limit),
+	}
+
+	if r.UserExternalID != "" {
+		var u *diygoapi.User
+		u, err = FindUserByExternalID(ctx, tx, r.UserExternalID)
+		if err != nil {
+			return nil, errs.E(op, err)
+		}
+		arg.UserID = u.NullUUID()
+	}
+
+	var rows []datastore.FindAuthzDecisionsRow
+	rows, err = datastore.New(tx).FindAuthzDecisions(ctx, arg)
+	if err != nil {
+		return nil, errs.E(op, errs.Database, err)
+	}
+
+	responses = make([]*diygoapi.AuthzDecisionResponse, 0, len(rows))
+	for _, row := range rows {
+		responses = append(responses, &diygoapi.AuthzDecisionResponse{
+			ID:             row.AuthzDecisionID.String(),
+			UserExternalID: row.UserExtlID.String,
+			AppExternalID:  row.AppExtlID.String,
+			OrgExternalID:  row.OrgExtlID.String,
+			Resource:       row.Resource,
+			Operation:      row.Operation,
+			Decision:       row.Decision,
+			RoleCode:       row.RoleCd.String,
+			RequestID:      row.RequestID.String,
+			Timestamp:      row.DecisionTimestamp,
+		})
+	}
+
+	// commit db txn using pgxpool
+	err = ds.CommitTx(ctx, tx)
+	if err != nil {
+		return nil, errs.E(op, err)
+	}
+
+	return responses, nil
+}
+
+// FindEffectivePermissions returns the permissions the audit user
+// holds in the org they are acting in, including those inherited through
+// the role hierarchy. Permission patterns are returned as is. A
+// permission denied by any role is returned with the deny effect.
+func (s *DBAuthorizationService) FindEffectivePermissions(ctx context.Context, adt diygoapi.Audit) (responses []*diygoapi.EffectivePermissionResponse, err error) {
+	const op errs.Op = "service/DBAuthorizationService.FindEffectivePermissions"
 
 	// start db txn using pgxpool
 	var tx pgx.Tx
@@ -613,11 +1158,80 @@ func (s *PermissionService) Create(ctx context.Context, r *diygoapi.CreatePermis
 		err = s.Datastorer.RollbackTx(ctx, tx, err)
 	}()
 
-	var p diygoapi.Permission
-	p, err = createPermissionTx(ctx, tx, r, adt)
+	var rows []datastore.FindEffectivePermissionsRow
+	rows, err = datastore.New(tx).FindEffectivePermissions(ctx, datastore.FindEffectivePermissionsParams{UserID: adt.User.ID, OrgID: adt.ActingOrg().ID})
+	if err != nil {
+		return nil, errs.E(op, errs.Database, err)
+	}
+
+	for _, row := range rows {
+		responses = append(responses, &diygoapi.EffectivePermissionResponse{
+			Resource:  row.Resource,
+			Operation: row.Operation,
+			RoleCode:  row.RoleCd,
+			Effect:    row.Effect,
+		})
+	}
+
+	// commit db txn using pgxpool
+	err = s.Datastorer.CommitTx(ctx, tx)
+	if err != nil {
+		return nil, errs.E(op, err)
+	}
+
+	return responses, nil
+}
+
+// Check determines, for each resource and operation in the request,
+// whether the audit user is authorized in the org they are acting in. It
+// uses the same rules as Authorize.
+func (s *DBAuthorizationService) Check(ctx context.Context, r *diygoapi.AuthorizationCheckRequest, adt diygoapi.Audit) (responses []*diygoapi.AuthorizationCheckResponse, err error) {
+	const op errs.Op = "service/DBAuthorizationService.Check"
+
+	err = r.Validate()
+	if err != nil {
+		return nil, errs.E(op, err)
+	}
+
+	// start db txn using pgxpool
+	var tx pgx.Tx
+	tx, err = s.Datastorer.BeginTx(ctx)
 	if err != nil {
 		return nil, errs.E(op, err)
 	}
+	// defer transaction rollback and handle error, if any
+	defer func() {
+		err = s.Datastorer.RollbackTx(ctx, tx, err)
+	}()
+
+	for _, c := range r.Checks {
+		arg := datastore.IsAuthorizedParams{
+			Resource:  c.Resource,
+			Operation: c.Operation,
+			UserID:    adt.User.ID,
+			OrgID:     adt.ActingOrg().ID,
+		}
+
+		var (
+			authorized datastore.IsAuthorizedRow
+			ok         bool
+		)
+		authorized, ok, err = isAuthorized(ctx, tx, arg)
+		if err != nil {
+			return nil, errs.E(op, err)
+		}
+
+		response := &diygoapi.AuthorizationCheckResponse{
+			Resource:         c.Resource,
+			Operation:        c.Operation,
+			Allowed:          ok,
+			DeniedByRoleCode: deniedByRoleCode(authorized),
+		}
+		if ok {
+			response.RoleCode = authorized.RoleCd
+		}
+		responses = append(responses, response)
+	}
 
 	// commit db txn using pgxpool
 	err = s.Datastorer.CommitTx(ctx, tx)
@@ -625,15 +1239,138 @@ func (s *PermissionService) Create(ctx context.Context, r *diygoapi.CreatePermis
 		return nil, errs.E(op, err)
 	}
 
-	response = &diygoapi.PermissionResponse{
-		ExternalID:  p.ExternalID.String(),
-		Resource:    p.Resource,
-		Operation:   p.Operation,
-		Description: p.Description,
-		Active:      p.Active,
+	return responses, nil
+}
+
+// isAuthorized runs the IsAuthorized query. If no permission grants
+// access or a permission explicitly denies it, ok is false and no
+// error is returned.
+func isAuthorized(ctx context.Context, tx pgx.Tx, arg datastore.IsAuthorizedParams) (row datastore.IsAuthorizedRow, ok bool, err error) {
+	const op errs.Op = "service/isAuthorized"
+
+	row, err = datastore.New(tx).IsAuthorized(ctx, arg)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return datastore.IsAuthorizedRow{}, false, nil
+		}
+		return datastore.IsAuthorizedRow{}, false, errs.E(op, errs.Database, err)
 	}
 
-	return response, nil
+	return row, row.UserID != uuid.Nil && row.Effect == diygoapi.PermissionEffectAllow, nil
+}
+
+// deniedByRoleCode returns the code of the role explicitly denying
+// access, if the permission returned by IsAuthorized is denied
+func deniedByRoleCode(row datastore.IsAuthorizedRow) string {
+	if row.Effect == diygoapi.PermissionEffectDeny {
+		return row.RoleCd
+	}
+	return ""
+}
+
+// authorizeOwner determines whether the audit user may modify a
+// resource given its owner. The user must have created the resource,
+// hold the orgAdmin or sysAdmin role (directly or through the role
+// hierarchy) in the owning org or hold the sysAdmin role in the
+// Principal org. Otherwise, an errs.Unauthorized error is returned.
+func authorizeOwner(ctx context.Context, tx pgx.Tx, adt diygoapi.Audit, owner diygoapi.Owner) error {
+	const op errs.Op = "service/authorizeOwner"
+
+	if adt.User == nil {
+		return errs.E(op, errs.Unauthorized, "a user is required to modify an owned resource")
+	}
+
+	if owner.CreatedBy(adt.User) {
+		return nil
+	}
+
+	arg := datastore.HasAnyOrgRoleParams{
+		UserID:  adt.User.ID,
+		OrgID:   owner.OrgID,
+		RoleCds: []string{diygoapi.OrgAdminRoleCode, diygoapi.SysAdminRoleCode},
+	}
+
+	admin, err := datastore.New(tx).HasAnyOrgRole(ctx, arg)
+	if err != nil {
+		return errs.E(op, errs.Database, err)
+	}
+	if admin {
+		return nil
+	}
+
+	// a sysAdmin in the Principal org administers every org
+	admin, err = isPrincipalSysAdmin(ctx, tx, adt.User)
+	if err != nil {
+		return errs.E(op, err)
+	}
+	if admin {
+		return nil
+	}
+
+	return errs.E(op, errs.Unauthorized, fmt.Sprintf("User_extl_id %s did not create the resource and is not an admin of the org which owns it", adt.User.ExternalID.String()))
+}
+
+// isPrincipalSysAdmin determines whether the User has the sysAdmin
+// role in the Principal org
+func isPrincipalSysAdmin(ctx context.Context, tx pgx.Tx, u *diygoapi.User) (bool, error) {
+	const op errs.Op = "service/isPrincipalSysAdmin"
+
+	principal, err := datastore.New(tx).FindOrgByName(ctx, PrincipalOrgName)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return false, nil
+		}
+		return false, errs.E(op, errs.Database, err)
+	}
+
+	arg := datastore.HasAnyOrgRoleParams{
+		UserID:  u.ID,
+		OrgID:   principal.OrgID,
+		RoleCds: []string{diygoapi.SysAdminRoleCode},
+	}
+
+	var admin bool
+	admin, err = datastore.New(tx).HasAnyOrgRole(ctx, arg)
+	if err != nil {
+		return false, errs.E(op, errs.Database, err)
+	}
+
+	return admin, nil
+}
+
+// PermissionService is a service for creating, reading, updating and deleting a Permission
+type PermissionService struct {
+	Datastorer diygoapi.Datastorer
+}
+
+// Create is used to create a Permission
+func (s *PermissionService) Create(ctx context.Context, r *diygoapi.CreatePermissionRequest, adt diygoapi.Audit) (response *diygoapi.PermissionResponse, err error) {
+	const op errs.Op = "service/PermissionService.Create"
+
+	// start db txn using pgxpool
+	var tx pgx.Tx
+	tx, err = s.Datastorer.BeginTx(ctx)
+	if err != nil {
+		return nil, errs.E(op, err)
+	}
+	// defer transaction rollback and handle error, if any
+	defer func() {
+		err = s.Datastorer.RollbackTx(ctx, tx, err)
+	}()
+
+	var p diygoapi.Permission
+	p, err = createPermissionTx(ctx, tx, r, adt)
+	if err != nil {
+		return nil, errs.E(op, err)
+	}
+
+	// commit db txn using pgxpool
+	err = s.Datastorer.CommitTx(ctx, tx)
+	if err != nil {
+		return nil, errs.E(op, err)
+	}
+
+	return newPermissionResponse(p), nil
 }
 
 // createPermissionTX separates the transaction logic as it needs to also be called during Genesis
@@ -649,50 +1386,1060 @@ func createPermissionTx(ctx context.Context, tx pgx.Tx, r *diygoapi.CreatePermis
 		Active:      r.Active,
 	}
 
-	err = p.Validate()
+	err = p.Validate()
+	if err != nil {
+		return diygoapi.Permission{}, errs.E(op, err)
+	}
+
+	arg := datastore.CreatePermissionParams{
+		PermissionID:          p.ID,
+		PermissionExtlID:      p.ExternalID.String(),
+		Resource:              p.Resource,
+		Operation:             p.Operation,
+		PermissionDescription: p.Description,
+		Active:                p.Active,
+		CreateAppID:           adt.App.ID,
+		CreateUserID:          adt.User.NullUUID(),
+		CreateTimestamp:       time.Now(),
+		UpdateAppID:           adt.App.ID,
+		UpdateUserID:          adt.User.NullUUID(),
+		UpdateTimestamp:       time.Now(),
+	}
+
+	var rowsAffected int64
+	rowsAffected, err = datastore.New(tx).CreatePermission(ctx, arg)
+	if err != nil {
+		var pgErr *pgconn.PgError
+		if errors.As(err, &pgErr) {
+			if pgErr.Code == "23505" {
+				return diygoapi.Permission{}, errs.E(errs.Exist, errs.Exist.String())
+			}
+			return diygoapi.Permission{}, errs.E(op, errs.Database, pgErr.Message)
+		}
+		return diygoapi.Permission{}, errs.E(op, errs.Database, err)
+	}
+
+	// should only impact exactly one record
+	if rowsAffected != 1 {
+		return diygoapi.Permission{}, errs.E(op, errs.Database, fmt.Sprintf("Create() should insert 1 row, actual: %d", rowsAffected))
+	}
+
+	return p, nil
+}
+
+// FindAll retrieves all permissions
+func (s *PermissionService) FindAll(ctx context.Context) (permissions []*diygoapi.PermissionResponse, err error) {
+	const op errs.Op = "service/PermissionService.FindAll"
+
+	// start db txn using pgxpool
+	var tx pgx.Tx
+	tx, err = s.Datastorer.BeginTx(ctx)
+	if err != nil {
+		return nil, errs.E(op, err)
+	}
+	// defer transaction rollback and handle error, if any
+	defer func() {
+		err = s.Datastorer.RollbackTx(ctx, tx, err)
+	}()
+
+	var rows []datastore.Permission
+	rows, err = datastore.New(tx).FindAllPermissions(ctx)
+	if err != nil {
+		return nil, errs.E(op, errs.Database, err)
+	}
+
+	var sp []*diygoapi.PermissionResponse
+	for _, row := range rows {
+		p := &diygoapi.PermissionResponse{
+			ExternalID:  row.PermissionExtlID,
+			Resource:    row.Resource,
+			Operation:   row.Operation,
+			Description: row.PermissionDescription,
+			Active:      row.Active,
+		}
+		sp = append(sp, p)
+	}
+
+	return sp, nil
+}
+
+// Delete is used to delete a Permission
+func (s *PermissionService) Delete(ctx context.Context, extlID string) (dr diygoapi.DeleteResponse, err error) {
+	const op errs.Op = "service/PermissionService.Delete"
+
+	// start db txn using pgxpool
+	var tx pgx.Tx
+	tx, err = s.Datastorer.BeginTx(ctx)
+	if err != nil {
+		return diygoapi.DeleteResponse{}, errs.E(op, err)
+	}
+	// defer transaction rollback and handle error, if any
+	defer func() {
+		err = s.Datastorer.RollbackTx(ctx, tx, err)
+	}()
+
+	var rowsAffected int64
+	rowsAffected, err = datastore.New(tx).DeletePermissionByExternalID(ctx, extlID)
+	if err != nil {
+		return diygoapi.DeleteResponse{}, errs.E(op, errs.Database, err)
+	}
+
+	if rowsAffected != 1 {
+		return diygoapi.DeleteResponse{}, errs.E(op, errs.Database, fmt.Sprintf("rows affected should be 1, actual: %d", rowsAffected))
+	}
+
+	// commit db txn using pgxpool
+	err = s.Datastorer.CommitTx(ctx, tx)
+	if err != nil {
+		return diygoapi.DeleteResponse{}, errs.E(op, err)
+	}
+
+	response := diygoapi.DeleteResponse{
+		ExternalID: extlID,
+		Deleted:    true,
+	}
+
+	return response, nil
+}
+
+// Sync reconciles the permissions in the datastore with the given
+// routes. When r.Upsert is true, an active permission is created for
+// each route which requires authorization, but has no matching
+// permission. Permissions are created using the Principal app for
+// auditing, so Genesis must have been run first. Created permissions
+// are not granted to any role.
+func (s *PermissionService) Sync(ctx context.Context, r *diygoapi.PermissionSyncRequest) (response *diygoapi.PermissionSyncResponse, err error) {
+	const op errs.Op = "service/PermissionService.Sync"
+
+	// start db txn using pgxpool
+	var tx pgx.Tx
+	tx, err = s.Datastorer.BeginTx(ctx)
+	if err != nil {
+		return nil, errs.E(op, err)
+	}
+	// defer transaction rollback and handle error, if any
+	defer func() {
+		err = s.Datastorer.RollbackTx(ctx, tx, err)
+	}()
+
+	var rows []datastore.Permission
+	rows, err = datastore.New(tx).FindAllPermissions(ctx)
+	if err != nil {
+		return nil, errs.E(op, errs.Database, err)
+	}
+
+	var permissions []diygoapi.Permission
+	for _, row := range rows {
+		permissions = append(permissions, *newPermission(row))
+	}
+
+	missing, orphaned := diygoapi.ReconcilePermissions(r.Routes, permissions)
+
+	response = &diygoapi.PermissionSyncResponse{Missing: missing}
+	for _, p := range orphaned {
+		response.Orphaned = append(response.Orphaned, newPermissionResponse(p))
+	}
+
+	if !r.Upsert || len(missing) == 0 {
+		return response, nil
+	}
+
+	var app *diygoapi.App
+	app, err = findPrincipalApp(ctx, tx)
+	if err != nil {
+		return nil, errs.E(op, err)
+	}
+	adt := diygoapi.Audit{App: app, User: &diygoapi.User{}, Moment: time.Now()}
+
+	for _, route := range missing {
+		cpr := &diygoapi.CreatePermissionRequest{
+			Resource:    route.Resource,
+			Operation:   route.Operation,
+			Description: fmt.Sprintf("allows for %s requests to %s", route.Operation, route.Resource),
+			Active:      true,
+		}
+
+		var p diygoapi.Permission
+		p, err = createPermissionTx(ctx, tx, cpr, adt)
+		if err != nil {
+			return nil, errs.E(op, err)
+		}
+		response.Created = append(response.Created, newPermissionResponse(p))
+	}
+
+	// commit db txn using pgxpool
+	err = s.Datastorer.CommitTx(ctx, tx)
+	if err != nil {
+		return nil, errs.E(op, err)
+	}
+
+	return response, nil
+}
+
+// findPrincipalApp finds the Principal app created during Genesis
+func findPrincipalApp(ctx context.Context, tx pgx.Tx) (*diygoapi.App, error) {
+	const op errs.Op = "service/findPrincipalApp"
+
+	org, err := datastore.New(tx).FindOrgByName(ctx, PrincipalOrgName)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, errs.E(op, errs.NotExist, "Principal org not found, Genesis must be run first")
+		}
+		return nil, errs.E(op, errs.Database, err)
+	}
+
+	var row datastore.FindAppByNameRow
+	row, err = datastore.New(tx).FindAppByName(ctx, datastore.FindAppByNameParams{OrgID: org.OrgID, AppName: PrincipalAppName})
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, errs.E(op, errs.NotExist, "Principal app not found, Genesis must be run first")
+		}
+		return nil, errs.E(op, errs.Database, err)
+	}
+
+	return &diygoapi.App{
+		ID:         row.AppID,
+		ExternalID: secure.MustParseIdentifier(row.AppExtlID),
+		Name:       row.AppName,
+	}, nil
+}
+
+// newPermissionResponse initializes a PermissionResponse given a Permission
+func newPermissionResponse(p diygoapi.Permission) *diygoapi.PermissionResponse {
+	return &diygoapi.PermissionResponse{
+		ExternalID:  p.ExternalID.String(),
+		Resource:    p.Resource,
+		Operation:   p.Operation,
+		Description: p.Description,
+		Active:      p.Active,
+	}
+}
+
+// newPermission initializes a Permission given a datastore.Permission
+func newPermission(ap datastore.Permission) *diygoapi.Permission {
+	return &diygoapi.Permission{
+		ID:          ap.PermissionID,
+		ExternalID:  secure.MustParseIdentifier(ap.PermissionExtlID),
+		Resource:    ap.Resource,
+		Operation:   ap.Operation,
+		Description: ap.PermissionDescription,
+		Active:      ap.Active,
+	}
+}
+
+// RoleService is a service for creating, reading, updating and deleting a Role
+type RoleService struct {
+	Datastorer diygoapi.Datastorer
+}
+
+// Create is used to create a Role
+func (s *RoleService) Create(ctx context.Context, r *diygoapi.CreateRoleRequest, adt diygoapi.Audit) (response *diygoapi.RoleResponse, err error) {
+	const op errs.Op = "service/RoleService.Create"
+
+	// start db txn using pgxpool
+	var tx pgx.Tx
+	tx, err = s.Datastorer.BeginTx(ctx)
+	if err != nil {
+		return nil, errs.E(op, err)
+	}
+	// defer transaction rollback and handle error, if any
+	defer func() {
+		err = s.Datastorer.RollbackTx(ctx, tx, err)
+	}()
+
+	var rolePermissions []*diygoapi.Permission
+	rolePermissions, err = findPermissions(ctx, tx, r.Permissions)
+	if err != nil {
+		return nil, errs.E(op, err)
+	}
+
+	var deniedPermissions []*diygoapi.Permission
+	deniedPermissions, err = findPermissions(ctx, tx, r.DeniedPermissions)
+	if err != nil {
+		return nil, errs.E(op, err)
+	}
+
+	var parents []*diygoapi.Role
+	for _, code := range r.ParentRoles {
+		var parent diygoapi.Role
+		parent, err = findRoleByCode(ctx, tx, code)
+		if err != nil {
+			return nil, errs.E(op, err)
+		}
+		parents = append(parents, &parent)
+	}
+
+	role := diygoapi.Role{
+		ID:                uuid.New(),
+		ExternalID:        secure.NewID(),
+		Code:              r.Code,
+		Description:       r.Description,
+		Active:            r.Active,
+		Permissions:       rolePermissions,
+		DeniedPermissions: deniedPermissions,
+		Parents:           parents,
+	}
+
+	err = createRoleTx(ctx, tx, role, adt)
+	if err != nil {
+		return nil, errs.E(op, err)
+	}
+
+	// commit db txn using pgxpool
+	err = s.Datastorer.CommitTx(ctx, tx)
+	if err != nil {
+		return nil, errs.E(op, err)
+	}
+
+	return newRoleResponse(role), nil
+}
+
+// createRoleTx creates the role in the database
+func createRoleTx(ctx context.Context, tx pgx.Tx, role diygoapi.Role, adt diygoapi.Audit) (err error) {
+	const op errs.Op = "service/createRoleTx"
+
+	err = role.Validate()
+	if err != nil {
+		return errs.E(op, err)
+	}
+
+	arg := datastore.CreateRoleParams{
+		RoleID:          role.ID,
+		RoleExtlID:      role.ExternalID.String(),
+		RoleCd:          role.Code,
+		RoleDescription: role.Description,
+		Active:          role.Active,
+		CreateAppID:     adt.App.ID,
+		CreateUserID:    adt.User.NullUUID(),
+		CreateTimestamp: adt.Moment,
+		UpdateAppID:     adt.App.ID,
+		UpdateUserID:    adt.User.NullUUID(),
+		UpdateTimestamp: adt.Moment,
+	}
+
+	var rowsAffected int64
+	rowsAffected, err = datastore.New(tx).CreateRole(ctx, arg)
+	if err != nil {
+		return errs.E(op, errs.Database, err)
+	}
+
+	// should only impact exactly one record
+	if rowsAffected != 1 {
+		return errs.E(op, errs.Database, fmt.Sprintf("Create() should insert 1 row, actual: %d", rowsAffected))
+	}
+
+	err = UpdateRolePermissions(ctx, tx, UpdateRolePermissionsParams{Role: role, Audit: adt})
+	if err != nil {
+		return errs.E(op, err)
+	}
+
+	for _, parent := range role.Parents {
+		err = createRoleParentTx(ctx, tx, role, *parent, adt)
+		if err != nil {
+			return errs.E(op, err)
+		}
+	}
+
+	return nil
+}
+
+// createRoleParentTx makes parent a parent of role, so that role
+// inherits all of parent's permissions. A parent which would make
+// the role hierarchy cyclic is rejected.
+func createRoleParentTx(ctx context.Context, tx pgx.Tx, role, parent diygoapi.Role, adt diygoapi.Audit) error {
+	const op errs.Op = "service/createRoleParentTx"
+
+	if parent.ID == role.ID {
+		return errs.E(op, errs.Validation, fmt.Sprintf("role %s cannot be its own parent", role.Code))
+	}
+
+	// if the role is already an ancestor of the parent, adding the
+	// parent would create a cycle
+	ancestors, err := datastore.New(tx).FindRoleAncestorIDs(ctx, parent.ID)
+	if err != nil {
+		return errs.E(op, errs.Database, err)
+	}
+	for _, id := range ancestors {
+		if id == role.ID {
+			return errs.E(op, errs.Validation, fmt.Sprintf("role %s inherits from role %s, making it a parent would create a cycle", parent.Code, role.Code))
+		}
+	}
+
+	params := datastore.CreateRoleParentParams{
+		RoleID:          role.ID,
+		ParentRoleID:    parent.ID,
+		CreateAppID:     adt.App.ID,
+		CreateUserID:    adt.User.NullUUID(),
+		CreateTimestamp: adt.Moment,
+		UpdateAppID:     adt.App.ID,
+		UpdateUserID:    adt.User.NullUUID(),
+		UpdateTimestamp: adt.Moment,
+	}
+
+	var rowsAffected int64
+	rowsAffected, err = datastore.New(tx).CreateRoleParent(ctx, params)
+	if err != nil {
+		return errs.E(op, errs.Database, err)
+	}
+
+	// should only impact exactly one record
+	if rowsAffected != 1 {
+		return errs.E(op, errs.Database, fmt.Sprintf("CreateRoleParent() should insert 1 row, actual: %d", rowsAffected))
+	}
+
+	return nil
+}
+
+// Update is used to update a Role's code, description and active flag
+func (s *RoleService) Update(ctx context.Context, r *diygoapi.UpdateRoleRequest, adt diygoapi.Audit) (response *diygoapi.RoleResponse, err error) {
+	const op errs.Op = "service/RoleService.Update"
+
+	// start db txn using pgxpool
+	var tx pgx.Tx
+	tx, err = s.Datastorer.BeginTx(ctx)
+	if err != nil {
+		return nil, errs.E(op, err)
+	}
+	// defer transaction rollback and handle error, if any
+	defer func() {
+		err = s.Datastorer.RollbackTx(ctx, tx, err)
+	}()
+
+	// retrieve existing Role
+	var role diygoapi.Role
+	role, err = findRoleByExternalID(ctx, tx, r.ExternalID)
+	if err != nil {
+		return nil, errs.E(op, err)
+	}
+
+	// override fields with data from request
+	role.Code = r.Code
+	role.Description = r.Description
+	role.Active = r.Active
+
+	err = role.Validate()
+	if err != nil {
+		return nil, errs.E(op, err)
+	}
+
+	params := datastore.UpdateRoleParams{
+		RoleCd:          role.Code,
+		RoleDescription: role.Description,
+		Active:          role.Active,
+		UpdateAppID:     adt.App.ID,
+		UpdateUserID:    adt.User.NullUUID(),
+		UpdateTimestamp: adt.Moment,
+		RoleID:          role.ID,
+	}
+
+	var rowsAffected int64
+	rowsAffected, err = datastore.New(tx).UpdateRole(ctx, params)
+	if err != nil {
+		var pgErr *pgconn.PgError
+		if errors.As(err, &pgErr) && pgErr.Code == "23505" {
+			return nil, errs.E(op, errs.Exist, "a role already exists for the given role code")
+		}
+		return nil, errs.E(op, errs.Database, err)
+	}
+
+	// update should only update exactly one record
+	if rowsAffected != 1 {
+		return nil, errs.E(op, errs.Database, fmt.Sprintf("UpdateRole() should update 1 row, actual: %d", rowsAffected))
+	}
+
+	// commit db txn using pgxpool
+	err = s.Datastorer.CommitTx(ctx, tx)
+	if err != nil {
+		return nil, errs.E(op, err)
+	}
+
+	return newRoleResponse(role), nil
+}
+
+// Delete is used to delete a Role. A role which is still assigned
+// to users cannot be deleted.
+func (s *RoleService) Delete(ctx context.Context, extlID string) (dr diygoapi.DeleteResponse, err error) {
+	const op errs.Op = "service/RoleService.Delete"
+
+	// start db txn using pgxpool
+	var tx pgx.Tx
+	tx, err = s.Datastorer.BeginTx(ctx)
+	if err != nil {
+		return diygoapi.DeleteResponse{}, errs.E(op, err)
+	}
+	// defer transaction rollback and handle error, if any
+	defer func() {
+		err = s.Datastorer.RollbackTx(ctx, tx, err)
+	}()
+
+	// retrieve existing Role
+	var dbRole datastore.Role
+	dbRole, err = datastore.New(tx).FindRoleByExternalID(ctx, extlID)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return diygoapi.DeleteResponse{}, errs.E(op, errs.NotExist, "No role exists for the given external ID")
+		}
+		return diygoapi.DeleteResponse{}, errs.E(op, errs.Database, err)
+	}
+
+	var assigned int64
+	assigned, err = datastore.New(tx).CountUsersRolesByRoleID(ctx, dbRole.RoleID)
+	if err != nil {
+		return diygoapi.DeleteResponse{}, errs.E(op, errs.Database, err)
+	}
+	if assigned > 0 {
+		return diygoapi.DeleteResponse{}, errs.E(op, errs.Validation, fmt.Sprintf("role %s is assigned to %d user(s) and cannot be deleted", dbRole.RoleCd, assigned))
+	}
+
+	var children int64
+	children, err = datastore.New(tx).CountRoleChildren(ctx, dbRole.RoleID)
+	if err != nil {
+		return diygoapi.DeleteResponse{}, errs.E(op, errs.Database, err)
+	}
+	if children > 0 {
+		return diygoapi.DeleteResponse{}, errs.E(op, errs.Validation, fmt.Sprintf("role %s is inherited by %d role(s) and cannot be deleted", dbRole.RoleCd, children))
+	}
+
+	var kinds int64
+	kinds, err = datastore.New(tx).CountOrgKindRolesByRoleID(ctx, dbRole.RoleID)
+	if err != nil {
+		return diygoapi.DeleteResponse{}, errs.E(op, errs.Database, err)
+	}
+	if kinds > 0 {
+		return diygoapi.DeleteResponse{}, errs.E(op, errs.Validation, fmt.Sprintf("role %s is a default role of %d org kind(s) and cannot be deleted", dbRole.RoleCd, kinds))
+	}
+
+	_, err = datastore.New(tx).DeleteAllPermissions4Role(ctx, dbRole.RoleID)
+	if err != nil {
+		return diygoapi.DeleteResponse{}, errs.E(op, errs.Database, err)
+	}
+
+	_, err = datastore.New(tx).DeleteAllParents4Role(ctx, dbRole.RoleID)
+	if err != nil {
+		return diygoapi.DeleteResponse{}, errs.E(op, errs.Database, err)
+	}
+
+	var rowsAffected int64
+	rowsAffected, err = datastore.New(tx).DeleteRole(ctx, dbRole.RoleID)
+	if err != nil {
+		return diygoapi.DeleteResponse{}, errs.E(op, errs.Database, err)
+	}
+
+	if rowsAffected != 1 {
+		return diygoapi.DeleteResponse{}, errs.E(op, errs.Database, fmt.Sprintf("rows affected should be 1, actual: %d", rowsAffected))
+	}
+
+	// commit db txn using pgxpool
+	err = s.Datastorer.CommitTx(ctx, tx)
+	if err != nil {
+		return diygoapi.DeleteResponse{}, errs.E(op, err)
+	}
+
+	response := diygoapi.DeleteResponse{
+		ExternalID: extlID,
+		Deleted:    true,
+	}
+
+	return response, nil
+}
+
+// FindAll retrieves all roles and their permissions
+func (s *RoleService) FindAll(ctx context.Context) (responses []*diygoapi.RoleResponse, err error) {
+	const op errs.Op = "service/RoleService.FindAll"
+
+	// start db txn using pgxpool
+	var tx pgx.Tx
+	tx, err = s.Datastorer.BeginTx(ctx)
+	if err != nil {
+		return nil, errs.E(op, err)
+	}
+	// defer transaction rollback and handle error, if any
+	defer func() {
+		err = s.Datastorer.RollbackTx(ctx, tx, err)
+	}()
+
+	var rows []datastore.Role
+	rows, err = datastore.New(tx).FindAllRoles(ctx)
+	if err != nil {
+		return nil, errs.E(op, errs.Database, err)
+	}
+
+	for _, row := range rows {
+		var role diygoapi.Role
+		role, err = newRole(ctx, tx, row)
+		if err != nil {
+			return nil, errs.E(op, err)
+		}
+		responses = append(responses, newRoleResponse(role))
+	}
+
+	// commit db txn using pgxpool
+	err = s.Datastorer.CommitTx(ctx, tx)
+	if err != nil {
+		return nil, errs.E(op, err)
+	}
+
+	return responses, nil
+}
+
+// FindByExternalID retrieves a role and its permissions given its external ID
+func (s *RoleService) FindByExternalID(ctx context.Context, extlID string) (response *diygoapi.RoleResponse, err error) {
+	const op errs.Op = "service/RoleService.FindByExternalID"
+
+	// start db txn using pgxpool
+	var tx pgx.Tx
+	tx, err = s.Datastorer.BeginTx(ctx)
+	if err != nil {
+		return nil, errs.E(op, err)
+	}
+	// defer transaction rollback and handle error, if any
+	defer func() {
+		err = s.Datastorer.RollbackTx(ctx, tx, err)
+	}()
+
+	var role diygoapi.Role
+	role, err = findRoleByExternalID(ctx, tx, extlID)
+	if err != nil {
+		return nil, errs.E(op, err)
+	}
+
+	// commit db txn using pgxpool
+	err = s.Datastorer.CommitTx(ctx, tx)
+	if err != nil {
+		return nil, errs.E(op, err)
+	}
+
+	return newRoleResponse(role), nil
+}
+
+// AddPermissions adds the requested permissions to a Role with the
+// requested effect. Permissions the role already has with that effect
+// are ignored, those it has with the other effect are switched.
+func (s *RoleService) AddPermissions(ctx context.Context, r *diygoapi.RolePermissionsRequest, adt diygoapi.Audit) (response *diygoapi.RoleResponse, err error) {
+	const op errs.Op = "service/RoleService.AddPermissions"
+
+	err = r.Validate()
+	if err != nil {
+		return nil, errs.E(op, err)
+	}
+
+	// start db txn using pgxpool
+	var tx pgx.Tx
+	tx, err = s.Datastorer.BeginTx(ctx)
+	if err != nil {
+		return nil, errs.E(op, err)
+	}
+	// defer transaction rollback and handle error, if any
+	defer func() {
+		err = s.Datastorer.RollbackTx(ctx, tx, err)
+	}()
+
+	var role diygoapi.Role
+	role, err = findRoleByExternalID(ctx, tx, r.RoleExternalID)
+	if err != nil {
+		return nil, errs.E(op, err)
+	}
+
+	var add []*diygoapi.Permission
+	add, err = findPermissions(ctx, tx, r.Permissions)
+	if err != nil {
+		return nil, errs.E(op, err)
+	}
+
+	if r.Effect == diygoapi.PermissionEffectDeny {
+		role.Permissions = withoutPermissions(role.Permissions, add)
+		for _, p := range add {
+			if !hasPermission(role.DeniedPermissions, p) {
+				role.DeniedPermissions = append(role.DeniedPermissions, p)
+			}
+		}
+	} else {
+		role.DeniedPermissions = withoutPermissions(role.DeniedPermissions, add)
+		for _, p := range add {
+			if !hasPermission(role.Permissions, p) {
+				role.Permissions = append(role.Permissions, p)
+			}
+		}
+	}
+
+	err = UpdateRolePermissions(ctx, tx, UpdateRolePermissionsParams{Role: role, Audit: adt})
+	if err != nil {
+		return nil, errs.E(op, err)
+	}
+
+	// commit db txn using pgxpool
+	err = s.Datastorer.CommitTx(ctx, tx)
+	if err != nil {
+		return nil, errs.E(op, err)
+	}
+
+	return newRoleResponse(role), nil
+}
+
+// RemovePermissions removes the requested permissions from a Role,
+// whether they are allowed or denied. Permissions the role does not
+// have are ignored.
+func (s *RoleService) RemovePermissions(ctx context.Context, r *diygoapi.RolePermissionsRequest, adt diygoapi.Audit) (response *diygoapi.RoleResponse, err error) {
+	const op errs.Op = "service/RoleService.RemovePermissions"
+
+	err = r.Validate()
+	if err != nil {
+		return nil, errs.E(op, err)
+	}
+
+	// start db txn using pgxpool
+	var tx pgx.Tx
+	tx, err = s.Datastorer.BeginTx(ctx)
+	if err != nil {
+		return nil, errs.E(op, err)
+	}
+	// defer transaction rollback and handle error, if any
+	defer func() {
+		err = s.Datastorer.RollbackTx(ctx, tx, err)
+	}()
+
+	var role diygoapi.Role
+	role, err = findRoleByExternalID(ctx, tx, r.RoleExternalID)
+	if err != nil {
+		return nil, errs.E(op, err)
+	}
+
+	var remove []*diygoapi.Permission
+	remove, err = findPermissions(ctx, tx, r.Permissions)
+	if err != nil {
+		return nil, errs.E(op, err)
+	}
+
+	role.Permissions = withoutPermissions(role.Permissions, remove)
+	role.DeniedPermissions = withoutPermissions(role.DeniedPermissions, remove)
+
+	err = UpdateRolePermissions(ctx, tx, UpdateRolePermissionsParams{Role: role, Audit: adt})
+	if err != nil {
+		return nil, errs.E(op, err)
+	}
+
+	// commit db txn using pgxpool
+	err = s.Datastorer.CommitTx(ctx, tx)
+	if err != nil {
+		return nil, errs.E(op, err)
+	}
+
+	return newRoleResponse(role), nil
+}
+
+// AddParent makes the requested role a parent of the Role, so that the
+// Role inherits all of its permissions.
+func (s *RoleService) AddParent(ctx context.Context, r *diygoapi.RoleParentRequest, adt diygoapi.Audit) (response *diygoapi.RoleResponse, err error) {
+	const op errs.Op = "service/RoleService.AddParent"
+
+	// start db txn using pgxpool
+	var tx pgx.Tx
+	tx, err = s.Datastorer.BeginTx(ctx)
+	if err != nil {
+		return nil, errs.E(op, err)
+	}
+	// defer transaction rollback and handle error, if any
+	defer func() {
+		err = s.Datastorer.RollbackTx(ctx, tx, err)
+	}()
+
+	var role diygoapi.Role
+	role, err = findRoleByExternalID(ctx, tx, r.RoleExternalID)
+	if err != nil {
+		return nil, errs.E(op, err)
+	}
+
+	var parent diygoapi.Role
+	parent, err = findRoleByCode(ctx, tx, r.ParentRoleCode)
+	if err != nil {
+		return nil, errs.E(op, err)
+	}
+
+	if !hasParent(role, parent) {
+		err = createRoleParentTx(ctx, tx, role, parent, adt)
+		if err != nil {
+			return nil, errs.E(op, err)
+		}
+		role.Parents = append(role.Parents, &parent)
+	}
+
+	// commit db txn using pgxpool
+	err = s.Datastorer.CommitTx(ctx, tx)
+	if err != nil {
+		return nil, errs.E(op, err)
+	}
+
+	return newRoleResponse(role), nil
+}
+
+// RemoveParent removes the requested parent role from the Role. The
+// Role no longer inherits the parent's permissions.
+func (s *RoleService) RemoveParent(ctx context.Context, r *diygoapi.RoleParentRequest) (response *diygoapi.RoleResponse, err error) {
+	const op errs.Op = "service/RoleService.RemoveParent"
+
+	// start db txn using pgxpool
+	var tx pgx.Tx
+	tx, err = s.Datastorer.BeginTx(ctx)
+	if err != nil {
+		return nil, errs.E(op, err)
+	}
+	// defer transaction rollback and handle error, if any
+	defer func() {
+		err = s.Datastorer.RollbackTx(ctx, tx, err)
+	}()
+
+	var role diygoapi.Role
+	role, err = findRoleByExternalID(ctx, tx, r.RoleExternalID)
+	if err != nil {
+		return nil, errs.E(op, err)
+	}
+
+	var parent diygoapi.Role
+	parent, err = findRoleByCode(ctx, tx, r.ParentRoleCode)
+	if err != nil {
+		return nil, errs.E(op, err)
+	}
+
+	var rowsAffected int64
+	rowsAffected, err = datastore.New(tx).DeleteRoleParent(ctx, datastore.DeleteRoleParentParams{RoleID: role.ID, ParentRoleID: parent.ID})
+	if err != nil {
+		return nil, errs.E(op, errs.Database, err)
+	}
+
+	if rowsAffected == 0 {
+		return nil, errs.E(op, errs.NotExist, fmt.Sprintf("role %s does not inherit from role %s", role.Code, parent.Code))
+	}
+
+	var parents []*diygoapi.Role
+	for _, p := range role.Parents {
+		if p.ID != parent.ID {
+			parents = append(parents, p)
+		}
+	}
+	role.Parents = parents
+
+	// commit db txn using pgxpool
+	err = s.Datastorer.CommitTx(ctx, tx)
+	if err != nil {
+		return nil, errs.E(op, err)
+	}
+
+	return newRoleResponse(role), nil
+}
+
+// hasParent reports whether parent is a direct parent of role
+func hasParent(role, parent diygoapi.Role) bool {
+	for _, p := range role.Parents {
+		if p.ID == parent.ID {
+			return true
+		}
+	}
+	return false
+}
+
+// AssignUserRole assigns a role to a user within an organization. The
+// grant can be bounded by an optional validity window. Assigning a role
+// the user already has in the organization replaces the grant's window.
+func (s *RoleService) AssignUserRole(ctx context.Context, r *diygoapi.UserRoleRequest, adt diygoapi.Audit) (response *diygoapi.UserRoleResponse, err error) {
+	const op errs.Op = "service/RoleService.AssignUserRole"
+
+	err = r.Validate()
+	if err != nil {
+		return nil, errs.E(op, err)
+	}
+
+	// start db txn using pgxpool
+	var tx pgx.Tx
+	tx, err = s.Datastorer.BeginTx(ctx)
+	if err != nil {
+		return nil, errs.E(op, err)
+	}
+	// defer transaction rollback and handle error, if any
+	defer func() {
+		err = s.Datastorer.RollbackTx(ctx, tx, err)
+	}()
+
+	var urp userRoleParams
+	urp, err = findUserRoleParams(ctx, tx, r)
+	if err != nil {
+		return nil, errs.E(op, err)
+	}
+
+	err = authorizeUserRoleGrant(ctx, tx, adt, urp)
+	if err != nil {
+		return nil, errs.E(op, err)
+	}
+
+	if urp.User.ID == adt.User.ID {
+		return nil, errs.E(op, errs.Unauthorized, "users cannot assign roles to themselves")
+	}
+
+	// roles are only assigned to members of the org
+	_, err = datastore.New(tx).FindUserOrgByExtlID(ctx, datastore.FindUserOrgByExtlIDParams{OrgExtlID: r.OrgExternalID, UserID: urp.User.ID})
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, errs.E(op, errs.Validation, fmt.Sprintf("user %s is not a member of org %s", r.UserExternalID, r.OrgExternalID))
+		}
+		return nil, errs.E(op, errs.Database, err)
+	}
+
+	var assigned bool
+	assigned, err = hasOrgRole(ctx, tx, urp)
+	if err != nil {
+		return nil, errs.E(op, err)
+	}
+
+	if assigned {
+		err = updateOrgRoleValidity(ctx, tx, urp, r.ValidFrom, r.ValidUntil, adt)
+	} else {
+		err = assignOrgRole(ctx, tx, assignOrgRoleParams{
+			Role:       urp.Role,
+			User:       urp.User,
+			Org:        &urp.Org,
+			ValidFrom:  r.ValidFrom,
+			ValidUntil: r.ValidUntil,
+			Audit:      adt,
+		})
+	}
+	if err != nil {
+		return nil, errs.E(op, err)
+	}
+
+	// commit db txn using pgxpool
+	err = s.Datastorer.CommitTx(ctx, tx)
+	if err != nil {
+		return nil, errs.E(op, err)
+	}
+
+	response = newUserRoleResponse(urp.Org, urp.User, urp.Role)
+	response.ValidFrom = r.ValidFrom
+	response.ValidUntil = r.ValidUntil
+
+	return response, nil
+}
+
+// RevokeUserRole removes a role from a user within an organization.
+func (s *RoleService) RevokeUserRole(ctx context.Context, r *diygoapi.UserRoleRequest, adt diygoapi.Audit) (dr diygoapi.DeleteResponse, err error) {
+	const op errs.Op = "service/RoleService.RevokeUserRole"
+
+	// start db txn using pgxpool
+	var tx pgx.Tx
+	tx, err = s.Datastorer.BeginTx(ctx)
+	if err != nil {
+		return diygoapi.DeleteResponse{}, errs.E(op, err)
+	}
+	// defer transaction rollback and handle error, if any
+	defer func() {
+		err = s.Datastorer.RollbackTx(ctx, tx, err)
+	}()
+
+	var urp userRoleParams
+	urp, err = findUserRoleParams(ctx, tx, r)
+	if err != nil {
+		return diygoapi.DeleteResponse{}, errs.E(op, err)
+	}
+
+	err = authorizeUserRoleGrant(ctx, tx, adt, urp)
+	if err != nil {
+		return diygoapi.DeleteResponse{}, errs.E(op, err)
+	}
+
+	params := datastore.DeleteUsersRoleParams{
+		UserID: urp.User.ID,
+		RoleID: urp.Role.ID,
+		OrgID:  urp.Org.ID,
+	}
+
+	var rowsAffected int64
+	rowsAffected, err = datastore.New(tx).DeleteUsersRole(ctx, params)
+	if err != nil {
+		return diygoapi.DeleteResponse{}, errs.E(op, errs.Database, err)
+	}
+
+	if rowsAffected == 0 {
+		return diygoapi.DeleteResponse{}, errs.E(op, errs.NotExist, fmt.Sprintf("user %s does not have role %s in org %s", r.UserExternalID, r.RoleCode, r.OrgExternalID))
+	}
+
+	if rowsAffected != 1 {
+		return diygoapi.DeleteResponse{}, errs.E(op, errs.Database, fmt.Sprintf("rows affected should be 1, actual: %d", rowsAffected))
+	}
+
+	// commit db txn using pgxpool
+	err = s.Datastorer.CommitTx(ctx, tx)
+	if err != nil {
+		return diygoapi.DeleteResponse{}, errs.E(op, err)
+	}
+
+	response := diygoapi.DeleteResponse{
+		ExternalID: r.UserExternalID,
+		Deleted:    true,
+	}
+
+	return response, nil
+}
+
+// FindUsersByOrgRole retrieves the users which have been given a role
+// within an organization.
+func (s *RoleService) FindUsersByOrgRole(ctx context.Context, orgExtlID, roleCd string) (responses []*diygoapi.UserRoleResponse, err error) {
+	const op errs.Op = "service/RoleService.FindUsersByOrgRole"
+
+	// start db txn using pgxpool
+	var tx pgx.Tx
+	tx, err = s.Datastorer.BeginTx(ctx)
+	if err != nil {
+		return nil, errs.E(op, err)
+	}
+	// defer transaction rollback and handle error, if any
+	defer func() {
+		err = s.Datastorer.RollbackTx(ctx, tx, err)
+	}()
+
+	var o diygoapi.Org
+	o, err = findOrgByExternalID(ctx, tx, orgExtlID)
 	if err != nil {
-		return diygoapi.Permission{}, errs.E(op, err)
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, errs.E(op, errs.NotExist, "No org exists for the given external ID")
+		}
+		return nil, errs.E(op, err)
 	}
 
-	arg := datastore.CreatePermissionParams{
-		PermissionID:          p.ID,
-		PermissionExtlID:      p.ExternalID.String(),
-		Resource:              p.Resource,
-		Operation:             p.Operation,
-		PermissionDescription: p.Description,
-		Active:                p.Active,
-		CreateAppID:           adt.App.ID,
-		CreateUserID:          adt.User.NullUUID(),
-		CreateTimestamp:       time.Now(),
-		UpdateAppID:           adt.App.ID,
-		UpdateUserID:          adt.User.NullUUID(),
-		UpdateTimestamp:       time.Now(),
+	var role diygoapi.Role
+	role, err = findRoleByCode(ctx, tx, roleCd)
+	if err != nil {
+		return nil, errs.E(op, err)
 	}
 
-	var rowsAffected int64
-	rowsAffected, err = datastore.New(tx).CreatePermission(ctx, arg)
+	var rows []datastore.UsersRole
+	rows, err = datastore.New(tx).FindUsersByOrgRole(ctx, datastore.FindUsersByOrgRoleParams{OrgID: o.ID, RoleID: role.ID})
 	if err != nil {
-		var pgErr *pgconn.PgError
-		if errors.As(err, &pgErr) {
-			if pgErr.Code == "23505" {
-				return diygoapi.Permission{}, errs.E(errs.Exist, errs.Exist.String())
-			}
-			return diygoapi.Permission{}, errs.E(op, errs.Database, pgErr.Message)
+		return nil, errs.E(op, errs.Database, err)
+	}
+
+	for _, row := range rows {
+		var u *diygoapi.User
+		u, err = FindUserByID(ctx, tx, row.UserID)
+		if err != nil {
+			return nil, errs.E(op, err)
 		}
-		return diygoapi.Permission{}, errs.E(op, errs.Database, err)
+		response := newUserRoleResponse(o, u, role)
+		response.ValidFrom = timePtr(row.ValidFrom)
+		response.ValidUntil = timePtr(row.ValidUntil)
+		responses = append(responses, response)
 	}
 
-	// should only impact exactly one record
-	if rowsAffected != 1 {
-		return diygoapi.Permission{}, errs.E(op, errs.Database, fmt.Sprintf("Create() should insert 1 row, actual: %d", rowsAffected))
+	// commit db txn using pgxpool
+	err = s.Datastorer.CommitTx(ctx, tx)
+	if err != nil {
+		return nil, errs.E(op, err)
 	}
 
-	return p, nil
+	return responses, nil
 }
 
-// FindAll retrieves all permissions
-func (s *PermissionService) FindAll(ctx context.Context) (permissions []*diygoapi.PermissionResponse, err error) {
-	const op errs.Op = "service/PermissionService.FindAll"
+// FindExpiredUserRoles retrieves the role grants, across all
+// organizations, whose validity window has ended.
+func (s *RoleService) FindExpiredUserRoles(ctx context.Context) (responses []*diygoapi.UserRoleResponse, err error) {
+	const op errs.Op = "service/RoleService.FindExpiredUserRoles"
 
 	// start db txn using pgxpool
 	var tx pgx.Tx
@@ -705,36 +2452,32 @@ func (s *PermissionService) FindAll(ctx context.Context) (permissions []*diygoap
 		err = s.Datastorer.RollbackTx(ctx, tx, err)
 	}()
 
-	var rows []datastore.Permission
-	rows, err = datastore.New(tx).FindAllPermissions(ctx)
+	responses, err = findExpiredUserRoles(ctx, tx)
 	if err != nil {
-		return nil, errs.E(op, errs.Database, err)
+		return nil, errs.E(op, err)
 	}
 
-	var sp []*diygoapi.PermissionResponse
-	for _, row := range rows {
-		p := &diygoapi.PermissionResponse{
-			ExternalID:  row.PermissionExtlID,
-			Resource:    row.Resource,
-			Operation:   row.Operation,
-			Description: row.PermissionDescription,
-			Active:      row.Active,
-		}
-		sp = append(sp, p)
+	// commit db txn using pgxpool
+	err = s.Datastorer.CommitTx(ctx, tx)
+	if err != nil {
+		return nil, errs.E(op, err)
 	}
 
-	return sp, nil
+	return responses, nil
 }
 
-// Delete is used to delete a Permission
-func (s *PermissionService) Delete(ctx context.Context, extlID string) (dr diygoapi.DeleteResponse, err error) {
-	const op errs.Op = "service/PermissionService.Delete"
+// PurgeExpiredUserRoles deletes the role grants, across all
+// organizations, whose validity window has ended and returns the
+// number deleted. Grants may be purged concurrently, so the number
+// is whatever this delete removed.
+func (s *RoleService) PurgeExpiredUserRoles(ctx context.Context) (pr diygoapi.UserRolePurgeResponse, err error) {
+	const op errs.Op = "service/RoleService.PurgeExpiredUserRoles"
 
 	// start db txn using pgxpool
 	var tx pgx.Tx
 	tx, err = s.Datastorer.BeginTx(ctx)
 	if err != nil {
-		return diygoapi.DeleteResponse{}, errs.E(op, err)
+		return diygoapi.UserRolePurgeResponse{}, errs.E(op, err)
 	}
 	// defer transaction rollback and handle error, if any
 	defer func() {
@@ -742,137 +2485,248 @@ func (s *PermissionService) Delete(ctx context.Context, extlID string) (dr diygo
 	}()
 
 	var rowsAffected int64
-	rowsAffected, err = datastore.New(tx).DeletePermissionByExternalID(ctx, extlID)
+	rowsAffected, err = datastore.New(tx).DeleteExpiredUsersRoles(ctx)
 	if err != nil {
-		return diygoapi.DeleteResponse{}, errs.E(op, errs.Database, err)
-	}
-
-	if rowsAffected != 1 {
-		return diygoapi.DeleteResponse{}, errs.E(op, errs.Database, fmt.Sprintf("rows affected should be 1, actual: %d", rowsAffected))
+		return diygoapi.UserRolePurgeResponse{}, errs.E(op, errs.Database, err)
 	}
 
 	// commit db txn using pgxpool
 	err = s.Datastorer.CommitTx(ctx, tx)
 	if err != nil {
-		return diygoapi.DeleteResponse{}, errs.E(op, err)
-	}
-
-	response := diygoapi.DeleteResponse{
-		ExternalID: extlID,
-		Deleted:    true,
+		return diygoapi.UserRolePurgeResponse{}, errs.E(op, err)
 	}
 
-	return response, nil
+	return diygoapi.UserRolePurgeResponse{Purged: rowsAffected}, nil
 }
 
-// newPermission initializes a Permission given a datastore.Permission
-func newPermission(ap datastore.Permission) *diygoapi.Permission {
-	return &diygoapi.Permission{
-		ID:          ap.PermissionID,
-		ExternalID:  secure.MustParseIdentifier(ap.PermissionExtlID),
-		Resource:    ap.Resource,
-		Operation:   ap.Operation,
-		Description: ap.PermissionDescription,
-		Active:      ap.Active,
+// findExpiredUserRoles retrieves the role grants whose validity
+// window has ended
+func findExpiredUserRoles(ctx context.Context, tx pgx.Tx) ([]*diygoapi.UserRoleResponse, error) {
+	const op errs.Op = "service/findExpiredUserRoles"
+
+	rows, err := datastore.New(tx).FindExpiredUsersRoles(ctx)
+	if err != nil {
+		return nil, errs.E(op, errs.Database, err)
 	}
+
+	responses := make([]*diygoapi.UserRoleResponse, 0, len(rows))
+	for _, row := range rows {
+		responses = append(responses, &diygoapi.UserRoleResponse{
+			OrgExternalID:  row.OrgExtlID,
+			UserExternalID: row.UserExtlID,
+			Email:          row.Email.String,
+			FirstName:      row.FirstName,
+			LastName:       row.LastName,
+			RoleCode:       row.RoleCd,
+			ValidFrom:      timePtr(row.ValidFrom),
+			ValidUntil:     timePtr(row.ValidUntil),
+		})
+	}
+
+	return responses, nil
 }
 
-// RoleService is a service for creating, reading, updating and deleting a Role
-type RoleService struct {
-	Datastorer diygoapi.Datastorer
+// userRoleParams is the org, user and role referenced by a UserRoleRequest
+type userRoleParams struct {
+	Org  diygoapi.Org
+	User *diygoapi.User
+	Role diygoapi.Role
 }
 
-// Create is used to create a Role
-func (s *RoleService) Create(ctx context.Context, r *diygoapi.CreateRoleRequest, adt diygoapi.Audit) (response *diygoapi.RoleResponse, err error) {
-	const op errs.Op = "service/RoleService.Create"
+// findUserRoleParams looks up the org, user and role referenced by
+// a UserRoleRequest.
+func findUserRoleParams(ctx context.Context, tx pgx.Tx, r *diygoapi.UserRoleRequest) (userRoleParams, error) {
+	const op errs.Op = "service/findUserRoleParams"
 
-	// start db txn using pgxpool
-	var tx pgx.Tx
-	tx, err = s.Datastorer.BeginTx(ctx)
+	o, err := findOrgByExternalID(ctx, tx, r.OrgExternalID)
 	if err != nil {
-		return nil, errs.E(op, err)
+		if errors.Is(err, pgx.ErrNoRows) {
+			return userRoleParams{}, errs.E(op, errs.NotExist, "No org exists for the given external ID")
+		}
+		return userRoleParams{}, errs.E(op, err)
 	}
-	// defer transaction rollback and handle error, if any
-	defer func() {
-		err = s.Datastorer.RollbackTx(ctx, tx, err)
-	}()
 
-	var rolePermissions []*diygoapi.Permission
-	rolePermissions, err = findPermissions(ctx, tx, r.Permissions)
+	var u *diygoapi.User
+	u, err = FindUserByExternalID(ctx, tx, r.UserExternalID)
 	if err != nil {
-		return nil, errs.E(op, err)
+		return userRoleParams{}, errs.E(op, err)
 	}
 
-	role := diygoapi.Role{
-		ID:          uuid.New(),
-		ExternalID:  secure.NewID(),
-		Code:        r.Code,
-		Description: r.Description,
-		Active:      r.Active,
-		Permissions: rolePermissions,
+	var role diygoapi.Role
+	role, err = findRoleByCode(ctx, tx, r.RoleCode)
+	if err != nil {
+		return userRoleParams{}, errs.E(op, err)
 	}
 
-	err = createRoleTx(ctx, tx, role, adt)
+	return userRoleParams{Org: o, User: u, Role: role}, nil
+}
+
+// authorizeUserRoleGrant determines whether the audit User may assign
+// or revoke the role of the userRoleParams within its org. The audit
+// User must be an admin of the org (or of one of its ancestors) and
+// hold the role there. A sysAdmin in the Principal org may assign or
+// revoke any role in any org, and is the only one who may assign or
+// revoke the sysAdmin role.
+func authorizeUserRoleGrant(ctx context.Context, tx pgx.Tx, adt diygoapi.Audit, urp userRoleParams) error {
+	const op errs.Op = "service/authorizeUserRoleGrant"
+
+	if adt.User == nil {
+		return errs.E(op, errs.Unauthorized, "a user is required to assign or revoke a role")
+	}
+
+	principalSysAdmin, err := isPrincipalSysAdmin(ctx, tx, adt.User)
 	if err != nil {
-		return nil, errs.E(op, err)
+		return errs.E(op, err)
+	}
+	if principalSysAdmin {
+		return nil
 	}
 
-	// commit db txn using pgxpool
-	err = s.Datastorer.CommitTx(ctx, tx)
+	if urp.Role.Code == diygoapi.SysAdminRoleCode {
+		return errs.E(op, errs.Unauthorized, fmt.Sprintf("only a %s of the Principal org can assign or revoke the %s role", diygoapi.SysAdminRoleCode, diygoapi.SysAdminRoleCode))
+	}
+
+	var admin bool
+	admin, err = datastore.New(tx).HasAnyOrgRole(ctx, datastore.HasAnyOrgRoleParams{
+		UserID:  adt.User.ID,
+		OrgID:   urp.Org.ID,
+		RoleCds: []string{diygoapi.OrgAdminRoleCode, diygoapi.SysAdminRoleCode},
+	})
 	if err != nil {
-		return nil, errs.E(op, err)
+		return errs.E(op, errs.Database, err)
+	}
+	if !admin {
+		return errs.E(op, errs.Unauthorized, fmt.Sprintf("User_extl_id %s is not an admin of org %s", adt.User.ExternalID.String(), urp.Org.ExternalID.String()))
 	}
 
-	response = &diygoapi.RoleResponse{
-		ExternalID:  role.ExternalID.String(),
-		Code:        role.Code,
-		Description: role.Description,
-		Active:      role.Active,
-		Permissions: role.Permissions,
+	// a role cannot be handed out by someone who does not hold it
+	var held bool
+	held, err = datastore.New(tx).HasAnyOrgRole(ctx, datastore.HasAnyOrgRoleParams{
+		UserID:  adt.User.ID,
+		OrgID:   urp.Org.ID,
+		RoleCds: []string{urp.Role.Code},
+	})
+	if err != nil {
+		return errs.E(op, errs.Database, err)
+	}
+	if !held {
+		return errs.E(op, errs.Unauthorized, fmt.Sprintf("User_extl_id %s does not have role %s in org %s", adt.User.ExternalID.String(), urp.Role.Code, urp.Org.ExternalID.String()))
 	}
 
-	return response, nil
+	return nil
 }
 
-// createRoleTx creates the role in the database
-func createRoleTx(ctx context.Context, tx pgx.Tx, role diygoapi.Role, adt diygoapi.Audit) (err error) {
-	const op errs.Op = "service/createRoleTx"
+// hasOrgRole determines whether the user already has the role within the org
+func hasOrgRole(ctx context.Context, tx pgx.Tx, urp userRoleParams) (bool, error) {
+	const op errs.Op = "service/hasOrgRole"
 
-	err = role.Validate()
+	rows, err := datastore.New(tx).FindUsersByOrgRole(ctx, datastore.FindUsersByOrgRoleParams{OrgID: urp.Org.ID, RoleID: urp.Role.ID})
 	if err != nil {
-		return errs.E(op, err)
+		return false, errs.E(op, errs.Database, err)
 	}
 
-	arg := datastore.CreateRoleParams{
-		RoleID:          role.ID,
-		RoleExtlID:      role.ExternalID.String(),
-		RoleCd:          role.Code,
-		Active:          role.Active,
-		CreateAppID:     adt.App.ID,
-		CreateUserID:    adt.User.NullUUID(),
-		CreateTimestamp: adt.Moment,
+	for _, row := range rows {
+		if row.UserID == urp.User.ID {
+			return true, nil
+		}
+	}
+
+	return false, nil
+}
+
+// updateOrgRoleValidity replaces the validity window of a role the
+// user already has within the org
+func updateOrgRoleValidity(ctx context.Context, tx pgx.Tx, urp userRoleParams, validFrom, validUntil *time.Time, adt diygoapi.Audit) error {
+	const op errs.Op = "service/updateOrgRoleValidity"
+
+	params := datastore.UpdateUsersRoleValidityParams{
+		UserID:          urp.User.ID,
+		RoleID:          urp.Role.ID,
+		OrgID:           urp.Org.ID,
+		ValidFrom:       nullTime(validFrom),
+		ValidUntil:      nullTime(validUntil),
 		UpdateAppID:     adt.App.ID,
 		UpdateUserID:    adt.User.NullUUID(),
 		UpdateTimestamp: adt.Moment,
 	}
 
-	var rowsAffected int64
-	rowsAffected, err = datastore.New(tx).CreateRole(ctx, arg)
+	rowsAffected, err := datastore.New(tx).UpdateUsersRoleValidity(ctx, params)
 	if err != nil {
 		return errs.E(op, errs.Database, err)
 	}
 
-	// should only impact exactly one record
 	if rowsAffected != 1 {
-		return errs.E(op, errs.Database, fmt.Sprintf("Create() should insert 1 row, actual: %d", rowsAffected))
+		return errs.E(op, errs.Database, fmt.Sprintf("rows affected should be 1, actual: %d", rowsAffected))
 	}
 
-	err = UpdateRolePermissions(ctx, tx, UpdateRolePermissionsParams{Role: role, Audit: adt})
-	if err != nil {
-		return errs.E(op, err)
+	return nil
+}
+
+// nullTime returns a null if t is nil, otherwise the time t points to
+func nullTime(t *time.Time) sql.NullTime {
+	if t == nil {
+		return sql.NullTime{}
 	}
+	return sql.NullTime{Time: *t, Valid: true}
+}
 
-	return nil
+// timePtr returns nil if nt is null, otherwise a pointer to its time
+func timePtr(nt sql.NullTime) *time.Time {
+	if !nt.Valid {
+		return nil
+	}
+	return &nt.Time
+}
+
+// newUserRoleResponse initializes a UserRoleResponse
+func newUserRoleResponse(o diygoapi.Org, u *diygoapi.User, role diygoapi.Role) *diygoapi.UserRoleResponse {
+	return &diygoapi.UserRoleResponse{
+		OrgExternalID:  o.ExternalID.String(),
+		UserExternalID: u.ExternalID.String(),
+		Email:          u.Email,
+		FirstName:      u.FirstName,
+		LastName:       u.LastName,
+		RoleCode:       role.Code,
+	}
+}
+
+// hasPermission reports whether p is in permissions
+func hasPermission(permissions []*diygoapi.Permission, p *diygoapi.Permission) bool {
+	for _, rp := range permissions {
+		if rp.ID == p.ID {
+			return true
+		}
+	}
+	return false
+}
+
+// withoutPermissions returns the permissions which are not in remove
+func withoutPermissions(permissions, remove []*diygoapi.Permission) []*diygoapi.Permission {
+	var keep []*diygoapi.Permission
+	for _, p := range permissions {
+		if !hasPermission(remove, p) {
+			keep = append(keep, p)
+		}
+	}
+	return keep
+}
+
+// newRoleResponse initializes a RoleResponse given a Role
+func newRoleResponse(role diygoapi.Role) *diygoapi.RoleResponse {
+	var parents []string
+	for _, p := range role.Parents {
+		parents = append(parents, p.Code)
+	}
+
+	return &diygoapi.RoleResponse{
+		ExternalID:        role.ExternalID.String(),
+		Code:              role.Code,
+		Description:       role.Description,
+		Active:            role.Active,
+		Permissions:       role.Permissions,
+		DeniedPermissions: role.DeniedPermissions,
+		ParentRoles:       parents,
+	}
 }
 
 // UpdateRolePermissionsParams is the parameters for the UpdateRolePermissions function
@@ -881,8 +2735,8 @@ type UpdateRolePermissionsParams struct {
 	Audit diygoapi.Audit
 }
 
-// UpdateRolePermissions writes the Permissions attached to the role to the database.
-// If there are existing permissions, in the database, they are removed.
+// UpdateRolePermissions writes the Permissions and DeniedPermissions attached to the
+// role to the database. If there are existing permissions, in the database, they are removed.
 func UpdateRolePermissions(ctx context.Context, tx pgx.Tx, params UpdateRolePermissionsParams) (err error) {
 	const op errs.Op = "service/UpdateRolePermissions"
 
@@ -891,10 +2745,29 @@ func UpdateRolePermissions(ctx context.Context, tx pgx.Tx, params UpdateRolePerm
 		return errs.E(op, errs.Database, err)
 	}
 
-	for _, rp := range params.Role.Permissions {
+	err = createRolePermissions(ctx, tx, params, params.Role.Permissions, diygoapi.PermissionEffectAllow)
+	if err != nil {
+		return errs.E(op, err)
+	}
+
+	err = createRolePermissions(ctx, tx, params, params.Role.DeniedPermissions, diygoapi.PermissionEffectDeny)
+	if err != nil {
+		return errs.E(op, err)
+	}
+
+	return nil
+}
+
+// createRolePermissions writes the permissions to the database for the
+// role with the given effect
+func createRolePermissions(ctx context.Context, tx pgx.Tx, params UpdateRolePermissionsParams, permissions []*diygoapi.Permission, effect string) (err error) {
+	const op errs.Op = "service/createRolePermissions"
+
+	for _, rp := range permissions {
 		createRolePermissionParams := datastore.CreateRolePermissionParams{
 			RoleID:          params.Role.ID,
 			PermissionID:    rp.ID,
+			Effect:          effect,
 			CreateAppID:     params.Audit.App.ID,
 			CreateUserID:    params.Audit.User.NullUUID(),
 			CreateTimestamp: params.Audit.Moment,
@@ -927,44 +2800,123 @@ func FindRoleByCode(ctx context.Context, tx datastore.DBTX, code string) (diygoa
 		return diygoapi.Role{}, errs.E(op, errs.Database, err)
 	}
 
-	var dbPermissions []datastore.Permission
-	dbPermissions, err = datastore.New(tx).FindRolePermissionsByRoleID(ctx, dbRole.RoleID)
+	var role diygoapi.Role
+	role, err = newRole(ctx, tx, dbRole)
+	if err != nil {
+		return diygoapi.Role{}, errs.E(op, err)
+	}
+
+	return role, nil
+}
+
+// findRoleByCode returns a Role and its permissions given the Role
+// code. Unlike FindRoleByCode, a missing Role is reported as NotExist.
+func findRoleByCode(ctx context.Context, tx datastore.DBTX, code string) (diygoapi.Role, error) {
+	const op errs.Op = "service/findRoleByCode"
+
+	dbRole, err := datastore.New(tx).FindRoleByCode(ctx, code)
 	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return diygoapi.Role{}, errs.E(op, errs.NotExist, fmt.Sprintf("no role found with code: %s", code))
+		}
 		return diygoapi.Role{}, errs.E(op, errs.Database, err)
 	}
 
-	var permissions []*diygoapi.Permission
-	if dbPermissions != nil {
-		for _, dbp := range dbPermissions {
-			p := &diygoapi.Permission{
-				ID:          dbp.PermissionID,
-				ExternalID:  secure.MustParseIdentifier(dbp.PermissionExtlID),
-				Resource:    dbp.Resource,
-				Operation:   dbp.Operation,
-				Description: dbp.PermissionDescription,
-				Active:      dbp.Active,
-			}
-			permissions = append(permissions, p)
+	var role diygoapi.Role
+	role, err = newRole(ctx, tx, dbRole)
+	if err != nil {
+		return diygoapi.Role{}, errs.E(op, err)
+	}
+
+	return role, nil
+}
+
+// findRoleByExternalID returns a Role and its permissions given the
+// Role external ID.
+func findRoleByExternalID(ctx context.Context, tx datastore.DBTX, extlID string) (diygoapi.Role, error) {
+	const op errs.Op = "service/findRoleByExternalID"
+
+	dbRole, err := datastore.New(tx).FindRoleByExternalID(ctx, extlID)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return diygoapi.Role{}, errs.E(op, errs.NotExist, "No role exists for the given external ID")
 		}
+		return diygoapi.Role{}, errs.E(op, errs.Database, err)
+	}
+
+	var role diygoapi.Role
+	role, err = newRole(ctx, tx, dbRole)
+	if err != nil {
+		return diygoapi.Role{}, errs.E(op, err)
+	}
+
+	return role, nil
+}
+
+// newRole initializes a Role given a datastore.Role and retrieves
+// its permissions and direct parents. The parents' own permissions
+// and parents are not retrieved.
+func newRole(ctx context.Context, tx datastore.DBTX, dbRole datastore.Role) (diygoapi.Role, error) {
+	const op errs.Op = "service/newRole"
+
+	dbPermissions, err := datastore.New(tx).FindRolePermissionsByRoleID(ctx, datastore.FindRolePermissionsByRoleIDParams{RoleID: dbRole.RoleID, Effect: diygoapi.PermissionEffectAllow})
+	if err != nil {
+		return diygoapi.Role{}, errs.E(op, errs.Database, err)
+	}
+
+	var permissions []*diygoapi.Permission
+	for _, dbp := range dbPermissions {
+		permissions = append(permissions, newPermission(dbp))
+	}
+
+	dbPermissions, err = datastore.New(tx).FindRolePermissionsByRoleID(ctx, datastore.FindRolePermissionsByRoleIDParams{RoleID: dbRole.RoleID, Effect: diygoapi.PermissionEffectDeny})
+	if err != nil {
+		return diygoapi.Role{}, errs.E(op, errs.Database, err)
+	}
+
+	var deniedPermissions []*diygoapi.Permission
+	for _, dbp := range dbPermissions {
+		deniedPermissions = append(deniedPermissions, newPermission(dbp))
+	}
+
+	var dbParents []datastore.Role
+	dbParents, err = datastore.New(tx).FindRoleParentsByRoleID(ctx, dbRole.RoleID)
+	if err != nil {
+		return diygoapi.Role{}, errs.E(op, errs.Database, err)
+	}
+
+	var parents []*diygoapi.Role
+	for _, dbp := range dbParents {
+		parents = append(parents, &diygoapi.Role{
+			ID:          dbp.RoleID,
+			ExternalID:  secure.MustParseIdentifier(dbp.RoleExtlID),
+			Code:        dbp.RoleCd,
+			Description: dbp.RoleDescription,
+			Active:      dbp.Active,
+		})
 	}
 
 	role := diygoapi.Role{
-		ID:          dbRole.RoleID,
-		ExternalID:  secure.MustParseIdentifier(dbRole.RoleExtlID),
-		Code:        dbRole.RoleCd,
-		Description: dbRole.RoleDescription,
-		Active:      dbRole.Active,
-		Permissions: permissions,
+		ID:                dbRole.RoleID,
+		ExternalID:        secure.MustParseIdentifier(dbRole.RoleExtlID),
+		Code:              dbRole.RoleCd,
+		Description:       dbRole.RoleDescription,
+		Active:            dbRole.Active,
+		Permissions:       permissions,
+		DeniedPermissions: deniedPermissions,
+		Parents:           parents,
 	}
 
 	return role, nil
 }
 
 type assignOrgRoleParams struct {
-	Role  diygoapi.Role
-	User  *diygoapi.User
-	Org   *diygoapi.Org
-	Audit diygoapi.Audit
+	Role       diygoapi.Role
+	User       *diygoapi.User
+	Org        *diygoapi.Org
+	ValidFrom  *time.Time
+	ValidUntil *time.Time
+	Audit      diygoapi.Audit
 }
 
 // assignOrgRoles assigns a role to a user for a given org.
@@ -975,6 +2927,8 @@ func assignOrgRole(ctx context.Context, tx pgx.Tx, p assignOrgRoleParams) (err e
 		UserID:          p.User.ID,
 		RoleID:          p.Role.ID,
 		OrgID:           p.Org.ID,
+		ValidFrom:       nullTime(p.ValidFrom),
+		ValidUntil:      nullTime(p.ValidUntil),
 		CreateAppID:     p.Audit.App.ID,
 		CreateUserID:    p.Audit.User.NullUUID(),
 		CreateTimestamp: p.Audit.Moment,
@@ -1015,12 +2969,18 @@ func findPermissions(ctx context.Context, tx pgx.Tx, prs []*diygoapi.FindPermiss
 		if pr.ExternalID != "" {
 			ap, err = datastore.New(tx).FindPermissionByExternalID(ctx, pr.ExternalID)
 			if err != nil {
+				if errors.Is(err, pgx.ErrNoRows) {
+					return nil, errs.E(op, errs.NotExist, fmt.Sprintf("no permission exists for external ID %s", pr.ExternalID))
+				}
 				return nil, errs.E(op, errs.Database, err)
 			}
 			aps = append(aps, newPermission(ap))
 		} else {
 			ap, err = datastore.New(tx).FindPermissionByResourceOperation(ctx, datastore.FindPermissionByResourceOperationParams{Resource: pr.Resource, Operation: pr.Operation})
 			if err != nil {
+				if errors.Is(err, pgx.ErrNoRows) {
+					return nil, errs.E(op, errs.NotExist, fmt.Sprintf("no permission exists for %s %s", pr.Operation, pr.Resource))
+				}
 				return nil, errs.E(op, errs.Database, err)
 			}
 			aps = append(aps, newPermission(ap))
@@ -2,17 +2,28 @@ package service_test
 
 import (
 	"context"
+	"database/sql"
+	"fmt"
 	"net/http"
 	"net/http/httptest"
 	"os"
 	"testing"
+	"time"
 
 	qt "github.com/frankban/quicktest"
+	"github.com/google/uuid"
 	"github.com/gorilla/mux"
+	"github.com/jackc/pgx/v4"
 	"github.com/rs/zerolog"
+	"golang.org/x/oauth2"
+	"golang.org/x/text/language"
 
+	"github.com/gilcrest/diygoapi"
+	"github.com/gilcrest/diygoapi/errs"
 	"github.com/gilcrest/diygoapi/logger"
+	"github.com/gilcrest/diygoapi/secure"
 	"github.com/gilcrest/diygoapi/service"
+	"github.com/gilcrest/diygoapi/sqldb/datastore"
 	"github.com/gilcrest/diygoapi/sqldb/sqldbtest"
 )
 
@@ -93,4 +104,617 @@ func TestDBAuthorizer_Authorize(t *testing.T) {
 		c.Assert(rr.Code, qt.Equals, http.StatusOK)
 
 	})
+	t.Run("inactive role", func(t *testing.T) {
+		c := qt.New(t)
+
+		db, cleanup := sqldbtest.NewDB(t)
+		c.Cleanup(cleanup)
+
+		ctx := context.Background()
+		tx, err := db.BeginTx(ctx)
+		if err != nil {
+			c.Fatalf("BeginTx() error = %v", err)
+		}
+		adt := findTestAudit(ctx, c, tx)
+		o := createTestOrg(ctx, c, tx, adt, nil)
+		u := createTestUser(ctx, c, tx, adt, o)
+		err = db.CommitTx(ctx, tx)
+		c.Assert(err, qt.IsNil)
+
+		suffix := uuid.NewString()
+		path := "/api/v1/inactive-role-test/" + suffix
+
+		ps := service.PermissionService{Datastorer: db}
+		_, err = ps.Create(ctx, &diygoapi.CreatePermissionRequest{
+			Resource:    path,
+			Operation:   http.MethodGet,
+			Description: "Permission created via TestDBAuthorizer_Authorize",
+			Active:      true,
+		}, adt)
+		c.Assert(err, qt.IsNil)
+
+		// the user is only granted the child role, which inherits
+		// the permission from the parent role
+		rs := service.RoleService{Datastorer: db}
+		var parent, child *diygoapi.RoleResponse
+		parent, err = rs.Create(ctx, &diygoapi.CreateRoleRequest{
+			Code:        "parent-" + suffix,
+			Description: "Parent role created via TestDBAuthorizer_Authorize",
+			Active:      true,
+			Permissions: []*diygoapi.FindPermissionRequest{{Resource: path, Operation: http.MethodGet}},
+		}, adt)
+		c.Assert(err, qt.IsNil)
+		child, err = rs.Create(ctx, &diygoapi.CreateRoleRequest{
+			Code:        "child-" + suffix,
+			Description: "Child role created via TestDBAuthorizer_Authorize",
+			Active:      true,
+			ParentRoles: []string{parent.Code},
+		}, adt)
+		c.Assert(err, qt.IsNil)
+
+		tx, err = db.BeginTx(ctx)
+		if err != nil {
+			c.Fatalf("BeginTx() error = %v", err)
+		}
+		grantTestRole(ctx, c, tx, adt, u, o, child.Code, time.Time{}, time.Time{})
+		err = db.CommitTx(ctx, tx)
+		c.Assert(err, qt.IsNil)
+
+		setActive := func(role *diygoapi.RoleResponse, active bool) {
+			c.Helper()
+			_, err := rs.Update(ctx, &diygoapi.UpdateRoleRequest{ExternalID: role.ExternalID, Code: role.Code, Description: role.Description, Active: active}, adt)
+			c.Assert(err, qt.IsNil)
+		}
+
+		dba := &service.DBAuthorizationService{Datastorer: db}
+		uadt := diygoapi.Audit{App: adt.App, User: u, Org: o, Moment: time.Now()}
+
+		c.Assert(testAuthorize(c, dba, uadt, path), qt.IsNil)
+
+		// an inactive parent role no longer grants its permissions
+		// through the roles which inherit from it
+		setActive(parent, false)
+		c.Assert(errs.KindIs(errs.Unauthorized, testAuthorize(c, dba, uadt, path)), qt.IsTrue)
+
+		setActive(parent, true)
+		c.Assert(testAuthorize(c, dba, uadt, path), qt.IsNil)
+
+		// an inactive role grants nothing, not even what it inherits
+		setActive(child, false)
+		c.Assert(errs.KindIs(errs.Unauthorized, testAuthorize(c, dba, uadt, path)), qt.IsTrue)
+	})
+	t.Run("role grant validity window", func(t *testing.T) {
+		c := qt.New(t)
+
+		db, cleanup := sqldbtest.NewDB(t)
+		c.Cleanup(cleanup)
+
+		ctx := context.Background()
+		adt := func() diygoapi.Audit {
+			tx, err := db.BeginTx(ctx)
+			if err != nil {
+				c.Fatalf("BeginTx() error = %v", err)
+			}
+			defer func() { _ = db.RollbackTx(ctx, tx, err) }()
+			return findTestAudit(ctx, c, tx)
+		}()
+
+		suffix := uuid.NewString()
+		path := "/api/v1/role-validity-test/" + suffix
+
+		ps := service.PermissionService{Datastorer: db}
+		_, err := ps.Create(ctx, &diygoapi.CreatePermissionRequest{
+			Resource:    path,
+			Operation:   http.MethodGet,
+			Description: "Permission created via TestDBAuthorizer_Authorize",
+			Active:      true,
+		}, adt)
+		c.Assert(err, qt.IsNil)
+
+		rs := service.RoleService{Datastorer: db}
+		var role *diygoapi.RoleResponse
+		role, err = rs.Create(ctx, &diygoapi.CreateRoleRequest{
+			Code:        "validity-" + suffix,
+			Description: "Role created via TestDBAuthorizer_Authorize",
+			Active:      true,
+			Permissions: []*diygoapi.FindPermissionRequest{{Resource: path, Operation: http.MethodGet}},
+		}, adt)
+		c.Assert(err, qt.IsNil)
+
+		// each user is granted the role with a different window
+		now := time.Now()
+		yesterday, tomorrow := now.Add(-24*time.Hour), now.Add(24*time.Hour)
+
+		tx, err := db.BeginTx(ctx)
+		if err != nil {
+			c.Fatalf("BeginTx() error = %v", err)
+		}
+		o := createTestOrg(ctx, c, tx, adt, nil)
+		current := createTestUser(ctx, c, tx, adt, o)
+		notYetValid := createTestUser(ctx, c, tx, adt, o)
+		expired := createTestUser(ctx, c, tx, adt, o)
+		grantTestRole(ctx, c, tx, adt, current, o, role.Code, yesterday, tomorrow)
+		grantTestRole(ctx, c, tx, adt, notYetValid, o, role.Code, tomorrow, time.Time{})
+		grantTestRole(ctx, c, tx, adt, expired, o, role.Code, time.Time{}, yesterday)
+		err = db.CommitTx(ctx, tx)
+		c.Assert(err, qt.IsNil)
+
+		dba := &service.DBAuthorizationService{Datastorer: db}
+		audit := func(u *diygoapi.User) diygoapi.Audit {
+			return diygoapi.Audit{App: adt.App, User: u, Org: o, Moment: time.Now()}
+		}
+
+		c.Assert(testAuthorize(c, dba, audit(current), path), qt.IsNil)
+		c.Assert(errs.KindIs(errs.Unauthorized, testAuthorize(c, dba, audit(notYetValid), path)), qt.IsTrue)
+		c.Assert(errs.KindIs(errs.Unauthorized, testAuthorize(c, dba, audit(expired), path)), qt.IsTrue)
+
+		// the expired grant is purged, the others are kept
+		var pr diygoapi.UserRolePurgeResponse
+		pr, err = rs.PurgeExpiredUserRoles(ctx)
+		c.Assert(err, qt.IsNil)
+		c.Assert(pr.Purged >= 1, qt.IsTrue)
+
+		var grants []*diygoapi.UserRoleResponse
+		grants, err = rs.FindUsersByOrgRole(ctx, o.ExternalID.String(), role.Code)
+		c.Assert(err, qt.IsNil)
+		c.Assert(grants, qt.HasLen, 2)
+	})
+}
+
+func TestDBAuthorizer_FindDecisions(t *testing.T) {
+	c := qt.New(t)
+
+	db, cleanup := sqldbtest.NewDB(t)
+	c.Cleanup(cleanup)
+
+	// u is a member of two orgs without any role, so every request
+	// is denied and recorded
+	ctx := context.Background()
+	tx, err := db.BeginTx(ctx)
+	if err != nil {
+		c.Fatalf("BeginTx() error = %v", err)
+	}
+	adt := findTestAudit(ctx, c, tx)
+	org := createTestOrg(ctx, c, tx, adt, nil)
+	otherOrg := createTestOrg(ctx, c, tx, adt, nil)
+	u := createTestUser(ctx, c, tx, adt, org, otherOrg)
+	err = db.CommitTx(ctx, tx)
+	c.Assert(err, qt.IsNil)
+
+	dba := &service.DBAuthorizationService{Datastorer: db}
+	audit := func(o *diygoapi.Org) diygoapi.Audit {
+		return diygoapi.Audit{App: adt.App, User: u, Org: o, Moment: time.Now()}
+	}
+
+	path := "/api/v1/decision-test/" + uuid.NewString()
+	c.Assert(errs.KindIs(errs.Unauthorized, testAuthorize(c, dba, audit(org), path)), qt.IsTrue)
+	c.Assert(errs.KindIs(errs.Unauthorized, testAuthorize(c, dba, audit(otherOrg), path)), qt.IsTrue)
+
+	r := &diygoapi.AuthzDecisionSearchRequest{
+		UserExternalID: u.ExternalID.String(),
+		From:           time.Now().Add(-time.Hour),
+		Until:          time.Now().Add(time.Hour),
+		Limit:          10,
+	}
+
+	// only the decisions of the org the caller is acting in are found
+	decisions, err := dba.FindDecisions(ctx, r, audit(org))
+	c.Assert(err, qt.IsNil)
+	c.Assert(decisions, qt.HasLen, 1)
+	c.Assert(decisions[0].OrgExternalID, qt.Equals, org.ExternalID.String())
+	c.Assert(decisions[0].Decision, qt.Equals, diygoapi.AuthzDecisionDeny)
+}
+
+// testAuthorize calls Authorize for a GET request of path. Authorize
+// must be called inside a handler as it uses mux.CurrentRoute.
+func testAuthorize(c *qt.C, dba *service.DBAuthorizationService, adt diygoapi.Audit, path string) error {
+	c.Helper()
+
+	lgr := logger.New(os.Stdout, zerolog.DebugLevel, true)
+
+	var err error
+	rtr := mux.NewRouter()
+	rtr.Handle(path, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		err = dba.Authorize(r, lgr, adt)
+	})).Methods(http.MethodGet)
+	rtr.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, path, nil))
+
+	return err
+}
+
+// createTestOrg creates an Org with the kind of the Test Org. If
+// parent is not nil, the Org is created as its child.
+func createTestOrg(ctx context.Context, c *qt.C, tx pgx.Tx, adt diygoapi.Audit, parent *diygoapi.Org) *diygoapi.Org {
+	c.Helper()
+
+	testOrg, err := service.FindOrgByName(ctx, tx, service.TestOrgName)
+	if err != nil {
+		c.Fatalf("FindOrgByName() error = %v", err)
+	}
+
+	o := &diygoapi.Org{
+		ID:          uuid.New(),
+		ExternalID:  secure.NewID(),
+		Name:        "Test Org " + uuid.NewString(),
+		Description: "Org created for a test",
+		Kind:        testOrg.Kind,
+		Parent:      parent,
+	}
+
+	params := datastore.CreateOrgParams{
+		OrgID:           o.ID,
+		OrgExtlID:       o.ExternalID.String(),
+		OrgName:         o.Name,
+		OrgDescription:  o.Description,
+		OrgKindID:       o.Kind.ID,
+		CreateAppID:     adt.App.ID,
+		CreateUserID:    adt.User.NullUUID(),
+		CreateTimestamp: adt.Moment,
+		UpdateAppID:     adt.App.ID,
+		UpdateUserID:    adt.User.NullUUID(),
+		UpdateTimestamp: adt.Moment,
+	}
+	if parent != nil {
+		params.ParentOrgID = diygoapi.NewNullUUID(parent.ID)
+	}
+
+	_, err = datastore.New(tx).CreateOrg(ctx, params)
+	if err != nil {
+		c.Fatalf("CreateOrg() error = %v", err)
+	}
+
+	return o
+}
+
+// createTestUser creates a Person and User and makes the User a
+// member of each of the given orgs
+func createTestUser(ctx context.Context, c *qt.C, tx pgx.Tx, adt diygoapi.Audit, orgs ...*diygoapi.Org) *diygoapi.User {
+	c.Helper()
+
+	personID := uuid.New()
+	_, err := datastore.New(tx).CreatePerson(ctx, datastore.CreatePersonParams{
+		PersonID:        personID,
+		PersonExtlID:    secure.NewID().String(),
+		CreateAppID:     adt.App.ID,
+		CreateUserID:    adt.User.NullUUID(),
+		CreateTimestamp: adt.Moment,
+		UpdateAppID:     adt.App.ID,
+		UpdateUserID:    adt.User.NullUUID(),
+		UpdateTimestamp: adt.Moment,
+	})
+	if err != nil {
+		c.Fatalf("CreatePerson() error = %v", err)
+	}
+
+	u := &diygoapi.User{
+		ID:         uuid.New(),
+		ExternalID: secure.NewID(),
+		FirstName:  "Test",
+		LastName:   "User",
+		Email:      fmt.Sprintf("%s@example.com", uuid.NewString()),
+	}
+
+	_, err = datastore.New(tx).CreateUser(ctx, datastore.CreateUserParams{
+		UserID:          u.ID,
+		UserExtlID:      u.ExternalID.String(),
+		PersonID:        personID,
+		FirstName:       u.FirstName,
+		LastName:        u.LastName,
+		Email:           diygoapi.NewNullString(u.Email),
+		CreateAppID:     adt.App.ID,
+		CreateUserID:    adt.User.NullUUID(),
+		CreateTimestamp: adt.Moment,
+		UpdateAppID:     adt.App.ID,
+		UpdateUserID:    adt.User.NullUUID(),
+		UpdateTimestamp: adt.Moment,
+	})
+	if err != nil {
+		c.Fatalf("CreateUser() error = %v", err)
+	}
+
+	for _, o := range orgs {
+		_, err = datastore.New(tx).CreateUsersOrg(ctx, datastore.CreateUsersOrgParams{
+			UsersOrgID:      uuid.New(),
+			OrgID:           o.ID,
+			UserID:          u.ID,
+			CreateAppID:     adt.App.ID,
+			CreateUserID:    adt.User.NullUUID(),
+			CreateTimestamp: adt.Moment,
+			UpdateAppID:     adt.App.ID,
+			UpdateUserID:    adt.User.NullUUID(),
+			UpdateTimestamp: adt.Moment,
+		})
+		if err != nil {
+			c.Fatalf("CreateUsersOrg() error = %v", err)
+		}
+	}
+
+	return u
+}
+
+// grantTestRole grants the role with the given code to the User in
+// the Org. A zero validFrom or validUntil leaves the grant unbounded.
+func grantTestRole(ctx context.Context, c *qt.C, tx pgx.Tx, adt diygoapi.Audit, u *diygoapi.User, o *diygoapi.Org, roleCd string, validFrom, validUntil time.Time) {
+	c.Helper()
+
+	role, err := service.FindRoleByCode(ctx, tx, roleCd)
+	if err != nil {
+		c.Fatalf("FindRoleByCode() error = %v", err)
+	}
+
+	_, err = datastore.New(tx).CreateUsersRole(ctx, datastore.CreateUsersRoleParams{
+		UserID:          u.ID,
+		RoleID:          role.ID,
+		OrgID:           o.ID,
+		ValidFrom:       sql.NullTime{Time: validFrom, Valid: !validFrom.IsZero()},
+		ValidUntil:      sql.NullTime{Time: validUntil, Valid: !validUntil.IsZero()},
+		CreateAppID:     adt.App.ID,
+		CreateUserID:    adt.User.NullUUID(),
+		CreateTimestamp: adt.Moment,
+		UpdateAppID:     adt.App.ID,
+		UpdateUserID:    adt.User.NullUUID(),
+		UpdateTimestamp: adt.Moment,
+	})
+	if err != nil {
+		c.Fatalf("CreateUsersRole() error = %v", err)
+	}
+}
+
+func TestRoleService_AssignUserRole(t *testing.T) {
+	c := qt.New(t)
+
+	db, cleanup := sqldbtest.NewDB(t)
+	c.Cleanup(cleanup)
+
+	// admin is the orgAdmin of org, member is a member of org and
+	// outsider is a member of another org
+	ctx := context.Background()
+	tx, err := db.BeginTx(ctx)
+	if err != nil {
+		c.Fatalf("BeginTx() error = %v", err)
+	}
+	adt := findTestAudit(ctx, c, tx)
+	org := createTestOrg(ctx, c, tx, adt, nil)
+	otherOrg := createTestOrg(ctx, c, tx, adt, nil)
+	admin := createTestUser(ctx, c, tx, adt, org)
+	member := createTestUser(ctx, c, tx, adt, org)
+	outsider := createTestUser(ctx, c, tx, adt, otherOrg)
+	grantTestRole(ctx, c, tx, adt, admin, org, diygoapi.OrgAdminRoleCode, time.Time{}, time.Time{})
+	err = db.CommitTx(ctx, tx)
+	c.Assert(err, qt.IsNil)
+
+	s := service.RoleService{Datastorer: db}
+	adminAdt := diygoapi.Audit{App: adt.App, User: admin, Org: org, Moment: time.Now()}
+
+	request := func(o *diygoapi.Org, u *diygoapi.User, roleCd string) *diygoapi.UserRoleRequest {
+		return &diygoapi.UserRoleRequest{
+			OrgExternalID:  o.ExternalID.String(),
+			UserExternalID: u.ExternalID.String(),
+			RoleCode:       roleCd,
+		}
+	}
+
+	denied := []struct {
+		name string
+		r    *diygoapi.UserRoleRequest
+		kind errs.Kind
+	}{
+		{name: "org the caller is not an admin of", r: request(otherOrg, outsider, diygoapi.OrgAdminRoleCode), kind: errs.Unauthorized},
+		{name: "sysAdmin role", r: request(org, member, diygoapi.SysAdminRoleCode), kind: errs.Unauthorized},
+		{name: "role the caller does not hold", r: request(org, member, service.TestRoleCode), kind: errs.Unauthorized},
+		{name: "caller themselves", r: request(org, admin, diygoapi.OrgAdminRoleCode), kind: errs.Unauthorized},
+		{name: "user who is not a member of the org", r: request(org, outsider, diygoapi.OrgAdminRoleCode), kind: errs.Validation},
+	}
+	for _, tt := range denied {
+		c.Run(tt.name, func(c *qt.C) {
+			_, err := s.AssignUserRole(ctx, tt.r, adminAdt)
+			c.Assert(errs.KindIs(tt.kind, err), qt.IsTrue, qt.Commentf("error = %v", err))
+		})
+	}
+
+	c.Run("revoke in an org the caller is not an admin of", func(c *qt.C) {
+		_, err := s.RevokeUserRole(ctx, request(otherOrg, outsider, diygoapi.OrgAdminRoleCode), adminAdt)
+		c.Assert(errs.KindIs(errs.Unauthorized, err), qt.IsTrue, qt.Commentf("error = %v", err))
+	})
+	c.Run("revoke sysAdmin role", func(c *qt.C) {
+		_, err := s.RevokeUserRole(ctx, request(org, member, diygoapi.SysAdminRoleCode), adminAdt)
+		c.Assert(errs.KindIs(errs.Unauthorized, err), qt.IsTrue, qt.Commentf("error = %v", err))
+	})
+	c.Run("assign and revoke a held role to a member", func(c *qt.C) {
+		_, err := s.AssignUserRole(ctx, request(org, member, diygoapi.OrgAdminRoleCode), adminAdt)
+		c.Assert(err, qt.IsNil)
+
+		var dr diygoapi.DeleteResponse
+		dr, err = s.RevokeUserRole(ctx, request(org, member, diygoapi.OrgAdminRoleCode), adminAdt)
+		c.Assert(err, qt.IsNil)
+		c.Assert(dr.Deleted, qt.IsTrue)
+	})
+}
+
+// TestAuthorizeOwner exercises the owner checks through the API keys
+// of an app, which only its owners may list
+func TestAuthorizeOwner(t *testing.T) {
+	c := qt.New(t)
+
+	db, cleanup := sqldbtest.NewDB(t)
+	c.Cleanup(cleanup)
+
+	// the app is created by creator in child, a child of parent.
+	// parentAdmin is the orgAdmin of parent, sysAdmin holds the
+	// sysAdmin role in the Principal org, member is a member of child
+	// without any role and outsider is a member of another org.
+	ctx := context.Background()
+	tx, err := db.BeginTx(ctx)
+	if err != nil {
+		c.Fatalf("BeginTx() error = %v", err)
+	}
+	adt := findTestAudit(ctx, c, tx)
+	principal, err := service.FindOrgByName(ctx, tx, service.PrincipalOrgName)
+	if err != nil {
+		c.Fatalf("FindOrgByName() error = %v", err)
+	}
+	parent := createTestOrg(ctx, c, tx, adt, nil)
+	child := createTestOrg(ctx, c, tx, adt, parent)
+	otherOrg := createTestOrg(ctx, c, tx, adt, nil)
+	creator := createTestUser(ctx, c, tx, adt, child)
+	parentAdmin := createTestUser(ctx, c, tx, adt, parent)
+	sysAdmin := createTestUser(ctx, c, tx, adt, principal)
+	member := createTestUser(ctx, c, tx, adt, child)
+	outsider := createTestUser(ctx, c, tx, adt, otherOrg)
+	grantTestRole(ctx, c, tx, adt, parentAdmin, parent, diygoapi.OrgAdminRoleCode, time.Time{}, time.Time{})
+	grantTestRole(ctx, c, tx, adt, sysAdmin, principal, diygoapi.SysAdminRoleCode, time.Time{}, time.Time{})
+	err = db.CommitTx(ctx, tx)
+	c.Assert(err, qt.IsNil)
+
+	s := service.AppService{
+		Datastorer:      db,
+		APIKeyGenerator: secure.RandomGenerator{},
+		EncryptionKey:   &[32]byte{},
+	}
+
+	audit := func(u *diygoapi.User, o *diygoapi.Org) diygoapi.Audit {
+		return diygoapi.Audit{App: adt.App, User: u, Org: o, Moment: time.Now()}
+	}
+
+	ar, err := s.Create(ctx, &diygoapi.CreateAppRequest{Name: "Owned App " + uuid.NewString(), Description: "App owned by a test user"}, audit(creator, child))
+	c.Assert(err, qt.IsNil)
+
+	tests := []struct {
+		name    string
+		adt     diygoapi.Audit
+		allowed bool
+	}{
+		{name: "creator", adt: audit(creator, child), allowed: true},
+		{name: "org admin in the lineage", adt: audit(parentAdmin, parent), allowed: true},
+		{name: "Principal sysAdmin", adt: audit(sysAdmin, principal), allowed: true},
+		{name: "member who did not create it", adt: audit(member, child), allowed: false},
+		{name: "non-owner in another org", adt: audit(outsider, otherOrg), allowed: false},
+	}
+	for _, tt := range tests {
+		c.Run(tt.name, func(c *qt.C) {
+			_, err := s.FindAPIKeys(ctx, ar.ExternalID, tt.adt)
+			if tt.allowed {
+				c.Assert(err, qt.IsNil)
+				return
+			}
+			c.Assert(errs.KindIs(errs.Unauthorized, err), qt.IsTrue, qt.Commentf("error = %v", err))
+		})
+	}
+}
+
+// testTokenExchanger is a diygoapi.TokenExchanger which returns the
+// same ProviderInfo for any token
+type testTokenExchanger struct {
+	pi *diygoapi.ProviderInfo
+}
+
+func (e testTokenExchanger) Exchange(ctx context.Context, realm string, provider diygoapi.Provider, token *oauth2.Token) (*diygoapi.ProviderInfo, error) {
+	return e.pi, nil
+}
+
+// newTestAuthenticationService returns a DBAuthenticationService whose
+// provider returns an OIDC ProviderInfo for a new person, the ID token
+// expiring at expiry
+func newTestAuthenticationService(db diygoapi.Datastorer, expiry time.Time) service.DBAuthenticationService {
+	id := uuid.NewString()
+	pi := &diygoapi.ProviderInfo{
+		Provider:  diygoapi.OIDC,
+		TokenInfo: &diygoapi.ProviderTokenInfo{Expiration: expiry, ClientID: "test-client-id"},
+		UserInfo: &diygoapi.ProviderUserInfo{
+			ExternalID:    id,
+			Email:         id + "@example.com",
+			EmailVerified: true,
+			FirstName:     "Otto",
+			LastName:      "Maddox",
+			FullName:      "Otto Maddox",
+		},
+	}
+
+	return service.DBAuthenticationService{
+		Datastorer:         db,
+		TokenExchanger:     testTokenExchanger{pi: pi},
+		LanguageMatcher:    language.NewMatcher([]language.Tag{language.AmericanEnglish}),
+		RegistrationPolicy: diygoapi.RegistrationPolicy{Mode: diygoapi.RegistrationOpen},
+	}
+}
+
+// registerTestUser registers a person through s using the test app and
+// returns the authentication params they authenticate with
+func registerTestUser(ctx context.Context, c *qt.C, db diygoapi.Datastorer, s service.DBAuthenticationService) diygoapi.AuthenticationParams {
+	c.Helper()
+
+	tx, err := db.BeginTx(ctx)
+	if err != nil {
+		c.Fatalf("BeginTx() error = %v", err)
+	}
+	adt := findTestAudit(ctx, c, tx)
+	err = db.CommitTx(ctx, tx)
+	c.Assert(err, qt.IsNil)
+
+	params := diygoapi.AuthenticationParams{
+		Realm:    "diygoapi",
+		Provider: diygoapi.OIDC,
+		Token:    &oauth2.Token{AccessToken: "id-token-" + uuid.NewString(), TokenType: diygoapi.BearerTokenType},
+	}
+
+	_, err = s.Register(diygoapi.NewContextWithApp(ctx, adt.App), params, nil)
+	c.Assert(err, qt.IsNil)
+
+	return params
+}
+
+func TestDBAuthenticationService_FindAuth(t *testing.T) {
+	c := qt.New(t)
+
+	db, cleanup := sqldbtest.NewDB(t)
+	c.Cleanup(cleanup)
+
+	ctx := context.Background()
+
+	c.Run("valid cached ID token", func(c *qt.C) {
+		s := newTestAuthenticationService(db, time.Now().Add(time.Hour))
+		params := registerTestUser(ctx, c, db, s)
+
+		_, err := s.FindAuth(ctx, params)
+		c.Assert(err, qt.IsNil)
+	})
+	c.Run("expired cached ID token", func(c *qt.C) {
+		// the ID token was valid when verified at registration and
+		// has expired since
+		s := newTestAuthenticationService(db, time.Now().Add(-time.Minute))
+		params := registerTestUser(ctx, c, db, s)
+
+		_, err := s.FindAuth(ctx, params)
+		c.Assert(errs.KindIs(errs.Unauthenticated, err), qt.IsTrue, qt.Commentf("error = %v", err))
+	})
+	c.Run("suspended user", func(c *qt.C) {
+		s := newTestAuthenticationService(db, time.Now().Add(time.Hour))
+		params := registerTestUser(ctx, c, db, s)
+
+		auth, err := s.FindAuth(ctx, params)
+		c.Assert(err, qt.IsNil)
+
+		tx, err := db.BeginTx(ctx)
+		if err != nil {
+			c.Fatalf("BeginTx() error = %v", err)
+		}
+		adt := findPrincipalTestAudit(ctx, c, tx)
+		err = db.CommitTx(ctx, tx)
+		c.Assert(err, qt.IsNil)
+
+		us := service.UserService{Datastorer: db}
+		_, err = us.Suspend(ctx, &diygoapi.SuspendUserRequest{ExternalID: auth.User.ExternalID.String(), Reason: "Suspended by TestDBAuthenticationService_FindAuth"}, adt)
+		c.Assert(err, qt.IsNil)
+
+		_, err = s.FindAuth(ctx, params)
+		c.Assert(errs.KindIs(errs.Unauthenticated, err), qt.IsTrue, qt.Commentf("error = %v", err))
+
+		// the auths of the user were expired on suspension, the old
+		// token stays unusable once the user is reinstated
+		_, err = us.Reinstate(ctx, auth.User.ExternalID.String(), adt)
+		c.Assert(err, qt.IsNil)
+
+		_, err = s.FindAuth(ctx, params)
+		c.Assert(errs.KindIs(errs.Unauthenticated, err), qt.IsTrue, qt.Commentf("error = %v", err))
+	})
 }
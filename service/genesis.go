@@ -3,6 +3,7 @@ package service
 import (
 	"context"
 	"encoding/json"
+	"fmt"
 	"os"
 	"time"
 
@@ -23,6 +24,11 @@ const (
 	PrincipalOrgName               = "Principal"
 	principalOrgDescription        = "The Principal org represents the first organization created in the database and exists for the administrative purpose of creating other organizations, apps and users."
 	principalOrgKind        string = "principal"
+	// standardOrgKind is the kind of the orgs created for business
+	// purposes, e.g. the personal org of a registered user
+	standardOrgKind string = "standard"
+	// testOrgKind is the kind of the test org
+	testOrgKind string = "test"
 	// PrincipalAppName is the first app created as part of the
 	// Genesis event and is the central administration app.
 	PrincipalAppName        = "Developer Dashboard"
@@ -249,20 +255,10 @@ func (s *GenesisService) seedPrincipal(ctx context.Context, tx pgx.Tx, r *diygoa
 		Moment: time.Now(),
 	}
 
-	cg := datastore.CreateAuthProviderParams{
-		AuthProviderID:   int64(diygoapi.Google),
-		AuthProviderCd:   diygoapi.Google.String(),
-		AuthProviderDesc: "Google Oauth2",
-		CreateAppID:      adt.App.ID,
-		CreateUserID:     adt.User.NullUUID(),
-		CreateTimestamp:  adt.Moment,
-		UpdateAppID:      adt.App.ID,
-		UpdateUserID:     adt.User.NullUUID(),
-		UpdateTimestamp:  adt.Moment,
-	}
-	_, err = datastore.New(tx).CreateAuthProvider(ctx, cg)
+	// seed the auth_provider lookup table
+	err = createAuthProviders(ctx, tx, adt)
 	if err != nil {
-		return principalSeed{}, errs.E(op, errs.Database, err)
+		return principalSeed{}, errs.E(op, err)
 	}
 
 	// write Person/User from request to the database
@@ -525,6 +521,46 @@ func (s *GenesisService) seedUserInitiatedData(ctx context.Context, tx pgx.Tx, p
 	return ui, nil
 }
 
+// createAuthProviders initializes the auth_provider lookup table with
+// a record for each supported authentication provider
+func createAuthProviders(ctx context.Context, tx pgx.Tx, adt diygoapi.Audit) error {
+	const op errs.Op = "service/createAuthProviders"
+
+	providers := []struct {
+		Provider    diygoapi.Provider
+		Description string
+	}{
+		{Provider: diygoapi.Google, Description: "Google Oauth2"},
+		{Provider: diygoapi.GitHub, Description: "GitHub Oauth2"},
+		{Provider: diygoapi.OIDC, Description: "OpenID Connect"},
+	}
+
+	for _, p := range providers {
+		params := datastore.CreateAuthProviderParams{
+			AuthProviderID:   int64(p.Provider),
+			AuthProviderCd:   p.Provider.String(),
+			AuthProviderDesc: p.Description,
+			CreateAppID:      adt.App.ID,
+			CreateUserID:     adt.User.NullUUID(),
+			CreateTimestamp:  adt.Moment,
+			UpdateAppID:      adt.App.ID,
+			UpdateUserID:     adt.User.NullUUID(),
+			UpdateTimestamp:  adt.Moment,
+		}
+
+		rowsAffected, err := datastore.New(tx).CreateAuthProvider(ctx, params)
+		if err != nil {
+			return errs.E(op, errs.Database, err)
+		}
+
+		if rowsAffected != 1 {
+			return errs.E(op, errs.Database, fmt.Sprintf("rows affected should be 1, actual: %d", rowsAffected))
+		}
+	}
+
+	return nil
+}
+
 func genesisHasOccurred(ctx context.Context, dbtx datastore.DBTX) (err error) {
 	const op errs.Op = "service/genesisHasOccurred"
 
@@ -626,6 +662,22 @@ func seedRoles(ctx context.Context, tx pgx.Tx, r *diygoapi.GenesisRequest, adt d
 		}
 		role.Permissions = rolePermissions
 
+		// find and add Permissions explicitly denied to the role
+		role.DeniedPermissions, err = findPermissions(ctx, tx, crr.DeniedPermissions)
+		if err != nil {
+			return genesisRoles{}, errs.E(op, err)
+		}
+
+		// parent roles must be listed earlier in the request
+		for _, code := range crr.ParentRoles {
+			var parent diygoapi.Role
+			parent, err = findRoleByCode(ctx, tx, code)
+			if err != nil {
+				return genesisRoles{}, errs.E(op, err)
+			}
+			role.Parents = append(role.Parents, &parent)
+		}
+
 		// add the Test user and Genesis input user to roles by attaching their external ids
 		err = createRoleTx(ctx, tx, role, adt)
 		if err != nil {
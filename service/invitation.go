@@ -0,0 +1,487 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v4"
+
+	"github.com/gilcrest/diygoapi"
+	"github.com/gilcrest/diygoapi/errs"
+	"github.com/gilcrest/diygoapi/secure"
+	"github.com/gilcrest/diygoapi/sqldb/datastore"
+)
+
+// InvitationService is a service for inviting people to join an Org
+type InvitationService struct {
+	Datastorer    diygoapi.Datastorer
+	EncryptionKey *[32]byte
+}
+
+// Create invites a person, by email, to join an Org with a Role. The
+// response holds the invitation token, which is to be delivered to the
+// invited person.
+func (s *InvitationService) Create(ctx context.Context, r *diygoapi.CreateInvitationRequest, adt diygoapi.Audit) (response *diygoapi.InvitationResponse, err error) {
+	const op errs.Op = "service/InvitationService.Create"
+
+	err = r.Validate()
+	if err != nil {
+		return nil, errs.E(op, err)
+	}
+
+	// start db txn using pgxpool
+	var tx pgx.Tx
+	tx, err = s.Datastorer.BeginTx(ctx)
+	if err != nil {
+		return nil, errs.E(op, err)
+	}
+	// defer transaction rollback and handle error, if any
+	defer func() {
+		err = s.Datastorer.RollbackTx(ctx, tx, err)
+	}()
+
+	var o diygoapi.Org
+	o, err = findOrgByExternalID(ctx, tx, r.OrgExternalID)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, errs.E(op, errs.NotExist, "No org exists for the given external ID")
+		}
+		return nil, errs.E(op, err)
+	}
+
+	// only an admin of the org may invite people to join it
+	err = authorizeOwner(ctx, tx, adt, diygoapi.Owner{OrgID: o.ID})
+	if err != nil {
+		return nil, errs.E(op, err)
+	}
+
+	var role diygoapi.Role
+	role, err = findRoleByCode(ctx, tx, r.RoleCode)
+	if err != nil {
+		return nil, errs.E(op, err)
+	}
+
+	if !role.Active {
+		return nil, errs.E(op, errs.Validation, fmt.Sprintf("role %s is not active", r.RoleCode))
+	}
+
+	// admin roles are granted explicitly, never by invitation
+	if role.Code == diygoapi.OrgAdminRoleCode || role.Code == diygoapi.SysAdminRoleCode {
+		return nil, errs.E(op, errs.Unauthorized, fmt.Sprintf("the %s role cannot be given by invitation", role.Code))
+	}
+
+	// the inviter may only hand out a role they hold in the org
+	var hasRole bool
+	hasRole, err = datastore.New(tx).HasAnyOrgRole(ctx, datastore.HasAnyOrgRoleParams{
+		UserID:  adt.User.ID,
+		OrgID:   o.ID,
+		RoleCds: []string{role.Code},
+	})
+	if err != nil {
+		return nil, errs.E(op, errs.Database, err)
+	}
+	if !hasRole {
+		return nil, errs.E(op, errs.Unauthorized, fmt.Sprintf("user does not have the %s role in the org and cannot invite others to it", role.Code))
+	}
+
+	inv := diygoapi.Invitation{
+		ID:         uuid.New(),
+		ExternalID: secure.NewID(),
+		Org:        &o,
+		Role:       &role,
+		Email:      r.Email,
+		// the token holds the expiry in seconds
+		Expiry:    adt.Moment.Add(diygoapi.InvitationValidity).Truncate(time.Second),
+		SendCount: 1,
+		LastSent:  adt.Moment,
+	}
+
+	params := datastore.CreateOrgInvitationParams{
+		OrgInvitationID:     inv.ID,
+		OrgInvitationExtlID: inv.ExternalID.String(),
+		OrgID:               inv.Org.ID,
+		RoleID:              inv.Role.ID,
+		Email:               inv.Email,
+		ExpiryTimestamp:     inv.Expiry,
+		LastSentTimestamp:   inv.LastSent,
+		CreateAppID:         adt.App.ID,
+		CreateUserID:        adt.User.NullUUID(),
+		CreateTimestamp:     adt.Moment,
+		UpdateAppID:         adt.App.ID,
+		UpdateUserID:        adt.User.NullUUID(),
+		UpdateTimestamp:     adt.Moment,
+	}
+
+	var rowsAffected int64
+	rowsAffected, err = datastore.New(tx).CreateOrgInvitation(ctx, params)
+	if err != nil {
+		return nil, errs.E(op, errs.Database, err)
+	}
+
+	if rowsAffected != 1 {
+		return nil, errs.E(op, errs.Database, fmt.Sprintf("CreateOrgInvitation() should insert 1 row, actual: %d", rowsAffected))
+	}
+
+	// commit db txn using pgxpool
+	err = s.Datastorer.CommitTx(ctx, tx)
+	if err != nil {
+		return nil, errs.E(op, err)
+	}
+
+	response = newInvitationResponse(inv, adt.Moment)
+	response.Token = inv.Token(s.EncryptionKey)
+
+	return response, nil
+}
+
+// FindByOrg lists the invitations of an Org, most recent first
+func (s *InvitationService) FindByOrg(ctx context.Context, orgExtlID string, adt diygoapi.Audit) (responses []*diygoapi.InvitationResponse, err error) {
+	const op errs.Op = "service/InvitationService.FindByOrg"
+
+	// start db txn using pgxpool
+	var tx pgx.Tx
+	tx, err = s.Datastorer.BeginTx(ctx)
+	if err != nil {
+		return nil, errs.E(op, err)
+	}
+	// defer transaction rollback and handle error, if any
+	defer func() {
+		err = s.Datastorer.RollbackTx(ctx, tx, err)
+	}()
+
+	var o diygoapi.Org
+	o, err = findOrgByExternalID(ctx, tx, orgExtlID)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, errs.E(op, errs.NotExist, "No org exists for the given external ID")
+		}
+		return nil, errs.E(op, err)
+	}
+
+	// invitations hold the emails of people outside the org, only an
+	// admin of the org may list them
+	err = authorizeOwner(ctx, tx, adt, diygoapi.Owner{OrgID: o.ID})
+	if err != nil {
+		return nil, errs.E(op, err)
+	}
+
+	var rows []datastore.FindOrgInvitationsByOrgIDRow
+	rows, err = datastore.New(tx).FindOrgInvitationsByOrgID(ctx, o.ID)
+	if err != nil {
+		return nil, errs.E(op, errs.Database, err)
+	}
+
+	for _, row := range rows {
+		responses = append(responses, newInvitationResponse(newInvitation(datastore.FindOrgInvitationByExternalIDRow(row)), adt.Moment))
+	}
+
+	// commit db txn using pgxpool
+	err = s.Datastorer.CommitTx(ctx, tx)
+	if err != nil {
+		return nil, errs.E(op, err)
+	}
+
+	return responses, nil
+}
+
+// Resend issues a new token, with a new expiry, for an invitation
+// which has not been accepted or revoked. Tokens issued before are no
+// longer valid. The response holds the new token, which is to be
+// delivered to the invited person.
+func (s *InvitationService) Resend(ctx context.Context, orgExtlID, extlID string, adt diygoapi.Audit) (response *diygoapi.InvitationResponse, err error) {
+	const op errs.Op = "service/InvitationService.Resend"
+
+	// start db txn using pgxpool
+	var tx pgx.Tx
+	tx, err = s.Datastorer.BeginTx(ctx)
+	if err != nil {
+		return nil, errs.E(op, err)
+	}
+	// defer transaction rollback and handle error, if any
+	defer func() {
+		err = s.Datastorer.RollbackTx(ctx, tx, err)
+	}()
+
+	var inv diygoapi.Invitation
+	inv, err = findOrgInvitation(ctx, tx, orgExtlID, extlID)
+	if err != nil {
+		return nil, errs.E(op, err)
+	}
+
+	err = authorizeOwner(ctx, tx, adt, diygoapi.Owner{OrgID: inv.Org.ID})
+	if err != nil {
+		return nil, errs.E(op, err)
+	}
+
+	switch inv.Status(adt.Moment) {
+	case diygoapi.InvitationStatusAccepted, diygoapi.InvitationStatusRevoked:
+		return nil, errs.E(op, errs.Validation, fmt.Sprintf("invitation %s has been %s and cannot be resent", extlID, inv.Status(adt.Moment)))
+	}
+
+	inv.Expiry = adt.Moment.Add(diygoapi.InvitationValidity).Truncate(time.Second)
+	inv.SendCount++
+	inv.LastSent = adt.Moment
+
+	params := datastore.ResendOrgInvitationParams{
+		ExpiryTimestamp:   inv.Expiry,
+		LastSentTimestamp: inv.LastSent,
+		UpdateAppID:       adt.App.ID,
+		UpdateUserID:      adt.User.NullUUID(),
+		UpdateTimestamp:   adt.Moment,
+		OrgInvitationID:   inv.ID,
+	}
+
+	var rowsAffected int64
+	rowsAffected, err = datastore.New(tx).ResendOrgInvitation(ctx, params)
+	if err != nil {
+		return nil, errs.E(op, errs.Database, err)
+	}
+
+	if rowsAffected != 1 {
+		return nil, errs.E(op, errs.Database, fmt.Sprintf("rows affected should be 1, actual: %d", rowsAffected))
+	}
+
+	// commit db txn using pgxpool
+	err = s.Datastorer.CommitTx(ctx, tx)
+	if err != nil {
+		return nil, errs.E(op, err)
+	}
+
+	response = newInvitationResponse(inv, adt.Moment)
+	response.Token = inv.Token(s.EncryptionKey)
+
+	return response, nil
+}
+
+// Revoke revokes an invitation which has not been accepted. The
+// invitation can no longer be accepted or resent.
+func (s *InvitationService) Revoke(ctx context.Context, orgExtlID, extlID string, adt diygoapi.Audit) (dr diygoapi.DeleteResponse, err error) {
+	const op errs.Op = "service/InvitationService.Revoke"
+
+	// start db txn using pgxpool
+	var tx pgx.Tx
+	tx, err = s.Datastorer.BeginTx(ctx)
+	if err != nil {
+		return diygoapi.DeleteResponse{}, errs.E(op, err)
+	}
+	// defer transaction rollback and handle error, if any
+	defer func() {
+		err = s.Datastorer.RollbackTx(ctx, tx, err)
+	}()
+
+	var inv diygoapi.Invitation
+	inv, err = findOrgInvitation(ctx, tx, orgExtlID, extlID)
+	if err != nil {
+		return diygoapi.DeleteResponse{}, errs.E(op, err)
+	}
+
+	err = authorizeOwner(ctx, tx, adt, diygoapi.Owner{OrgID: inv.Org.ID})
+	if err != nil {
+		return diygoapi.DeleteResponse{}, errs.E(op, err)
+	}
+
+	switch inv.Status(adt.Moment) {
+	case diygoapi.InvitationStatusAccepted, diygoapi.InvitationStatusRevoked:
+		return diygoapi.DeleteResponse{}, errs.E(op, errs.Validation, fmt.Sprintf("invitation %s has already been %s", extlID, inv.Status(adt.Moment)))
+	}
+
+	params := datastore.RevokeOrgInvitationParams{
+		RevokedTimestamp: diygoapi.NewNullTime(adt.Moment),
+		UpdateAppID:      adt.App.ID,
+		UpdateUserID:     adt.User.NullUUID(),
+		UpdateTimestamp:  adt.Moment,
+		OrgInvitationID:  inv.ID,
+	}
+
+	var rowsAffected int64
+	rowsAffected, err = datastore.New(tx).RevokeOrgInvitation(ctx, params)
+	if err != nil {
+		return diygoapi.DeleteResponse{}, errs.E(op, errs.Database, err)
+	}
+
+	if rowsAffected != 1 {
+		return diygoapi.DeleteResponse{}, errs.E(op, errs.Database, fmt.Sprintf("rows affected should be 1, actual: %d", rowsAffected))
+	}
+
+	// commit db txn using pgxpool
+	err = s.Datastorer.CommitTx(ctx, tx)
+	if err != nil {
+		return diygoapi.DeleteResponse{}, errs.E(op, err)
+	}
+
+	response := diygoapi.DeleteResponse{
+		ExternalID: extlID,
+		Deleted:    true,
+	}
+
+	return response, nil
+}
+
+type acceptInvitationParams struct {
+	// Token is the invitation token sent by the invited person
+	Token string
+	// EncryptionKey is the key the token signing key is derived from
+	EncryptionKey *[32]byte
+	// Email is the email given by the authentication provider
+	Email string
+	// EmailVerified is true when the authentication provider has
+	// verified the User owns Email
+	EmailVerified bool
+	// Audit is the audit of the authenticated User accepting the invitation
+	Audit diygoapi.Audit
+}
+
+// acceptInvitation accepts the invitation of the given token on behalf
+// of the audit User. The User is attached to the Org, if not already a
+// member, and given the invited Role. Accepting an invitation the User
+// has already accepted does nothing.
+func acceptInvitation(ctx context.Context, tx pgx.Tx, p acceptInvitationParams) error {
+	const op errs.Op = "service/acceptInvitation"
+
+	extlID, expiry, err := diygoapi.ParseInvitationToken(p.Token, p.EncryptionKey, p.Audit.Moment)
+	if err != nil {
+		return errs.E(op, err)
+	}
+
+	var row datastore.FindOrgInvitationByExternalIDRow
+	row, err = datastore.New(tx).FindOrgInvitationByExternalID(ctx, extlID)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return errs.E(op, errs.Validation, "no invitation exists for the invitation token")
+		}
+		return errs.E(op, errs.Database, err)
+	}
+	inv := newInvitation(row)
+
+	switch {
+	case inv.AcceptedBy != nil && inv.AcceptedBy.ID == p.Audit.User.ID:
+		return nil
+	case inv.Status(p.Audit.Moment) != diygoapi.InvitationStatusPending:
+		return errs.E(op, errs.Validation, fmt.Sprintf("invitation has been %s", inv.Status(p.Audit.Moment)))
+	case !inv.Expiry.Equal(expiry):
+		// the invitation was resent, only the latest token is valid
+		return errs.E(op, errs.Validation, "invitation token has been replaced by a newer one")
+	case !p.EmailVerified:
+		return errs.E(op, errs.Unauthorized, "the email of the authenticated user has not been verified by the provider")
+	case !strings.EqualFold(strings.TrimSpace(p.Email), inv.Email):
+		return errs.E(op, errs.Unauthorized, "the email of the authenticated user does not match the invitation")
+	}
+
+	// attach the user to the org, unless already a member
+	_, err = datastore.New(tx).FindUserOrgByExtlID(ctx, datastore.FindUserOrgByExtlIDParams{OrgExtlID: inv.Org.ExternalID.String(), UserID: p.Audit.User.ID})
+	if err != nil {
+		if !errors.Is(err, pgx.ErrNoRows) {
+			return errs.E(op, errs.Database, err)
+		}
+		err = attachOrgAssociation(ctx, tx, attachOrgAssociationParams{Org: inv.Org, User: p.Audit.User, Audit: p.Audit})
+		if err != nil {
+			return errs.E(op, err)
+		}
+	}
+
+	// grant the role, unless already held
+	_, err = datastore.New(tx).FindUsersRole(ctx, datastore.FindUsersRoleParams{UserID: p.Audit.User.ID, RoleID: inv.Role.ID, OrgID: inv.Org.ID})
+	if err != nil {
+		if !errors.Is(err, pgx.ErrNoRows) {
+			return errs.E(op, errs.Database, err)
+		}
+		err = assignOrgRole(ctx, tx, assignOrgRoleParams{Role: *inv.Role, User: p.Audit.User, Org: inv.Org, Audit: p.Audit})
+		if err != nil {
+			return errs.E(op, err)
+		}
+	}
+
+	params := datastore.AcceptOrgInvitationParams{
+		AcceptedUserID:    p.Audit.User.NullUUID(),
+		AcceptedTimestamp: diygoapi.NewNullTime(p.Audit.Moment),
+		UpdateAppID:       p.Audit.App.ID,
+		UpdateUserID:      p.Audit.User.NullUUID(),
+		UpdateTimestamp:   p.Audit.Moment,
+		OrgInvitationID:   inv.ID,
+	}
+
+	var rowsAffected int64
+	rowsAffected, err = datastore.New(tx).AcceptOrgInvitation(ctx, params)
+	if err != nil {
+		return errs.E(op, errs.Database, err)
+	}
+
+	if rowsAffected != 1 {
+		return errs.E(op, errs.Database, fmt.Sprintf("rows affected should be 1, actual: %d", rowsAffected))
+	}
+
+	return nil
+}
+
+// findOrgInvitation finds an invitation given its external ID and the
+// external ID of the Org it belongs to.
+func findOrgInvitation(ctx context.Context, tx pgx.Tx, orgExtlID, extlID string) (diygoapi.Invitation, error) {
+	const op errs.Op = "service/findOrgInvitation"
+
+	row, err := datastore.New(tx).FindOrgInvitationByExternalID(ctx, extlID)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return diygoapi.Invitation{}, errs.E(op, errs.NotExist, fmt.Sprintf("no invitation found with external ID: %s", extlID))
+		}
+		return diygoapi.Invitation{}, errs.E(op, errs.Database, err)
+	}
+
+	if row.OrgExtlID != orgExtlID {
+		return diygoapi.Invitation{}, errs.E(op, errs.NotExist, fmt.Sprintf("no invitation found with external ID %s for org %s", extlID, orgExtlID))
+	}
+
+	return newInvitation(row), nil
+}
+
+// newInvitation initializes an Invitation given a row selected from
+// the datastore. The rows of FindOrgInvitationsByOrgID have the same
+// fields and can be converted to this type.
+func newInvitation(row datastore.FindOrgInvitationByExternalIDRow) diygoapi.Invitation {
+	inv := diygoapi.Invitation{
+		ID:         row.OrgInvitationID,
+		ExternalID: secure.MustParseIdentifier(row.OrgInvitationExtlID),
+		Org:        &diygoapi.Org{ID: row.OrgID, ExternalID: secure.MustParseIdentifier(row.OrgExtlID)},
+		Role:       &diygoapi.Role{ID: row.RoleID, Code: row.RoleCd},
+		Email:      row.Email,
+		Expiry:     row.ExpiryTimestamp,
+		SendCount:  row.SendCount,
+		LastSent:   row.LastSentTimestamp,
+		AcceptedAt: row.AcceptedTimestamp.Time,
+		RevokedAt:  row.RevokedTimestamp.Time,
+	}
+
+	if row.AcceptedUserID.Valid {
+		inv.AcceptedBy = &diygoapi.User{ID: row.AcceptedUserID.UUID, ExternalID: secure.MustParseIdentifier(row.AcceptedUserExtlID.String)}
+	}
+
+	return inv
+}
+
+// newInvitationResponse initializes an InvitationResponse given an
+// Invitation. The status is determined as of now. The token is not set.
+func newInvitationResponse(inv diygoapi.Invitation, now time.Time) *diygoapi.InvitationResponse {
+	response := &diygoapi.InvitationResponse{
+		ExternalID:       inv.ExternalID.String(),
+		OrgExtlID:        inv.Org.ExternalID.String(),
+		Email:            inv.Email,
+		RoleCode:         inv.Role.Code,
+		Status:           inv.Status(now),
+		ExpiryDateTime:   inv.Expiry.Format(time.RFC3339),
+		SendCount:        inv.SendCount,
+		LastSentDateTime: inv.LastSent.Format(time.RFC3339),
+	}
+
+	if inv.AcceptedBy != nil {
+		response.AcceptedUserExtlID = inv.AcceptedBy.ExternalID.String()
+		response.AcceptedDateTime = inv.AcceptedAt.Format(time.RFC3339)
+	}
+	if !inv.RevokedAt.IsZero() {
+		response.RevokedDateTime = inv.RevokedAt.Format(time.RFC3339)
+	}
+
+	return response
+}
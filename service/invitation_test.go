@@ -0,0 +1,72 @@
+package service_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	qt "github.com/frankban/quicktest"
+
+	"github.com/gilcrest/diygoapi"
+	"github.com/gilcrest/diygoapi/errs"
+	"github.com/gilcrest/diygoapi/secure"
+	"github.com/gilcrest/diygoapi/service"
+	"github.com/gilcrest/diygoapi/sqldb/sqldbtest"
+)
+
+func TestInvitationService_Create(t *testing.T) {
+	c := qt.New(t)
+
+	db, cleanup := sqldbtest.NewDB(t)
+	c.Cleanup(cleanup)
+
+	ek, err := secure.NewEncryptionKey()
+	c.Assert(err, qt.IsNil)
+
+	// holder is an orgAdmin of org holding the test role as well,
+	// admin is an orgAdmin of org only
+	ctx := context.Background()
+	tx, err := db.BeginTx(ctx)
+	if err != nil {
+		c.Fatalf("BeginTx() error = %v", err)
+	}
+	adt := findTestAudit(ctx, c, tx)
+	org := createTestOrg(ctx, c, tx, adt, nil)
+	holder := createTestUser(ctx, c, tx, adt, org)
+	admin := createTestUser(ctx, c, tx, adt, org)
+	grantTestRole(ctx, c, tx, adt, holder, org, diygoapi.OrgAdminRoleCode, time.Time{}, time.Time{})
+	grantTestRole(ctx, c, tx, adt, holder, org, service.TestRoleCode, time.Time{}, time.Time{})
+	grantTestRole(ctx, c, tx, adt, admin, org, diygoapi.OrgAdminRoleCode, time.Time{}, time.Time{})
+	err = db.CommitTx(ctx, tx)
+	c.Assert(err, qt.IsNil)
+
+	s := service.InvitationService{Datastorer: db, EncryptionKey: ek}
+
+	tests := []struct {
+		name     string
+		inviter  *diygoapi.User
+		roleCode string
+		wantErr  bool
+	}{
+		{"held role", holder, service.TestRoleCode, false},
+		{"role not held", admin, service.TestRoleCode, true},
+		{diygoapi.OrgAdminRoleCode, holder, diygoapi.OrgAdminRoleCode, true},
+		{diygoapi.SysAdminRoleCode, holder, diygoapi.SysAdminRoleCode, true},
+	}
+	for _, tt := range tests {
+		c.Run(tt.name, func(c *qt.C) {
+			r := &diygoapi.CreateInvitationRequest{
+				OrgExternalID: org.ExternalID.String(),
+				Email:         "invitee@example.com",
+				RoleCode:      tt.roleCode,
+			}
+			response, err := s.Create(ctx, r, diygoapi.Audit{App: adt.App, User: tt.inviter, Org: org, Moment: time.Now()})
+			if tt.wantErr {
+				c.Assert(errs.KindIs(errs.Unauthorized, err), qt.IsTrue, qt.Commentf("error = %v", err))
+				return
+			}
+			c.Assert(err, qt.IsNil)
+			c.Assert(response.Token, qt.Not(qt.Equals), "")
+		})
+	}
+}
@@ -22,7 +22,7 @@ type movieAudit struct {
 
 // newMovieResponse initializes MovieResponse
 func newMovieResponse(ma movieAudit) *diygoapi.MovieResponse {
-	return &diygoapi.MovieResponse{
+	mr := &diygoapi.MovieResponse{
 		ExternalID:          ma.Movie.ExternalID.String(),
 		Title:               ma.Movie.Title,
 		Rated:               ma.Movie.Rated,
@@ -39,6 +39,64 @@ func newMovieResponse(ma movieAudit) *diygoapi.MovieResponse {
 		UpdateUserLastName:  ma.SimpleAudit.Update.User.LastName,
 		UpdateDateTime:      ma.SimpleAudit.Update.Moment.Format(time.RFC3339),
 	}
+	if !ma.Movie.DeletedAt.IsZero() {
+		mr.DeletedDateTime = ma.Movie.DeletedAt.Format(time.RFC3339)
+	}
+
+	return mr
+}
+
+// newMovieAudit initializes a movieAudit given a row retrieved with
+// FindMovieByExternalIDWithAudit
+func newMovieAudit(row datastore.FindMovieByExternalIDWithAuditRow) movieAudit {
+	m := diygoapi.Movie{
+		ID:         row.MovieID,
+		ExternalID: secure.MustParseIdentifier(row.ExtlID),
+		Title:      row.Title,
+		Rated:      row.Rated.String,
+		Released:   row.Released.Time,
+		RunTime:    int(row.RunTime.Int32),
+		Director:   row.Director.String,
+		Writer:     row.Writer.String,
+		DeletedAt:  row.DeletedTimestamp.Time,
+	}
+
+	sa := diygoapi.SimpleAudit{
+		Create: diygoapi.Audit{
+			App: &diygoapi.App{
+				ID:          row.CreateAppID,
+				ExternalID:  secure.MustParseIdentifier(row.CreateAppExtlID),
+				Org:         &diygoapi.Org{ID: row.CreateAppOrgID},
+				Name:        row.CreateAppName,
+				Description: row.CreateAppDescription,
+				APIKeys:     nil,
+			},
+			User: &diygoapi.User{
+				ID:        row.CreateUserID.UUID,
+				FirstName: row.CreateUserFirstName.String,
+				LastName:  row.CreateUserLastName.String,
+			},
+			Moment: row.CreateTimestamp,
+		},
+		Update: diygoapi.Audit{
+			App: &diygoapi.App{
+				ID:          row.UpdateAppID,
+				ExternalID:  secure.MustParseIdentifier(row.UpdateAppExtlID),
+				Org:         &diygoapi.Org{ID: row.UpdateAppOrgID},
+				Name:        row.UpdateAppName,
+				Description: row.UpdateAppDescription,
+				APIKeys:     nil,
+			},
+			User: &diygoapi.User{
+				ID:        row.UpdateUserID.UUID,
+				FirstName: row.UpdateUserFirstName.String,
+				LastName:  row.UpdateUserLastName.String,
+			},
+			Moment: row.UpdateTimestamp,
+		},
+	}
+
+	return movieAudit{Movie: m, SimpleAudit: sa}
 }
 
 // MovieService is a service for creating a Movie
@@ -155,7 +213,7 @@ func (s *MovieService) Update(ctx context.Context, r *diygoapi.UpdateMovieReques
 
 	// retrieve existing Movie
 	var row datastore.FindMovieByExternalIDWithAuditRow
-	row, err = datastore.New(tx).FindMovieByExternalIDWithAudit(ctx, r.ExternalID)
+	row, err = datastore.New(tx).FindMovieByExternalIDWithAudit(ctx, datastore.FindMovieByExternalIDWithAuditParams{ExtlID: r.ExternalID})
 	if err != nil {
 		if err == pgx.ErrNoRows {
 			return nil, errs.E(op, errs.Validation, "No movie exists for the given external ID")
@@ -163,6 +221,13 @@ func (s *MovieService) Update(ctx context.Context, r *diygoapi.UpdateMovieReques
 		return nil, errs.E(op, errs.Database, err)
 	}
 
+	// only the user who created the movie or an admin of the
+	// org which owns it (the org of the creating app) may update it
+	err = authorizeOwner(ctx, tx, adt, diygoapi.Owner{OrgID: row.CreateAppOrgID, UserID: row.CreateUserID})
+	if err != nil {
+		return nil, errs.E(op, err)
+	}
+
 	m := diygoapi.Movie{
 		ID:         row.MovieID,
 		ExternalID: secure.MustParseIdentifier(row.ExtlID),
@@ -237,8 +302,9 @@ func (s *MovieService) Update(ctx context.Context, r *diygoapi.UpdateMovieReques
 	return mr, nil
 }
 
-// Delete is used to delete a movie
-func (s *MovieService) Delete(ctx context.Context, extlID string) (dr diygoapi.DeleteResponse, err error) {
+// Delete is used to soft delete a movie. The movie is kept until it is
+// purged and can be restored until then.
+func (s *MovieService) Delete(ctx context.Context, extlID string, adt diygoapi.Audit) (dr diygoapi.DeleteResponse, err error) {
 	const op errs.Op = "service/MovieService.Delete"
 
 	// start db txn using pgxpool
@@ -253,8 +319,8 @@ func (s *MovieService) Delete(ctx context.Context, extlID string) (dr diygoapi.D
 	}()
 
 	// retrieve existing Movie
-	var dbm datastore.Movie
-	dbm, err = datastore.New(tx).FindMovieByExternalID(ctx, extlID)
+	var row datastore.FindMovieByExternalIDWithAuditRow
+	row, err = datastore.New(tx).FindMovieByExternalIDWithAudit(ctx, datastore.FindMovieByExternalIDWithAuditParams{ExtlID: extlID})
 	if err != nil {
 		if err == pgx.ErrNoRows {
 			return diygoapi.DeleteResponse{}, errs.E(op, errs.Validation, "No movie exists for the given external ID")
@@ -262,8 +328,22 @@ func (s *MovieService) Delete(ctx context.Context, extlID string) (dr diygoapi.D
 		return diygoapi.DeleteResponse{}, errs.E(op, errs.Database, err)
 	}
 
+	// only the user who created the movie or an admin of the
+	// org which owns it (the org of the creating app) may delete it
+	err = authorizeOwner(ctx, tx, adt, diygoapi.Owner{OrgID: row.CreateAppOrgID, UserID: row.CreateUserID})
+	if err != nil {
+		return diygoapi.DeleteResponse{}, errs.E(op, err)
+	}
+
+	params := datastore.SoftDeleteMovieParams{
+		DeletedAppID:     diygoapi.NewNullUUID(adt.App.ID),
+		DeletedUserID:    adt.User.NullUUID(),
+		DeletedTimestamp: diygoapi.NewNullTime(adt.Moment),
+		MovieID:          row.MovieID,
+	}
+
 	var rowsAffected int64
-	rowsAffected, err = datastore.New(tx).DeleteMovie(ctx, dbm.MovieID)
+	rowsAffected, err = datastore.New(tx).SoftDeleteMovie(ctx, params)
 	if err != nil {
 		return diygoapi.DeleteResponse{}, errs.E(op, errs.Database, err)
 	}
@@ -279,16 +359,16 @@ func (s *MovieService) Delete(ctx context.Context, extlID string) (dr diygoapi.D
 	}
 
 	response := diygoapi.DeleteResponse{
-		ExternalID: dbm.ExtlID,
+		ExternalID: row.ExtlID,
 		Deleted:    true,
 	}
 
 	return response, nil
 }
 
-// FindMovieByExternalID is used to find an individual movie
-func (s *MovieService) FindMovieByExternalID(ctx context.Context, extlID string) (mr *diygoapi.MovieResponse, err error) {
-	const op errs.Op = "service/MovieService.FindMovieByExternalID"
+// Restore undoes the deletion of a movie
+func (s *MovieService) Restore(ctx context.Context, extlID string, adt diygoapi.Audit) (mr *diygoapi.MovieResponse, err error) {
+	const op errs.Op = "service/MovieService.Restore"
 
 	// start db txn using pgxpool
 	var tx pgx.Tx
@@ -301,68 +381,82 @@ func (s *MovieService) FindMovieByExternalID(ctx context.Context, extlID string)
 		err = s.Datastorer.RollbackTx(ctx, tx, err)
 	}()
 
-	var row datastore.FindMovieByExternalIDWithAuditRow
-	row, err = datastore.New(tx).FindMovieByExternalIDWithAudit(ctx, extlID)
+	var ma movieAudit
+	ma, err = findMovieByExternalIDWithAudit(ctx, tx, extlID, true)
+	if err != nil {
+		return nil, errs.E(op, err)
+	}
+
+	if ma.Movie.DeletedAt.IsZero() {
+		return nil, errs.E(op, errs.NotExist, fmt.Sprintf("movie %s is not deleted", extlID))
+	}
+
+	// only the user who created the movie or an admin of the
+	// org which owns it (the org of the creating app) may restore it
+	err = authorizeOwner(ctx, tx, adt, diygoapi.Owner{OrgID: ma.SimpleAudit.Create.App.Org.ID, UserID: diygoapi.NewNullUUID(ma.SimpleAudit.Create.User.ID)})
+	if err != nil {
+		return nil, errs.E(op, err)
+	}
+
+	params := datastore.RestoreMovieParams{
+		UpdateAppID:     adt.App.ID,
+		UpdateUserID:    adt.User.NullUUID(),
+		UpdateTimestamp: adt.Moment,
+		MovieID:         ma.Movie.ID,
+	}
+
+	var rowsAffected int64
+	rowsAffected, err = datastore.New(tx).RestoreMovie(ctx, params)
 	if err != nil {
-		if err == pgx.ErrNoRows {
-			return nil, errs.E(op, errs.Validation, "no movie exists for the given external ID")
-		}
 		return nil, errs.E(op, errs.Database, err)
 	}
 
-	m := diygoapi.Movie{
-		ID:         row.MovieID,
-		ExternalID: secure.MustParseIdentifier(row.ExtlID),
-		Title:      row.Title,
-		Rated:      row.Rated.String,
-		Released:   row.Released.Time,
-		RunTime:    int(row.RunTime.Int32),
-		Director:   row.Director.String,
-		Writer:     row.Writer.String,
+	if rowsAffected != 1 {
+		return nil, errs.E(op, errs.Database, fmt.Sprintf("RestoreMovie() should update 1 row, actual: %d", rowsAffected))
 	}
 
-	sa := diygoapi.SimpleAudit{
-		Create: diygoapi.Audit{
-			App: &diygoapi.App{
-				ID:          row.CreateAppID,
-				ExternalID:  secure.MustParseIdentifier(row.CreateAppExtlID),
-				Org:         &diygoapi.Org{ID: row.CreateAppOrgID},
-				Name:        row.CreateAppName,
-				Description: row.CreateAppDescription,
-				APIKeys:     nil,
-			},
-			User: &diygoapi.User{
-				ID:        row.CreateUserID.UUID,
-				FirstName: row.CreateUserFirstName.String,
-				LastName:  row.CreateUserLastName.String,
-			},
-			Moment: row.CreateTimestamp,
-		},
-		Update: diygoapi.Audit{
-			App: &diygoapi.App{
-				ID:          row.UpdateAppID,
-				ExternalID:  secure.MustParseIdentifier(row.UpdateAppExtlID),
-				Org:         &diygoapi.Org{ID: row.UpdateAppOrgID},
-				Name:        row.UpdateAppName,
-				Description: row.UpdateAppDescription,
-				APIKeys:     nil,
-			},
-			User: &diygoapi.User{
-				ID:        row.UpdateUserID.UUID,
-				FirstName: row.UpdateUserFirstName.String,
-				LastName:  row.UpdateUserLastName.String,
-			},
-			Moment: row.UpdateTimestamp,
-		},
+	// commit db txn using pgxpool
+	err = s.Datastorer.CommitTx(ctx, tx)
+	if err != nil {
+		return nil, errs.E(op, err)
 	}
 
-	mr = newMovieResponse(movieAudit{m, sa})
+	ma.Movie.DeletedAt = time.Time{}
+	ma.SimpleAudit.Update = adt
+
+	return newMovieResponse(ma), nil
+}
+
+// FindMovieByExternalID is used to find an individual movie. Deleted
+// movies are only found if includeDeleted is true.
+func (s *MovieService) FindMovieByExternalID(ctx context.Context, extlID string, includeDeleted bool) (mr *diygoapi.MovieResponse, err error) {
+	const op errs.Op = "service/MovieService.FindMovieByExternalID"
+
+	// start db txn using pgxpool
+	var tx pgx.Tx
+	tx, err = s.Datastorer.BeginTx(ctx)
+	if err != nil {
+		return nil, errs.E(op, err)
+	}
+	// defer transaction rollback and handle error, if any
+	defer func() {
+		err = s.Datastorer.RollbackTx(ctx, tx, err)
+	}()
+
+	var ma movieAudit
+	ma, err = findMovieByExternalIDWithAudit(ctx, tx, extlID, includeDeleted)
+	if err != nil {
+		return nil, errs.E(op, err)
+	}
+
+	mr = newMovieResponse(ma)
 
 	return mr, nil
 }
 
-// FindAllMovies is used to list all movies in the db
-func (s *MovieService) FindAllMovies(ctx context.Context) (smr []*diygoapi.MovieResponse, err error) {
+// FindAllMovies is used to list all movies in the db. Deleted movies
+// are only listed if includeDeleted is true.
+func (s *MovieService) FindAllMovies(ctx context.Context, includeDeleted bool) (smr []*diygoapi.MovieResponse, err error) {
 	const op errs.Op = "service/MovieService.FindAllMovies"
 
 	// start db txn using pgxpool
@@ -377,7 +471,7 @@ func (s *MovieService) FindAllMovies(ctx context.Context) (smr []*diygoapi.Movie
 	}()
 
 	var rows []datastore.FindMoviesRow
-	rows, err = datastore.New(tx).FindMovies(ctx)
+	rows, err = datastore.New(tx).FindMovies(ctx, includeDeleted)
 	if err != nil {
 		if err == pgx.ErrNoRows {
 			return nil, errs.E(op, errs.Validation, "no movies exists")
@@ -386,53 +480,26 @@ func (s *MovieService) FindAllMovies(ctx context.Context) (smr []*diygoapi.Movie
 	}
 
 	for _, row := range rows {
-		m := diygoapi.Movie{
-			ID:         row.MovieID,
-			ExternalID: secure.MustParseIdentifier(row.ExtlID),
-			Title:      row.Title,
-			Rated:      row.Rated.String,
-			Released:   row.Released.Time,
-			RunTime:    int(row.RunTime.Int32),
-			Director:   row.Director.String,
-			Writer:     row.Writer.String,
-		}
-		sa := diygoapi.SimpleAudit{
-			Create: diygoapi.Audit{
-				App: &diygoapi.App{
-					ID:          row.CreateAppID,
-					ExternalID:  secure.MustParseIdentifier(row.CreateAppExtlID),
-					Org:         &diygoapi.Org{ID: row.CreateAppOrgID},
-					Name:        row.CreateAppName,
-					Description: row.CreateAppDescription,
-					APIKeys:     nil,
-				},
-				User: &diygoapi.User{
-					ID:        row.CreateUserID.UUID,
-					FirstName: row.CreateUserFirstName.String,
-					LastName:  row.CreateUserLastName.String,
-				},
-				Moment: row.CreateTimestamp,
-			},
-			Update: diygoapi.Audit{
-				App: &diygoapi.App{
-					ID:          row.UpdateAppID,
-					ExternalID:  secure.MustParseIdentifier(row.UpdateAppExtlID),
-					Org:         &diygoapi.Org{ID: row.UpdateAppOrgID},
-					Name:        row.UpdateAppName,
-					Description: row.UpdateAppDescription,
-					APIKeys:     nil,
-				},
-				User: &diygoapi.User{
-					ID:        row.UpdateUserID.UUID,
-					FirstName: row.UpdateUserLastName.String,
-					LastName:  row.UpdateUserLastName.String,
-				},
-				Moment: row.UpdateTimestamp,
-			},
-		}
-		mr := newMovieResponse(movieAudit{m, sa})
+		mr := newMovieResponse(newMovieAudit(datastore.FindMovieByExternalIDWithAuditRow(row)))
 		smr = append(smr, mr)
 	}
 
 	return smr, nil
 }
+
+// findMovieByExternalIDWithAudit retrieves a Movie and its audit data
+// given its external ID. Deleted movies are only found if
+// includeDeleted is true.
+func findMovieByExternalIDWithAudit(ctx context.Context, dbtx diygoapi.DBTX, extlID string, includeDeleted bool) (movieAudit, error) {
+	const op errs.Op = "service/findMovieByExternalIDWithAudit"
+
+	row, err := datastore.New(dbtx).FindMovieByExternalIDWithAudit(ctx, datastore.FindMovieByExternalIDWithAuditParams{ExtlID: extlID, IncludeDeleted: includeDeleted})
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			return movieAudit{}, errs.E(op, errs.Validation, "no movie exists for the given external ID")
+		}
+		return movieAudit{}, errs.E(op, errs.Database, err)
+	}
+
+	return newMovieAudit(row), nil
+}
@@ -133,7 +133,7 @@ func TestMovieService(t *testing.T) {
 		s := service.MovieService{Datastorer: db}
 
 		var got *diygoapi.MovieResponse
-		got, err = s.FindMovieByExternalID(context.Background(), dbm.ExtlID)
+		got, err = s.FindMovieByExternalID(context.Background(), dbm.ExtlID, false)
 		want := "The Return of the Living Dead"
 		c.Assert(err, qt.IsNil)
 		c.Assert(got.Title, qt.Equals, want)
@@ -154,7 +154,7 @@ func TestMovieService(t *testing.T) {
 			got []*diygoapi.MovieResponse
 			err error
 		)
-		got, err = s.FindAllMovies(ctx)
+		got, err = s.FindAllMovies(ctx, false)
 		c.Assert(err, qt.IsNil)
 		c.Assert(len(got) >= 1, qt.IsTrue, qt.Commentf("movies found = %d", len(got)))
 		c.Logf("movies found = %d", len(got))
@@ -258,8 +258,10 @@ func TestMovieService(t *testing.T) {
 			Datastorer: db,
 		}
 
+		adt := findPrincipalTestAudit(ctx, c, tx)
+
 		var got diygoapi.DeleteResponse
-		got, err = s.Delete(context.Background(), dbm.ExtlID)
+		got, err = s.Delete(context.Background(), dbm.ExtlID, adt)
 		want := diygoapi.DeleteResponse{
 			ExternalID: dbm.ExtlID,
 			Deleted:    true,
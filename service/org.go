@@ -7,6 +7,7 @@ import (
 	"time"
 
 	"github.com/google/uuid"
+	"github.com/jackc/pgconn"
 	"github.com/jackc/pgx/v4"
 
 	"github.com/gilcrest/diygoapi"
@@ -38,6 +39,14 @@ func newOrgResponse(oa *orgAudit, aa appAudit) *diygoapi.OrgResponse {
 		UpdateDateTime:      oa.SimpleAudit.Update.Moment.Format(time.RFC3339),
 	}
 
+	if oa.Org.Parent != nil {
+		r.ParentExternalID = oa.Org.Parent.ExternalID.String()
+	}
+
+	if !oa.Org.DeletedAt.IsZero() {
+		r.DeletedDateTime = oa.Org.DeletedAt.Format(time.RFC3339)
+	}
+
 	if aa.App != nil {
 		r.App = newAppResponse(aa)
 	}
@@ -99,6 +108,13 @@ func (s *OrgService) Create(ctx context.Context, r *diygoapi.CreateOrgRequest, a
 		Description: r.Description,
 		Kind:        kind,
 	}
+
+	if r.ParentExternalID != "" {
+		o.Parent, err = findParentOrg(ctx, tx, r.ParentExternalID, adt)
+		if err != nil {
+			return nil, errs.E(op, err)
+		}
+	}
 	oa := &orgAudit{
 		Org:         o,
 		SimpleAudit: sa,
@@ -148,6 +164,12 @@ func (s *OrgService) Create(ctx context.Context, r *diygoapi.CreateOrgRequest, a
 		}
 	}
 
+	// grant the creator the default roles of the org kind
+	err = grantCreatorRoles(ctx, tx, o, adt)
+	if err != nil {
+		return nil, errs.E(op, err)
+	}
+
 	// commit db txn using pgxpool
 	err = s.Datastorer.CommitTx(ctx, tx)
 	if err != nil {
@@ -196,6 +218,7 @@ func newCreateOrgParams(oa *orgAudit) datastore.CreateOrgParams {
 		UpdateAppID:     oa.SimpleAudit.Update.App.ID,
 		UpdateUserID:    oa.SimpleAudit.Update.User.NullUUID(),
 		UpdateTimestamp: oa.SimpleAudit.Update.Moment,
+		ParentOrgID:     oa.Org.ParentID(),
 	}
 }
 
@@ -216,13 +239,19 @@ func (s *OrgService) Update(ctx context.Context, r *diygoapi.UpdateOrgRequest, a
 
 	// retrieve existing Org
 	var oa *orgAudit
-	oa, err = findOrgByExternalIDWithAudit(ctx, tx, r.ExternalID)
+	oa, err = findOrgByExternalIDWithAudit(ctx, tx, r.ExternalID, false)
 	if err != nil {
 		if err == pgx.ErrNoRows {
 			return nil, errs.E(op, errs.Validation, "No org exists for the given external ID")
 		}
 		return nil, errs.E(op, errs.Database, err)
 	}
+	// only the user who created the org or an admin of it may update it
+	err = authorizeOwner(ctx, tx, adt, diygoapi.Owner{OrgID: oa.Org.ID, UserID: oa.SimpleAudit.Create.User.NullUUID()})
+	if err != nil {
+		return nil, errs.E(op, err)
+	}
+
 	// overwrite Last audit with the current audit
 	oa.SimpleAudit.Update = adt
 
@@ -230,6 +259,11 @@ func (s *OrgService) Update(ctx context.Context, r *diygoapi.UpdateOrgRequest, a
 	oa.Org.Name = r.Name
 	oa.Org.Description = r.Description
 
+	err = updateOrgParent(ctx, tx, oa.Org, r.ParentExternalID, adt)
+	if err != nil {
+		return nil, errs.E(op, err)
+	}
+
 	params := datastore.UpdateOrgParams{
 		OrgID:           oa.Org.ID,
 		OrgName:         oa.Org.Name,
@@ -237,6 +271,7 @@ func (s *OrgService) Update(ctx context.Context, r *diygoapi.UpdateOrgRequest, a
 		UpdateAppID:     adt.App.ID,
 		UpdateUserID:    adt.User.NullUUID(),
 		UpdateTimestamp: adt.Moment,
+		ParentOrgID:     oa.Org.ParentID(),
 	}
 
 	// update database record using datastore
@@ -260,8 +295,12 @@ func (s *OrgService) Update(ctx context.Context, r *diygoapi.UpdateOrgRequest, a
 	return newOrgResponse(oa, appAudit{}), nil
 }
 
-// Delete is used to delete an Org
-func (s *OrgService) Delete(ctx context.Context, extlID string) (dr diygoapi.DeleteResponse, err error) {
+// Delete is used to soft delete an Org along with its apps. An Org
+// with child orgs is only deleted when cascade is true, its
+// descendants are then deleted as well. The Org and its descendants
+// and apps are given the same deleted timestamp, which allows them to
+// be restored together.
+func (s *OrgService) Delete(ctx context.Context, extlID string, cascade bool, adt diygoapi.Audit) (dr diygoapi.DeleteResponse, err error) {
 	const op errs.Op = "service/OrgService.Delete"
 
 	// start db txn using pgxpool
@@ -276,37 +315,49 @@ func (s *OrgService) Delete(ctx context.Context, extlID string) (dr diygoapi.Del
 	}()
 
 	// retrieve existing Org
-	var o diygoapi.Org
-	o, err = findOrgByExternalID(ctx, tx, extlID)
+	var oa *orgAudit
+	oa, err = findOrgByExternalIDWithAudit(ctx, tx, extlID, false)
 	if err != nil {
-		if err == pgx.ErrNoRows {
-			return diygoapi.DeleteResponse{}, errs.E(op, errs.Validation, "No org exists for the given external ID")
-		}
-		return diygoapi.DeleteResponse{}, errs.E(op, errs.Database, err)
+		return diygoapi.DeleteResponse{}, errs.E(op, err)
 	}
+	o := oa.Org
 
-	var dbApps []datastore.App
-	dbApps, err = datastore.New(tx).FindAppsByOrg(ctx, o.ID)
+	// only the user who created the org or an admin of it may delete it
+	err = authorizeOwner(ctx, tx, adt, diygoapi.Owner{OrgID: o.ID, UserID: oa.SimpleAudit.Create.User.NullUUID()})
 	if err != nil {
-		return diygoapi.DeleteResponse{}, errs.E(op, errs.Database, err)
+		return diygoapi.DeleteResponse{}, errs.E(op, err)
+	}
+
+	var descendants []*orgAudit
+	descendants, err = findOrgDescendantsWithAudit(ctx, tx, o.ID, false)
+	if err != nil {
+		return diygoapi.DeleteResponse{}, errs.E(op, err)
 	}
 
-	for _, aa := range dbApps {
-		a := diygoapi.App{ID: aa.AppID}
-		err = deleteAppTx(ctx, tx, a)
+	if len(descendants) > 0 && !cascade {
+		return diygoapi.DeleteResponse{}, errs.E(op, errs.Validation, fmt.Sprintf("org %s has %d descendant orgs, they must be deleted first or the delete must cascade", extlID, len(descendants)))
+	}
+
+	// the descendants may have been created by others, only an admin
+	// of the org, whose role applies to its descendants, may delete them
+	if len(descendants) > 0 {
+		err = authorizeOwner(ctx, tx, adt, diygoapi.Owner{OrgID: o.ID})
 		if err != nil {
-			return diygoapi.DeleteResponse{}, errs.E(op, errs.Database, err)
+			return diygoapi.DeleteResponse{}, errs.E(op, err)
 		}
 	}
 
-	var rowsAffected int64
-	rowsAffected, err = datastore.New(tx).DeleteOrg(ctx, o.ID)
-	if err != nil {
-		return diygoapi.DeleteResponse{}, errs.E(op, errs.Database, err)
+	// descendants are ordered by depth, delete the deepest first
+	for i := len(descendants) - 1; i >= 0; i-- {
+		err = softDeleteOrgTx(ctx, tx, *descendants[i].Org, adt)
+		if err != nil {
+			return diygoapi.DeleteResponse{}, errs.E(op, err)
+		}
 	}
 
-	if rowsAffected != 1 {
-		return diygoapi.DeleteResponse{}, errs.E(op, errs.Database, fmt.Sprintf("rows affected should be 1, actual: %d", rowsAffected))
+	err = softDeleteOrgTx(ctx, tx, *o, adt)
+	if err != nil {
+		return diygoapi.DeleteResponse{}, errs.E(op, err)
 	}
 
 	// commit db txn using pgxpool
@@ -323,8 +374,290 @@ func (s *OrgService) Delete(ctx context.Context, extlID string) (dr diygoapi.Del
 	return response, nil
 }
 
-// FindAll is used to list all orgs in the datastore
-func (s *OrgService) FindAll(ctx context.Context) (responses []*diygoapi.OrgResponse, err error) {
+// softDeleteOrgTx soft deletes an Org and its apps
+func softDeleteOrgTx(ctx context.Context, tx pgx.Tx, o diygoapi.Org, adt diygoapi.Audit) error {
+	const op errs.Op = "service/softDeleteOrgTx"
+
+	_, err := datastore.New(tx).SoftDeleteAppsByOrg(ctx, datastore.SoftDeleteAppsByOrgParams{
+		DeletedAppID:     diygoapi.NewNullUUID(adt.App.ID),
+		DeletedUserID:    adt.User.NullUUID(),
+		DeletedTimestamp: diygoapi.NewNullTime(adt.Moment),
+		OrgID:            o.ID,
+	})
+	if err != nil {
+		return errs.E(op, errs.Database, err)
+	}
+
+	params := datastore.SoftDeleteOrgParams{
+		DeletedAppID:     diygoapi.NewNullUUID(adt.App.ID),
+		DeletedUserID:    adt.User.NullUUID(),
+		DeletedTimestamp: diygoapi.NewNullTime(adt.Moment),
+		OrgID:            o.ID,
+	}
+
+	var rowsAffected int64
+	rowsAffected, err = datastore.New(tx).SoftDeleteOrg(ctx, params)
+	if err != nil {
+		return errs.E(op, errs.Database, err)
+	}
+
+	if rowsAffected != 1 {
+		return errs.E(op, errs.Database, fmt.Sprintf("rows affected should be 1, actual: %d", rowsAffected))
+	}
+
+	return nil
+}
+
+// Restore undoes the deletion of an Org. The descendants and apps
+// which were deleted along with the Org are restored as well. An Org
+// cannot be restored while its parent is deleted.
+func (s *OrgService) Restore(ctx context.Context, extlID string, adt diygoapi.Audit) (or *diygoapi.OrgResponse, err error) {
+	const op errs.Op = "service/OrgService.Restore"
+
+	// start db txn using pgxpool
+	var tx pgx.Tx
+	tx, err = s.Datastorer.BeginTx(ctx)
+	if err != nil {
+		return nil, errs.E(op, err)
+	}
+	// defer transaction rollback and handle error, if any
+	defer func() {
+		err = s.Datastorer.RollbackTx(ctx, tx, err)
+	}()
+
+	var oa *orgAudit
+	oa, err = findOrgByExternalIDWithAudit(ctx, tx, extlID, true)
+	if err != nil {
+		return nil, errs.E(op, err)
+	}
+	o := oa.Org
+
+	if o.DeletedAt.IsZero() {
+		return nil, errs.E(op, errs.NotExist, fmt.Sprintf("org %s is not deleted", extlID))
+	}
+
+	// only the user who created the org or an admin of it may restore it
+	err = authorizeOwner(ctx, tx, adt, diygoapi.Owner{OrgID: o.ID, UserID: oa.SimpleAudit.Create.User.NullUUID()})
+	if err != nil {
+		return nil, errs.E(op, err)
+	}
+
+	if o.Parent != nil {
+		_, err = findOrgByExternalID(ctx, tx, o.Parent.ExternalID.String())
+		if err != nil {
+			if errors.Is(err, pgx.ErrNoRows) {
+				return nil, errs.E(op, errs.Validation, fmt.Sprintf("parent org %s is deleted and must be restored first", o.Parent.ExternalID.String()))
+			}
+			return nil, errs.E(op, err)
+		}
+	}
+
+	var descendants []*orgAudit
+	descendants, err = findOrgDescendantsWithAudit(ctx, tx, o.ID, true)
+	if err != nil {
+		return nil, errs.E(op, err)
+	}
+
+	// only the descendants deleted along with the org are restored
+	for _, d := range descendants {
+		if d.Org.DeletedAt.Equal(o.DeletedAt) {
+			err = restoreOrgTx(ctx, tx, *d.Org, adt)
+			if err != nil {
+				return nil, errs.E(op, err)
+			}
+		}
+	}
+
+	err = restoreOrgTx(ctx, tx, *o, adt)
+	if err != nil {
+		return nil, errs.E(op, err)
+	}
+
+	// commit db txn using pgxpool
+	err = s.Datastorer.CommitTx(ctx, tx)
+	if err != nil {
+		return nil, errs.E(op, err)
+	}
+
+	o.DeletedAt = time.Time{}
+	oa.SimpleAudit.Update = adt
+
+	return newOrgResponse(oa, appAudit{}), nil
+}
+
+// restoreOrgTx restores a deleted Org along with the apps deleted at
+// the same moment as the Org
+func restoreOrgTx(ctx context.Context, tx pgx.Tx, o diygoapi.Org, adt diygoapi.Audit) error {
+	const op errs.Op = "service/restoreOrgTx"
+
+	_, err := datastore.New(tx).RestoreAppsByOrg(ctx, datastore.RestoreAppsByOrgParams{
+		UpdateAppID:      adt.App.ID,
+		UpdateUserID:     adt.User.NullUUID(),
+		UpdateTimestamp:  adt.Moment,
+		OrgID:            o.ID,
+		DeletedTimestamp: diygoapi.NewNullTime(o.DeletedAt),
+	})
+	if err != nil {
+		return errs.E(op, errs.Database, err)
+	}
+
+	params := datastore.RestoreOrgParams{
+		UpdateAppID:     adt.App.ID,
+		UpdateUserID:    adt.User.NullUUID(),
+		UpdateTimestamp: adt.Moment,
+		OrgID:           o.ID,
+	}
+
+	var rowsAffected int64
+	rowsAffected, err = datastore.New(tx).RestoreOrg(ctx, params)
+	if err != nil {
+		return errs.E(op, errs.Database, err)
+	}
+
+	if rowsAffected != 1 {
+		return errs.E(op, errs.Database, fmt.Sprintf("rows affected should be 1, actual: %d", rowsAffected))
+	}
+
+	return nil
+}
+
+// findParentOrg finds the Org to be set as a parent given its
+// external ID. The audit user must be allowed to administer it.
+func findParentOrg(ctx context.Context, tx pgx.Tx, extlID string, adt diygoapi.Audit) (*diygoapi.Org, error) {
+	const op errs.Op = "service/findParentOrg"
+
+	parent, err := findOrgByExternalID(ctx, tx, extlID)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, errs.E(op, errs.Validation, fmt.Sprintf("no parent org found with external ID: %s", extlID))
+		}
+		return nil, errs.E(op, err)
+	}
+
+	// attaching an org to a parent grants the roles held in the
+	// parent on the org, only an admin of the parent may do so
+	err = authorizeOwner(ctx, tx, adt, diygoapi.Owner{OrgID: parent.ID})
+	if err != nil {
+		return nil, errs.E(op, err)
+	}
+
+	return &parent, nil
+}
+
+// updateOrgParent sets the parent of an Org given the parent external
+// ID. A nil external ID leaves the parent unchanged, an empty external
+// ID detaches the Org from its parent. An Org cannot become a
+// descendant of itself.
+func updateOrgParent(ctx context.Context, tx pgx.Tx, o *diygoapi.Org, parentExtlIDPtr *string, adt diygoapi.Audit) error {
+	const op errs.Op = "service/updateOrgParent"
+
+	if parentExtlIDPtr == nil {
+		return nil
+	}
+
+	parentExtlID := *parentExtlIDPtr
+	if parentExtlID == "" {
+		o.Parent = nil
+		return nil
+	}
+
+	if o.Parent != nil && o.Parent.ExternalID.String() == parentExtlID {
+		return nil
+	}
+
+	if o.ExternalID.String() == parentExtlID {
+		return errs.E(op, errs.Validation, "an org cannot be its own parent")
+	}
+
+	parent, err := findParentOrg(ctx, tx, parentExtlID, adt)
+	if err != nil {
+		return errs.E(op, err)
+	}
+
+	var descendants []*orgAudit
+	descendants, err = findOrgDescendantsWithAudit(ctx, tx, o.ID, false)
+	if err != nil {
+		return errs.E(op, err)
+	}
+	for _, d := range descendants {
+		if d.Org.ID == parent.ID {
+			return errs.E(op, errs.Validation, fmt.Sprintf("org %s is a descendant of org %s and cannot be its parent", parentExtlID, o.ExternalID.String()))
+		}
+	}
+
+	o.Parent = parent
+
+	return nil
+}
+
+// FindDescendants is used to list the descendants of an Org. Deleted
+// orgs are only listed if includeDeleted is true.
+func (s *OrgService) FindDescendants(ctx context.Context, extlID string, includeDeleted bool) (responses []*diygoapi.OrgResponse, err error) {
+	const op errs.Op = "service/OrgService.FindDescendants"
+
+	// start db txn using pgxpool
+	var tx pgx.Tx
+	tx, err = s.Datastorer.BeginTx(ctx)
+	if err != nil {
+		return nil, errs.E(op, err)
+	}
+	// defer transaction rollback and handle error, if any
+	defer func() {
+		err = s.Datastorer.RollbackTx(ctx, tx, err)
+	}()
+
+	var oa *orgAudit
+	oa, err = findOrgByExternalIDWithAudit(ctx, tx, extlID, includeDeleted)
+	if err != nil {
+		return nil, errs.E(op, err)
+	}
+
+	var descendants []*orgAudit
+	descendants, err = findOrgDescendantsWithAudit(ctx, tx, oa.Org.ID, includeDeleted)
+	if err != nil {
+		return nil, errs.E(op, err)
+	}
+
+	for _, d := range descendants {
+		responses = append(responses, newOrgResponse(d, appAudit{}))
+	}
+
+	// commit db txn using pgxpool
+	err = s.Datastorer.CommitTx(ctx, tx)
+	if err != nil {
+		return nil, errs.E(op, err)
+	}
+
+	return responses, nil
+}
+
+// findOrgDescendantsWithAudit retrieves the descendants of an Org,
+// ordered by depth, from the datastore. Deleted orgs are only
+// retrieved if includeDeleted is true.
+func findOrgDescendantsWithAudit(ctx context.Context, dbtx diygoapi.DBTX, id uuid.UUID, includeDeleted bool) ([]*orgAudit, error) {
+	const op errs.Op = "service/findOrgDescendantsWithAudit"
+
+	params := datastore.FindOrgDescendantsWithAuditParams{
+		ParentOrgID:    diygoapi.NewNullUUID(id),
+		IncludeDeleted: includeDeleted,
+	}
+
+	rows, err := datastore.New(dbtx).FindOrgDescendantsWithAudit(ctx, params)
+	if err != nil {
+		return nil, errs.E(op, errs.Database, err)
+	}
+
+	descendants := make([]*orgAudit, 0, len(rows))
+	for _, row := range rows {
+		descendants = append(descendants, newOrgAudit(datastore.FindOrgByExtlIDWithAuditRow(row)))
+	}
+
+	return descendants, nil
+}
+
+// FindAll is used to list all orgs in the datastore. Deleted orgs are
+// only listed if includeDeleted is true.
+func (s *OrgService) FindAll(ctx context.Context, includeDeleted bool) (responses []*diygoapi.OrgResponse, err error) {
 	const op errs.Op = "service/OrgService.FindAll"
 
 	// start db txn using pgxpool
@@ -342,68 +675,21 @@ func (s *OrgService) FindAll(ctx context.Context) (responses []*diygoapi.OrgResp
 		rows []datastore.FindOrgsWithAuditRow
 	)
 
-	rows, err = datastore.New(tx).FindOrgsWithAudit(ctx)
+	rows, err = datastore.New(tx).FindOrgsWithAudit(ctx, includeDeleted)
 	if err != nil {
 		return nil, errs.E(op, errs.Database, err)
 	}
 
 	for _, row := range rows {
-		o := diygoapi.Org{
-			ID:          row.OrgID,
-			ExternalID:  secure.MustParseIdentifier(row.OrgExtlID),
-			Name:        row.OrgName,
-			Description: row.OrgDescription,
-			Kind: &diygoapi.OrgKind{
-				ID:          row.OrgKindID,
-				ExternalID:  row.OrgKindExtlID,
-				Description: row.OrgKindDesc,
-			},
-		}
-
-		sa := diygoapi.SimpleAudit{
-			Create: diygoapi.Audit{
-				App: &diygoapi.App{
-					ID:          row.CreateAppID,
-					ExternalID:  secure.MustParseIdentifier(row.CreateAppExtlID),
-					Org:         &diygoapi.Org{ID: row.CreateAppOrgID},
-					Name:        row.CreateAppName,
-					Description: row.CreateAppDescription,
-					APIKeys:     nil,
-				},
-				User: &diygoapi.User{
-					ID:        row.CreateUserID.UUID,
-					FirstName: row.CreateUserFirstName,
-					LastName:  row.CreateUserLastName,
-				},
-				Moment: row.CreateTimestamp,
-			},
-			Update: diygoapi.Audit{
-				App: &diygoapi.App{
-					ID:          row.UpdateAppID,
-					ExternalID:  secure.MustParseIdentifier(row.UpdateAppExtlID),
-					Org:         &diygoapi.Org{ID: row.UpdateAppOrgID},
-					Name:        row.UpdateAppName,
-					Description: row.UpdateAppDescription,
-					APIKeys:     nil,
-				},
-				User: &diygoapi.User{
-					ID:        row.UpdateUserID.UUID,
-					FirstName: row.UpdateUserFirstName,
-					LastName:  row.UpdateUserLastName,
-				},
-				Moment: row.UpdateTimestamp,
-			},
-		}
-		or := newOrgResponse(&orgAudit{Org: &o, SimpleAudit: &sa}, appAudit{})
-
-		responses = append(responses, or)
+		responses = append(responses, newOrgResponse(newOrgAudit(datastore.FindOrgByExtlIDWithAuditRow(row)), appAudit{}))
 	}
 
 	return responses, nil
 }
 
-// FindByExternalID is used to find an Org by its External ID
-func (s *OrgService) FindByExternalID(ctx context.Context, extlID string) (or *diygoapi.OrgResponse, err error) {
+// FindByExternalID is used to find an Org by its External ID. A
+// deleted Org is only found if includeDeleted is true.
+func (s *OrgService) FindByExternalID(ctx context.Context, extlID string, includeDeleted bool) (or *diygoapi.OrgResponse, err error) {
 	const op errs.Op = "service/OrgService.FindByExternalID"
 
 	// start db txn using pgxpool
@@ -418,7 +704,7 @@ func (s *OrgService) FindByExternalID(ctx context.Context, extlID string) (or *d
 	}()
 
 	var oa *orgAudit
-	oa, err = findOrgByExternalIDWithAudit(ctx, tx, extlID)
+	oa, err = findOrgByExternalIDWithAudit(ctx, tx, extlID, includeDeleted)
 	if err != nil {
 		return nil, errs.E(op, err)
 	}
@@ -452,7 +738,8 @@ func findOrgByExternalID(ctx context.Context, dbtx diygoapi.DBTX, extlID string)
 
 // findOrgByExternalID retrieves Org data from the datastore given a
 // unique external ID, which is then hydrated into Org and audit structs.
-func findOrgByExternalIDWithAudit(ctx context.Context, dbtx diygoapi.DBTX, extlID string) (*orgAudit, error) {
+// A deleted Org is only found if includeDeleted is true.
+func findOrgByExternalIDWithAudit(ctx context.Context, dbtx diygoapi.DBTX, extlID string, includeDeleted bool) (*orgAudit, error) {
 	const op errs.Op = "service/findOrgByExternalIDWithAudit"
 
 	var (
@@ -460,7 +747,7 @@ func findOrgByExternalIDWithAudit(ctx context.Context, dbtx diygoapi.DBTX, extlI
 		err error
 	)
 
-	row, err = datastore.New(dbtx).FindOrgByExtlIDWithAudit(ctx, extlID)
+	row, err = datastore.New(dbtx).FindOrgByExtlIDWithAudit(ctx, datastore.FindOrgByExtlIDWithAuditParams{OrgExtlID: extlID, IncludeDeleted: includeDeleted})
 	if err != nil {
 		if errors.Is(err, pgx.ErrNoRows) {
 			return nil, errs.E(op, errs.NotExist, fmt.Sprintf("no org found with external ID: %s", extlID))
@@ -469,6 +756,13 @@ func findOrgByExternalIDWithAudit(ctx context.Context, dbtx diygoapi.DBTX, extlI
 		}
 	}
 
+	return newOrgAudit(row), nil
+}
+
+// newOrgAudit initializes an orgAudit given a row selected with audit
+// data. The rows of the other "WithAudit" org queries have the same
+// fields and can be converted to this type.
+func newOrgAudit(row datastore.FindOrgByExtlIDWithAuditRow) *orgAudit {
 	o := &diygoapi.Org{
 		ID:          row.OrgID,
 		ExternalID:  secure.MustParseIdentifier(row.OrgExtlID),
@@ -479,6 +773,7 @@ func findOrgByExternalIDWithAudit(ctx context.Context, dbtx diygoapi.DBTX, extlI
 			ExternalID:  row.OrgKindExtlID,
 			Description: row.OrgKindDesc,
 		},
+		DeletedAt: row.DeletedTimestamp.Time,
 	}
 
 	sa := &diygoapi.SimpleAudit{
@@ -516,7 +811,14 @@ func findOrgByExternalIDWithAudit(ctx context.Context, dbtx diygoapi.DBTX, extlI
 		},
 	}
 
-	return &orgAudit{Org: o, SimpleAudit: sa}, nil
+	if row.ParentOrgID.Valid {
+		o.Parent = &diygoapi.Org{
+			ID:         row.ParentOrgID.UUID,
+			ExternalID: secure.MustParseIdentifier(row.ParentOrgExtlID.String),
+		}
+	}
+
+	return &orgAudit{Org: o, SimpleAudit: sa}
 }
 
 // FindOrgByName finds an Org in the database using its unique name.
@@ -543,19 +845,54 @@ func FindOrgByName(ctx context.Context, tx datastore.DBTX, name string) (*diygoa
 	return o, nil
 }
 
-// findOrgKindByExtlID finds an org kind from the datastore given its External ID
+// findOrgKindByExtlID finds an org kind, along with its default
+// roles, from the datastore given its External ID
 func findOrgKindByExtlID(ctx context.Context, dbtx diygoapi.DBTX, extlID string) (*diygoapi.OrgKind, error) {
 	const op errs.Op = "service/findOrgKindByExtlID"
 
 	kind, err := datastore.New(dbtx).FindOrgKindByExtlID(ctx, extlID)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, errs.E(op, errs.NotExist, fmt.Sprintf("no org kind exists for external ID %s", extlID))
+		}
+		return nil, errs.E(op, errs.Database, err)
+	}
+
+	orgKind, err := newOrgKind(ctx, dbtx, kind)
+	if err != nil {
+		return nil, errs.E(op, err)
+	}
+
+	return orgKind, nil
+}
+
+// newOrgKind initializes an OrgKind given a datastore.OrgKind. The
+// default roles of the kind are retrieved from the datastore, without
+// their permissions or parents.
+func newOrgKind(ctx context.Context, dbtx diygoapi.DBTX, kind datastore.OrgKind) (*diygoapi.OrgKind, error) {
+	const op errs.Op = "service/newOrgKind"
+
+	dbRoles, err := datastore.New(dbtx).FindOrgKindRolesByKindID(ctx, kind.OrgKindID)
 	if err != nil {
 		return nil, errs.E(op, errs.Database, err)
 	}
 
+	var roles []*diygoapi.Role
+	for _, dbr := range dbRoles {
+		roles = append(roles, &diygoapi.Role{
+			ID:          dbr.RoleID,
+			ExternalID:  secure.MustParseIdentifier(dbr.RoleExtlID),
+			Code:        dbr.RoleCd,
+			Description: dbr.RoleDescription,
+			Active:      dbr.Active,
+		})
+	}
+
 	orgKind := &diygoapi.OrgKind{
-		ID:          kind.OrgKindID,
-		ExternalID:  kind.OrgKindExtlID,
-		Description: kind.OrgKindDesc,
+		ID:           kind.OrgKindID,
+		ExternalID:   kind.OrgKindExtlID,
+		Description:  kind.OrgKindDesc,
+		DefaultRoles: roles,
 	}
 
 	return orgKind, nil
@@ -599,7 +936,7 @@ func createTestOrgKind(ctx context.Context, tx pgx.Tx, adt diygoapi.Audit) (data
 
 	testParams := datastore.CreateOrgKindParams{
 		OrgKindID:       uuid.New(),
-		OrgKindExtlID:   "test",
+		OrgKindExtlID:   testOrgKind,
 		OrgKindDesc:     "The test org is used strictly for testing",
 		CreateAppID:     adt.App.ID,
 		CreateUserID:    adt.User.NullUUID(),
@@ -631,7 +968,7 @@ func createStandardOrgKind(ctx context.Context, tx pgx.Tx, adt diygoapi.Audit) (
 
 	standardParams := datastore.CreateOrgKindParams{
 		OrgKindID:       uuid.New(),
-		OrgKindExtlID:   "standard",
+		OrgKindExtlID:   standardOrgKind,
 		OrgKindDesc:     "The standard org is used for myriad business purposes",
 		CreateAppID:     adt.App.ID,
 		CreateUserID:    adt.User.NullUUID(),
@@ -656,3 +993,462 @@ func createStandardOrgKind(ctx context.Context, tx pgx.Tx, adt diygoapi.Audit) (
 
 	return standardParams, nil
 }
+
+// OrgKindService is a service for creating, reading, updating and
+// deleting an OrgKind
+type OrgKindService struct {
+	Datastorer diygoapi.Datastorer
+}
+
+// authorizeOrgKindAdmin determines whether the audit user may create,
+// update or delete an OrgKind. An OrgKind is shared by every org and
+// its default roles are granted to whoever creates an org of the kind,
+// only a sysAdmin of the Principal org may manage one.
+func authorizeOrgKindAdmin(ctx context.Context, tx pgx.Tx, adt diygoapi.Audit) error {
+	const op errs.Op = "service/authorizeOrgKindAdmin"
+
+	if adt.User == nil {
+		return errs.E(op, errs.Unauthorized, "a user is required to manage org kinds")
+	}
+
+	admin, err := isPrincipalSysAdmin(ctx, tx, adt.User)
+	if err != nil {
+		return errs.E(op, err)
+	}
+	if !admin {
+		return errs.E(op, errs.Unauthorized, fmt.Sprintf("User_extl_id %s is not a %s of the Principal org and cannot manage org kinds", adt.User.ExternalID.String(), diygoapi.SysAdminRoleCode))
+	}
+
+	return nil
+}
+
+// isBuiltInOrgKind reports whether the OrgKind is one created by
+// Genesis. The built-in kinds cannot be changed or deleted.
+func isBuiltInOrgKind(extlID string) bool {
+	switch extlID {
+	case principalOrgKind, testOrgKind, standardOrgKind:
+		return true
+	}
+	return false
+}
+
+// Create is used to create an OrgKind
+func (s *OrgKindService) Create(ctx context.Context, r *diygoapi.CreateOrgKindRequest, adt diygoapi.Audit) (response *diygoapi.OrgKindResponse, err error) {
+	const op errs.Op = "service/OrgKindService.Create"
+
+	if r == nil {
+		return nil, errs.E(op, errs.Validation, "CreateOrgKindRequest must have a value when creating an OrgKind")
+	}
+	err = r.Validate()
+	if err != nil {
+		return nil, errs.E(op, err)
+	}
+
+	// start db txn using pgxpool
+	var tx pgx.Tx
+	tx, err = s.Datastorer.BeginTx(ctx)
+	if err != nil {
+		return nil, errs.E(op, err)
+	}
+	// defer transaction rollback and handle error, if any
+	defer func() {
+		err = s.Datastorer.RollbackTx(ctx, tx, err)
+	}()
+
+	err = authorizeOrgKindAdmin(ctx, tx, adt)
+	if err != nil {
+		return nil, errs.E(op, err)
+	}
+
+	kind := &diygoapi.OrgKind{
+		ID:          uuid.New(),
+		ExternalID:  r.ExternalID,
+		Description: r.Description,
+	}
+
+	kind.DefaultRoles, err = findDefaultRoles(ctx, tx, r.DefaultRoleCodes)
+	if err != nil {
+		return nil, errs.E(op, err)
+	}
+
+	params := datastore.CreateOrgKindParams{
+		OrgKindID:       kind.ID,
+		OrgKindExtlID:   kind.ExternalID,
+		OrgKindDesc:     kind.Description,
+		CreateAppID:     adt.App.ID,
+		CreateUserID:    adt.User.NullUUID(),
+		CreateTimestamp: adt.Moment,
+		UpdateAppID:     adt.App.ID,
+		UpdateUserID:    adt.User.NullUUID(),
+		UpdateTimestamp: adt.Moment,
+	}
+
+	var rowsAffected int64
+	rowsAffected, err = datastore.New(tx).CreateOrgKind(ctx, params)
+	if err != nil {
+		var pgErr *pgconn.PgError
+		if errors.As(err, &pgErr) && pgErr.Code == "23505" {
+			return nil, errs.E(op, errs.Exist, fmt.Sprintf("an org kind already exists for external ID %s", kind.ExternalID))
+		}
+		return nil, errs.E(op, errs.Database, err)
+	}
+
+	if rowsAffected != 1 {
+		return nil, errs.E(op, errs.Database, fmt.Sprintf("rows affected should be 1, actual: %d", rowsAffected))
+	}
+
+	err = createOrgKindRolesTx(ctx, tx, kind, adt)
+	if err != nil {
+		return nil, errs.E(op, err)
+	}
+
+	// commit db txn using pgxpool
+	err = s.Datastorer.CommitTx(ctx, tx)
+	if err != nil {
+		return nil, errs.E(op, err)
+	}
+
+	return newOrgKindResponse(kind), nil
+}
+
+// Update is used to update the description of an OrgKind and replace
+// its default roles
+func (s *OrgKindService) Update(ctx context.Context, r *diygoapi.UpdateOrgKindRequest, adt diygoapi.Audit) (response *diygoapi.OrgKindResponse, err error) {
+	const op errs.Op = "service/OrgKindService.Update"
+
+	if r == nil {
+		return nil, errs.E(op, errs.Validation, "UpdateOrgKindRequest must have a value when updating an OrgKind")
+	}
+	err = r.Validate()
+	if err != nil {
+		return nil, errs.E(op, err)
+	}
+
+	// start db txn using pgxpool
+	var tx pgx.Tx
+	tx, err = s.Datastorer.BeginTx(ctx)
+	if err != nil {
+		return nil, errs.E(op, err)
+	}
+	// defer transaction rollback and handle error, if any
+	defer func() {
+		err = s.Datastorer.RollbackTx(ctx, tx, err)
+	}()
+
+	err = authorizeOrgKindAdmin(ctx, tx, adt)
+	if err != nil {
+		return nil, errs.E(op, err)
+	}
+
+	if isBuiltInOrgKind(r.ExternalID) {
+		return nil, errs.E(op, errs.Validation, fmt.Sprintf("org kind %s is built in and cannot be changed", r.ExternalID))
+	}
+
+	var kind *diygoapi.OrgKind
+	kind, err = findOrgKindByExtlID(ctx, tx, r.ExternalID)
+	if err != nil {
+		return nil, errs.E(op, err)
+	}
+
+	kind.Description = r.Description
+	kind.DefaultRoles, err = findDefaultRoles(ctx, tx, r.DefaultRoleCodes)
+	if err != nil {
+		return nil, errs.E(op, err)
+	}
+
+	params := datastore.UpdateOrgKindParams{
+		OrgKindDesc:     kind.Description,
+		UpdateAppID:     adt.App.ID,
+		UpdateUserID:    adt.User.NullUUID(),
+		UpdateTimestamp: adt.Moment,
+		OrgKindID:       kind.ID,
+	}
+
+	var rowsAffected int64
+	rowsAffected, err = datastore.New(tx).UpdateOrgKind(ctx, params)
+	if err != nil {
+		return nil, errs.E(op, errs.Database, err)
+	}
+
+	// update should only update exactly one record
+	if rowsAffected != 1 {
+		return nil, errs.E(op, errs.Database, fmt.Sprintf("UpdateOrgKind() should update 1 row, actual: %d", rowsAffected))
+	}
+
+	_, err = datastore.New(tx).DeleteOrgKindRoles(ctx, kind.ID)
+	if err != nil {
+		return nil, errs.E(op, errs.Database, err)
+	}
+
+	err = createOrgKindRolesTx(ctx, tx, kind, adt)
+	if err != nil {
+		return nil, errs.E(op, err)
+	}
+
+	// commit db txn using pgxpool
+	err = s.Datastorer.CommitTx(ctx, tx)
+	if err != nil {
+		return nil, errs.E(op, err)
+	}
+
+	return newOrgKindResponse(kind), nil
+}
+
+// Delete is used to delete an OrgKind. An OrgKind which classifies an
+// Org, including a deleted Org which has not been purged, cannot be
+// deleted.
+func (s *OrgKindService) Delete(ctx context.Context, extlID string, adt diygoapi.Audit) (dr diygoapi.DeleteResponse, err error) {
+	const op errs.Op = "service/OrgKindService.Delete"
+
+	// start db txn using pgxpool
+	var tx pgx.Tx
+	tx, err = s.Datastorer.BeginTx(ctx)
+	if err != nil {
+		return diygoapi.DeleteResponse{}, errs.E(op, err)
+	}
+	// defer transaction rollback and handle error, if any
+	defer func() {
+		err = s.Datastorer.RollbackTx(ctx, tx, err)
+	}()
+
+	err = authorizeOrgKindAdmin(ctx, tx, adt)
+	if err != nil {
+		return diygoapi.DeleteResponse{}, errs.E(op, err)
+	}
+
+	if isBuiltInOrgKind(extlID) {
+		return diygoapi.DeleteResponse{}, errs.E(op, errs.Validation, fmt.Sprintf("org kind %s is built in and cannot be deleted", extlID))
+	}
+
+	var kind *diygoapi.OrgKind
+	kind, err = findOrgKindByExtlID(ctx, tx, extlID)
+	if err != nil {
+		return diygoapi.DeleteResponse{}, errs.E(op, err)
+	}
+
+	var orgs int64
+	orgs, err = datastore.New(tx).CountOrgsByKind(ctx, kind.ID)
+	if err != nil {
+		return diygoapi.DeleteResponse{}, errs.E(op, errs.Database, err)
+	}
+	if orgs > 0 {
+		return diygoapi.DeleteResponse{}, errs.E(op, errs.Validation, fmt.Sprintf("org kind %s classifies %d org(s) and cannot be deleted", kind.ExternalID, orgs))
+	}
+
+	_, err = datastore.New(tx).DeleteOrgKindRoles(ctx, kind.ID)
+	if err != nil {
+		return diygoapi.DeleteResponse{}, errs.E(op, errs.Database, err)
+	}
+
+	var rowsAffected int64
+	rowsAffected, err = datastore.New(tx).DeleteOrgKind(ctx, kind.ID)
+	if err != nil {
+		return diygoapi.DeleteResponse{}, errs.E(op, errs.Database, err)
+	}
+
+	if rowsAffected != 1 {
+		return diygoapi.DeleteResponse{}, errs.E(op, errs.Database, fmt.Sprintf("rows affected should be 1, actual: %d", rowsAffected))
+	}
+
+	// commit db txn using pgxpool
+	err = s.Datastorer.CommitTx(ctx, tx)
+	if err != nil {
+		return diygoapi.DeleteResponse{}, errs.E(op, err)
+	}
+
+	response := diygoapi.DeleteResponse{
+		ExternalID: extlID,
+		Deleted:    true,
+	}
+
+	return response, nil
+}
+
+// FindAll is used to list all org kinds in the datastore
+func (s *OrgKindService) FindAll(ctx context.Context) (responses []*diygoapi.OrgKindResponse, err error) {
+	const op errs.Op = "service/OrgKindService.FindAll"
+
+	// start db txn using pgxpool
+	var tx pgx.Tx
+	tx, err = s.Datastorer.BeginTx(ctx)
+	if err != nil {
+		return nil, errs.E(op, err)
+	}
+	// defer transaction rollback and handle error, if any
+	defer func() {
+		err = s.Datastorer.RollbackTx(ctx, tx, err)
+	}()
+
+	var rows []datastore.OrgKind
+	rows, err = datastore.New(tx).FindOrgKinds(ctx)
+	if err != nil {
+		return nil, errs.E(op, errs.Database, err)
+	}
+
+	for _, row := range rows {
+		var kind *diygoapi.OrgKind
+		kind, err = newOrgKind(ctx, tx, row)
+		if err != nil {
+			return nil, errs.E(op, err)
+		}
+		responses = append(responses, newOrgKindResponse(kind))
+	}
+
+	// commit db txn using pgxpool
+	err = s.Datastorer.CommitTx(ctx, tx)
+	if err != nil {
+		return nil, errs.E(op, err)
+	}
+
+	return responses, nil
+}
+
+// FindByExternalID is used to find an OrgKind by its External ID
+func (s *OrgKindService) FindByExternalID(ctx context.Context, extlID string) (response *diygoapi.OrgKindResponse, err error) {
+	const op errs.Op = "service/OrgKindService.FindByExternalID"
+
+	// start db txn using pgxpool
+	var tx pgx.Tx
+	tx, err = s.Datastorer.BeginTx(ctx)
+	if err != nil {
+		return nil, errs.E(op, err)
+	}
+	// defer transaction rollback and handle error, if any
+	defer func() {
+		err = s.Datastorer.RollbackTx(ctx, tx, err)
+	}()
+
+	var kind *diygoapi.OrgKind
+	kind, err = findOrgKindByExtlID(ctx, tx, extlID)
+	if err != nil {
+		return nil, errs.E(op, err)
+	}
+
+	// commit db txn using pgxpool
+	err = s.Datastorer.CommitTx(ctx, tx)
+	if err != nil {
+		return nil, errs.E(op, err)
+	}
+
+	return newOrgKindResponse(kind), nil
+}
+
+// newOrgKindResponse initializes OrgKindResponse given an OrgKind
+func newOrgKindResponse(kind *diygoapi.OrgKind) *diygoapi.OrgKindResponse {
+	codes := make([]string, 0, len(kind.DefaultRoles))
+	for _, r := range kind.DefaultRoles {
+		codes = append(codes, r.Code)
+	}
+
+	return &diygoapi.OrgKindResponse{
+		ExternalID:       kind.ExternalID,
+		Description:      kind.Description,
+		DefaultRoleCodes: codes,
+	}
+}
+
+// findDefaultRoles finds the roles for the given role codes. A code
+// given more than once is only considered once. The admin roles cannot
+// be default roles, as anyone allowed to create an Org of the kind
+// would be given them.
+func findDefaultRoles(ctx context.Context, tx pgx.Tx, codes []string) ([]*diygoapi.Role, error) {
+	const op errs.Op = "service/findDefaultRoles"
+
+	var roles []*diygoapi.Role
+	seen := make(map[string]bool)
+	for _, code := range codes {
+		if seen[code] {
+			continue
+		}
+		seen[code] = true
+
+		if code == diygoapi.OrgAdminRoleCode || code == diygoapi.SysAdminRoleCode {
+			return nil, errs.E(op, errs.Validation, fmt.Sprintf("the %s role cannot be a default role of an org kind", code))
+		}
+
+		role, err := findRoleByCode(ctx, tx, code)
+		if err != nil {
+			return nil, errs.E(op, err)
+		}
+		roles = append(roles, &role)
+	}
+
+	return roles, nil
+}
+
+// createOrgKindRolesTx writes the default roles of an OrgKind to the
+// database
+func createOrgKindRolesTx(ctx context.Context, tx pgx.Tx, kind *diygoapi.OrgKind, adt diygoapi.Audit) error {
+	const op errs.Op = "service/createOrgKindRolesTx"
+
+	for _, r := range kind.DefaultRoles {
+		params := datastore.CreateOrgKindRoleParams{
+			OrgKindID:       kind.ID,
+			RoleID:          r.ID,
+			CreateAppID:     adt.App.ID,
+			CreateUserID:    adt.User.NullUUID(),
+			CreateTimestamp: adt.Moment,
+			UpdateAppID:     adt.App.ID,
+			UpdateUserID:    adt.User.NullUUID(),
+			UpdateTimestamp: adt.Moment,
+		}
+
+		rowsAffected, err := datastore.New(tx).CreateOrgKindRole(ctx, params)
+		if err != nil {
+			return errs.E(op, errs.Database, err)
+		}
+
+		if rowsAffected != 1 {
+			return errs.E(op, errs.Database, fmt.Sprintf("CreateOrgKindRole() should insert 1 row, actual: %d", rowsAffected))
+		}
+	}
+
+	return nil
+}
+
+// grantCreatorRoles associates the audit User with a newly created Org
+// and grants them, within the Org, the default roles of its kind along
+// with any additional roles given. Nothing is granted when there is no
+// audit User or no roles to grant.
+func grantCreatorRoles(ctx context.Context, tx pgx.Tx, o *diygoapi.Org, adt diygoapi.Audit, additional ...diygoapi.Role) error {
+	const op errs.Op = "service/grantCreatorRoles"
+
+	if adt.User == nil || adt.User.ID == uuid.Nil {
+		return nil
+	}
+
+	var roles []diygoapi.Role
+	seen := make(map[uuid.UUID]bool)
+	if o.Kind != nil {
+		for _, r := range o.Kind.DefaultRoles {
+			if !seen[r.ID] {
+				seen[r.ID] = true
+				roles = append(roles, *r)
+			}
+		}
+	}
+	for _, r := range additional {
+		if !seen[r.ID] {
+			seen[r.ID] = true
+			roles = append(roles, r)
+		}
+	}
+
+	if len(roles) == 0 {
+		return nil
+	}
+
+	err := attachOrgAssociation(ctx, tx, attachOrgAssociationParams{Org: o, User: adt.User, Audit: adt})
+	if err != nil {
+		return errs.E(op, err)
+	}
+
+	for _, r := range roles {
+		err = assignOrgRole(ctx, tx, assignOrgRoleParams{Role: r, User: adt.User, Org: o, Audit: adt})
+		if err != nil {
+			return errs.E(op, err)
+		}
+	}
+
+	return nil
+}
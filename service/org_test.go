@@ -8,6 +8,7 @@ import (
 
 	qt "github.com/frankban/quicktest"
 	"github.com/google/go-cmp/cmp/cmpopts"
+	"github.com/google/uuid"
 	"github.com/jackc/pgx/v4"
 
 	"github.com/gilcrest/diygoapi"
@@ -244,7 +245,7 @@ func TestOrgService(t *testing.T) {
 		adt := findPrincipalTestAudit(ctx, c, tx)
 
 		var got *diygoapi.OrgResponse
-		got, err = s.FindByExternalID(context.Background(), testOrg.OrgExtlID)
+		got, err = s.FindByExternalID(context.Background(), testOrg.OrgExtlID, false)
 		want := &diygoapi.OrgResponse{
 			ExternalID:          got.ExternalID,
 			Name:                testOrgServiceUpdatedOrgName,
@@ -276,7 +277,7 @@ func TestOrgService(t *testing.T) {
 			got []*diygoapi.OrgResponse
 			err error
 		)
-		got, err = s.FindAll(ctx)
+		got, err = s.FindAll(ctx, false)
 		c.Assert(err, qt.IsNil)
 		c.Assert(len(got) >= 1, qt.IsTrue, qt.Commentf("orgs found = %d", len(got)))
 		c.Logf("orgs found = %d", len(got))
@@ -313,8 +314,10 @@ func TestOrgService(t *testing.T) {
 			Datastorer: db,
 		}
 
+		adt := findPrincipalTestAudit(ctx, c, tx)
+
 		var got diygoapi.DeleteResponse
-		got, err = s.Delete(context.Background(), testOrg.OrgExtlID)
+		got, err = s.Delete(context.Background(), testOrg.OrgExtlID, false, adt)
 		want := diygoapi.DeleteResponse{
 			ExternalID: testOrg.OrgExtlID,
 			Deleted:    true,
@@ -415,3 +418,275 @@ func findPrincipalTestAudit(ctx context.Context, c *qt.C, tx pgx.Tx) diygoapi.Au
 
 	return adt
 }
+
+func TestOrgService_UpdateParent(t *testing.T) {
+	c := qt.New(t)
+
+	db, cleanup := sqldbtest.NewDB(t)
+	c.Cleanup(cleanup)
+
+	// admin is the orgAdmin of grandparent, which makes them an admin
+	// of parent and child as well, but not of otherOrg
+	ctx := context.Background()
+	tx, err := db.BeginTx(ctx)
+	if err != nil {
+		c.Fatalf("BeginTx() error = %v", err)
+	}
+	adt := findTestAudit(ctx, c, tx)
+	grandparent := createTestOrg(ctx, c, tx, adt, nil)
+	parent := createTestOrg(ctx, c, tx, adt, grandparent)
+	child := createTestOrg(ctx, c, tx, adt, parent)
+	otherOrg := createTestOrg(ctx, c, tx, adt, nil)
+	admin := createTestUser(ctx, c, tx, adt, grandparent)
+	grantTestRole(ctx, c, tx, adt, admin, grandparent, diygoapi.OrgAdminRoleCode, time.Time{}, time.Time{})
+	err = db.CommitTx(ctx, tx)
+	c.Assert(err, qt.IsNil)
+
+	s := service.OrgService{Datastorer: db}
+	update := func(o *diygoapi.Org, parentExtlID *string) (*diygoapi.OrgResponse, error) {
+		r := &diygoapi.UpdateOrgRequest{
+			ExternalID:       o.ExternalID.String(),
+			Name:             o.Name,
+			Description:      o.Description,
+			ParentExternalID: parentExtlID,
+		}
+		return s.Update(ctx, r, diygoapi.Audit{App: adt.App, User: admin, Org: grandparent, Moment: time.Now()})
+	}
+	extlID := func(o *diygoapi.Org) *string {
+		id := o.ExternalID.String()
+		return &id
+	}
+
+	c.Run("parent not sent", func(c *qt.C) {
+		got, err := update(child, nil)
+		c.Assert(err, qt.IsNil)
+		c.Assert(got.ParentExternalID, qt.Equals, parent.ExternalID.String())
+	})
+	c.Run("own parent", func(c *qt.C) {
+		_, err := update(child, extlID(child))
+		c.Assert(errs.KindIs(errs.Validation, err), qt.IsTrue, qt.Commentf("error = %v", err))
+	})
+	c.Run("cycle", func(c *qt.C) {
+		_, err := update(grandparent, extlID(child))
+		c.Assert(errs.KindIs(errs.Validation, err), qt.IsTrue, qt.Commentf("error = %v", err))
+	})
+	c.Run("parent without authority", func(c *qt.C) {
+		_, err := update(child, extlID(otherOrg))
+		c.Assert(errs.KindIs(errs.Unauthorized, err), qt.IsTrue, qt.Commentf("error = %v", err))
+	})
+	c.Run("new parent", func(c *qt.C) {
+		got, err := update(child, extlID(grandparent))
+		c.Assert(err, qt.IsNil)
+		c.Assert(got.ParentExternalID, qt.Equals, grandparent.ExternalID.String())
+	})
+	c.Run("detach", func(c *qt.C) {
+		detach := ""
+		got, err := update(parent, &detach)
+		c.Assert(err, qt.IsNil)
+		c.Assert(got.ParentExternalID, qt.Equals, "")
+	})
+}
+
+func TestOrgService_DeleteCascade(t *testing.T) {
+	c := qt.New(t)
+
+	db, cleanup := sqldbtest.NewDB(t)
+	c.Cleanup(cleanup)
+
+	// creator created parent and leaf without holding any role, child
+	// was created under parent by someone else, admin is the orgAdmin
+	// of parent
+	ctx := context.Background()
+	tx, err := db.BeginTx(ctx)
+	if err != nil {
+		c.Fatalf("BeginTx() error = %v", err)
+	}
+	adt := findTestAudit(ctx, c, tx)
+	creator := createTestUser(ctx, c, tx, adt)
+	creatorAdt := diygoapi.Audit{App: adt.App, User: creator, Moment: time.Now()}
+	parent := createTestOrg(ctx, c, tx, creatorAdt, nil)
+	leaf := createTestOrg(ctx, c, tx, creatorAdt, nil)
+	child := createTestOrg(ctx, c, tx, adt, parent)
+	admin := createTestUser(ctx, c, tx, adt, parent)
+	grantTestRole(ctx, c, tx, adt, admin, parent, diygoapi.OrgAdminRoleCode, time.Time{}, time.Time{})
+	err = db.CommitTx(ctx, tx)
+	c.Assert(err, qt.IsNil)
+
+	s := service.OrgService{Datastorer: db}
+	audit := func(u *diygoapi.User) diygoapi.Audit {
+		return diygoapi.Audit{App: adt.App, User: u, Moment: time.Now()}
+	}
+
+	// the creator of an org without descendants may delete it
+	_, err = s.Delete(ctx, leaf.ExternalID.String(), false, audit(creator))
+	c.Assert(err, qt.IsNil)
+
+	// but not the descendants created by others
+	_, err = s.Delete(ctx, parent.ExternalID.String(), true, audit(creator))
+	c.Assert(errs.KindIs(errs.Unauthorized, err), qt.IsTrue, qt.Commentf("error = %v", err))
+
+	_, err = s.Delete(ctx, parent.ExternalID.String(), true, audit(admin))
+	c.Assert(err, qt.IsNil)
+
+	_, err = s.FindByExternalID(ctx, child.ExternalID.String(), false)
+	c.Assert(errs.KindIs(errs.NotExist, err), qt.IsTrue, qt.Commentf("error = %v", err))
+}
+
+func TestOrgKindService(t *testing.T) {
+	t.Run("create no request error", func(t *testing.T) {
+		c := qt.New(t)
+
+		db, cleanup := sqldbtest.NewDB(t)
+		c.Cleanup(cleanup)
+
+		s := service.OrgKindService{Datastorer: db}
+
+		got, err := s.Create(context.Background(), nil, diygoapi.Audit{})
+		c.Assert(errs.KindIs(errs.Validation, err), qt.IsTrue)
+		c.Assert(err.Error(), qt.Equals, "CreateOrgKindRequest must have a value when creating an OrgKind")
+		c.Assert(got, qt.IsNil)
+	})
+	t.Run("create, update and delete org kind", func(t *testing.T) {
+		c := qt.New(t)
+
+		db, cleanup := sqldbtest.NewDB(t)
+		c.Cleanup(cleanup)
+
+		// start db txn using pgxpool
+		ctx := context.Background()
+		tx, err := db.BeginTx(ctx)
+		if err != nil {
+			t.Fatalf("db.BeginTx error: %v", err)
+		}
+		adt := findPrincipalTestAudit(ctx, c, tx)
+		// user holds no role in the Principal org
+		testAdt := findTestAudit(ctx, c, tx)
+		user := createTestUser(ctx, c, tx, testAdt)
+		err = db.CommitTx(ctx, tx)
+		c.Assert(err, qt.IsNil)
+		userAdt := diygoapi.Audit{App: testAdt.App, User: user, Moment: time.Now()}
+
+		s := service.OrgKindService{Datastorer: db}
+
+		r := &diygoapi.CreateOrgKindRequest{
+			ExternalID:       "TestOrgKindService",
+			Description:      "Org kind created via TestOrgKindService",
+			DefaultRoleCodes: []string{service.TestRoleCode, service.TestRoleCode},
+		}
+
+		// only a sysAdmin of the Principal org may manage org kinds
+		_, err = s.Create(ctx, r, userAdt)
+		c.Assert(errs.KindIs(errs.Unauthorized, err), qt.IsTrue, qt.Commentf("error = %v", err))
+
+		var got *diygoapi.OrgKindResponse
+		got, err = s.Create(ctx, r, adt)
+		c.Assert(err, qt.IsNil)
+		c.Assert(got, qt.DeepEquals, &diygoapi.OrgKindResponse{
+			ExternalID:       r.ExternalID,
+			Description:      r.Description,
+			DefaultRoleCodes: []string{service.TestRoleCode},
+		})
+
+		_, err = s.Create(ctx, r, adt)
+		c.Assert(errs.KindIs(errs.Exist, err), qt.IsTrue)
+
+		// admin roles cannot be default roles
+		for _, code := range []string{diygoapi.OrgAdminRoleCode, diygoapi.SysAdminRoleCode} {
+			_, err = s.Update(ctx, &diygoapi.UpdateOrgKindRequest{ExternalID: r.ExternalID, Description: r.Description, DefaultRoleCodes: []string{code}}, adt)
+			c.Assert(errs.KindIs(errs.Validation, err), qt.IsTrue, qt.Commentf("error = %v", err))
+		}
+
+		update := &diygoapi.UpdateOrgKindRequest{ExternalID: r.ExternalID, Description: "Org kind updated via TestOrgKindService"}
+		_, err = s.Update(ctx, update, userAdt)
+		c.Assert(errs.KindIs(errs.Unauthorized, err), qt.IsTrue, qt.Commentf("error = %v", err))
+
+		got, err = s.Update(ctx, update, adt)
+		c.Assert(err, qt.IsNil)
+		c.Assert(got.Description, qt.Equals, "Org kind updated via TestOrgKindService")
+		c.Assert(got.DefaultRoleCodes, qt.HasLen, 0)
+
+		_, err = s.Delete(ctx, r.ExternalID, userAdt)
+		c.Assert(errs.KindIs(errs.Unauthorized, err), qt.IsTrue, qt.Commentf("error = %v", err))
+
+		var dr diygoapi.DeleteResponse
+		dr, err = s.Delete(ctx, r.ExternalID, adt)
+		c.Assert(err, qt.IsNil)
+		c.Assert(dr, qt.DeepEquals, diygoapi.DeleteResponse{ExternalID: r.ExternalID, Deleted: true})
+
+		_, err = s.FindByExternalID(ctx, r.ExternalID)
+		c.Assert(errs.KindIs(errs.NotExist, err), qt.IsTrue)
+	})
+	t.Run("delete org kind in use", func(t *testing.T) {
+		c := qt.New(t)
+
+		db, cleanup := sqldbtest.NewDB(t)
+		c.Cleanup(cleanup)
+
+		ctx := context.Background()
+		tx, err := db.BeginTx(ctx)
+		if err != nil {
+			t.Fatalf("db.BeginTx error: %v", err)
+		}
+		adt := findPrincipalTestAudit(ctx, c, tx)
+		err = db.CommitTx(ctx, tx)
+		c.Assert(err, qt.IsNil)
+
+		s := service.OrgKindService{Datastorer: db}
+
+		r := &diygoapi.CreateOrgKindRequest{ExternalID: "TestOrgKindServiceInUse", Description: "Org kind in use created via TestOrgKindService"}
+		_, err = s.Create(ctx, r, adt)
+		c.Assert(err, qt.IsNil)
+
+		// classify an org with the kind
+		tx, err = db.BeginTx(ctx)
+		if err != nil {
+			t.Fatalf("db.BeginTx error: %v", err)
+		}
+		kind, err := datastore.New(tx).FindOrgKindByExtlID(ctx, r.ExternalID)
+		c.Assert(err, qt.IsNil)
+		_, err = datastore.New(tx).CreateOrg(ctx, datastore.CreateOrgParams{
+			OrgID:           uuid.New(),
+			OrgExtlID:       secure.NewID().String(),
+			OrgName:         "Test Org " + uuid.NewString(),
+			OrgDescription:  "Org created for a test",
+			OrgKindID:       kind.OrgKindID,
+			CreateAppID:     adt.App.ID,
+			CreateUserID:    adt.User.NullUUID(),
+			CreateTimestamp: adt.Moment,
+			UpdateAppID:     adt.App.ID,
+			UpdateUserID:    adt.User.NullUUID(),
+			UpdateTimestamp: adt.Moment,
+		})
+		c.Assert(err, qt.IsNil)
+		err = db.CommitTx(ctx, tx)
+		c.Assert(err, qt.IsNil)
+
+		_, err = s.Delete(ctx, r.ExternalID, adt)
+		c.Assert(errs.KindIs(errs.Validation, err), qt.IsTrue, qt.Commentf("error = %v", err))
+	})
+	t.Run("built-in org kinds", func(t *testing.T) {
+		c := qt.New(t)
+
+		db, cleanup := sqldbtest.NewDB(t)
+		c.Cleanup(cleanup)
+
+		ctx := context.Background()
+		tx, err := db.BeginTx(ctx)
+		if err != nil {
+			t.Fatalf("db.BeginTx error: %v", err)
+		}
+		adt := findPrincipalTestAudit(ctx, c, tx)
+		err = db.CommitTx(ctx, tx)
+		c.Assert(err, qt.IsNil)
+
+		s := service.OrgKindService{Datastorer: db}
+
+		for _, extlID := range []string{"principal", testOrgServiceOrgKind, "standard"} {
+			_, err = s.Update(ctx, &diygoapi.UpdateOrgKindRequest{ExternalID: extlID, Description: "Built-in org kind updated via TestOrgKindService", DefaultRoleCodes: []string{service.TestRoleCode}}, adt)
+			c.Assert(errs.KindIs(errs.Validation, err), qt.IsTrue, qt.Commentf("error = %v", err))
+
+			_, err = s.Delete(ctx, extlID, adt)
+			c.Assert(errs.KindIs(errs.Validation, err), qt.IsTrue, qt.Commentf("error = %v", err))
+		}
+	})
+}
@@ -3,6 +3,7 @@ package service
 import (
 	"context"
 	"database/sql"
+	"errors"
 	"fmt"
 
 	"github.com/google/uuid"
@@ -121,10 +122,46 @@ func createUserTx(ctx context.Context, tx pgx.Tx, params createUserTxParams) err
 		return errs.E(op, errs.Database, fmt.Sprintf("user rows affected should be 1, actual: %d", rowsAffected))
 	}
 
+	err = createUserLanguagePreferencesTx(ctx, tx, params.User, params.Audit)
+	if err != nil {
+		return errs.E(op, err)
+	}
+
 	return nil
 
 }
 
+// createUserLanguagePreferencesTx writes the language preferences of a
+// User to the database, in order of preference
+func createUserLanguagePreferencesTx(ctx context.Context, tx pgx.Tx, u *diygoapi.User, adt diygoapi.Audit) error {
+	const op errs.Op = "service/createUserLanguagePreferencesTx"
+
+	for i, tag := range u.LanguagePreferences {
+		params := datastore.CreateUserLanguagePreferenceParams{
+			UserID:          u.ID,
+			LanguageTag:     tag.String(),
+			PrefOrder:       int32(i + 1),
+			CreateAppID:     adt.App.ID,
+			CreateUserID:    adt.User.NullUUID(),
+			CreateTimestamp: adt.Moment,
+			UpdateAppID:     adt.App.ID,
+			UpdateUserID:    adt.User.NullUUID(),
+			UpdateTimestamp: adt.Moment,
+		}
+
+		rowsAffected, err := datastore.New(tx).CreateUserLanguagePreference(ctx, params)
+		if err != nil {
+			return errs.E(op, errs.Database, err)
+		}
+
+		if rowsAffected != 1 {
+			return errs.E(op, errs.Database, fmt.Sprintf("CreateUserLanguagePreference() should insert 1 row, actual: %d", rowsAffected))
+		}
+	}
+
+	return nil
+}
+
 // FindUserByID finds a User in the datastore given their User ID
 func FindUserByID(ctx context.Context, dbtx datastore.DBTX, id uuid.UUID) (*diygoapi.User, error) {
 	const op errs.Op = "service/FindUserByID"
@@ -134,8 +171,42 @@ func FindUserByID(ctx context.Context, dbtx datastore.DBTX, id uuid.UUID) (*diyg
 		return nil, errs.E(op, errs.Database, err)
 	}
 
-	var ulp []datastore.UsersLangPref
-	ulp, err = datastore.New(dbtx).FindUserLanguagePreferencesByUserID(ctx, id)
+	var u *diygoapi.User
+	u, err = newUser(ctx, dbtx, dbUser)
+	if err != nil {
+		return nil, errs.E(op, err)
+	}
+
+	return u, nil
+}
+
+// FindUserByExternalID finds a User in the datastore given their User External ID
+func FindUserByExternalID(ctx context.Context, dbtx datastore.DBTX, extlID string) (*diygoapi.User, error) {
+	const op errs.Op = "service/FindUserByExternalID"
+
+	dbUser, err := datastore.New(dbtx).FindUserByExternalID(ctx, extlID)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, errs.E(op, errs.NotExist, fmt.Sprintf("no user found with external ID: %s", extlID))
+		}
+		return nil, errs.E(op, errs.Database, err)
+	}
+
+	var u *diygoapi.User
+	u, err = newUser(ctx, dbtx, dbUser)
+	if err != nil {
+		return nil, errs.E(op, err)
+	}
+
+	return u, nil
+}
+
+// newUser initializes a User given a datastore.User and retrieves
+// the user's language preferences
+func newUser(ctx context.Context, dbtx datastore.DBTX, dbUser datastore.User) (*diygoapi.User, error) {
+	const op errs.Op = "service/newUser"
+
+	ulp, err := datastore.New(dbtx).FindUserLanguagePreferencesByUserID(ctx, dbUser.UserID)
 	if err != nil {
 		return nil, errs.E(op, errs.Database, err)
 	}
@@ -167,6 +238,9 @@ func FindUserByID(ctx context.Context, dbtx datastore.DBTX, id uuid.UUID) (*diyg
 		PictureURL:          "",
 		ProfileLink:         "",
 		Source:              "",
+		Suspended:           !dbUser.Active,
+		SuspendedReason:     dbUser.SuspendedReason.String,
+		SuspendedAt:         dbUser.SuspendedTimestamp.Time,
 	}
 
 	return u, nil
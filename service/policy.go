@@ -0,0 +1,304 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/netip"
+	"sort"
+	"time"
+
+	"github.com/jackc/pgx/v4"
+	"github.com/rs/zerolog"
+
+	"github.com/gilcrest/diygoapi"
+	"github.com/gilcrest/diygoapi/errs"
+	"github.com/gilcrest/diygoapi/policy"
+	"github.com/gilcrest/diygoapi/sqldb/datastore"
+)
+
+// PolicyAuthorizationService manages authorization by evaluating a
+// declarative policy.Policy. The roles a user holds in the org they
+// are acting in are retrieved from the database, the Policy decides
+// what those roles (or the user themselves) are allowed to do.
+type PolicyAuthorizationService struct {
+	Datastorer diygoapi.Datastorer
+	Policy     *policy.Policy
+	// AllowSampleRate is the fraction (between 0 and 1) of allow
+	// decisions made by Authorize which are recorded. Deny decisions
+	// are always recorded.
+	AllowSampleRate float64
+}
+
+// Authorize ensures that a subject (User) can perform a particular
+// action on a resource by evaluating the Policy. The http.Request
+// context is used to determine the route/path information and must be
+// issued through the gorilla/mux library. Each decision is recorded in
+// the database, allow decisions are sampled using AllowSampleRate.
+func (s *PolicyAuthorizationService) Authorize(r *http.Request, lgr zerolog.Logger, adt diygoapi.Audit) error {
+	const op errs.Op = "service/PolicyAuthorizationService.Authorize"
+
+	ctx := r.Context()
+
+	pathTemplate, err := routePathTemplate(r)
+	if err != nil {
+		return errs.E(op, err)
+	}
+
+	var d policy.Decision
+	d, err = s.decide(ctx, adt, newPolicyRequest(r, adt, pathTemplate))
+	if err != nil {
+		return errs.E(op, err)
+	}
+
+	recordDecision(ctx, lgr, s.Datastorer, s.AllowSampleRate, newAuthzDecisionParams(r, adt, pathTemplate, d.RoleCode, d.Allowed))
+
+	if !d.Allowed {
+		lgr.Info().Str("user_extl_id", adt.User.ExternalID.String()).Str("org_extl_id", adt.ActingOrg().ExternalID.String()).
+			Str("resource", pathTemplate).Str("operation", r.Method).Str("policy_statement", d.StatementID).
+			Msgf("Unauthorized (user_extl_id: %s, resource: %s, operation: %s)", adt.User.ExternalID.String(), pathTemplate, r.Method)
+
+		return errs.E(op, errs.Unauthorized, fmt.Sprintf("User_extl_id %s does not have %s permission for %s", adt.User.ExternalID.String(), r.Method, pathTemplate))
+	}
+
+	lgr.Debug().Str("user_extl_id", adt.User.ExternalID.String()).Str("org_extl_id", adt.ActingOrg().ExternalID.String()).
+		Str("resource", pathTemplate).Str("operation", r.Method).Str("policy_statement", d.StatementID).
+		Msgf("Authorized (user_extl_id: %s, resource: %s, operation: %s)", adt.User.ExternalID.String(), pathTemplate, r.Method)
+
+	return nil
+}
+
+// decide evaluates the Policy for the request after adding the roles
+// the audit user holds in the org they are acting in
+func (s *PolicyAuthorizationService) decide(ctx context.Context, adt diygoapi.Audit, req policy.Request) (d policy.Decision, err error) {
+	const op errs.Op = "service/PolicyAuthorizationService.decide"
+
+	// start db txn using pgxpool
+	var tx pgx.Tx
+	tx, err = s.Datastorer.BeginTx(ctx)
+	if err != nil {
+		return policy.Decision{}, errs.E(op, err)
+	}
+	// defer transaction rollback and handle error, if any
+	defer func() {
+		err = s.Datastorer.RollbackTx(ctx, tx, err)
+	}()
+
+	req.Roles, err = findUserOrgRoleCodes(ctx, tx, adt)
+	if err != nil {
+		return policy.Decision{}, errs.E(op, err)
+	}
+
+	// commit db txn using pgxpool
+	err = s.Datastorer.CommitTx(ctx, tx)
+	if err != nil {
+		return policy.Decision{}, errs.E(op, err)
+	}
+
+	return s.Policy.Evaluate(req), nil
+}
+
+// FindEffectivePermissions returns the resources and operations the
+// Policy allows the audit user in the org they are acting in. Deny
+// statements and conditions are not considered, so a permission
+// returned may not apply to every request.
+func (s *PolicyAuthorizationService) FindEffectivePermissions(ctx context.Context, adt diygoapi.Audit) (responses []*diygoapi.EffectivePermissionResponse, err error) {
+	const op errs.Op = "service/PolicyAuthorizationService.FindEffectivePermissions"
+
+	// start db txn using pgxpool
+	var tx pgx.Tx
+	tx, err = s.Datastorer.BeginTx(ctx)
+	if err != nil {
+		return nil, errs.E(op, err)
+	}
+	// defer transaction rollback and handle error, if any
+	defer func() {
+		err = s.Datastorer.RollbackTx(ctx, tx, err)
+	}()
+
+	var roles []string
+	roles, err = findUserOrgRoleCodes(ctx, tx, adt)
+	if err != nil {
+		return nil, errs.E(op, err)
+	}
+
+	seen := make(map[string]bool)
+	for _, g := range s.Policy.Grants(adt.User.ExternalID.String(), roles) {
+		for _, res := range g.Statement.Resources {
+			for _, o := range g.Statement.Operations {
+				if seen[res+" "+o] {
+					continue
+				}
+				seen[res+" "+o] = true
+				responses = append(responses, &diygoapi.EffectivePermissionResponse{
+					Resource:  res,
+					Operation: o,
+					RoleCode:  g.RoleCode,
+					Effect:    diygoapi.PermissionEffectAllow,
+				})
+			}
+		}
+	}
+
+	sort.Slice(responses, func(i, j int) bool {
+		if responses[i].Resource != responses[j].Resource {
+			return responses[i].Resource < responses[j].Resource
+		}
+		return responses[i].Operation < responses[j].Operation
+	})
+
+	// commit db txn using pgxpool
+	err = s.Datastorer.CommitTx(ctx, tx)
+	if err != nil {
+		return nil, errs.E(op, err)
+	}
+
+	return responses, nil
+}
+
+// Check determines, for each resource and operation in the request,
+// whether the Policy allows the audit user in the org they are acting
+// in. The source IP of the checked requests is unknown, so statements
+// with source IP conditions do not apply.
+func (s *PolicyAuthorizationService) Check(ctx context.Context, r *diygoapi.AuthorizationCheckRequest, adt diygoapi.Audit) (responses []*diygoapi.AuthorizationCheckResponse, err error) {
+	const op errs.Op = "service/PolicyAuthorizationService.Check"
+
+	err = r.Validate()
+	if err != nil {
+		return nil, errs.E(op, err)
+	}
+
+	// start db txn using pgxpool
+	var tx pgx.Tx
+	tx, err = s.Datastorer.BeginTx(ctx)
+	if err != nil {
+		return nil, errs.E(op, err)
+	}
+	// defer transaction rollback and handle error, if any
+	defer func() {
+		err = s.Datastorer.RollbackTx(ctx, tx, err)
+	}()
+
+	var roles []string
+	roles, err = findUserOrgRoleCodes(ctx, tx, adt)
+	if err != nil {
+		return nil, errs.E(op, err)
+	}
+
+	now := time.Now()
+	for _, c := range r.Checks {
+		d := s.Policy.Evaluate(policy.Request{
+			Subject:   adt.User.ExternalID.String(),
+			Roles:     roles,
+			Resource:  c.Resource,
+			Operation: c.Operation,
+			Time:      now,
+		})
+
+		response := &diygoapi.AuthorizationCheckResponse{
+			Resource:  c.Resource,
+			Operation: c.Operation,
+			Allowed:   d.Allowed,
+		}
+		if d.Allowed {
+			response.RoleCode = d.RoleCode
+		}
+		responses = append(responses, response)
+	}
+
+	// commit db txn using pgxpool
+	err = s.Datastorer.CommitTx(ctx, tx)
+	if err != nil {
+		return nil, errs.E(op, err)
+	}
+
+	return responses, nil
+}
+
+// FindDecisions searches the authorization decisions recorded for
+// the org the audit user is acting in, most recent first.
+func (s *PolicyAuthorizationService) FindDecisions(ctx context.Context, r *diygoapi.AuthzDecisionSearchRequest, adt diygoapi.Audit) ([]*diygoapi.AuthzDecisionResponse, error) {
+	const op errs.Op = "service/PolicyAuthorizationService.FindDecisions"
+
+	responses, err := findDecisions(ctx, s.Datastorer, r, adt)
+	if err != nil {
+		return nil, errs.E(op, err)
+	}
+
+	return responses, nil
+}
+
+// ShadowAuthorizationService authorizes requests using the database,
+// exactly as DBAuthorizationService does, and evaluates each request
+// with the policy engine in the shadow. Disagreements between the two
+// are logged, the policy decision is never enforced. It allows a
+// policy to be validated against real traffic before switching to it.
+type ShadowAuthorizationService struct {
+	*DBAuthorizationService
+	Shadow *PolicyAuthorizationService
+}
+
+// Authorize authorizes the request using the database and compares
+// the decision with the one of the shadow policy engine.
+func (s *ShadowAuthorizationService) Authorize(r *http.Request, lgr zerolog.Logger, adt diygoapi.Audit) error {
+	err := s.DBAuthorizationService.Authorize(r, lgr, adt)
+
+	// only compare when the database made a decision
+	if err != nil && !errs.KindIs(errs.Unauthorized, err) {
+		return err
+	}
+	allowed := err == nil
+
+	pathTemplate, rerr := routePathTemplate(r)
+	if rerr != nil {
+		return err
+	}
+
+	d, serr := s.Shadow.decide(r.Context(), adt, newPolicyRequest(r, adt, pathTemplate))
+	if serr != nil {
+		lgr.Error().Err(serr).Msg("shadow policy not evaluated")
+		return err
+	}
+
+	if d.Allowed != allowed {
+		lgr.Warn().Str("user_extl_id", adt.User.ExternalID.String()).Str("org_extl_id", adt.ActingOrg().ExternalID.String()).
+			Str("resource", pathTemplate).Str("operation", r.Method).
+			Bool("db_allowed", allowed).Bool("policy_allowed", d.Allowed).Str("policy_statement", d.StatementID).
+			Msgf("authorization engines disagree (user_extl_id: %s, resource: %s, operation: %s)", adt.User.ExternalID.String(), pathTemplate, r.Method)
+	}
+
+	return err
+}
+
+// newPolicyRequest initializes a policy.Request for an http.Request.
+// The roles of the user are not set.
+func newPolicyRequest(r *http.Request, adt diygoapi.Audit, pathTemplate string) policy.Request {
+	req := policy.Request{
+		Subject:   adt.User.ExternalID.String(),
+		Resource:  pathTemplate,
+		Operation: r.Method,
+		Time:      time.Now(),
+	}
+
+	// RemoteAddr is normally host:port, but is not guaranteed to be
+	if ap, err := netip.ParseAddrPort(r.RemoteAddr); err == nil {
+		req.SourceIP = ap.Addr()
+	} else if a, err := netip.ParseAddr(r.RemoteAddr); err == nil {
+		req.SourceIP = a
+	}
+
+	return req
+}
+
+// findUserOrgRoleCodes returns the codes of the active roles the audit
+// user holds in the org they are acting in, including inherited roles
+func findUserOrgRoleCodes(ctx context.Context, tx pgx.Tx, adt diygoapi.Audit) ([]string, error) {
+	const op errs.Op = "service/findUserOrgRoleCodes"
+
+	roles, err := datastore.New(tx).FindUserOrgRoleCodes(ctx, datastore.FindUserOrgRoleCodesParams{UserID: adt.User.ID, OrgID: adt.ActingOrg().ID})
+	if err != nil {
+		return nil, errs.E(op, errs.Database, err)
+	}
+
+	return roles, nil
+}
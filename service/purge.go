@@ -0,0 +1,210 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/jackc/pgconn"
+	"github.com/jackc/pgx/v4"
+
+	"github.com/gilcrest/diygoapi"
+	"github.com/gilcrest/diygoapi/errs"
+	"github.com/gilcrest/diygoapi/sqldb/datastore"
+)
+
+// PurgeService permanently removes soft deleted records and the
+// authorization decisions past their retention period
+type PurgeService struct {
+	Datastorer diygoapi.Datastorer
+}
+
+// Purge permanently removes the movies, apps and orgs deleted before
+// the given moment. An org is removed along with its apps, members,
+// role grants and invitations. Each app and org is removed within its
+// own savepoint, one which is still referenced by other records (e.g.
+// an app which created other records) is skipped and left deleted.
+func (s *PurgeService) Purge(ctx context.Context, before time.Time) (response diygoapi.PurgeResponse, err error) {
+	const op errs.Op = "service/PurgeService.Purge"
+
+	// start db txn using pgxpool
+	var tx pgx.Tx
+	tx, err = s.Datastorer.BeginTx(ctx)
+	if err != nil {
+		return diygoapi.PurgeResponse{}, errs.E(op, err)
+	}
+	// defer transaction rollback and handle error, if any
+	defer func() {
+		err = s.Datastorer.RollbackTx(ctx, tx, err)
+	}()
+
+	cutoff := diygoapi.NewNullTime(before)
+
+	response.Movies, err = datastore.New(tx).PurgeDeletedMovies(ctx, cutoff)
+	if err != nil {
+		return diygoapi.PurgeResponse{}, errs.E(op, errs.Database, err)
+	}
+
+	var apps []datastore.FindDeletedAppsRow
+	apps, err = datastore.New(tx).FindDeletedApps(ctx, cutoff)
+	if err != nil {
+		return diygoapi.PurgeResponse{}, errs.E(op, errs.Database, err)
+	}
+
+	for _, row := range apps {
+		var purged bool
+		purged, err = purgeSavepoint(ctx, tx, func(sp pgx.Tx) error {
+			return deleteAppTx(ctx, sp, diygoapi.App{ID: row.AppID})
+		})
+		if err != nil {
+			return diygoapi.PurgeResponse{}, errs.E(op, err)
+		}
+		if !purged {
+			response.Skipped++
+			continue
+		}
+		response.Apps++
+	}
+
+	// deleted orgs are ordered deepest first, so that children are
+	// removed before their parent
+	var orgs []datastore.FindDeletedOrgsRow
+	orgs, err = datastore.New(tx).FindDeletedOrgs(ctx, cutoff)
+	if err != nil {
+		return diygoapi.PurgeResponse{}, errs.E(op, errs.Database, err)
+	}
+
+	for _, row := range orgs {
+		var purged bool
+		purged, err = purgeSavepoint(ctx, tx, func(sp pgx.Tx) error {
+			return purgeOrgTx(ctx, sp, row)
+		})
+		if err != nil {
+			return diygoapi.PurgeResponse{}, errs.E(op, err)
+		}
+		if !purged {
+			response.Skipped++
+			continue
+		}
+		response.Orgs++
+	}
+
+	// commit db txn using pgxpool
+	err = s.Datastorer.CommitTx(ctx, tx)
+	if err != nil {
+		return diygoapi.PurgeResponse{}, errs.E(op, err)
+	}
+
+	return response, nil
+}
+
+// PurgeAuthzDecisions permanently removes the authorization decisions
+// made before the given moment and returns the number removed.
+func (s *PurgeService) PurgeAuthzDecisions(ctx context.Context, before time.Time) (n int64, err error) {
+	const op errs.Op = "service/PurgeService.PurgeAuthzDecisions"
+
+	// start db txn using pgxpool
+	var tx pgx.Tx
+	tx, err = s.Datastorer.BeginTx(ctx)
+	if err != nil {
+		return 0, errs.E(op, err)
+	}
+	// defer transaction rollback and handle error, if any
+	defer func() {
+		err = s.Datastorer.RollbackTx(ctx, tx, err)
+	}()
+
+	n, err = datastore.New(tx).DeleteAuthzDecisions(ctx, before)
+	if err != nil {
+		return 0, errs.E(op, errs.Database, err)
+	}
+
+	// commit db txn using pgxpool
+	err = s.Datastorer.CommitTx(ctx, tx)
+	if err != nil {
+		return 0, errs.E(op, err)
+	}
+
+	return n, nil
+}
+
+// purgeSavepoint runs fn within a savepoint of tx. If fn fails as the
+// record is still referenced by other records, the savepoint is rolled
+// back and false is returned without error.
+func purgeSavepoint(ctx context.Context, tx pgx.Tx, fn func(sp pgx.Tx) error) (bool, error) {
+	const op errs.Op = "service/purgeSavepoint"
+
+	sp, err := tx.Begin(ctx)
+	if err != nil {
+		return false, errs.E(op, errs.Database, err)
+	}
+
+	err = fn(sp)
+	if err != nil {
+		if rerr := sp.Rollback(ctx); rerr != nil {
+			return false, errs.E(op, errs.Database, rerr)
+		}
+		if errs.KindIs(errs.Validation, err) {
+			return false, nil
+		}
+		return false, errs.E(op, err)
+	}
+
+	err = sp.Commit(ctx)
+	if err != nil {
+		return false, errs.E(op, errs.Database, err)
+	}
+
+	return true, nil
+}
+
+// purgeOrgTx permanently removes a deleted Org along with its apps,
+// members, role grants and invitations
+func purgeOrgTx(ctx context.Context, tx pgx.Tx, row datastore.FindDeletedOrgsRow) error {
+	const op errs.Op = "service/purgeOrgTx"
+
+	_, err := datastore.New(tx).DeleteOrgInvitationsByOrg(ctx, row.OrgID)
+	if err != nil {
+		return errs.E(op, errs.Database, err)
+	}
+
+	_, err = datastore.New(tx).DeleteUsersRolesByOrg(ctx, row.OrgID)
+	if err != nil {
+		return errs.E(op, errs.Database, err)
+	}
+
+	_, err = datastore.New(tx).DeleteUsersOrgsByOrg(ctx, row.OrgID)
+	if err != nil {
+		return errs.E(op, errs.Database, err)
+	}
+
+	var apps []datastore.App
+	apps, err = datastore.New(tx).FindAppsByOrg(ctx, row.OrgID)
+	if err != nil {
+		return errs.E(op, errs.Database, err)
+	}
+
+	for _, a := range apps {
+		err = deleteAppTx(ctx, tx, diygoapi.App{ID: a.AppID})
+		if err != nil {
+			return errs.E(op, err)
+		}
+	}
+
+	var rowsAffected int64
+	rowsAffected, err = datastore.New(tx).DeleteOrg(ctx, row.OrgID)
+	if err != nil {
+		var pgErr *pgconn.PgError
+		if errors.As(err, &pgErr) && pgErr.Code == "23503" {
+			return errs.E(op, errs.Validation, fmt.Sprintf("org %s cannot be purged as it is referenced by other records", row.OrgExtlID))
+		}
+		return errs.E(op, errs.Database, err)
+	}
+
+	if rowsAffected != 1 {
+		return errs.E(op, errs.Database, fmt.Sprintf("rows affected should be 1, actual: %d", rowsAffected))
+	}
+
+	return nil
+}
@@ -0,0 +1,769 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v4"
+	"golang.org/x/text/language"
+
+	"github.com/gilcrest/diygoapi"
+	"github.com/gilcrest/diygoapi/errs"
+	"github.com/gilcrest/diygoapi/secure"
+	"github.com/gilcrest/diygoapi/sqldb/datastore"
+)
+
+// userAudit is the combination of a domain User and its audit data
+type userAudit struct {
+	User        *diygoapi.User
+	SimpleAudit *diygoapi.SimpleAudit
+}
+
+// newUserResponse initializes UserResponse given a User and its audit data.
+func newUserResponse(ua *userAudit) *diygoapi.UserResponse {
+	response := &diygoapi.UserResponse{
+		ExternalID:          ua.User.ExternalID.String(),
+		NamePrefix:          ua.User.NamePrefix,
+		FirstName:           ua.User.FirstName,
+		MiddleName:          ua.User.MiddleName,
+		LastName:            ua.User.LastName,
+		NameSuffix:          ua.User.NameSuffix,
+		Nickname:            ua.User.Nickname,
+		Email:               ua.User.Email,
+		CompanyName:         ua.User.CompanyName,
+		CompanyDepartment:   ua.User.CompanyDepartment,
+		JobTitle:            ua.User.JobTitle,
+		Active:              !ua.User.Suspended,
+		SuspendedReason:     ua.User.SuspendedReason,
+		CreateAppExtlID:     ua.SimpleAudit.Create.App.ExternalID.String(),
+		CreateUserFirstName: ua.SimpleAudit.Create.User.FirstName,
+		CreateUserLastName:  ua.SimpleAudit.Create.User.LastName,
+		CreateDateTime:      ua.SimpleAudit.Create.Moment.Format(time.RFC3339),
+		UpdateAppExtlID:     ua.SimpleAudit.Update.App.ExternalID.String(),
+		UpdateUserFirstName: ua.SimpleAudit.Update.User.FirstName,
+		UpdateUserLastName:  ua.SimpleAudit.Update.User.LastName,
+		UpdateDateTime:      ua.SimpleAudit.Update.Moment.Format(time.RFC3339),
+	}
+
+	if ua.User.Suspended {
+		response.SuspendedDateTime = ua.User.SuspendedAt.Format(time.RFC3339)
+	}
+
+	return response
+}
+
+// UserService is a service for retrieving and updating Users
+type UserService struct {
+	Datastorer diygoapi.Datastorer
+}
+
+// FindAll is used to list the users who are members of the org the
+// audit user is acting in
+func (s *UserService) FindAll(ctx context.Context, adt diygoapi.Audit) (responses []*diygoapi.UserResponse, err error) {
+	const op errs.Op = "service/UserService.FindAll"
+
+	// start db txn using pgxpool
+	var tx pgx.Tx
+	tx, err = s.Datastorer.BeginTx(ctx)
+	if err != nil {
+		return nil, errs.E(op, err)
+	}
+	// defer transaction rollback and handle error, if any
+	defer func() {
+		err = s.Datastorer.RollbackTx(ctx, tx, err)
+	}()
+
+	var rows []datastore.FindUsersByOrgWithAuditRow
+	rows, err = datastore.New(tx).FindUsersByOrgWithAudit(ctx, adt.ActingOrg().ID)
+	if err != nil {
+		return nil, errs.E(op, errs.Database, err)
+	}
+
+	for _, row := range rows {
+		responses = append(responses, newUserResponse(newUserAudit(datastore.FindUserByExternalIDWithAuditRow(row))))
+	}
+
+	// commit db txn using pgxpool
+	err = s.Datastorer.CommitTx(ctx, tx)
+	if err != nil {
+		return nil, errs.E(op, err)
+	}
+
+	return responses, nil
+}
+
+// FindByExternalID is used to find a User by its External ID. Only
+// the audit user themselves or a member of the org the audit user is
+// acting in is found.
+func (s *UserService) FindByExternalID(ctx context.Context, extlID string, adt diygoapi.Audit) (response *diygoapi.UserResponse, err error) {
+	const op errs.Op = "service/UserService.FindByExternalID"
+
+	// start db txn using pgxpool
+	var tx pgx.Tx
+	tx, err = s.Datastorer.BeginTx(ctx)
+	if err != nil {
+		return nil, errs.E(op, err)
+	}
+	// defer transaction rollback and handle error, if any
+	defer func() {
+		err = s.Datastorer.RollbackTx(ctx, tx, err)
+	}()
+
+	var ua *userAudit
+	ua, err = findUserByExternalIDWithAudit(ctx, tx, extlID)
+	if err != nil {
+		return nil, errs.E(op, err)
+	}
+
+	err = authorizeUser(ctx, tx, adt, ua.User, false)
+	if err != nil {
+		return nil, errs.E(op, err)
+	}
+
+	// commit db txn using pgxpool
+	err = s.Datastorer.CommitTx(ctx, tx)
+	if err != nil {
+		return nil, errs.E(op, err)
+	}
+
+	return newUserResponse(ua), nil
+}
+
+// FindByOrg is used to list the users who are members of an Org. The
+// audit user must be a member or an admin of the Org.
+func (s *UserService) FindByOrg(ctx context.Context, orgExtlID string, adt diygoapi.Audit) (responses []*diygoapi.UserResponse, err error) {
+	const op errs.Op = "service/UserService.FindByOrg"
+
+	// start db txn using pgxpool
+	var tx pgx.Tx
+	tx, err = s.Datastorer.BeginTx(ctx)
+	if err != nil {
+		return nil, errs.E(op, err)
+	}
+	// defer transaction rollback and handle error, if any
+	defer func() {
+		err = s.Datastorer.RollbackTx(ctx, tx, err)
+	}()
+
+	var o diygoapi.Org
+	o, err = findOrgByExternalID(ctx, tx, orgExtlID)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, errs.E(op, errs.NotExist, "No org exists for the given external ID")
+		}
+		return nil, errs.E(op, err)
+	}
+
+	if adt.User == nil {
+		return nil, errs.E(op, errs.Unauthorized, "a user is required to list the users of an org")
+	}
+
+	// the members of an org see each other, otherwise only an admin of
+	// the org may list them
+	_, err = datastore.New(tx).FindUserOrgByExtlID(ctx, datastore.FindUserOrgByExtlIDParams{OrgExtlID: o.ExternalID.String(), UserID: adt.User.ID})
+	if err != nil {
+		if !errors.Is(err, pgx.ErrNoRows) {
+			return nil, errs.E(op, errs.Database, err)
+		}
+		err = authorizeOwner(ctx, tx, adt, diygoapi.Owner{OrgID: o.ID})
+		if err != nil {
+			return nil, errs.E(op, err)
+		}
+	}
+
+	var rows []datastore.FindUsersByOrgWithAuditRow
+	rows, err = datastore.New(tx).FindUsersByOrgWithAudit(ctx, o.ID)
+	if err != nil {
+		return nil, errs.E(op, errs.Database, err)
+	}
+
+	for _, row := range rows {
+		responses = append(responses, newUserResponse(newUserAudit(datastore.FindUserByExternalIDWithAuditRow(row))))
+	}
+
+	// commit db txn using pgxpool
+	err = s.Datastorer.CommitTx(ctx, tx)
+	if err != nil {
+		return nil, errs.E(op, err)
+	}
+
+	return responses, nil
+}
+
+// Update is used to update the profile of a User. A User may update
+// their own profile, otherwise the User must be a member of the org
+// the audit user is acting in and the audit user an admin of it.
+func (s *UserService) Update(ctx context.Context, r *diygoapi.UpdateUserRequest, adt diygoapi.Audit) (response *diygoapi.UserResponse, err error) {
+	const op errs.Op = "service/UserService.Update"
+
+	err = r.Validate()
+	if err != nil {
+		return nil, errs.E(op, err)
+	}
+
+	// start db txn using pgxpool
+	var tx pgx.Tx
+	tx, err = s.Datastorer.BeginTx(ctx)
+	if err != nil {
+		return nil, errs.E(op, err)
+	}
+	// defer transaction rollback and handle error, if any
+	defer func() {
+		err = s.Datastorer.RollbackTx(ctx, tx, err)
+	}()
+
+	// retrieve existing User
+	var ua *userAudit
+	ua, err = findUserByExternalIDWithAudit(ctx, tx, r.ExternalID)
+	if err != nil {
+		return nil, errs.E(op, err)
+	}
+
+	err = authorizeUser(ctx, tx, adt, ua.User, true)
+	if err != nil {
+		return nil, errs.E(op, err)
+	}
+
+	// overwrite Last audit with the current audit
+	ua.SimpleAudit.Update = adt
+
+	// override fields with data from request
+	ua.User.NamePrefix = r.NamePrefix
+	ua.User.FirstName = r.FirstName
+	ua.User.MiddleName = r.MiddleName
+	ua.User.LastName = r.LastName
+	ua.User.NameSuffix = r.NameSuffix
+	ua.User.Nickname = r.Nickname
+	ua.User.CompanyName = r.CompanyName
+	ua.User.CompanyDepartment = r.CompanyDepartment
+	ua.User.JobTitle = r.JobTitle
+
+	params := datastore.UpdateUserParams{
+		NamePrefix:      diygoapi.NewNullString(ua.User.NamePrefix),
+		FirstName:       ua.User.FirstName,
+		MiddleName:      diygoapi.NewNullString(ua.User.MiddleName),
+		LastName:        ua.User.LastName,
+		NameSuffix:      diygoapi.NewNullString(ua.User.NameSuffix),
+		Nickname:        diygoapi.NewNullString(ua.User.Nickname),
+		CompanyName:     diygoapi.NewNullString(ua.User.CompanyName),
+		CompanyDept:     diygoapi.NewNullString(ua.User.CompanyDepartment),
+		JobTitle:        diygoapi.NewNullString(ua.User.JobTitle),
+		UpdateAppID:     adt.App.ID,
+		UpdateUserID:    adt.User.NullUUID(),
+		UpdateTimestamp: adt.Moment,
+		UserID:          ua.User.ID,
+	}
+
+	// update database record using datastore
+	var rowsAffected int64
+	rowsAffected, err = datastore.New(tx).UpdateUser(ctx, params)
+	if err != nil {
+		return nil, errs.E(op, errs.Database, err)
+	}
+
+	// update should only update exactly one record
+	if rowsAffected != 1 {
+		return nil, errs.E(op, errs.Database, fmt.Sprintf("UpdateUser() should update 1 row, actual: %d", rowsAffected))
+	}
+
+	// commit db txn using pgxpool
+	err = s.Datastorer.CommitTx(ctx, tx)
+	if err != nil {
+		return nil, errs.E(op, err)
+	}
+
+	return newUserResponse(ua), nil
+}
+
+// RemoveFromOrg removes a User from an Org. The roles the User holds
+// in the Org are revoked as well.
+func (s *UserService) RemoveFromOrg(ctx context.Context, orgExtlID, userExtlID string, adt diygoapi.Audit) (dr diygoapi.DeleteResponse, err error) {
+	const op errs.Op = "service/UserService.RemoveFromOrg"
+
+	// start db txn using pgxpool
+	var tx pgx.Tx
+	tx, err = s.Datastorer.BeginTx(ctx)
+	if err != nil {
+		return diygoapi.DeleteResponse{}, errs.E(op, err)
+	}
+	// defer transaction rollback and handle error, if any
+	defer func() {
+		err = s.Datastorer.RollbackTx(ctx, tx, err)
+	}()
+
+	var o diygoapi.Org
+	o, err = findOrgByExternalID(ctx, tx, orgExtlID)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return diygoapi.DeleteResponse{}, errs.E(op, errs.NotExist, "No org exists for the given external ID")
+		}
+		return diygoapi.DeleteResponse{}, errs.E(op, err)
+	}
+
+	// only an admin of the org may remove its members
+	err = authorizeOwner(ctx, tx, adt, diygoapi.Owner{OrgID: o.ID})
+	if err != nil {
+		return diygoapi.DeleteResponse{}, errs.E(op, err)
+	}
+
+	var u *diygoapi.User
+	u, err = FindUserByExternalID(ctx, tx, userExtlID)
+	if err != nil {
+		return diygoapi.DeleteResponse{}, errs.E(op, err)
+	}
+
+	_, err = datastore.New(tx).DeleteUsersRolesByOrgUser(ctx, datastore.DeleteUsersRolesByOrgUserParams{OrgID: o.ID, UserID: u.ID})
+	if err != nil {
+		return diygoapi.DeleteResponse{}, errs.E(op, errs.Database, err)
+	}
+
+	var rowsAffected int64
+	rowsAffected, err = datastore.New(tx).DeleteUsersOrg(ctx, datastore.DeleteUsersOrgParams{OrgID: o.ID, UserID: u.ID})
+	if err != nil {
+		return diygoapi.DeleteResponse{}, errs.E(op, errs.Database, err)
+	}
+
+	if rowsAffected == 0 {
+		return diygoapi.DeleteResponse{}, errs.E(op, errs.NotExist, fmt.Sprintf("user %s is not a member of org %s", userExtlID, orgExtlID))
+	}
+	if rowsAffected != 1 {
+		return diygoapi.DeleteResponse{}, errs.E(op, errs.Database, fmt.Sprintf("rows affected should be 1, actual: %d", rowsAffected))
+	}
+
+	// commit db txn using pgxpool
+	err = s.Datastorer.CommitTx(ctx, tx)
+	if err != nil {
+		return diygoapi.DeleteResponse{}, errs.E(op, err)
+	}
+
+	response := diygoapi.DeleteResponse{
+		ExternalID: userExtlID,
+		Deleted:    true,
+	}
+
+	return response, nil
+}
+
+// Suspend prevents a User from authenticating until they are
+// reinstated. The access tokens stored for the User are expired, so
+// they cannot be used again after reinstatement. The audit user must
+// be an admin of every org the User belongs to and cannot suspend
+// themselves.
+func (s *UserService) Suspend(ctx context.Context, r *diygoapi.SuspendUserRequest, adt diygoapi.Audit) (response *diygoapi.UserResponse, err error) {
+	const op errs.Op = "service/UserService.Suspend"
+
+	err = r.Validate()
+	if err != nil {
+		return nil, errs.E(op, err)
+	}
+
+	// start db txn using pgxpool
+	var tx pgx.Tx
+	tx, err = s.Datastorer.BeginTx(ctx)
+	if err != nil {
+		return nil, errs.E(op, err)
+	}
+	// defer transaction rollback and handle error, if any
+	defer func() {
+		err = s.Datastorer.RollbackTx(ctx, tx, err)
+	}()
+
+	var ua *userAudit
+	ua, err = findUserByExternalIDWithAudit(ctx, tx, r.ExternalID)
+	if err != nil {
+		return nil, errs.E(op, err)
+	}
+
+	// a user suspending themselves would lock themselves out
+	if ua.User.ID == adt.User.ID {
+		return nil, errs.E(op, errs.Validation, "you cannot suspend yourself")
+	}
+
+	err = authorizeUserAdmin(ctx, tx, adt, ua.User)
+	if err != nil {
+		return nil, errs.E(op, err)
+	}
+
+	if ua.User.Suspended {
+		return nil, errs.E(op, errs.Exist, fmt.Sprintf("user %s is already suspended", r.ExternalID))
+	}
+
+	ua.User.Suspended = true
+	ua.User.SuspendedReason = r.Reason
+	ua.User.SuspendedAt = adt.Moment
+	ua.SimpleAudit.Update = adt
+
+	params := datastore.SuspendUserParams{
+		SuspendedReason:    diygoapi.NewNullString(ua.User.SuspendedReason),
+		SuspendedTimestamp: diygoapi.NewNullTime(ua.User.SuspendedAt),
+		UpdateAppID:        adt.App.ID,
+		UpdateUserID:       adt.User.NullUUID(),
+		UpdateTimestamp:    adt.Moment,
+		UserID:             ua.User.ID,
+	}
+
+	var rowsAffected int64
+	rowsAffected, err = datastore.New(tx).SuspendUser(ctx, params)
+	if err != nil {
+		return nil, errs.E(op, errs.Database, err)
+	}
+
+	if rowsAffected != 1 {
+		return nil, errs.E(op, errs.Database, fmt.Sprintf("SuspendUser() should update 1 row, actual: %d", rowsAffected))
+	}
+
+	// expire the access tokens stored for the user
+	_, err = datastore.New(tx).ExpireAuthsByUserID(ctx, datastore.ExpireAuthsByUserIDParams{
+		UserID:                        ua.User.ID,
+		AuthProviderAccessTokenExpiry: diygoapi.NewNullTime(adt.Moment),
+		UpdateAppID:                   adt.App.ID,
+		UpdateUserID:                  adt.User.NullUUID(),
+		UpdateTimestamp:               adt.Moment,
+	})
+	if err != nil {
+		return nil, errs.E(op, errs.Database, err)
+	}
+
+	// commit db txn using pgxpool
+	err = s.Datastorer.CommitTx(ctx, tx)
+	if err != nil {
+		return nil, errs.E(op, err)
+	}
+
+	return newUserResponse(ua), nil
+}
+
+// Reinstate allows a suspended User to authenticate again. The User
+// must authenticate with a new access token. The audit user must be
+// an admin of every org the User belongs to.
+func (s *UserService) Reinstate(ctx context.Context, extlID string, adt diygoapi.Audit) (response *diygoapi.UserResponse, err error) {
+	const op errs.Op = "service/UserService.Reinstate"
+
+	// start db txn using pgxpool
+	var tx pgx.Tx
+	tx, err = s.Datastorer.BeginTx(ctx)
+	if err != nil {
+		return nil, errs.E(op, err)
+	}
+	// defer transaction rollback and handle error, if any
+	defer func() {
+		err = s.Datastorer.RollbackTx(ctx, tx, err)
+	}()
+
+	var ua *userAudit
+	ua, err = findUserByExternalIDWithAudit(ctx, tx, extlID)
+	if err != nil {
+		return nil, errs.E(op, err)
+	}
+
+	err = authorizeUserAdmin(ctx, tx, adt, ua.User)
+	if err != nil {
+		return nil, errs.E(op, err)
+	}
+
+	if !ua.User.Suspended {
+		return nil, errs.E(op, errs.NotExist, fmt.Sprintf("user %s is not suspended", extlID))
+	}
+
+	ua.User.Suspended = false
+	ua.User.SuspendedReason = ""
+	ua.User.SuspendedAt = time.Time{}
+	ua.SimpleAudit.Update = adt
+
+	params := datastore.ReinstateUserParams{
+		UpdateAppID:     adt.App.ID,
+		UpdateUserID:    adt.User.NullUUID(),
+		UpdateTimestamp: adt.Moment,
+		UserID:          ua.User.ID,
+	}
+
+	var rowsAffected int64
+	rowsAffected, err = datastore.New(tx).ReinstateUser(ctx, params)
+	if err != nil {
+		return nil, errs.E(op, errs.Database, err)
+	}
+
+	if rowsAffected != 1 {
+		return nil, errs.E(op, errs.Database, fmt.Sprintf("ReinstateUser() should update 1 row, actual: %d", rowsAffected))
+	}
+
+	// commit db txn using pgxpool
+	err = s.Datastorer.CommitTx(ctx, tx)
+	if err != nil {
+		return nil, errs.E(op, err)
+	}
+
+	return newUserResponse(ua), nil
+}
+
+// FindLanguagePreferences is used to find the language preferences of
+// a User, the most preferred first. The preferences may only be read by
+// the User or an admin of the acting org.
+func (s *UserService) FindLanguagePreferences(ctx context.Context, extlID string, adt diygoapi.Audit) (response *diygoapi.UserLanguagePreferencesResponse, err error) {
+	const op errs.Op = "service/UserService.FindLanguagePreferences"
+
+	// start db txn using pgxpool
+	var tx pgx.Tx
+	tx, err = s.Datastorer.BeginTx(ctx)
+	if err != nil {
+		return nil, errs.E(op, err)
+	}
+	// defer transaction rollback and handle error, if any
+	defer func() {
+		err = s.Datastorer.RollbackTx(ctx, tx, err)
+	}()
+
+	var u *diygoapi.User
+	u, err = FindUserByExternalID(ctx, tx, extlID)
+	if err != nil {
+		return nil, errs.E(op, err)
+	}
+
+	err = authorizeUser(ctx, tx, adt, u, true)
+	if err != nil {
+		return nil, errs.E(op, err)
+	}
+
+	// commit db txn using pgxpool
+	err = s.Datastorer.CommitTx(ctx, tx)
+	if err != nil {
+		return nil, errs.E(op, err)
+	}
+
+	return newUserLanguagePreferencesResponse(u), nil
+}
+
+// UpdateLanguagePreferences is used to replace the language
+// preferences of a User. An empty list of language tags removes all
+// the preferences of the User. The preferences may only be replaced by
+// the User or an admin of the acting org.
+func (s *UserService) UpdateLanguagePreferences(ctx context.Context, r *diygoapi.UpdateUserLanguagePreferencesRequest, adt diygoapi.Audit) (response *diygoapi.UserLanguagePreferencesResponse, err error) {
+	const op errs.Op = "service/UserService.UpdateLanguagePreferences"
+
+	err = r.Validate()
+	if err != nil {
+		return nil, errs.E(op, err)
+	}
+
+	// start db txn using pgxpool
+	var tx pgx.Tx
+	tx, err = s.Datastorer.BeginTx(ctx)
+	if err != nil {
+		return nil, errs.E(op, err)
+	}
+	// defer transaction rollback and handle error, if any
+	defer func() {
+		err = s.Datastorer.RollbackTx(ctx, tx, err)
+	}()
+
+	var u *diygoapi.User
+	u, err = FindUserByExternalID(ctx, tx, r.ExternalID)
+	if err != nil {
+		return nil, errs.E(op, err)
+	}
+
+	err = authorizeUser(ctx, tx, adt, u, true)
+	if err != nil {
+		return nil, errs.E(op, err)
+	}
+
+	// the tags have been validated, they parse without error
+	u.LanguagePreferences = nil
+	for _, lt := range r.LanguageTags {
+		u.LanguagePreferences = append(u.LanguagePreferences, language.Make(lt))
+	}
+
+	_, err = datastore.New(tx).DeleteUserLanguagePreferences(ctx, u.ID)
+	if err != nil {
+		return nil, errs.E(op, errs.Database, err)
+	}
+
+	err = createUserLanguagePreferencesTx(ctx, tx, u, adt)
+	if err != nil {
+		return nil, errs.E(op, err)
+	}
+
+	// commit db txn using pgxpool
+	err = s.Datastorer.CommitTx(ctx, tx)
+	if err != nil {
+		return nil, errs.E(op, err)
+	}
+
+	return newUserLanguagePreferencesResponse(u), nil
+}
+
+// newUserLanguagePreferencesResponse initializes
+// UserLanguagePreferencesResponse given a User
+func newUserLanguagePreferencesResponse(u *diygoapi.User) *diygoapi.UserLanguagePreferencesResponse {
+	tags := make([]string, 0, len(u.LanguagePreferences))
+	for _, tag := range u.LanguagePreferences {
+		tags = append(tags, tag.String())
+	}
+
+	return &diygoapi.UserLanguagePreferencesResponse{
+		UserExtlID:   u.ExternalID.String(),
+		LanguageTags: tags,
+	}
+}
+
+// findUserByExternalIDWithAudit retrieves User data from the datastore
+// given a unique external ID, which is then hydrated into User and
+// audit structs.
+func findUserByExternalIDWithAudit(ctx context.Context, dbtx diygoapi.DBTX, extlID string) (*userAudit, error) {
+	const op errs.Op = "service/findUserByExternalIDWithAudit"
+
+	row, err := datastore.New(dbtx).FindUserByExternalIDWithAudit(ctx, extlID)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, errs.E(op, errs.NotExist, fmt.Sprintf("no user found with external ID: %s", extlID))
+		}
+		return nil, errs.E(op, errs.Database, err)
+	}
+
+	return newUserAudit(row), nil
+}
+
+// newUserAudit initializes a userAudit given a row selected with audit
+// data. The rows of the other "WithAudit" user queries have the same
+// fields and can be converted to this type.
+func newUserAudit(row datastore.FindUserByExternalIDWithAuditRow) *userAudit {
+	u := &diygoapi.User{
+		ID:                row.UserID,
+		ExternalID:        secure.MustParseIdentifier(row.UserExtlID),
+		NamePrefix:        row.NamePrefix.String,
+		FirstName:         row.FirstName,
+		MiddleName:        row.MiddleName.String,
+		LastName:          row.LastName,
+		NameSuffix:        row.NameSuffix.String,
+		Nickname:          row.Nickname.String,
+		Email:             row.Email.String,
+		CompanyName:       row.CompanyName.String,
+		CompanyDepartment: row.CompanyDept.String,
+		JobTitle:          row.JobTitle.String,
+		Suspended:         !row.Active,
+		SuspendedReason:   row.SuspendedReason.String,
+		SuspendedAt:       row.SuspendedTimestamp.Time,
+	}
+
+	sa := &diygoapi.SimpleAudit{
+		Create: diygoapi.Audit{
+			App: &diygoapi.App{
+				ID:          row.CreateAppID,
+				ExternalID:  secure.MustParseIdentifier(row.CreateAppExtlID),
+				Org:         &diygoapi.Org{ID: row.CreateAppOrgID},
+				Name:        row.CreateAppName,
+				Description: row.CreateAppDescription,
+			},
+			User: &diygoapi.User{
+				ID:        row.CreateUserID.UUID,
+				FirstName: row.CreateUserFirstName.String,
+				LastName:  row.CreateUserLastName.String,
+			},
+			Moment: row.CreateTimestamp,
+		},
+		Update: diygoapi.Audit{
+			App: &diygoapi.App{
+				ID:          row.UpdateAppID,
+				ExternalID:  secure.MustParseIdentifier(row.UpdateAppExtlID),
+				Org:         &diygoapi.Org{ID: row.UpdateAppOrgID},
+				Name:        row.UpdateAppName,
+				Description: row.UpdateAppDescription,
+			},
+			User: &diygoapi.User{
+				ID:        row.UpdateUserID.UUID,
+				FirstName: row.UpdateUserFirstName.String,
+				LastName:  row.UpdateUserLastName.String,
+			},
+			Moment: row.UpdateTimestamp,
+		},
+	}
+
+	return &userAudit{User: u, SimpleAudit: sa}
+}
+
+// authorizeUser determines whether the audit user may act on the User
+// u. A User may always act on themselves. Otherwise, u must be a member
+// of the org the audit user is acting in, if not, an errs.NotExist error
+// is returned so users of other orgs are not disclosed. To modify u,
+// the audit user must also be an admin of that org.
+func authorizeUser(ctx context.Context, tx pgx.Tx, adt diygoapi.Audit, u *diygoapi.User, modify bool) error {
+	const op errs.Op = "service/authorizeUser"
+
+	if adt.User != nil && adt.User.ID == u.ID {
+		return nil
+	}
+
+	o := adt.ActingOrg()
+
+	_, err := datastore.New(tx).FindUserOrgByExtlID(ctx, datastore.FindUserOrgByExtlIDParams{OrgExtlID: o.ExternalID.String(), UserID: u.ID})
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return errs.E(op, errs.NotExist, fmt.Sprintf("user %s is not a member of org %s", u.ExternalID.String(), o.ExternalID.String()))
+		}
+		return errs.E(op, errs.Database, err)
+	}
+
+	if !modify {
+		return nil
+	}
+
+	err = authorizeOwner(ctx, tx, adt, diygoapi.Owner{OrgID: o.ID})
+	if err != nil {
+		return errs.E(op, err)
+	}
+
+	return nil
+}
+
+// authorizeUserAdmin determines whether the audit user administers
+// the User u, which affects u in every org u belongs to. The audit user
+// must hold the orgAdmin or sysAdmin role in each of those orgs or the
+// sysAdmin role in the Principal org. Otherwise, an errs.Unauthorized
+// error is returned.
+func authorizeUserAdmin(ctx context.Context, tx pgx.Tx, adt diygoapi.Audit, u *diygoapi.User) error {
+	const op errs.Op = "service/authorizeUserAdmin"
+
+	if adt.User == nil {
+		return errs.E(op, errs.Unauthorized, "a user is required to administer a user")
+	}
+
+	admin, err := isPrincipalSysAdmin(ctx, tx, adt.User)
+	if err != nil {
+		return errs.E(op, err)
+	}
+	if admin {
+		return nil
+	}
+
+	var orgIDs []uuid.UUID
+	orgIDs, err = datastore.New(tx).FindOrgIDsByUser(ctx, u.ID)
+	if err != nil {
+		return errs.E(op, errs.Database, err)
+	}
+
+	unauthorized := errs.E(op, errs.Unauthorized, fmt.Sprintf("User_extl_id %s is not an admin of every org user %s belongs to", adt.User.ExternalID.String(), u.ExternalID.String()))
+
+	// a user who belongs to no org is only administered by a sysAdmin
+	if len(orgIDs) == 0 {
+		return unauthorized
+	}
+
+	for _, orgID := range orgIDs {
+		admin, err = datastore.New(tx).HasAnyOrgRole(ctx, datastore.HasAnyOrgRoleParams{
+			UserID:  adt.User.ID,
+			OrgID:   orgID,
+			RoleCds: []string{diygoapi.OrgAdminRoleCode, diygoapi.SysAdminRoleCode},
+		})
+		if err != nil {
+			return errs.E(op, errs.Database, err)
+		}
+		if !admin {
+			return unauthorized
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,146 @@
+package service_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	qt "github.com/frankban/quicktest"
+
+	"github.com/gilcrest/diygoapi"
+	"github.com/gilcrest/diygoapi/errs"
+	"github.com/gilcrest/diygoapi/service"
+	"github.com/gilcrest/diygoapi/sqldb/sqldbtest"
+)
+
+func TestUserService(t *testing.T) {
+	c := qt.New(t)
+
+	db, cleanup := sqldbtest.NewDB(t)
+	c.Cleanup(cleanup)
+
+	// admin is the orgAdmin of org, member is a member of org,
+	// outsider is a member of another org and dualMember is a member
+	// of both
+	ctx := context.Background()
+	tx, err := db.BeginTx(ctx)
+	if err != nil {
+		c.Fatalf("BeginTx() error = %v", err)
+	}
+	adt := findTestAudit(ctx, c, tx)
+	org := createTestOrg(ctx, c, tx, adt, nil)
+	otherOrg := createTestOrg(ctx, c, tx, adt, nil)
+	admin := createTestUser(ctx, c, tx, adt, org)
+	member := createTestUser(ctx, c, tx, adt, org)
+	outsider := createTestUser(ctx, c, tx, adt, otherOrg)
+	dualMember := createTestUser(ctx, c, tx, adt, org, otherOrg)
+	grantTestRole(ctx, c, tx, adt, admin, org, diygoapi.OrgAdminRoleCode, time.Time{}, time.Time{})
+	err = db.CommitTx(ctx, tx)
+	c.Assert(err, qt.IsNil)
+
+	s := service.UserService{Datastorer: db}
+
+	audit := func(u *diygoapi.User) diygoapi.Audit {
+		return diygoapi.Audit{App: adt.App, User: u, Org: org, Moment: time.Now()}
+	}
+	update := func(u *diygoapi.User) *diygoapi.UpdateUserRequest {
+		return &diygoapi.UpdateUserRequest{ExternalID: u.ExternalID.String(), FirstName: "Updated", LastName: u.LastName}
+	}
+
+	c.Run("find all", func(c *qt.C) {
+		responses, err := s.FindAll(ctx, audit(member))
+		c.Assert(err, qt.IsNil)
+
+		var got []string
+		for _, r := range responses {
+			got = append(got, r.ExternalID)
+		}
+		c.Assert(got, qt.HasLen, 3)
+		c.Assert(got, qt.Contains, admin.ExternalID.String())
+		c.Assert(got, qt.Contains, member.ExternalID.String())
+	})
+	c.Run("find by org", func(c *qt.C) {
+		responses, err := s.FindByOrg(ctx, org.ExternalID.String(), audit(member))
+		c.Assert(err, qt.IsNil)
+		c.Assert(responses, qt.HasLen, 3)
+
+		_, err = s.FindByOrg(ctx, org.ExternalID.String(), audit(outsider))
+		c.Assert(errs.KindIs(errs.Unauthorized, err), qt.IsTrue, qt.Commentf("error = %v", err))
+	})
+	c.Run("find a member", func(c *qt.C) {
+		_, err := s.FindByExternalID(ctx, admin.ExternalID.String(), audit(member))
+		c.Assert(err, qt.IsNil)
+	})
+	c.Run("find a user of another org", func(c *qt.C) {
+		_, err := s.FindByExternalID(ctx, outsider.ExternalID.String(), audit(member))
+		c.Assert(errs.KindIs(errs.NotExist, err), qt.IsTrue, qt.Commentf("error = %v", err))
+	})
+	c.Run("update themselves", func(c *qt.C) {
+		_, err := s.Update(ctx, update(member), audit(member))
+		c.Assert(err, qt.IsNil)
+	})
+	c.Run("update a member as a non-admin", func(c *qt.C) {
+		_, err := s.Update(ctx, update(admin), audit(member))
+		c.Assert(errs.KindIs(errs.Unauthorized, err), qt.IsTrue, qt.Commentf("error = %v", err))
+	})
+	c.Run("update a member as an admin", func(c *qt.C) {
+		_, err := s.Update(ctx, update(member), audit(admin))
+		c.Assert(err, qt.IsNil)
+	})
+	c.Run("update a user of another org", func(c *qt.C) {
+		_, err := s.Update(ctx, update(outsider), audit(admin))
+		c.Assert(errs.KindIs(errs.NotExist, err), qt.IsTrue, qt.Commentf("error = %v", err))
+	})
+	c.Run("language preferences", func(c *qt.C) {
+		langs := func(u *diygoapi.User) *diygoapi.UpdateUserLanguagePreferencesRequest {
+			return &diygoapi.UpdateUserLanguagePreferencesRequest{ExternalID: u.ExternalID.String(), LanguageTags: []string{"en-US"}}
+		}
+
+		_, err := s.FindLanguagePreferences(ctx, member.ExternalID.String(), audit(member))
+		c.Assert(err, qt.IsNil)
+		_, err = s.UpdateLanguagePreferences(ctx, langs(member), audit(member))
+		c.Assert(err, qt.IsNil)
+
+		_, err = s.FindLanguagePreferences(ctx, member.ExternalID.String(), audit(admin))
+		c.Assert(err, qt.IsNil)
+		_, err = s.UpdateLanguagePreferences(ctx, langs(member), audit(admin))
+		c.Assert(err, qt.IsNil)
+
+		_, err = s.FindLanguagePreferences(ctx, admin.ExternalID.String(), audit(member))
+		c.Assert(errs.KindIs(errs.Unauthorized, err), qt.IsTrue, qt.Commentf("error = %v", err))
+		_, err = s.UpdateLanguagePreferences(ctx, langs(admin), audit(member))
+		c.Assert(errs.KindIs(errs.Unauthorized, err), qt.IsTrue, qt.Commentf("error = %v", err))
+
+		_, err = s.FindLanguagePreferences(ctx, outsider.ExternalID.String(), audit(admin))
+		c.Assert(errs.KindIs(errs.NotExist, err), qt.IsTrue, qt.Commentf("error = %v", err))
+		_, err = s.UpdateLanguagePreferences(ctx, langs(outsider), audit(admin))
+		c.Assert(errs.KindIs(errs.NotExist, err), qt.IsTrue, qt.Commentf("error = %v", err))
+	})
+	c.Run("suspend", func(c *qt.C) {
+		suspend := func(u *diygoapi.User) *diygoapi.SuspendUserRequest {
+			return &diygoapi.SuspendUserRequest{ExternalID: u.ExternalID.String(), Reason: "Suspended by TestUserService"}
+		}
+
+		_, err := s.Suspend(ctx, suspend(admin), audit(admin))
+		c.Assert(errs.KindIs(errs.Validation, err), qt.IsTrue, qt.Commentf("error = %v", err))
+
+		_, err = s.Suspend(ctx, suspend(admin), audit(member))
+		c.Assert(errs.KindIs(errs.Unauthorized, err), qt.IsTrue, qt.Commentf("error = %v", err))
+
+		_, err = s.Suspend(ctx, suspend(outsider), audit(admin))
+		c.Assert(errs.KindIs(errs.Unauthorized, err), qt.IsTrue, qt.Commentf("error = %v", err))
+
+		// an admin of only one of the orgs the user belongs to
+		_, err = s.Suspend(ctx, suspend(dualMember), audit(admin))
+		c.Assert(errs.KindIs(errs.Unauthorized, err), qt.IsTrue, qt.Commentf("error = %v", err))
+
+		_, err = s.Suspend(ctx, suspend(member), audit(admin))
+		c.Assert(err, qt.IsNil)
+
+		_, err = s.Reinstate(ctx, member.ExternalID.String(), audit(outsider))
+		c.Assert(errs.KindIs(errs.Unauthorized, err), qt.IsTrue, qt.Commentf("error = %v", err))
+
+		_, err = s.Reinstate(ctx, member.ExternalID.String(), audit(admin))
+		c.Assert(err, qt.IsNil)
+	})
+}
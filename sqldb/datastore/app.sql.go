@@ -60,13 +60,14 @@ func (q *Queries) CreateApp(ctx context.Context, arg CreateAppParams) (int64, er
 }
 
 const createAppAPIKey = `-- name: CreateAppAPIKey :execrows
-INSERT INTO app_api_key (api_key, app_id, deactv_date, create_app_id, create_user_id,
+INSERT INTO app_api_key (api_key, api_key_prefix, app_id, deactv_date, create_app_id, create_user_id,
                          create_timestamp, update_app_id, update_user_id, update_timestamp)
-VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)
 `
 
 type CreateAppAPIKeyParams struct {
 	ApiKey          string
+	ApiKeyPrefix    string
 	AppID           uuid.UUID
 	DeactvDate      time.Time
 	CreateAppID     uuid.UUID
@@ -80,6 +81,7 @@ type CreateAppAPIKeyParams struct {
 func (q *Queries) CreateAppAPIKey(ctx context.Context, arg CreateAppAPIKeyParams) (int64, error) {
 	result, err := q.db.Exec(ctx, createAppAPIKey,
 		arg.ApiKey,
+		arg.ApiKeyPrefix,
 		arg.AppID,
 		arg.DeactvDate,
 		arg.CreateAppID,
@@ -135,7 +137,7 @@ func (q *Queries) DeleteAppAPIKeys(ctx context.Context, appID uuid.UUID) (int64,
 }
 
 const findAPIKeysByAppID = `-- name: FindAPIKeysByAppID :many
-SELECT api_key, app_id, deactv_date, create_app_id, create_user_id, create_timestamp, update_app_id, update_user_id, update_timestamp FROM app_api_key
+SELECT api_key, api_key_prefix, app_id, deactv_date, last_used_timestamp, create_app_id, create_user_id, create_timestamp, update_app_id, update_user_id, update_timestamp FROM app_api_key
 WHERE app_id = $1
 `
 
@@ -150,8 +152,10 @@ func (q *Queries) FindAPIKeysByAppID(ctx context.Context, appID uuid.UUID) ([]Ap
 		var i AppApiKey
 		if err := rows.Scan(
 			&i.ApiKey,
+			&i.ApiKeyPrefix,
 			&i.AppID,
 			&i.DeactvDate,
+			&i.LastUsedTimestamp,
 			&i.CreateAppID,
 			&i.CreateUserID,
 			&i.CreateTimestamp,
@@ -179,28 +183,40 @@ select a.app_id,
        o.org_name,
        o.org_description,
        aak.api_key,
-       aak.deactv_date
+       aak.api_key_prefix,
+       aak.deactv_date,
+       aak.last_used_timestamp
 from app a
          inner join org o on o.org_id = a.org_id
          inner join app_api_key aak on a.app_id = aak.app_id
 where a.app_extl_id = $1
+  and aak.api_key_prefix = $2
+  and a.deleted_timestamp is null
+  and o.deleted_timestamp is null
 `
 
+type FindAppAPIKeysByAppExtlIDParams struct {
+	AppExtlID    string
+	ApiKeyPrefix string
+}
+
 type FindAppAPIKeysByAppExtlIDRow struct {
-	AppID          uuid.UUID
-	AppExtlID      string
-	AppName        string
-	AppDescription string
-	OrgID          uuid.UUID
-	OrgExtlID      string
-	OrgName        string
-	OrgDescription string
-	ApiKey         string
-	DeactvDate     time.Time
+	AppID             uuid.UUID
+	AppExtlID         string
+	AppName           string
+	AppDescription    string
+	OrgID             uuid.UUID
+	OrgExtlID         string
+	OrgName           string
+	OrgDescription    string
+	ApiKey            string
+	ApiKeyPrefix      string
+	DeactvDate        time.Time
+	LastUsedTimestamp sql.NullTime
 }
 
-func (q *Queries) FindAppAPIKeysByAppExtlID(ctx context.Context, appExtlID string) ([]FindAppAPIKeysByAppExtlIDRow, error) {
-	rows, err := q.db.Query(ctx, findAppAPIKeysByAppExtlID, appExtlID)
+func (q *Queries) FindAppAPIKeysByAppExtlID(ctx context.Context, arg FindAppAPIKeysByAppExtlIDParams) ([]FindAppAPIKeysByAppExtlIDRow, error) {
+	rows, err := q.db.Query(ctx, findAppAPIKeysByAppExtlID, arg.AppExtlID, arg.ApiKeyPrefix)
 	if err != nil {
 		return nil, err
 	}
@@ -218,7 +234,9 @@ func (q *Queries) FindAppAPIKeysByAppExtlID(ctx context.Context, appExtlID strin
 			&i.OrgName,
 			&i.OrgDescription,
 			&i.ApiKey,
+			&i.ApiKeyPrefix,
 			&i.DeactvDate,
+			&i.LastUsedTimestamp,
 		); err != nil {
 			return nil, err
 		}
@@ -246,6 +264,7 @@ FROM app a
          INNER JOIN org o on o.org_id = a.org_id
          INNER JOIN org_kind ok on ok.org_kind_id = o.org_kind_id
 WHERE a.app_extl_id = $1
+  AND a.deleted_timestamp IS NULL
 `
 
 type FindAppByExternalIDRow struct {
@@ -293,6 +312,8 @@ SELECT a.org_id,
        a.app_extl_id,
        a.app_name,
        a.app_description,
+       a.auth_provider_id,
+       a.auth_provider_client_id,
        a.create_app_id,
        ca.org_id          create_app_org_id,
        ca.app_extl_id     create_app_extl_id,
@@ -310,7 +331,8 @@ SELECT a.org_id,
        a.update_user_id,
        uu.first_name     update_user_first_name,
        uu.last_name      update_user_last_name,
-       a.update_timestamp
+       a.update_timestamp,
+       a.deleted_timestamp
 FROM app a
          INNER JOIN org o on o.org_id = a.org_id
          INNER JOIN org_kind ok on ok.org_kind_id = o.org_kind_id
@@ -319,8 +341,14 @@ FROM app a
          LEFT JOIN users cu on cu.user_id = a.create_user_id
          LEFT JOIN users uu on uu.user_id = a.update_user_id
 WHERE a.app_extl_id = $1
+  AND ($2::boolean OR a.deleted_timestamp IS NULL)
 `
 
+type FindAppByExternalIDWithAuditParams struct {
+	AppExtlID      string
+	IncludeDeleted bool
+}
+
 type FindAppByExternalIDWithAuditRow struct {
 	OrgID                uuid.UUID
 	OrgExtlID            string
@@ -333,6 +361,8 @@ type FindAppByExternalIDWithAuditRow struct {
 	AppExtlID            string
 	AppName              string
 	AppDescription       string
+	AuthProviderID       sql.NullInt32
+	AuthProviderClientID sql.NullString
 	CreateAppID          uuid.UUID
 	CreateAppOrgID       uuid.UUID
 	CreateAppExtlID      string
@@ -351,10 +381,11 @@ type FindAppByExternalIDWithAuditRow struct {
 	UpdateUserFirstName  sql.NullString
 	UpdateUserLastName   sql.NullString
 	UpdateTimestamp      time.Time
+	DeletedTimestamp     sql.NullTime
 }
 
-func (q *Queries) FindAppByExternalIDWithAudit(ctx context.Context, appExtlID string) (FindAppByExternalIDWithAuditRow, error) {
-	row := q.db.QueryRow(ctx, findAppByExternalIDWithAudit, appExtlID)
+func (q *Queries) FindAppByExternalIDWithAudit(ctx context.Context, arg FindAppByExternalIDWithAuditParams) (FindAppByExternalIDWithAuditRow, error) {
+	row := q.db.QueryRow(ctx, findAppByExternalIDWithAudit, arg.AppExtlID, arg.IncludeDeleted)
 	var i FindAppByExternalIDWithAuditRow
 	err := row.Scan(
 		&i.OrgID,
@@ -368,6 +399,8 @@ func (q *Queries) FindAppByExternalIDWithAudit(ctx context.Context, appExtlID st
 		&i.AppExtlID,
 		&i.AppName,
 		&i.AppDescription,
+		&i.AuthProviderID,
+		&i.AuthProviderClientID,
 		&i.CreateAppID,
 		&i.CreateAppOrgID,
 		&i.CreateAppExtlID,
@@ -386,6 +419,7 @@ func (q *Queries) FindAppByExternalIDWithAudit(ctx context.Context, appExtlID st
 		&i.UpdateUserFirstName,
 		&i.UpdateUserLastName,
 		&i.UpdateTimestamp,
+		&i.DeletedTimestamp,
 	)
 	return i, err
 }
@@ -567,6 +601,7 @@ FROM app a
          INNER JOIN org_kind ok on ok.org_kind_id = o.org_kind_id
 WHERE o.org_id = $1
   AND a.app_name = $2
+  AND a.deleted_timestamp IS NULL
 `
 
 type FindAppByNameParams struct {
@@ -623,6 +658,7 @@ FROM app a
          INNER JOIN org o on o.org_id = a.org_id
          INNER JOIN org_kind ok on ok.org_kind_id = o.org_kind_id
 WHERE a.auth_provider_client_id = $1
+  AND a.deleted_timestamp IS NULL
 `
 
 type FindAppByProviderClientIDRow struct {
@@ -659,7 +695,7 @@ func (q *Queries) FindAppByProviderClientID(ctx context.Context, authProviderCli
 }
 
 const findApps = `-- name: FindApps :many
-SELECT app_id, app_extl_id, org_id, app_name, app_description, auth_provider_id, auth_provider_client_id, create_app_id, create_user_id, create_timestamp, update_app_id, update_user_id, update_timestamp FROM app
+SELECT app_id, app_extl_id, org_id, app_name, app_description, auth_provider_id, auth_provider_client_id, create_app_id, create_user_id, create_timestamp, update_app_id, update_user_id, update_timestamp, deleted_app_id, deleted_user_id, deleted_timestamp FROM app
 ORDER BY app_name
 `
 
@@ -686,6 +722,9 @@ func (q *Queries) FindApps(ctx context.Context) ([]App, error) {
 			&i.UpdateAppID,
 			&i.UpdateUserID,
 			&i.UpdateTimestamp,
+			&i.DeletedAppID,
+			&i.DeletedUserID,
+			&i.DeletedTimestamp,
 		); err != nil {
 			return nil, err
 		}
@@ -698,7 +737,7 @@ func (q *Queries) FindApps(ctx context.Context) ([]App, error) {
 }
 
 const findAppsByOrg = `-- name: FindAppsByOrg :many
-SELECT app_id, app_extl_id, org_id, app_name, app_description, auth_provider_id, auth_provider_client_id, create_app_id, create_user_id, create_timestamp, update_app_id, update_user_id, update_timestamp FROM app
+SELECT app_id, app_extl_id, org_id, app_name, app_description, auth_provider_id, auth_provider_client_id, create_app_id, create_user_id, create_timestamp, update_app_id, update_user_id, update_timestamp, deleted_app_id, deleted_user_id, deleted_timestamp FROM app
 WHERE org_id = $1
 `
 
@@ -725,6 +764,9 @@ func (q *Queries) FindAppsByOrg(ctx context.Context, orgID uuid.UUID) ([]App, er
 			&i.UpdateAppID,
 			&i.UpdateUserID,
 			&i.UpdateTimestamp,
+			&i.DeletedAppID,
+			&i.DeletedUserID,
+			&i.DeletedTimestamp,
 		); err != nil {
 			return nil, err
 		}
@@ -857,29 +899,433 @@ func (q *Queries) FindAppsWithAudit(ctx context.Context) ([]FindAppsWithAuditRow
 	return items, nil
 }
 
-const updateApp = `-- name: UpdateApp :execrows
+const findAppsWithAuditByOrg = `-- name: FindAppsWithAuditByOrg :many
+SELECT a.org_id,
+       o.org_extl_id,
+       o.org_name,
+       o.org_description,
+       ok.org_kind_id,
+       ok.org_kind_extl_id,
+       ok.org_kind_desc,
+       a.app_id,
+       a.app_extl_id,
+       a.app_name,
+       a.app_description,
+       a.auth_provider_id,
+       a.auth_provider_client_id,
+       a.create_app_id,
+       ca.org_id          create_app_org_id,
+       ca.app_extl_id     create_app_extl_id,
+       ca.app_name        create_app_name,
+       ca.app_description create_app_description,
+       a.create_user_id,
+       cu.first_name     create_user_first_name,
+       cu.last_name      create_user_last_name,
+       a.create_timestamp,
+       a.update_app_id,
+       ua.org_id          update_app_org_id,
+       ua.app_extl_id     update_app_extl_id,
+       ua.app_name        update_app_name,
+       ua.app_description update_app_description,
+       a.update_user_id,
+       uu.first_name     update_user_first_name,
+       uu.last_name      update_user_last_name,
+       a.update_timestamp,
+       a.deleted_timestamp
+FROM app a
+         INNER JOIN org o on o.org_id = a.org_id
+         INNER JOIN org_kind ok on ok.org_kind_id = o.org_kind_id
+         INNER JOIN app ca on ca.app_id = a.create_app_id
+         INNER JOIN app ua on ua.app_id = a.update_app_id
+         LEFT JOIN users cu on cu.user_id = a.create_user_id
+         LEFT JOIN users uu on uu.user_id = a.update_user_id
+WHERE a.org_id = $1
+  AND ($2::boolean OR a.deleted_timestamp IS NULL)
+ORDER BY a.app_name
+`
+
+type FindAppsWithAuditByOrgParams struct {
+	OrgID          uuid.UUID
+	IncludeDeleted bool
+}
+
+type FindAppsWithAuditByOrgRow struct {
+	OrgID                uuid.UUID
+	OrgExtlID            string
+	OrgName              string
+	OrgDescription       string
+	OrgKindID            uuid.UUID
+	OrgKindExtlID        string
+	OrgKindDesc          string
+	AppID                uuid.UUID
+	AppExtlID            string
+	AppName              string
+	AppDescription       string
+	AuthProviderID       sql.NullInt32
+	AuthProviderClientID sql.NullString
+	CreateAppID          uuid.UUID
+	CreateAppOrgID       uuid.UUID
+	CreateAppExtlID      string
+	CreateAppName        string
+	CreateAppDescription string
+	CreateUserID         uuid.NullUUID
+	CreateUserFirstName  sql.NullString
+	CreateUserLastName   sql.NullString
+	CreateTimestamp      time.Time
+	UpdateAppID          uuid.UUID
+	UpdateAppOrgID       uuid.UUID
+	UpdateAppExtlID      string
+	UpdateAppName        string
+	UpdateAppDescription string
+	UpdateUserID         uuid.NullUUID
+	UpdateUserFirstName  sql.NullString
+	UpdateUserLastName   sql.NullString
+	UpdateTimestamp      time.Time
+	DeletedTimestamp     sql.NullTime
+}
+
+func (q *Queries) FindAppsWithAuditByOrg(ctx context.Context, arg FindAppsWithAuditByOrgParams) ([]FindAppsWithAuditByOrgRow, error) {
+	rows, err := q.db.Query(ctx, findAppsWithAuditByOrg, arg.OrgID, arg.IncludeDeleted)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []FindAppsWithAuditByOrgRow
+	for rows.Next() {
+		var i FindAppsWithAuditByOrgRow
+		if err := rows.Scan(
+			&i.OrgID,
+			&i.OrgExtlID,
+			&i.OrgName,
+			&i.OrgDescription,
+			&i.OrgKindID,
+			&i.OrgKindExtlID,
+			&i.OrgKindDesc,
+			&i.AppID,
+			&i.AppExtlID,
+			&i.AppName,
+			&i.AppDescription,
+			&i.AuthProviderID,
+			&i.AuthProviderClientID,
+			&i.CreateAppID,
+			&i.CreateAppOrgID,
+			&i.CreateAppExtlID,
+			&i.CreateAppName,
+			&i.CreateAppDescription,
+			&i.CreateUserID,
+			&i.CreateUserFirstName,
+			&i.CreateUserLastName,
+			&i.CreateTimestamp,
+			&i.UpdateAppID,
+			&i.UpdateAppOrgID,
+			&i.UpdateAppExtlID,
+			&i.UpdateAppName,
+			&i.UpdateAppDescription,
+			&i.UpdateUserID,
+			&i.UpdateUserFirstName,
+			&i.UpdateUserLastName,
+			&i.UpdateTimestamp,
+			&i.DeletedTimestamp,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const findDeletedApps = `-- name: FindDeletedApps :many
+SELECT app_id, app_extl_id
+FROM app
+WHERE deleted_timestamp < $1
+`
+
+type FindDeletedAppsRow struct {
+	AppID     uuid.UUID
+	AppExtlID string
+}
+
+func (q *Queries) FindDeletedApps(ctx context.Context, deletedTimestamp sql.NullTime) ([]FindDeletedAppsRow, error) {
+	rows, err := q.db.Query(ctx, findDeletedApps, deletedTimestamp)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []FindDeletedAppsRow
+	for rows.Next() {
+		var i FindDeletedAppsRow
+		if err := rows.Scan(&i.AppID, &i.AppExtlID); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const findLegacyAppAPIKeys = `-- name: FindLegacyAppAPIKeys :many
+SELECT api_key, app_id
+FROM app_api_key
+WHERE api_key_prefix IS NULL
+`
+
+type FindLegacyAppAPIKeysRow struct {
+	ApiKey string
+	AppID  uuid.UUID
+}
+
+func (q *Queries) FindLegacyAppAPIKeys(ctx context.Context) ([]FindLegacyAppAPIKeysRow, error) {
+	rows, err := q.db.Query(ctx, findLegacyAppAPIKeys)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []FindLegacyAppAPIKeysRow
+	for rows.Next() {
+		var i FindLegacyAppAPIKeysRow
+		if err := rows.Scan(&i.ApiKey, &i.AppID); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const restoreApp = `-- name: RestoreApp :execrows
 UPDATE app
-SET app_name        = $1,
-    app_description = $2,
-    update_app_id    = $3,
-    update_user_id   = $4,
-    update_timestamp = $5
-WHERE app_id = $6
+SET deleted_app_id    = NULL,
+    deleted_user_id   = NULL,
+    deleted_timestamp = NULL,
+    update_app_id     = $1,
+    update_user_id    = $2,
+    update_timestamp  = $3
+WHERE app_id = $4
+  AND deleted_timestamp IS NOT NULL
 `
 
-type UpdateAppParams struct {
-	AppName         string
-	AppDescription  string
+type RestoreAppParams struct {
 	UpdateAppID     uuid.UUID
 	UpdateUserID    uuid.NullUUID
 	UpdateTimestamp time.Time
 	AppID           uuid.UUID
 }
 
+func (q *Queries) RestoreApp(ctx context.Context, arg RestoreAppParams) (int64, error) {
+	result, err := q.db.Exec(ctx, restoreApp,
+		arg.UpdateAppID,
+		arg.UpdateUserID,
+		arg.UpdateTimestamp,
+		arg.AppID,
+	)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
+
+const restoreAppsByOrg = `-- name: RestoreAppsByOrg :execrows
+UPDATE app
+SET deleted_app_id    = NULL,
+    deleted_user_id   = NULL,
+    deleted_timestamp = NULL,
+    update_app_id     = $1,
+    update_user_id    = $2,
+    update_timestamp  = $3
+WHERE org_id = $4
+  AND deleted_timestamp = $5
+`
+
+type RestoreAppsByOrgParams struct {
+	UpdateAppID      uuid.UUID
+	UpdateUserID     uuid.NullUUID
+	UpdateTimestamp  time.Time
+	OrgID            uuid.UUID
+	DeletedTimestamp sql.NullTime
+}
+
+func (q *Queries) RestoreAppsByOrg(ctx context.Context, arg RestoreAppsByOrgParams) (int64, error) {
+	result, err := q.db.Exec(ctx, restoreAppsByOrg,
+		arg.UpdateAppID,
+		arg.UpdateUserID,
+		arg.UpdateTimestamp,
+		arg.OrgID,
+		arg.DeletedTimestamp,
+	)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
+
+const softDeleteApp = `-- name: SoftDeleteApp :execrows
+UPDATE app
+SET deleted_app_id    = $1,
+    deleted_user_id   = $2,
+    deleted_timestamp = $3
+WHERE app_id = $4
+  AND deleted_timestamp IS NULL
+`
+
+type SoftDeleteAppParams struct {
+	DeletedAppID     uuid.NullUUID
+	DeletedUserID    uuid.NullUUID
+	DeletedTimestamp sql.NullTime
+	AppID            uuid.UUID
+}
+
+func (q *Queries) SoftDeleteApp(ctx context.Context, arg SoftDeleteAppParams) (int64, error) {
+	result, err := q.db.Exec(ctx, softDeleteApp,
+		arg.DeletedAppID,
+		arg.DeletedUserID,
+		arg.DeletedTimestamp,
+		arg.AppID,
+	)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
+
+const softDeleteAppsByOrg = `-- name: SoftDeleteAppsByOrg :execrows
+UPDATE app
+SET deleted_app_id    = $1,
+    deleted_user_id   = $2,
+    deleted_timestamp = $3
+WHERE org_id = $4
+  AND deleted_timestamp IS NULL
+`
+
+type SoftDeleteAppsByOrgParams struct {
+	DeletedAppID     uuid.NullUUID
+	DeletedUserID    uuid.NullUUID
+	DeletedTimestamp sql.NullTime
+	OrgID            uuid.UUID
+}
+
+func (q *Queries) SoftDeleteAppsByOrg(ctx context.Context, arg SoftDeleteAppsByOrgParams) (int64, error) {
+	result, err := q.db.Exec(ctx, softDeleteAppsByOrg,
+		arg.DeletedAppID,
+		arg.DeletedUserID,
+		arg.DeletedTimestamp,
+		arg.OrgID,
+	)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
+
+const updateApp = `-- name: UpdateApp :execrows
+UPDATE app
+SET app_name                = $1,
+    app_description         = $2,
+    auth_provider_id        = $3,
+    auth_provider_client_id = $4,
+    update_app_id           = $5,
+    update_user_id          = $6,
+    update_timestamp        = $7
+WHERE app_id = $8
+`
+
+type UpdateAppParams struct {
+	AppName              string
+	AppDescription       string
+	AuthProviderID       sql.NullInt32
+	AuthProviderClientID sql.NullString
+	UpdateAppID          uuid.UUID
+	UpdateUserID         uuid.NullUUID
+	UpdateTimestamp      time.Time
+	AppID                uuid.UUID
+}
+
 func (q *Queries) UpdateApp(ctx context.Context, arg UpdateAppParams) (int64, error) {
 	result, err := q.db.Exec(ctx, updateApp,
 		arg.AppName,
 		arg.AppDescription,
+		arg.AuthProviderID,
+		arg.AuthProviderClientID,
+		arg.UpdateAppID,
+		arg.UpdateUserID,
+		arg.UpdateTimestamp,
+		arg.AppID,
+	)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
+
+const updateAppAPIKeyHash = `-- name: UpdateAppAPIKeyHash :execrows
+UPDATE app_api_key
+SET api_key        = $1,
+    api_key_prefix = $2
+WHERE api_key = $3
+`
+
+type UpdateAppAPIKeyHashParams struct {
+	ApiKeyHash   string
+	ApiKeyPrefix string
+	LegacyApiKey string
+}
+
+func (q *Queries) UpdateAppAPIKeyHash(ctx context.Context, arg UpdateAppAPIKeyHashParams) (int64, error) {
+	result, err := q.db.Exec(ctx, updateAppAPIKeyHash, arg.ApiKeyHash, arg.ApiKeyPrefix, arg.LegacyApiKey)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
+
+const updateAppAPIKeyLastUsed = `-- name: UpdateAppAPIKeyLastUsed :execrows
+UPDATE app_api_key
+SET last_used_timestamp = $1
+WHERE api_key = $2
+  AND (last_used_timestamp IS NULL OR last_used_timestamp < $3)
+`
+
+type UpdateAppAPIKeyLastUsedParams struct {
+	LastUsedTimestamp sql.NullTime
+	ApiKey            string
+	LastUsedBefore    sql.NullTime
+}
+
+func (q *Queries) UpdateAppAPIKeyLastUsed(ctx context.Context, arg UpdateAppAPIKeyLastUsedParams) (int64, error) {
+	result, err := q.db.Exec(ctx, updateAppAPIKeyLastUsed, arg.LastUsedTimestamp, arg.ApiKey, arg.LastUsedBefore)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
+
+const updateAppAPIKeysDeactvDate = `-- name: UpdateAppAPIKeysDeactvDate :execrows
+UPDATE app_api_key
+SET deactv_date      = $1,
+    update_app_id    = $2,
+    update_user_id   = $3,
+    update_timestamp = $4
+WHERE app_id = $5
+  AND deactv_date > $1
+`
+
+type UpdateAppAPIKeysDeactvDateParams struct {
+	DeactvDate      time.Time
+	UpdateAppID     uuid.UUID
+	UpdateUserID    uuid.NullUUID
+	UpdateTimestamp time.Time
+	AppID           uuid.UUID
+}
+
+func (q *Queries) UpdateAppAPIKeysDeactvDate(ctx context.Context, arg UpdateAppAPIKeysDeactvDateParams) (int64, error) {
+	result, err := q.db.Exec(ctx, updateAppAPIKeysDeactvDate,
+		arg.DeactvDate,
 		arg.UpdateAppID,
 		arg.UpdateUserID,
 		arg.UpdateTimestamp,
@@ -13,6 +13,32 @@ import (
 	"github.com/google/uuid"
 )
 
+const countRoleChildren = `-- name: CountRoleChildren :one
+SELECT count(*)
+FROM role_parent
+WHERE parent_role_id = $1
+`
+
+func (q *Queries) CountRoleChildren(ctx context.Context, parentRoleID uuid.UUID) (int64, error) {
+	row := q.db.QueryRow(ctx, countRoleChildren, parentRoleID)
+	var count int64
+	err := row.Scan(&count)
+	return count, err
+}
+
+const countUsersRolesByRoleID = `-- name: CountUsersRolesByRoleID :one
+SELECT count(*)
+FROM users_role
+WHERE role_id = $1
+`
+
+func (q *Queries) CountUsersRolesByRoleID(ctx context.Context, roleID uuid.UUID) (int64, error) {
+	row := q.db.QueryRow(ctx, countUsersRolesByRoleID, roleID)
+	var count int64
+	err := row.Scan(&count)
+	return count, err
+}
+
 const createAuth = `-- name: CreateAuth :execrows
 INSERT INTO auth (auth_id, user_id, auth_provider_id, auth_provider_cd, auth_provider_client_id,
                   auth_provider_person_id,
@@ -99,6 +125,44 @@ func (q *Queries) CreateAuthProvider(ctx context.Context, arg CreateAuthProvider
 	return result.RowsAffected(), nil
 }
 
+const createAuthzDecision = `-- name: CreateAuthzDecision :execrows
+INSERT INTO authz_decision (authz_decision_id, user_id, app_id, org_id, resource, operation, decision, role_cd,
+                            request_id, decision_timestamp)
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)
+`
+
+type CreateAuthzDecisionParams struct {
+	AuthzDecisionID   uuid.UUID
+	UserID            uuid.UUID
+	AppID             uuid.UUID
+	OrgID             uuid.UUID
+	Resource          string
+	Operation         string
+	Decision          string
+	RoleCd            sql.NullString
+	RequestID         sql.NullString
+	DecisionTimestamp time.Time
+}
+
+func (q *Queries) CreateAuthzDecision(ctx context.Context, arg CreateAuthzDecisionParams) (int64, error) {
+	result, err := q.db.Exec(ctx, createAuthzDecision,
+		arg.AuthzDecisionID,
+		arg.UserID,
+		arg.AppID,
+		arg.OrgID,
+		arg.Resource,
+		arg.Operation,
+		arg.Decision,
+		arg.RoleCd,
+		arg.RequestID,
+		arg.DecisionTimestamp,
+	)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
+
 const createPermission = `-- name: CreatePermission :execrows
 insert into permission (permission_id, permission_extl_id, resource, operation, permission_description, active,
                         create_app_id,
@@ -182,15 +246,50 @@ func (q *Queries) CreateRole(ctx context.Context, arg CreateRoleParams) (int64,
 	return result.RowsAffected(), nil
 }
 
-const createRolePermission = `-- name: CreateRolePermission :execrows
-insert into role_permission (role_id, permission_id, create_app_id, create_user_id, create_timestamp, update_app_id,
-                             update_user_id, update_timestamp)
+const createRoleParent = `-- name: CreateRoleParent :execrows
+insert into role_parent (role_id, parent_role_id, create_app_id, create_user_id, create_timestamp, update_app_id,
+                         update_user_id, update_timestamp)
 VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
 `
 
+type CreateRoleParentParams struct {
+	RoleID          uuid.UUID
+	ParentRoleID    uuid.UUID
+	CreateAppID     uuid.UUID
+	CreateUserID    uuid.NullUUID
+	CreateTimestamp time.Time
+	UpdateAppID     uuid.UUID
+	UpdateUserID    uuid.NullUUID
+	UpdateTimestamp time.Time
+}
+
+func (q *Queries) CreateRoleParent(ctx context.Context, arg CreateRoleParentParams) (int64, error) {
+	result, err := q.db.Exec(ctx, createRoleParent,
+		arg.RoleID,
+		arg.ParentRoleID,
+		arg.CreateAppID,
+		arg.CreateUserID,
+		arg.CreateTimestamp,
+		arg.UpdateAppID,
+		arg.UpdateUserID,
+		arg.UpdateTimestamp,
+	)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
+
+const createRolePermission = `-- name: CreateRolePermission :execrows
+insert into role_permission (role_id, permission_id, effect, create_app_id, create_user_id, create_timestamp,
+                             update_app_id, update_user_id, update_timestamp)
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
+`
+
 type CreateRolePermissionParams struct {
 	RoleID          uuid.UUID
 	PermissionID    uuid.UUID
+	Effect          string
 	CreateAppID     uuid.UUID
 	CreateUserID    uuid.NullUUID
 	CreateTimestamp time.Time
@@ -203,6 +302,7 @@ func (q *Queries) CreateRolePermission(ctx context.Context, arg CreateRolePermis
 	result, err := q.db.Exec(ctx, createRolePermission,
 		arg.RoleID,
 		arg.PermissionID,
+		arg.Effect,
 		arg.CreateAppID,
 		arg.CreateUserID,
 		arg.CreateTimestamp,
@@ -217,15 +317,17 @@ func (q *Queries) CreateRolePermission(ctx context.Context, arg CreateRolePermis
 }
 
 const createUsersRole = `-- name: CreateUsersRole :execrows
-insert into users_role (user_id, role_id, org_id, create_app_id, create_user_id, create_timestamp, update_app_id,
-                        update_user_id, update_timestamp)
-VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
+insert into users_role (user_id, role_id, org_id, valid_from, valid_until, create_app_id, create_user_id,
+                        create_timestamp, update_app_id, update_user_id, update_timestamp)
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11)
 `
 
 type CreateUsersRoleParams struct {
 	UserID          uuid.UUID
 	RoleID          uuid.UUID
 	OrgID           uuid.UUID
+	ValidFrom       sql.NullTime
+	ValidUntil      sql.NullTime
 	CreateAppID     uuid.UUID
 	CreateUserID    uuid.NullUUID
 	CreateTimestamp time.Time
@@ -239,6 +341,8 @@ func (q *Queries) CreateUsersRole(ctx context.Context, arg CreateUsersRoleParams
 		arg.UserID,
 		arg.RoleID,
 		arg.OrgID,
+		arg.ValidFrom,
+		arg.ValidUntil,
 		arg.CreateAppID,
 		arg.CreateUserID,
 		arg.CreateTimestamp,
@@ -252,6 +356,46 @@ func (q *Queries) CreateUsersRole(ctx context.Context, arg CreateUsersRoleParams
 	return result.RowsAffected(), nil
 }
 
+const deleteAuthzDecisions = `-- name: DeleteAuthzDecisions :execrows
+DELETE
+FROM authz_decision
+WHERE decision_timestamp < $1
+`
+
+func (q *Queries) DeleteAuthzDecisions(ctx context.Context, decisionTimestamp time.Time) (int64, error) {
+	result, err := q.db.Exec(ctx, deleteAuthzDecisions, decisionTimestamp)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
+
+const deleteExpiredUsersRoles = `-- name: DeleteExpiredUsersRoles :execrows
+DELETE FROM users_role
+WHERE valid_until <= now()
+`
+
+func (q *Queries) DeleteExpiredUsersRoles(ctx context.Context) (int64, error) {
+	result, err := q.db.Exec(ctx, deleteExpiredUsersRoles)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
+
+const deleteAllParents4Role = `-- name: DeleteAllParents4Role :execrows
+DELETE FROM role_parent
+WHERE role_id = $1
+`
+
+func (q *Queries) DeleteAllParents4Role(ctx context.Context, roleID uuid.UUID) (int64, error) {
+	result, err := q.db.Exec(ctx, deleteAllParents4Role, roleID)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
+
 const deleteAllPermissions4Role = `-- name: DeleteAllPermissions4Role :execrows
 DELETE FROM role_permission
 WHERE role_id = $1
@@ -278,6 +422,122 @@ func (q *Queries) DeletePermissionByExternalID(ctx context.Context, permissionEx
 	return result.RowsAffected(), nil
 }
 
+const deleteRole = `-- name: DeleteRole :execrows
+DELETE FROM role
+WHERE role_id = $1
+`
+
+func (q *Queries) DeleteRole(ctx context.Context, roleID uuid.UUID) (int64, error) {
+	result, err := q.db.Exec(ctx, deleteRole, roleID)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
+
+const deleteRoleParent = `-- name: DeleteRoleParent :execrows
+DELETE FROM role_parent
+WHERE role_id = $1
+  AND parent_role_id = $2
+`
+
+type DeleteRoleParentParams struct {
+	RoleID       uuid.UUID
+	ParentRoleID uuid.UUID
+}
+
+func (q *Queries) DeleteRoleParent(ctx context.Context, arg DeleteRoleParentParams) (int64, error) {
+	result, err := q.db.Exec(ctx, deleteRoleParent, arg.RoleID, arg.ParentRoleID)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
+
+const deleteUsersRole = `-- name: DeleteUsersRole :execrows
+DELETE FROM users_role
+WHERE user_id = $1
+  AND role_id = $2
+  AND org_id = $3
+`
+
+type DeleteUsersRoleParams struct {
+	UserID uuid.UUID
+	RoleID uuid.UUID
+	OrgID  uuid.UUID
+}
+
+func (q *Queries) DeleteUsersRole(ctx context.Context, arg DeleteUsersRoleParams) (int64, error) {
+	result, err := q.db.Exec(ctx, deleteUsersRole, arg.UserID, arg.RoleID, arg.OrgID)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
+
+const deleteUsersRolesByOrg = `-- name: DeleteUsersRolesByOrg :execrows
+DELETE FROM users_role
+WHERE org_id = $1
+`
+
+func (q *Queries) DeleteUsersRolesByOrg(ctx context.Context, orgID uuid.UUID) (int64, error) {
+	result, err := q.db.Exec(ctx, deleteUsersRolesByOrg, orgID)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
+
+const deleteUsersRolesByOrgUser = `-- name: DeleteUsersRolesByOrgUser :execrows
+DELETE FROM users_role
+WHERE org_id = $1
+  AND user_id = $2
+`
+
+type DeleteUsersRolesByOrgUserParams struct {
+	OrgID  uuid.UUID
+	UserID uuid.UUID
+}
+
+func (q *Queries) DeleteUsersRolesByOrgUser(ctx context.Context, arg DeleteUsersRolesByOrgUserParams) (int64, error) {
+	result, err := q.db.Exec(ctx, deleteUsersRolesByOrgUser, arg.OrgID, arg.UserID)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
+
+const expireAuthsByUserID = `-- name: ExpireAuthsByUserID :execrows
+UPDATE auth
+SET auth_provider_access_token_expiry = $2,
+    update_app_id                     = $3,
+    update_user_id                    = $4,
+    update_timestamp                  = $5
+WHERE user_id = $1
+`
+
+type ExpireAuthsByUserIDParams struct {
+	UserID                        uuid.UUID
+	AuthProviderAccessTokenExpiry sql.NullTime
+	UpdateAppID                   uuid.UUID
+	UpdateUserID                  uuid.NullUUID
+	UpdateTimestamp               time.Time
+}
+
+func (q *Queries) ExpireAuthsByUserID(ctx context.Context, arg ExpireAuthsByUserIDParams) (int64, error) {
+	result, err := q.db.Exec(ctx, expireAuthsByUserID,
+		arg.UserID,
+		arg.AuthProviderAccessTokenExpiry,
+		arg.UpdateAppID,
+		arg.UpdateUserID,
+		arg.UpdateTimestamp,
+	)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
+
 const findAllPermissions = `-- name: FindAllPermissions :many
 select permission_id, permission_extl_id, resource, operation, permission_description, active, create_app_id, create_user_id, create_timestamp, update_app_id, update_user_id, update_timestamp
 from permission
@@ -316,6 +576,44 @@ func (q *Queries) FindAllPermissions(ctx context.Context) ([]Permission, error)
 	return items, nil
 }
 
+const findAllRoles = `-- name: FindAllRoles :many
+SELECT role_id, role_extl_id, role_cd, role_description, active, create_app_id, create_user_id, create_timestamp, update_app_id, update_user_id, update_timestamp
+FROM role
+ORDER BY role_cd
+`
+
+func (q *Queries) FindAllRoles(ctx context.Context) ([]Role, error) {
+	rows, err := q.db.Query(ctx, findAllRoles)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []Role
+	for rows.Next() {
+		var i Role
+		if err := rows.Scan(
+			&i.RoleID,
+			&i.RoleExtlID,
+			&i.RoleCd,
+			&i.RoleDescription,
+			&i.Active,
+			&i.CreateAppID,
+			&i.CreateUserID,
+			&i.CreateTimestamp,
+			&i.UpdateAppID,
+			&i.UpdateUserID,
+			&i.UpdateTimestamp,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
 const findAuthByAccessToken = `-- name: FindAuthByAccessToken :one
 SELECT auth_id, user_id, auth_provider_id, auth_provider_cd, auth_provider_client_id, auth_provider_person_id, auth_provider_access_token, auth_provider_refresh_token, auth_provider_access_token_expiry, create_app_id, create_user_id, create_timestamp, update_app_id, update_user_id, update_timestamp
 FROM auth
@@ -380,6 +678,212 @@ func (q *Queries) FindAuthByProviderUserID(ctx context.Context, arg FindAuthByPr
 	return i, err
 }
 
+const findAuthzDecisions = `-- name: FindAuthzDecisions :many
+SELECT d.authz_decision_id,
+       u.user_extl_id,
+       a.app_extl_id,
+       o.org_extl_id,
+       d.resource,
+       d.operation,
+       d.decision,
+       d.role_cd,
+       d.request_id,
+       d.decision_timestamp
+FROM authz_decision d
+         LEFT JOIN users u on u.user_id = d.user_id
+         LEFT JOIN app a on a.app_id = d.app_id
+         LEFT JOIN org o on o.org_id = d.org_id
+WHERE ($1::uuid IS NULL OR d.user_id = $1)
+  AND ($2::varchar IS NULL OR d.decision = $2)
+  AND d.decision_timestamp >= $3
+  AND d.decision_timestamp < $4
+  AND d.org_id = $5
+ORDER BY d.decision_timestamp DESC
+LIMIT $6
+`
+
+type FindAuthzDecisionsParams struct {
+	UserID        uuid.NullUUID
+	Decision      sql.NullString
+	DecisionFrom  time.Time
+	DecisionUntil time.Time
+	OrgID         uuid.UUID
+	RowLimit      int32
+}
+
+type FindAuthzDecisionsRow struct {
+	AuthzDecisionID   uuid.UUID
+	UserExtlID        sql.NullString
+	AppExtlID         sql.NullString
+	OrgExtlID         sql.NullString
+	Resource          string
+	Operation         string
+	Decision          string
+	RoleCd            sql.NullString
+	RequestID         sql.NullString
+	DecisionTimestamp time.Time
+}
+
+func (q *Queries) FindAuthzDecisions(ctx context.Context, arg FindAuthzDecisionsParams) ([]FindAuthzDecisionsRow, error) {
+	rows, err := q.db.Query(ctx, findAuthzDecisions,
+		arg.UserID,
+		arg.Decision,
+		arg.DecisionFrom,
+		arg.DecisionUntil,
+		arg.OrgID,
+		arg.RowLimit,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []FindAuthzDecisionsRow
+	for rows.Next() {
+		var i FindAuthzDecisionsRow
+		if err := rows.Scan(
+			&i.AuthzDecisionID,
+			&i.UserExtlID,
+			&i.AppExtlID,
+			&i.OrgExtlID,
+			&i.Resource,
+			&i.Operation,
+			&i.Decision,
+			&i.RoleCd,
+			&i.RequestID,
+			&i.DecisionTimestamp,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const findEffectivePermissions = `-- name: FindEffectivePermissions :many
+WITH RECURSIVE org_lineage AS (
+    SELECT o.org_id, o.parent_org_id
+    FROM org o
+    WHERE o.org_id = $2
+    UNION
+    SELECT po.org_id, po.parent_org_id
+    FROM org po
+             INNER JOIN org_lineage l on l.parent_org_id = po.org_id
+),
+               user_roles AS (
+    SELECT ur.role_id
+    FROM users_role ur
+             INNER JOIN role r on r.role_id = ur.role_id
+    WHERE r.active = true
+      AND ur.user_id = $1
+      AND ur.org_id IN (SELECT org_id FROM org_lineage)
+      AND (ur.valid_from IS NULL OR ur.valid_from <= now())
+      AND (ur.valid_until IS NULL OR ur.valid_until > now())
+    UNION
+    SELECT rp.parent_role_id
+    FROM role_parent rp
+             INNER JOIN user_roles u on u.role_id = rp.role_id
+             INNER JOIN role r on r.role_id = rp.parent_role_id
+    WHERE r.active = true
+)
+SELECT DISTINCT ON (p.resource, p.operation) p.resource, p.operation, r.role_cd, rp.effect
+FROM user_roles u
+         INNER JOIN role r on r.role_id = u.role_id
+         INNER JOIN role_permission rp on rp.role_id = u.role_id
+         INNER JOIN permission p on p.permission_id = rp.permission_id
+WHERE p.active = true
+ORDER BY p.resource, p.operation, rp.effect = 'deny' DESC, r.role_cd
+`
+
+type FindEffectivePermissionsParams struct {
+	UserID uuid.UUID
+	OrgID  uuid.UUID
+}
+
+type FindEffectivePermissionsRow struct {
+	Resource  string
+	Operation string
+	RoleCd    string
+	Effect    string
+}
+
+func (q *Queries) FindEffectivePermissions(ctx context.Context, arg FindEffectivePermissionsParams) ([]FindEffectivePermissionsRow, error) {
+	rows, err := q.db.Query(ctx, findEffectivePermissions, arg.UserID, arg.OrgID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []FindEffectivePermissionsRow
+	for rows.Next() {
+		var i FindEffectivePermissionsRow
+		if err := rows.Scan(
+			&i.Resource,
+			&i.Operation,
+			&i.RoleCd,
+			&i.Effect,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const findExpiredUsersRoles = `-- name: FindExpiredUsersRoles :many
+SELECT o.org_extl_id, u.user_extl_id, u.email, u.first_name, u.last_name, r.role_cd, ur.valid_from, ur.valid_until
+FROM users_role ur
+         INNER JOIN org o on o.org_id = ur.org_id
+         INNER JOIN users u on u.user_id = ur.user_id
+         INNER JOIN role r on r.role_id = ur.role_id
+WHERE ur.valid_until <= now()
+ORDER BY o.org_extl_id, r.role_cd, ur.valid_until
+`
+
+type FindExpiredUsersRolesRow struct {
+	OrgExtlID  string
+	UserExtlID string
+	Email      sql.NullString
+	FirstName  string
+	LastName   string
+	RoleCd     string
+	ValidFrom  sql.NullTime
+	ValidUntil sql.NullTime
+}
+
+func (q *Queries) FindExpiredUsersRoles(ctx context.Context) ([]FindExpiredUsersRolesRow, error) {
+	rows, err := q.db.Query(ctx, findExpiredUsersRoles)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []FindExpiredUsersRolesRow
+	for rows.Next() {
+		var i FindExpiredUsersRolesRow
+		if err := rows.Scan(
+			&i.OrgExtlID,
+			&i.UserExtlID,
+			&i.Email,
+			&i.FirstName,
+			&i.LastName,
+			&i.RoleCd,
+			&i.ValidFrom,
+			&i.ValidUntil,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
 const findPermissionByExternalID = `-- name: FindPermissionByExternalID :one
 SELECT permission_id, permission_extl_id, resource, operation, permission_description, active, create_app_id, create_user_id, create_timestamp, update_app_id, update_user_id, update_timestamp
 FROM permission
@@ -438,6 +942,40 @@ func (q *Queries) FindPermissionByResourceOperation(ctx context.Context, arg Fin
 	return i, err
 }
 
+const findRoleAncestorIDs = `-- name: FindRoleAncestorIDs :many
+WITH RECURSIVE ancestors AS (
+    SELECT rp.parent_role_id
+    FROM role_parent rp
+    WHERE rp.role_id = $1
+    UNION
+    SELECT rp.parent_role_id
+    FROM role_parent rp
+             INNER JOIN ancestors a on a.parent_role_id = rp.role_id
+)
+SELECT parent_role_id
+FROM ancestors
+`
+
+func (q *Queries) FindRoleAncestorIDs(ctx context.Context, roleID uuid.UUID) ([]uuid.UUID, error) {
+	rows, err := q.db.Query(ctx, findRoleAncestorIDs, roleID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []uuid.UUID
+	for rows.Next() {
+		var parent_role_id uuid.UUID
+		if err := rows.Scan(&parent_role_id); err != nil {
+			return nil, err
+		}
+		items = append(items, parent_role_id)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
 const findRoleByCode = `-- name: FindRoleByCode :one
 SELECT role_id, role_extl_id, role_cd, role_description, active, create_app_id, create_user_id, create_timestamp, update_app_id, update_user_id, update_timestamp
 FROM role
@@ -463,15 +1001,86 @@ func (q *Queries) FindRoleByCode(ctx context.Context, roleCd string) (Role, erro
 	return i, err
 }
 
+const findRoleByExternalID = `-- name: FindRoleByExternalID :one
+SELECT role_id, role_extl_id, role_cd, role_description, active, create_app_id, create_user_id, create_timestamp, update_app_id, update_user_id, update_timestamp
+FROM role
+WHERE role_extl_id = $1
+`
+
+func (q *Queries) FindRoleByExternalID(ctx context.Context, roleExtlID string) (Role, error) {
+	row := q.db.QueryRow(ctx, findRoleByExternalID, roleExtlID)
+	var i Role
+	err := row.Scan(
+		&i.RoleID,
+		&i.RoleExtlID,
+		&i.RoleCd,
+		&i.RoleDescription,
+		&i.Active,
+		&i.CreateAppID,
+		&i.CreateUserID,
+		&i.CreateTimestamp,
+		&i.UpdateAppID,
+		&i.UpdateUserID,
+		&i.UpdateTimestamp,
+	)
+	return i, err
+}
+
+const findRoleParentsByRoleID = `-- name: FindRoleParentsByRoleID :many
+SELECT r.role_id, r.role_extl_id, r.role_cd, r.role_description, r.active, r.create_app_id, r.create_user_id, r.create_timestamp, r.update_app_id, r.update_user_id, r.update_timestamp
+FROM role_parent rp
+         inner join role r on r.role_id = rp.parent_role_id
+WHERE rp.role_id = $1
+ORDER BY r.role_cd
+`
+
+func (q *Queries) FindRoleParentsByRoleID(ctx context.Context, roleID uuid.UUID) ([]Role, error) {
+	rows, err := q.db.Query(ctx, findRoleParentsByRoleID, roleID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []Role
+	for rows.Next() {
+		var i Role
+		if err := rows.Scan(
+			&i.RoleID,
+			&i.RoleExtlID,
+			&i.RoleCd,
+			&i.RoleDescription,
+			&i.Active,
+			&i.CreateAppID,
+			&i.CreateUserID,
+			&i.CreateTimestamp,
+			&i.UpdateAppID,
+			&i.UpdateUserID,
+			&i.UpdateTimestamp,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
 const findRolePermissionsByRoleID = `-- name: FindRolePermissionsByRoleID :many
 SELECT p.permission_id, p.permission_extl_id, p.resource, p.operation, p.permission_description, p.active, p.create_app_id, p.create_user_id, p.create_timestamp, p.update_app_id, p.update_user_id, p.update_timestamp
 FROM role_permission r
          inner join permission p on p.permission_id = r.permission_id
 WHERE r.role_id = $1
+  AND r.effect = $2
 `
 
-func (q *Queries) FindRolePermissionsByRoleID(ctx context.Context, roleID uuid.UUID) ([]Permission, error) {
-	rows, err := q.db.Query(ctx, findRolePermissionsByRoleID, roleID)
+type FindRolePermissionsByRoleIDParams struct {
+	RoleID uuid.UUID
+	Effect string
+}
+
+func (q *Queries) FindRolePermissionsByRoleID(ctx context.Context, arg FindRolePermissionsByRoleIDParams) ([]Permission, error) {
+	rows, err := q.db.Query(ctx, findRolePermissionsByRoleID, arg.RoleID, arg.Effect)
 	if err != nil {
 		return nil, err
 	}
@@ -503,8 +1112,65 @@ func (q *Queries) FindRolePermissionsByRoleID(ctx context.Context, roleID uuid.U
 	return items, nil
 }
 
+const findUserOrgRoleCodes = `-- name: FindUserOrgRoleCodes :many
+WITH RECURSIVE org_lineage AS (
+    SELECT o.org_id, o.parent_org_id
+    FROM org o
+    WHERE o.org_id = $2
+    UNION
+    SELECT po.org_id, po.parent_org_id
+    FROM org po
+             INNER JOIN org_lineage l on l.parent_org_id = po.org_id
+),
+               user_roles AS (
+    SELECT ur.role_id
+    FROM users_role ur
+             INNER JOIN role r on r.role_id = ur.role_id
+    WHERE r.active = true
+      AND ur.user_id = $1
+      AND ur.org_id IN (SELECT org_id FROM org_lineage)
+      AND (ur.valid_from IS NULL OR ur.valid_from <= now())
+      AND (ur.valid_until IS NULL OR ur.valid_until > now())
+    UNION
+    SELECT rp.parent_role_id
+    FROM role_parent rp
+             INNER JOIN user_roles u on u.role_id = rp.role_id
+             INNER JOIN role r on r.role_id = rp.parent_role_id
+    WHERE r.active = true
+)
+SELECT r.role_cd
+FROM user_roles u
+         INNER JOIN role r on r.role_id = u.role_id
+ORDER BY r.role_cd
+`
+
+type FindUserOrgRoleCodesParams struct {
+	UserID uuid.UUID
+	OrgID  uuid.UUID
+}
+
+func (q *Queries) FindUserOrgRoleCodes(ctx context.Context, arg FindUserOrgRoleCodesParams) ([]string, error) {
+	rows, err := q.db.Query(ctx, findUserOrgRoleCodes, arg.UserID, arg.OrgID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []string
+	for rows.Next() {
+		var role_cd string
+		if err := rows.Scan(&role_cd); err != nil {
+			return nil, err
+		}
+		items = append(items, role_cd)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
 const findUsersByOrgRole = `-- name: FindUsersByOrgRole :many
-SELECT user_id, role_id, org_id, create_app_id, create_user_id, create_timestamp, update_app_id, update_user_id, update_timestamp
+SELECT user_id, role_id, org_id, valid_from, valid_until, create_app_id, create_user_id, create_timestamp, update_app_id, update_user_id, update_timestamp
 FROM users_role ur
 WHERE ur.org_id = $1
   aND ur.role_id = $2
@@ -528,6 +1194,8 @@ func (q *Queries) FindUsersByOrgRole(ctx context.Context, arg FindUsersByOrgRole
 			&i.UserID,
 			&i.RoleID,
 			&i.OrgID,
+			&i.ValidFrom,
+			&i.ValidUntil,
 			&i.CreateAppID,
 			&i.CreateUserID,
 			&i.CreateTimestamp,
@@ -545,16 +1213,123 @@ func (q *Queries) FindUsersByOrgRole(ctx context.Context, arg FindUsersByOrgRole
 	return items, nil
 }
 
+const findUsersRole = `-- name: FindUsersRole :one
+SELECT user_id, role_id, org_id, valid_from, valid_until, create_app_id, create_user_id, create_timestamp, update_app_id, update_user_id, update_timestamp
+FROM users_role
+WHERE user_id = $1
+  AND role_id = $2
+  AND org_id = $3
+`
+
+type FindUsersRoleParams struct {
+	UserID uuid.UUID
+	RoleID uuid.UUID
+	OrgID  uuid.UUID
+}
+
+func (q *Queries) FindUsersRole(ctx context.Context, arg FindUsersRoleParams) (UsersRole, error) {
+	row := q.db.QueryRow(ctx, findUsersRole, arg.UserID, arg.RoleID, arg.OrgID)
+	var i UsersRole
+	err := row.Scan(
+		&i.UserID,
+		&i.RoleID,
+		&i.OrgID,
+		&i.ValidFrom,
+		&i.ValidUntil,
+		&i.CreateAppID,
+		&i.CreateUserID,
+		&i.CreateTimestamp,
+		&i.UpdateAppID,
+		&i.UpdateUserID,
+		&i.UpdateTimestamp,
+	)
+	return i, err
+}
+
+const hasAnyOrgRole = `-- name: HasAnyOrgRole :one
+WITH RECURSIVE org_lineage AS (
+    SELECT o.org_id, o.parent_org_id
+    FROM org o
+    WHERE o.org_id = $1
+    UNION
+    SELECT po.org_id, po.parent_org_id
+    FROM org po
+             INNER JOIN org_lineage l on l.parent_org_id = po.org_id
+),
+               user_roles AS (
+    SELECT ur.role_id
+    FROM users_role ur
+             INNER JOIN role r on r.role_id = ur.role_id
+    WHERE r.active = true
+      AND ur.user_id = $2
+      AND ur.org_id IN (SELECT org_id FROM org_lineage)
+      AND (ur.valid_from IS NULL OR ur.valid_from <= now())
+      AND (ur.valid_until IS NULL OR ur.valid_until > now())
+    UNION
+    SELECT rp.parent_role_id
+    FROM role_parent rp
+             INNER JOIN user_roles u on u.role_id = rp.role_id
+             INNER JOIN role r on r.role_id = rp.parent_role_id
+    WHERE r.active = true
+)
+SELECT EXISTS(SELECT 1
+              FROM user_roles u
+                       INNER JOIN role r on r.role_id = u.role_id
+              WHERE r.role_cd = ANY ($3::varchar[]))
+`
+
+type HasAnyOrgRoleParams struct {
+	OrgID   uuid.UUID
+	UserID  uuid.UUID
+	RoleCds []string
+}
+
+func (q *Queries) HasAnyOrgRole(ctx context.Context, arg HasAnyOrgRoleParams) (bool, error) {
+	row := q.db.QueryRow(ctx, hasAnyOrgRole, arg.OrgID, arg.UserID, arg.RoleCds)
+	var exists bool
+	err := row.Scan(&exists)
+	return exists, err
+}
+
 const isAuthorized = `-- name: IsAuthorized :one
-SELECT ur.user_id
-FROM users_role ur
-         INNER JOIN role_permission rp on rp.role_id = ur.role_id
+WITH RECURSIVE org_lineage AS (
+    SELECT o.org_id, o.parent_org_id
+    FROM org o
+    WHERE o.org_id = $4
+    UNION
+    SELECT po.org_id, po.parent_org_id
+    FROM org po
+             INNER JOIN org_lineage l on l.parent_org_id = po.org_id
+),
+               user_roles AS (
+    SELECT ur.user_id, ur.role_id
+    FROM users_role ur
+             INNER JOIN role r on r.role_id = ur.role_id
+    WHERE r.active = true
+      AND ur.user_id = $3
+      AND ur.org_id IN (SELECT org_id FROM org_lineage)
+      AND (ur.valid_from IS NULL OR ur.valid_from <= now())
+      AND (ur.valid_until IS NULL OR ur.valid_until > now())
+    UNION
+    SELECT u.user_id, rp.parent_role_id
+    FROM role_parent rp
+             INNER JOIN user_roles u on u.role_id = rp.role_id
+             INNER JOIN role r on r.role_id = rp.parent_role_id
+    WHERE r.active = true
+)
+SELECT u.user_id, r.role_cd, p.resource, p.operation, rp.effect
+FROM user_roles u
+         INNER JOIN role r on r.role_id = u.role_id
+         INNER JOIN role_permission rp on rp.role_id = u.role_id
          INNER JOIN permission p on p.permission_id = rp.permission_id
 WHERE p.active = true
-  AND p.resource = $1
-  AND p.operation = $2
-  AND ur.user_id = $3
-  AND ur.org_id = $4
+  AND (p.operation = $2 OR p.operation = '*')
+  AND (p.resource = $1
+    OR p.resource = '*'
+    OR (right(p.resource, 2) = '/*'
+        AND ($1 = left(p.resource, -2) OR starts_with($1, left(p.resource, -1)))))
+ORDER BY rp.effect = 'deny' DESC, strpos(p.resource, '*') = 0 DESC, length(p.resource) DESC, p.operation <> '*' DESC
+LIMIT 1
 `
 
 type IsAuthorizedParams struct {
@@ -564,14 +1339,105 @@ type IsAuthorizedParams struct {
 	OrgID     uuid.UUID
 }
 
-func (q *Queries) IsAuthorized(ctx context.Context, arg IsAuthorizedParams) (uuid.UUID, error) {
+type IsAuthorizedRow struct {
+	UserID    uuid.UUID
+	RoleCd    string
+	Resource  string
+	Operation string
+	Effect    string
+}
+
+func (q *Queries) IsAuthorized(ctx context.Context, arg IsAuthorizedParams) (IsAuthorizedRow, error) {
 	row := q.db.QueryRow(ctx, isAuthorized,
 		arg.Resource,
 		arg.Operation,
 		arg.UserID,
 		arg.OrgID,
 	)
-	var user_id uuid.UUID
-	err := row.Scan(&user_id)
-	return user_id, err
+	var i IsAuthorizedRow
+	err := row.Scan(
+		&i.UserID,
+		&i.RoleCd,
+		&i.Resource,
+		&i.Operation,
+		&i.Effect,
+	)
+	return i, err
+}
+
+const updateRole = `-- name: UpdateRole :execrows
+UPDATE role
+SET role_cd          = $1,
+    role_description = $2,
+    active           = $3,
+    update_app_id    = $4,
+    update_user_id   = $5,
+    update_timestamp = $6
+WHERE role_id = $7
+`
+
+type UpdateRoleParams struct {
+	RoleCd          string
+	RoleDescription string
+	Active          bool
+	UpdateAppID     uuid.UUID
+	UpdateUserID    uuid.NullUUID
+	UpdateTimestamp time.Time
+	RoleID          uuid.UUID
+}
+
+func (q *Queries) UpdateRole(ctx context.Context, arg UpdateRoleParams) (int64, error) {
+	result, err := q.db.Exec(ctx, updateRole,
+		arg.RoleCd,
+		arg.RoleDescription,
+		arg.Active,
+		arg.UpdateAppID,
+		arg.UpdateUserID,
+		arg.UpdateTimestamp,
+		arg.RoleID,
+	)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
+
+const updateUsersRoleValidity = `-- name: UpdateUsersRoleValidity :execrows
+UPDATE users_role
+SET valid_from       = $4,
+    valid_until      = $5,
+    update_app_id    = $6,
+    update_user_id   = $7,
+    update_timestamp = $8
+WHERE user_id = $1
+  AND role_id = $2
+  AND org_id = $3
+`
+
+type UpdateUsersRoleValidityParams struct {
+	UserID          uuid.UUID
+	RoleID          uuid.UUID
+	OrgID           uuid.UUID
+	ValidFrom       sql.NullTime
+	ValidUntil      sql.NullTime
+	UpdateAppID     uuid.UUID
+	UpdateUserID    uuid.NullUUID
+	UpdateTimestamp time.Time
+}
+
+func (q *Queries) UpdateUsersRoleValidity(ctx context.Context, arg UpdateUsersRoleValidityParams) (int64, error) {
+	result, err := q.db.Exec(ctx, updateUsersRoleValidity,
+		arg.UserID,
+		arg.RoleID,
+		arg.OrgID,
+		arg.ValidFrom,
+		arg.ValidUntil,
+		arg.UpdateAppID,
+		arg.UpdateUserID,
+		arg.UpdateTimestamp,
+	)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
 }
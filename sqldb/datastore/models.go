@@ -39,20 +39,31 @@ type App struct {
 	UpdateUserID uuid.NullUUID
 	// The timestamp when the record was updated most recently.
 	UpdateTimestamp time.Time
+	// The application which deleted this record. Null unless the record is deleted.
+	DeletedAppID uuid.NullUUID
+	// The user which deleted this record. Null unless the record is deleted.
+	DeletedUserID uuid.NullUUID
+	// The timestamp when this record was deleted. A deleted record is kept until it is purged after a retention period. Null unless the record is deleted.
+	DeletedTimestamp sql.NullTime
 }
 
 type AppApiKey struct {
-	// app_key is a hash of a key given to an person for an app
+	// api_key is a hash (HMAC-SHA256, hex encoded) of a key given to an person for an app
 	ApiKey string
+	// api_key_prefix is the first characters of the key, used to look up the key
+	ApiKeyPrefix string
 	// foreign key to app table
-	AppID           uuid.UUID
-	DeactvDate      time.Time
-	CreateAppID     uuid.UUID
-	CreateUserID    uuid.NullUUID
-	CreateTimestamp time.Time
-	UpdateAppID     uuid.UUID
-	UpdateUserID    uuid.NullUUID
-	UpdateTimestamp time.Time
+	AppID uuid.UUID
+	// the moment the key is no longer usable
+	DeactvDate time.Time
+	// the last time the key was used to authenticate
+	LastUsedTimestamp sql.NullTime
+	CreateAppID       uuid.UUID
+	CreateUserID      uuid.NullUUID
+	CreateTimestamp   time.Time
+	UpdateAppID       uuid.UUID
+	UpdateUserID      uuid.NullUUID
+	UpdateTimestamp   time.Time
 }
 
 // The auth table stores which user has authenticated through an Oauth2 provider.
@@ -110,6 +121,30 @@ type AuthProvider struct {
 	UpdateTimestamp time.Time
 }
 
+// The authz_decision table stores the authorization decisions made for requests. Decisions are kept after the user, app or org they reference is removed, so there are no foreign keys.
+type AuthzDecision struct {
+	// The unique ID for the authorization decision.
+	AuthzDecisionID uuid.UUID
+	// The user the decision was made for.
+	UserID uuid.UUID
+	// The application the request was made through.
+	AppID uuid.UUID
+	// The organization the user was acting in.
+	OrgID uuid.UUID
+	// The resource requested (e.g. an HTTP route path template).
+	Resource string
+	// The operation requested on the resource (e.g. GET, POST, etc.).
+	Operation string
+	// The decision made, either allow or deny.
+	Decision string
+	// The role whose permission allowed the request or explicitly denied it. Null when no permission matched the request.
+	RoleCd sql.NullString
+	// The ID of the request the decision was made for.
+	RequestID sql.NullString
+	// The timestamp when the decision was made.
+	DecisionTimestamp time.Time
+}
+
 // The movie table stores details about a movie.
 type Movie struct {
 	// The unique ID given to the movie.
@@ -140,6 +175,12 @@ type Movie struct {
 	UpdateUserID uuid.NullUUID
 	// The timestamp when the record was updated most recently.
 	UpdateTimestamp time.Time
+	// The application which deleted this record. Null unless the record is deleted.
+	DeletedAppID uuid.NullUUID
+	// The user which deleted this record. Null unless the record is deleted.
+	DeletedUserID uuid.NullUUID
+	// The timestamp when this record was deleted. A deleted record is kept until it is purged after a retention period. Null unless the record is deleted.
+	DeletedTimestamp sql.NullTime
 }
 
 type Org struct {
@@ -165,6 +206,52 @@ type Org struct {
 	UpdateUserID uuid.NullUUID
 	// The timestamp when the record was updated most recently.
 	UpdateTimestamp time.Time
+	// The parent organization, null for a top level organization. A role granted in an organization applies to all of its descendants.
+	ParentOrgID uuid.NullUUID
+	// The application which deleted this record. Null unless the record is deleted.
+	DeletedAppID uuid.NullUUID
+	// The user which deleted this record. Null unless the record is deleted.
+	DeletedUserID uuid.NullUUID
+	// The timestamp when this record was deleted. A deleted record is kept until it is purged after a retention period. Null unless the record is deleted.
+	DeletedTimestamp sql.NullTime
+}
+
+// The org_invitation table stores the invitations given to people (by email) to join an organization with a role. An invitation is accepted when the invited person authenticates with its token and a matching email.
+type OrgInvitation struct {
+	// The unique ID for the invitation.
+	OrgInvitationID uuid.UUID
+	// The unique external ID for the invitation.
+	OrgInvitationExtlID string
+	// The organization the person is invited to join.
+	OrgID uuid.UUID
+	// The role the person is given in the organization when the invitation is accepted.
+	RoleID uuid.UUID
+	// The email of the invited person. It must match the email given by the authentication provider when the invitation is accepted.
+	Email string
+	// The moment the invitation token expires. A new token, with a new expiry, is issued each time the invitation is sent.
+	ExpiryTimestamp time.Time
+	// The number of times the invitation has been sent.
+	SendCount int64
+	// The timestamp when the invitation was last sent.
+	LastSentTimestamp time.Time
+	// The user who accepted the invitation. Null until the invitation is accepted.
+	AcceptedUserID uuid.NullUUID
+	// The timestamp when the invitation was accepted. Null until the invitation is accepted.
+	AcceptedTimestamp sql.NullTime
+	// The timestamp when the invitation was revoked. Null unless the invitation is revoked.
+	RevokedTimestamp sql.NullTime
+	// The application which created this record.
+	CreateAppID uuid.UUID
+	// The user which created this record.
+	CreateUserID uuid.NullUUID
+	// The timestamp when this record was created.
+	CreateTimestamp time.Time
+	// The application which performed the most recent update to this record.
+	UpdateAppID uuid.UUID
+	// The user which performed the most recent update to this record.
+	UpdateUserID uuid.NullUUID
+	// The timestamp when the record was updated most recently.
+	UpdateTimestamp time.Time
 }
 
 // Organization Kind is a reference table denoting an organization's (org) classification. Examples are Genesis, Test, Standard
@@ -189,6 +276,26 @@ type OrgKind struct {
 	UpdateTimestamp time.Time
 }
 
+// The org_kind_role table stores the default roles of an organization kind. The user who creates an organization is granted the default roles of its kind in the organization.
+type OrgKindRole struct {
+	// The organization kind.
+	OrgKindID uuid.UUID
+	// The role granted to the creator of an organization of the kind.
+	RoleID uuid.UUID
+	// The application which created this record.
+	CreateAppID uuid.UUID
+	// The user which created this record.
+	CreateUserID uuid.NullUUID
+	// The timestamp when this record was created.
+	CreateTimestamp time.Time
+	// The application which performed the most recent update to this record.
+	UpdateAppID uuid.UUID
+	// The user which performed the most recent update to this record.
+	UpdateUserID uuid.NullUUID
+	// The timestamp when the record was updated most recently.
+	UpdateTimestamp time.Time
+}
+
 // The permission table stores an approval of a mode of access to a resource.
 type Permission struct {
 	// The unique ID for the table.
@@ -262,12 +369,34 @@ type Role struct {
 	UpdateTimestamp time.Time
 }
 
+// The role_parent table stores the role hierarchy. A role inherits all permissions of its ancestors.
+type RoleParent struct {
+	// The role which inherits the permissions of the parent role.
+	RoleID uuid.UUID
+	// The role whose permissions are inherited.
+	ParentRoleID uuid.UUID
+	// The application which created this record.
+	CreateAppID uuid.UUID
+	// The user which created this record.
+	CreateUserID uuid.NullUUID
+	// The timestamp when this record was created.
+	CreateTimestamp time.Time
+	// The application which performed the most recent update to this record.
+	UpdateAppID uuid.UUID
+	// The user which performed the most recent update to this record.
+	UpdateUserID uuid.NullUUID
+	// The timestamp when the record was updated most recently.
+	UpdateTimestamp time.Time
+}
+
 // The role_permission table stores which roles have which permissions.
 type RolePermission struct {
 	// The unique role which can have 1 to many permissions set in this table.
 	RoleID uuid.UUID
 	// The unique permission that is being given to the role.
 	PermissionID uuid.UUID
+	// Whether the permission is allowed or denied for the role. A deny takes precedence over any allow the user holds for the same request.
+	Effect string
 	// The application which created this record.
 	CreateAppID uuid.UUID
 	// The user which created this record.
@@ -308,6 +437,12 @@ type User struct {
 	UpdateAppID     uuid.UUID
 	UpdateUserID    uuid.NullUUID
 	UpdateTimestamp time.Time
+	// A boolean denoting whether the user is active (true) or suspended (false). A suspended user cannot authenticate.
+	Active bool
+	// Why the user was suspended. Null when the user is active.
+	SuspendedReason sql.NullString
+	// The timestamp when the user was suspended. Null when the user is active.
+	SuspendedTimestamp sql.NullTime
 }
 
 // The users_lang_prefs table stores the list of language tag preferences for the user.
@@ -316,6 +451,8 @@ type UsersLangPref struct {
 	UserID uuid.UUID
 	// The BCP 47 Language Tag which identifies a language both spoken and written.
 	LanguageTag string
+	// The position of the language tag in the ordered preferences of the user, 1 being the most preferred.
+	PrefOrder int32
 	// The application which created this record.
 	CreateAppID uuid.UUID
 	// The user which created this record.
@@ -359,6 +496,10 @@ type UsersRole struct {
 	RoleID uuid.UUID
 	// The organization to which the role and user are associated.
 	OrgID uuid.UUID
+	// The moment the role grant becomes effective. If null, the grant is effective from when it is created.
+	ValidFrom sql.NullTime
+	// The moment the role grant expires. If null, the grant does not expire.
+	ValidUntil sql.NullTime
 	// The application which created this record.
 	CreateAppID uuid.UUID
 	// The user which created this record.
@@ -70,7 +70,7 @@ func (q *Queries) DeleteMovie(ctx context.Context, movieID uuid.UUID) (int64, er
 }
 
 const findMovieByExternalID = `-- name: FindMovieByExternalID :one
-SELECT m.movie_id, m.extl_id, m.title, m.rated, m.released, m.run_time, m.director, m.writer, m.create_app_id, m.create_user_id, m.create_timestamp, m.update_app_id, m.update_user_id, m.update_timestamp
+SELECT m.movie_id, m.extl_id, m.title, m.rated, m.released, m.run_time, m.director, m.writer, m.create_app_id, m.create_user_id, m.create_timestamp, m.update_app_id, m.update_user_id, m.update_timestamp, m.deleted_app_id, m.deleted_user_id, m.deleted_timestamp
 FROM movie m
 WHERE m.extl_id = $1
 `
@@ -93,6 +93,9 @@ func (q *Queries) FindMovieByExternalID(ctx context.Context, extlID string) (Mov
 		&i.UpdateAppID,
 		&i.UpdateUserID,
 		&i.UpdateTimestamp,
+		&i.DeletedAppID,
+		&i.DeletedUserID,
+		&i.DeletedTimestamp,
 	)
 	return i, err
 }
@@ -123,15 +126,22 @@ SELECT m.movie_id,
        m.update_user_id,
        uu.first_name     update_user_first_name,
        uu.last_name      update_user_last_name,
-       m.update_timestamp
+       m.update_timestamp,
+       m.deleted_timestamp
 FROM movie m
          INNER JOIN app ca on ca.app_id = m.create_app_id
          INNER JOIN app ua on ua.app_id = m.update_app_id
          LEFT JOIN users cu on cu.user_id = m.create_user_id
          LEFT JOIN users uu on uu.user_id = m.update_user_id
 WHERE m.extl_id = $1
+  AND ($2::boolean OR m.deleted_timestamp IS NULL)
 `
 
+type FindMovieByExternalIDWithAuditParams struct {
+	ExtlID         string
+	IncludeDeleted bool
+}
+
 type FindMovieByExternalIDWithAuditRow struct {
 	MovieID              uuid.UUID
 	ExtlID               string
@@ -159,10 +169,11 @@ type FindMovieByExternalIDWithAuditRow struct {
 	UpdateUserFirstName  sql.NullString
 	UpdateUserLastName   sql.NullString
 	UpdateTimestamp      time.Time
+	DeletedTimestamp     sql.NullTime
 }
 
-func (q *Queries) FindMovieByExternalIDWithAudit(ctx context.Context, extlID string) (FindMovieByExternalIDWithAuditRow, error) {
-	row := q.db.QueryRow(ctx, findMovieByExternalIDWithAudit, extlID)
+func (q *Queries) FindMovieByExternalIDWithAudit(ctx context.Context, arg FindMovieByExternalIDWithAuditParams) (FindMovieByExternalIDWithAuditRow, error) {
+	row := q.db.QueryRow(ctx, findMovieByExternalIDWithAudit, arg.ExtlID, arg.IncludeDeleted)
 	var i FindMovieByExternalIDWithAuditRow
 	err := row.Scan(
 		&i.MovieID,
@@ -191,6 +202,7 @@ func (q *Queries) FindMovieByExternalIDWithAudit(ctx context.Context, extlID str
 		&i.UpdateUserFirstName,
 		&i.UpdateUserLastName,
 		&i.UpdateTimestamp,
+		&i.DeletedTimestamp,
 	)
 	return i, err
 }
@@ -221,12 +233,14 @@ SELECT m.movie_id,
        m.update_user_id,
        uu.first_name     update_user_first_name,
        uu.last_name      update_user_last_name,
-       m.update_timestamp
+       m.update_timestamp,
+       m.deleted_timestamp
 FROM movie m
          INNER JOIN app ca on ca.app_id = m.create_app_id
          INNER JOIN app ua on ua.app_id = m.update_app_id
          LEFT JOIN users cu on cu.user_id = m.create_user_id
          LEFT JOIN users uu on uu.user_id = m.update_user_id
+WHERE ($1::boolean OR m.deleted_timestamp IS NULL)
 `
 
 type FindMoviesRow struct {
@@ -256,10 +270,11 @@ type FindMoviesRow struct {
 	UpdateUserFirstName  sql.NullString
 	UpdateUserLastName   sql.NullString
 	UpdateTimestamp      time.Time
+	DeletedTimestamp     sql.NullTime
 }
 
-func (q *Queries) FindMovies(ctx context.Context) ([]FindMoviesRow, error) {
-	rows, err := q.db.Query(ctx, findMovies)
+func (q *Queries) FindMovies(ctx context.Context, includeDeleted bool) ([]FindMoviesRow, error) {
+	rows, err := q.db.Query(ctx, findMovies, includeDeleted)
 	if err != nil {
 		return nil, err
 	}
@@ -294,6 +309,7 @@ func (q *Queries) FindMovies(ctx context.Context) ([]FindMoviesRow, error) {
 			&i.UpdateUserFirstName,
 			&i.UpdateUserLastName,
 			&i.UpdateTimestamp,
+			&i.DeletedTimestamp,
 		); err != nil {
 			return nil, err
 		}
@@ -306,7 +322,7 @@ func (q *Queries) FindMovies(ctx context.Context) ([]FindMoviesRow, error) {
 }
 
 const findMoviesByTitle = `-- name: FindMoviesByTitle :many
-SELECT m.movie_id, m.extl_id, m.title, m.rated, m.released, m.run_time, m.director, m.writer, m.create_app_id, m.create_user_id, m.create_timestamp, m.update_app_id, m.update_user_id, m.update_timestamp
+SELECT m.movie_id, m.extl_id, m.title, m.rated, m.released, m.run_time, m.director, m.writer, m.create_app_id, m.create_user_id, m.create_timestamp, m.update_app_id, m.update_user_id, m.update_timestamp, m.deleted_app_id, m.deleted_user_id, m.deleted_timestamp
 FROM movie m
 WHERE m.title = $1
 `
@@ -335,6 +351,9 @@ func (q *Queries) FindMoviesByTitle(ctx context.Context, title string) ([]Movie,
 			&i.UpdateAppID,
 			&i.UpdateUserID,
 			&i.UpdateTimestamp,
+			&i.DeletedAppID,
+			&i.DeletedUserID,
+			&i.DeletedTimestamp,
 		); err != nil {
 			return nil, err
 		}
@@ -346,6 +365,80 @@ func (q *Queries) FindMoviesByTitle(ctx context.Context, title string) ([]Movie,
 	return items, nil
 }
 
+const purgeDeletedMovies = `-- name: PurgeDeletedMovies :execrows
+DELETE FROM movie
+WHERE deleted_timestamp < $1
+`
+
+func (q *Queries) PurgeDeletedMovies(ctx context.Context, deletedTimestamp sql.NullTime) (int64, error) {
+	result, err := q.db.Exec(ctx, purgeDeletedMovies, deletedTimestamp)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
+
+const restoreMovie = `-- name: RestoreMovie :execrows
+UPDATE movie
+SET deleted_app_id    = NULL,
+    deleted_user_id   = NULL,
+    deleted_timestamp = NULL,
+    update_app_id     = $1,
+    update_user_id    = $2,
+    update_timestamp  = $3
+WHERE movie_id = $4
+  AND deleted_timestamp IS NOT NULL
+`
+
+type RestoreMovieParams struct {
+	UpdateAppID     uuid.UUID
+	UpdateUserID    uuid.NullUUID
+	UpdateTimestamp time.Time
+	MovieID         uuid.UUID
+}
+
+func (q *Queries) RestoreMovie(ctx context.Context, arg RestoreMovieParams) (int64, error) {
+	result, err := q.db.Exec(ctx, restoreMovie,
+		arg.UpdateAppID,
+		arg.UpdateUserID,
+		arg.UpdateTimestamp,
+		arg.MovieID,
+	)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
+
+const softDeleteMovie = `-- name: SoftDeleteMovie :execrows
+UPDATE movie
+SET deleted_app_id    = $1,
+    deleted_user_id   = $2,
+    deleted_timestamp = $3
+WHERE movie_id = $4
+  AND deleted_timestamp IS NULL
+`
+
+type SoftDeleteMovieParams struct {
+	DeletedAppID     uuid.NullUUID
+	DeletedUserID    uuid.NullUUID
+	DeletedTimestamp sql.NullTime
+	MovieID          uuid.UUID
+}
+
+func (q *Queries) SoftDeleteMovie(ctx context.Context, arg SoftDeleteMovieParams) (int64, error) {
+	result, err := q.db.Exec(ctx, softDeleteMovie,
+		arg.DeletedAppID,
+		arg.DeletedUserID,
+		arg.DeletedTimestamp,
+		arg.MovieID,
+	)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
+
 const updateMovie = `-- name: UpdateMovie :exec
 UPDATE movie
 SET title            = $1,
@@ -7,15 +7,78 @@ package datastore
 
 import (
 	"context"
+	"database/sql"
 	"time"
 
 	"github.com/google/uuid"
 )
 
+const acceptOrgInvitation = `-- name: AcceptOrgInvitation :execrows
+UPDATE org_invitation
+SET accepted_user_id   = $1,
+    accepted_timestamp = $2,
+    update_app_id      = $3,
+    update_user_id     = $4,
+    update_timestamp   = $5
+WHERE org_invitation_id = $6
+  AND accepted_timestamp IS NULL
+  AND revoked_timestamp IS NULL
+`
+
+type AcceptOrgInvitationParams struct {
+	AcceptedUserID    uuid.NullUUID
+	AcceptedTimestamp sql.NullTime
+	UpdateAppID       uuid.UUID
+	UpdateUserID      uuid.NullUUID
+	UpdateTimestamp   time.Time
+	OrgInvitationID   uuid.UUID
+}
+
+func (q *Queries) AcceptOrgInvitation(ctx context.Context, arg AcceptOrgInvitationParams) (int64, error) {
+	result, err := q.db.Exec(ctx, acceptOrgInvitation,
+		arg.AcceptedUserID,
+		arg.AcceptedTimestamp,
+		arg.UpdateAppID,
+		arg.UpdateUserID,
+		arg.UpdateTimestamp,
+		arg.OrgInvitationID,
+	)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
+
+const countOrgKindRolesByRoleID = `-- name: CountOrgKindRolesByRoleID :one
+SELECT count(*)
+FROM org_kind_role
+WHERE role_id = $1
+`
+
+func (q *Queries) CountOrgKindRolesByRoleID(ctx context.Context, roleID uuid.UUID) (int64, error) {
+	row := q.db.QueryRow(ctx, countOrgKindRolesByRoleID, roleID)
+	var count int64
+	err := row.Scan(&count)
+	return count, err
+}
+
+const countOrgsByKind = `-- name: CountOrgsByKind :one
+SELECT count(*)
+FROM org
+WHERE org_kind_id = $1
+`
+
+func (q *Queries) CountOrgsByKind(ctx context.Context, orgKindID uuid.UUID) (int64, error) {
+	row := q.db.QueryRow(ctx, countOrgsByKind, orgKindID)
+	var count int64
+	err := row.Scan(&count)
+	return count, err
+}
+
 const createOrg = `-- name: CreateOrg :execrows
 INSERT INTO org (org_id, org_extl_id, org_name, org_description, org_kind_id, create_app_id, create_user_id,
-                 create_timestamp, update_app_id, update_user_id, update_timestamp)
-VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11)
+                 create_timestamp, update_app_id, update_user_id, update_timestamp, parent_org_id)
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12)
 `
 
 type CreateOrgParams struct {
@@ -30,6 +93,7 @@ type CreateOrgParams struct {
 	UpdateAppID     uuid.UUID
 	UpdateUserID    uuid.NullUUID
 	UpdateTimestamp time.Time
+	ParentOrgID     uuid.NullUUID
 }
 
 func (q *Queries) CreateOrg(ctx context.Context, arg CreateOrgParams) (int64, error) {
@@ -45,6 +109,52 @@ func (q *Queries) CreateOrg(ctx context.Context, arg CreateOrgParams) (int64, er
 		arg.UpdateAppID,
 		arg.UpdateUserID,
 		arg.UpdateTimestamp,
+		arg.ParentOrgID,
+	)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
+
+const createOrgInvitation = `-- name: CreateOrgInvitation :execrows
+INSERT INTO org_invitation (org_invitation_id, org_invitation_extl_id, org_id, role_id, email, expiry_timestamp,
+                            last_sent_timestamp, create_app_id, create_user_id, create_timestamp, update_app_id,
+                            update_user_id, update_timestamp)
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13)
+`
+
+type CreateOrgInvitationParams struct {
+	OrgInvitationID     uuid.UUID
+	OrgInvitationExtlID string
+	OrgID               uuid.UUID
+	RoleID              uuid.UUID
+	Email               string
+	ExpiryTimestamp     time.Time
+	LastSentTimestamp   time.Time
+	CreateAppID         uuid.UUID
+	CreateUserID        uuid.NullUUID
+	CreateTimestamp     time.Time
+	UpdateAppID         uuid.UUID
+	UpdateUserID        uuid.NullUUID
+	UpdateTimestamp     time.Time
+}
+
+func (q *Queries) CreateOrgInvitation(ctx context.Context, arg CreateOrgInvitationParams) (int64, error) {
+	result, err := q.db.Exec(ctx, createOrgInvitation,
+		arg.OrgInvitationID,
+		arg.OrgInvitationExtlID,
+		arg.OrgID,
+		arg.RoleID,
+		arg.Email,
+		arg.ExpiryTimestamp,
+		arg.LastSentTimestamp,
+		arg.CreateAppID,
+		arg.CreateUserID,
+		arg.CreateTimestamp,
+		arg.UpdateAppID,
+		arg.UpdateUserID,
+		arg.UpdateTimestamp,
 	)
 	if err != nil {
 		return 0, err
@@ -88,6 +198,40 @@ func (q *Queries) CreateOrgKind(ctx context.Context, arg CreateOrgKindParams) (i
 	return result.RowsAffected(), nil
 }
 
+const createOrgKindRole = `-- name: CreateOrgKindRole :execrows
+insert into org_kind_role (org_kind_id, role_id, create_app_id, create_user_id, create_timestamp, update_app_id,
+                           update_user_id, update_timestamp)
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+`
+
+type CreateOrgKindRoleParams struct {
+	OrgKindID       uuid.UUID
+	RoleID          uuid.UUID
+	CreateAppID     uuid.UUID
+	CreateUserID    uuid.NullUUID
+	CreateTimestamp time.Time
+	UpdateAppID     uuid.UUID
+	UpdateUserID    uuid.NullUUID
+	UpdateTimestamp time.Time
+}
+
+func (q *Queries) CreateOrgKindRole(ctx context.Context, arg CreateOrgKindRoleParams) (int64, error) {
+	result, err := q.db.Exec(ctx, createOrgKindRole,
+		arg.OrgKindID,
+		arg.RoleID,
+		arg.CreateAppID,
+		arg.CreateUserID,
+		arg.CreateTimestamp,
+		arg.UpdateAppID,
+		arg.UpdateUserID,
+		arg.UpdateTimestamp,
+	)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
+
 const deleteOrg = `-- name: DeleteOrg :execrows
 DELETE
 FROM org
@@ -102,6 +246,89 @@ func (q *Queries) DeleteOrg(ctx context.Context, orgID uuid.UUID) (int64, error)
 	return result.RowsAffected(), nil
 }
 
+const deleteOrgInvitationsByOrg = `-- name: DeleteOrgInvitationsByOrg :execrows
+DELETE
+FROM org_invitation
+WHERE org_id = $1
+`
+
+func (q *Queries) DeleteOrgInvitationsByOrg(ctx context.Context, orgID uuid.UUID) (int64, error) {
+	result, err := q.db.Exec(ctx, deleteOrgInvitationsByOrg, orgID)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
+
+const deleteOrgKind = `-- name: DeleteOrgKind :execrows
+DELETE FROM org_kind
+WHERE org_kind_id = $1
+`
+
+func (q *Queries) DeleteOrgKind(ctx context.Context, orgKindID uuid.UUID) (int64, error) {
+	result, err := q.db.Exec(ctx, deleteOrgKind, orgKindID)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
+
+const deleteOrgKindRoles = `-- name: DeleteOrgKindRoles :execrows
+DELETE FROM org_kind_role
+WHERE org_kind_id = $1
+`
+
+func (q *Queries) DeleteOrgKindRoles(ctx context.Context, orgKindID uuid.UUID) (int64, error) {
+	result, err := q.db.Exec(ctx, deleteOrgKindRoles, orgKindID)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
+
+const findDeletedOrgs = `-- name: FindDeletedOrgs :many
+WITH RECURSIVE org_depth AS (
+    SELECT o.org_id, 0 AS depth
+    FROM org o
+    WHERE o.parent_org_id IS NULL
+    UNION ALL
+    SELECT c.org_id, d.depth + 1
+    FROM org c
+             INNER JOIN org_depth d on d.org_id = c.parent_org_id
+)
+SELECT o.org_id,
+       o.org_extl_id
+FROM org o
+         INNER JOIN org_depth d on d.org_id = o.org_id
+WHERE o.deleted_timestamp < $1
+ORDER BY d.depth DESC
+`
+
+type FindDeletedOrgsRow struct {
+	OrgID     uuid.UUID
+	OrgExtlID string
+}
+
+func (q *Queries) FindDeletedOrgs(ctx context.Context, deletedTimestamp sql.NullTime) ([]FindDeletedOrgsRow, error) {
+	rows, err := q.db.Query(ctx, findDeletedOrgs, deletedTimestamp)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []FindDeletedOrgsRow
+	for rows.Next() {
+		var i FindDeletedOrgsRow
+		if err := rows.Scan(&i.OrgID, &i.OrgExtlID); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
 const findOrgByExtlID = `-- name: FindOrgByExtlID :one
 SELECT o.org_id,
        o.org_extl_id,
@@ -113,6 +340,7 @@ SELECT o.org_id,
 FROM org o
          INNER JOIN org_kind ok on ok.org_kind_id = o.org_kind_id
 WHERE org_extl_id = $1
+  AND o.deleted_timestamp IS NULL
 `
 
 type FindOrgByExtlIDRow struct {
@@ -165,16 +393,26 @@ SELECT o.org_id,
        o.update_user_id,
        uu.first_name      update_user_first_name,
        uu.last_name       update_user_last_name,
-       o.update_timestamp
+       o.update_timestamp,
+       o.parent_org_id,
+       po.org_extl_id     parent_org_extl_id,
+       o.deleted_timestamp
 FROM org o
          INNER JOIN org_kind ok on ok.org_kind_id = o.org_kind_id
          INNER JOIN app a on a.app_id = o.create_app_id
          INNER JOIN app a2 on a2.app_id = o.update_app_id
          INNER JOIN users cu on cu.user_id = o.create_user_id
          INNER JOIN users uu on uu.user_id = o.update_user_id
+         LEFT JOIN org po on po.org_id = o.parent_org_id
 WHERE o.org_extl_id = $1
+  AND ($2::boolean OR o.deleted_timestamp IS NULL)
 `
 
+type FindOrgByExtlIDWithAuditParams struct {
+	OrgExtlID      string
+	IncludeDeleted bool
+}
+
 type FindOrgByExtlIDWithAuditRow struct {
 	OrgID                uuid.UUID
 	OrgExtlID            string
@@ -201,10 +439,13 @@ type FindOrgByExtlIDWithAuditRow struct {
 	UpdateUserFirstName  string
 	UpdateUserLastName   string
 	UpdateTimestamp      time.Time
+	ParentOrgID          uuid.NullUUID
+	ParentOrgExtlID      sql.NullString
+	DeletedTimestamp     sql.NullTime
 }
 
-func (q *Queries) FindOrgByExtlIDWithAudit(ctx context.Context, orgExtlID string) (FindOrgByExtlIDWithAuditRow, error) {
-	row := q.db.QueryRow(ctx, findOrgByExtlIDWithAudit, orgExtlID)
+func (q *Queries) FindOrgByExtlIDWithAudit(ctx context.Context, arg FindOrgByExtlIDWithAuditParams) (FindOrgByExtlIDWithAuditRow, error) {
+	row := q.db.QueryRow(ctx, findOrgByExtlIDWithAudit, arg.OrgExtlID, arg.IncludeDeleted)
 	var i FindOrgByExtlIDWithAuditRow
 	err := row.Scan(
 		&i.OrgID,
@@ -232,6 +473,9 @@ func (q *Queries) FindOrgByExtlIDWithAudit(ctx context.Context, orgExtlID string
 		&i.UpdateUserFirstName,
 		&i.UpdateUserLastName,
 		&i.UpdateTimestamp,
+		&i.ParentOrgID,
+		&i.ParentOrgExtlID,
+		&i.DeletedTimestamp,
 	)
 	return i, err
 }
@@ -381,6 +625,7 @@ SELECT o.org_id,
 FROM org o
          INNER JOIN org_kind ok on ok.org_kind_id = o.org_kind_id
 WHERE o.org_name = $1
+  AND o.deleted_timestamp IS NULL
 `
 
 type FindOrgByNameRow struct {
@@ -441,6 +686,7 @@ FROM org o
          INNER JOIN users cu on cu.user_id = o.create_user_id
          INNER JOIN users uu on uu.user_id = o.update_user_id
 WHERE o.org_name = $1
+  AND o.deleted_timestamp IS NULL
 `
 
 type FindOrgByNameWithAuditRow struct {
@@ -504,6 +750,289 @@ func (q *Queries) FindOrgByNameWithAudit(ctx context.Context, orgName string) (F
 	return i, err
 }
 
+const findOrgDescendantsWithAudit = `-- name: FindOrgDescendantsWithAudit :many
+WITH RECURSIVE descendants AS (
+    SELECT c.org_id, 1 AS depth
+    FROM org c
+    WHERE c.parent_org_id = $1
+    UNION ALL
+    SELECT c.org_id, d.depth + 1
+    FROM org c
+             INNER JOIN descendants d on d.org_id = c.parent_org_id
+)
+SELECT o.org_id,
+       o.org_extl_id,
+       o.org_name,
+       o.org_description,
+       ok.org_kind_id,
+       ok.org_kind_extl_id,
+       ok.org_kind_desc,
+       o.create_app_id,
+       a.org_id           create_app_org_id,
+       a.app_extl_id      create_app_extl_id,
+       a.app_name         create_app_name,
+       a.app_description  create_app_description,
+       o.create_user_id,
+       cu.first_name      create_user_first_name,
+       cu.last_name       create_user_last_name,
+       o.create_timestamp,
+       o.update_app_id,
+       a2.org_id          update_app_org_id,
+       a2.app_extl_id     update_app_extl_id,
+       a2.app_name        update_app_name,
+       a2.app_description update_app_description,
+       o.update_user_id,
+       uu.first_name      update_user_first_name,
+       uu.last_name       update_user_last_name,
+       o.update_timestamp,
+       o.parent_org_id,
+       po.org_extl_id     parent_org_extl_id,
+       o.deleted_timestamp
+FROM descendants d
+         INNER JOIN org o on o.org_id = d.org_id
+         INNER JOIN org_kind ok on ok.org_kind_id = o.org_kind_id
+         INNER JOIN app a on a.app_id = o.create_app_id
+         INNER JOIN app a2 on a2.app_id = o.update_app_id
+         INNER JOIN users cu on cu.user_id = o.create_user_id
+         INNER JOIN users uu on uu.user_id = o.update_user_id
+         LEFT JOIN org po on po.org_id = o.parent_org_id
+WHERE ($2::boolean OR o.deleted_timestamp IS NULL)
+ORDER BY d.depth, o.org_name
+`
+
+type FindOrgDescendantsWithAuditParams struct {
+	ParentOrgID    uuid.NullUUID
+	IncludeDeleted bool
+}
+
+type FindOrgDescendantsWithAuditRow struct {
+	OrgID                uuid.UUID
+	OrgExtlID            string
+	OrgName              string
+	OrgDescription       string
+	OrgKindID            uuid.UUID
+	OrgKindExtlID        string
+	OrgKindDesc          string
+	CreateAppID          uuid.UUID
+	CreateAppOrgID       uuid.UUID
+	CreateAppExtlID      string
+	CreateAppName        string
+	CreateAppDescription string
+	CreateUserID         uuid.NullUUID
+	CreateUserFirstName  string
+	CreateUserLastName   string
+	CreateTimestamp      time.Time
+	UpdateAppID          uuid.UUID
+	UpdateAppOrgID       uuid.UUID
+	UpdateAppExtlID      string
+	UpdateAppName        string
+	UpdateAppDescription string
+	UpdateUserID         uuid.NullUUID
+	UpdateUserFirstName  string
+	UpdateUserLastName   string
+	UpdateTimestamp      time.Time
+	ParentOrgID          uuid.NullUUID
+	ParentOrgExtlID      sql.NullString
+	DeletedTimestamp     sql.NullTime
+}
+
+func (q *Queries) FindOrgDescendantsWithAudit(ctx context.Context, arg FindOrgDescendantsWithAuditParams) ([]FindOrgDescendantsWithAuditRow, error) {
+	rows, err := q.db.Query(ctx, findOrgDescendantsWithAudit, arg.ParentOrgID, arg.IncludeDeleted)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []FindOrgDescendantsWithAuditRow
+	for rows.Next() {
+		var i FindOrgDescendantsWithAuditRow
+		if err := rows.Scan(
+			&i.OrgID,
+			&i.OrgExtlID,
+			&i.OrgName,
+			&i.OrgDescription,
+			&i.OrgKindID,
+			&i.OrgKindExtlID,
+			&i.OrgKindDesc,
+			&i.CreateAppID,
+			&i.CreateAppOrgID,
+			&i.CreateAppExtlID,
+			&i.CreateAppName,
+			&i.CreateAppDescription,
+			&i.CreateUserID,
+			&i.CreateUserFirstName,
+			&i.CreateUserLastName,
+			&i.CreateTimestamp,
+			&i.UpdateAppID,
+			&i.UpdateAppOrgID,
+			&i.UpdateAppExtlID,
+			&i.UpdateAppName,
+			&i.UpdateAppDescription,
+			&i.UpdateUserID,
+			&i.UpdateUserFirstName,
+			&i.UpdateUserLastName,
+			&i.UpdateTimestamp,
+			&i.ParentOrgID,
+			&i.ParentOrgExtlID,
+			&i.DeletedTimestamp,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const findOrgInvitationByExternalID = `-- name: FindOrgInvitationByExternalID :one
+SELECT i.org_invitation_id,
+       i.org_invitation_extl_id,
+       i.org_id,
+       o.org_extl_id,
+       i.role_id,
+       r.role_cd,
+       i.email,
+       i.expiry_timestamp,
+       i.send_count,
+       i.last_sent_timestamp,
+       i.accepted_user_id,
+       u.user_extl_id accepted_user_extl_id,
+       i.accepted_timestamp,
+       i.revoked_timestamp,
+       i.create_timestamp,
+       i.update_timestamp
+FROM org_invitation i
+         INNER JOIN org o on o.org_id = i.org_id
+         INNER JOIN role r on r.role_id = i.role_id
+         LEFT JOIN users u on u.user_id = i.accepted_user_id
+WHERE i.org_invitation_extl_id = $1
+`
+
+type FindOrgInvitationByExternalIDRow struct {
+	OrgInvitationID     uuid.UUID
+	OrgInvitationExtlID string
+	OrgID               uuid.UUID
+	OrgExtlID           string
+	RoleID              uuid.UUID
+	RoleCd              string
+	Email               string
+	ExpiryTimestamp     time.Time
+	SendCount           int64
+	LastSentTimestamp   time.Time
+	AcceptedUserID      uuid.NullUUID
+	AcceptedUserExtlID  sql.NullString
+	AcceptedTimestamp   sql.NullTime
+	RevokedTimestamp    sql.NullTime
+	CreateTimestamp     time.Time
+	UpdateTimestamp     time.Time
+}
+
+func (q *Queries) FindOrgInvitationByExternalID(ctx context.Context, orgInvitationExtlID string) (FindOrgInvitationByExternalIDRow, error) {
+	row := q.db.QueryRow(ctx, findOrgInvitationByExternalID, orgInvitationExtlID)
+	var i FindOrgInvitationByExternalIDRow
+	err := row.Scan(
+		&i.OrgInvitationID,
+		&i.OrgInvitationExtlID,
+		&i.OrgID,
+		&i.OrgExtlID,
+		&i.RoleID,
+		&i.RoleCd,
+		&i.Email,
+		&i.ExpiryTimestamp,
+		&i.SendCount,
+		&i.LastSentTimestamp,
+		&i.AcceptedUserID,
+		&i.AcceptedUserExtlID,
+		&i.AcceptedTimestamp,
+		&i.RevokedTimestamp,
+		&i.CreateTimestamp,
+		&i.UpdateTimestamp,
+	)
+	return i, err
+}
+
+const findOrgInvitationsByOrgID = `-- name: FindOrgInvitationsByOrgID :many
+SELECT i.org_invitation_id,
+       i.org_invitation_extl_id,
+       i.org_id,
+       o.org_extl_id,
+       i.role_id,
+       r.role_cd,
+       i.email,
+       i.expiry_timestamp,
+       i.send_count,
+       i.last_sent_timestamp,
+       i.accepted_user_id,
+       u.user_extl_id accepted_user_extl_id,
+       i.accepted_timestamp,
+       i.revoked_timestamp,
+       i.create_timestamp,
+       i.update_timestamp
+FROM org_invitation i
+         INNER JOIN org o on o.org_id = i.org_id
+         INNER JOIN role r on r.role_id = i.role_id
+         LEFT JOIN users u on u.user_id = i.accepted_user_id
+WHERE i.org_id = $1
+ORDER BY i.create_timestamp DESC
+`
+
+type FindOrgInvitationsByOrgIDRow struct {
+	OrgInvitationID     uuid.UUID
+	OrgInvitationExtlID string
+	OrgID               uuid.UUID
+	OrgExtlID           string
+	RoleID              uuid.UUID
+	RoleCd              string
+	Email               string
+	ExpiryTimestamp     time.Time
+	SendCount           int64
+	LastSentTimestamp   time.Time
+	AcceptedUserID      uuid.NullUUID
+	AcceptedUserExtlID  sql.NullString
+	AcceptedTimestamp   sql.NullTime
+	RevokedTimestamp    sql.NullTime
+	CreateTimestamp     time.Time
+	UpdateTimestamp     time.Time
+}
+
+func (q *Queries) FindOrgInvitationsByOrgID(ctx context.Context, orgID uuid.UUID) ([]FindOrgInvitationsByOrgIDRow, error) {
+	rows, err := q.db.Query(ctx, findOrgInvitationsByOrgID, orgID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []FindOrgInvitationsByOrgIDRow
+	for rows.Next() {
+		var i FindOrgInvitationsByOrgIDRow
+		if err := rows.Scan(
+			&i.OrgInvitationID,
+			&i.OrgInvitationExtlID,
+			&i.OrgID,
+			&i.OrgExtlID,
+			&i.RoleID,
+			&i.RoleCd,
+			&i.Email,
+			&i.ExpiryTimestamp,
+			&i.SendCount,
+			&i.LastSentTimestamp,
+			&i.AcceptedUserID,
+			&i.AcceptedUserExtlID,
+			&i.AcceptedTimestamp,
+			&i.RevokedTimestamp,
+			&i.CreateTimestamp,
+			&i.UpdateTimestamp,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
 const findOrgKindByExtlID = `-- name: FindOrgKindByExtlID :one
 SELECT org_kind_id, org_kind_extl_id, org_kind_desc, create_app_id, create_user_id, create_timestamp, update_app_id, update_user_id, update_timestamp
 FROM org_kind
@@ -527,10 +1056,51 @@ func (q *Queries) FindOrgKindByExtlID(ctx context.Context, orgKindExtlID string)
 	return i, err
 }
 
+const findOrgKindRolesByKindID = `-- name: FindOrgKindRolesByKindID :many
+SELECT r.role_id, r.role_extl_id, r.role_cd, r.role_description, r.active, r.create_app_id, r.create_user_id, r.create_timestamp, r.update_app_id, r.update_user_id, r.update_timestamp
+FROM org_kind_role okr
+         inner join role r on r.role_id = okr.role_id
+WHERE okr.org_kind_id = $1
+ORDER BY r.role_cd
+`
+
+func (q *Queries) FindOrgKindRolesByKindID(ctx context.Context, orgKindID uuid.UUID) ([]Role, error) {
+	rows, err := q.db.Query(ctx, findOrgKindRolesByKindID, orgKindID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []Role
+	for rows.Next() {
+		var i Role
+		if err := rows.Scan(
+			&i.RoleID,
+			&i.RoleExtlID,
+			&i.RoleCd,
+			&i.RoleDescription,
+			&i.Active,
+			&i.CreateAppID,
+			&i.CreateUserID,
+			&i.CreateTimestamp,
+			&i.UpdateAppID,
+			&i.UpdateUserID,
+			&i.UpdateTimestamp,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
 const findOrgKinds = `-- name: FindOrgKinds :many
 
 SELECT org_kind_id, org_kind_extl_id, org_kind_desc, create_app_id, create_user_id, create_timestamp, update_app_id, update_user_id, update_timestamp
 FROM org_kind
+ORDER BY org_kind_extl_id
 `
 
 // ---------------------------------------------------------------------------------------------------------------------
@@ -576,6 +1146,7 @@ SELECT o.org_id,
        ok.org_kind_desc
 FROM org o
          INNER JOIN org_kind ok on ok.org_kind_id = o.org_kind_id
+WHERE o.deleted_timestamp IS NULL
 ORDER BY org_name
 `
 
@@ -627,6 +1198,7 @@ SELECT o.org_id,
 FROM org o
          INNER JOIN org_kind ok on ok.org_kind_id = o.org_kind_id
 WHERE ok.org_kind_extl_id = $1
+  AND o.deleted_timestamp IS NULL
 `
 
 type FindOrgsByKindExtlIDRow struct {
@@ -690,13 +1262,18 @@ SELECT o.org_id,
        o.update_user_id,
        uu.first_name      update_user_first_name,
        uu.last_name       update_user_last_name,
-       o.update_timestamp
+       o.update_timestamp,
+       o.parent_org_id,
+       po.org_extl_id     parent_org_extl_id,
+       o.deleted_timestamp
 FROM org o
          INNER JOIN org_kind ok on ok.org_kind_id = o.org_kind_id
          INNER JOIN app a on a.app_id = o.create_app_id
          INNER JOIN app a2 on a2.app_id = o.update_app_id
          INNER JOIN users cu on cu.user_id = o.create_user_id
          INNER JOIN users uu on uu.user_id = o.update_user_id
+         LEFT JOIN org po on po.org_id = o.parent_org_id
+WHERE ($1::boolean OR o.deleted_timestamp IS NULL)
 `
 
 type FindOrgsWithAuditRow struct {
@@ -725,10 +1302,13 @@ type FindOrgsWithAuditRow struct {
 	UpdateUserFirstName  string
 	UpdateUserLastName   string
 	UpdateTimestamp      time.Time
+	ParentOrgID          uuid.NullUUID
+	ParentOrgExtlID      sql.NullString
+	DeletedTimestamp     sql.NullTime
 }
 
-func (q *Queries) FindOrgsWithAudit(ctx context.Context) ([]FindOrgsWithAuditRow, error) {
-	rows, err := q.db.Query(ctx, findOrgsWithAudit)
+func (q *Queries) FindOrgsWithAudit(ctx context.Context, includeDeleted bool) ([]FindOrgsWithAuditRow, error) {
+	rows, err := q.db.Query(ctx, findOrgsWithAudit, includeDeleted)
 	if err != nil {
 		return nil, err
 	}
@@ -762,6 +1342,9 @@ func (q *Queries) FindOrgsWithAudit(ctx context.Context) ([]FindOrgsWithAuditRow
 			&i.UpdateUserFirstName,
 			&i.UpdateUserLastName,
 			&i.UpdateTimestamp,
+			&i.ParentOrgID,
+			&i.ParentOrgExtlID,
+			&i.DeletedTimestamp,
 		); err != nil {
 			return nil, err
 		}
@@ -773,14 +1356,249 @@ func (q *Queries) FindOrgsWithAudit(ctx context.Context) ([]FindOrgsWithAuditRow
 	return items, nil
 }
 
+const findUserLineageOrgByExtlID = `-- name: FindUserLineageOrgByExtlID :one
+WITH RECURSIVE org_lineage AS (
+    SELECT o.org_id, o.parent_org_id
+    FROM org o
+    WHERE o.org_extl_id = $1
+    UNION
+    SELECT po.org_id, po.parent_org_id
+    FROM org po
+             INNER JOIN org_lineage l on l.parent_org_id = po.org_id
+)
+SELECT o.org_id,
+       o.org_extl_id,
+       o.org_name,
+       o.org_description,
+       o.org_kind_id,
+       ok.org_kind_extl_id,
+       ok.org_kind_desc
+FROM org o
+         INNER JOIN org_kind ok on ok.org_kind_id = o.org_kind_id
+WHERE o.org_extl_id = $1
+  AND o.deleted_timestamp IS NULL
+  AND EXISTS(SELECT 1
+             FROM users_org uo
+                      INNER JOIN org_lineage l on l.org_id = uo.org_id
+             WHERE uo.user_id = $2)
+`
+
+type FindUserLineageOrgByExtlIDParams struct {
+	OrgExtlID string
+	UserID    uuid.UUID
+}
+
+type FindUserLineageOrgByExtlIDRow struct {
+	OrgID          uuid.UUID
+	OrgExtlID      string
+	OrgName        string
+	OrgDescription string
+	OrgKindID      uuid.UUID
+	OrgKindExtlID  string
+	OrgKindDesc    string
+}
+
+func (q *Queries) FindUserLineageOrgByExtlID(ctx context.Context, arg FindUserLineageOrgByExtlIDParams) (FindUserLineageOrgByExtlIDRow, error) {
+	row := q.db.QueryRow(ctx, findUserLineageOrgByExtlID, arg.OrgExtlID, arg.UserID)
+	var i FindUserLineageOrgByExtlIDRow
+	err := row.Scan(
+		&i.OrgID,
+		&i.OrgExtlID,
+		&i.OrgName,
+		&i.OrgDescription,
+		&i.OrgKindID,
+		&i.OrgKindExtlID,
+		&i.OrgKindDesc,
+	)
+	return i, err
+}
+
+const findUserOrgByExtlID = `-- name: FindUserOrgByExtlID :one
+SELECT o.org_id,
+       o.org_extl_id,
+       o.org_name,
+       o.org_description,
+       o.org_kind_id,
+       ok.org_kind_extl_id,
+       ok.org_kind_desc
+FROM org o
+         INNER JOIN org_kind ok on ok.org_kind_id = o.org_kind_id
+         INNER JOIN users_org uo on uo.org_id = o.org_id
+WHERE o.org_extl_id = $1
+  AND uo.user_id = $2
+  AND o.deleted_timestamp IS NULL
+`
+
+type FindUserOrgByExtlIDParams struct {
+	OrgExtlID string
+	UserID    uuid.UUID
+}
+
+type FindUserOrgByExtlIDRow struct {
+	OrgID          uuid.UUID
+	OrgExtlID      string
+	OrgName        string
+	OrgDescription string
+	OrgKindID      uuid.UUID
+	OrgKindExtlID  string
+	OrgKindDesc    string
+}
+
+func (q *Queries) FindUserOrgByExtlID(ctx context.Context, arg FindUserOrgByExtlIDParams) (FindUserOrgByExtlIDRow, error) {
+	row := q.db.QueryRow(ctx, findUserOrgByExtlID, arg.OrgExtlID, arg.UserID)
+	var i FindUserOrgByExtlIDRow
+	err := row.Scan(
+		&i.OrgID,
+		&i.OrgExtlID,
+		&i.OrgName,
+		&i.OrgDescription,
+		&i.OrgKindID,
+		&i.OrgKindExtlID,
+		&i.OrgKindDesc,
+	)
+	return i, err
+}
+
+const resendOrgInvitation = `-- name: ResendOrgInvitation :execrows
+UPDATE org_invitation
+SET expiry_timestamp    = $1,
+    send_count          = send_count + 1,
+    last_sent_timestamp = $2,
+    update_app_id       = $3,
+    update_user_id      = $4,
+    update_timestamp    = $5
+WHERE org_invitation_id = $6
+  AND accepted_timestamp IS NULL
+  AND revoked_timestamp IS NULL
+`
+
+type ResendOrgInvitationParams struct {
+	ExpiryTimestamp   time.Time
+	LastSentTimestamp time.Time
+	UpdateAppID       uuid.UUID
+	UpdateUserID      uuid.NullUUID
+	UpdateTimestamp   time.Time
+	OrgInvitationID   uuid.UUID
+}
+
+func (q *Queries) ResendOrgInvitation(ctx context.Context, arg ResendOrgInvitationParams) (int64, error) {
+	result, err := q.db.Exec(ctx, resendOrgInvitation,
+		arg.ExpiryTimestamp,
+		arg.LastSentTimestamp,
+		arg.UpdateAppID,
+		arg.UpdateUserID,
+		arg.UpdateTimestamp,
+		arg.OrgInvitationID,
+	)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
+
+const restoreOrg = `-- name: RestoreOrg :execrows
+UPDATE org
+SET deleted_app_id    = NULL,
+    deleted_user_id   = NULL,
+    deleted_timestamp = NULL,
+    update_app_id     = $1,
+    update_user_id    = $2,
+    update_timestamp  = $3
+WHERE org_id = $4
+  AND deleted_timestamp IS NOT NULL
+`
+
+type RestoreOrgParams struct {
+	UpdateAppID     uuid.UUID
+	UpdateUserID    uuid.NullUUID
+	UpdateTimestamp time.Time
+	OrgID           uuid.UUID
+}
+
+func (q *Queries) RestoreOrg(ctx context.Context, arg RestoreOrgParams) (int64, error) {
+	result, err := q.db.Exec(ctx, restoreOrg,
+		arg.UpdateAppID,
+		arg.UpdateUserID,
+		arg.UpdateTimestamp,
+		arg.OrgID,
+	)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
+
+const revokeOrgInvitation = `-- name: RevokeOrgInvitation :execrows
+UPDATE org_invitation
+SET revoked_timestamp = $1,
+    update_app_id     = $2,
+    update_user_id    = $3,
+    update_timestamp  = $4
+WHERE org_invitation_id = $5
+  AND accepted_timestamp IS NULL
+  AND revoked_timestamp IS NULL
+`
+
+type RevokeOrgInvitationParams struct {
+	RevokedTimestamp sql.NullTime
+	UpdateAppID      uuid.UUID
+	UpdateUserID     uuid.NullUUID
+	UpdateTimestamp  time.Time
+	OrgInvitationID  uuid.UUID
+}
+
+func (q *Queries) RevokeOrgInvitation(ctx context.Context, arg RevokeOrgInvitationParams) (int64, error) {
+	result, err := q.db.Exec(ctx, revokeOrgInvitation,
+		arg.RevokedTimestamp,
+		arg.UpdateAppID,
+		arg.UpdateUserID,
+		arg.UpdateTimestamp,
+		arg.OrgInvitationID,
+	)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
+
+const softDeleteOrg = `-- name: SoftDeleteOrg :execrows
+UPDATE org
+SET deleted_app_id    = $1,
+    deleted_user_id   = $2,
+    deleted_timestamp = $3
+WHERE org_id = $4
+  AND deleted_timestamp IS NULL
+`
+
+type SoftDeleteOrgParams struct {
+	DeletedAppID     uuid.NullUUID
+	DeletedUserID    uuid.NullUUID
+	DeletedTimestamp sql.NullTime
+	OrgID            uuid.UUID
+}
+
+func (q *Queries) SoftDeleteOrg(ctx context.Context, arg SoftDeleteOrgParams) (int64, error) {
+	result, err := q.db.Exec(ctx, softDeleteOrg,
+		arg.DeletedAppID,
+		arg.DeletedUserID,
+		arg.DeletedTimestamp,
+		arg.OrgID,
+	)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
+
 const updateOrg = `-- name: UpdateOrg :execrows
 UPDATE org
 SET org_name         = $1,
     org_description  = $2,
     update_app_id    = $3,
     update_user_id   = $4,
-    update_timestamp = $5
-WHERE org_id = $6
+    update_timestamp = $5,
+    parent_org_id    = $6
+WHERE org_id = $7
 `
 
 type UpdateOrgParams struct {
@@ -789,6 +1607,7 @@ type UpdateOrgParams struct {
 	UpdateAppID     uuid.UUID
 	UpdateUserID    uuid.NullUUID
 	UpdateTimestamp time.Time
+	ParentOrgID     uuid.NullUUID
 	OrgID           uuid.UUID
 }
 
@@ -799,6 +1618,7 @@ func (q *Queries) UpdateOrg(ctx context.Context, arg UpdateOrgParams) (int64, er
 		arg.UpdateAppID,
 		arg.UpdateUserID,
 		arg.UpdateTimestamp,
+		arg.ParentOrgID,
 		arg.OrgID,
 	)
 	if err != nil {
@@ -806,3 +1626,34 @@ func (q *Queries) UpdateOrg(ctx context.Context, arg UpdateOrgParams) (int64, er
 	}
 	return result.RowsAffected(), nil
 }
+
+const updateOrgKind = `-- name: UpdateOrgKind :execrows
+UPDATE org_kind
+SET org_kind_desc    = $1,
+    update_app_id    = $2,
+    update_user_id   = $3,
+    update_timestamp = $4
+WHERE org_kind_id = $5
+`
+
+type UpdateOrgKindParams struct {
+	OrgKindDesc     string
+	UpdateAppID     uuid.UUID
+	UpdateUserID    uuid.NullUUID
+	UpdateTimestamp time.Time
+	OrgKindID       uuid.UUID
+}
+
+func (q *Queries) UpdateOrgKind(ctx context.Context, arg UpdateOrgKindParams) (int64, error) {
+	result, err := q.db.Exec(ctx, updateOrgKind,
+		arg.OrgKindDesc,
+		arg.UpdateAppID,
+		arg.UpdateUserID,
+		arg.UpdateTimestamp,
+		arg.OrgKindID,
+	)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
@@ -114,13 +114,14 @@ func (q *Queries) CreateUser(ctx context.Context, arg CreateUserParams) (int64,
 }
 
 const createUserLanguagePreference = `-- name: CreateUserLanguagePreference :execrows
-INSERT INTO users_lang_prefs (user_id, language_tag, create_app_id, create_user_id, create_timestamp, update_app_id, update_user_id, update_timestamp)
-VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+INSERT INTO users_lang_prefs (user_id, language_tag, pref_order, create_app_id, create_user_id, create_timestamp, update_app_id, update_user_id, update_timestamp)
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
 `
 
 type CreateUserLanguagePreferenceParams struct {
 	UserID          uuid.UUID
 	LanguageTag     string
+	PrefOrder       int32
 	CreateAppID     uuid.UUID
 	CreateUserID    uuid.NullUUID
 	CreateTimestamp time.Time
@@ -133,6 +134,7 @@ func (q *Queries) CreateUserLanguagePreference(ctx context.Context, arg CreateUs
 	result, err := q.db.Exec(ctx, createUserLanguagePreference,
 		arg.UserID,
 		arg.LanguageTag,
+		arg.PrefOrder,
 		arg.CreateAppID,
 		arg.CreateUserID,
 		arg.CreateTimestamp,
@@ -222,6 +224,64 @@ func (q *Queries) DeleteUserLanguagePreferences(ctx context.Context, userID uuid
 	return result.RowsAffected(), nil
 }
 
+const deleteUsersOrg = `-- name: DeleteUsersOrg :execrows
+DELETE FROM users_org
+WHERE org_id = $1
+  AND user_id = $2
+`
+
+type DeleteUsersOrgParams struct {
+	OrgID  uuid.UUID
+	UserID uuid.UUID
+}
+
+func (q *Queries) DeleteUsersOrg(ctx context.Context, arg DeleteUsersOrgParams) (int64, error) {
+	result, err := q.db.Exec(ctx, deleteUsersOrg, arg.OrgID, arg.UserID)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
+
+const deleteUsersOrgsByOrg = `-- name: DeleteUsersOrgsByOrg :execrows
+DELETE FROM users_org
+WHERE org_id = $1
+`
+
+func (q *Queries) DeleteUsersOrgsByOrg(ctx context.Context, orgID uuid.UUID) (int64, error) {
+	result, err := q.db.Exec(ctx, deleteUsersOrgsByOrg, orgID)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
+
+const findOrgIDsByUser = `-- name: FindOrgIDsByUser :many
+SELECT org_id
+FROM users_org
+WHERE user_id = $1
+`
+
+func (q *Queries) FindOrgIDsByUser(ctx context.Context, userID uuid.UUID) ([]uuid.UUID, error) {
+	rows, err := q.db.Query(ctx, findOrgIDsByUser, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []uuid.UUID
+	for rows.Next() {
+		var org_id uuid.UUID
+		if err := rows.Scan(&org_id); err != nil {
+			return nil, err
+		}
+		items = append(items, org_id)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
 const findPersonByUserExternalID = `-- name: FindPersonByUserExternalID :one
 SELECT p.person_id,
        p.person_extl_id,
@@ -365,7 +425,7 @@ func (q *Queries) FindPersonByUserID(ctx context.Context, userID uuid.UUID) (Fin
 }
 
 const findUserByExternalID = `-- name: FindUserByExternalID :one
-SELECT user_id, user_extl_id, person_id, name_prefix, first_name, middle_name, last_name, name_suffix, nickname, email, company_name, company_dept, job_title, birth_date, birth_year, birth_month, birth_day, create_app_id, create_user_id, create_timestamp, update_app_id, update_user_id, update_timestamp FROM users
+SELECT user_id, user_extl_id, person_id, name_prefix, first_name, middle_name, last_name, name_suffix, nickname, email, company_name, company_dept, job_title, birth_date, birth_year, birth_month, birth_day, create_app_id, create_user_id, create_timestamp, update_app_id, update_user_id, update_timestamp, active, suspended_reason, suspended_timestamp FROM users
 WHERE user_extl_id = $1
 `
 
@@ -400,8 +460,130 @@ func (q *Queries) FindUserByExternalID(ctx context.Context, userExtlID string) (
 	return i, err
 }
 
+const findUserByExternalIDWithAudit = `-- name: FindUserByExternalIDWithAudit :one
+SELECT u.user_id,
+       u.user_extl_id,
+       u.name_prefix,
+       u.first_name,
+       u.middle_name,
+       u.last_name,
+       u.name_suffix,
+       u.nickname,
+       u.email,
+       u.company_name,
+       u.company_dept,
+       u.job_title,
+       u.active,
+       u.suspended_reason,
+       u.suspended_timestamp,
+       u.create_app_id,
+       a.org_id           create_app_org_id,
+       a.app_extl_id      create_app_extl_id,
+       a.app_name         create_app_name,
+       a.app_description  create_app_description,
+       u.create_user_id,
+       cu.first_name      create_user_first_name,
+       cu.last_name       create_user_last_name,
+       u.create_timestamp,
+       u.update_app_id,
+       a2.org_id          update_app_org_id,
+       a2.app_extl_id     update_app_extl_id,
+       a2.app_name        update_app_name,
+       a2.app_description update_app_description,
+       u.update_user_id,
+       uu.first_name      update_user_first_name,
+       uu.last_name       update_user_last_name,
+       u.update_timestamp
+FROM users u
+         INNER JOIN app a on a.app_id = u.create_app_id
+         INNER JOIN app a2 on a2.app_id = u.update_app_id
+         LEFT JOIN users cu on cu.user_id = u.create_user_id
+         LEFT JOIN users uu on uu.user_id = u.update_user_id
+WHERE u.user_extl_id = $1
+`
+
+type FindUserByExternalIDWithAuditRow struct {
+	UserID               uuid.UUID
+	UserExtlID           string
+	NamePrefix           sql.NullString
+	FirstName            string
+	MiddleName           sql.NullString
+	LastName             string
+	NameSuffix           sql.NullString
+	Nickname             sql.NullString
+	Email                sql.NullString
+	CompanyName          sql.NullString
+	CompanyDept          sql.NullString
+	JobTitle             sql.NullString
+	Active               bool
+	SuspendedReason      sql.NullString
+	SuspendedTimestamp   sql.NullTime
+	CreateAppID          uuid.UUID
+	CreateAppOrgID       uuid.UUID
+	CreateAppExtlID      string
+	CreateAppName        string
+	CreateAppDescription string
+	CreateUserID         uuid.NullUUID
+	CreateUserFirstName  sql.NullString
+	CreateUserLastName   sql.NullString
+	CreateTimestamp      time.Time
+	UpdateAppID          uuid.UUID
+	UpdateAppOrgID       uuid.UUID
+	UpdateAppExtlID      string
+	UpdateAppName        string
+	UpdateAppDescription string
+	UpdateUserID         uuid.NullUUID
+	UpdateUserFirstName  sql.NullString
+	UpdateUserLastName   sql.NullString
+	UpdateTimestamp      time.Time
+}
+
+func (q *Queries) FindUserByExternalIDWithAudit(ctx context.Context, userExtlID string) (FindUserByExternalIDWithAuditRow, error) {
+	row := q.db.QueryRow(ctx, findUserByExternalIDWithAudit, userExtlID)
+	var i FindUserByExternalIDWithAuditRow
+	err := row.Scan(
+		&i.UserID,
+		&i.UserExtlID,
+		&i.NamePrefix,
+		&i.FirstName,
+		&i.MiddleName,
+		&i.LastName,
+		&i.NameSuffix,
+		&i.Nickname,
+		&i.Email,
+		&i.CompanyName,
+		&i.CompanyDept,
+		&i.JobTitle,
+		&i.Active,
+		&i.SuspendedReason,
+		&i.SuspendedTimestamp,
+		&i.CreateAppID,
+		&i.CreateAppOrgID,
+		&i.CreateAppExtlID,
+		&i.CreateAppName,
+		&i.CreateAppDescription,
+		&i.CreateUserID,
+		&i.CreateUserFirstName,
+		&i.CreateUserLastName,
+		&i.CreateTimestamp,
+		&i.UpdateAppID,
+		&i.UpdateAppOrgID,
+		&i.UpdateAppExtlID,
+		&i.UpdateAppName,
+		&i.UpdateAppDescription,
+		&i.UpdateUserID,
+		&i.UpdateUserFirstName,
+		&i.UpdateUserLastName,
+		&i.UpdateTimestamp,
+		&i.Active,
+		&i.SuspendedReason,
+		&i.SuspendedTimestamp,
+	)
+	return i, err
+}
+
 const findUserByID = `-- name: FindUserByID :one
-SELECT user_id, user_extl_id, person_id, name_prefix, first_name, middle_name, last_name, name_suffix, nickname, email, company_name, company_dept, job_title, birth_date, birth_year, birth_month, birth_day, create_app_id, create_user_id, create_timestamp, update_app_id, update_user_id, update_timestamp FROM users
+SELECT user_id, user_extl_id, person_id, name_prefix, first_name, middle_name, last_name, name_suffix, nickname, email, company_name, company_dept, job_title, birth_date, birth_year, birth_month, birth_day, create_app_id, create_user_id, create_timestamp, update_app_id, update_user_id, update_timestamp, active, suspended_reason, suspended_timestamp FROM users
 WHERE user_id = $1
 `
 
@@ -432,14 +614,18 @@ func (q *Queries) FindUserByID(ctx context.Context, userID uuid.UUID) (User, err
 		&i.UpdateAppID,
 		&i.UpdateUserID,
 		&i.UpdateTimestamp,
+		&i.Active,
+		&i.SuspendedReason,
+		&i.SuspendedTimestamp,
 	)
 	return i, err
 }
 
 const findUserLanguagePreferencesByUserID = `-- name: FindUserLanguagePreferencesByUserID :many
-SELECT user_id, language_tag, create_app_id, create_user_id, create_timestamp, update_app_id, update_user_id, update_timestamp
+SELECT user_id, language_tag, pref_order, create_app_id, create_user_id, create_timestamp, update_app_id, update_user_id, update_timestamp
 FROM users_lang_prefs
 WHERE user_id = $1
+ORDER BY pref_order
 `
 
 func (q *Queries) FindUserLanguagePreferencesByUserID(ctx context.Context, userID uuid.UUID) ([]UsersLangPref, error) {
@@ -454,6 +640,7 @@ func (q *Queries) FindUserLanguagePreferencesByUserID(ctx context.Context, userI
 		if err := rows.Scan(
 			&i.UserID,
 			&i.LanguageTag,
+			&i.PrefOrder,
 			&i.CreateAppID,
 			&i.CreateUserID,
 			&i.CreateTimestamp,
@@ -470,3 +657,258 @@ func (q *Queries) FindUserLanguagePreferencesByUserID(ctx context.Context, userI
 	}
 	return items, nil
 }
+
+const findUsersByOrgWithAudit = `-- name: FindUsersByOrgWithAudit :many
+SELECT u.user_id,
+       u.user_extl_id,
+       u.name_prefix,
+       u.first_name,
+       u.middle_name,
+       u.last_name,
+       u.name_suffix,
+       u.nickname,
+       u.email,
+       u.company_name,
+       u.company_dept,
+       u.job_title,
+       u.active,
+       u.suspended_reason,
+       u.suspended_timestamp,
+       u.create_app_id,
+       a.org_id           create_app_org_id,
+       a.app_extl_id      create_app_extl_id,
+       a.app_name         create_app_name,
+       a.app_description  create_app_description,
+       u.create_user_id,
+       cu.first_name      create_user_first_name,
+       cu.last_name       create_user_last_name,
+       u.create_timestamp,
+       u.update_app_id,
+       a2.org_id          update_app_org_id,
+       a2.app_extl_id     update_app_extl_id,
+       a2.app_name        update_app_name,
+       a2.app_description update_app_description,
+       u.update_user_id,
+       uu.first_name      update_user_first_name,
+       uu.last_name       update_user_last_name,
+       u.update_timestamp
+FROM users u
+         INNER JOIN users_org uo on uo.user_id = u.user_id
+         INNER JOIN app a on a.app_id = u.create_app_id
+         INNER JOIN app a2 on a2.app_id = u.update_app_id
+         LEFT JOIN users cu on cu.user_id = u.create_user_id
+         LEFT JOIN users uu on uu.user_id = u.update_user_id
+WHERE uo.org_id = $1
+ORDER BY u.last_name, u.first_name
+`
+
+type FindUsersByOrgWithAuditRow struct {
+	UserID               uuid.UUID
+	UserExtlID           string
+	NamePrefix           sql.NullString
+	FirstName            string
+	MiddleName           sql.NullString
+	LastName             string
+	NameSuffix           sql.NullString
+	Nickname             sql.NullString
+	Email                sql.NullString
+	CompanyName          sql.NullString
+	CompanyDept          sql.NullString
+	JobTitle             sql.NullString
+	Active               bool
+	SuspendedReason      sql.NullString
+	SuspendedTimestamp   sql.NullTime
+	CreateAppID          uuid.UUID
+	CreateAppOrgID       uuid.UUID
+	CreateAppExtlID      string
+	CreateAppName        string
+	CreateAppDescription string
+	CreateUserID         uuid.NullUUID
+	CreateUserFirstName  sql.NullString
+	CreateUserLastName   sql.NullString
+	CreateTimestamp      time.Time
+	UpdateAppID          uuid.UUID
+	UpdateAppOrgID       uuid.UUID
+	UpdateAppExtlID      string
+	UpdateAppName        string
+	UpdateAppDescription string
+	UpdateUserID         uuid.NullUUID
+	UpdateUserFirstName  sql.NullString
+	UpdateUserLastName   sql.NullString
+	UpdateTimestamp      time.Time
+}
+
+func (q *Queries) FindUsersByOrgWithAudit(ctx context.Context, orgID uuid.UUID) ([]FindUsersByOrgWithAuditRow, error) {
+	rows, err := q.db.Query(ctx, findUsersByOrgWithAudit, orgID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []FindUsersByOrgWithAuditRow
+	for rows.Next() {
+		var i FindUsersByOrgWithAuditRow
+		if err := rows.Scan(
+			&i.UserID,
+			&i.UserExtlID,
+			&i.NamePrefix,
+			&i.FirstName,
+			&i.MiddleName,
+			&i.LastName,
+			&i.NameSuffix,
+			&i.Nickname,
+			&i.Email,
+			&i.CompanyName,
+			&i.CompanyDept,
+			&i.JobTitle,
+			&i.Active,
+			&i.SuspendedReason,
+			&i.SuspendedTimestamp,
+			&i.CreateAppID,
+			&i.CreateAppOrgID,
+			&i.CreateAppExtlID,
+			&i.CreateAppName,
+			&i.CreateAppDescription,
+			&i.CreateUserID,
+			&i.CreateUserFirstName,
+			&i.CreateUserLastName,
+			&i.CreateTimestamp,
+			&i.UpdateAppID,
+			&i.UpdateAppOrgID,
+			&i.UpdateAppExtlID,
+			&i.UpdateAppName,
+			&i.UpdateAppDescription,
+			&i.UpdateUserID,
+			&i.UpdateUserFirstName,
+			&i.UpdateUserLastName,
+			&i.UpdateTimestamp,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const reinstateUser = `-- name: ReinstateUser :execrows
+UPDATE users
+SET active              = true,
+    suspended_reason    = null,
+    suspended_timestamp = null,
+    update_app_id       = $1,
+    update_user_id      = $2,
+    update_timestamp    = $3
+WHERE user_id = $4
+`
+
+type ReinstateUserParams struct {
+	UpdateAppID     uuid.UUID
+	UpdateUserID    uuid.NullUUID
+	UpdateTimestamp time.Time
+	UserID          uuid.UUID
+}
+
+func (q *Queries) ReinstateUser(ctx context.Context, arg ReinstateUserParams) (int64, error) {
+	result, err := q.db.Exec(ctx, reinstateUser,
+		arg.UpdateAppID,
+		arg.UpdateUserID,
+		arg.UpdateTimestamp,
+		arg.UserID,
+	)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
+
+const suspendUser = `-- name: SuspendUser :execrows
+UPDATE users
+SET active              = false,
+    suspended_reason    = $1,
+    suspended_timestamp = $2,
+    update_app_id       = $3,
+    update_user_id      = $4,
+    update_timestamp    = $5
+WHERE user_id = $6
+`
+
+type SuspendUserParams struct {
+	SuspendedReason    sql.NullString
+	SuspendedTimestamp sql.NullTime
+	UpdateAppID        uuid.UUID
+	UpdateUserID       uuid.NullUUID
+	UpdateTimestamp    time.Time
+	UserID             uuid.UUID
+}
+
+func (q *Queries) SuspendUser(ctx context.Context, arg SuspendUserParams) (int64, error) {
+	result, err := q.db.Exec(ctx, suspendUser,
+		arg.SuspendedReason,
+		arg.SuspendedTimestamp,
+		arg.UpdateAppID,
+		arg.UpdateUserID,
+		arg.UpdateTimestamp,
+		arg.UserID,
+	)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
+
+const updateUser = `-- name: UpdateUser :execrows
+UPDATE users
+SET name_prefix      = $1,
+    first_name       = $2,
+    middle_name      = $3,
+    last_name        = $4,
+    name_suffix      = $5,
+    nickname         = $6,
+    company_name     = $7,
+    company_dept     = $8,
+    job_title        = $9,
+    update_app_id    = $10,
+    update_user_id   = $11,
+    update_timestamp = $12
+WHERE user_id = $13
+`
+
+type UpdateUserParams struct {
+	NamePrefix      sql.NullString
+	FirstName       string
+	MiddleName      sql.NullString
+	LastName        string
+	NameSuffix      sql.NullString
+	Nickname        sql.NullString
+	CompanyName     sql.NullString
+	CompanyDept     sql.NullString
+	JobTitle        sql.NullString
+	UpdateAppID     uuid.UUID
+	UpdateUserID    uuid.NullUUID
+	UpdateTimestamp time.Time
+	UserID          uuid.UUID
+}
+
+func (q *Queries) UpdateUser(ctx context.Context, arg UpdateUserParams) (int64, error) {
+	result, err := q.db.Exec(ctx, updateUser,
+		arg.NamePrefix,
+		arg.FirstName,
+		arg.MiddleName,
+		arg.LastName,
+		arg.NameSuffix,
+		arg.Nickname,
+		arg.CompanyName,
+		arg.CompanyDept,
+		arg.JobTitle,
+		arg.UpdateAppID,
+		arg.UpdateUserID,
+		arg.UpdateTimestamp,
+		arg.UserID,
+	)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
@@ -69,7 +69,8 @@ SELECT a.app_id,
 FROM app a
          INNER JOIN org o on o.org_id = a.org_id
          INNER JOIN org_kind ok on ok.org_kind_id = o.org_kind_id
-WHERE a.app_extl_id = $1;
+WHERE a.app_extl_id = $1
+  AND a.deleted_timestamp IS NULL;
 
 -- name: FindAppByExternalIDWithAudit :one
 SELECT a.org_id,
@@ -83,6 +84,8 @@ SELECT a.org_id,
        a.app_extl_id,
        a.app_name,
        a.app_description,
+       a.auth_provider_id,
+       a.auth_provider_client_id,
        a.create_app_id,
        ca.org_id          create_app_org_id,
        ca.app_extl_id     create_app_extl_id,
@@ -100,7 +103,8 @@ SELECT a.org_id,
        a.update_user_id,
        uu.first_name     update_user_first_name,
        uu.last_name      update_user_last_name,
-       a.update_timestamp
+       a.update_timestamp,
+       a.deleted_timestamp
 FROM app a
          INNER JOIN org o on o.org_id = a.org_id
          INNER JOIN org_kind ok on ok.org_kind_id = o.org_kind_id
@@ -108,7 +112,8 @@ FROM app a
          INNER JOIN app ua on ua.app_id = a.update_app_id
          LEFT JOIN users cu on cu.user_id = a.create_user_id
          LEFT JOIN users uu on uu.user_id = a.update_user_id
-WHERE a.app_extl_id = $1;
+WHERE a.app_extl_id = @app_extl_id
+  AND (@include_deleted::boolean OR a.deleted_timestamp IS NULL);
 
 -- name: FindAppByName :one
 SELECT a.app_id,
@@ -126,7 +131,8 @@ FROM app a
          INNER JOIN org o on o.org_id = a.org_id
          INNER JOIN org_kind ok on ok.org_kind_id = o.org_kind_id
 WHERE o.org_id = $1
-  AND a.app_name = $2;
+  AND a.app_name = $2
+  AND a.deleted_timestamp IS NULL;
 
 -- name: FindAppByProviderClientID :one
 SELECT a.app_id,
@@ -143,7 +149,8 @@ SELECT a.app_id,
 FROM app a
          INNER JOIN org o on o.org_id = a.org_id
          INNER JOIN org_kind ok on ok.org_kind_id = o.org_kind_id
-WHERE a.auth_provider_client_id = $1;
+WHERE a.auth_provider_client_id = $1
+  AND a.deleted_timestamp IS NULL;
 
 -- name: FindApps :many
 SELECT * FROM app
@@ -191,6 +198,50 @@ FROM app a
          LEFT JOIN users cu on cu.user_id = a.create_user_id
          LEFT JOIN users uu on uu.user_id = a.update_user_id;
 
+-- name: FindAppsWithAuditByOrg :many
+SELECT a.org_id,
+       o.org_extl_id,
+       o.org_name,
+       o.org_description,
+       ok.org_kind_id,
+       ok.org_kind_extl_id,
+       ok.org_kind_desc,
+       a.app_id,
+       a.app_extl_id,
+       a.app_name,
+       a.app_description,
+       a.auth_provider_id,
+       a.auth_provider_client_id,
+       a.create_app_id,
+       ca.org_id          create_app_org_id,
+       ca.app_extl_id     create_app_extl_id,
+       ca.app_name        create_app_name,
+       ca.app_description create_app_description,
+       a.create_user_id,
+       cu.first_name     create_user_first_name,
+       cu.last_name      create_user_last_name,
+       a.create_timestamp,
+       a.update_app_id,
+       ua.org_id          update_app_org_id,
+       ua.app_extl_id     update_app_extl_id,
+       ua.app_name        update_app_name,
+       ua.app_description update_app_description,
+       a.update_user_id,
+       uu.first_name     update_user_first_name,
+       uu.last_name      update_user_last_name,
+       a.update_timestamp,
+       a.deleted_timestamp
+FROM app a
+         INNER JOIN org o on o.org_id = a.org_id
+         INNER JOIN org_kind ok on ok.org_kind_id = o.org_kind_id
+         INNER JOIN app ca on ca.app_id = a.create_app_id
+         INNER JOIN app ua on ua.app_id = a.update_app_id
+         LEFT JOIN users cu on cu.user_id = a.create_user_id
+         LEFT JOIN users uu on uu.user_id = a.update_user_id
+WHERE a.org_id = @org_id
+  AND (@include_deleted::boolean OR a.deleted_timestamp IS NULL)
+ORDER BY a.app_name;
+
 -- name: CreateApp :execrows
 INSERT INTO app (app_id, org_id, app_extl_id, app_name, app_description,
                  auth_provider_id, auth_provider_client_id,
@@ -200,13 +251,57 @@ VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13);
 
 -- name: UpdateApp :execrows
 UPDATE app
-SET app_name        = $1,
-    app_description = $2,
-    update_app_id    = $3,
-    update_user_id   = $4,
-    update_timestamp = $5
-WHERE app_id = $6;
+SET app_name                = $1,
+    app_description         = $2,
+    auth_provider_id        = $3,
+    auth_provider_client_id = $4,
+    update_app_id           = $5,
+    update_user_id          = $6,
+    update_timestamp        = $7
+WHERE app_id = $8;
 
+-- name: SoftDeleteApp :execrows
+UPDATE app
+SET deleted_app_id    = $1,
+    deleted_user_id   = $2,
+    deleted_timestamp = $3
+WHERE app_id = $4
+  AND deleted_timestamp IS NULL;
+
+-- name: SoftDeleteAppsByOrg :execrows
+UPDATE app
+SET deleted_app_id    = $1,
+    deleted_user_id   = $2,
+    deleted_timestamp = $3
+WHERE org_id = $4
+  AND deleted_timestamp IS NULL;
+
+-- name: RestoreApp :execrows
+UPDATE app
+SET deleted_app_id    = NULL,
+    deleted_user_id   = NULL,
+    deleted_timestamp = NULL,
+    update_app_id     = $1,
+    update_user_id    = $2,
+    update_timestamp  = $3
+WHERE app_id = $4
+  AND deleted_timestamp IS NOT NULL;
+
+-- name: RestoreAppsByOrg :execrows
+UPDATE app
+SET deleted_app_id    = NULL,
+    deleted_user_id   = NULL,
+    deleted_timestamp = NULL,
+    update_app_id     = $1,
+    update_user_id    = $2,
+    update_timestamp  = $3
+WHERE org_id = $4
+  AND deleted_timestamp = $5;
+
+-- name: FindDeletedApps :many
+SELECT app_id, app_extl_id
+FROM app
+WHERE deleted_timestamp < $1;
 
 -- name: DeleteApp :execrows
 DELETE FROM app
@@ -225,9 +320,9 @@ SELECT * FROM app_api_key
 WHERE app_id = $1;
 
 -- name: CreateAppAPIKey :execrows
-INSERT INTO app_api_key (api_key, app_id, deactv_date, create_app_id, create_user_id,
+INSERT INTO app_api_key (api_key, api_key_prefix, app_id, deactv_date, create_app_id, create_user_id,
                          create_timestamp, update_app_id, update_user_id, update_timestamp)
-VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9);
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10);
 
 -- name: FindAppAPIKeysByAppExtlID :many
 select a.app_id,
@@ -239,8 +334,39 @@ select a.app_id,
        o.org_name,
        o.org_description,
        aak.api_key,
-       aak.deactv_date
+       aak.api_key_prefix,
+       aak.deactv_date,
+       aak.last_used_timestamp
 from app a
          inner join org o on o.org_id = a.org_id
          inner join app_api_key aak on a.app_id = aak.app_id
-where a.app_extl_id = $1;
\ No newline at end of file
+where a.app_extl_id = $1
+  and aak.api_key_prefix = $2
+  and a.deleted_timestamp is null
+  and o.deleted_timestamp is null;
+
+-- name: FindLegacyAppAPIKeys :many
+SELECT api_key, app_id
+FROM app_api_key
+WHERE api_key_prefix IS NULL;
+
+-- name: UpdateAppAPIKeyHash :execrows
+UPDATE app_api_key
+SET api_key        = @api_key_hash,
+    api_key_prefix = @api_key_prefix
+WHERE api_key = @legacy_api_key;
+
+-- name: UpdateAppAPIKeyLastUsed :execrows
+UPDATE app_api_key
+SET last_used_timestamp = @last_used_timestamp
+WHERE api_key = @api_key
+  AND (last_used_timestamp IS NULL OR last_used_timestamp < @last_used_before);
+
+-- name: UpdateAppAPIKeysDeactvDate :execrows
+UPDATE app_api_key
+SET deactv_date      = $1,
+    update_app_id    = $2,
+    update_user_id   = $3,
+    update_timestamp = $4
+WHERE app_id = $5
+  AND deactv_date > $1;
@@ -33,37 +33,277 @@ SELECT *
 FROM role
 WHERE role_cd = $1;
 
+-- name: FindRoleByExternalID :one
+SELECT *
+FROM role
+WHERE role_extl_id = $1;
+
+-- name: FindAllRoles :many
+SELECT *
+FROM role
+ORDER BY role_cd;
+
+-- name: UpdateRole :execrows
+UPDATE role
+SET role_cd          = $1,
+    role_description = $2,
+    active           = $3,
+    update_app_id    = $4,
+    update_user_id   = $5,
+    update_timestamp = $6
+WHERE role_id = $7;
+
+-- name: DeleteRole :execrows
+DELETE FROM role
+WHERE role_id = $1;
+
 -- name: FindRolePermissionsByRoleID :many
 SELECT p.*
 FROM role_permission r
          inner join permission p on p.permission_id = r.permission_id
-WHERE r.role_id = $1;
+WHERE r.role_id = $1
+  AND r.effect = $2;
 
 
 -- name: CreateRolePermission :execrows
-insert into role_permission (role_id, permission_id, create_app_id, create_user_id, create_timestamp, update_app_id,
-                             update_user_id, update_timestamp)
-VALUES ($1, $2, $3, $4, $5, $6, $7, $8);
+insert into role_permission (role_id, permission_id, effect, create_app_id, create_user_id, create_timestamp,
+                             update_app_id, update_user_id, update_timestamp)
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9);
 
 -- name: DeleteAllPermissions4Role :execrows
 DELETE FROM role_permission
 WHERE role_id = $1;
 
+-- name: CreateRoleParent :execrows
+insert into role_parent (role_id, parent_role_id, create_app_id, create_user_id, create_timestamp, update_app_id,
+                         update_user_id, update_timestamp)
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8);
+
+-- name: DeleteRoleParent :execrows
+DELETE FROM role_parent
+WHERE role_id = $1
+  AND parent_role_id = $2;
+
+-- name: DeleteAllParents4Role :execrows
+DELETE FROM role_parent
+WHERE role_id = $1;
+
+-- name: CountRoleChildren :one
+SELECT count(*)
+FROM role_parent
+WHERE parent_role_id = $1;
+
+-- name: FindRoleParentsByRoleID :many
+SELECT r.*
+FROM role_parent rp
+         inner join role r on r.role_id = rp.parent_role_id
+WHERE rp.role_id = $1
+ORDER BY r.role_cd;
+
+-- name: FindRoleAncestorIDs :many
+WITH RECURSIVE ancestors AS (
+    SELECT rp.parent_role_id
+    FROM role_parent rp
+    WHERE rp.role_id = $1
+    UNION
+    SELECT rp.parent_role_id
+    FROM role_parent rp
+             INNER JOIN ancestors a on a.parent_role_id = rp.role_id
+)
+SELECT parent_role_id
+FROM ancestors;
+
 -- name: CreateUsersRole :execrows
-insert into users_role (user_id, role_id, org_id, create_app_id, create_user_id, create_timestamp, update_app_id,
-                        update_user_id, update_timestamp)
-VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9);
+insert into users_role (user_id, role_id, org_id, valid_from, valid_until, create_app_id, create_user_id,
+                        create_timestamp, update_app_id, update_user_id, update_timestamp)
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11);
 
--- name: IsAuthorized :one
-SELECT ur.user_id
+-- name: UpdateUsersRoleValidity :execrows
+UPDATE users_role
+SET valid_from       = $4,
+    valid_until      = $5,
+    update_app_id    = $6,
+    update_user_id   = $7,
+    update_timestamp = $8
+WHERE user_id = $1
+  AND role_id = $2
+  AND org_id = $3;
+
+-- name: DeleteUsersRole :execrows
+DELETE FROM users_role
+WHERE user_id = $1
+  AND role_id = $2
+  AND org_id = $3;
+
+-- name: DeleteUsersRolesByOrg :execrows
+DELETE FROM users_role
+WHERE org_id = $1;
+
+-- name: DeleteUsersRolesByOrgUser :execrows
+DELETE FROM users_role
+WHERE org_id = $1
+  AND user_id = $2;
+
+-- name: FindExpiredUsersRoles :many
+SELECT o.org_extl_id, u.user_extl_id, u.email, u.first_name, u.last_name, r.role_cd, ur.valid_from, ur.valid_until
 FROM users_role ur
-         INNER JOIN role_permission rp on rp.role_id = ur.role_id
+         INNER JOIN org o on o.org_id = ur.org_id
+         INNER JOIN users u on u.user_id = ur.user_id
+         INNER JOIN role r on r.role_id = ur.role_id
+WHERE ur.valid_until <= now()
+ORDER BY o.org_extl_id, r.role_cd, ur.valid_until;
+
+-- name: DeleteExpiredUsersRoles :execrows
+DELETE FROM users_role
+WHERE valid_until <= now();
+
+-- name: CountUsersRolesByRoleID :one
+SELECT count(*)
+FROM users_role
+WHERE role_id = $1;
+
+-- name: HasAnyOrgRole :one
+WITH RECURSIVE org_lineage AS (
+    SELECT o.org_id, o.parent_org_id
+    FROM org o
+    WHERE o.org_id = @org_id
+    UNION
+    SELECT po.org_id, po.parent_org_id
+    FROM org po
+             INNER JOIN org_lineage l on l.parent_org_id = po.org_id
+),
+               user_roles AS (
+    SELECT ur.role_id
+    FROM users_role ur
+             INNER JOIN role r on r.role_id = ur.role_id
+    WHERE r.active = true
+      AND ur.user_id = @user_id
+      AND ur.org_id IN (SELECT org_id FROM org_lineage)
+      AND (ur.valid_from IS NULL OR ur.valid_from <= now())
+      AND (ur.valid_until IS NULL OR ur.valid_until > now())
+    UNION
+    SELECT rp.parent_role_id
+    FROM role_parent rp
+             INNER JOIN user_roles u on u.role_id = rp.role_id
+             INNER JOIN role r on r.role_id = rp.parent_role_id
+    WHERE r.active = true
+)
+SELECT EXISTS(SELECT 1
+              FROM user_roles u
+                       INNER JOIN role r on r.role_id = u.role_id
+              WHERE r.role_cd = ANY (@role_cds::varchar[]));
+
+-- name: IsAuthorized :one
+WITH RECURSIVE org_lineage AS (
+    SELECT o.org_id, o.parent_org_id
+    FROM org o
+    WHERE o.org_id = $4
+    UNION
+    SELECT po.org_id, po.parent_org_id
+    FROM org po
+             INNER JOIN org_lineage l on l.parent_org_id = po.org_id
+),
+               user_roles AS (
+    SELECT ur.user_id, ur.role_id
+    FROM users_role ur
+             INNER JOIN role r on r.role_id = ur.role_id
+    WHERE r.active = true
+      AND ur.user_id = $3
+      AND ur.org_id IN (SELECT org_id FROM org_lineage)
+      AND (ur.valid_from IS NULL OR ur.valid_from <= now())
+      AND (ur.valid_until IS NULL OR ur.valid_until > now())
+    UNION
+    SELECT u.user_id, rp.parent_role_id
+    FROM role_parent rp
+             INNER JOIN user_roles u on u.role_id = rp.role_id
+             INNER JOIN role r on r.role_id = rp.parent_role_id
+    WHERE r.active = true
+)
+SELECT u.user_id, r.role_cd, p.resource, p.operation, rp.effect
+FROM user_roles u
+         INNER JOIN role r on r.role_id = u.role_id
+         INNER JOIN role_permission rp on rp.role_id = u.role_id
          INNER JOIN permission p on p.permission_id = rp.permission_id
 WHERE p.active = true
-  AND p.resource = $1
-  AND p.operation = $2
-  AND ur.user_id = $3
-  AND ur.org_id = $4;
+  AND (p.operation = $2 OR p.operation = '*')
+  AND (p.resource = $1
+    OR p.resource = '*'
+    OR (right(p.resource, 2) = '/*'
+        AND ($1 = left(p.resource, -2) OR starts_with($1, left(p.resource, -1)))))
+ORDER BY rp.effect = 'deny' DESC, strpos(p.resource, '*') = 0 DESC, length(p.resource) DESC, p.operation <> '*' DESC
+LIMIT 1;
+
+-- name: FindEffectivePermissions :many
+WITH RECURSIVE org_lineage AS (
+    SELECT o.org_id, o.parent_org_id
+    FROM org o
+    WHERE o.org_id = $2
+    UNION
+    SELECT po.org_id, po.parent_org_id
+    FROM org po
+             INNER JOIN org_lineage l on l.parent_org_id = po.org_id
+),
+               user_roles AS (
+    SELECT ur.role_id
+    FROM users_role ur
+             INNER JOIN role r on r.role_id = ur.role_id
+    WHERE r.active = true
+      AND ur.user_id = $1
+      AND ur.org_id IN (SELECT org_id FROM org_lineage)
+      AND (ur.valid_from IS NULL OR ur.valid_from <= now())
+      AND (ur.valid_until IS NULL OR ur.valid_until > now())
+    UNION
+    SELECT rp.parent_role_id
+    FROM role_parent rp
+             INNER JOIN user_roles u on u.role_id = rp.role_id
+             INNER JOIN role r on r.role_id = rp.parent_role_id
+    WHERE r.active = true
+)
+SELECT DISTINCT ON (p.resource, p.operation) p.resource, p.operation, r.role_cd, rp.effect
+FROM user_roles u
+         INNER JOIN role r on r.role_id = u.role_id
+         INNER JOIN role_permission rp on rp.role_id = u.role_id
+         INNER JOIN permission p on p.permission_id = rp.permission_id
+WHERE p.active = true
+ORDER BY p.resource, p.operation, rp.effect = 'deny' DESC, r.role_cd;
+
+-- name: FindUserOrgRoleCodes :many
+WITH RECURSIVE org_lineage AS (
+    SELECT o.org_id, o.parent_org_id
+    FROM org o
+    WHERE o.org_id = $2
+    UNION
+    SELECT po.org_id, po.parent_org_id
+    FROM org po
+             INNER JOIN org_lineage l on l.parent_org_id = po.org_id
+),
+               user_roles AS (
+    SELECT ur.role_id
+    FROM users_role ur
+             INNER JOIN role r on r.role_id = ur.role_id
+    WHERE r.active = true
+      AND ur.user_id = $1
+      AND ur.org_id IN (SELECT org_id FROM org_lineage)
+      AND (ur.valid_from IS NULL OR ur.valid_from <= now())
+      AND (ur.valid_until IS NULL OR ur.valid_until > now())
+    UNION
+    SELECT rp.parent_role_id
+    FROM role_parent rp
+             INNER JOIN user_roles u on u.role_id = rp.role_id
+             INNER JOIN role r on r.role_id = rp.parent_role_id
+    WHERE r.active = true
+)
+SELECT r.role_cd
+FROM user_roles u
+         INNER JOIN role r on r.role_id = u.role_id
+ORDER BY r.role_cd;
+
+-- name: FindUsersRole :one
+SELECT *
+FROM users_role
+WHERE user_id = $1
+  AND role_id = $2
+  AND org_id = $3;
 
 -- name: FindUsersByOrgRole :many
 SELECT *
@@ -91,6 +331,47 @@ FROM auth
 WHERE auth_provider_id = $1
   AND auth_provider_person_id = $2;
 
+-- name: ExpireAuthsByUserID :execrows
+UPDATE auth
+SET auth_provider_access_token_expiry = $2,
+    update_app_id                     = $3,
+    update_user_id                    = $4,
+    update_timestamp                  = $5
+WHERE user_id = $1;
+
+-- name: CreateAuthzDecision :execrows
+INSERT INTO authz_decision (authz_decision_id, user_id, app_id, org_id, resource, operation, decision, role_cd,
+                            request_id, decision_timestamp)
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10);
+
+-- name: DeleteAuthzDecisions :execrows
+DELETE
+FROM authz_decision
+WHERE decision_timestamp < $1;
+
+-- name: FindAuthzDecisions :many
+SELECT d.authz_decision_id,
+       u.user_extl_id,
+       a.app_extl_id,
+       o.org_extl_id,
+       d.resource,
+       d.operation,
+       d.decision,
+       d.role_cd,
+       d.request_id,
+       d.decision_timestamp
+FROM authz_decision d
+         LEFT JOIN users u on u.user_id = d.user_id
+         LEFT JOIN app a on a.app_id = d.app_id
+         LEFT JOIN org o on o.org_id = d.org_id
+WHERE (sqlc.narg('user_id')::uuid IS NULL OR d.user_id = sqlc.narg('user_id'))
+  AND (sqlc.narg('decision')::varchar IS NULL OR d.decision = sqlc.narg('decision'))
+  AND d.decision_timestamp >= @decision_from
+  AND d.decision_timestamp < @decision_until
+  AND d.org_id = @org_id
+ORDER BY d.decision_timestamp DESC
+LIMIT @row_limit;
+
 -- name: CreateAuthProvider :execrows
 INSERT INTO auth_provider (auth_provider_id, auth_provider_cd, auth_provider_desc, create_app_id, create_user_id, create_timestamp, update_app_id, update_user_id, update_timestamp)
 VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9);
@@ -34,13 +34,15 @@ SELECT m.movie_id,
        m.update_user_id,
        uu.first_name     update_user_first_name,
        uu.last_name      update_user_last_name,
-       m.update_timestamp
+       m.update_timestamp,
+       m.deleted_timestamp
 FROM movie m
          INNER JOIN app ca on ca.app_id = m.create_app_id
          INNER JOIN app ua on ua.app_id = m.update_app_id
          LEFT JOIN users cu on cu.user_id = m.create_user_id
          LEFT JOIN users uu on uu.user_id = m.update_user_id
-WHERE m.extl_id = $1;
+WHERE m.extl_id = @extl_id
+  AND (@include_deleted::boolean OR m.deleted_timestamp IS NULL);
 
 -- name: FindMoviesByTitle :many
 SELECT m.*
@@ -73,12 +75,14 @@ SELECT m.movie_id,
        m.update_user_id,
        uu.first_name     update_user_first_name,
        uu.last_name      update_user_last_name,
-       m.update_timestamp
+       m.update_timestamp,
+       m.deleted_timestamp
 FROM movie m
          INNER JOIN app ca on ca.app_id = m.create_app_id
          INNER JOIN app ua on ua.app_id = m.update_app_id
          LEFT JOIN users cu on cu.user_id = m.create_user_id
-         LEFT JOIN users uu on uu.user_id = m.update_user_id;
+         LEFT JOIN users uu on uu.user_id = m.update_user_id
+WHERE (@include_deleted::boolean OR m.deleted_timestamp IS NULL);
 
 -- name: UpdateMovie :exec
 UPDATE movie
@@ -93,6 +97,29 @@ SET title            = $1,
     update_timestamp = $9
 WHERE movie_id = $10;
 
+-- name: SoftDeleteMovie :execrows
+UPDATE movie
+SET deleted_app_id    = $1,
+    deleted_user_id   = $2,
+    deleted_timestamp = $3
+WHERE movie_id = $4
+  AND deleted_timestamp IS NULL;
+
+-- name: RestoreMovie :execrows
+UPDATE movie
+SET deleted_app_id    = NULL,
+    deleted_user_id   = NULL,
+    deleted_timestamp = NULL,
+    update_app_id     = $1,
+    update_user_id    = $2,
+    update_timestamp  = $3
+WHERE movie_id = $4
+  AND deleted_timestamp IS NOT NULL;
+
+-- name: PurgeDeletedMovies :execrows
+DELETE FROM movie
+WHERE deleted_timestamp < $1;
+
 -- name: DeleteMovie :execrows
 DELETE FROM movie
 WHERE movie_id = $1;
@@ -54,7 +54,8 @@ SELECT o.org_id,
        ok.org_kind_desc
 FROM org o
          INNER JOIN org_kind ok on ok.org_kind_id = o.org_kind_id
-WHERE org_extl_id = $1;
+WHERE org_extl_id = $1
+  AND o.deleted_timestamp IS NULL;
 
 -- name: FindOrgByExtlIDWithAudit :one
 SELECT o.org_id,
@@ -81,14 +82,19 @@ SELECT o.org_id,
        o.update_user_id,
        uu.first_name      update_user_first_name,
        uu.last_name       update_user_last_name,
-       o.update_timestamp
+       o.update_timestamp,
+       o.parent_org_id,
+       po.org_extl_id     parent_org_extl_id,
+       o.deleted_timestamp
 FROM org o
          INNER JOIN org_kind ok on ok.org_kind_id = o.org_kind_id
          INNER JOIN app a on a.app_id = o.create_app_id
          INNER JOIN app a2 on a2.app_id = o.update_app_id
          INNER JOIN users cu on cu.user_id = o.create_user_id
          INNER JOIN users uu on uu.user_id = o.update_user_id
-WHERE o.org_extl_id = $1;
+         LEFT JOIN org po on po.org_id = o.parent_org_id
+WHERE o.org_extl_id = @org_extl_id
+  AND (@include_deleted::boolean OR o.deleted_timestamp IS NULL);
 
 -- name: FindOrgByName :one
 SELECT o.org_id,
@@ -100,7 +106,8 @@ SELECT o.org_id,
        ok.org_kind_desc
 FROM org o
          INNER JOIN org_kind ok on ok.org_kind_id = o.org_kind_id
-WHERE o.org_name = $1;
+WHERE o.org_name = $1
+  AND o.deleted_timestamp IS NULL;
 
 -- name: FindOrgByNameWithAudit :one
 SELECT o.org_id,
@@ -134,7 +141,8 @@ FROM org o
          INNER JOIN app a2 on a2.app_id = o.update_app_id
          INNER JOIN users cu on cu.user_id = o.create_user_id
          INNER JOIN users uu on uu.user_id = o.update_user_id
-WHERE o.org_name = $1;
+WHERE o.org_name = $1
+  AND o.deleted_timestamp IS NULL;
 
 -- name: FindOrgs :many
 SELECT o.org_id,
@@ -146,6 +154,7 @@ SELECT o.org_id,
        ok.org_kind_desc
 FROM org o
          INNER JOIN org_kind ok on ok.org_kind_id = o.org_kind_id
+WHERE o.deleted_timestamp IS NULL
 ORDER BY org_name;
 
 -- name: FindOrgsWithAudit :many
@@ -173,13 +182,67 @@ SELECT o.org_id,
        o.update_user_id,
        uu.first_name      update_user_first_name,
        uu.last_name       update_user_last_name,
-       o.update_timestamp
+       o.update_timestamp,
+       o.parent_org_id,
+       po.org_extl_id     parent_org_extl_id,
+       o.deleted_timestamp
 FROM org o
          INNER JOIN org_kind ok on ok.org_kind_id = o.org_kind_id
          INNER JOIN app a on a.app_id = o.create_app_id
          INNER JOIN app a2 on a2.app_id = o.update_app_id
          INNER JOIN users cu on cu.user_id = o.create_user_id
-         INNER JOIN users uu on uu.user_id = o.update_user_id;
+         INNER JOIN users uu on uu.user_id = o.update_user_id
+         LEFT JOIN org po on po.org_id = o.parent_org_id
+WHERE (@include_deleted::boolean OR o.deleted_timestamp IS NULL);
+
+-- name: FindOrgDescendantsWithAudit :many
+WITH RECURSIVE descendants AS (
+    SELECT c.org_id, 1 AS depth
+    FROM org c
+    WHERE c.parent_org_id = @parent_org_id
+    UNION ALL
+    SELECT c.org_id, d.depth + 1
+    FROM org c
+             INNER JOIN descendants d on d.org_id = c.parent_org_id
+)
+SELECT o.org_id,
+       o.org_extl_id,
+       o.org_name,
+       o.org_description,
+       ok.org_kind_id,
+       ok.org_kind_extl_id,
+       ok.org_kind_desc,
+       o.create_app_id,
+       a.org_id           create_app_org_id,
+       a.app_extl_id      create_app_extl_id,
+       a.app_name         create_app_name,
+       a.app_description  create_app_description,
+       o.create_user_id,
+       cu.first_name      create_user_first_name,
+       cu.last_name       create_user_last_name,
+       o.create_timestamp,
+       o.update_app_id,
+       a2.org_id          update_app_org_id,
+       a2.app_extl_id     update_app_extl_id,
+       a2.app_name        update_app_name,
+       a2.app_description update_app_description,
+       o.update_user_id,
+       uu.first_name      update_user_first_name,
+       uu.last_name       update_user_last_name,
+       o.update_timestamp,
+       o.parent_org_id,
+       po.org_extl_id     parent_org_extl_id,
+       o.deleted_timestamp
+FROM descendants d
+         INNER JOIN org o on o.org_id = d.org_id
+         INNER JOIN org_kind ok on ok.org_kind_id = o.org_kind_id
+         INNER JOIN app a on a.app_id = o.create_app_id
+         INNER JOIN app a2 on a2.app_id = o.update_app_id
+         INNER JOIN users cu on cu.user_id = o.create_user_id
+         INNER JOIN users uu on uu.user_id = o.update_user_id
+         LEFT JOIN org po on po.org_id = o.parent_org_id
+WHERE (@include_deleted::boolean OR o.deleted_timestamp IS NULL)
+ORDER BY d.depth, o.org_name;
 
 -- name: FindOrgsByKindExtlID :many
 SELECT o.org_id,
@@ -190,13 +253,54 @@ SELECT o.org_id,
        ok.org_kind_desc
 FROM org o
          INNER JOIN org_kind ok on ok.org_kind_id = o.org_kind_id
-WHERE ok.org_kind_extl_id = $1;
+WHERE ok.org_kind_extl_id = $1
+  AND o.deleted_timestamp IS NULL;
 
+-- name: FindUserOrgByExtlID :one
+SELECT o.org_id,
+       o.org_extl_id,
+       o.org_name,
+       o.org_description,
+       o.org_kind_id,
+       ok.org_kind_extl_id,
+       ok.org_kind_desc
+FROM org o
+         INNER JOIN org_kind ok on ok.org_kind_id = o.org_kind_id
+         INNER JOIN users_org uo on uo.org_id = o.org_id
+WHERE o.org_extl_id = $1
+  AND uo.user_id = $2
+  AND o.deleted_timestamp IS NULL;
+
+-- name: FindUserLineageOrgByExtlID :one
+WITH RECURSIVE org_lineage AS (
+    SELECT o.org_id, o.parent_org_id
+    FROM org o
+    WHERE o.org_extl_id = $1
+    UNION
+    SELECT po.org_id, po.parent_org_id
+    FROM org po
+             INNER JOIN org_lineage l on l.parent_org_id = po.org_id
+)
+SELECT o.org_id,
+       o.org_extl_id,
+       o.org_name,
+       o.org_description,
+       o.org_kind_id,
+       ok.org_kind_extl_id,
+       ok.org_kind_desc
+FROM org o
+         INNER JOIN org_kind ok on ok.org_kind_id = o.org_kind_id
+WHERE o.org_extl_id = $1
+  AND o.deleted_timestamp IS NULL
+  AND EXISTS(SELECT 1
+             FROM users_org uo
+                      INNER JOIN org_lineage l on l.org_id = uo.org_id
+             WHERE uo.user_id = $2);
 
 -- name: CreateOrg :execrows
 INSERT INTO org (org_id, org_extl_id, org_name, org_description, org_kind_id, create_app_id, create_user_id,
-                 create_timestamp, update_app_id, update_user_id, update_timestamp)
-VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11);
+                 create_timestamp, update_app_id, update_user_id, update_timestamp, parent_org_id)
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12);
 
 -- name: UpdateOrg :execrows
 UPDATE org
@@ -204,8 +308,45 @@ SET org_name         = $1,
     org_description  = $2,
     update_app_id    = $3,
     update_user_id   = $4,
-    update_timestamp = $5
-WHERE org_id = $6;
+    update_timestamp = $5,
+    parent_org_id    = $6
+WHERE org_id = $7;
+
+-- name: SoftDeleteOrg :execrows
+UPDATE org
+SET deleted_app_id    = $1,
+    deleted_user_id   = $2,
+    deleted_timestamp = $3
+WHERE org_id = $4
+  AND deleted_timestamp IS NULL;
+
+-- name: RestoreOrg :execrows
+UPDATE org
+SET deleted_app_id    = NULL,
+    deleted_user_id   = NULL,
+    deleted_timestamp = NULL,
+    update_app_id     = $1,
+    update_user_id    = $2,
+    update_timestamp  = $3
+WHERE org_id = $4
+  AND deleted_timestamp IS NOT NULL;
+
+-- name: FindDeletedOrgs :many
+WITH RECURSIVE org_depth AS (
+    SELECT o.org_id, 0 AS depth
+    FROM org o
+    WHERE o.parent_org_id IS NULL
+    UNION ALL
+    SELECT c.org_id, d.depth + 1
+    FROM org c
+             INNER JOIN org_depth d on d.org_id = c.parent_org_id
+)
+SELECT o.org_id,
+       o.org_extl_id
+FROM org o
+         INNER JOIN org_depth d on d.org_id = o.org_id
+WHERE o.deleted_timestamp < $1
+ORDER BY d.depth DESC;
 
 -- name: DeleteOrg :execrows
 DELETE
@@ -218,7 +359,8 @@ WHERE org_id = $1;
 
 -- name: FindOrgKinds :many
 SELECT *
-FROM org_kind;
+FROM org_kind
+ORDER BY org_kind_extl_id;
 
 -- name: FindOrgKindByExtlID :one
 SELECT *
@@ -229,3 +371,132 @@ WHERE org_kind_extl_id = $1;
 insert into org_kind (org_kind_id, org_kind_extl_id, org_kind_desc, create_app_id, create_user_id, create_timestamp,
                       update_app_id, update_user_id, update_timestamp)
 values ($1, $2, $3, $4, $5, $6, $7, $8, $9);
+
+-- name: UpdateOrgKind :execrows
+UPDATE org_kind
+SET org_kind_desc    = $1,
+    update_app_id    = $2,
+    update_user_id   = $3,
+    update_timestamp = $4
+WHERE org_kind_id = $5;
+
+-- name: DeleteOrgKind :execrows
+DELETE FROM org_kind
+WHERE org_kind_id = $1;
+
+-- name: CountOrgsByKind :one
+SELECT count(*)
+FROM org
+WHERE org_kind_id = $1;
+
+-- name: CreateOrgKindRole :execrows
+insert into org_kind_role (org_kind_id, role_id, create_app_id, create_user_id, create_timestamp, update_app_id,
+                           update_user_id, update_timestamp)
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8);
+
+-- name: DeleteOrgKindRoles :execrows
+DELETE FROM org_kind_role
+WHERE org_kind_id = $1;
+
+-- name: FindOrgKindRolesByKindID :many
+SELECT r.*
+FROM org_kind_role okr
+         inner join role r on r.role_id = okr.role_id
+WHERE okr.org_kind_id = $1
+ORDER BY r.role_cd;
+
+-- name: CountOrgKindRolesByRoleID :one
+SELECT count(*)
+FROM org_kind_role
+WHERE role_id = $1;
+
+-- name: CreateOrgInvitation :execrows
+INSERT INTO org_invitation (org_invitation_id, org_invitation_extl_id, org_id, role_id, email, expiry_timestamp,
+                            last_sent_timestamp, create_app_id, create_user_id, create_timestamp, update_app_id,
+                            update_user_id, update_timestamp)
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13);
+
+-- name: FindOrgInvitationByExternalID :one
+SELECT i.org_invitation_id,
+       i.org_invitation_extl_id,
+       i.org_id,
+       o.org_extl_id,
+       i.role_id,
+       r.role_cd,
+       i.email,
+       i.expiry_timestamp,
+       i.send_count,
+       i.last_sent_timestamp,
+       i.accepted_user_id,
+       u.user_extl_id accepted_user_extl_id,
+       i.accepted_timestamp,
+       i.revoked_timestamp,
+       i.create_timestamp,
+       i.update_timestamp
+FROM org_invitation i
+         INNER JOIN org o on o.org_id = i.org_id
+         INNER JOIN role r on r.role_id = i.role_id
+         LEFT JOIN users u on u.user_id = i.accepted_user_id
+WHERE i.org_invitation_extl_id = $1;
+
+-- name: FindOrgInvitationsByOrgID :many
+SELECT i.org_invitation_id,
+       i.org_invitation_extl_id,
+       i.org_id,
+       o.org_extl_id,
+       i.role_id,
+       r.role_cd,
+       i.email,
+       i.expiry_timestamp,
+       i.send_count,
+       i.last_sent_timestamp,
+       i.accepted_user_id,
+       u.user_extl_id accepted_user_extl_id,
+       i.accepted_timestamp,
+       i.revoked_timestamp,
+       i.create_timestamp,
+       i.update_timestamp
+FROM org_invitation i
+         INNER JOIN org o on o.org_id = i.org_id
+         INNER JOIN role r on r.role_id = i.role_id
+         LEFT JOIN users u on u.user_id = i.accepted_user_id
+WHERE i.org_id = $1
+ORDER BY i.create_timestamp DESC;
+
+-- name: ResendOrgInvitation :execrows
+UPDATE org_invitation
+SET expiry_timestamp    = $1,
+    send_count          = send_count + 1,
+    last_sent_timestamp = $2,
+    update_app_id       = $3,
+    update_user_id      = $4,
+    update_timestamp    = $5
+WHERE org_invitation_id = $6
+  AND accepted_timestamp IS NULL
+  AND revoked_timestamp IS NULL;
+
+-- name: RevokeOrgInvitation :execrows
+UPDATE org_invitation
+SET revoked_timestamp = $1,
+    update_app_id     = $2,
+    update_user_id    = $3,
+    update_timestamp  = $4
+WHERE org_invitation_id = $5
+  AND accepted_timestamp IS NULL
+  AND revoked_timestamp IS NULL;
+
+-- name: DeleteOrgInvitationsByOrg :execrows
+DELETE
+FROM org_invitation
+WHERE org_id = $1;
+
+-- name: AcceptOrgInvitation :execrows
+UPDATE org_invitation
+SET accepted_user_id   = $1,
+    accepted_timestamp = $2,
+    update_app_id      = $3,
+    update_user_id     = $4,
+    update_timestamp   = $5
+WHERE org_invitation_id = $6
+  AND accepted_timestamp IS NULL
+  AND revoked_timestamp IS NULL;
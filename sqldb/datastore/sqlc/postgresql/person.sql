@@ -59,6 +59,20 @@ INSERT INTO users_org (users_org_id, org_id, user_id,
                        create_app_id, create_user_id, create_timestamp, update_app_id, update_user_id, update_timestamp)
 VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9);
 
+-- name: DeleteUsersOrg :execrows
+DELETE FROM users_org
+WHERE org_id = $1
+  AND user_id = $2;
+
+-- name: DeleteUsersOrgsByOrg :execrows
+DELETE FROM users_org
+WHERE org_id = $1;
+
+-- name: FindOrgIDsByUser :many
+SELECT org_id
+FROM users_org
+WHERE user_id = $1;
+
 -- name: CreateUser :execrows
 INSERT INTO users (user_id, user_extl_id, person_id, name_prefix, first_name, middle_name, last_name, name_suffix,
                    nickname, email, company_name, company_dept, job_title, birth_date, birth_year, birth_month, birth_day,
@@ -74,6 +88,126 @@ WHERE user_id = $1;
 SELECT * FROM users
 WHERE user_extl_id = $1;
 
+-- name: FindUserByExternalIDWithAudit :one
+SELECT u.user_id,
+       u.user_extl_id,
+       u.name_prefix,
+       u.first_name,
+       u.middle_name,
+       u.last_name,
+       u.name_suffix,
+       u.nickname,
+       u.email,
+       u.company_name,
+       u.company_dept,
+       u.job_title,
+       u.active,
+       u.suspended_reason,
+       u.suspended_timestamp,
+       u.create_app_id,
+       a.org_id           create_app_org_id,
+       a.app_extl_id      create_app_extl_id,
+       a.app_name         create_app_name,
+       a.app_description  create_app_description,
+       u.create_user_id,
+       cu.first_name      create_user_first_name,
+       cu.last_name       create_user_last_name,
+       u.create_timestamp,
+       u.update_app_id,
+       a2.org_id          update_app_org_id,
+       a2.app_extl_id     update_app_extl_id,
+       a2.app_name        update_app_name,
+       a2.app_description update_app_description,
+       u.update_user_id,
+       uu.first_name      update_user_first_name,
+       uu.last_name       update_user_last_name,
+       u.update_timestamp
+FROM users u
+         INNER JOIN app a on a.app_id = u.create_app_id
+         INNER JOIN app a2 on a2.app_id = u.update_app_id
+         LEFT JOIN users cu on cu.user_id = u.create_user_id
+         LEFT JOIN users uu on uu.user_id = u.update_user_id
+WHERE u.user_extl_id = $1;
+
+-- name: FindUsersByOrgWithAudit :many
+SELECT u.user_id,
+       u.user_extl_id,
+       u.name_prefix,
+       u.first_name,
+       u.middle_name,
+       u.last_name,
+       u.name_suffix,
+       u.nickname,
+       u.email,
+       u.company_name,
+       u.company_dept,
+       u.job_title,
+       u.active,
+       u.suspended_reason,
+       u.suspended_timestamp,
+       u.create_app_id,
+       a.org_id           create_app_org_id,
+       a.app_extl_id      create_app_extl_id,
+       a.app_name         create_app_name,
+       a.app_description  create_app_description,
+       u.create_user_id,
+       cu.first_name      create_user_first_name,
+       cu.last_name       create_user_last_name,
+       u.create_timestamp,
+       u.update_app_id,
+       a2.org_id          update_app_org_id,
+       a2.app_extl_id     update_app_extl_id,
+       a2.app_name        update_app_name,
+       a2.app_description update_app_description,
+       u.update_user_id,
+       uu.first_name      update_user_first_name,
+       uu.last_name       update_user_last_name,
+       u.update_timestamp
+FROM users u
+         INNER JOIN users_org uo on uo.user_id = u.user_id
+         INNER JOIN app a on a.app_id = u.create_app_id
+         INNER JOIN app a2 on a2.app_id = u.update_app_id
+         LEFT JOIN users cu on cu.user_id = u.create_user_id
+         LEFT JOIN users uu on uu.user_id = u.update_user_id
+WHERE uo.org_id = $1
+ORDER BY u.last_name, u.first_name;
+
+-- name: UpdateUser :execrows
+UPDATE users
+SET name_prefix      = $1,
+    first_name       = $2,
+    middle_name      = $3,
+    last_name        = $4,
+    name_suffix      = $5,
+    nickname         = $6,
+    company_name     = $7,
+    company_dept     = $8,
+    job_title        = $9,
+    update_app_id    = $10,
+    update_user_id   = $11,
+    update_timestamp = $12
+WHERE user_id = $13;
+
+-- name: SuspendUser :execrows
+UPDATE users
+SET active              = false,
+    suspended_reason    = $1,
+    suspended_timestamp = $2,
+    update_app_id       = $3,
+    update_user_id      = $4,
+    update_timestamp    = $5
+WHERE user_id = $6;
+
+-- name: ReinstateUser :execrows
+UPDATE users
+SET active              = true,
+    suspended_reason    = null,
+    suspended_timestamp = null,
+    update_app_id       = $1,
+    update_user_id      = $2,
+    update_timestamp    = $3
+WHERE user_id = $4;
+
 -- name: DeleteUserByID :execrows
 DELETE FROM users
 WHERE user_id = $1;
@@ -81,11 +215,12 @@ WHERE user_id = $1;
 -- name: FindUserLanguagePreferencesByUserID :many
 SELECT *
 FROM users_lang_prefs
-WHERE user_id = $1;
+WHERE user_id = $1
+ORDER BY pref_order;
 
 -- name: CreateUserLanguagePreference :execrows
-INSERT INTO users_lang_prefs (user_id, language_tag, create_app_id, create_user_id, create_timestamp, update_app_id, update_user_id, update_timestamp)
-VALUES ($1, $2, $3, $4, $5, $6, $7, $8);
+INSERT INTO users_lang_prefs (user_id, language_tag, pref_order, create_app_id, create_user_id, create_timestamp, update_app_id, update_user_id, update_timestamp)
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9);
 
 -- name: DeleteUserLanguagePreferences :execrows
 DELETE FROM users_lang_prefs
@@ -2,6 +2,8 @@ package diygoapi
 
 import (
 	"context"
+	"fmt"
+	"strings"
 	"time"
 
 	"github.com/google/uuid"
@@ -13,7 +15,34 @@ import (
 
 // RegisterUserServicer registers a new user
 type RegisterUserServicer interface {
-	SelfRegister(ctx context.Context, adt Audit) error
+	// Register registers the person authenticated by the provider as
+	// a new User, subject to the RegistrationPolicy
+	Register(ctx context.Context, params AuthenticationParams, r *RegisterUserRequest) (*RegisterUserResponse, error)
+}
+
+// UserServicer manages the retrieval and manipulation of a User
+type UserServicer interface {
+	// FindAll returns the users who are members of the org the audit
+	// user is acting in
+	FindAll(ctx context.Context, adt Audit) ([]*UserResponse, error)
+	FindByExternalID(ctx context.Context, extlID string, adt Audit) (*UserResponse, error)
+	// FindByOrg returns the users who are members of an Org. Only a
+	// member or an admin of the Org may list them.
+	FindByOrg(ctx context.Context, orgExtlID string, adt Audit) ([]*UserResponse, error)
+	Update(ctx context.Context, r *UpdateUserRequest, adt Audit) (*UserResponse, error)
+	// RemoveFromOrg removes a user from an Org, along with the roles
+	// they hold in it
+	RemoveFromOrg(ctx context.Context, orgExtlID, userExtlID string, adt Audit) (DeleteResponse, error)
+	// Suspend prevents a User from authenticating until reinstated
+	Suspend(ctx context.Context, r *SuspendUserRequest, adt Audit) (*UserResponse, error)
+	// Reinstate allows a suspended User to authenticate again
+	Reinstate(ctx context.Context, extlID string, adt Audit) (*UserResponse, error)
+	// FindLanguagePreferences returns the ordered language preferences
+	// of a User. Only the User or an admin of the acting org may read them.
+	FindLanguagePreferences(ctx context.Context, extlID string, adt Audit) (*UserLanguagePreferencesResponse, error)
+	// UpdateLanguagePreferences replaces the language preferences of a
+	// User. Only the User or an admin of the acting org may replace them.
+	UpdateLanguagePreferences(ctx context.Context, r *UpdateUserLanguagePreferencesRequest, adt Audit) (*UserLanguagePreferencesResponse, error)
 }
 
 // Person - from Wikipedia: "A person (plural people or persons) is a being that
@@ -131,6 +160,15 @@ type User struct {
 
 	// Source: The origin of the User (e.g. Google Oauth2, Apple Oauth2, etc.)
 	Source string
+
+	// Suspended: Whether the User is suspended. A suspended User cannot authenticate.
+	Suspended bool
+
+	// SuspendedReason: Why the User was suspended
+	SuspendedReason string
+
+	// SuspendedAt: The moment the User was suspended
+	SuspendedAt time.Time
 }
 
 // Validate determines whether the Person has proper data to be considered valid
@@ -161,3 +199,214 @@ func (u User) NullUUID() uuid.NullUUID {
 		Valid: true,
 	}
 }
+
+// UpdateUserRequest is the request struct for updating the profile of a User
+type UpdateUserRequest struct {
+	ExternalID        string
+	NamePrefix        string `json:"name_prefix"`
+	FirstName         string `json:"first_name"`
+	MiddleName        string `json:"middle_name"`
+	LastName          string `json:"last_name"`
+	NameSuffix        string `json:"name_suffix"`
+	Nickname          string `json:"nickname"`
+	CompanyName       string `json:"company_name"`
+	CompanyDepartment string `json:"company_dept"`
+	JobTitle          string `json:"job_title"`
+}
+
+// Validate determines whether the UpdateUserRequest has proper data to be considered valid
+func (r UpdateUserRequest) Validate() error {
+	const op errs.Op = "diygoapi/UpdateUserRequest.Validate"
+
+	switch {
+	case r.FirstName == "":
+		return errs.E(op, errs.Validation, "first name is required")
+	case r.LastName == "":
+		return errs.E(op, errs.Validation, "last name is required")
+	}
+	return nil
+}
+
+// SuspendUserRequest is the request struct for suspending a User
+type SuspendUserRequest struct {
+	ExternalID string
+	Reason     string `json:"reason"`
+}
+
+// Validate determines whether the SuspendUserRequest has proper data to be considered valid
+func (r SuspendUserRequest) Validate() error {
+	const op errs.Op = "diygoapi/SuspendUserRequest.Validate"
+
+	if r.Reason == "" {
+		return errs.E(op, errs.Validation, "reason is required")
+	}
+	return nil
+}
+
+// UpdateUserLanguagePreferencesRequest is the request struct for
+// replacing the language preferences of a User. The language tags
+// are given in order of preference, the most preferred first.
+type UpdateUserLanguagePreferencesRequest struct {
+	ExternalID   string
+	LanguageTags []string `json:"language_tags"`
+}
+
+// Validate determines whether the UpdateUserLanguagePreferencesRequest has proper data to be considered valid
+func (r UpdateUserLanguagePreferencesRequest) Validate() error {
+	const op errs.Op = "diygoapi/UpdateUserLanguagePreferencesRequest.Validate"
+
+	seen := make(map[string]bool)
+	for _, lt := range r.LanguageTags {
+		tag, err := language.Parse(lt)
+		if err != nil {
+			return errs.E(op, errs.Validation, fmt.Sprintf("%s is not a valid language tag", lt))
+		}
+		if seen[tag.String()] {
+			return errs.E(op, errs.Validation, fmt.Sprintf("language tag %s is given more than once", lt))
+		}
+		seen[tag.String()] = true
+	}
+	return nil
+}
+
+// UserLanguagePreferencesResponse is the response struct for the
+// language preferences of a User, the most preferred first
+type UserLanguagePreferencesResponse struct {
+	UserExtlID   string   `json:"user_extl_id"`
+	LanguageTags []string `json:"language_tags"`
+}
+
+// NegotiateLanguage returns the language, among those supported by the
+// matcher, which best suits the languages accepted for a request and
+// the language preferences of the User. acceptLanguage is the value of
+// the Accept-Language header, the languages it accepts take precedence
+// over the preferences of the User. A malformed header is ignored.
+func NegotiateLanguage(m language.Matcher, acceptLanguage string, prefs []language.Tag) language.Tag {
+	accepted, _, _ := language.ParseAcceptLanguage(acceptLanguage)
+
+	tags := make([]language.Tag, 0, len(accepted)+len(prefs))
+	tags = append(tags, accepted...)
+	tags = append(tags, prefs...)
+
+	tag, _, _ := m.Match(tags...)
+
+	return tag
+}
+
+// UserResponse is the response struct for a User
+type UserResponse struct {
+	ExternalID          string `json:"external_id"`
+	NamePrefix          string `json:"name_prefix"`
+	FirstName           string `json:"first_name"`
+	MiddleName          string `json:"middle_name"`
+	LastName            string `json:"last_name"`
+	NameSuffix          string `json:"name_suffix"`
+	Nickname            string `json:"nickname"`
+	Email               string `json:"email"`
+	CompanyName         string `json:"company_name"`
+	CompanyDepartment   string `json:"company_dept"`
+	JobTitle            string `json:"job_title"`
+	Active              bool   `json:"active"`
+	SuspendedReason     string `json:"suspended_reason,omitempty"`
+	SuspendedDateTime   string `json:"suspended_date_time,omitempty"`
+	CreateAppExtlID     string `json:"create_app_extl_id"`
+	CreateUserFirstName string `json:"create_user_first_name"`
+	CreateUserLastName  string `json:"create_user_last_name"`
+	CreateDateTime      string `json:"create_date_time"`
+	UpdateAppExtlID     string `json:"update_app_extl_id"`
+	UpdateUserFirstName string `json:"update_user_first_name"`
+	UpdateUserLastName  string `json:"update_user_last_name"`
+	UpdateDateTime      string `json:"update_date_time"`
+}
+
+// Registration policy modes
+const (
+	// RegistrationOpen allows anyone to register
+	RegistrationOpen = "open"
+	// RegistrationDomains allows people with an email address in one
+	// of the allowed domains, or with an invitation, to register
+	RegistrationDomains = "domains"
+	// RegistrationInvite allows only people with an invitation to register
+	RegistrationInvite = "invite"
+)
+
+// RegistrationPolicy determines who is allowed to register as a User
+type RegistrationPolicy struct {
+	// Mode: one of RegistrationOpen, RegistrationDomains or RegistrationInvite
+	Mode string
+	// AllowedDomains: the email domains allowed to register when
+	// Mode is RegistrationDomains
+	AllowedDomains []string
+}
+
+// NewRegistrationPolicy initializes a RegistrationPolicy and validates it
+func NewRegistrationPolicy(mode string, allowedDomains []string) (RegistrationPolicy, error) {
+	const op errs.Op = "diygoapi/NewRegistrationPolicy"
+
+	var domains []string
+	for _, d := range allowedDomains {
+		d = strings.ToLower(strings.TrimSpace(d))
+		if d != "" {
+			domains = append(domains, d)
+		}
+	}
+
+	switch mode {
+	case RegistrationOpen, RegistrationInvite:
+	case RegistrationDomains:
+		if len(domains) == 0 {
+			return RegistrationPolicy{}, errs.E(op, "at least one allowed domain is required for the domains registration policy")
+		}
+	default:
+		return RegistrationPolicy{}, errs.E(op, fmt.Sprintf("registration policy %q is not valid (open, domains or invite)", mode))
+	}
+
+	return RegistrationPolicy{Mode: mode, AllowedDomains: domains}, nil
+}
+
+// Allow determines whether a person with the given email is allowed to
+// register. emailVerified reports whether the authentication provider
+// has verified the email, an unverified email is not trusted to place
+// the person in an allowed domain. invited reports whether an
+// invitation token was given, the token itself is verified when the
+// invitation is accepted.
+func (p RegistrationPolicy) Allow(email string, emailVerified, invited bool) error {
+	const op errs.Op = "diygoapi/RegistrationPolicy.Allow"
+
+	if p.Mode == RegistrationOpen || invited {
+		return nil
+	}
+
+	if p.Mode == RegistrationDomains {
+		if !emailVerified {
+			return errs.E(op, errs.Unauthorized, fmt.Sprintf("registration requires a verified email, %s has not been verified", email))
+		}
+		at := strings.LastIndex(email, "@")
+		if at != -1 {
+			domain := strings.ToLower(email[at+1:])
+			for _, d := range p.AllowedDomains {
+				if domain == d {
+					return nil
+				}
+			}
+		}
+		return errs.E(op, errs.Unauthorized, fmt.Sprintf("registration is not open to %s", email))
+	}
+
+	return errs.E(op, errs.Unauthorized, "registration requires an invitation")
+}
+
+// RegisterUserRequest is the request struct for registering a User.
+// If CreateOrg is true, a personal Org is created for the User.
+type RegisterUserRequest struct {
+	CreateOrg      bool   `json:"create_org"`
+	OrgName        string `json:"org_name"`
+	OrgDescription string `json:"org_description"`
+}
+
+// RegisterUserResponse is the response struct for a registered User.
+// Org is the personal Org created for the User, if any.
+type RegisterUserResponse struct {
+	User *UserResponse `json:"user"`
+	Org  *OrgResponse  `json:"org,omitempty"`
+}
@@ -7,6 +7,7 @@ import (
 	qt "github.com/frankban/quicktest"
 	"github.com/google/go-cmp/cmp"
 	"github.com/google/uuid"
+	"golang.org/x/text/language"
 
 	"github.com/gilcrest/diygoapi/errs"
 	"github.com/gilcrest/diygoapi/secure"
@@ -92,3 +93,90 @@ func TestUser_Validate(t *testing.T) {
 		})
 	}
 }
+
+func TestUpdateUserRequest_Validate(t *testing.T) {
+	c := qt.New(t)
+
+	c.Assert(UpdateUserRequest{FirstName: "Otto", LastName: "Maddox", JobTitle: "Repo Man"}.Validate(), qt.IsNil)
+	c.Assert(errs.KindIs(errs.Validation, UpdateUserRequest{LastName: "Maddox"}.Validate()), qt.IsTrue)
+	c.Assert(errs.KindIs(errs.Validation, UpdateUserRequest{FirstName: "Otto"}.Validate()), qt.IsTrue)
+}
+
+func TestSuspendUserRequest_Validate(t *testing.T) {
+	c := qt.New(t)
+
+	c.Assert(SuspendUserRequest{ExternalID: "abc", Reason: "left the company"}.Validate(), qt.IsNil)
+	c.Assert(errs.KindIs(errs.Validation, SuspendUserRequest{ExternalID: "abc"}.Validate()), qt.IsTrue)
+}
+
+func TestUpdateUserLanguagePreferencesRequest_Validate(t *testing.T) {
+	c := qt.New(t)
+
+	c.Assert(UpdateUserLanguagePreferencesRequest{ExternalID: "abc", LanguageTags: []string{"fr-CA", "en-US"}}.Validate(), qt.IsNil)
+	c.Assert(UpdateUserLanguagePreferencesRequest{ExternalID: "abc"}.Validate(), qt.IsNil)
+	c.Assert(errs.KindIs(errs.Validation, UpdateUserLanguagePreferencesRequest{ExternalID: "abc", LanguageTags: []string{"not a tag"}}.Validate()), qt.IsTrue)
+	c.Assert(errs.KindIs(errs.Validation, UpdateUserLanguagePreferencesRequest{ExternalID: "abc", LanguageTags: []string{"en-US", "en-us"}}.Validate()), qt.IsTrue)
+}
+
+func TestNegotiateLanguage(t *testing.T) {
+	c := qt.New(t)
+
+	m := language.NewMatcher([]language.Tag{language.AmericanEnglish, language.French, language.Spanish})
+
+	base := func(tag language.Tag) language.Base {
+		b, _ := tag.Base()
+		return b
+	}
+
+	// the accepted languages take precedence over the preferences
+	tag := NegotiateLanguage(m, "es;q=0.8, fr;q=0.9", []language.Tag{language.AmericanEnglish})
+	c.Assert(base(tag), qt.Equals, base(language.French))
+
+	// the preferences are used when no accepted language is supported
+	tag = NegotiateLanguage(m, "de", []language.Tag{language.Spanish})
+	c.Assert(base(tag), qt.Equals, base(language.Spanish))
+
+	// a malformed header is ignored
+	tag = NegotiateLanguage(m, "=;;", []language.Tag{language.Spanish})
+	c.Assert(base(tag), qt.Equals, base(language.Spanish))
+
+	// the first supported language is the default
+	tag = NegotiateLanguage(m, "", nil)
+	c.Assert(tag, qt.Equals, language.AmericanEnglish)
+}
+
+func TestNewRegistrationPolicy(t *testing.T) {
+	c := qt.New(t)
+
+	p, err := NewRegistrationPolicy(RegistrationDomains, []string{" Example.com", ""})
+	c.Assert(err, qt.IsNil)
+	c.Assert(p, qt.DeepEquals, RegistrationPolicy{Mode: RegistrationDomains, AllowedDomains: []string{"example.com"}})
+
+	_, err = NewRegistrationPolicy(RegistrationOpen, nil)
+	c.Assert(err, qt.IsNil)
+	_, err = NewRegistrationPolicy(RegistrationInvite, nil)
+	c.Assert(err, qt.IsNil)
+	_, err = NewRegistrationPolicy(RegistrationDomains, nil)
+	c.Assert(err, qt.IsNotNil)
+	_, err = NewRegistrationPolicy("closed", nil)
+	c.Assert(err, qt.IsNotNil)
+}
+
+func TestRegistrationPolicy_Allow(t *testing.T) {
+	c := qt.New(t)
+
+	open := RegistrationPolicy{Mode: RegistrationOpen}
+	c.Assert(open.Allow("otto.maddox@gmail.com", true, false), qt.IsNil)
+
+	domains := RegistrationPolicy{Mode: RegistrationDomains, AllowedDomains: []string{"helpinghandacceptanceco.com"}}
+	c.Assert(domains.Allow("otto.maddox@HelpingHandAcceptanceCo.com", true, false), qt.IsNil)
+	c.Assert(domains.Allow("otto.maddox@gmail.com", true, true), qt.IsNil)
+	c.Assert(errs.KindIs(errs.Unauthorized, domains.Allow("otto.maddox@gmail.com", true, false)), qt.IsTrue)
+	c.Assert(errs.KindIs(errs.Unauthorized, domains.Allow("", true, false)), qt.IsTrue)
+	c.Assert(errs.KindIs(errs.Unauthorized, domains.Allow("otto.maddox@HelpingHandAcceptanceCo.com", false, false)), qt.IsTrue)
+	c.Assert(domains.Allow("otto.maddox@HelpingHandAcceptanceCo.com", false, true), qt.IsNil)
+
+	invite := RegistrationPolicy{Mode: RegistrationInvite}
+	c.Assert(invite.Allow("otto.maddox@gmail.com", true, true), qt.IsNil)
+	c.Assert(errs.KindIs(errs.Unauthorized, invite.Allow("otto.maddox@gmail.com", true, false)), qt.IsTrue)
+}